@@ -10,10 +10,11 @@ import (
 	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
 	"github.com/fernandotsda/nemesys/shared/cache"
 	"github.com/fernandotsda/nemesys/shared/env"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/pg"
 	"github.com/fernandotsda/nemesys/shared/service"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/storage/backend"
 	"github.com/rabbitmq/amqp091-go"
 )
 
@@ -32,8 +33,8 @@ type Alarm struct {
 	amqph *amqph.Amqph
 	// smtpAuth is the smtp plain auth.
 	smtpAuth smtp.Auth
-	// influxdb is the influxdb client.
-	influxdb *influxdb.Client
+	// storage is the metrics data storage backend.
+	storage storage.Storage
 }
 
 func New(serviceNumber int) service.Service {
@@ -54,15 +55,21 @@ func New(serviceNumber int) service.Service {
 	}
 	log.Info("Connected to amqp server")
 
-	influxdb, err := influxdb.Connect()
+	storage, err := backend.Connect()
 	if err != nil {
-		log.Fatal("Fail to connect to influxdb", logger.ErrField(err))
+		log.Fatal("Fail to connect to storage backend", logger.ErrField(err))
 		return nil
 	}
-	log.Info("Connected to influxdb")
+	log.Info("Connected to storage backend: " + env.StorageBackend)
 
 	tools := service.NewTools(service.Alarm, serviceNumber)
 
+	err = storage.StartWriteBuffer(tools.ServiceIdent)
+	if err != nil {
+		log.Fatal("Fail to start storage write buffer", logger.ErrField(err))
+		return nil
+	}
+
 	publishers, err := strconv.Atoi(env.AlarmServiceAMQPPublishers)
 	if err != nil {
 		log.Fatal("Fail to parse env.AlarmServiceAMQPPublishers", logger.ErrField(err))
@@ -74,7 +81,7 @@ func New(serviceNumber int) service.Service {
 		Conn:       amqpConn,
 		Publishers: publishers,
 	})
-	go t.ServicePing(amqph, tools.ServiceIdent)
+	go t.ServicePingWithStatus(amqph, tools.ServiceIdent, storage.PongStatus(tools.ServiceIdent))
 
 	cache, err := cache.New()
 	if err != nil {
@@ -89,7 +96,7 @@ func New(serviceNumber int) service.Service {
 		log:      log,
 		amqph:    amqph,
 		Tools:    tools,
-		influxdb: &influxdb,
+		storage:  storage,
 		smtpAuth: smtp.PlainAuth("", env.MetricAlarmEmailSender, env.MetricAlarmEmailSenderPassword, env.MetricAlarmEmailSenderHost),
 	}
 }
@@ -113,7 +120,7 @@ func (a *Alarm) Close() error {
 	a.cache.Close()
 	a.log.Close()
 	a.pg.Close()
-	a.influxdb.Close()
+	a.storage.Close()
 
 	return nil
 }
@@ -2,7 +2,9 @@ package alarm
 
 import (
 	"context"
+	"strconv"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
@@ -38,6 +40,28 @@ func (a *Alarm) notifyAlarm(ctx context.Context, occurency models.AlarmOccurency
 	info.AlarmCategory.Level = occurency.Category.Level
 	info.OccurencyDate = occurency.Time.Unix()
 
+	profiles = a.matchingProfiles(profiles, info.Labels)
+	if len(profiles) == 0 {
+		a.log.Debug("Skipping alarm notification, no alarm profile selects the metric")
+		return
+	}
+
 	go a.notifyEmail(info, profiles)
 	go a.notifyEndpoints(info, profiles)
 }
+
+// matchingProfiles returns the profiles which label selector matches the labels.
+func (a *Alarm) matchingProfiles(profiles []models.AlarmProfileSimplified, l map[string]string) []models.AlarmProfileSimplified {
+	matching := make([]models.AlarmProfileSimplified, 0, len(profiles))
+	for _, p := range profiles {
+		selector, err := labels.Parse(p.Selector)
+		if err != nil {
+			a.log.Warn("Invalid alarm profile label selector, profile id: " + strconv.FormatInt(int64(p.Id), 10))
+			continue
+		}
+		if selector.Matches(l) {
+			matching = append(matching, p)
+		}
+	}
+	return matching
+}
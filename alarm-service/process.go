@@ -49,6 +49,6 @@ func (a *Alarm) processAlarm(occurency models.AlarmOccurency) {
 }
 
 func (a *Alarm) saveAlarmOccurency(occurency models.AlarmOccurency) {
-	a.influxdb.WriteAlarmOccurency(occurency)
-	a.log.Debug("Alarm occurency saved on influxdb, metric id: " + strconv.FormatInt(occurency.MetricId, 10))
+	a.storage.WriteAlarmOccurency(occurency)
+	a.log.Debug("Alarm occurency saved on storage backend, metric id: " + strconv.FormatInt(occurency.MetricId, 10))
 }
@@ -3,9 +3,10 @@ package main
 import (
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/router"
+	scheduledreport "github.com/fernandotsda/nemesys/api-manager/internal/scheduled-report"
 	"github.com/fernandotsda/nemesys/shared/service"
 )
 
 func main() {
-	service.Start(service.APIManager, api.New, router.Set)
+	service.Start(service.APIManager, api.New, router.Set, scheduledreport.StartScheduler)
 }
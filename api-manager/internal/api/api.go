@@ -15,11 +15,14 @@ import (
 	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
 	"github.com/fernandotsda/nemesys/shared/cache"
 	"github.com/fernandotsda/nemesys/shared/env"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/mail"
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/pg"
 	"github.com/fernandotsda/nemesys/shared/rdb"
 	"github.com/fernandotsda/nemesys/shared/service"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/storage/backend"
 	"github.com/fernandotsda/nemesys/shared/trap"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -39,18 +42,26 @@ type API struct {
 	Amqph *amqph.Amqph
 	// Postgresql handler.
 	PG *pg.PG
-	// Influx is the influxdb client.
-	Influx *influxdb.Client
+	// Storage is the metrics data storage backend.
+	Storage storage.Storage
 	// Cache is the cache handler.
 	Cache *cache.Cache
 	// Gin web fremework engine.
 	Router *gin.Engine
 	// Auth handler.
 	Auth *auth.Auth
+	// LDAP is the LDAP authentication provider. Is nil if disabled.
+	LDAP *auth.LDAP
+	// Mailer is the emails sender. Is nil if the SMTP server is not configured.
+	Mailer *mail.Mailer
 	// Validator.
 	Validate *validator.Validate
 	// User pw hash cost.
 	UserPWBcryptCost int
+	// auditLogRetention is the audit log entries retention.
+	auditLogRetention time.Duration
+	// RateLimits are the default rate limits of each route group.
+	RateLimits map[string]models.RateLimitPolicy
 	// Logger is the internal logger.
 	Log *logger.Logger
 	// Counter is the request counter.
@@ -92,18 +103,40 @@ func New(serviceNumber int) service.Service {
 	}
 	log.Info("Connected to redis client (auth client)")
 
+	var ldap *auth.LDAP
+	ldapConfig, ldapEnabled, err := auth.LDAPConfigFromEnv()
+	if err != nil {
+		log.Fatal("Fail to load LDAP configuration", logger.ErrField(err))
+		return nil
+	}
+	if ldapEnabled {
+		ldap = auth.NewLDAP(ldapConfig)
+		log.Info("LDAP authentication enabled: " + ldapConfig.URL)
+	}
+
+	mailer, mailEnabled := mail.NewFromEnv()
+	if !mailEnabled {
+		log.Warn("SMTP server is not configured, emails are disabled")
+	}
+
 	auth, err := auth.New(rdbAuth)
 	if err != nil {
 		log.Panic("Fail to create auth handler", logger.ErrField(err))
 		return nil
 	}
 
-	influxClient, err := influxdb.Connect()
+	storage, err := backend.Connect()
+	if err != nil {
+		log.Panic("Fail to connect to storage backend", logger.ErrField(err))
+		return nil
+	}
+	log.Info("Connected to storage backend: " + env.StorageBackend)
+
+	err = storage.StartWriteBuffer(tools.ServiceIdent)
 	if err != nil {
-		log.Panic("Fail to connect to influxdb", logger.ErrField(err))
+		log.Panic("Fail to start storage write buffer", logger.ErrField(err))
 		return nil
 	}
-	log.Info("Connected to influxdb")
 
 	bcryptCost, err := strconv.Atoi(env.UserPWBcryptCost)
 	if err != nil {
@@ -111,7 +144,24 @@ func New(serviceNumber int) service.Service {
 		return nil
 	}
 
+	auditLogRetention, err := strconv.ParseInt(env.AuditLogRetention, 10, 64)
+	if err != nil {
+		log.Fatal("Fail to parse env.AuditLogRetention", logger.ErrField(err))
+		return nil
+	}
+
+	rateLimits, err := rateLimitsFromEnv()
+	if err != nil {
+		log.Fatal("Fail to parse rate limits envs", logger.ErrField(err))
+		return nil
+	}
+
 	validate := validator.New()
+	err = registerValidations(validate)
+	if err != nil {
+		log.Fatal("Fail to register validations", logger.ErrField(err))
+		return nil
+	}
 	pg := pg.New()
 
 	publishers, err := strconv.Atoi(env.APIManagerAMQPPublishers)
@@ -125,7 +175,7 @@ func New(serviceNumber int) service.Service {
 		Conn:       amqpConn,
 		Publishers: publishers,
 	})
-	go t.ServicePing(amqph, tools.ServiceIdent)
+	go t.ServicePingWithStatus(amqph, tools.ServiceIdent, storage.PongStatus(tools.ServiceIdent))
 
 	cache, err := cache.New()
 	if err != nil {
@@ -134,19 +184,23 @@ func New(serviceNumber int) service.Service {
 	}
 
 	api := &API{
-		amqpConn:         amqpConn,
-		Tools:            tools,
-		PG:               pg,
-		Influx:           &influxClient,
-		Auth:             auth,
-		Validate:         validate,
-		Log:              log,
-		Cache:            cache,
-		Amqph:            amqph,
-		UserPWBcryptCost: bcryptCost,
-		Counter:          counter.New(&influxClient, pg, log, time.Second*10),
-		servicesStatus:   []service.ServiceStatus{},
-		trapsListeners:   []*trap.Trap{},
+		amqpConn:          amqpConn,
+		Tools:             tools,
+		PG:                pg,
+		Storage:           storage,
+		Auth:              auth,
+		LDAP:              ldap,
+		Mailer:            mailer,
+		Validate:          validate,
+		Log:               log,
+		Cache:             cache,
+		Amqph:             amqph,
+		UserPWBcryptCost:  bcryptCost,
+		auditLogRetention: time.Duration(auditLogRetention) * time.Hour,
+		RateLimits:        rateLimits,
+		Counter:           counter.New(storage, pg, log, time.Second*10),
+		servicesStatus:    []service.ServiceStatus{},
+		trapsListeners:    []*trap.Trap{},
 	}
 
 	err = api.createDefaultUser(context.Background())
@@ -160,6 +214,7 @@ func New(serviceNumber int) service.Service {
 func (api *API) Run() {
 	go api.servicesStatusListener()
 	go api.startTrapListeners()
+	go api.auditLogCleaner()
 
 	url := fmt.Sprintf("%s:%s", env.APIManagerHost, env.APIManagerPort)
 	api.Log.Info("Server listening to: " + url)
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/logger"
+)
+
+// auditLogCleanupInterval is the interval between each audit log cleanup.
+const auditLogCleanupInterval = time.Hour
+
+// auditLogCleaner deletes the audit entries older than the audit log retention.
+func (api *API) auditLogCleaner() {
+	ticker := time.NewTicker(auditLogCleanupInterval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		n, err := api.PG.DeleteAuditEntriesBefore(ctx, time.Now().Add(-api.auditLogRetention).Unix())
+		cancel()
+		if err != nil {
+			api.Log.Error("Fail to delete old audit entries", logger.ErrField(err))
+		} else if n > 0 {
+			api.Log.Debug("Old audit entries deleted, entries: " + strconv.FormatInt(n, 10))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-api.Done():
+			return
+		}
+	}
+}
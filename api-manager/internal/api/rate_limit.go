@@ -0,0 +1,32 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// rateLimitsFromEnv returns the default rate limits of each route group.
+func rateLimitsFromEnv() (limits map[string]models.RateLimitPolicy, err error) {
+	window, err := strconv.ParseInt(env.RateLimitWindow, 10, 32)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("invalid env.RateLimitWindow: %s", env.RateLimitWindow)
+	}
+	requests := map[string]string{
+		models.RateLimitGroupDefault:  env.RateLimitDefault,
+		models.RateLimitGroupRealtime: env.RateLimitRealtime,
+		models.RateLimitGroupHistory:  env.RateLimitHistory,
+		models.RateLimitGroupIngest:   env.RateLimitIngest,
+	}
+	limits = make(map[string]models.RateLimitPolicy, len(requests))
+	for group, raw := range requests {
+		n, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s rate limit: %s", group, raw)
+		}
+		limits[group] = models.RateLimitPolicy{Requests: int32(n), Window: int32(window)}
+	}
+	return limits, nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"github.com/fernandotsda/nemesys/shared/cron"
+	"github.com/fernandotsda/nemesys/shared/labels"
+	"github.com/go-playground/validator/v10"
+)
+
+// registerValidations registers the custom validations:
+//   - "labels" validates a map of labels.
+//   - "selector" validates a label selector string.
+//   - "cron" validates a cron schedule string.
+func registerValidations(validate *validator.Validate) error {
+	err := validate.RegisterValidation("labels", func(fl validator.FieldLevel) bool {
+		l, ok := fl.Field().Interface().(map[string]string)
+		return ok && labels.Validate(l) == nil
+	})
+	if err != nil {
+		return err
+	}
+	err = validate.RegisterValidation("selector", func(fl validator.FieldLevel) bool {
+		return labels.Valid(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+	return validate.RegisterValidation("cron", func(fl validator.FieldLevel) bool {
+		return cron.Valid(fl.Field().String())
+	})
+}
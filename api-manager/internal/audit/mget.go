@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Get multiple audit entries.
+// Params:
+//   - "limit" Limit of entries returned. Default is 30, max is 100, min is 1.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//   - "user-id" User id.
+//   - "api-key-id" API Key id.
+//   - "method" Request method.
+//   - "route" Route prefix, like "/users".
+//   - "entity" Entity path prefix, like "/users/1".
+//   - "status" Response status code.
+//   - "ip" Client ip.
+//   - "from" Minimum creation date in unix seconds.
+//   - "to" Maximum creation date in unix seconds.
+//   - "order-by" Column to order by. Default is "id".
+//   - "order-by-fn" Order function, "asc" or "desc". Default is "desc".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 100, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var ints [5]int64
+		for i, key := range []string{"user-id", "api-key-id", "status", "from", "to"} {
+			raw := c.Query(key)
+			if raw == "" {
+				continue
+			}
+			ints[i], err = strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+		}
+
+		filters := pg.AuditLogQueryFilters{
+			UserId:    int32(ints[0]),
+			APIKeyId:  int32(ints[1]),
+			Method:    strings.ToUpper(c.Query("method")),
+			Route:     c.Query("route"),
+			Entity:    c.Query("entity"),
+			Status:    int16(ints[2]),
+			IP:        c.Query("ip"),
+			From:      ints[3],
+			To:        ints[4],
+			OrderBy:   tools.DefaultQuery(c, "order-by", "id"),
+			OrderByFn: tools.DefaultQuery(c, "order-by-fn", "desc"),
+			Limit:     limit,
+			Offset:    offset,
+		}
+
+		entries, err := api.PG.GetAuditEntries(ctx, filters)
+		if err != nil {
+			if err == pg.ErrInvalidOrderByColumn || err == pg.ErrInvalidFilterValue || err == pg.ErrInvalidOrderByFn {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get audit entries", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(entries))
+	}
+}
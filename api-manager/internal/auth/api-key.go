@@ -2,10 +2,12 @@ package auth
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
 	"github.com/fernandotsda/nemesys/shared/amqp"
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/rdb"
 	"github.com/go-redis/redis/v8"
 )
@@ -17,6 +19,11 @@ type APIKeyMeta struct {
 	UserId int32
 	// Role is the user role.
 	Role roles.Role
+	// Scopes are the API Key scopes, empty for full access.
+	Scopes []models.APIKeyScope
+	// AllowedIPs are the ips and cidrs allowed to use the API Key, empty
+	// for any ip.
+	AllowedIPs []string
 }
 
 func (a *APIKeyMeta) Bytes() []byte {
@@ -50,7 +57,11 @@ func (a *Auth) RemoveAPIKey(ctx context.Context, id int32) error {
 	if err != nil && err != redis.Nil {
 		return err
 	}
-	return a.rdb.Del(ctx, rdb.AuthAPIKeyKey(apikey), rdb.AuthReverseAPIKeyKey(id)).Err()
+	p := a.rdb.Pipeline()
+	p.Del(ctx, rdb.AuthAPIKeyKey(apikey), rdb.AuthReverseAPIKeyKey(id))
+	p.HDel(ctx, rdb.AuthAPIKeysLastUsedKey(), strconv.FormatInt(int64(id), 10))
+	_, err = p.Exec(ctx)
+	return err
 }
 
 // ValidateAPIKey validates a API key on Redis and return the key metadata. An error
@@ -67,3 +78,36 @@ func (a *Auth) ValidateAPIKey(ctx context.Context, apikey string) (metadata APIK
 	}
 	return metadata, nil
 }
+
+// SetAPIKeyLastUsed saves the last use date of an API key.
+func (a *Auth) SetAPIKeyLastUsed(ctx context.Context, id int32, t time.Time) error {
+	return a.rdb.HSet(ctx, rdb.AuthAPIKeysLastUsedKey(), strconv.FormatInt(int64(id), 10), t.Unix()).Err()
+}
+
+// GetAPIKeysLastUsed returns the last use date in UNIX format of each API key,
+// zero if never used.
+func (a *Auth) GetAPIKeysLastUsed(ctx context.Context, ids []int16) (lastUsed []int64, err error) {
+	lastUsed = make([]int64, len(ids))
+	if len(ids) == 0 {
+		return lastUsed, nil
+	}
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = strconv.FormatInt(int64(id), 10)
+	}
+	values, err := a.rdb.HMGet(ctx, rdb.AuthAPIKeysLastUsedKey(), fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		lastUsed[i], err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lastUsed, nil
+}
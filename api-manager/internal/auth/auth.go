@@ -18,6 +18,9 @@ type Auth struct {
 
 	// Session Token size
 	TokenSize int
+
+	// PasswordResetTTL is the password reset token time to live.
+	PasswordResetTTL time.Duration
 }
 
 type AuthConfig struct {
@@ -33,10 +36,15 @@ func New(rdb *redis.Client) (*Auth, error) {
 	if err != nil {
 		return nil, fmt.Errorf("fail to parse env.UserSessionTokenSize to int, err:%s", err)
 	}
+	resetTTL, err := strconv.Atoi(env.PasswordResetTTL)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse env.PasswordResetTTL to int, err:%s", err)
+	}
 	return &Auth{
-		rdb:        rdb,
-		SessionTTL: time.Second * time.Duration(ttl),
-		TokenSize:  size,
+		rdb:              rdb,
+		SessionTTL:       time.Second * time.Duration(ttl),
+		TokenSize:        size,
+		PasswordResetTTL: time.Second * time.Duration(resetTTL),
 	}, nil
 }
 
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+)
+
+// TeamGroup maps a group of an external provider to a team membership.
+type TeamGroup struct {
+	// Group is the group name.
+	Group string
+	// Team is the team ident.
+	Team string
+	// Role is the member team role.
+	Role roles.TeamRole
+}
+
+// GroupsMapping maps the groups of an external provider to the user role and
+// teams memberships.
+type GroupsMapping struct {
+	// Roles are the roles of the groups, by group name.
+	Roles map[string]roles.Role
+	// Teams are the groups teams memberships.
+	Teams []TeamGroup
+}
+
+// ExternalUser is an user authenticated by an external provider.
+type ExternalUser struct {
+	// FirstName is the user first name.
+	FirstName string
+	// LastName is the user last name.
+	LastName string
+	// Email is the user email.
+	Email string
+	// Issuer is the provider that identifies the user by the subject.
+	Issuer string
+	// Subject is the immutable user identifier on the issuer. Is empty if the
+	// provider users are identified by the username.
+	Subject string
+	// Role is the highest role of the user groups. Is unknown if
+	// the user has no role.
+	Role roles.Role
+	// Teams are the user team roles by team ident, of all the mapped
+	// teams. The role is unknown if the user is not a member.
+	Teams map[string]roles.TeamRole
+}
+
+// Map sets the user role and teams of the groups.
+func (m GroupsMapping) Map(user *ExternalUser, groups []string) {
+	user.Role = roles.Unknown
+	user.Teams = make(map[string]roles.TeamRole, len(m.Teams))
+	for _, g := range m.Teams {
+		user.Teams[g.Team] = roles.TeamUnknown
+	}
+	for _, group := range groups {
+		if role := m.Roles[group]; role > user.Role {
+			user.Role = role
+		}
+		for _, g := range m.Teams {
+			if g.Group == group && g.Role > user.Teams[g.Team] {
+				user.Teams[g.Team] = g.Role
+			}
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials is returned when the username or password is wrong.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type LDAPConfig struct {
+	// URL is the server url.
+	URL string
+	// StartTLS is the use of StartTLS on "ldap://" urls.
+	StartTLS bool
+	// TLSSkipVerify is the skip of the server certificate verification.
+	TLSSkipVerify bool
+	// Timeout is the requests timeout.
+	Timeout time.Duration
+	// BindDN is the DN used to search the users.
+	BindDN string
+	// BindPassword is the bind DN password.
+	BindPassword string
+	// BaseDN is the DN where the users are searched.
+	BaseDN string
+	// UserFilter is the users search filter.
+	UserFilter string
+	// FirstNameAttribute is the user first name attribute.
+	FirstNameAttribute string
+	// LastNameAttribute is the user last name attribute.
+	LastNameAttribute string
+	// EmailAttribute is the user email attribute.
+	EmailAttribute string
+	// GroupAttribute is the user groups attribute.
+	GroupAttribute string
+	// Groups maps the groups, by DN in lower case, to the user role and teams.
+	Groups GroupsMapping
+}
+
+// LDAPUser is an user authenticated on the LDAP server.
+type LDAPUser struct {
+	ExternalUser
+	// DN is the user DN.
+	DN string
+}
+
+// LDAP is the LDAP authentication provider.
+type LDAP struct {
+	config LDAPConfig
+}
+
+// NewLDAP creates a new LDAP authentication provider.
+func NewLDAP(config LDAPConfig) *LDAP {
+	return &LDAP{config: config}
+}
+
+// LDAPConfigFromEnv returns the LDAP configuration of the enviroment. Returns
+// false if the LDAP authentication is disabled.
+func LDAPConfigFromEnv() (config LDAPConfig, enabled bool, err error) {
+	if env.LDAPURL == "" {
+		return config, false, nil
+	}
+	startTLS, err := strconv.ParseBool(env.LDAPStartTLS)
+	if err != nil {
+		return config, false, fmt.Errorf("fail to parse env.LDAPStartTLS, err: %s", err)
+	}
+	skipVerify, err := strconv.ParseBool(env.LDAPTLSSkipVerify)
+	if err != nil {
+		return config, false, fmt.Errorf("fail to parse env.LDAPTLSSkipVerify, err: %s", err)
+	}
+	timeout, err := strconv.Atoi(env.LDAPTimeout)
+	if err != nil {
+		return config, false, fmt.Errorf("fail to parse env.LDAPTimeout, err: %s", err)
+	}
+	rolesGroups, err := ParseLDAPRolesGroups(env.LDAPRolesGroups)
+	if err != nil {
+		return config, false, fmt.Errorf("fail to parse env.LDAPRolesGroups, err: %s", err)
+	}
+	teamsGroups, err := ParseLDAPTeamsGroups(env.LDAPTeamsGroups)
+	if err != nil {
+		return config, false, fmt.Errorf("fail to parse env.LDAPTeamsGroups, err: %s", err)
+	}
+	return LDAPConfig{
+		URL:                env.LDAPURL,
+		StartTLS:           startTLS,
+		TLSSkipVerify:      skipVerify,
+		Timeout:            time.Second * time.Duration(timeout),
+		BindDN:             env.LDAPBindDN,
+		BindPassword:       env.LDAPBindPassword,
+		BaseDN:             env.LDAPBaseDN,
+		UserFilter:         env.LDAPUserFilter,
+		FirstNameAttribute: env.LDAPFirstNameAttribute,
+		LastNameAttribute:  env.LDAPLastNameAttribute,
+		EmailAttribute:     env.LDAPEmailAttribute,
+		GroupAttribute:     env.LDAPGroupAttribute,
+		Groups:             GroupsMapping{Roles: rolesGroups, Teams: teamsGroups},
+	}, true, nil
+}
+
+// ParseLDAPRolesGroups parses the roles groups in the format "role:groupDN;role:groupDN".
+func ParseLDAPRolesGroups(s string) (rolesGroups map[string]roles.Role, err error) {
+	rolesGroups = make(map[string]roles.Role)
+	for _, item := range splitList(s) {
+		role, group, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid role group %q", item)
+		}
+		r, err := strconv.ParseUint(strings.TrimSpace(role), 10, 8)
+		if err != nil || !roles.ValidateRole(roles.Role(r)) {
+			return nil, fmt.Errorf("invalid role %q", role)
+		}
+		rolesGroups[normalizeDN(group)] = roles.Role(r)
+	}
+	return rolesGroups, nil
+}
+
+// ParseLDAPTeamsGroups parses the teams groups in the format
+// "teamIdent:teamRole:groupDN;teamIdent:teamRole:groupDN".
+func ParseLDAPTeamsGroups(s string) (teamsGroups []TeamGroup, err error) {
+	for _, item := range splitList(s) {
+		fields := strings.SplitN(item, ":", 3)
+		if len(fields) != 3 || strings.TrimSpace(fields[0]) == "" {
+			return nil, fmt.Errorf("invalid team group %q", item)
+		}
+		r, err := strconv.ParseUint(strings.TrimSpace(fields[1]), 10, 8)
+		if err != nil || !roles.ValidateTeamRole(roles.TeamRole(r)) {
+			return nil, fmt.Errorf("invalid team role %q", fields[1])
+		}
+		teamsGroups = append(teamsGroups, TeamGroup{
+			Group: normalizeDN(fields[2]),
+			Team:  strings.TrimSpace(fields[0]),
+			Role:  roles.TeamRole(r),
+		})
+	}
+	return teamsGroups, nil
+}
+
+func splitList(s string) (items []string) {
+	for _, item := range strings.Split(s, ";") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// NormalizeUsername returns the username in lower case and without surrounding
+// spaces, as the LDAP usernames are case insensitive.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// normalizeDN returns the DN in lower case and without spaces between the
+// components, so equal DNs can be compared.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(strings.TrimSpace(dn))
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(dn))
+	}
+	rdns := make([]string, len(parsed.RDNs))
+	for i, rdn := range parsed.RDNs {
+		attrs := make([]string, len(rdn.Attributes))
+		for j, attr := range rdn.Attributes {
+			attrs[j] = attr.Type + "=" + ldap.EscapeDN(attr.Value)
+		}
+		rdns[i] = strings.Join(attrs, "+")
+	}
+	return strings.ToLower(strings.Join(rdns, ","))
+}
+
+// Authenticate authenticates the user on the LDAP server and returns its
+// attributes, role and teams. Returns ErrInvalidCredentials if the user does
+// not exists or the password is wrong.
+func (l *LDAP) Authenticate(username string, password string) (user LDAPUser, err error) {
+	// an empty password is an unauthenticated bind, which succeeds on most servers
+	if username == "" || password == "" {
+		return user, ErrInvalidCredentials
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		return user, err
+	}
+	defer conn.Close()
+
+	if l.config.BindDN != "" {
+		err = conn.Bind(l.config.BindDN, l.config.BindPassword)
+		if err != nil {
+			return user, fmt.Errorf("fail to bind, err: %s", err)
+		}
+	}
+
+	attributes := []string{
+		l.config.FirstNameAttribute,
+		l.config.LastNameAttribute,
+		l.config.EmailAttribute,
+		l.config.GroupAttribute,
+	}
+	res, err := conn.Search(ldap.NewSearchRequest(
+		l.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		int(l.config.Timeout.Seconds()),
+		false,
+		fmt.Sprintf(l.config.UserFilter, ldap.EscapeFilter(username)),
+		attributes,
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return user, ErrInvalidCredentials
+		}
+		return user, fmt.Errorf("fail to search user, err: %s", err)
+	}
+	if len(res.Entries) != 1 {
+		return user, ErrInvalidCredentials
+	}
+	entry := res.Entries[0]
+
+	err = conn.Bind(entry.DN, password)
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return user, ErrInvalidCredentials
+		}
+		return user, fmt.Errorf("fail to bind user, err: %s", err)
+	}
+
+	user = LDAPUser{
+		DN: entry.DN,
+		ExternalUser: ExternalUser{
+			FirstName: entry.GetAttributeValue(l.config.FirstNameAttribute),
+			LastName:  entry.GetAttributeValue(l.config.LastNameAttribute),
+			Email:     entry.GetAttributeValue(l.config.EmailAttribute),
+		},
+	}
+	groups := entry.GetAttributeValues(l.config.GroupAttribute)
+	for i, group := range groups {
+		groups[i] = normalizeDN(group)
+	}
+	l.config.Groups.Map(&user.ExternalUser, groups)
+	return user, nil
+}
+
+func (l *LDAP) dial() (conn *ldap.Conn, err error) {
+	u, err := url.Parse(l.config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse url, err: %s", err)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: l.config.TLSSkipVerify,
+	}
+	conn, err = ldap.DialURL(l.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: l.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fail to dial, err: %s", err)
+	}
+	conn.SetTimeout(l.config.Timeout)
+
+	if l.config.StartTLS && u.Scheme == "ldap" {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("fail to start tls, err: %s", err)
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+type ldapTestEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// ldapTestServer is an in-process LDAP server that supports simple binds and
+// equality searches.
+type ldapTestServer struct {
+	ln      net.Listener
+	entries map[string]ldapTestEntry
+}
+
+func newLDAPTestServer(t *testing.T, entries ...ldapTestEntry) *ldapTestServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fail to listen, err: %s", err)
+	}
+	s := &ldapTestServer{ln: ln, entries: make(map[string]ldapTestEntry)}
+	for _, e := range entries {
+		s.entries[e.dn] = e
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *ldapTestServer) url() string {
+	return "ldap://" + s.ln.Addr().String()
+}
+
+func (s *ldapTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if e, ok := s.entries[dn]; ok && e.password == password {
+				code, bound = ldap.LDAPResultSuccess, dn
+			}
+			conn.Write(ldapTestMessage(id, ldapTestResult(ldap.ApplicationBindResponse, code)))
+		case ldap.ApplicationSearchRequest:
+			if bound == "" {
+				conn.Write(ldapTestMessage(id, ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights)))
+				continue
+			}
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for _, e := range s.entries {
+				for name, values := range e.attrs {
+					for _, v := range values {
+						if filter == "("+name+"="+ldap.EscapeFilter(v)+")" {
+							conn.Write(ldapTestMessage(id, ldapTestEntryPacket(e)))
+						}
+					}
+				}
+			}
+			conn.Write(ldapTestMessage(id, ldapTestResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)))
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func ldapTestMessage(id int64, op *ber.Packet) []byte {
+	p := ber.NewSequence("message")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "id"))
+	p.AppendChild(op)
+	return p.Bytes()
+}
+
+func ldapTestResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matched dn"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "message"))
+	return op
+}
+
+func ldapTestEntryPacket(e ldapTestEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "dn"))
+	attrs := ber.NewSequence("attributes")
+	for name, values := range e.attrs {
+		attr := ber.NewSequence("attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attr.AppendChild(set)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+	return op
+}
+
+func TestLDAPAuthenticate(t *testing.T) {
+	s := newLDAPTestServer(t,
+		ldapTestEntry{dn: "cn=svc,dc=example,dc=com", password: "svc"},
+		ldapTestEntry{
+			dn:       "uid=john,ou=users,dc=example,dc=com",
+			password: "john-pw",
+			attrs: map[string][]string{
+				"uid":       {"john"},
+				"givenName": {"John"},
+				"sn":        {"Doe"},
+				"mail":      {"john@example.com"},
+				"memberOf": {
+					"CN=Operators, OU=Groups, DC=example, DC=com",
+					"cn=admins,ou=groups,dc=example,dc=com",
+					"cn=noc,ou=groups,dc=example,dc=com",
+				},
+			},
+		},
+		ldapTestEntry{
+			dn:       "uid=mary,ou=users,dc=example,dc=com",
+			password: "mary-pw",
+			attrs:    map[string][]string{"uid": {"mary"}},
+		},
+	)
+	rolesGroups, err := ParseLDAPRolesGroups("1:cn=operators,ou=groups,dc=example,dc=com;3:cn=admins,ou=groups,dc=example,dc=com")
+	if err != nil {
+		t.Fatalf("fail to parse roles groups, err: %s", err)
+	}
+	teamsGroups, err := ParseLDAPTeamsGroups("noc:2:cn=noc,ou=groups,dc=example,dc=com;sales:1:cn=sales,ou=groups,dc=example,dc=com")
+	if err != nil {
+		t.Fatalf("fail to parse teams groups, err: %s", err)
+	}
+	l := NewLDAP(LDAPConfig{
+		URL:                s.url(),
+		Timeout:            time.Second * 5,
+		BindDN:             "cn=svc,dc=example,dc=com",
+		BindPassword:       "svc",
+		BaseDN:             "dc=example,dc=com",
+		UserFilter:         "(uid=%s)",
+		FirstNameAttribute: "givenName",
+		LastNameAttribute:  "sn",
+		EmailAttribute:     "mail",
+		GroupAttribute:     "memberOf",
+		Groups:             GroupsMapping{Roles: rolesGroups, Teams: teamsGroups},
+	})
+
+	user, err := l.Authenticate("john", "john-pw")
+	if err != nil {
+		t.Fatalf("fail to authenticate, err: %s", err)
+	}
+	want := LDAPUser{
+		DN: "uid=john,ou=users,dc=example,dc=com",
+		ExternalUser: ExternalUser{
+			FirstName: "John",
+			LastName:  "Doe",
+			Email:     "john@example.com",
+			Role:      roles.Admin,
+			Teams:     map[string]roles.TeamRole{"noc": roles.TeamOperator, "sales": roles.TeamUnknown},
+		},
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("Authenticate failed, want: %+v, got: %+v", want, user)
+	}
+
+	user, err = l.Authenticate("mary", "mary-pw")
+	if err != nil {
+		t.Fatalf("fail to authenticate, err: %s", err)
+	}
+	if user.Role != roles.Unknown {
+		t.Errorf("Authenticate without groups failed, want: %d, got: %d", roles.Unknown, user.Role)
+	}
+
+	tests := []struct{ username, password string }{
+		{"john", "wrong"},
+		{"john", ""},
+		{"unknown", "john-pw"},
+		{"*", "john-pw"},
+	}
+	for _, test := range tests {
+		_, err = l.Authenticate(test.username, test.password)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Authenticate(%q, %q) failed, want: %v, got: %v", test.username, test.password, ErrInvalidCredentials, err)
+		}
+	}
+
+	l.config.BindPassword = "wrong"
+	_, err = l.Authenticate("john", "john-pw")
+	if err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate with wrong bind password failed, got: %v", err)
+	}
+}
+
+func TestParseLDAPGroups(t *testing.T) {
+	tests := []struct {
+		roles   string
+		teams   string
+		invalid bool
+	}{
+		{roles: "", teams: ""},
+		{roles: "4:cn=masters,dc=example,dc=com; 2:cn=managers,dc=example,dc=com", teams: "noc:3:cn=noc,dc=example,dc=com"},
+		{roles: "5:cn=masters,dc=example,dc=com", invalid: true},
+		{roles: "cn=masters,dc=example,dc=com", invalid: true},
+		{teams: "noc:4:cn=noc,dc=example,dc=com", invalid: true},
+		{teams: "noc:cn=noc,dc=example,dc=com", invalid: true},
+		{teams: ":1:cn=noc,dc=example,dc=com", invalid: true},
+	}
+	for _, test := range tests {
+		_, rErr := ParseLDAPRolesGroups(test.roles)
+		_, tErr := ParseLDAPTeamsGroups(test.teams)
+		invalid := rErr != nil || tErr != nil
+		if invalid != test.invalid {
+			t.Errorf("ParseLDAPGroups(%q, %q) failed, want: %v, got: %v", test.roles, test.teams, test.invalid, invalid)
+		}
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	for _, username := range []string{"alice", "Alice", " ALICE "} {
+		if got := NormalizeUsername(username); got != "alice" {
+			t.Errorf("NormalizeUsername(%q) failed, want: %s, got: %s", username, "alice", got)
+		}
+	}
+}
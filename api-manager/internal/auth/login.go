@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/rdb"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// LoginChallengeTTL is the time that a login has to complete the second step.
+	LoginChallengeTTL = time.Minute * 5
+	// LoginChallengeAttempts is the number of wrong codes accepted before the
+	// login challenge is removed.
+	LoginChallengeAttempts = 5
+)
+
+// LoginChallenge is a login which password was checked, waiting for the
+// second step.
+type LoginChallenge struct {
+	// UserId is the user id.
+	UserId int32
+	// Role is the user role.
+	Role roles.Role
+	// Enrollment is true if the user must enroll on the TOTP before the
+	// session is created.
+	Enrollment bool
+}
+
+// NewLoginChallenge saves a login challenge and returns its token.
+func (a *Auth) NewLoginChallenge(ctx context.Context, c LoginChallenge) (token string, err error) {
+	token, err = NewURLToken()
+	if err != nil {
+		return "", err
+	}
+	key := rdb.AuthLoginChallengeKey(token)
+	p := a.rdb.TxPipeline()
+	p.HSet(ctx, key,
+		"user-id", c.UserId,
+		"role", c.Role,
+		"enrollment", c.Enrollment,
+		"attempts", 0,
+	)
+	p.Expire(ctx, key, LoginChallengeTTL)
+	_, err = p.Exec(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetLoginChallenge returns a login challenge.
+func (a *Auth) GetLoginChallenge(ctx context.Context, token string) (c LoginChallenge, exists bool, err error) {
+	values, err := a.rdb.HGetAll(ctx, rdb.AuthLoginChallengeKey(token)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return c, false, nil
+		}
+		return c, false, err
+	}
+	if len(values) == 0 {
+		return c, false, nil
+	}
+	userId, err := strconv.ParseInt(values["user-id"], 10, 32)
+	if err != nil {
+		return c, false, err
+	}
+	role, err := strconv.ParseUint(values["role"], 10, 8)
+	if err != nil {
+		return c, false, err
+	}
+	return LoginChallenge{
+		UserId:     int32(userId),
+		Role:       roles.Role(role),
+		Enrollment: values["enrollment"] == "1",
+	}, true, nil
+}
+
+// failLoginChallenge counts a wrong code of an existing login challenge and
+// removes it when the attempts are exhausted.
+var failLoginChallenge = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+if attempts >= tonumber(ARGV[1]) then
+	redis.call("DEL", KEYS[1])
+end
+return attempts
+`)
+
+// FailLoginChallenge counts a wrong code of the login challenge, removing it
+// when the attempts are exhausted.
+func (a *Auth) FailLoginChallenge(ctx context.Context, token string) error {
+	return failLoginChallenge.Run(ctx, a.rdb, []string{rdb.AuthLoginChallengeKey(token)}, LoginChallengeAttempts).Err()
+}
+
+// RemoveLoginChallenge removes a login challenge. Returns false if the
+// challenge does not exists, so each challenge is completed once.
+func (a *Auth) RemoveLoginChallenge(ctx context.Context, token string) (exists bool, err error) {
+	n, err := a.rdb.Del(ctx, rdb.AuthLoginChallengeKey(token)).Result()
+	return n != 0, err
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/rdb"
+	"github.com/go-redis/redis/v8"
+	"golang.org/x/oauth2"
+)
+
+// OIDCStateTTL is the time that a login has to be completed on the provider.
+const OIDCStateTTL = time.Minute * 10
+
+// OIDCStateCookieName is the name of the cookie that binds the login state to
+// the browser that started the login, holding the state hash.
+const OIDCStateCookieName = "oidc_state"
+
+// oidcTimeout is the timeout of the requests to the providers.
+const oidcTimeout = time.Second * 10
+
+// OIDCState is the state of an authorization request, saved until the callback.
+type OIDCState struct {
+	// Provider is the provider ident.
+	Provider string `json:"provider"`
+	// Verifier is the PKCE code verifier.
+	Verifier string `json:"verifier"`
+	// Nonce is the id token nonce.
+	Nonce string `json:"nonce"`
+	// Redirect is the path where the user is redirected after the login.
+	Redirect string `json:"redirect"`
+}
+
+// SaveOIDCState saves the state of an authorization request.
+func (a *Auth) SaveOIDCState(ctx context.Context, state string, s OIDCState) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return a.rdb.Set(ctx, rdb.AuthOIDCStateKey(state), b, OIDCStateTTL).Err()
+}
+
+// PopOIDCState returns and removes the state of an authorization request, so
+// each state is used once.
+func (a *Auth) PopOIDCState(ctx context.Context, state string) (s OIDCState, exists bool, err error) {
+	p := a.rdb.TxPipeline()
+	get := p.Get(ctx, rdb.AuthOIDCStateKey(state))
+	p.Del(ctx, rdb.AuthOIDCStateKey(state))
+	_, err = p.Exec(ctx)
+	if err != nil {
+		if err == redis.Nil {
+			return s, false, nil
+		}
+		return s, false, err
+	}
+	b, err := get.Bytes()
+	if err != nil {
+		return s, false, err
+	}
+	return s, true, json.Unmarshal(b, &s)
+}
+
+// NewURLToken returns a securely generated random string, safe to be used on urls.
+func NewURLToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OIDCStateHash returns the hash of the state, saved on the state cookie.
+func OIDCStateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PKCEChallenge returns the S256 code challenge of the PKCE code verifier.
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// OIDC is an OpenID Connect provider client.
+type OIDC struct {
+	config   models.OIDCProvider
+	oauth2   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	groups   GroupsMapping
+}
+
+// NewOIDC creates a new OpenID Connect provider client, reading the provider
+// discovery document.
+func NewOIDC(ctx context.Context, config models.OIDCProvider) (*OIDC, error) {
+	ctx = oidc.ClientContext(ctx, &http.Client{Timeout: oidcTimeout})
+	provider, err := oidc.NewProvider(ctx, config.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("fail to discover provider, err: %s", err)
+	}
+
+	groups := GroupsMapping{Roles: make(map[string]uint8, len(config.RolesGroups))}
+	for _, g := range config.RolesGroups {
+		if g.Role > groups.Roles[g.Group] {
+			groups.Roles[g.Group] = g.Role
+		}
+	}
+	for _, g := range config.TeamsGroups {
+		groups.Teams = append(groups.Teams, TeamGroup{Group: g.Group, Team: g.Team, Role: g.Role})
+	}
+
+	return &OIDC{
+		config: config,
+		oauth2: oauth2.Config{
+			ClientID:     config.ClientId,
+			ClientSecret: config.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  config.RedirectURL,
+			Scopes:       append([]string{oidc.ScopeOpenID}, config.Scopes...),
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: config.ClientId}),
+		groups:   groups,
+	}, nil
+}
+
+// AuthCodeURL returns the provider url where the user logins.
+func (o *OIDC) AuthCodeURL(state string, nonce string, verifier string) string {
+	return o.oauth2.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", PKCEChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+}
+
+// Exchange exchanges the authorization code for the id token and returns the
+// user of the id token claims. The user issuer and subject are the id token
+// ones, which identify the user on the provider. Returns ErrInvalidCredentials if the code or the
+// id token is invalid.
+func (o *OIDC) Exchange(ctx context.Context, code string, verifier string, nonce string) (username string, user ExternalUser, err error) {
+	ctx = oidc.ClientContext(ctx, &http.Client{Timeout: oidcTimeout})
+	token, err := o.oauth2.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
+		var rErr *oauth2.RetrieveError
+		if errors.As(err, &rErr) && rErr.Response.StatusCode < 500 {
+			return "", user, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+		}
+		return "", user, fmt.Errorf("fail to exchange code, err: %s", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", user, fmt.Errorf("%w: missing id token", ErrInvalidCredentials)
+	}
+	idToken, err := o.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", user, fmt.Errorf("%w: %s", ErrInvalidCredentials, err)
+	}
+	if idToken.Nonce != nonce {
+		return "", user, fmt.Errorf("%w: invalid nonce", ErrInvalidCredentials)
+	}
+	if idToken.Subject == "" || len(idToken.Subject) > 255 || len(idToken.Issuer) > 255 {
+		return "", user, fmt.Errorf("%w: invalid subject %q", ErrInvalidCredentials, idToken.Subject)
+	}
+
+	var claims map[string]any
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return "", user, fmt.Errorf("fail to read claims, err: %s", err)
+	}
+	username = stringClaim(claims, o.config.UsernameClaim)
+	if len(username) < 3 || len(username) > 50 {
+		return "", user, fmt.Errorf("%w: invalid username claim %q", ErrInvalidCredentials, username)
+	}
+	user = ExternalUser{
+		FirstName: stringClaim(claims, "given_name"),
+		LastName:  stringClaim(claims, "family_name"),
+		Email:     stringClaim(claims, "email"),
+		Issuer:    idToken.Issuer,
+		Subject:   idToken.Subject,
+	}
+	o.groups.Map(&user, stringsClaim(claims, o.config.GroupsClaim))
+	return username, user, nil
+}
+
+func stringClaim(claims map[string]any, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+// stringsClaim returns the claim strings. The claim may be a string or an
+// array of strings.
+func stringsClaim(claims map[string]any, name string) (values []string) {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/go-jose/go-jose/v3"
+)
+
+type oidcTestCode struct {
+	challenge string
+	nonce     string
+}
+
+// oidcTestProvider is a local OpenID Connect provider that supports the
+// authorization code flow with PKCE.
+type oidcTestProvider struct {
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]any
+
+	mu    sync.Mutex
+	codes map[string]oidcTestCode
+}
+
+func newOIDCTestProvider(t *testing.T, claims map[string]any) *oidcTestProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("fail to generate key, err: %s", err)
+	}
+	p := &oidcTestProvider{key: key, claims: claims, codes: make(map[string]oidcTestCode)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                p.srv.URL,
+			"authorization_endpoint":                p.srv.URL + "/authorize",
+			"token_endpoint":                        p.srv.URL + "/token",
+			"jwks_uri":                              p.srv.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+			http.Error(w, "missing code challenge", http.StatusBadRequest)
+			return
+		}
+		code, _ := NewURLToken()
+		p.mu.Lock()
+		p.codes[code] = oidcTestCode{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+		p.mu.Unlock()
+
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		v := redirect.Query()
+		v.Set("code", code)
+		v.Set("state", q.Get("state"))
+		redirect.RawQuery = v.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		p.mu.Lock()
+		code, ok := p.codes[r.Form.Get("code")]
+		delete(p.codes, r.Form.Get("code"))
+		p.mu.Unlock()
+		if !ok || PKCEChallenge(r.Form.Get("code_verifier")) != code.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		claims := map[string]any{
+			"iss":   p.srv.URL,
+			"sub":   "1",
+			"aud":   "nemesys",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": code.nonce,
+		}
+		for k, v := range p.claims {
+			claims[k] = v
+		}
+		payload, _ := json.Marshal(claims)
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "test"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		obj, err := signer.Sign(payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		idToken, _ := obj.CompactSerialize()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   60,
+			"id_token":     idToken,
+		})
+	})
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+// authorize follows the authorization url and returns the code and state of
+// the redirect.
+func (p *oidcTestProvider) authorize(t *testing.T, authURL string) (code string, state string) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("fail to authorize, err: %s", err)
+	}
+	res.Body.Close()
+	location, err := res.Location()
+	if err != nil {
+		t.Fatalf("fail to get authorization redirect, status: %d, err: %s", res.StatusCode, err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestOIDCExchange(t *testing.T) {
+	p := newOIDCTestProvider(t, map[string]any{
+		"preferred_username": "john",
+		"given_name":         "John",
+		"family_name":        "Doe",
+		"email":              "john@example.com",
+		"groups":             []string{"viewers", "admins", "noc"},
+	})
+	ctx := context.Background()
+	client, err := NewOIDC(ctx, models.OIDCProvider{
+		Ident:         "idp",
+		Issuer:        p.srv.URL,
+		ClientId:      "nemesys",
+		ClientSecret:  "secret",
+		RedirectURL:   "http://localhost/api/v1/login/oidc/idp/callback",
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "groups",
+		RolesGroups: []models.OIDCRoleGroup{
+			{Group: "viewers", Role: roles.Viewer},
+			{Group: "admins", Role: roles.Admin},
+		},
+		TeamsGroups: []models.OIDCTeamGroup{
+			{Group: "noc", Team: "noc", Role: roles.TeamAdmin},
+			{Group: "sales", Team: "sales", Role: roles.TeamViewer},
+		},
+	})
+	if err != nil {
+		t.Fatalf("fail to create client, err: %s", err)
+	}
+
+	code, state := p.authorize(t, client.AuthCodeURL("state", "nonce", "verifier"))
+	if state != "state" {
+		t.Errorf("AuthCodeURL state failed, want: %s, got: %s", "state", state)
+	}
+	username, user, err := client.Exchange(ctx, code, "verifier", "nonce")
+	if err != nil {
+		t.Fatalf("fail to exchange code, err: %s", err)
+	}
+	if username != "john" {
+		t.Errorf("Exchange username failed, want: %s, got: %s", "john", username)
+	}
+	want := ExternalUser{
+		FirstName: "John",
+		LastName:  "Doe",
+		Email:     "john@example.com",
+		Issuer:    p.srv.URL,
+		Subject:   "1",
+		Role:      roles.Admin,
+		Teams:     map[string]roles.TeamRole{"noc": roles.TeamAdmin, "sales": roles.TeamUnknown},
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("Exchange failed, want: %+v, got: %+v", want, user)
+	}
+
+	// the code is used once
+	_, _, err = client.Exchange(ctx, code, "verifier", "nonce")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Exchange with used code failed, want: %v, got: %v", ErrInvalidCredentials, err)
+	}
+
+	code, _ = p.authorize(t, client.AuthCodeURL("state", "nonce", "verifier"))
+	_, _, err = client.Exchange(ctx, code, "wrong", "nonce")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Exchange with wrong verifier failed, want: %v, got: %v", ErrInvalidCredentials, err)
+	}
+
+	code, _ = p.authorize(t, client.AuthCodeURL("state", "nonce", "verifier"))
+	_, _, err = client.Exchange(ctx, code, "verifier", "wrong")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Exchange with wrong nonce failed, want: %v, got: %v", ErrInvalidCredentials, err)
+	}
+}
+
+func TestStringsClaim(t *testing.T) {
+	claims := map[string]any{
+		"string": "a",
+		"array":  []any{"a", 1.0, "b"},
+		"number": 1.0,
+	}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"string", []string{"a"}},
+		{"array", []string{"a", "b"}},
+		{"number", nil},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		got := stringsClaim(claims, test.name)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("stringsClaim(%q) failed, want: %v, got: %v", test.name, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/shared/rdb"
+	"github.com/go-redis/redis/v8"
+)
+
+// NewPasswordReset creates a password reset token for the user and removes
+// the previous one.
+func (a *Auth) NewPasswordReset(ctx context.Context, userId int32) (token string, err error) {
+	err = a.RemovePasswordReset(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	token, err = NewURLToken()
+	if err != nil {
+		return "", err
+	}
+	p := a.rdb.TxPipeline()
+	p.Set(ctx, rdb.AuthPasswordResetKey(token), userId, a.PasswordResetTTL)
+	p.Set(ctx, rdb.AuthReversePasswordResetKey(userId), token, a.PasswordResetTTL)
+	_, err = p.Exec(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// PopPasswordReset returns the user of the password reset token and removes
+// the token, so each token is used once.
+func (a *Auth) PopPasswordReset(ctx context.Context, token string) (userId int32, exists bool, err error) {
+	p := a.rdb.TxPipeline()
+	get := p.Get(ctx, rdb.AuthPasswordResetKey(token))
+	p.Del(ctx, rdb.AuthPasswordResetKey(token))
+	_, err = p.Exec(ctx)
+	if err != nil {
+		if err == redis.Nil {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	id, err := strconv.ParseInt(get.Val(), 10, 32)
+	if err != nil {
+		return 0, false, err
+	}
+	return int32(id), true, a.rdb.Del(ctx, rdb.AuthReversePasswordResetKey(int32(id))).Err()
+}
+
+// RemovePasswordReset removes the user password reset token, if exists.
+func (a *Auth) RemovePasswordReset(ctx context.Context, userId int32) error {
+	token, err := a.rdb.Get(ctx, rdb.AuthReversePasswordResetKey(userId)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+		return err
+	}
+	return a.rdb.Del(ctx, rdb.AuthPasswordResetKey(token), rdb.AuthReversePasswordResetKey(userId)).Err()
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// ScopeRequest is a request checked against the API Key scopes.
+type ScopeRequest struct {
+	// Method is the request method.
+	Method string
+	// Route is the matched route without the routes prefix, as
+	// "/teams/:teamId/ctx".
+	Route string
+	// TeamId is the "teamId" route param, zero if missing.
+	TeamId int32
+	// ContainerId is the "containerId" route param, zero if missing.
+	ContainerId int32
+}
+
+// historyRoutes are the routes allowed by the "history" permission.
+var historyRoutes = map[string]bool{
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data/history": true,
+	"GET /teams/:teamId/ctx/:ctxId/data/export":                       true,
+}
+
+// ingestRoutes are the routes allowed by the "ingest" permission.
+var ingestRoutes = map[string]bool{
+	"POST /metrics/data":              true,
+	"POST /metrics/remote-write":      true,
+	"POST /metrics/data/import":       true,
+	"GET /metrics/data/import/:jobId": true,
+}
+
+// ValidScope returns false if the scope has a restriction that doesn't apply
+// to its permission.
+func ValidScope(s models.APIKeyScope) bool {
+	switch s.Permission {
+	case models.APIKeyPermissionRead:
+		return true
+	case models.APIKeyPermissionHistory:
+		return s.ContainerId == 0
+	case models.APIKeyPermissionIngest:
+		return s.TeamId == 0
+	}
+	return false
+}
+
+// ScopesAllow returns true if any scope allows the request. API Keys without
+// scopes have full access. The container restriction of the "ingest" scopes is
+// not checked, as the container is known only by the metric, see
+// ScopesAllowIngest.
+func ScopesAllow(scopes []models.APIKeyScope, r ScopeRequest) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	route := r.Method + " " + r.Route
+	for _, s := range scopes {
+		switch s.Permission {
+		case models.APIKeyPermissionRead:
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				continue
+			}
+		case models.APIKeyPermissionHistory:
+			if !historyRoutes[route] {
+				continue
+			}
+		case models.APIKeyPermissionIngest:
+			if ingestRoutes[route] {
+				return true
+			}
+			continue
+		default:
+			continue
+		}
+		if s.TeamId != 0 && s.TeamId != r.TeamId {
+			continue
+		}
+		if s.ContainerId != 0 && s.ContainerId != r.ContainerId {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// ScopesAllowIngest returns true if the scopes allow ingesting metric data on
+// the container.
+func ScopesAllowIngest(scopes []models.APIKeyScope, containerId int32) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, s := range scopes {
+		if s.Permission != models.APIKeyPermissionIngest {
+			continue
+		}
+		if s.ContainerId == 0 || s.ContainerId == containerId {
+			return true
+		}
+	}
+	return false
+}
+
+// IPAllowed returns true if the ip matches any of the allowed ips and cidrs.
+// An empty list allows any ip.
+func IPAllowed(allowed []string, ip string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, a := range allowed {
+		_, network, err := net.ParseCIDR(a)
+		if err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if allowedAddr := net.ParseIP(a); allowedAddr != nil && allowedAddr.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
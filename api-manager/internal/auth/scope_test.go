@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestScopesAllow(t *testing.T) {
+	history := ScopeRequest{Method: "GET", Route: "/teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data/history", TeamId: 3}
+	ingest := ScopeRequest{Method: "POST", Route: "/metrics/data"}
+	container := ScopeRequest{Method: "GET", Route: "/containers/basics/:containerId", ContainerId: 12}
+	update := ScopeRequest{Method: "PATCH", Route: "/containers/basics/:containerId", ContainerId: 12}
+
+	tests := []struct {
+		name   string
+		scopes []models.APIKeyScope
+		req    ScopeRequest
+		want   bool
+	}{
+		{"no scopes", nil, update, true},
+		{"read get", []models.APIKeyScope{{Permission: "read"}}, container, true},
+		{"read patch", []models.APIKeyScope{{Permission: "read"}}, update, false},
+		{"read post ingest", []models.APIKeyScope{{Permission: "read"}}, ingest, false},
+		{"read container", []models.APIKeyScope{{Permission: "read", ContainerId: 12}}, container, true},
+		{"read other container", []models.APIKeyScope{{Permission: "read", ContainerId: 13}}, container, false},
+		{"read team on container route", []models.APIKeyScope{{Permission: "read", TeamId: 3}}, container, false},
+		{"history team", []models.APIKeyScope{{Permission: "history", TeamId: 3}}, history, true},
+		{"history other team", []models.APIKeyScope{{Permission: "history", TeamId: 4}}, history, false},
+		{"history on container route", []models.APIKeyScope{{Permission: "history"}}, container, false},
+		{"ingest", []models.APIKeyScope{{Permission: "ingest", ContainerId: 12}}, ingest, true},
+		{"ingest on history", []models.APIKeyScope{{Permission: "ingest"}}, history, false},
+		{"any scope", []models.APIKeyScope{{Permission: "ingest"}, {Permission: "history", TeamId: 3}}, history, true},
+	}
+	for _, test := range tests {
+		if got := ScopesAllow(test.scopes, test.req); got != test.want {
+			t.Errorf("ScopesAllow %s failed, want: %v, got: %v", test.name, test.want, got)
+		}
+	}
+}
+
+func TestScopesAllowIngest(t *testing.T) {
+	scopes := []models.APIKeyScope{{Permission: "read"}, {Permission: "ingest", ContainerId: 12}}
+	if !ScopesAllowIngest(scopes, 12) {
+		t.Error("ScopesAllowIngest of allowed container failed, want: true, got: false")
+	}
+	if ScopesAllowIngest(scopes, 13) {
+		t.Error("ScopesAllowIngest of other container failed, want: false, got: true")
+	}
+	if ScopesAllowIngest([]models.APIKeyScope{{Permission: "read"}}, 12) {
+		t.Error("ScopesAllowIngest of read scope failed, want: false, got: true")
+	}
+	if !ScopesAllowIngest(nil, 12) {
+		t.Error("ScopesAllowIngest without scopes failed, want: true, got: false")
+	}
+}
+
+func TestIPAllowed(t *testing.T) {
+	allowed := []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.1.10", true},
+		{"192.168.1.11", false},
+		{"2001:db8::1", true},
+		{"invalid", false},
+	}
+	for _, test := range tests {
+		if got := IPAllowed(allowed, test.ip); got != test.want {
+			t.Errorf("IPAllowed %s failed, want: %v, got: %v", test.ip, test.want, got)
+		}
+	}
+	if !IPAllowed(nil, "1.1.1.1") {
+		t.Error("IPAllowed without allowlist failed, want: true, got: false")
+	}
+}
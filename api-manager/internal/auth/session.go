@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/rdb"
 	"github.com/go-redis/redis/v8"
 )
@@ -16,6 +17,12 @@ const SessionCookieName = "sess"
 type SessionMeta struct {
 	UserId int32
 	Role   roles.Role
+	// APIKeyId is the API Key id, if the client is authenticated by an API Key.
+	// Is not saved in the session.
+	APIKeyId int32
+	// Scopes are the API Key scopes, empty for full access. Is not saved
+	// in the session.
+	Scopes []models.APIKeyScope
 }
 
 func (m *SessionMeta) Bytes() []byte {
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/rdb"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// TOTPIssuer is the issuer shown on the authenticator apps.
+	TOTPIssuer = "Nemesys"
+	// TOTPPeriod is the time step of the codes.
+	TOTPPeriod = time.Second * 30
+	// TOTPDigits is the number of digits of the codes.
+	TOTPDigits = 6
+	// TOTPSkew is the number of time steps accepted before and after the
+	// current one, to tolerate clock drift.
+	TOTPSkew = 1
+	// RecoveryCodesCount is the number of recovery codes generated.
+	RecoveryCodesCount = 10
+	// TOTPMaxFailures is the number of wrong codes of a user before its codes
+	// are locked out.
+	TOTPMaxFailures = 10
+	// TOTPLockout is the time that the wrong codes of a user are counted after
+	// the last one, so a locked out user waits it before trying again.
+	TOTPLockout = time.Minute * 15
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret returns a new base32 encoded TOTP secret.
+func NewTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the provisioning uri of the secret, which is encoded on the
+// QR code read by the authenticator apps.
+func TOTPURI(account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", TOTPIssuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", strconv.Itoa(TOTPDigits))
+	v.Set("period", strconv.Itoa(int(TOTPPeriod.Seconds())))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + TOTPIssuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// TOTPCode returns the code of the secret at the time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("fail to decode secret, err: %s", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	h := hmac.New(sha1.New, key)
+	h.Write(counter[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	code := strconv.FormatUint(uint64(value%uint32(math.Pow10(TOTPDigits))), 10)
+	return strings.Repeat("0", TOTPDigits-len(code)) + code, nil
+}
+
+// TOTPStep returns the time step of the time.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod.Seconds())
+}
+
+// ValidateTOTP validates the code of the secret at the time, and returns the
+// matched time step.
+func ValidateTOTP(secret string, code string, t time.Time) (step int64, ok bool) {
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+	current := TOTPStep(t)
+	for s := current - TOTPSkew; s <= current+TOTPSkew; s++ {
+		expected, err := TOTPCode(secret, s)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+// UseTOTPStep marks the user time step as used. Returns false if the step was
+// already used, so each code is accepted once.
+func (a *Auth) UseTOTPStep(ctx context.Context, userId int32, step int64) (ok bool, err error) {
+	return a.rdb.SetNX(ctx, rdb.AuthTOTPStepKey(userId, step), 1, TOTPPeriod*(2*TOTPSkew+1)).Result()
+}
+
+// TOTPLocked returns true if the user has TOTPMaxFailures wrong codes, so its
+// codes must not be checked until the lockout expires.
+func (a *Auth) TOTPLocked(ctx context.Context, userId int32) (locked bool, err error) {
+	n, err := a.rdb.Get(ctx, rdb.AuthTOTPFailuresKey(userId)).Int()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, err
+	}
+	return n >= TOTPMaxFailures, nil
+}
+
+// FailTOTP counts a wrong code of the user, across all its logins and
+// sessions. The count expires TOTPLockout after the last wrong code.
+func (a *Auth) FailTOTP(ctx context.Context, userId int32) error {
+	key := rdb.AuthTOTPFailuresKey(userId)
+	p := a.rdb.TxPipeline()
+	p.Incr(ctx, key)
+	p.Expire(ctx, key, TOTPLockout)
+	_, err := p.Exec(ctx)
+	return err
+}
+
+// ResetTOTPFailures removes the user wrong codes count.
+func (a *Auth) ResetTOTPFailures(ctx context.Context, userId int32) error {
+	return a.rdb.Del(ctx, rdb.AuthTOTPFailuresKey(userId)).Err()
+}
+
+// NewRecoveryCodes returns new recovery codes, in the format "xxxxx-xxxxx".
+func NewRecoveryCodes() (codes []string, err error) {
+	codes = make([]string, RecoveryCodesCount)
+	b := make([]byte, 7)
+	for i := range codes {
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(totpEncoding.EncodeToString(b))[:10]
+		codes[i] = s[:5] + "-" + s[5:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hash of the recovery code. The code is
+// normalized, so it is accepted in upper case and without the dash.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
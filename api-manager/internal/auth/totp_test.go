@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 secret of the RFC 6238 test vectors, "12345678901234567890".
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		time int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, test := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(test.time, 0)))
+		if err != nil {
+			t.Fatalf("fail to get code, err: %s", err)
+		}
+		if got != test.want {
+			t.Errorf("TOTPCode at %d failed, want: %s, got: %s", test.time, test.want, got)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPStep(now)
+	tests := []struct {
+		step int64
+		want bool
+	}{
+		{current, true},
+		{current - 1, true},
+		{current + 1, true},
+		{current - 2, false},
+		{current + 2, false},
+	}
+	for _, test := range tests {
+		code, err := TOTPCode(rfc6238Secret, test.step)
+		if err != nil {
+			t.Fatalf("fail to get code, err: %s", err)
+		}
+		step, ok := ValidateTOTP(rfc6238Secret, code, now)
+		if ok != test.want {
+			t.Errorf("ValidateTOTP of step %d failed, want: %v, got: %v", test.step, test.want, ok)
+		}
+		if ok && step != test.step {
+			t.Errorf("ValidateTOTP step failed, want: %d, got: %d", test.step, step)
+		}
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "0059", now); ok {
+		t.Error("ValidateTOTP of short code failed, want: false, got: true")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("fail to create secret, err: %s", err)
+	}
+	u, err := url.Parse(TOTPURI("john", secret))
+	if err != nil {
+		t.Fatalf("fail to parse uri, err: %s", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/"+TOTPIssuer+":john" {
+		t.Errorf("TOTPURI failed, want: %s, got: %s", "otpauth://totp/"+TOTPIssuer+":john", u.Scheme+"://"+u.Host+u.Path)
+	}
+	if got := u.Query().Get("secret"); got != secret {
+		t.Errorf("TOTPURI secret failed, want: %s, got: %s", secret, got)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("fail to create recovery codes, err: %s", err)
+	}
+	if len(codes) != RecoveryCodesCount {
+		t.Errorf("NewRecoveryCodes count failed, want: %d, got: %d", RecoveryCodesCount, len(codes))
+	}
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("invalid recovery code format, got: %s", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicated recovery code, got: %s", code)
+		}
+		seen[code] = true
+	}
+
+	want := HashRecoveryCode("abcde-fghij")
+	for _, code := range []string{"ABCDE-FGHIJ", "abcdefghij", " abcde fghij "} {
+		if got := HashRecoveryCode(code); got != want {
+			t.Errorf("HashRecoveryCode(%q) failed, want: %s, got: %s", code, want, got)
+		}
+	}
+}
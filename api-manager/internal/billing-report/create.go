@@ -0,0 +1,108 @@
+package billingreport
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/labels"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a new billing report of a context month, computing the 95th and 99th
+// percentiles, average, peak and volume of each contextual metric and of the sum
+// of all contextual metrics, and saves it. The contextual metrics are selected by
+// id or by label selector.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If month is in the future.
+//   - 400 If a contextual metric is not numeric.
+//   - 400 If tier does not exists on a metric data policy.
+//   - 404 If a contextual metric is not found.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var r models.BillingReport
+		err = c.ShouldBind(&r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		if r.Interval == 0 {
+			r.Interval = defaultInterval
+		}
+		r.ContextId = int32(ctxId)
+
+		var metadata []models.ContextualMetricMetadata
+		if r.Selector != "" {
+			selector, _ := labels.Parse(r.Selector)
+			metadata, err = api.PG.GetContextualMetricsMetadataBySelector(ctx, r.ContextId, selector, maxReportMetrics)
+		} else {
+			metadata, err = api.PG.GetContextualMetricsMetadata(ctx, r.ContextId, r.ContextualMetricsIds, maxReportMetrics)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get contextual metrics metadata", logger.ErrField(err))
+			return
+		}
+		if len(metadata) != len(r.ContextualMetricsIds) && len(r.ContextualMetricsIds) != 0 {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextualMetricNotFound))
+			return
+		}
+
+		err = generate(ctx, api, &r, metadata)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			switch err {
+			case ErrInvalidMonth:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidReportMonth))
+			case ErrNonNumericMetric:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidMetricType))
+			case ErrInvalidReportTier:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidReportTier))
+			default:
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to generate billing report", logger.ErrField(err))
+			}
+			return
+		}
+		r.CreatedAt = time.Now().Unix()
+
+		r.Id, err = api.PG.CreateBillingReport(ctx, r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create billing report", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Billing report created, id: " + strconv.FormatInt(r.Id, 10))
+
+		c.JSON(http.StatusOK, tools.DataRes(r))
+	}
+}
@@ -0,0 +1,51 @@
+package billingreport
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Deletes a billing report.
+// Responses:
+//   - 404 If not found.
+//   - 200 If succeeded.
+func DeleteHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		rawId := c.Param("reportId")
+		id, err := strconv.ParseInt(rawId, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.DeleteBillingReport(ctx, int32(ctxId), id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to delete billing report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgBillingReportNotFound))
+			return
+		}
+		api.Log.Info("Billing report deleted, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
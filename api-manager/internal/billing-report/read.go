@@ -0,0 +1,161 @@
+package billingreport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+var reportCSVHeader = []string{"contextual_metric_id", "contextual_metric_ident", "contextual_metric_name",
+	"samples", "p95", "p99", "avg", "peak", "volume"}
+
+// Get multi billing reports of a context, newest first.
+// Params:
+//   - "limit" Limit of reports returned. Default is 30, max is 30, min is 1.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		reports, err := api.PG.GetBillingReports(ctx, int32(ctxId), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get billing reports", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(reports))
+	}
+}
+
+// Get a billing report.
+// Params:
+//   - "format" Response format, "json" or "csv". The csv is downloaded as a file,
+//     with the total on the last row. Default is "json".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func GetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		id, err := strconv.ParseInt(c.Param("reportId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, r, err := api.PG.GetBillingReport(ctx, int32(ctxId), id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get billing report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgBillingReportNotFound))
+			return
+		}
+
+		if format == "json" {
+			c.JSON(http.StatusOK, tools.DataRes(r))
+			return
+		}
+
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="billing-report-%d-%s-%d.csv"`, r.ContextId, r.Month, r.Id))
+		c.Status(http.StatusOK)
+
+		err = writeReportCSV(csv.NewWriter(c.Writer), r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			api.Log.Error("Fail to write billing report csv", logger.ErrField(err))
+		}
+	}
+}
+
+// writeReportCSV writes the report as csv, one row per contextual metric
+// and the total on the last row.
+func writeReportCSV(w *csv.Writer, r models.BillingReport) error {
+	err := w.Write(reportCSVHeader)
+	if err != nil {
+		return err
+	}
+	for _, m := range r.Metrics {
+		err = w.Write(getStatsRecord(strconv.FormatInt(m.ContextualMetricId, 10), m.ContextualMetricIdent, m.ContextualMetricName, m.Stats))
+		if err != nil {
+			return err
+		}
+	}
+	err = w.Write(getStatsRecord("", "total", "Total", r.Total))
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// getStatsRecord returns the csv record of the statistics.
+func getStatsRecord(id string, ident string, name string, s models.BillingReportStats) []string {
+	return []string{
+		id,
+		ident,
+		name,
+		strconv.FormatInt(s.Samples, 10),
+		strconv.FormatFloat(s.P95, 'f', -1, 64),
+		strconv.FormatFloat(s.P99, 'f', -1, 64),
+		strconv.FormatFloat(s.Avg, 'f', -1, 64),
+		strconv.FormatFloat(s.Peak, 'f', -1, 64),
+		strconv.FormatFloat(s.Volume, 'f', -1, 64),
+	}
+}
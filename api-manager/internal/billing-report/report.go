@@ -0,0 +1,155 @@
+package billingreport
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+// defaultInterval is the default sample interval in seconds.
+const defaultInterval = 300
+
+// maxReportMetrics is the maximum number of contextual metrics in a report.
+const maxReportMetrics = 100
+
+var (
+	ErrInvalidMonth      = errors.New("invalid report month")
+	ErrNonNumericMetric  = errors.New("contextual metric is not numeric")
+	ErrInvalidReportTier = errors.New("invalid report data policy tier")
+)
+
+// getMonthRange returns the month range in unix seconds. The stop is limited
+// to now, so the current month can be reported partially.
+func getMonthRange(month string, now time.Time) (start int64, stop int64, err error) {
+	t, err := time.ParseInLocation("2006-01", month, time.UTC)
+	if err != nil {
+		return 0, 0, ErrInvalidMonth
+	}
+	start = t.Unix()
+	stop = t.AddDate(0, 1, 0).Unix()
+	if start >= now.Unix() {
+		return 0, 0, ErrInvalidMonth
+	}
+	if stop > now.Unix() {
+		stop = now.Unix()
+	}
+	return start, stop, nil
+}
+
+// generate computes the report statistics of each contextual metric and of
+// the sum of all contextual metrics.
+func generate(ctx context.Context, api *api.API, r *models.BillingReport, metadata []models.ContextualMetricMetadata) (err error) {
+	start, stop, err := getMonthRange(r.Month, time.Now())
+	if err != nil {
+		return err
+	}
+
+	r.Metrics = make([]models.BillingReportMetric, len(metadata))
+	r.ContextualMetricsIds = make([]int64, len(metadata))
+	totals := make(map[int64]float64)
+	for i, m := range metadata {
+		if m.MetricRequest.MetricType == types.MTString {
+			return ErrNonNumericMetric
+		}
+
+		samples := make([]float64, 0)
+		err = api.Storage.QueryStream(ctx, storage.QueryOptions{
+			Start:        start,
+			Stop:         stop,
+			DataPolicyId: m.MetricRequest.DataPolicyId,
+			MetricId:     m.MetricRequest.MetricId,
+			MetricType:   m.MetricRequest.MetricType,
+			Step:         int64(r.Interval),
+			UseTier:      true,
+			Tier:         r.Tier,
+		}, func(timestamp time.Time, value any) error {
+			v, ok := toFloat(value)
+			if !ok {
+				return nil
+			}
+			samples = append(samples, v)
+			totals[timestamp.Unix()] += v
+			return nil
+		})
+		if err != nil {
+			if err == storage.ErrInvalidQueryOptions {
+				return ErrInvalidReportTier
+			}
+			return err
+		}
+
+		r.ContextualMetricsIds[i] = m.Id
+		r.Metrics[i] = models.BillingReportMetric{
+			ContextualMetricId:    m.Id,
+			ContextualMetricIdent: m.Ident,
+			ContextualMetricName:  m.Name,
+			Stats:                 getStats(samples, r.Interval),
+		}
+	}
+
+	samples := make([]float64, 0, len(totals))
+	for _, v := range totals {
+		samples = append(samples, v)
+	}
+	r.Total = getStats(samples, r.Interval)
+	return nil
+}
+
+// getStats returns the statistics of the samples.
+func getStats(samples []float64, interval int32) (stats models.BillingReportStats) {
+	if len(samples) == 0 {
+		return stats
+	}
+
+	sorted := make([]float64, len(samples))
+	copy(sorted, samples)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	stats.Samples = int64(len(sorted))
+	stats.P95 = percentile(sorted, 95)
+	stats.P99 = percentile(sorted, 99)
+	stats.Avg = sum / float64(len(sorted))
+	stats.Peak = sorted[len(sorted)-1]
+	stats.Volume = sum * float64(interval)
+	return stats
+}
+
+// percentile returns the nearest rank percentile of the sorted samples. This
+// is the usual burstable billing method, where the top samples above the
+// percentile are discarded and the highest remaining sample is billed.
+func percentile(sorted []float64, p int) float64 {
+	i := (p*len(sorted)+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// toFloat converts a numeric or boolean value to float.
+func toFloat(v any) (f float64, ok bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,76 @@
+package billingreport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestGetStats(t *testing.T) {
+	samples := make([]float64, 100)
+	for i := range samples {
+		// 100 to 1, unsorted
+		samples[i] = float64(100 - i)
+	}
+
+	want := models.BillingReportStats{
+		Samples: 100,
+		P95:     95,
+		P99:     99,
+		Avg:     50.5,
+		Peak:    100,
+		Volume:  5050 * 300,
+	}
+	got := getStats(samples, 300)
+	if got != want {
+		t.Errorf("getStats failed, want: %+v, got: %+v", want, got)
+	}
+	if samples[0] != 100 {
+		t.Errorf("getStats changed the samples order")
+	}
+
+	got = getStats([]float64{}, 300)
+	if got != (models.BillingReportStats{}) {
+		t.Errorf("getStats of empty samples failed, want: %+v, got: %+v", models.BillingReportStats{}, got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		p      int
+		want   float64
+	}{
+		{[]float64{1}, 95, 1},
+		{[]float64{1, 2}, 95, 2},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 95, 19},
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 99, 20},
+	}
+	for _, test := range tests {
+		got := percentile(test.sorted, test.p)
+		if got != test.want {
+			t.Errorf("percentile(%v, %d) failed, want: %v, got: %v", test.sorted, test.p, test.want, got)
+		}
+	}
+}
+
+func TestGetMonthRange(t *testing.T) {
+	now := time.Date(2023, 3, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		month       string
+		start, stop int64
+		err         error
+	}{
+		{"2023-01", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC).Unix(), nil},
+		{"2023-03", time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), now.Unix(), nil},
+		{"2023-04", 0, 0, ErrInvalidMonth},
+		{"2023-13", 0, 0, ErrInvalidMonth},
+	}
+	for _, test := range tests {
+		start, stop, err := getMonthRange(test.month, now)
+		if err != test.err || start != test.start || stop != test.stop {
+			t.Errorf("getMonthRange(%s) failed, want: %d, %d, %v, got: %d, %d, %v", test.month, test.start, test.stop, test.err, start, stop, err)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+// ApplyError is an error caused by the document, found while applying it.
+type ApplyError string
+
+func (e ApplyError) Error() string {
+	return string(e)
+}
+
+func applyErrorf(format string, a ...any) ApplyError {
+	return ApplyError(fmt.Sprintf(format, a...))
+}
+
+// dataPolicyChange is a data policy change on the storage backend.
+type dataPolicyChange struct {
+	// old is the data policy before the change, nil if created.
+	old *models.DataPolicy
+	// dp is the changed data policy.
+	dp models.DataPolicy
+}
+
+// applier applies the changes of the desired document on a transaction.
+type applier struct {
+	api     *api.API
+	pg      *pg.PG
+	state   *state
+	changes map[string]Change
+	// dataPolicies is the data policies changes, applied on the storage
+	// after the database changes.
+	dataPolicies []dataPolicyChange
+	// notifications is the notifications sent after the commit.
+	notifications []func()
+}
+
+// apply applies the changes of the desired document in a single transaction. The
+// services are notified after the commit.
+func apply(ctx context.Context, api *api.API, s *state, desired *Document, changes []Change) (err error) {
+	a := &applier{
+		api:     api,
+		state:   s,
+		changes: make(map[string]Change, len(changes)),
+	}
+	for _, c := range changes {
+		a.changes[c.key()] = c
+	}
+
+	var applied []dataPolicyChange
+	err = api.PG.WithTx(ctx, func(p *pg.PG) error {
+		a.pg = p
+		err := a.applyDocument(ctx, desired)
+		if err != nil {
+			return err
+		}
+		applied, err = a.applyStorage(ctx)
+		return err
+	})
+	if err != nil {
+		if len(applied) > 0 {
+			a.revertStorage(applied)
+		}
+		return err
+	}
+
+	for _, notify := range a.notifications {
+		notify()
+	}
+	return nil
+}
+
+// action returns the change action of the entity, empty if not changed.
+func (a *applier) action(kind string, ref string) string {
+	return a.changes[kind+" "+ref].Action
+}
+
+func (a *applier) applyDocument(ctx context.Context, doc *Document) (err error) {
+	for _, dp := range doc.DataPolicies {
+		err = a.applyDataPolicy(ctx, dp)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range doc.AlarmCategories {
+		err = a.applyAlarmCategory(ctx, c)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range doc.BasicContainers {
+		err = applyContainer(ctx, a, KindBasicContainer, types.CTBasic, c,
+			a.pg.CreateBasicContainer, a.pg.UpdateBasicContainer, a.pg.CreateBasicMetric, a.pg.UpdateBasicMetric)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range doc.SNMPv2cContainers {
+		err = applyContainer(ctx, a, KindSNMPv2cContainer, types.CTSNMPv2c, c,
+			a.pg.CreateSNMPv2cContainer, a.pg.UpdateSNMPv2cContainer, a.pg.CreateSNMPv2cMetric, a.pg.UpdateSNMPv2cMetric)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range doc.FlexLegacyContainers {
+		err = applyContainer(ctx, a, KindFlexLegacyContainer, types.CTFlexLegacy, c,
+			a.pg.CreateFlexLegacyContainer, a.pg.UpdateFlexLegacyContainer, a.pg.CreateFlexLegacyMetric, a.pg.UpdateFlexLegacyMetric)
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range doc.AlarmExpressions {
+		err = a.applyAlarmExpression(ctx, e)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range doc.AlarmProfiles {
+		err = a.applyAlarmProfile(ctx, p)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range doc.Contexts {
+		err = a.applyContext(ctx, c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDataPolicyModel(id int16, dp DataPolicy) models.DataPolicy {
+	return models.DataPolicy{
+		Id:        id,
+		Name:      dp.Name,
+		Descr:     dp.Descr,
+		Retention: dp.Retention,
+		Tiers:     dp.Tiers,
+		AggrFn:    dp.AggrFn,
+		AggrFns:   dp.AggrFns,
+	}
+}
+
+func (a *applier) applyDataPolicy(ctx context.Context, dp DataPolicy) error {
+	model := newDataPolicyModel(a.state.dataPolicies[dp.Name], dp)
+	switch a.action(KindDataPolicy, dp.Name) {
+	case ActionCreate:
+		tx, id, err := a.pg.CreateDataPolicy(ctx, model)
+		if err != nil {
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+		model.Id = id
+		a.state.dataPolicies[dp.Name] = id
+		a.dataPolicies = append(a.dataPolicies, dataPolicyChange{dp: model})
+	case ActionUpdate:
+		tx, exists, err := a.pg.UpdateDataPolicy(ctx, model)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Data policy %q does not exists.", dp.Name)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+		for _, c := range a.state.Doc.DataPolicies {
+			if c.Name == dp.Name {
+				old := newDataPolicyModel(model.Id, c)
+				a.dataPolicies = append(a.dataPolicies, dataPolicyChange{old: &old, dp: model})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (a *applier) applyAlarmCategory(ctx context.Context, c AlarmCategory) (err error) {
+	category := models.AlarmCategory{
+		Id:    a.state.categories[c.Name],
+		Name:  c.Name,
+		Descr: c.Descr,
+		Level: c.Level,
+	}
+	switch a.action(KindAlarmCategory, c.Name) {
+	case ActionCreate:
+		id, err := a.pg.CreateAlarmCategory(ctx, category)
+		if err != nil {
+			return err
+		}
+		a.state.categories[c.Name] = id
+	case ActionUpdate:
+		exists, err := a.pg.UpdateAlarmCategory(ctx, category)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Alarm category %q does not exists.", c.Name)
+		}
+	}
+	return nil
+}
+
+func applyContainer[T any, M any](
+	ctx context.Context,
+	a *applier,
+	kind string,
+	containerType types.ContainerType,
+	c Container[T, M],
+	create func(context.Context, models.Container[T]) (int32, error),
+	update func(context.Context, models.Container[T]) (bool, error),
+	createMetric func(context.Context, models.Metric[M]) (int64, error),
+	updateMetric func(context.Context, models.Metric[M]) (bool, error),
+) error {
+	container := models.Container[T]{
+		Base: models.BaseContainer{
+			Id:                 a.state.containers[c.Name],
+			Name:               c.Name,
+			Descr:              c.Descr,
+			Type:               containerType,
+			Enabled:            c.Enabled,
+			RTSPullingInterval: c.RTSPullingInterval,
+			TeamId:             a.state.teams[c.Team],
+			Labels:             c.Labels,
+		},
+		Protocol: c.Protocol,
+	}
+	tools.SetProtocolId(&container.Protocol, int64(container.Base.Id))
+
+	// the basic containers are not pulled, so the services are not notified
+	notify := containerType != types.CTBasic
+
+	switch a.action(kind, c.Name) {
+	case ActionCreate:
+		err := a.checkContainerProtocol(ctx, c.Name, -1, container.Protocol)
+		if err != nil {
+			return err
+		}
+		id, err := create(ctx, container)
+		if err != nil {
+			return err
+		}
+		container.Base.Id = id
+		tools.SetProtocolId(&container.Protocol, int64(id))
+		a.state.containers[c.Name] = id
+		if notify {
+			a.notifications = append(a.notifications, func() {
+				t.NotifyContainerCreated(a.api.Amqph, container.Base, container.Protocol)
+			})
+		}
+	case ActionUpdate:
+		err := a.checkContainerProtocol(ctx, c.Name, container.Base.Id, container.Protocol)
+		if err != nil {
+			return err
+		}
+		exists, err := update(ctx, container)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Container %q does not exists.", c.Name)
+		}
+		if notify {
+			a.notifications = append(a.notifications, func() {
+				t.NotifyContainerUpdated(a.api.Amqph, container.Base, container.Protocol)
+			})
+		}
+	}
+
+	for _, m := range c.Metrics {
+		ref := MetricRef{Container: c.Name, Metric: m.Name}
+		metric := models.Metric[M]{
+			Base: models.BaseMetric{
+				Id:                  a.state.metrics[ref],
+				ContainerId:         container.Base.Id,
+				ContainerType:       containerType,
+				Type:                m.Type,
+				Name:                m.Name,
+				Descr:               m.Descr,
+				Enabled:             m.Enabled,
+				DataPolicyId:        a.state.dataPolicies[m.DataPolicy],
+				RTSPullingTimes:     m.RTSPullingTimes,
+				RTSCacheDuration:    m.RTSCacheDuration,
+				DHSEnabled:          m.DHSEnabled,
+				DHSInterval:         m.DHSInterval,
+				EvaluableExpression: m.EvaluableExpression,
+				Labels:              m.Labels,
+			},
+			Protocol: m.Protocol,
+		}
+
+		switch a.action(KindMetric, ref.String()) {
+		case ActionCreate, ActionUpdate:
+			id, exists, notification, err := tools.SaveMetric(ctx, a.api, metric, createMetric, updateMetric)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return applyErrorf("Metric %q does not exists.", ref)
+			}
+			a.state.metrics[ref] = id
+			a.notifications = append(a.notifications, notification)
+		}
+	}
+	return nil
+}
+
+// checkContainerProtocol checks if the container target is available.
+func (a *applier) checkContainerProtocol(ctx context.Context, name string, id int32, protocol any) error {
+	switch p := protocol.(type) {
+	case models.SNMPv2cContainer:
+		exists, err := a.pg.AvailableSNMPv2cContainerTargetPort(ctx, p.Target, p.Port, id)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return applyErrorf("Container %q target and port combination already exists.", name)
+		}
+	case models.FlexLegacyContainer:
+		r, err := a.pg.ExistsFlexLegacyContainerTargetPortAndSerialNumber(ctx, id, p.Target, p.SerialNumber)
+		if err != nil {
+			return err
+		}
+		if r.TargetExists {
+			return applyErrorf("Container %q target already exists.", name)
+		}
+		if r.SerialNumberExists {
+			return applyErrorf("Container %q flex serial-number already exists.", name)
+		}
+	}
+	return nil
+}
+
+func (a *applier) applyAlarmExpression(ctx context.Context, e AlarmExpression) error {
+	exp := models.AlarmExpression{
+		Id:              a.state.expressions[e.Name],
+		Name:            e.Name,
+		Expression:      e.Expression,
+		AlarmCategoryId: a.state.categories[e.Category],
+	}
+	switch a.action(KindAlarmExpression, e.Name) {
+	case ActionCreate:
+		id, err := a.pg.CreateAlarmExpression(ctx, exp)
+		if err != nil {
+			return err
+		}
+		exp.Id = id
+		a.state.expressions[e.Name] = id
+	case ActionUpdate:
+		exists, err := a.pg.UpdateAlarmExpression(ctx, exp)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Alarm expression %q does not exists.", e.Name)
+		}
+	}
+
+	for _, ref := range e.Metrics {
+		if a.action(KindAlarmExpressionMetric, e.Name+" -> "+ref.String()) != ActionCreate {
+			continue
+		}
+		err := a.pg.CrateMetricAlarmExpressionRel(ctx, exp.Id, a.state.metrics[ref])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *applier) applyAlarmProfile(ctx context.Context, p AlarmProfile) error {
+	profile := models.AlarmProfile{
+		Id:    a.state.profiles[p.Name],
+		Name:  p.Name,
+		Descr: p.Descr,
+	}
+	switch a.action(KindAlarmProfile, p.Name) {
+	case ActionCreate:
+		id, err := a.pg.CreateAlarmProfile(ctx, profile)
+		if err != nil {
+			return err
+		}
+		profile.Id = int32(id)
+		a.state.profiles[p.Name] = profile.Id
+	case ActionUpdate:
+		exists, err := a.pg.UpdateAlarmProfile(ctx, profile)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Alarm profile %q does not exists.", p.Name)
+		}
+	}
+
+	for _, name := range p.Categories {
+		if a.action(KindAlarmProfileCategory, p.Name+" -> "+name) != ActionCreate {
+			continue
+		}
+		err := a.pg.AddCategoryToAlarmProfile(ctx, profile.Id, a.state.categories[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *applier) applyContext(ctx context.Context, c Context) error {
+	ref := contextRef{Team: c.Team, Ident: c.Ident}
+	name := c.Team + "/" + c.Ident
+	model := models.Context{
+		Id:     a.state.contexts[ref],
+		TeamId: a.state.teams[c.Team],
+		Name:   c.Name,
+		Ident:  c.Ident,
+		Descr:  c.Descr,
+	}
+	switch a.action(KindContext, name) {
+	case ActionCreate:
+		id, err := a.pg.CreateContext(ctx, model)
+		if err != nil {
+			return err
+		}
+		model.Id = id
+		a.state.contexts[ref] = id
+	case ActionUpdate:
+		exists, err := a.pg.UpdateContext(ctx, model)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return applyErrorf("Context %q does not exists.", name)
+		}
+	}
+
+	for _, m := range c.Metrics {
+		mRef := contextualMetricRef{contextRef: ref, Ident: m.Ident}
+		metric := models.ContextualMetric{
+			Id:        a.state.contextualMetrics[mRef],
+			ContextId: model.Id,
+			MetricId:  a.state.metrics[m.Metric],
+			Ident:     m.Ident,
+			Name:      m.Name,
+			Descr:     m.Descr,
+		}
+		switch a.action(KindContextualMetric, name+"/"+m.Ident) {
+		case ActionCreate:
+			id, err := a.pg.CreateContextualMetric(ctx, metric)
+			if err != nil {
+				return err
+			}
+			a.state.contextualMetrics[mRef] = id
+		case ActionUpdate:
+			exists, err := a.pg.UpdateContextualMetric(ctx, metric)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return applyErrorf("Contextual metric %q does not exists.", name+"/"+m.Ident)
+			}
+		}
+	}
+	return nil
+}
+
+// applyStorage applies the data policies changes on the storage backend. If fails,
+// the applied changes are reverted. Returns the applied changes.
+func (a *applier) applyStorage(ctx context.Context) (applied []dataPolicyChange, err error) {
+	for _, c := range a.dataPolicies {
+		if c.old == nil {
+			err = a.api.Storage.CreateDataPolicy(ctx, c.dp)
+		} else {
+			err = a.api.Storage.UpdateDataPolicy(ctx, c.dp)
+		}
+		if err != nil {
+			a.revertStorage(applied)
+			return nil, err
+		}
+		applied = append(applied, c)
+	}
+	return applied, nil
+}
+
+// revertStorage reverts the data policies changes on the storage backend.
+func (a *applier) revertStorage(applied []dataPolicyChange) {
+	ctx := context.Background()
+	for i := len(applied) - 1; i >= 0; i-- {
+		c := applied[i]
+		var err error
+		if c.old == nil {
+			err = a.api.Storage.DeleteDataPolicy(ctx, c.dp.Id)
+		} else {
+			err = a.api.Storage.UpdateDataPolicy(ctx, *c.old)
+		}
+		if err != nil {
+			a.api.Log.Error("Fail to revert data policy on storage backend, id: "+fmt.Sprint(c.dp.Id), logger.ErrField(err))
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the current document version.
+const Version = 1
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+var ErrInvalidFormat = errors.New("invalid format")
+
+// Document is the declarative configuration. The entities are referenced by name,
+// or by ident for the teams, contexts and contextual metrics, instead of ids.
+type Document struct {
+	// Version is the document version.
+	Version int `json:"version" validate:"required"`
+	// DataPolicies is the data policies.
+	DataPolicies []DataPolicy `json:"data-policies,omitempty" validate:"dive"`
+	// AlarmCategories is the alarm categories.
+	AlarmCategories []AlarmCategory `json:"alarm-categories,omitempty" validate:"dive"`
+	// AlarmExpressions is the alarm expressions.
+	AlarmExpressions []AlarmExpression `json:"alarm-expressions,omitempty" validate:"dive"`
+	// AlarmProfiles is the alarm profiles.
+	AlarmProfiles []AlarmProfile `json:"alarm-profiles,omitempty" validate:"dive"`
+	// BasicContainers is the basic containers and its metrics.
+	BasicContainers []Container[struct{}, struct{}] `json:"basic-containers,omitempty" validate:"dive"`
+	// SNMPv2cContainers is the SNMPv2c containers and its metrics.
+	SNMPv2cContainers []Container[models.SNMPv2cContainer, models.SNMPMetric] `json:"snmpv2c-containers,omitempty" validate:"dive"`
+	// FlexLegacyContainers is the flex legacy containers and its metrics.
+	FlexLegacyContainers []Container[models.FlexLegacyContainer, models.FlexLegacyMetric] `json:"flex-legacy-containers,omitempty" validate:"dive"`
+	// Contexts is the teams contexts and its contextual metrics.
+	Contexts []Context `json:"contexts,omitempty" validate:"dive"`
+}
+
+type DataPolicy struct {
+	// Name is the data policy name, used as reference.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the data policy description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Retention is the raw data retention in hours.
+	Retention int32 `json:"retention" validate:"required,min=1"`
+	// Tiers is the ordered list of aggregation tiers.
+	Tiers []models.DataPolicyTier `json:"tiers,omitempty" validate:"max=5,dive"`
+	// AggrFn is the primary aggregation funcion.
+	AggrFn string `json:"aggregation-function" validate:"required"`
+	// AggrFns is the additional aggregation functions.
+	AggrFns []string `json:"aggregation-functions,omitempty" validate:"max=8"`
+}
+
+type AlarmCategory struct {
+	// Name is the alarm category name, used as reference.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the alarm category description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Level is the alarm level.
+	Level int32 `json:"level" validate:"-"`
+}
+
+type AlarmExpression struct {
+	// Name is the alarm expression name, used as reference.
+	Name string `json:"name" validate:"required,max=50"`
+	// Expression is the alarm expression.
+	Expression string `json:"expression" validate:"required,max=255"`
+	// Category is the alarm category name.
+	Category string `json:"category" validate:"required,max=50"`
+	// Metrics is the metrics evaluated by the expression.
+	Metrics []MetricRef `json:"metrics,omitempty" validate:"dive"`
+}
+
+type AlarmProfile struct {
+	// Name is the alarm profile name, used as reference.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the alarm profile description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Categories is the alarm categories names.
+	Categories []string `json:"categories,omitempty" validate:"dive,max=50"`
+}
+
+type BaseContainer struct {
+	// Name is the container name, used as reference. Must be unique between
+	// all containers types.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the container description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Enabled is the enable state.
+	Enabled bool `json:"enabled" validate:"-"`
+	// RTSPullingInterval is the interval in miliseconds between each metric data pull.
+	RTSPullingInterval int32 `json:"rts-pulling-interval" validate:"required,min=100,max=3600000"`
+	// Team is the ident of the team that manages the container. Is optional.
+	Team string `json:"team,omitempty" validate:"max=50"`
+	// Labels are the container key/value labels.
+	Labels map[string]string `json:"labels,omitempty" validate:"labels"`
+}
+
+type Container[T any, M any] struct {
+	BaseContainer
+	// Protocol is the container protocol settings.
+	Protocol T `json:"protocol" validate:"required"`
+	// Metrics is the container metrics.
+	Metrics []Metric[M] `json:"metrics,omitempty" validate:"dive"`
+}
+
+type Metric[T any] struct {
+	// Name is the metric name, used as reference inside the container.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the metric description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Type is the metric type.
+	Type types.MetricType `json:"type" validate:"required"`
+	// Enabled is the metric enable state.
+	Enabled bool `json:"enabled" validate:"-"`
+	// DataPolicy is the data policy name.
+	DataPolicy string `json:"data-policy" validate:"required,max=50"`
+	// RTSPullingTimes is how many times will pull the data.
+	RTSPullingTimes int16 `json:"rts-pulling-times" validate:"min=0,max=1000000"`
+	// RTSCacheDuration is the data duration in miliseconds on RTS cache.
+	RTSCacheDuration int32 `json:"rts-cache-duration" validate:"min=1000,max=3600000"`
+	// DHSEnabled is the enabled state of for the data history service.
+	DHSEnabled bool `json:"dhs-enabled" validate:"-"`
+	// DHSInterval is the interval in seconds of the data history service.
+	DHSInterval int32 `json:"dhs-interval" validate:"-"`
+	// EvaluableExpression is the a evaluable expression for the metric value.
+	EvaluableExpression string `json:"evaluable-expression" validate:"max=255"`
+	// Labels are the metric key/value labels.
+	Labels map[string]string `json:"labels,omitempty" validate:"labels"`
+	// Protocol is the metric protocol settings.
+	Protocol T `json:"protocol" validate:"required"`
+}
+
+// MetricRef is a reference to a metric.
+type MetricRef struct {
+	// Container is the container name.
+	Container string `json:"container" validate:"required,max=50"`
+	// Metric is the metric name.
+	Metric string `json:"metric" validate:"required,max=50"`
+}
+
+func (r MetricRef) String() string {
+	return r.Container + "/" + r.Metric
+}
+
+type Context struct {
+	// Team is the team ident.
+	Team string `json:"team" validate:"required,max=50"`
+	// Ident is the context ident, used as reference inside the team.
+	Ident string `json:"ident" validate:"required,min=2,max=50"`
+	// Name is the context name.
+	Name string `json:"name" validate:"required,min=2,max=50"`
+	// Descr is the context description.
+	Descr string `json:"descr" validate:"max=255"`
+	// Metrics is the contextual metrics.
+	Metrics []ContextualMetric `json:"metrics,omitempty" validate:"dive"`
+}
+
+type ContextualMetric struct {
+	// Ident is the contextual metric ident, used as reference inside the context.
+	Ident string `json:"ident" validate:"required,min=2,max=50"`
+	// Name is the contextual metric name.
+	Name string `json:"name" validate:"required,min=2,max=50"`
+	// Descr is the conextual metric description.
+	Descr string `json:"descr" validate:"max=255"`
+	// Metric is the metric.
+	Metric MetricRef `json:"metric" validate:"required"`
+}
+
+// ValidateFormat returns true if the format is supported.
+func ValidateFormat(format string) bool {
+	return format == FormatJSON || format == FormatYAML
+}
+
+// Decode decodes the document on the format. The YAML documents use the same
+// fields names as the JSON documents.
+func Decode(b []byte, format string) (doc Document, err error) {
+	switch format {
+	case FormatJSON:
+	case FormatYAML:
+		var v any
+		err = yaml.Unmarshal(b, &v)
+		if err != nil {
+			return doc, err
+		}
+		b, err = json.Marshal(v)
+		if err != nil {
+			return doc, err
+		}
+	default:
+		return doc, ErrInvalidFormat
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+	return doc, d.Decode(&doc)
+}
+
+// Encode encodes the document on the format.
+func Encode(doc Document, format string) (b []byte, err error) {
+	b, err = json.MarshalIndent(doc, "", "  ")
+	if err != nil || format == FormatJSON {
+		return b, err
+	}
+	if format != FormatYAML {
+		return nil, ErrInvalidFormat
+	}
+
+	// JSON is valid YAML, decoding it as node keeps the fields order
+	var node yaml.Node
+	err = yaml.Unmarshal(b, &node)
+	if err != nil {
+		return nil, err
+	}
+	setBlockStyle(&node)
+
+	var buf bytes.Buffer
+	e := yaml.NewEncoder(&buf)
+	e.SetIndent(2)
+	err = e.Encode(&node)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), e.Close()
+}
+
+// setBlockStyle sets the block style on the node and its children.
+func setBlockStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.DoubleQuotedStyle {
+		node.Style = 0
+	}
+	for _, n := range node.Content {
+		setBlockStyle(n)
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Exports the configuration document, with the data policies, alarm categories,
+// alarm expressions, alarm profiles, containers, metrics and contexts.
+// Params:
+//   - "format" Document format, "json" or "yaml". Default is "json".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func ExportHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		format := tools.DefaultQuery(c, "format", FormatJSON)
+		if !ValidateFormat(format) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		s, err := loadState(ctx, api.PG)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to load configuration", logger.ErrField(err))
+			return
+		}
+
+		b, err := Encode(s.Doc, format)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to encode configuration", logger.ErrField(err))
+			return
+		}
+		c.Data(http.StatusOK, contentType(format), b)
+	}
+}
+
+func contentType(format string) string {
+	if format == FormatYAML {
+		return "application/yaml"
+	}
+	return "application/json"
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBodySize is the maximum size of a configuration document.
+const maxImportBodySize = 32 << 20
+
+// Imports a configuration document, creating the missing entities and updating
+// the changed ones. Entities missing on the document are kept. The changes are
+// applied in a single transaction.
+// Params:
+//   - "format" Document format, "json" or "yaml". Default is "json".
+//   - "dry-run" If true, only returns the changes. Default is false.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If exceeds the maximum number of data policies.
+//   - 400 If an aggregation function is not supported by the storage backend.
+//   - 400 If the document is invalid, with the errors found.
+//   - 200 If succeeded, with the changes.
+func ImportHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		format := tools.DefaultQuery(c, "format", FormatJSON)
+		if !ValidateFormat(format) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		dryRun, err := strconv.ParseBool(tools.DefaultQuery(c, "dry-run", "false"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		b, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		doc, err := Decode(b, format)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(doc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		s, err := loadState(ctx, api.PG)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to load configuration", logger.ErrField(err))
+			return
+		}
+
+		changes, errs := plan(&s.Doc, &doc)
+		errs = append(errs, s.checkTeams(&doc)...)
+		if len(errs) > 0 {
+			c.JSON(http.StatusBadRequest, tools.DataMsgRes(errs, tools.MsgInvalidConfig))
+			return
+		}
+
+		max, err := strconv.ParseInt(env.MaxDataPolicies, 10, 0)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to parse env.MaxDataPolicies", logger.ErrField(err))
+			return
+		}
+		n := len(s.Doc.DataPolicies)
+		for _, change := range changes {
+			if change.Kind == KindDataPolicy && change.Action == ActionCreate {
+				n++
+			}
+		}
+		if int64(n) > max {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgMaxDataPolicy))
+			return
+		}
+
+		if dryRun || len(changes) == 0 {
+			c.JSON(http.StatusOK, tools.DataRes(Plan{Changes: changes}))
+			return
+		}
+
+		err = apply(ctx, api, s, &doc, changes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if e, ok := err.(ApplyError); ok {
+				c.JSON(http.StatusBadRequest, tools.DataMsgRes([]string{e.Error()}, tools.MsgInvalidConfig))
+				return
+			}
+			if err == storage.ErrUnsupportedAggrFunction {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggrFn))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to apply configuration", logger.ErrField(err))
+			return
+		}
+
+		api.Log.Info("Configuration imported, changes: " + strconv.Itoa(len(changes)))
+		c.JSON(http.StatusOK, tools.DataRes(Plan{Applied: true, Changes: changes}))
+	}
+}
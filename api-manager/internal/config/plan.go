@@ -0,0 +1,420 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+)
+
+const (
+	KindDataPolicy            = "data-policy"
+	KindAlarmCategory         = "alarm-category"
+	KindAlarmExpression       = "alarm-expression"
+	KindAlarmExpressionMetric = "alarm-expression-metric"
+	KindAlarmProfile          = "alarm-profile"
+	KindAlarmProfileCategory  = "alarm-profile-category"
+	KindBasicContainer        = "basic-container"
+	KindSNMPv2cContainer      = "snmpv2c-container"
+	KindFlexLegacyContainer   = "flex-legacy-container"
+	KindMetric                = "metric"
+	KindContext               = "context"
+	KindContextualMetric      = "contextual-metric"
+)
+
+// Change is a change of the configuration.
+type Change struct {
+	// Action is the change action, "create" or "update".
+	Action string `json:"action"`
+	// Kind is the entity kind.
+	Kind string `json:"kind"`
+	// Ref is the entity reference, like "router/cpu" for a metric.
+	Ref string `json:"ref"`
+	// Fields is the changed fields of an update.
+	Fields []string `json:"fields,omitempty"`
+}
+
+func (c Change) key() string {
+	return c.Kind + " " + c.Ref
+}
+
+// Plan is the changes of an import.
+type Plan struct {
+	// Applied is true if the changes were applied.
+	Applied bool `json:"applied"`
+	// Changes is the changes, in the order they are applied.
+	Changes []Change `json:"changes"`
+}
+
+// planner compares the desired document with the current, saving the changes and
+// the errors found.
+type planner struct {
+	current *Document
+	desired *Document
+	changes []Change
+	errs    []string
+	// metrics is the current and desired metrics.
+	metrics map[MetricRef]bool
+	// ambiguousMetrics is the current metrics with duplicated names.
+	ambiguousMetrics map[MetricRef]bool
+}
+
+// plan returns the changes needed to apply the desired document on the current.
+// Entities missing on the desired document are kept. If the desired document is
+// invalid, returns the errors found.
+func plan(current *Document, desired *Document) (changes []Change, errs []string) {
+	p := &planner{
+		current:          current,
+		desired:          desired,
+		changes:          []Change{},
+		metrics:          make(map[MetricRef]bool),
+		ambiguousMetrics: make(map[MetricRef]bool),
+	}
+	if desired.Version != Version {
+		return nil, []string{fmt.Sprintf("Unsupported version %d, the supported version is %d.", desired.Version, Version)}
+	}
+
+	dps := p.planDataPolicies()
+	categories := p.planAlarmCategories()
+	p.planContainers(dps)
+	p.planAlarmExpressions(categories)
+	p.planAlarmProfiles(categories)
+	p.planContexts()
+	return p.changes, p.errs
+}
+
+// errorf saves the error, if not saved yet.
+func (p *planner) errorf(format string, a ...any) {
+	err := fmt.Sprintf(format, a...)
+	for _, e := range p.errs {
+		if e == err {
+			return
+		}
+	}
+	p.errs = append(p.errs, err)
+}
+
+// compare saves a create change if the entity does not exists or a update change
+// if some field has changed. Returns false if the entity is ambiguous.
+func (p *planner) compare(kind string, ref string, current any, exists bool, ambiguous bool, desired any, skip ...string) bool {
+	if ambiguous {
+		p.errorf("Ambiguous %s %q, the reference is used by many entities.", kind, ref)
+		return false
+	}
+	if !exists {
+		p.changes = append(p.changes, Change{Action: ActionCreate, Kind: kind, Ref: ref})
+		return true
+	}
+	fields := tools.ChangedFields(current, desired, skip...)
+	if len(fields) > 0 {
+		p.changes = append(p.changes, Change{Action: ActionUpdate, Kind: kind, Ref: ref, Fields: fields})
+	}
+	return true
+}
+
+// resolve returns true if the reference exists on the desired document or exists
+// and is not ambiguous on the current.
+func resolve[K comparable, V any](desired map[K]V, current map[K]V, ambiguous map[K]bool, ref K) bool {
+	if _, ok := desired[ref]; ok {
+		return true
+	}
+	_, ok := current[ref]
+	return ok && !ambiguous[ref]
+}
+
+func (p *planner) planDataPolicies() (resolved func(name string) bool) {
+	current, ambiguous := index(p.current.DataPolicies, func(dp DataPolicy) string { return dp.Name })
+	desired, duplicated := index(p.desired.DataPolicies, func(dp DataPolicy) string { return dp.Name })
+	for _, dp := range p.desired.DataPolicies {
+		if duplicated[dp.Name] {
+			p.errorf("Duplicated data policy %q.", dp.Name)
+			continue
+		}
+		model := models.DataPolicy{Retention: dp.Retention, Tiers: dp.Tiers, AggrFn: dp.AggrFn, AggrFns: dp.AggrFns}
+		if !storage.ValidateDataPolicyAggrFunctions(model) {
+			p.errorf("Invalid data policy %q aggregation function.", dp.Name)
+		}
+		if !storage.ValidateDataPolicyTiers(model) {
+			p.errorf("Invalid data policy %q tiers, retentions and intervals must be increasing and intervals shorter than the previous retention.", dp.Name)
+		}
+		c, exists := current[dp.Name]
+		p.compare(KindDataPolicy, dp.Name, c, exists, ambiguous[dp.Name], dp)
+	}
+	return func(name string) bool {
+		return resolve(desired, current, ambiguous, name)
+	}
+}
+
+func (p *planner) planAlarmCategories() (resolved func(name string) bool) {
+	current, ambiguous := index(p.current.AlarmCategories, func(c AlarmCategory) string { return c.Name })
+	desired, duplicated := index(p.desired.AlarmCategories, func(c AlarmCategory) string { return c.Name })
+	for _, c := range p.desired.AlarmCategories {
+		if duplicated[c.Name] {
+			p.errorf("Duplicated alarm category %q.", c.Name)
+			continue
+		}
+		cc, exists := current[c.Name]
+		p.compare(KindAlarmCategory, c.Name, cc, exists, ambiguous[c.Name], c)
+	}
+
+	// levels must be unique after the import
+	levels := make(map[int32]string)
+	for _, c := range p.current.AlarmCategories {
+		if _, ok := desired[c.Name]; !ok {
+			levels[c.Level] = c.Name
+		}
+	}
+	for _, c := range p.desired.AlarmCategories {
+		if duplicated[c.Name] {
+			continue
+		}
+		if name, ok := levels[c.Level]; ok {
+			p.errorf("Alarm category %q level %d is used by the alarm category %q.", c.Name, c.Level, name)
+			continue
+		}
+		levels[c.Level] = c.Name
+	}
+	return func(name string) bool {
+		return resolve(desired, current, ambiguous, name)
+	}
+}
+
+// container is a container of any kind.
+type container struct {
+	kind  string
+	base  BaseContainer
+	value any
+	// metrics is the container metrics.
+	metrics []metric
+}
+
+// metric is a metric of any container kind.
+type metric struct {
+	name       string
+	dataPolicy string
+	metricType types.MetricType
+	value      any
+}
+
+func newContainer[T any, M any](kind string, c Container[T, M]) container {
+	r := container{kind: kind, base: c.BaseContainer, value: c}
+	for _, m := range c.Metrics {
+		r.metrics = append(r.metrics, metric{name: m.Name, dataPolicy: m.DataPolicy, metricType: m.Type, value: m})
+	}
+	return r
+}
+
+// getContainers returns the containers of all kinds.
+func getContainers(doc *Document) (containers []container) {
+	for _, c := range doc.BasicContainers {
+		containers = append(containers, newContainer(KindBasicContainer, c))
+	}
+	for _, c := range doc.SNMPv2cContainers {
+		containers = append(containers, newContainer(KindSNMPv2cContainer, c))
+	}
+	for _, c := range doc.FlexLegacyContainers {
+		containers = append(containers, newContainer(KindFlexLegacyContainer, c))
+	}
+	return containers
+}
+
+func (p *planner) planContainers(dpResolved func(name string) bool) {
+	getName := func(c container) string { return c.base.Name }
+	getMetricName := func(m metric) string { return m.name }
+
+	currentContainers := getContainers(p.current)
+	current, ambiguous := index(currentContainers, getName)
+	for _, c := range currentContainers {
+		for _, m := range c.metrics {
+			ref := MetricRef{Container: c.base.Name, Metric: m.name}
+			if p.metrics[ref] || ambiguous[c.base.Name] {
+				p.ambiguousMetrics[ref] = true
+			}
+			p.metrics[ref] = true
+		}
+	}
+
+	desiredContainers := getContainers(p.desired)
+	_, duplicated := index(desiredContainers, getName)
+	for _, c := range desiredContainers {
+		name := c.base.Name
+		if duplicated[name] {
+			p.errorf("Duplicated container %q.", name)
+			continue
+		}
+		cc, exists := current[name]
+		if exists && cc.kind != c.kind {
+			p.errorf("Container %q kind can't be changed from %s to %s.", name, cc.kind, c.kind)
+			continue
+		}
+		if !p.compare(c.kind, name, cc.value, exists, ambiguous[name], c.value, "metrics") {
+			continue
+		}
+
+		currentMetrics, _ := index(cc.metrics, getMetricName)
+		_, duplicatedMetrics := index(c.metrics, getMetricName)
+		for _, m := range c.metrics {
+			ref := MetricRef{Container: name, Metric: m.name}
+			if duplicatedMetrics[m.name] {
+				p.errorf("Duplicated metric %q.", ref)
+				continue
+			}
+			if !types.ValidateMetricType(m.metricType) {
+				p.errorf("Invalid metric %q type.", ref)
+			}
+			if !dpResolved(m.dataPolicy) {
+				p.errorf("Metric %q data policy %q not found.", ref, m.dataPolicy)
+			}
+			cm, exists := currentMetrics[m.name]
+			if !p.compare(KindMetric, ref.String(), cm.value, exists, p.ambiguousMetrics[ref], m.value) {
+				continue
+			}
+			p.metrics[ref] = true
+		}
+	}
+}
+
+// resolveMetric returns true if the metric exists and is not ambiguous.
+func (p *planner) resolveMetric(ref MetricRef) bool {
+	return p.metrics[ref] && !p.ambiguousMetrics[ref]
+}
+
+func (p *planner) planAlarmExpressions(categoryResolved func(name string) bool) {
+	current, ambiguous := index(p.current.AlarmExpressions, func(e AlarmExpression) string { return e.Name })
+	_, duplicated := index(p.desired.AlarmExpressions, func(e AlarmExpression) string { return e.Name })
+	for _, e := range p.desired.AlarmExpressions {
+		if duplicated[e.Name] {
+			p.errorf("Duplicated alarm expression %q.", e.Name)
+			continue
+		}
+		if !categoryResolved(e.Category) {
+			p.errorf("Alarm expression %q category %q not found.", e.Name, e.Category)
+		}
+		ce, exists := current[e.Name]
+		if !p.compare(KindAlarmExpression, e.Name, ce, exists, ambiguous[e.Name], e, "metrics") {
+			continue
+		}
+
+		rels := make(map[MetricRef]bool, len(ce.Metrics))
+		for _, ref := range ce.Metrics {
+			rels[ref] = true
+		}
+		for _, ref := range e.Metrics {
+			if !p.resolveMetric(ref) {
+				p.errorf("Alarm expression %q metric %q not found.", e.Name, ref)
+				continue
+			}
+			if rels[ref] {
+				continue
+			}
+			rels[ref] = true
+			p.changes = append(p.changes, Change{Action: ActionCreate, Kind: KindAlarmExpressionMetric, Ref: e.Name + " -> " + ref.String()})
+		}
+	}
+}
+
+func (p *planner) planAlarmProfiles(categoryResolved func(name string) bool) {
+	current, ambiguous := index(p.current.AlarmProfiles, func(pr AlarmProfile) string { return pr.Name })
+	_, duplicated := index(p.desired.AlarmProfiles, func(pr AlarmProfile) string { return pr.Name })
+	for _, pr := range p.desired.AlarmProfiles {
+		if duplicated[pr.Name] {
+			p.errorf("Duplicated alarm profile %q.", pr.Name)
+			continue
+		}
+		cp, exists := current[pr.Name]
+		if !p.compare(KindAlarmProfile, pr.Name, cp, exists, ambiguous[pr.Name], pr, "categories") {
+			continue
+		}
+
+		rels := make(map[string]bool, len(cp.Categories))
+		for _, name := range cp.Categories {
+			rels[name] = true
+		}
+		for _, name := range pr.Categories {
+			if !categoryResolved(name) {
+				p.errorf("Alarm profile %q category %q not found.", pr.Name, name)
+				continue
+			}
+			if rels[name] {
+				continue
+			}
+			rels[name] = true
+			p.changes = append(p.changes, Change{Action: ActionCreate, Kind: KindAlarmProfileCategory, Ref: pr.Name + " -> " + name})
+		}
+	}
+}
+
+func (p *planner) planContexts() {
+	getRef := func(c Context) contextRef { return contextRef{Team: c.Team, Ident: c.Ident} }
+	getIdent := func(m ContextualMetric) string { return m.Ident }
+
+	current, ambiguous := index(p.current.Contexts, getRef)
+	_, duplicated := index(p.desired.Contexts, getRef)
+	for _, c := range p.desired.Contexts {
+		ref := getRef(c)
+		name := c.Team + "/" + c.Ident
+		if duplicated[ref] {
+			p.errorf("Duplicated context %q.", name)
+			continue
+		}
+		if _, err := strconv.ParseInt(c.Ident, 10, 64); err == nil {
+			p.errorf("Context %q ident must not be number as text.", name)
+		}
+		cc, exists := current[ref]
+		if !p.compare(KindContext, name, cc, exists, ambiguous[ref], c, "metrics") {
+			continue
+		}
+
+		currentMetrics, ambiguousMetrics := index(cc.Metrics, getIdent)
+		_, duplicatedMetrics := index(c.Metrics, getIdent)
+		for _, m := range c.Metrics {
+			mName := name + "/" + m.Ident
+			if duplicatedMetrics[m.Ident] {
+				p.errorf("Duplicated contextual metric %q.", mName)
+				continue
+			}
+			if !p.resolveMetric(m.Metric) {
+				p.errorf("Contextual metric %q metric %q not found.", mName, m.Metric)
+			}
+			cm, exists := currentMetrics[m.Ident]
+			if exists && !ambiguousMetrics[m.Ident] && cm.Metric != m.Metric {
+				p.errorf("Contextual metric %q metric can't be changed.", mName)
+				continue
+			}
+			p.compare(KindContextualMetric, mName, cm, exists, ambiguousMetrics[m.Ident], m)
+		}
+	}
+}
+
+// index indexes the values by key. The keys of many values are returned
+// as duplicated.
+func index[K comparable, V any](values []V, key func(V) K) (m map[K]V, duplicated map[K]bool) {
+	m = make(map[K]V, len(values))
+	duplicated = make(map[K]bool)
+	for _, v := range values {
+		k := key(v)
+		if _, ok := m[k]; ok {
+			duplicated[k] = true
+		}
+		m[k] = v
+	}
+	return m, duplicated
+}
+
+func sortMetricsRefs(refs []MetricRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Container != refs[j].Container {
+			return refs[i].Container < refs[j].Container
+		}
+		return refs[i].Metric < refs[j].Metric
+	})
+}
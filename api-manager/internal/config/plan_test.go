@@ -0,0 +1,112 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+func testDocument() Document {
+	return Document{
+		Version: Version,
+		DataPolicies: []DataPolicy{
+			{Name: "default", Descr: "Default", Retention: 720, AggrFn: "mean"},
+		},
+		AlarmCategories: []AlarmCategory{
+			{Name: "critical", Descr: "Critical", Level: 1},
+		},
+		AlarmExpressions: []AlarmExpression{
+			{Name: "high-cpu", Expression: "x > 90", Category: "critical", Metrics: []MetricRef{{Container: "router", Metric: "cpu"}}},
+		},
+		SNMPv2cContainers: []Container[models.SNMPv2cContainer, models.SNMPMetric]{
+			{
+				BaseContainer: BaseContainer{Name: "router", Descr: "Router", Enabled: true, RTSPullingInterval: 1000},
+				Protocol:      models.SNMPv2cContainer{Target: "10.0.0.1", Port: 161, Transport: "udp", Community: "public", Timeout: 1000, Retries: 1, MaxOids: 60},
+				Metrics: []Metric[models.SNMPMetric]{
+					{Name: "cpu", Descr: "CPU", Type: types.MTFloat, DataPolicy: "default", RTSCacheDuration: 1000, Protocol: models.SNMPMetric{OID: ".1.3.6.1"}},
+				},
+			},
+		},
+		Contexts: []Context{
+			{Team: "noc", Ident: "core", Name: "Core", Metrics: []ContextualMetric{
+				{Ident: "cpu", Name: "CPU", Metric: MetricRef{Container: "router", Metric: "cpu"}},
+			}},
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	current := testDocument()
+	current.DataPolicies[0].Tiers = []models.DataPolicyTier{{Retention: 2160, Interval: 300}}
+	desired := testDocument()
+	desired.DataPolicies[0].Retention = 1440
+	desired.SNMPv2cContainers[0].Metrics = append(desired.SNMPv2cContainers[0].Metrics, Metric[models.SNMPMetric]{
+		Name: "memory", Descr: "Memory", Type: types.MTInt, DataPolicy: "default", RTSCacheDuration: 1000, Protocol: models.SNMPMetric{OID: ".1.3.6.2"},
+	})
+	desired.AlarmExpressions[0].Metrics = append(desired.AlarmExpressions[0].Metrics, MetricRef{Container: "router", Metric: "memory"})
+
+	changes, errs := plan(&current, &desired)
+	if len(errs) != 0 {
+		t.Fatalf("Plan failed, want no errors, got: %v", errs)
+	}
+	want := []Change{
+		{Action: ActionUpdate, Kind: KindDataPolicy, Ref: "default", Fields: []string{"retention", "tiers"}},
+		{Action: ActionCreate, Kind: KindMetric, Ref: "router/memory"},
+		{Action: ActionCreate, Kind: KindAlarmExpressionMetric, Ref: "high-cpu -> router/memory"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes failed, want: %v, got: %v", want, changes)
+	}
+
+	changes, errs = plan(&current, &current)
+	if len(errs) != 0 || len(changes) != 0 {
+		t.Errorf("Same document failed, want no changes and errors, got: %v and %v", changes, errs)
+	}
+}
+
+func TestPlanErrors(t *testing.T) {
+	current := testDocument()
+	current.AlarmCategories = append(current.AlarmCategories, AlarmCategory{Name: "warning", Descr: "Warning", Level: 2})
+	current.BasicContainers = []Container[struct{}, struct{}]{
+		{BaseContainer: BaseContainer{Name: "switch"}},
+		{BaseContainer: BaseContainer{Name: "switch"}},
+	}
+
+	desired := testDocument()
+	desired.AlarmCategories[0].Level = 2
+	desired.AlarmExpressions[0].Category = "unknown"
+	desired.SNMPv2cContainers[0].Metrics[0].DataPolicy = "unknown"
+	desired.BasicContainers = []Container[struct{}, struct{}]{{BaseContainer: BaseContainer{Name: "switch"}}}
+	desired.Contexts = append(desired.Contexts, desired.Contexts[0])
+
+	_, errs := plan(&current, &desired)
+	want := []string{
+		`Alarm category "critical" level 2 is used by the alarm category "warning".`,
+		`Ambiguous basic-container "switch", the reference is used by many entities.`,
+		`Metric "router/cpu" data policy "unknown" not found.`,
+		`Alarm expression "high-cpu" category "unknown" not found.`,
+		`Duplicated context "noc/core".`,
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("Errors failed, want: %v, got: %v", want, errs)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	doc := testDocument()
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		b, err := Encode(doc, format)
+		if err != nil {
+			t.Fatalf("Fail to encode %s, err: %s", format, err)
+		}
+		decoded, err := Decode(b, format)
+		if err != nil {
+			t.Fatalf("Fail to decode %s, err: %s", format, err)
+		}
+		if !reflect.DeepEqual(decoded, doc) {
+			t.Errorf("%s decode failed, want: %+v, got: %+v", format, doc, decoded)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+)
+
+// pageLimit is the number of rows read on each database query.
+const pageLimit = 500
+
+type contextRef struct {
+	// Team is the team ident.
+	Team string
+	// Ident is the context ident.
+	Ident string
+}
+
+type contextualMetricRef struct {
+	contextRef
+	// Ident is the contextual metric ident.
+	Ident string
+}
+
+// state is the current configuration and the ids of its entities.
+type state struct {
+	// Doc is the current configuration document.
+	Doc Document
+
+	dataPolicies      map[string]int16
+	categories        map[string]int32
+	expressions       map[string]int32
+	profiles          map[string]int32
+	containers        map[string]int32
+	metrics           map[MetricRef]int64
+	teams             map[string]int32
+	contexts          map[contextRef]int32
+	contextualMetrics map[contextualMetricRef]int64
+}
+
+// loadState reads the current configuration from the database.
+func loadState(ctx context.Context, p *pg.PG) (s *state, err error) {
+	s = &state{
+		Doc:               Document{Version: Version},
+		dataPolicies:      make(map[string]int16),
+		categories:        make(map[string]int32),
+		expressions:       make(map[string]int32),
+		profiles:          make(map[string]int32),
+		containers:        make(map[string]int32),
+		metrics:           make(map[MetricRef]int64),
+		teams:             make(map[string]int32),
+		contexts:          make(map[contextRef]int32),
+		contextualMetrics: make(map[contextualMetricRef]int64),
+	}
+
+	dpNames := make(map[int16]string)
+	dps, err := p.GetDataPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, dp := range dps {
+		dpNames[dp.Id] = dp.Name
+		s.dataPolicies[dp.Name] = dp.Id
+		s.Doc.DataPolicies = append(s.Doc.DataPolicies, DataPolicy{
+			Name:      dp.Name,
+			Descr:     dp.Descr,
+			Retention: dp.Retention,
+			Tiers:     dp.Tiers,
+			AggrFn:    dp.AggrFn,
+			AggrFns:   dp.AggrFns,
+		})
+	}
+
+	categoriesNames := make(map[int32]string)
+	for offset := 0; ; offset += pageLimit {
+		categories, err := p.GetAlarmCategories(ctx, pg.AlarmCategoriesQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range categories {
+			categoriesNames[c.Id] = c.Name
+			s.categories[c.Name] = c.Id
+			s.Doc.AlarmCategories = append(s.Doc.AlarmCategories, AlarmCategory{
+				Name:  c.Name,
+				Descr: c.Descr,
+				Level: c.Level,
+			})
+		}
+		if len(categories) < pageLimit {
+			break
+		}
+	}
+
+	var teams []models.Team
+	teamsIdents := make(map[int32]string)
+	for offset := 0; ; offset += pageLimit {
+		page, err := p.GetTeams(ctx, pg.TeamQueryFilters{
+			OrderBy:   "ident",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page {
+			s.teams[t.Ident] = t.Id
+			teamsIdents[t.Id] = t.Ident
+		}
+		teams = append(teams, page...)
+		if len(page) < pageLimit {
+			break
+		}
+	}
+
+	// metricsRefs is the metrics references by id
+	metricsRefs := make(map[int64]MetricRef)
+	addMetric := func(container string, base models.BaseMetric) Metric[struct{}] {
+		ref := MetricRef{Container: container, Metric: base.Name}
+		metricsRefs[base.Id] = ref
+		s.metrics[ref] = base.Id
+		return Metric[struct{}]{
+			Name:                base.Name,
+			Descr:               base.Descr,
+			Type:                base.Type,
+			Enabled:             base.Enabled,
+			DataPolicy:          dpNames[base.DataPolicyId],
+			RTSPullingTimes:     base.RTSPullingTimes,
+			RTSCacheDuration:    base.RTSCacheDuration,
+			DHSEnabled:          base.DHSEnabled,
+			DHSInterval:         base.DHSInterval,
+			EvaluableExpression: base.EvaluableExpression,
+			Labels:              base.Labels,
+		}
+	}
+
+	for offset := 0; ; offset += pageLimit {
+		containers, err := p.GetBasicContainers(ctx, pg.BasicContainersQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			s.containers[c.Base.Name] = c.Base.Id
+			container := Container[struct{}, struct{}]{BaseContainer: newBaseContainer(c.Base, teamsIdents[c.Base.TeamId])}
+			for mOffset := 0; ; mOffset += pageLimit {
+				metrics, err := p.GetBasicMetrics(ctx, pg.BasicMetricQueryFilters{
+					ContainerId: c.Base.Id,
+					OrderBy:     "name",
+					OrderByFn:   "ASC",
+					Limit:       pageLimit,
+					Offset:      mOffset,
+				})
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range metrics {
+					container.Metrics = append(container.Metrics, addMetric(c.Base.Name, m.Base))
+				}
+				if len(metrics) < pageLimit {
+					break
+				}
+			}
+			s.Doc.BasicContainers = append(s.Doc.BasicContainers, container)
+		}
+		if len(containers) < pageLimit {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += pageLimit {
+		containers, err := p.GetSNMPv2cGetContainers(ctx, pg.SNMPv2cContainerQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			s.containers[c.Base.Name] = c.Base.Id
+			container := Container[models.SNMPv2cContainer, models.SNMPMetric]{
+				BaseContainer: newBaseContainer(c.Base, teamsIdents[c.Base.TeamId]),
+				Protocol:      c.Protocol,
+			}
+			for mOffset := 0; ; mOffset += pageLimit {
+				metrics, err := p.GetSNMPv2cMetrics(ctx, pg.SNMPv2cMetricQueryFilters{
+					ContainerId: c.Base.Id,
+					OrderBy:     "name",
+					OrderByFn:   "ASC",
+					Limit:       pageLimit,
+					Offset:      mOffset,
+				})
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range metrics {
+					container.Metrics = append(container.Metrics, withProtocol(addMetric(c.Base.Name, m.Base), m.Protocol))
+				}
+				if len(metrics) < pageLimit {
+					break
+				}
+			}
+			s.Doc.SNMPv2cContainers = append(s.Doc.SNMPv2cContainers, container)
+		}
+		if len(containers) < pageLimit {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += pageLimit {
+		containers, err := p.GetFlexLegacyContainers(ctx, pg.FlexLegacyContainerQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			s.containers[c.Base.Name] = c.Base.Id
+			container := Container[models.FlexLegacyContainer, models.FlexLegacyMetric]{
+				BaseContainer: newBaseContainer(c.Base, teamsIdents[c.Base.TeamId]),
+				Protocol:      c.Protocol,
+			}
+			for mOffset := 0; ; mOffset += pageLimit {
+				metrics, err := p.GetFlexLegacyMetrics(ctx, pg.FlexLegacyMetricQueryFilters{
+					ContainerId: c.Base.Id,
+					OrderBy:     "name",
+					OrderByFn:   "ASC",
+					Limit:       pageLimit,
+					Offset:      mOffset,
+				})
+				if err != nil {
+					return nil, err
+				}
+				for _, m := range metrics {
+					container.Metrics = append(container.Metrics, withProtocol(addMetric(c.Base.Name, m.Base), m.Protocol))
+				}
+				if len(metrics) < pageLimit {
+					break
+				}
+			}
+			s.Doc.FlexLegacyContainers = append(s.Doc.FlexLegacyContainers, container)
+		}
+		if len(containers) < pageLimit {
+			break
+		}
+	}
+
+	// expressionsMetrics is the expressions metrics by expression id
+	expressionsMetrics := make(map[int32][]MetricRef)
+	if len(metricsRefs) > 0 {
+		ids := make([]int64, 0, len(metricsRefs))
+		for id := range metricsRefs {
+			ids = append(ids, id)
+		}
+		expressions, err := p.GetMetricsAlarmExpressions(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i, exps := range expressions {
+			for _, e := range exps {
+				expressionsMetrics[e.Id] = append(expressionsMetrics[e.Id], metricsRefs[ids[i]])
+			}
+		}
+	}
+	for _, refs := range expressionsMetrics {
+		sortMetricsRefs(refs)
+	}
+
+	for offset := 0; ; offset += pageLimit {
+		expressions, err := p.GetAlarmExpressions(ctx, pg.AlarmExpressionQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range expressions {
+			s.expressions[e.Name] = e.Id
+			s.Doc.AlarmExpressions = append(s.Doc.AlarmExpressions, AlarmExpression{
+				Name:       e.Name,
+				Expression: e.Expression,
+				Category:   categoriesNames[e.AlarmCategoryId],
+				Metrics:    expressionsMetrics[e.Id],
+			})
+		}
+		if len(expressions) < pageLimit {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += pageLimit {
+		profiles, err := p.GetAlarmProfiles(ctx, pg.AlarmProfileQueryFilters{
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range profiles {
+			s.profiles[pr.Name] = pr.Id
+			profile := AlarmProfile{Name: pr.Name, Descr: pr.Descr}
+			for cOffset := 0; ; cOffset += pageLimit {
+				categories, err := p.GetAlarmProfileCategories(ctx, pr.Id, pageLimit, cOffset)
+				if err != nil {
+					return nil, err
+				}
+				for _, c := range categories {
+					profile.Categories = append(profile.Categories, c.Name)
+				}
+				if len(categories) < pageLimit {
+					break
+				}
+			}
+			s.Doc.AlarmProfiles = append(s.Doc.AlarmProfiles, profile)
+		}
+		if len(profiles) < pageLimit {
+			break
+		}
+	}
+
+	for _, t := range teams {
+		err = s.loadTeamContexts(ctx, p, t.Id, t.Ident, metricsRefs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// loadTeamContexts reads the team contexts and its contextual metrics.
+func (s *state) loadTeamContexts(ctx context.Context, p *pg.PG, teamId int32, team string, metricsRefs map[int64]MetricRef) error {
+	for offset := 0; ; offset += pageLimit {
+		contexts, err := p.GetContexts(ctx, pg.ContextQueryFilters{
+			TeamId:    teamId,
+			OrderBy:   "ident",
+			OrderByFn: "ASC",
+			Limit:     pageLimit,
+			Offset:    offset,
+		})
+		if err != nil {
+			return err
+		}
+		for _, c := range contexts {
+			ref := contextRef{Team: team, Ident: c.Ident}
+			s.contexts[ref] = c.Id
+			context := Context{
+				Team:  team,
+				Ident: c.Ident,
+				Name:  c.Name,
+				Descr: c.Descr,
+			}
+			for mOffset := 0; ; mOffset += pageLimit {
+				metrics, err := p.GetContextualMetrics(ctx, pg.ContextualMetricQueryFilters{
+					CtxId:     c.Id,
+					OrderBy:   "ident",
+					OrderByFn: "ASC",
+					Limit:     pageLimit,
+					Offset:    mOffset,
+				})
+				if err != nil {
+					return err
+				}
+				for _, m := range metrics {
+					s.contextualMetrics[contextualMetricRef{contextRef: ref, Ident: m.Ident}] = m.Id
+					context.Metrics = append(context.Metrics, ContextualMetric{
+						Ident:  m.Ident,
+						Name:   m.Name,
+						Descr:  m.Descr,
+						Metric: metricsRefs[m.MetricId],
+					})
+				}
+				if len(metrics) < pageLimit {
+					break
+				}
+			}
+			s.Doc.Contexts = append(s.Doc.Contexts, context)
+		}
+		if len(contexts) < pageLimit {
+			break
+		}
+	}
+	return nil
+}
+
+func newBaseContainer(base models.BaseContainer, team string) BaseContainer {
+	return BaseContainer{
+		Name:               base.Name,
+		Team:               team,
+		Descr:              base.Descr,
+		Enabled:            base.Enabled,
+		RTSPullingInterval: base.RTSPullingInterval,
+		Labels:             base.Labels,
+	}
+}
+
+// withProtocol returns the metric with the protocol.
+func withProtocol[T any](m Metric[struct{}], protocol T) Metric[T] {
+	return Metric[T]{
+		Name:                m.Name,
+		Descr:               m.Descr,
+		Type:                m.Type,
+		Enabled:             m.Enabled,
+		DataPolicy:          m.DataPolicy,
+		RTSPullingTimes:     m.RTSPullingTimes,
+		RTSCacheDuration:    m.RTSCacheDuration,
+		DHSEnabled:          m.DHSEnabled,
+		DHSInterval:         m.DHSInterval,
+		EvaluableExpression: m.EvaluableExpression,
+		Labels:              m.Labels,
+		Protocol:            protocol,
+	}
+}
+
+// checkTeams returns the errors of the document containers and contexts which
+// teams does not exists.
+func (s *state) checkTeams(doc *Document) (errs []string) {
+	for _, c := range getContainers(doc) {
+		if _, ok := s.teams[c.base.Team]; c.base.Team != "" && !ok {
+			errs = append(errs, fmt.Sprintf("Container %q team %q not found.", c.base.Name, c.base.Team))
+		}
+	}
+	for _, c := range doc.Contexts {
+		if _, ok := s.teams[c.Team]; !ok {
+			errs = append(errs, fmt.Sprintf("Context %q team %q not found.", c.Team+"/"+c.Ident, c.Team))
+		}
+	}
+	return errs
+}
@@ -16,6 +16,7 @@ import (
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
+//   - 403 If can't manage the container team.
 //   - 200 If succeeded.
 func CreateBasicHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -34,6 +35,20 @@ func CreateBasicHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ok, err := tools.ManagesTeamContainers(api, c, container.Base.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		container.Base.Type = types.CTBasic
 
 		id, err := api.PG.CreateBasicContainer(ctx, container)
@@ -17,6 +17,7 @@ import (
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
 //   - 404 If not found.
+//   - 403 If can't manage the container team.
 //   - 200 If succeeded.
 func UpdateBasicHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -42,6 +43,20 @@ func UpdateBasicHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ok, err := tools.ManagesTeamContainers(api, c, container.Base.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		container.Base.Id = int32(id)
 		container.Base.Type = types.CTBasic
 
@@ -18,6 +18,7 @@ import (
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
 //   - 400 If serial-number or target:port is in use.
+//   - 403 If can't manage the container team.
 //   - 200 If succeeded.
 func CreateFlexLegacy(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -36,6 +37,20 @@ func CreateFlexLegacy(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ok, err := tools.ManagesTeamContainers(api, c, container.Base.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		container.Base.Type = types.CTFlexLegacy
 
 		r, err := api.PG.ExistsFlexLegacyContainerTargetPortAndSerialNumber(ctx,
@@ -19,6 +19,7 @@ import (
 //   - 400 If json fields are invalid.
 //   - 400 If serial-number or target:port is in use.
 //   - 404 If container not found.
+//   - 403 If can't manage the container team.
 //   - 200 If succeeded.
 func UpdateFlexLegacy(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -44,6 +45,20 @@ func UpdateFlexLegacy(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ok, err := tools.ManagesTeamContainers(api, c, container.Base.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		container.Base.Id = int32(id)
 		container.Protocol.Id = int32(id)
 		container.Base.Type = types.CTFlexLegacy
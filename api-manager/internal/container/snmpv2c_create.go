@@ -18,6 +18,7 @@ import (
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
 //   - 400 If target:port is in use.
+//   - 403 If can't manage the container team.
 //   - 200 If succeeded.
 func CreateSNMPv2cHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -36,6 +37,20 @@ func CreateSNMPv2cHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ok, err := tools.ManagesTeamContainers(api, c, container.Base.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		container.Base.Type = types.CTSNMPv2c
 
 		exists, err := api.PG.AvailableSNMPv2cContainerTargetPort(ctx,
@@ -44,7 +44,12 @@ func GetSNMPv2cHandler(api *api.API) func(c *gin.Context) {
 }
 
 // Get SNMPv2c containers.
+// Params:
+//   - "team-id" Team id, required if the user is not an admin.
+//   - "labels" Label selector, as "site=POA,role in (core,edge)".
+//
 // Responses:
+//   - 403 If can't manage the team containers.
 //   - 200 If succeeded.
 func GetSNMPv2cContainers(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -61,6 +66,27 @@ func GetSNMPv2cContainers(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		selector, err := tools.SelectorQuery(c, "labels")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		teamId, _ := strconv.ParseInt(c.Query("team-id"), 0, 32)
+		ok, err := tools.ManagesTeamContainers(api, c, int32(teamId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTeamNotManaged))
+			return
+		}
+
 		createdAtStart, _ := strconv.ParseInt(c.Query("createdAtStart"), 0, 64)
 		createdAtStop, _ := strconv.ParseInt(c.Query("createdAtStop"), 0, 64)
 
@@ -81,6 +107,8 @@ func GetSNMPv2cContainers(api *api.API) func(c *gin.Context) {
 			CreatedAtStart: createdAtStart,
 			CreatedAtStop:  createdAtStop,
 			Enabled:        enabled,
+			TeamId:         int32(teamId),
+			Labels:         selector,
 			OrderBy:        c.Query("order-by"),
 			OrderByFn:      c.Query("order-by-fn"),
 			Target:         c.Query("target"),
@@ -6,8 +6,8 @@ import (
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -25,7 +25,7 @@ func AlarmHistoryHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		var opts influxdb.QueryAlarmHistoryOptions
+		var opts storage.QueryAlarmHistoryOptions
 
 		start, err := strconv.ParseInt(c.Query("start"), 0, 64)
 		if err != nil {
@@ -58,7 +58,7 @@ func AlarmHistoryHandler(api *api.API) func(c *gin.Context) {
 		opts.MetricId = req.MetricId
 		opts.Start = start
 
-		points, err := api.Influx.QueryAlarmHistory(ctx, opts)
+		points, err := api.Storage.QueryAlarmHistory(ctx, opts)
 		if err != nil {
 			c.Status(http.StatusInternalServerError)
 			api.Log.Error("Fail to query alarm history", logger.ErrField(err))
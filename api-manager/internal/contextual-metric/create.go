@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
@@ -17,6 +18,7 @@ import (
 //   - 400 If json fields are invalid.
 //   - 404 If context or metric does not exists.
 //   - 400 If ident is already in use.
+//   - 403 If the metric is not of the team and user is not a teams manager.
 //   - 200 If succeeded.
 func CreateHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -66,6 +68,30 @@ func CreateHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+		// team admins can only use the team metrics
+		if meta.Role < roles.TeamsManager {
+			ctxTeamId, _ := strconv.ParseInt(c.Param("teamId"), 10, 32)
+			_, teamId, err := api.PG.GetMetricContainerTeam(ctx, cmetric.MetricId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to get metric container team", logger.ErrField(err))
+				return
+			}
+			if teamId != int32(ctxTeamId) {
+				c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgMetricNotOfTeam))
+				return
+			}
+		}
+
 		id, err := api.PG.CreateContextualMetric(ctx, cmetric)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -13,6 +13,7 @@ import (
 	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/fernandotsda/nemesys/shared/uuid"
 	"github.com/gin-gonic/gin"
 	"github.com/rabbitmq/amqp091-go"
@@ -105,6 +106,28 @@ func DataHandler(api *api.API) func(c *gin.Context) {
 	}
 }
 
+// Queries the metric history.
+// Params:
+//   - "start" Range start in unix seconds.
+//   - "stop" Range stop in unix seconds. Default is now.
+//   - "aggregate" Aggregation function to read from the aggregated tiers. Default is
+//     the data policy primary function.
+//   - "step" Window duration in seconds. The series is aggregated into evenly spaced
+//     windows. Default is no windowing.
+//   - "max-points" Maximum number of points. A bigger step is used if needed.
+//   - "fill" Fill method of the empty windows, "none", "null", "previous" or "linear".
+//     Default is "none". Requires "step" or "max-points".
+//   - "custom_query" Custom query id or ident.
+//   - "params[<name>]" Custom query param value. Default is the param default.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If aggregate is not stored by the data policy.
+//   - 400 If the custom query flux is no longer valid.
+//   - 400 If invalid custom query params.
+//   - 400 If the storage backend does not support custom queries.
+//   - 404 If custom query not found.
+//   - 200 If succeeded.
 func QueryDataHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -116,7 +139,7 @@ func QueryDataHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		var opts influxdb.QueryOptions
+		var opts storage.QueryOptions
 		opts.MetricId = r.MetricId
 		opts.MetricType = r.MetricType
 		opts.DataPolicyId = r.DataPolicyId
@@ -138,7 +161,56 @@ func QueryDataHandler(api *api.API) func(c *gin.Context) {
 			opts.Stop = stop
 		}
 
-		cq, err := GetCustomQueryFlux(api, c)
+		stepS := c.Query("step")
+		if stepS != "" {
+			step, err := strconv.ParseInt(stepS, 0, 64)
+			if err != nil || step < 1 {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			opts.Step = step
+		}
+
+		maxPointsS := c.Query("max-points")
+		if maxPointsS != "" {
+			maxPoints, err := strconv.ParseInt(maxPointsS, 0, 64)
+			if err != nil || maxPoints < 1 {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			opts.MaxPoints = maxPoints
+		}
+
+		opts.Fill = c.DefaultQuery("fill", storage.FillNone)
+		if !storage.ValidateFill(opts.Fill) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		aggregate := c.Query("aggregate")
+		if aggregate != "" {
+			exists, dp, err := api.PG.GetDataPolicy(ctx, r.DataPolicyId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to get data policy", logger.ErrField(err))
+				return
+			}
+			if !exists {
+				c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDataPolicyNotFound))
+				return
+			}
+			if aggregate != dp.AggrFn && !hasAggrFn(dp, aggregate) {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggregate))
+				return
+			}
+			opts.Aggregate = aggregate
+			opts.AggrFn = dp.AggrFn
+		}
+
+		cq, err := GetCustomQuery(api, c)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
@@ -151,16 +223,31 @@ func QueryDataHandler(api *api.API) func(c *gin.Context) {
 			api.Log.Error("Fail to get custom query", logger.ErrField(err))
 			return
 		}
-		opts.CustomQueryFlux = cq
-		points, err := api.Influx.Query(ctx, opts)
+		if cq.Flux != "" {
+			// stored queries may predate the current validation rules
+			if influxdb.ValidateCustomQuery(cq) != nil {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidCustomQueryFlux))
+				return
+			}
+			opts.CustomQueryFlux, err = influxdb.GetCustomQueryFlux(cq, c.QueryMap("params"))
+			if err != nil {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidCustomQueryParams))
+				return
+			}
+		}
+		points, err := api.Storage.Query(ctx, opts)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
-			if err == influxdb.ErrInvalidQueryOptions {
+			if err == storage.ErrInvalidQueryOptions {
 				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
 				return
 			}
+			if err == storage.ErrCustomQueryNotSupported {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgCustomQueryNotSupported))
+				return
+			}
 			c.Status(http.StatusInternalServerError)
 			api.Log.Error("Fail to query metric data", logger.ErrField(err))
 			return
@@ -169,9 +256,10 @@ func QueryDataHandler(api *api.API) func(c *gin.Context) {
 	}
 }
 
-// GetCustomQueryFlux get the custom query id/ident on gin context query. Try to get
-// the flux on cache, if cache is missing goes to database and save on cache after.
-func GetCustomQueryFlux(api *api.API, c *gin.Context) (flux string, err error) {
+// GetCustomQuery get the custom query id/ident on gin context query. Try to get
+// the custom query on cache, if cache is missing goes to database and save on cache after.
+// Returns ErrCustomQueryNotFound if the custom query does not exists.
+func GetCustomQuery(api *api.API, c *gin.Context) (cq models.CustomQuery, err error) {
 	ctx := c.Request.Context()
 	rawCustomQuery := c.Query("custom_query")
 	if len(rawCustomQuery) != 0 {
@@ -179,47 +267,47 @@ func GetCustomQueryFlux(api *api.API, c *gin.Context) (flux string, err error) {
 		if err != nil {
 			cacheRes, err := api.Cache.GetCustomQueryByIdent(ctx, rawCustomQuery)
 			if err != nil {
-				return flux, err
+				return cq, err
 			}
 			if !cacheRes.Exists {
 				exists, cq, err := api.PG.GetCustomQueryByIdent(ctx, rawCustomQuery)
 				if err != nil {
-					return flux, err
+					return cq, err
 				}
 				if !exists {
-					return flux, err
+					return cq, ErrCustomQueryNotFound
 				}
-				flux = cq.Flux
-				err = api.Cache.SetCustomQueryByIdent(ctx, cq.Flux, rawCustomQuery)
-				if err != nil {
-					return flux, err
-				}
-			} else {
-				flux = cacheRes.Flux
+				return cq, api.Cache.SetCustomQueryByIdent(ctx, cq, rawCustomQuery)
 			}
+			cq = cacheRes.CustomQuery
 		} else {
 			cacheRes, err := api.Cache.GetCustomQuery(ctx, int32(id))
 			if err != nil {
-				return flux, err
+				return cq, err
 			}
 
 			if !cacheRes.Exists {
 				exists, cq, err := api.PG.GetCustomQuery(ctx, int32(id))
 				if err != nil {
-					return flux, err
+					return cq, err
 				}
 				if !exists {
-					return flux, err
-				}
-				flux = cq.Flux
-				err = api.Cache.SetCustomQuery(ctx, cq.Flux, int32(id))
-				if err != nil {
-					return flux, err
+					return cq, ErrCustomQueryNotFound
 				}
-			} else {
-				flux = cacheRes.Flux
+				return cq, api.Cache.SetCustomQuery(ctx, cq, int32(id))
 			}
+			cq = cacheRes.CustomQuery
+		}
+	}
+	return cq, nil
+}
+
+// hasAggrFn returns true if the data policy stores the additional aggregation function.
+func hasAggrFn(dp models.DataPolicy, fn string) bool {
+	for _, v := range dp.AggrFns {
+		if v == fn {
+			return true
 		}
 	}
-	return flux, nil
+	return false
 }
@@ -0,0 +1,200 @@
+package ctxmetric
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// maxExportMetrics is the maximum number of contextual metrics in a export.
+const maxExportMetrics = 100
+
+// Exports the history of one or many contextual metrics of a context. The data is
+// streamed, one contextual metric after another, with the metric and context metadata
+// on each row.
+// Params:
+//   - "start" Range start in unix seconds.
+//   - "stop" Range stop in unix seconds. Default is now.
+//   - "format" Export format, "csv", "ndjson" or "parquet". Default is "csv".
+//   - "metrics" Comma separated contextual metrics ids. Default is all contextual
+//     metrics of the context, max is 100.
+//   - "aggregate" Aggregation function to read from the aggregated tiers. Default is
+//     the data policy primary function.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If aggregate is not stored by a metric data policy.
+//   - 404 If a contextual metric is not found.
+//   - 200 If succeeded.
+func ExportDataHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		start, err := strconv.ParseInt(c.Query("start"), 0, 64)
+		if err != nil || start < 1 {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var stop int64
+		stopS := c.Query("stop")
+		if stopS != "" {
+			stop, err = strconv.ParseInt(stopS, 0, 64)
+			if err != nil || stop < 1 {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+		}
+
+		format := c.DefaultQuery("format", ExportFormatCSV)
+		if !ValidateExportFormat(format) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		ids, err := parseExportMetricsIds(c.Query("metrics"))
+		if err != nil || len(ids) > maxExportMetrics {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		metadata, err := api.PG.GetContextualMetricsMetadata(ctx, int32(ctxId), ids, maxExportMetrics)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get contextual metrics metadata", logger.ErrField(err))
+			return
+		}
+		if len(metadata) != len(ids) && len(ids) != 0 {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextualMetricNotFound))
+			return
+		}
+
+		// validate aggregate on each data policy, storing its primary function
+		aggrFns := make(map[int16]string)
+		aggregate := c.Query("aggregate")
+		for _, m := range metadata {
+			dpId := m.MetricRequest.DataPolicyId
+			if _, ok := aggrFns[dpId]; ok || aggregate == "" {
+				continue
+			}
+			exists, dp, err := api.PG.GetDataPolicy(ctx, dpId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to get data policy", logger.ErrField(err))
+				return
+			}
+			if !exists {
+				c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDataPolicyNotFound))
+				return
+			}
+			if aggregate != dp.AggrFn && !hasAggrFn(dp, aggregate) {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggregate))
+				return
+			}
+			aggrFns[dpId] = dp.AggrFn
+		}
+
+		c.Header("Content-Type", getExportContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="history-%d-%d.%s"`, ctxId, start, format))
+		c.Status(http.StatusOK)
+
+		w, err := newExportWriter(c.Writer, format)
+		if err != nil {
+			api.Log.Error("Fail to create export writer", logger.ErrField(err))
+			return
+		}
+
+		for _, m := range metadata {
+			opts := storage.QueryOptions{
+				Start:        start,
+				Stop:         stop,
+				DataPolicyId: m.MetricRequest.DataPolicyId,
+				MetricId:     m.MetricRequest.MetricId,
+				MetricType:   m.MetricRequest.MetricType,
+				Aggregate:    aggregate,
+				AggrFn:       aggrFns[m.MetricRequest.DataPolicyId],
+			}
+			row := newExportRow(m)
+			err = api.Storage.QueryStream(ctx, opts, func(timestamp time.Time, value any) error {
+				row.Timestamp = timestamp.Unix()
+				row.Value = value
+				return w.WriteRow(row)
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				api.Log.Error("Fail to export metric data", logger.ErrField(err))
+				return
+			}
+		}
+
+		err = w.Close()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			api.Log.Error("Fail to close export writer", logger.ErrField(err))
+		}
+	}
+}
+
+// parseExportMetricsIds parses the comma separated contextual metrics ids,
+// ignoring duplicates.
+func parseExportMetricsIds(s string) (ids []int64, err error) {
+	ids = make([]int64, 0)
+	if s == "" {
+		return ids, nil
+	}
+	seen := make(map[int64]struct{})
+	for _, raw := range strings.Split(s, ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// newExportRow returns an export row with the contextual metric metadata.
+func newExportRow(m models.ContextualMetricMetadata) exportRow {
+	return exportRow{
+		TeamId:                m.TeamId,
+		ContextId:             m.ContextId,
+		ContextIdent:          m.ContextIdent,
+		ContextName:           m.ContextName,
+		ContextualMetricId:    m.Id,
+		ContextualMetricIdent: m.Ident,
+		ContextualMetricName:  m.Name,
+		MetricId:              m.MetricRequest.MetricId,
+		MetricType:            uint8(m.MetricRequest.MetricType),
+		ContainerId:           m.MetricRequest.ContainerId,
+		DataPolicyId:          m.MetricRequest.DataPolicyId,
+	}
+}
@@ -0,0 +1,192 @@
+package ctxmetric
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatNDJSON  = "ndjson"
+	ExportFormatParquet = "parquet"
+)
+
+// exportParquetRowGroupSize is the maximum size in bytes buffered in memory
+// before the parquet writer flushes a row group.
+const exportParquetRowGroupSize = 8 * 1024 * 1024
+
+// exportRow is a row of the history export.
+type exportRow struct {
+	Timestamp             int64  `json:"timestamp"`
+	Value                 any    `json:"value"`
+	TeamId                int32  `json:"team_id"`
+	ContextId             int32  `json:"context_id"`
+	ContextIdent          string `json:"context_ident"`
+	ContextName           string `json:"context_name"`
+	ContextualMetricId    int64  `json:"contextual_metric_id"`
+	ContextualMetricIdent string `json:"contextual_metric_ident"`
+	ContextualMetricName  string `json:"contextual_metric_name"`
+	MetricId              int64  `json:"metric_id"`
+	MetricType            uint8  `json:"metric_type"`
+	ContainerId           int32  `json:"container_id"`
+	DataPolicyId          int16  `json:"data_policy_id"`
+}
+
+// parquetExportRow is a row of the parquet history export. Value is
+// null if not numeric, booleans are written as 0 or 1.
+type parquetExportRow struct {
+	Timestamp             int64    `parquet:"name=timestamp, type=INT64"`
+	Value                 *float64 `parquet:"name=value, type=DOUBLE, repetitiontype=OPTIONAL"`
+	TeamId                int32    `parquet:"name=team_id, type=INT32"`
+	ContextId             int32    `parquet:"name=context_id, type=INT32"`
+	ContextIdent          string   `parquet:"name=context_ident, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ContextName           string   `parquet:"name=context_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ContextualMetricId    int64    `parquet:"name=contextual_metric_id, type=INT64"`
+	ContextualMetricIdent string   `parquet:"name=contextual_metric_ident, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ContextualMetricName  string   `parquet:"name=contextual_metric_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	MetricId              int64    `parquet:"name=metric_id, type=INT64"`
+	MetricType            int32    `parquet:"name=metric_type, type=INT32"`
+	ContainerId           int32    `parquet:"name=container_id, type=INT32"`
+	DataPolicyId          int32    `parquet:"name=data_policy_id, type=INT32"`
+}
+
+var exportCSVHeader = []string{"timestamp", "value", "team_id", "context_id", "context_ident", "context_name",
+	"contextual_metric_id", "contextual_metric_ident", "contextual_metric_name", "metric_id", "metric_type",
+	"container_id", "data_policy_id"}
+
+// exportWriter writes the history export rows in a file format.
+type exportWriter interface {
+	// WriteRow writes a row.
+	WriteRow(row exportRow) error
+	// Close flushes the buffered rows and finishes the file.
+	Close() error
+}
+
+// ValidateExportFormat validates the export format.
+func ValidateExportFormat(format string) bool {
+	return format == ExportFormatCSV || format == ExportFormatNDJSON || format == ExportFormatParquet
+}
+
+// getExportContentType returns the content type of the export format.
+func getExportContentType(format string) string {
+	switch format {
+	case ExportFormatNDJSON:
+		return "application/x-ndjson"
+	case ExportFormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv"
+	}
+}
+
+// newExportWriter returns an export writer for the format.
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case ExportFormatNDJSON:
+		b := bufio.NewWriter(w)
+		return &ndjsonExportWriter{b: b, enc: json.NewEncoder(b)}, nil
+	case ExportFormatParquet:
+		pw, err := writer.NewParquetWriterFromWriter(w, new(parquetExportRow), 1)
+		if err != nil {
+			return nil, err
+		}
+		pw.RowGroupSize = exportParquetRowGroupSize
+		return &parquetExportWriter{w: pw}, nil
+	default:
+		c := csv.NewWriter(w)
+		return &csvExportWriter{w: c, record: make([]string, len(exportCSVHeader))}, c.Write(exportCSVHeader)
+	}
+}
+
+type csvExportWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (w *csvExportWriter) WriteRow(row exportRow) error {
+	w.record[0] = strconv.FormatInt(row.Timestamp, 10)
+	w.record[1] = fmt.Sprint(row.Value)
+	w.record[2] = strconv.FormatInt(int64(row.TeamId), 10)
+	w.record[3] = strconv.FormatInt(int64(row.ContextId), 10)
+	w.record[4] = row.ContextIdent
+	w.record[5] = row.ContextName
+	w.record[6] = strconv.FormatInt(row.ContextualMetricId, 10)
+	w.record[7] = row.ContextualMetricIdent
+	w.record[8] = row.ContextualMetricName
+	w.record[9] = strconv.FormatInt(row.MetricId, 10)
+	w.record[10] = strconv.FormatInt(int64(row.MetricType), 10)
+	w.record[11] = strconv.FormatInt(int64(row.ContainerId), 10)
+	w.record[12] = strconv.FormatInt(int64(row.DataPolicyId), 10)
+	return w.w.Write(w.record)
+}
+
+func (w *csvExportWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonExportWriter struct {
+	b   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *ndjsonExportWriter) WriteRow(row exportRow) error {
+	return w.enc.Encode(row)
+}
+
+func (w *ndjsonExportWriter) Close() error {
+	return w.b.Flush()
+}
+
+type parquetExportWriter struct {
+	w *writer.ParquetWriter
+}
+
+func (w *parquetExportWriter) WriteRow(row exportRow) error {
+	return w.w.Write(parquetExportRow{
+		Timestamp:             row.Timestamp,
+		Value:                 toFloat(row.Value),
+		TeamId:                row.TeamId,
+		ContextId:             row.ContextId,
+		ContextIdent:          row.ContextIdent,
+		ContextName:           row.ContextName,
+		ContextualMetricId:    row.ContextualMetricId,
+		ContextualMetricIdent: row.ContextualMetricIdent,
+		ContextualMetricName:  row.ContextualMetricName,
+		MetricId:              row.MetricId,
+		MetricType:            int32(row.MetricType),
+		ContainerId:           row.ContainerId,
+		DataPolicyId:          int32(row.DataPolicyId),
+	})
+}
+
+func (w *parquetExportWriter) Close() error {
+	return w.w.WriteStop()
+}
+
+// toFloat converts a numeric or boolean value to float. Returns nil if
+// the value is not numeric.
+func toFloat(v any) *float64 {
+	var f float64
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case int64:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case bool:
+		if v {
+			f = 1
+		}
+	default:
+		return nil
+	}
+	return &f
+}
@@ -0,0 +1,43 @@
+package ctxmetric
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportWriters(t *testing.T) {
+	row := exportRow{Timestamp: 1670000000, Value: 1.5, TeamId: 1, ContextId: 2, ContextIdent: "ctx",
+		ContextName: "Context", ContextualMetricId: 3, ContextualMetricIdent: "cpu", ContextualMetricName: "CPU",
+		MetricId: 4, MetricType: 2, ContainerId: 5, DataPolicyId: 6}
+
+	tests := map[string]func(b []byte) bool{
+		ExportFormatCSV: func(b []byte) bool {
+			return string(b) == "timestamp,value,team_id,context_id,context_ident,context_name,contextual_metric_id,contextual_metric_ident,contextual_metric_name,metric_id,metric_type,container_id,data_policy_id\n"+
+				"1670000000,1.5,1,2,ctx,Context,3,cpu,CPU,4,2,5,6\n"
+		},
+		ExportFormatNDJSON: func(b []byte) bool {
+			return string(b) == `{"timestamp":1670000000,"value":1.5,"team_id":1,"context_id":2,"context_ident":"ctx","context_name":"Context","contextual_metric_id":3,"contextual_metric_ident":"cpu","contextual_metric_name":"CPU","metric_id":4,"metric_type":2,"container_id":5,"data_policy_id":6}`+"\n"
+		},
+		ExportFormatParquet: func(b []byte) bool {
+			return bytes.HasPrefix(b, []byte("PAR1")) && bytes.HasSuffix(b, []byte("PAR1"))
+		},
+	}
+	for format, check := range tests {
+		var b bytes.Buffer
+		w, err := newExportWriter(&b, format)
+		if err != nil {
+			t.Fatalf("Fail to create %s writer, err: %s", format, err)
+		}
+		err = w.WriteRow(row)
+		if err != nil {
+			t.Fatalf("Fail to write %s row, err: %s", format, err)
+		}
+		err = w.Close()
+		if err != nil {
+			t.Fatalf("Fail to close %s writer, err: %s", format, err)
+		}
+		if !check(b.Bytes()) {
+			t.Errorf("Invalid %s export, got: %q", format, b.String())
+		}
+	}
+}
@@ -45,23 +45,23 @@ func calculate(ctx context.Context, api *api.API) (result models.ServerCostResul
 		return result, err
 	}
 
-	points, err := api.Influx.CountAllMetricsPoints(dps)
+	points, err := api.Storage.CountAllMetricsPoints(dps)
 	if err != nil {
 		return result, err
 	}
 	elements.InfluxDataPoints = points
 
-	requests, err := api.Influx.GetTotalRequests(ctx)
+	requests, err := api.Storage.GetTotalRequests(ctx)
 	if err != nil {
 		return result, err
 	}
 
-	realtimeRequest, err := api.Influx.GetTotalRealtimeDataRequests(ctx)
+	realtimeRequest, err := api.Storage.GetTotalRealtimeDataRequests(ctx)
 	if err != nil {
 		return result, err
 	}
 
-	dataHistoryRequests, err := api.Influx.GetTotalDataHistoryRequests(ctx)
+	dataHistoryRequests, err := api.Storage.GetTotalDataHistoryRequests(ctx)
 	if err != nil {
 		return result, err
 	}
@@ -5,13 +5,13 @@ import (
 	"sync"
 	"time"
 
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/fernandotsda/nemesys/shared/storage"
 )
 
 type Counter struct {
-	influxClient         *influxdb.Client
+	storage              storage.Storage
 	pg                   *pg.PG
 	flushTicker          *time.Ticker
 	done                 chan any
@@ -23,14 +23,14 @@ type Counter struct {
 	log                  *logger.Logger
 }
 
-func New(influxClient *influxdb.Client, pg *pg.PG, logger *logger.Logger, flushInterval time.Duration) *Counter {
+func New(storage storage.Storage, pg *pg.PG, logger *logger.Logger, flushInterval time.Duration) *Counter {
 	c := &Counter{
-		influxClient: influxClient,
-		pg:           pg,
-		flushTicker:  time.NewTicker(flushInterval),
-		log:          logger,
-		Whitelist:    sync.Map{},
-		done:         make(chan any),
+		storage:     storage,
+		pg:          pg,
+		flushTicker: time.NewTicker(flushInterval),
+		log:         logger,
+		Whitelist:   sync.Map{},
+		done:        make(chan any),
 	}
 	c.LoadWhitelist()
 	go c.Run()
@@ -52,15 +52,15 @@ func (c *Counter) Run() {
 			c.mu.Unlock()
 
 			if r > 0 {
-				c.influxClient.WriteRequestsCount(r)
+				c.storage.WriteRequestsCount(r)
 				c.log.Debug("Requests count writed")
 			}
 			if rrd > 0 {
-				c.influxClient.WriteRealtimeDataRequestsCount(rrd)
+				c.storage.WriteRealtimeDataRequestsCount(rrd)
 				c.log.Debug("Realtime data requests count writed")
 			}
 			if rhd > 0 {
-				c.influxClient.WriteHistoryDataRequestsCount(rhd)
+				c.storage.WriteHistoryDataRequestsCount(rhd)
 				c.log.Debug("Data history requests count writed")
 			}
 		case <-c.done:
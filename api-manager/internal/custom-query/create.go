@@ -6,8 +6,10 @@ import (
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,6 +17,10 @@ import (
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
+//   - 400 If flux or params are invalid.
+//   - 400 If flux uses a function that is not allowed.
+//   - 400 If flux does not compile.
+//   - 400 If custom queries are not supported by the storage backend.
 //   - 400 If ident is already in use.
 //   - 200 If succeeded.
 func CreateHandler(api *api.API) func(c *gin.Context) {
@@ -40,6 +46,37 @@ func CreateHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		err = influxdb.ValidateCustomQuery(cq)
+		if err != nil {
+			switch err {
+			case influxdb.ErrCustomQueryFunctionNotAllowed:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgCustomQueryFunctionNotAllowed))
+			case influxdb.ErrInvalidCustomQueryParams:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidCustomQueryParams))
+			default:
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidCustomQueryFlux))
+			}
+			return
+		}
+
+		err = api.Storage.AnalyzeCustomQuery(ctx, cq)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if err == influxdb.ErrInvalidCustomQueryFlux {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidCustomQueryFlux))
+				return
+			}
+			if err == storage.ErrCustomQueryNotSupported {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgCustomQueryNotSupported))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to analyze custom query", logger.ErrField(err))
+			return
+		}
+
 		_, identExists, err := api.PG.ExistsCustomQueryIdent(ctx, -1, cq.Ident)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -0,0 +1,96 @@
+package dashboard
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a new team dashboard.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If a panel contextual metric is not of the team or a panel custom query does not exists.
+//   - 404 If team not found.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var d models.Dashboard
+		err = c.ShouldBind(&d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		exists, _, err := api.PG.GetTeam(ctx, int32(teamId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get team", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgTeamNotFound))
+			return
+		}
+
+		valid, err := validPanels(ctx, api, int32(teamId), d.Panels)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to validate dashboard panels", logger.ErrField(err))
+			return
+		}
+		if !valid {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidDashboardPanels))
+			return
+		}
+
+		d.TeamId = int32(teamId)
+		d.UpdatedAt = time.Now().Unix()
+		id, err := api.PG.CreateDashboard(ctx, d, meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create dashboard", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Dashboard created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
@@ -0,0 +1,60 @@
+package dashboard
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// panelsReferences returns the contextual metrics and custom queries used on the
+// panels, without duplicates.
+func panelsReferences(panels []models.DashboardPanel) (ctxMetricsIds []int64, customQueriesIds []int32) {
+	ctxMetricsIds = make([]int64, 0)
+	customQueriesIds = make([]int32, 0)
+	seenCtxMetrics := make(map[int64]struct{})
+	seenCustomQueries := make(map[int32]struct{})
+	for _, p := range panels {
+		for _, id := range p.ContextualMetricsIds {
+			if _, ok := seenCtxMetrics[id]; ok {
+				continue
+			}
+			seenCtxMetrics[id] = struct{}{}
+			ctxMetricsIds = append(ctxMetricsIds, id)
+		}
+		if p.CustomQueryId == 0 {
+			continue
+		}
+		if _, ok := seenCustomQueries[p.CustomQueryId]; ok {
+			continue
+		}
+		seenCustomQueries[p.CustomQueryId] = struct{}{}
+		customQueriesIds = append(customQueriesIds, p.CustomQueryId)
+	}
+	return ctxMetricsIds, customQueriesIds
+}
+
+// validPanels returns true if the panels contextual metrics are of the team
+// contexts and the panels custom queries exists.
+func validPanels(ctx context.Context, api *api.API, teamId int32, panels []models.DashboardPanel) (valid bool, err error) {
+	ctxMetricsIds, customQueriesIds := panelsReferences(panels)
+	if len(ctxMetricsIds) > 0 {
+		n, err := api.PG.CountTeamContextualMetrics(ctx, teamId, ctxMetricsIds)
+		if err != nil {
+			return false, err
+		}
+		if n != len(ctxMetricsIds) {
+			return false, nil
+		}
+	}
+	if len(customQueriesIds) > 0 {
+		n, err := api.PG.CountCustomQueries(ctx, customQueriesIds)
+		if err != nil {
+			return false, err
+		}
+		if n != len(customQueriesIds) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
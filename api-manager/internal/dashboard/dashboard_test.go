@@ -0,0 +1,29 @@
+package dashboard
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestPanelsReferences(t *testing.T) {
+	panels := []models.DashboardPanel{
+		{ContextualMetricsIds: []int64{1, 2}},
+		{ContextualMetricsIds: []int64{2, 3}, CustomQueryId: 5},
+		{CustomQueryId: 5},
+		{CustomQueryId: 7},
+	}
+	ctxMetricsIds, customQueriesIds := panelsReferences(panels)
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(ctxMetricsIds, want) {
+		t.Errorf("panelsReferences contextual metrics failed, want: %v, got: %v", want, ctxMetricsIds)
+	}
+	if want := []int32{5, 7}; !reflect.DeepEqual(customQueriesIds, want) {
+		t.Errorf("panelsReferences custom queries failed, want: %v, got: %v", want, customQueriesIds)
+	}
+
+	ctxMetricsIds, customQueriesIds = panelsReferences(nil)
+	if len(ctxMetricsIds) != 0 || len(customQueriesIds) != 0 {
+		t.Errorf("panelsReferences of no panels failed, want: no references, got: %v, %v", ctxMetricsIds, customQueriesIds)
+	}
+}
@@ -0,0 +1,52 @@
+package dashboard
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Deletes a team dashboard with its versions. Dashboards shared with the team
+// can't be deleted.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func DeleteHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		rawId := c.Param("dashboardId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.DeleteDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to delete dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+		api.Log.Info("Dashboard deleted, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
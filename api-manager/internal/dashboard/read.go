@@ -0,0 +1,108 @@
+package dashboard
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets a dashboard of the team or shared with the team.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func GetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("dashboardId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(d))
+	}
+}
+
+// Gets the dashboards of the team and shared with the team.
+// Params:
+//   - "limit" Limit of dashboards returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//   - "name" Filter by name.
+//   - "descr" Filter by description.
+//   - "order-by" Column to order by.
+//   - "order-by-fn" Order function, "asc" or "desc".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		dashboards, err := api.PG.GetDashboards(ctx, pg.DashboardQueryFilters{
+			ViewerTeamId: int32(teamId),
+			Name:         c.Query("name"),
+			Descr:        c.Query("descr"),
+			OrderBy:      c.Query("order-by"),
+			OrderByFn:    c.Query("order-by-fn"),
+			Limit:        limit,
+			Offset:       offset,
+		})
+		if err != nil {
+			if err == pg.ErrInvalidOrderByColumn || err == pg.ErrInvalidFilterValue || err == pg.ErrInvalidOrderByFn {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboards", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(dashboards))
+	}
+}
@@ -0,0 +1,205 @@
+package dashboard
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets the teams a team dashboard is shared with.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If dashboard not found.
+//   - 200 If succeeded.
+func MGetSharesHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("dashboardId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists || d.TeamId != int32(teamId) {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		teams, err := api.PG.GetDashboardShares(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard shares", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(teams))
+	}
+}
+
+// Shares a team dashboard with other team, which can read it and the data of
+// the contextual metrics on its panels.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If the team is the dashboard owner.
+//   - 404 If dashboard or team not found.
+//   - 200 If succeeded.
+func ShareHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		rawId := c.Param("dashboardId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var share models.DashboardShare
+		err = c.ShouldBind(&share)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(share)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		if share.TeamId == int32(teamId) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgDashboardOwnerShare))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists || d.TeamId != int32(teamId) {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		exists, _, err = api.PG.GetTeam(ctx, share.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get team", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgTeamNotFound))
+			return
+		}
+
+		err = api.PG.ShareDashboard(ctx, int32(id), share.TeamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to share dashboard", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Dashboard shared, id: " + rawId + ", team id: " + strconv.FormatInt(int64(share.TeamId), 10))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Stops sharing a team dashboard with other team.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If dashboard not found or not shared with the team.
+//   - 200 If succeeded.
+func UnshareHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		rawId := c.Param("dashboardId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		shareTeamId, err := strconv.ParseInt(c.Param("shareTeamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists || d.TeamId != int32(teamId) {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		exists, err = api.PG.UnshareDashboard(ctx, int32(id), int32(shareTeamId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to unshare dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardShareNotFound))
+			return
+		}
+		api.Log.Info("Dashboard unshared, id: " + rawId + ", team id: " + c.Param("shareTeamId"))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
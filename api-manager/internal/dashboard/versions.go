@@ -0,0 +1,199 @@
+package dashboard
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets the versions of a dashboard of the team or shared with the team, newest first.
+// Params:
+//   - "limit" Limit of versions returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If dashboard not found.
+//   - 200 If succeeded.
+func MGetVersionsHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("dashboardId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, _, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		versions, err := api.PG.GetDashboardVersions(ctx, int32(id), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard versions", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(versions))
+	}
+}
+
+// Gets a version of a dashboard of the team or shared with the team.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If dashboard or version not found.
+//   - 200 If succeeded.
+func GetVersionHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("dashboardId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		version, err := strconv.ParseInt(c.Param("version"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, current, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboardVersion(ctx, int32(id), int32(version))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard version", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardVersionNotFound))
+			return
+		}
+		d.TeamId = current.TeamId
+
+		c.JSON(http.StatusOK, tools.DataRes(d))
+	}
+}
+
+// Rollbacks a team dashboard to a version, saving it as a new version.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If dashboard or version not found.
+//   - 200 If succeeded.
+func RollbackHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		rawId := c.Param("dashboardId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		version, err := strconv.ParseInt(c.Param("version"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		exists, d, err := api.PG.GetDashboard(ctx, int32(teamId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get dashboard", logger.ErrField(err))
+			return
+		}
+		// shared dashboards can't be changed
+		if !exists || d.TeamId != int32(teamId) {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardNotFound))
+			return
+		}
+
+		exists, newVersion, err := api.PG.RollbackDashboard(ctx, int32(teamId), int32(id), int32(version), time.Now().Unix(), meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to rollback dashboard", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDashboardVersionNotFound))
+			return
+		}
+		api.Log.Info("Dashboard rolled back, id: " + rawId + ", from version: " + c.Param("version") + ", to version: " + strconv.FormatInt(int64(newVersion), 10))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -7,9 +7,9 @@ import (
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
 	"github.com/fernandotsda/nemesys/shared/env"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,7 +17,9 @@ import (
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
+//   - 400 If tiers retentions or intervals are not increasing, or an interval is not shorter than the previous retention.
 //   - 400 If exceeds the maximum number of data policies.
+//   - 400 If an aggregation function is not supported by the storage backend.
 //   - 200 If succeeded.
 func CreateHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -36,11 +38,16 @@ func CreateHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		if !influxdb.ValidateAggrFunction(dp.AggrFn) {
+		if !storage.ValidateDataPolicyAggrFunctions(dp) {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggrFn))
 			return
 		}
 
+		if !storage.ValidateDataPolicyTiers(dp) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidDataPolicyTiers))
+			return
+		}
+
 		n, err := api.PG.CountDataPolicy(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
@@ -75,13 +82,17 @@ func CreateHandler(api *api.API) func(c *gin.Context) {
 
 		dp.Id = id
 
-		err = api.Influx.CreateDataPolicy(ctx, dp)
+		err = api.Storage.CreateDataPolicy(ctx, dp)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
-			api.Log.Error("Fail to create data policy on influxdb", logger.ErrField(err))
-			c.Status(http.StatusInternalServerError)
+			if err == storage.ErrUnsupportedAggrFunction {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggrFn))
+			} else {
+				api.Log.Error("Fail to create data policy on storage backend", logger.ErrField(err))
+				c.Status(http.StatusInternalServerError)
+			}
 			err = tx.Rollback()
 			if err != nil {
 				if ctx.Err() != nil {
@@ -40,13 +40,13 @@ func DeleteHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		err = api.Influx.DeleteDataPolicy(ctx, int16(id))
+		err = api.Storage.DeleteDataPolicy(ctx, int16(id))
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
 			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to delete data policy from influxdb", logger.ErrField(err))
+			api.Log.Error("Fail to delete data policy from storage backend", logger.ErrField(err))
 			return
 		}
 		t.NotifyDataPolicyDeleted(api.Amqph, int16(id))
@@ -6,9 +6,9 @@ import (
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -17,6 +17,8 @@ import (
 //   - 400 If invalid id.
 //   - 400 If invalid body.
 //   - 400 If invalid body fields.
+//   - 400 If tiers retentions or intervals are not increasing, or an interval is not shorter than the previous retention.
+//   - 400 If an aggregation function is not supported by the storage backend.
 //   - 404 If data policy not found.
 //   - 200 If succeeded.
 func UpdateHandler(api *api.API) func(c *gin.Context) {
@@ -42,11 +44,16 @@ func UpdateHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		if !influxdb.ValidateAggrFunction(dp.AggrFn) {
+		if !storage.ValidateDataPolicyAggrFunctions(dp) {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggrFn))
 			return
 		}
 
+		if !storage.ValidateDataPolicyTiers(dp) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidDataPolicyTiers))
+			return
+		}
+
 		dp.Id = int16(id)
 		tx, exists, err := api.PG.UpdateDataPolicy(ctx, dp)
 		if err != nil {
@@ -54,7 +61,7 @@ func UpdateHandler(api *api.API) func(c *gin.Context) {
 				return
 			}
 			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to update data policy on influxdb", logger.ErrField(err))
+			api.Log.Error("Fail to update data policy on postgres", logger.ErrField(err))
 			return
 		}
 		if !exists {
@@ -62,13 +69,17 @@ func UpdateHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		err = api.Influx.UpdateDataPolicy(ctx, dp)
+		err = api.Storage.UpdateDataPolicy(ctx, dp)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
-			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to update data policy on influxdb", logger.ErrField(err))
+			if err == storage.ErrUnsupportedAggrFunction {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAggrFn))
+			} else {
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to update data policy on storage backend", logger.ErrField(err))
+			}
 			err = tx.Rollback()
 			if err != nil {
 				if ctx.Err() != nil {
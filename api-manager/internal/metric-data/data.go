@@ -1,11 +1,14 @@
 package metricdata
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
 	"github.com/fernandotsda/nemesys/shared/amqp"
 	"github.com/fernandotsda/nemesys/shared/amqph"
@@ -16,12 +19,18 @@ import (
 	"github.com/rabbitmq/amqp091-go"
 )
 
+var (
+	ErrMetricDisabled    = errors.New("metric is disabled")
+	ErrInvalidMetricData = errors.New("invalid metric data")
+)
+
 // Adds a metric data to metric data history if enabled and send data
 // to real time service.
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
 //   - 404 If refkey not found.
+//   - 403 If metric container is out of the API Key scopes.
 //   - 400 If metric is disabled.
 //   - 200 If succeeded.
 func AddHandler(api *api.API) func(c *gin.Context) {
@@ -41,139 +50,170 @@ func AddHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		var form models.BasicMetricAddDataForm
-
-		cacheRes, err := api.Cache.GetMetricAddDataForm(ctx, data.Refkey)
+		exists, form, err := getAddDataForm(ctx, api, data.Refkey)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
 			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to get metricAddDataForm on cache", logger.ErrField(err))
+			api.Log.Error("Fail to get metric add data form", logger.ErrField(err))
 			return
 		}
-		form = cacheRes.Form
-
-		if !cacheRes.Exists {
-			exists, rk, err := api.PG.GetRefkey(ctx, data.Refkey)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.Status(http.StatusInternalServerError)
-				api.Log.Error("Fail to get refkey", logger.ErrField(err))
-				return
-			}
-			if !exists {
-				c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgRefkeyNotFound))
-				return
-			}
-
-			r, err := api.PG.GetMetricRequest(ctx, rk.MetricId)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.Status(http.StatusInternalServerError)
-				api.Log.Error("Fail to get metric request", logger.ErrField(err))
-				return
-			}
-
-			_, enabled, err := api.PG.GetMetricDHSEnabled(ctx, rk.MetricId)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.Status(http.StatusInternalServerError)
-				api.Log.Error("Fail to check if metric dhs_enabled is enabled", logger.ErrField(err))
-				return
-			}
-
-			form.MetricId = r.MetricRequest.MetricId
-			form.DataPolicyId = r.MetricRequest.DataPolicyId
-			form.MetricType = r.MetricRequest.MetricType
-			form.ContainerId = r.MetricRequest.ContainerId
-			form.Enabled = r.Enabled
-			form.DHSEnabled = enabled
-
-			err = api.Cache.SetMetricAddDataForm(ctx, data.Refkey, form)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.Status(http.StatusInternalServerError)
-				api.Log.Error("Fail to set metricAddDataForm on cache", logger.ErrField(err))
-				return
-			}
-		}
-
-		if !form.Enabled {
-			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgMetricDisabled))
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgRefkeyNotFound))
 			return
 		}
 
-		value, err := types.ParseValue(data.Value, form.MetricType)
+		meta, err := tools.GetSessionMeta(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidMetricData))
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+		if !auth.ScopesAllowIngest(meta.Scopes, form.ContainerId) {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgOutOfAPIKeyScopes))
 			return
 		}
 
-		metricDataResponse := models.MetricDataResponse{
-			MetricBasicDataReponse: models.MetricBasicDataReponse{
-				Id:           form.MetricId,
-				Type:         form.MetricType,
-				Value:        value,
-				DataPolicyId: form.DataPolicyId,
-				Failed:       false,
-			},
-			ContainerId: form.ContainerId,
+		var timestamp time.Time
+		if data.Timestamp > 0 {
+			timestamp = time.Unix(data.Timestamp, 0)
+		} else {
+			timestamp = time.Now()
 		}
 
-		metricIdString := strconv.FormatInt(form.MetricId, 10)
-		if form.DHSEnabled {
-			var timestamp time.Time
-			if data.Timestamp > 0 {
-				timestamp = time.Unix(data.Timestamp, 0)
-			} else {
-				timestamp = time.Now()
+		err = addData(ctx, api, form, data.Value, timestamp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
-
-			err = api.Influx.WritePoint(ctx, metricDataResponse, timestamp)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				c.Status(http.StatusInternalServerError)
-				api.Log.Error("Fail to write point in influxdb", logger.ErrField(err))
+			if err == ErrMetricDisabled {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgMetricDisabled))
 				return
 			}
-			api.Log.Debug("Metric data point saved on influxdb, metric id: " + metricIdString)
+			if err == ErrInvalidMetricData {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidMetricData))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to add metric data", logger.ErrField(err))
+			return
 		}
+	}
+}
+
+// getAddDataForm returns the add data form of a refkey. Try to get the form on cache,
+// if cache is missing goes to database and save on cache after.
+func getAddDataForm(ctx context.Context, api *api.API, refkey string) (exists bool, form models.BasicMetricAddDataForm, err error) {
+	cacheRes, err := api.Cache.GetMetricAddDataForm(ctx, refkey)
+	if err != nil {
+		return false, form, err
+	}
+	if cacheRes.Exists {
+		return true, cacheRes.Form, nil
+	}
+
+	exists, rk, err := api.PG.GetRefkey(ctx, refkey)
+	if err != nil {
+		return false, form, err
+	}
+	if !exists {
+		return false, form, nil
+	}
 
-		b, err := amqp.Encode(metricDataResponse)
+	r, err := api.PG.GetMetricRequest(ctx, rk.MetricId)
+	if err != nil {
+		return false, form, err
+	}
+
+	_, enabled, err := api.PG.GetMetricDHSEnabled(ctx, rk.MetricId)
+	if err != nil {
+		return false, form, err
+	}
+
+	form.MetricId = r.MetricRequest.MetricId
+	form.DataPolicyId = r.MetricRequest.DataPolicyId
+	form.MetricType = r.MetricRequest.MetricType
+	form.ContainerId = r.MetricRequest.ContainerId
+	form.Enabled = r.Enabled
+	form.DHSEnabled = enabled
+
+	err = api.Cache.SetMetricAddDataForm(ctx, refkey, form)
+	if err != nil {
+		return false, form, err
+	}
+	return true, form, nil
+}
+
+// addData writes the value on the data history if enabled and sends it to the
+// alarm service and to the real time service. Returns ErrMetricDisabled if the
+// metric is disabled and ErrInvalidMetricData if value could not be parsed.
+func addData(ctx context.Context, api *api.API, form models.BasicMetricAddDataForm, rawValue any, timestamp time.Time) (err error) {
+	value, err := parseData(form, rawValue)
+	if err != nil {
+		return err
+	}
+	return writeData(ctx, api, form, value, timestamp)
+}
+
+// parseData parses the value to the metric type. Returns ErrMetricDisabled if the
+// metric is disabled and ErrInvalidMetricData if value could not be parsed.
+func parseData(form models.BasicMetricAddDataForm, rawValue any) (value any, err error) {
+	if !form.Enabled {
+		return nil, ErrMetricDisabled
+	}
+	value, err = types.ParseValue(rawValue, form.MetricType)
+	if err != nil {
+		return nil, ErrInvalidMetricData
+	}
+	return value, nil
+}
+
+// writeData writes the parsed value on the data history if enabled and sends it
+// to the alarm service and to the real time service.
+func writeData(ctx context.Context, api *api.API, form models.BasicMetricAddDataForm, value any, timestamp time.Time) (err error) {
+	metricDataResponse := models.MetricDataResponse{
+		MetricBasicDataReponse: models.MetricBasicDataReponse{
+			Id:           form.MetricId,
+			Type:         form.MetricType,
+			Value:        value,
+			DataPolicyId: form.DataPolicyId,
+			Failed:       false,
+		},
+		ContainerId: form.ContainerId,
+	}
+
+	metricIdString := strconv.FormatInt(form.MetricId, 10)
+	if form.DHSEnabled {
+		err = api.Storage.WritePoint(ctx, metricDataResponse, timestamp)
 		if err != nil {
-			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to encode metric data response", logger.ErrField(err))
-			return
+			return err
 		}
+		api.Log.Debug("Metric data point saved on storage backend, metric id: " + metricIdString)
+	}
 
-		api.Amqph.Publish(amqph.Publish{
-			Exchange: amqp.ExchangeCheckMetricAlarm,
-			Publishing: amqp091.Publishing{
-				Body: b,
-				Type: amqp.FromMessageType(amqp.OK),
-			},
-		})
-
-		api.Amqph.Publish(amqph.Publish{
-			Exchange:   amqp.ExchangeMetricDataRes,
-			RoutingKey: "rts",
-			Publishing: amqp091.Publishing{
-				Body: b,
-				Type: amqp.FromMessageType(amqp.OK),
-			},
-		})
-
-		api.Log.Debug("Metric data sent to Alarm service and RTS, metric id: " + metricIdString)
+	b, err := amqp.Encode(metricDataResponse)
+	if err != nil {
+		return err
 	}
+
+	api.Amqph.Publish(amqph.Publish{
+		Exchange: amqp.ExchangeCheckMetricAlarm,
+		Publishing: amqp091.Publishing{
+			Body: b,
+			Type: amqp.FromMessageType(amqp.OK),
+		},
+	})
+
+	api.Amqph.Publish(amqph.Publish{
+		Exchange:   amqp.ExchangeMetricDataRes,
+		RoutingKey: "rts",
+		Publishing: amqp091.Publishing{
+			Body: b,
+			Type: amqp.FromMessageType(amqp.OK),
+		},
+	})
+
+	api.Log.Debug("Metric data sent to Alarm service and RTS, metric id: " + metricIdString)
+	return nil
 }
@@ -0,0 +1,355 @@
+package metricdata
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+	"github.com/fernandotsda/nemesys/shared/uuid"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxImportBodySize is the maximum size of an import file.
+	maxImportBodySize = 1 << 30
+	// maxImportRejectedRows is the maximum number of rejected rows kept on the job.
+	maxImportRejectedRows = 100
+	// importProgressRows is the number of rows between job progress updates.
+	importProgressRows = 5000
+)
+
+const (
+	reasonMetricNotFound    = "metric not found"
+	reasonHistoryDisabled   = "metric data history is disabled"
+	reasonNoHistory         = "metric type has no data history"
+	reasonInvalidValue      = "invalid value for the metric type"
+	reasonOutOfRetention    = "timestamp is older than the data policy retention"
+	reasonOutOfScopes       = "metric container is out of the api key scopes"
+	importJobFailedErrorMsg = "fail to write data points"
+)
+
+// importForm is the import data form of a metric.
+type importForm struct {
+	exists bool
+	form   models.BasicMetricAddDataForm
+}
+
+// countingReader counts the bytes read.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// Imports the data history of metrics from a csv or ndjson file on the body. The
+// file is imported in background, writing each point with the original timestamp on the
+// data policy tier that keeps it, without sending it to the alarm service and the real time
+// service. The csv header must have the "timestamp", "value" and "refkey" or "metric_id"
+// columns, and each ndjson line the same keys. Rows are keyed by refkey or, if empty, by
+// metric id. Timestamps are unix seconds or RFC3339.
+// Params:
+//   - "format" File format, "csv" or "ndjson". Default is "csv".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If invalid csv header.
+//   - 413 If body is too large.
+//   - 200 If succeeded, with the import job.
+func ImportHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		format := tools.DefaultQuery(c, "format", ImportFormatCSV)
+		if !ValidateImportFormat(format) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		f, err := os.CreateTemp("", "nemesys-import-*")
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create import temporary file", logger.ErrField(err))
+			return
+		}
+		remove := func() {
+			f.Close()
+			os.Remove(f.Name())
+		}
+
+		size, err := io.Copy(f, http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodySize))
+		if err != nil {
+			remove()
+			if ctx.Err() != nil {
+				return
+			}
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, tools.MsgRes(tools.MsgBodyTooLarge))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to write import temporary file", logger.ErrField(err))
+			return
+		}
+
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			remove()
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to seek import temporary file", logger.ErrField(err))
+			return
+		}
+
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			remove()
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		counter := &countingReader{r: f}
+		reader, err := newImportReader(counter, format)
+		if err != nil {
+			remove()
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidImportHeader))
+			return
+		}
+
+		id, err := uuid.New()
+		if err != nil {
+			remove()
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to generate new uuid", logger.ErrField(err))
+			return
+		}
+
+		job := models.ImportJob{
+			Id:           id,
+			Status:       models.ImportJobRunning,
+			Format:       format,
+			Size:         size,
+			RejectedRows: make([]models.ImportRejectedRow, 0),
+			CreatedAt:    time.Now().Unix(),
+		}
+		err = api.Cache.SetImportJob(ctx, job)
+		if err != nil {
+			remove()
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to save import job", logger.ErrField(err))
+			return
+		}
+
+		go func() {
+			defer remove()
+			runImport(api, job, reader, counter, meta.Scopes)
+		}()
+		api.Log.Info("Import job started, id: " + id)
+
+		c.JSON(http.StatusOK, tools.DataRes(job))
+	}
+}
+
+// Gets an import job progress.
+// Responses:
+//   - 404 If not found.
+//   - 200 If succeeded.
+func GetImportHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		r, err := api.Cache.GetImportJob(ctx, c.Param("jobId"))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get import job", logger.ErrField(err))
+			return
+		}
+		if !r.Exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgImportJobNotFound))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(r.Job))
+	}
+}
+
+// runImport imports the rows, saving the job progress periodically. Rows of
+// containers out of the API Key scopes are rejected.
+func runImport(api *api.API, job models.ImportJob, reader importReader, counter *countingReader, scopes []models.APIKeyScope) {
+	ctx := context.Background()
+	forms := make(map[string]importForm)
+
+	var w storage.HistoryWriter
+	save := func() {
+		job.Read = counter.n
+		if w != nil {
+			job.Imported = w.Written()
+		}
+		err := api.Cache.SetImportJob(ctx, job)
+		if err != nil {
+			api.Log.Error("Fail to save import job", logger.ErrField(err))
+		}
+	}
+
+	dps, err := api.PG.GetDataPolicies(ctx)
+	if err != nil {
+		api.Log.Error("Fail to get data policies", logger.ErrField(err))
+		job.Status = models.ImportJobFailed
+		job.Error = importJobFailedErrorMsg
+		job.FinishedAt = time.Now().Unix()
+		save()
+		return
+	}
+	w = api.Storage.NewHistoryWriter(dps)
+
+	for {
+		row, reason, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			job.Status = models.ImportJobFailed
+			job.Error = err.Error()
+			break
+		}
+		job.Rows++
+
+		if reason == "" {
+			reason, err = importData(ctx, api, w, forms, scopes, row)
+			if err != nil {
+				api.Log.Error("Fail to import metric data", logger.ErrField(err))
+				job.Status = models.ImportJobFailed
+				job.Error = importJobFailedErrorMsg
+				break
+			}
+		}
+		if reason != "" {
+			job.Rejected++
+			if len(job.RejectedRows) < maxImportRejectedRows {
+				job.RejectedRows = append(job.RejectedRows, models.ImportRejectedRow{Line: row.line, Reason: reason})
+			}
+		}
+
+		if job.Rows%importProgressRows == 0 {
+			save()
+		}
+	}
+	if job.Status == models.ImportJobRunning {
+		err = w.Flush()
+		if err != nil {
+			api.Log.Error("Fail to import metric data", logger.ErrField(err))
+			job.Status = models.ImportJobFailed
+			job.Error = importJobFailedErrorMsg
+		} else {
+			job.Status = models.ImportJobDone
+		}
+	}
+	job.FinishedAt = time.Now().Unix()
+	save()
+	api.Log.Info("Import job finished, id: " + job.Id + ", imported: " + strconv.FormatInt(job.Imported, 10) +
+		", rejected: " + strconv.FormatInt(job.Rejected, 10))
+}
+
+// importData writes the row on the data history. Returns the rejection reason if
+// the row can't be imported.
+func importData(ctx context.Context, api *api.API, w storage.HistoryWriter, forms map[string]importForm, scopes []models.APIKeyScope, row importRow) (reason string, err error) {
+	key := "m:" + strconv.FormatInt(row.metricId, 10)
+	if row.refkey != "" {
+		key = "r:" + row.refkey
+	}
+	f, ok := forms[key]
+	if !ok {
+		f, err = getImportForm(ctx, api, row)
+		if err != nil {
+			return reason, err
+		}
+		forms[key] = f
+	}
+	if !f.exists {
+		return reasonMetricNotFound, nil
+	}
+	if !auth.ScopesAllowIngest(scopes, f.form.ContainerId) {
+		return reasonOutOfScopes, nil
+	}
+	if !f.form.DHSEnabled {
+		return reasonHistoryDisabled, nil
+	}
+	if f.form.MetricType == types.MTString {
+		return reasonNoHistory, nil
+	}
+
+	value, err := types.ParseValue(row.value, f.form.MetricType)
+	if err != nil {
+		return reasonInvalidValue, nil
+	}
+
+	err = w.WritePoint(models.MetricDataResponse{
+		MetricBasicDataReponse: models.MetricBasicDataReponse{
+			Id:           f.form.MetricId,
+			Type:         f.form.MetricType,
+			Value:        value,
+			DataPolicyId: f.form.DataPolicyId,
+		},
+		ContainerId: f.form.ContainerId,
+	}, time.Unix(row.timestamp, 0))
+	if err != nil {
+		if err == storage.ErrPointOutOfRetention {
+			return reasonOutOfRetention, nil
+		}
+		return reason, err
+	}
+	return "", nil
+}
+
+// getImportForm returns the import form of the row metric, by refkey or by metric id.
+func getImportForm(ctx context.Context, api *api.API, row importRow) (f importForm, err error) {
+	if row.refkey != "" {
+		f.exists, f.form, err = getAddDataForm(ctx, api, row.refkey)
+		return f, err
+	}
+
+	r, err := api.PG.GetMetricRequest(ctx, row.metricId)
+	if err != nil {
+		return f, err
+	}
+	if !r.Exists {
+		return f, nil
+	}
+
+	_, enabled, err := api.PG.GetMetricDHSEnabled(ctx, row.metricId)
+	if err != nil {
+		return f, err
+	}
+
+	f.form.MetricId = r.MetricRequest.MetricId
+	f.form.DataPolicyId = r.MetricRequest.DataPolicyId
+	f.form.MetricType = r.MetricRequest.MetricType
+	f.form.ContainerId = r.MetricRequest.ContainerId
+	f.form.Enabled = r.Enabled
+	f.form.DHSEnabled = enabled
+	f.exists = true
+	return f, nil
+}
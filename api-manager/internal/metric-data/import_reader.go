@@ -0,0 +1,227 @@
+package metricdata
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// maxImportLineSize is the maximum size of a ndjson line.
+const maxImportLineSize = 1024 * 1024
+
+const (
+	reasonInvalidRow       = "invalid row"
+	reasonInvalidTimestamp = "invalid timestamp"
+	reasonMissingKey       = "missing refkey or metric id"
+	reasonMissingValue     = "missing value"
+)
+
+var (
+	ErrInvalidImportHeader    = errors.New("csv header must have timestamp, value and refkey or metric_id columns")
+	ErrInvalidImportTimestamp = errors.New("invalid import timestamp")
+)
+
+// importRow is a row of the import file. Rows are keyed by refkey or, if
+// empty, by metric id.
+type importRow struct {
+	// line is the file line of the row.
+	line int64
+	// refkey is the metric refkey.
+	refkey string
+	// metricId is the metric id.
+	metricId int64
+	// timestamp is the timestamp in unix seconds.
+	timestamp int64
+	// value is the raw value.
+	value any
+}
+
+// importReader reads the rows of an import file.
+type importReader interface {
+	// Next returns the next row. If the row is invalid, reason is the rejection
+	// reason. Returns io.EOF at the end of the file.
+	Next() (row importRow, reason string, err error)
+}
+
+// ValidateImportFormat validates the import format.
+func ValidateImportFormat(format string) bool {
+	return format == ImportFormatCSV || format == ImportFormatNDJSON
+}
+
+// newImportReader returns an import reader for the format. The csv header is
+// read and validated.
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	if format == ImportFormatNDJSON {
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 64*1024), maxImportLineSize)
+		return &ndjsonImportReader{s: s}, nil
+	}
+
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	c.ReuseRecord = true
+	header, err := c.Read()
+	if err != nil {
+		return nil, ErrInvalidImportHeader
+	}
+	ir := &csvImportReader{r: c, timestamp: -1, value: -1, refkey: -1, metricId: -1}
+	for i, column := range header {
+		switch strings.TrimSpace(column) {
+		case "timestamp":
+			ir.timestamp = i
+		case "value":
+			ir.value = i
+		case "refkey":
+			ir.refkey = i
+		case "metric_id":
+			ir.metricId = i
+		}
+	}
+	if ir.timestamp < 0 || ir.value < 0 || (ir.refkey < 0 && ir.metricId < 0) {
+		return nil, ErrInvalidImportHeader
+	}
+	return ir, nil
+}
+
+// parseImportTimestamp parses a timestamp in unix seconds or RFC3339.
+func parseImportTimestamp(s string) (timestamp int64, err error) {
+	timestamp, err = strconv.ParseInt(s, 10, 64)
+	if err == nil {
+		return timestamp, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+type csvImportReader struct {
+	r *csv.Reader
+	// columns indexes, -1 if missing
+	timestamp int
+	value     int
+	refkey    int
+	metricId  int
+}
+
+func (r *csvImportReader) Next() (row importRow, reason string, err error) {
+	record, err := r.r.Read()
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			row.line = int64(perr.Line)
+			return row, reasonInvalidRow, nil
+		}
+		return row, reason, err
+	}
+	line, _ := r.r.FieldPos(0)
+	row.line = int64(line)
+
+	column := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row.refkey = column(r.refkey)
+	if row.refkey == "" {
+		rawId := column(r.metricId)
+		if rawId == "" {
+			return row, reasonMissingKey, nil
+		}
+		row.metricId, err = strconv.ParseInt(rawId, 10, 64)
+		if err != nil {
+			return row, reasonInvalidRow, nil
+		}
+	}
+
+	row.timestamp, err = parseImportTimestamp(column(r.timestamp))
+	if err != nil {
+		return row, reasonInvalidTimestamp, nil
+	}
+
+	value := column(r.value)
+	if value == "" {
+		return row, reasonMissingValue, nil
+	}
+	row.value = value
+	return row, "", nil
+}
+
+type ndjsonImportReader struct {
+	s    *bufio.Scanner
+	line int64
+}
+
+type ndjsonImportRow struct {
+	Refkey    string `json:"refkey"`
+	MetricId  int64  `json:"metric_id"`
+	Timestamp any    `json:"timestamp"`
+	Value     any    `json:"value"`
+}
+
+func (r *ndjsonImportReader) Next() (row importRow, reason string, err error) {
+	for {
+		if !r.s.Scan() {
+			if err = r.s.Err(); err != nil {
+				return row, reason, err
+			}
+			return row, reason, io.EOF
+		}
+		r.line++
+		if len(strings.TrimSpace(r.s.Text())) > 0 {
+			break
+		}
+	}
+	row.line = r.line
+
+	var raw ndjsonImportRow
+	d := json.NewDecoder(strings.NewReader(r.s.Text()))
+	d.UseNumber()
+	err = d.Decode(&raw)
+	if err != nil {
+		return row, reasonInvalidRow, nil
+	}
+
+	row.refkey = raw.Refkey
+	row.metricId = raw.MetricId
+	if row.refkey == "" && row.metricId == 0 {
+		return row, reasonMissingKey, nil
+	}
+
+	switch v := raw.Timestamp.(type) {
+	case json.Number:
+		row.timestamp, err = parseImportTimestamp(v.String())
+	case string:
+		row.timestamp, err = parseImportTimestamp(v)
+	default:
+		err = ErrInvalidImportTimestamp
+	}
+	if err != nil {
+		return row, reasonInvalidTimestamp, nil
+	}
+
+	switch v := raw.Value.(type) {
+	case nil:
+		return row, reasonMissingValue, nil
+	case json.Number:
+		row.value = v.String()
+	case string, bool:
+		row.value = v
+	default:
+		return row, reasonInvalidRow, nil
+	}
+	return row, "", nil
+}
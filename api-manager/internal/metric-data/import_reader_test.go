@@ -0,0 +1,90 @@
+package metricdata
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type importResult struct {
+	row    importRow
+	reason string
+}
+
+func readAllImportRows(t *testing.T, r importReader) (results []importResult) {
+	for {
+		row, reason, err := r.Next()
+		if err == io.EOF {
+			return results
+		}
+		if err != nil {
+			t.Fatalf("Next failed, err: %v", err)
+		}
+		if reason != "" {
+			row = importRow{line: row.line}
+		}
+		results = append(results, importResult{row, reason})
+	}
+}
+
+func TestCSVImportReader(t *testing.T) {
+	data := "value,timestamp,metric_id,refkey\n" +
+		"10,1670000000,1,\n" +
+		"true,2022-12-02T16:53:20Z,,rk\n" +
+		"1,x,1,\n" +
+		",1670000000,1,\n" +
+		"1,1670000000,,\n" +
+		"1,\"1670000000,1\n"
+
+	r, err := newImportReader(strings.NewReader(data), ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("newImportReader failed, err: %v", err)
+	}
+	want := []importResult{
+		{importRow{line: 2, metricId: 1, timestamp: 1670000000, value: "10"}, ""},
+		{importRow{line: 3, refkey: "rk", timestamp: 1670000000, value: "true"}, ""},
+		{importRow{line: 4}, reasonInvalidTimestamp},
+		{importRow{line: 5}, reasonMissingValue},
+		{importRow{line: 6}, reasonMissingKey},
+		{importRow{line: 7}, reasonInvalidRow},
+	}
+	got := readAllImportRows(t, r)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("csv import reader failed, want: %+v, got: %+v", want, got)
+	}
+
+	_, err = newImportReader(strings.NewReader("time,value,refkey\n"), ImportFormatCSV)
+	if err != ErrInvalidImportHeader {
+		t.Errorf("newImportReader with invalid header failed, want: %v, got: %v", ErrInvalidImportHeader, err)
+	}
+}
+
+func TestNDJSONImportReader(t *testing.T) {
+	data := `{"metric_id": 1, "timestamp": 1670000000, "value": 9007199254740993}` + "\n" +
+		"\n" +
+		`{"refkey": "rk", "timestamp": "2022-12-02T16:53:20Z", "value": false}` + "\n" +
+		`{"refkey": "rk", "timestamp": 1670000000}` + "\n" +
+		`{"timestamp": 1670000000, "value": 1}` + "\n" +
+		`{"refkey": "rk", "timestamp": true, "value": 1}` + "\n" +
+		`{"refkey": "rk", "timestamp": 1670000000, "value": [1]}` + "\n" +
+		`{"refkey": `
+
+	r, err := newImportReader(strings.NewReader(data), ImportFormatNDJSON)
+	if err != nil {
+		t.Fatalf("newImportReader failed, err: %v", err)
+	}
+	want := []importResult{
+		{importRow{line: 1, metricId: 1, timestamp: 1670000000, value: "9007199254740993"}, ""},
+		{importRow{line: 3, refkey: "rk", timestamp: 1670000000, value: false}, ""},
+		{importRow{line: 4}, reasonMissingValue},
+		{importRow{line: 5}, reasonMissingKey},
+		{importRow{line: 6}, reasonInvalidTimestamp},
+		{importRow{line: 7}, reasonInvalidRow},
+		{importRow{line: 8}, reasonInvalidRow},
+	}
+	got := readAllImportRows(t, r)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ndjson import reader failed, want: %+v, got: %+v", want, got)
+	}
+}
@@ -0,0 +1,164 @@
+package metricdata
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrInvalidWriteRequest = errors.New("invalid remote write request")
+
+// promLabel is a Prometheus label.
+type promLabel struct {
+	// Name is the label name.
+	Name string
+	// Value is the label value.
+	Value string
+}
+
+// promSample is a Prometheus sample.
+type promSample struct {
+	// Value is the sample value.
+	Value float64
+	// Timestamp is the sample timestamp in UNIX miliseconds.
+	Timestamp int64
+}
+
+// promTimeSeries is a Prometheus time series.
+type promTimeSeries struct {
+	// Labels is the series labels.
+	Labels []promLabel
+	// Samples is the series samples.
+	Samples []promSample
+}
+
+// label returns the value of the label with the given name.
+func (ts promTimeSeries) label(name string) (value string, ok bool) {
+	for _, l := range ts.Labels {
+		if l.Name == name {
+			return l.Value, true
+		}
+	}
+	return "", false
+}
+
+// decodeWriteRequest decodes a Prometheus remote write request (prometheus.WriteRequest)
+// protobuf message. Metadata and unknown fields are ignored.
+func decodeWriteRequest(b []byte) (series []promTimeSeries, err error) {
+	series = []promTimeSeries{}
+	err = rangeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := decodeTimeSeries(v)
+		if err != nil {
+			return err
+		}
+		series = append(series, ts)
+		return nil
+	})
+	return series, err
+}
+
+func decodeTimeSeries(b []byte) (ts promTimeSeries, err error) {
+	err = rangeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			l, err := decodeLabel(v)
+			if err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+		case 2:
+			s, err := decodeSample(v)
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, s)
+		}
+		return nil
+	})
+	return ts, err
+}
+
+func decodeLabel(b []byte) (l promLabel, err error) {
+	err = rangeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			l.Name = string(v)
+		case 2:
+			l.Value = string(v)
+		}
+		return nil
+	})
+	return l, err
+}
+
+func decodeSample(b []byte) (s promSample, err error) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return s, ErrInvalidWriteRequest
+		}
+		b = b[n:]
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			if n < 0 {
+				return s, ErrInvalidWriteRequest
+			}
+			s.Value = math.Float64frombits(v)
+			b = b[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return s, ErrInvalidWriteRequest
+			}
+			s.Timestamp = int64(v)
+			b = b[n:]
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return s, ErrInvalidWriteRequest
+			}
+			b = b[n:]
+		}
+	}
+	return s, nil
+}
+
+// rangeFields calls fn for each field of a protobuf message. Only length delimited
+// fields values are passed to fn, other types are skipped after calling fn with nil value.
+func rangeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ErrInvalidWriteRequest
+		}
+		b = b[n:]
+
+		var v []byte
+		if typ == protowire.BytesType {
+			v, n = protowire.ConsumeBytes(b)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return ErrInvalidWriteRequest
+		}
+		b = b[n:]
+
+		err := fn(num, typ, v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
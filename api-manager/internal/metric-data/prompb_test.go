@@ -0,0 +1,74 @@
+package metricdata
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendLabel(b []byte, name, value string) []byte {
+	var l []byte
+	l = protowire.AppendTag(l, 1, protowire.BytesType)
+	l = protowire.AppendString(l, name)
+	l = protowire.AppendTag(l, 2, protowire.BytesType)
+	l = protowire.AppendString(l, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, l)
+}
+
+func appendSample(b []byte, value float64, timestamp int64) []byte {
+	var s []byte
+	s = protowire.AppendTag(s, 1, protowire.Fixed64Type)
+	s = protowire.AppendFixed64(s, math.Float64bits(value))
+	s = protowire.AppendTag(s, 2, protowire.VarintType)
+	s = protowire.AppendVarint(s, uint64(timestamp))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, s)
+}
+
+func TestDecodeWriteRequest(t *testing.T) {
+	var ts []byte
+	ts = appendLabel(ts, "__name__", "node_load1")
+	ts = appendLabel(ts, "instance", "10.0.0.1:9100")
+	ts = appendSample(ts, 1.5, 1666000000000)
+	ts = appendSample(ts, 2.5, 1666000015000)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, ts)
+	// metadata field must be ignored
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, []byte{})
+
+	series, err := decodeWriteRequest(b)
+	if err != nil {
+		t.Fatalf("fail to decode write request, err: %s", err)
+	}
+	if len(series) != 1 {
+		t.Fatalf("invalid series length, want: 1, got: %d", len(series))
+	}
+	if len(series[0].Labels) != 2 || len(series[0].Samples) != 2 {
+		t.Fatalf("invalid series, got: %v", series[0])
+	}
+	s := series[0].Samples[1]
+	if s.Value != 2.5 || s.Timestamp != 1666000015000 {
+		t.Errorf("invalid sample, want: {2.5 1666000015000}, got: %v", s)
+	}
+
+	refkey, ok := seriesRefkey(series[0], []string{"__name__", "instance"})
+	if !ok || refkey != "node_load1:10.0.0.1:9100" {
+		t.Errorf("invalid refkey, want: node_load1:10.0.0.1:9100, got: %s", refkey)
+	}
+	_, ok = seriesRefkey(series[0], []string{"__name__", "job"})
+	if ok {
+		t.Error("refkey with missing label must fail")
+	}
+}
+
+func TestDecodeWriteRequestInvalid(t *testing.T) {
+	_, err := decodeWriteRequest([]byte{0x0a, 0xff})
+	if err != ErrInvalidWriteRequest {
+		t.Errorf("want: %s, got: %v", ErrInvalidWriteRequest, err)
+	}
+}
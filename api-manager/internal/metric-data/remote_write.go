@@ -0,0 +1,319 @@
+package metricdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/snappy"
+)
+
+const (
+	// maxRemoteWriteBodySize is the max size of a compressed remote write body.
+	maxRemoteWriteBodySize = 32 << 20
+	// maxRemoteWriteDecodedSize is the max size of a decompressed remote write body.
+	maxRemoteWriteDecodedSize = 128 << 20
+	// defaultRefkeyLabels is the default labels used to build a refkey.
+	defaultRefkeyLabels = "__name__,instance"
+	// refkeySeparator is the separator of the label values on a refkey.
+	refkeySeparator = ":"
+	// remoteWriteRTSCacheDuration is the RTS cache duration in miliseconds of
+	// the metrics created by the remote write.
+	remoteWriteRTSCacheDuration = 60000
+)
+
+// Receives a Prometheus remote write request (snappy compressed protobuf) and adds
+// each sample as a metric data, using the same path as the metric data add. The series
+// are mapped to metrics by the refkey built with the values of the "refkey-labels".
+// Params:
+//   - "refkey-labels" Comma separated labels used to build the refkey, the label values are joined
+//     with ":". Default is "__name__,instance".
+//   - "container-id" Basic container to create the missing metrics. If omitted, series without
+//     refkey are ignored.
+//   - "data-policy-id" Data policy of the created metrics. Required if "container-id" is set.
+//
+// Series of containers out of the API Key scopes are ignored. All series are validated
+// before any sample is written, but the samples are written one by one, so a request
+// that fails while writing may have written part of its samples.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 413 If body is too large.
+//   - 403 If container is out of the API Key scopes.
+//   - 404 If container not found.
+//   - 404 If data policy not found.
+//   - 200 If succeeded.
+func RemoteWriteHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		labels := strings.Split(tools.DefaultQuery(c, "refkey-labels", defaultRefkeyLabels), ",")
+
+		containerId, err := tools.IntQuery(c, "container-id", 0)
+		if err != nil || containerId < 0 {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		dataPolicyId, err := tools.IntQuery(c, "data-policy-id", 0)
+		if err != nil || dataPolicyId < 0 || dataPolicyId > math.MaxInt16 {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		if containerId != 0 {
+			if !auth.ScopesAllowIngest(meta.Scopes, int32(containerId)) {
+				c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgOutOfAPIKeyScopes))
+				return
+			}
+			if dataPolicyId == 0 {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			r, err := api.PG.MetricContainerAndDataPolicyExists(ctx, models.BaseMetric{
+				Id:            -1,
+				ContainerId:   int32(containerId),
+				ContainerType: types.CTBasic,
+				DataPolicyId:  int16(dataPolicyId),
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to check container and data policy existence", logger.ErrField(err))
+				return
+			}
+			if !r.ContainerExists {
+				c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContainerNotFound))
+				return
+			}
+			if !r.DataPolicyExists {
+				c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDataPolicyNotFound))
+				return
+			}
+		}
+
+		compressed, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxRemoteWriteBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, tools.MsgRes(tools.MsgBodyTooLarge))
+				return
+			}
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		size, err := snappy.DecodedLen(compressed)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		if size > maxRemoteWriteDecodedSize {
+			c.JSON(http.StatusRequestEntityTooLarge, tools.MsgRes(tools.MsgBodyTooLarge))
+			return
+		}
+
+		b, err := snappy.Decode(nil, compressed)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		series, err := decodeWriteRequest(b)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		// validates all series before writing, the missing metrics are only
+		// created on the write
+		writes := make([]remoteWrite, 0, len(series))
+		byRefkey := make(map[string]int, len(series))
+		var samples, rejected int
+		for _, ts := range series {
+			refkey, ok := seriesRefkey(ts, labels)
+			if !ok {
+				rejected += len(ts.Samples)
+				continue
+			}
+
+			i, ok := byRefkey[refkey]
+			if !ok {
+				w := remoteWrite{refkey: refkey, series: ts}
+				exists, form, err := getAddDataForm(ctx, api, refkey)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					c.Status(http.StatusInternalServerError)
+					api.Log.Error("Fail to get metric add data form", logger.ErrField(err))
+					return
+				}
+				if !exists {
+					if containerId == 0 {
+						rejected += len(ts.Samples)
+						continue
+					}
+					// the created metrics are enabled float metrics
+					w.create = true
+					form = models.BasicMetricAddDataForm{
+						MetricType:   types.MTFloat,
+						ContainerId:  int32(containerId),
+						DataPolicyId: int16(dataPolicyId),
+						Enabled:      true,
+						DHSEnabled:   true,
+					}
+				}
+				if !auth.ScopesAllowIngest(meta.Scopes, form.ContainerId) {
+					rejected += len(ts.Samples)
+					continue
+				}
+				w.form = form
+				i = len(writes)
+				byRefkey[refkey] = i
+				writes = append(writes, w)
+			}
+
+			for _, s := range ts.Samples {
+				if math.IsNaN(s.Value) {
+					rejected++
+					continue
+				}
+				value, err := parseData(writes[i].form, s.Value)
+				if err != nil {
+					rejected++
+					continue
+				}
+				writes[i].values = append(writes[i].values, value)
+				writes[i].timestamps = append(writes[i].timestamps, time.UnixMilli(s.Timestamp))
+			}
+		}
+
+		for _, w := range writes {
+			if len(w.values) == 0 {
+				continue
+			}
+			if w.create {
+				w.form, err = createRemoteWriteMetric(ctx, api, w.series, w.refkey, int32(containerId), int16(dataPolicyId))
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					c.Status(http.StatusInternalServerError)
+					api.Log.Error("Fail to create remote write metric", logger.ErrField(err))
+					return
+				}
+			}
+			for i, value := range w.values {
+				err = writeData(ctx, api, w.form, value, w.timestamps[i])
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					c.Status(http.StatusInternalServerError)
+					api.Log.Error("Fail to add metric data", logger.ErrField(err))
+					return
+				}
+				samples++
+			}
+		}
+		api.Log.Debug(fmt.Sprintf("Remote write received, series: %d, samples: %d, rejected: %d", len(series), samples, rejected))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// remoteWrite is the validated samples of a refkey.
+type remoteWrite struct {
+	refkey string
+	series promTimeSeries
+	// create is true if the metric does not exists and is created on the write.
+	create     bool
+	form       models.BasicMetricAddDataForm
+	values     []any
+	timestamps []time.Time
+}
+
+// seriesRefkey returns the refkey of a series, joining the values of the labels. Returns
+// false if a label is missing or if the refkey is too long.
+func seriesRefkey(ts promTimeSeries, labels []string) (refkey string, ok bool) {
+	values := make([]string, 0, len(labels))
+	for _, name := range labels {
+		v, ok := ts.label(strings.TrimSpace(name))
+		if !ok || v == "" {
+			return "", false
+		}
+		values = append(values, v)
+	}
+	refkey = strings.Join(values, refkeySeparator)
+	if len(refkey) == 0 || len(refkey) > 200 {
+		return "", false
+	}
+	return refkey, true
+}
+
+// createRemoteWriteMetric creates a float basic metric and it's refkey for a series
+// and returns the metric add data form.
+func createRemoteWriteMetric(ctx context.Context, api *api.API, ts promTimeSeries, refkey string, containerId int32, dataPolicyId int16) (form models.BasicMetricAddDataForm, err error) {
+	name, ok := ts.label("__name__")
+	if !ok || name == "" {
+		name = refkey
+	}
+
+	var metric models.Metric[struct{}]
+	metric.Base.ContainerId = containerId
+	metric.Base.ContainerType = types.CTBasic
+	metric.Base.Type = types.MTFloat
+	metric.Base.Name = truncate(name, 50)
+	metric.Base.Descr = truncate("Created by remote write, refkey: "+refkey, 255)
+	metric.Base.Enabled = true
+	metric.Base.DataPolicyId = dataPolicyId
+	metric.Base.RTSCacheDuration = remoteWriteRTSCacheDuration
+	metric.Base.DHSEnabled = true
+
+	id, err := api.PG.CreateBasicMetricWithRefkey(ctx, metric, refkey)
+	if err != nil {
+		return form, err
+	}
+	metric.Base.Id = id
+	api.Log.Info("Basic metric created by remote write, id: " + strconv.FormatInt(id, 10))
+	t.NotifyMetricCreated(api.Amqph, metric.Base, metric.Protocol)
+
+	form.MetricId = id
+	form.MetricType = metric.Base.Type
+	form.ContainerId = containerId
+	form.DataPolicyId = dataPolicyId
+	form.Enabled = true
+	form.DHSEnabled = true
+	return form, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,233 @@
+package metrictemplate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Applies a metric template to containers, linking them to the template and
+// creating the missing metrics. The metrics edited on the containers are
+// skipped, unless forced.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If a container type is not the template container type.
+//   - 404 If template or a container not found.
+//   - 200 If succeeded. With the sync result of each container.
+func ApplyHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("templateId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var form models.MetricTemplateApplyForm
+		err = c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		exists, template, err := api.PG.GetMetricTemplate(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+
+		for _, containerId := range form.ContainersIds {
+			exists, _, err := api.PG.GetContainer(ctx, containerId, template.ContainerType)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to get container", logger.ErrField(err))
+				return
+			}
+			if exists {
+				continue
+			}
+			exists, err = api.PG.ContainerExist(ctx, containerId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to check if container exists", logger.ErrField(err))
+				return
+			}
+			if exists {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgContainerTypeMismatch))
+				return
+			}
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContainerNotFound))
+			return
+		}
+
+		s := &syncer{api: api}
+		results := make([]models.MetricTemplateSyncResult, 0, len(form.ContainersIds))
+		found := false
+		err = api.PG.WithTx(ctx, func(p *pg.PG) error {
+			s.pg = p
+			// locks the template, so it is not updated while applied
+			exists, template, _, err := p.GetMetricTemplateForUpdate(ctx, int32(id))
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return nil
+			}
+			found = true
+			s.template = template
+			for _, containerId := range form.ContainersIds {
+				r, err := s.sync(ctx, containerId, syncOptions{force: form.Force})
+				if err != nil {
+					return err
+				}
+				results = append(results, r)
+			}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to apply metric template", logger.ErrField(err))
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+		s.notify()
+		api.Log.Info("Metric template applied, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.DataRes(results))
+	}
+}
+
+// Get multi containers linked to a metric template.
+// Params:
+//   - "limit" Limit of containers returned. Default is 30, max is 30, min is 1.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If template not found.
+//   - 200 If succeeded.
+func MGetContainersHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, _, err := api.PG.GetMetricTemplate(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+
+		containers, err := api.PG.GetMetricTemplateContainers(ctx, int32(id), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template containers", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(containers))
+	}
+}
+
+// Unlinks a container from a metric template. The container metrics are kept.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If container is not linked.
+//   - 200 If succeeded.
+func UnlinkHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		rawContainerId := c.Param("containerId")
+		containerId, err := strconv.ParseInt(rawContainerId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.UnlinkMetricTemplateContainer(ctx, int32(id), int32(containerId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to unlink metric template container", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateContainerNotFound))
+			return
+		}
+		api.Log.Info("Container unlinked from metric template, container id: " + rawContainerId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -0,0 +1,107 @@
+package metrictemplate
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a metric template.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If invalid container type or metrics.
+//   - 400 If name already exists.
+//   - 404 If a data policy or alarm expression does not exists.
+//   - 200 If succeeded. With the template id.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var template models.MetricTemplate
+		err := c.ShouldBind(&template)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(template)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		if !validContainerType(template.ContainerType) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidContainerType))
+			return
+		}
+		_, next, ok := setIds(template.Metrics, nil, 1)
+		if !ok || !validMetrics(template.ContainerType, template.Metrics) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidTemplateMetrics))
+			return
+		}
+
+		if !validate(c, api, template) {
+			return
+		}
+
+		template.UpdatedAt = time.Now().Unix()
+		id, err := api.PG.CreateMetricTemplate(ctx, template, next)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create metric template", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Metric template created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
+
+// validate checks if the template name is available and the metrics data policies
+// and alarm expressions exists, responding if not. Returns true if valid.
+func validate(c *gin.Context, api *api.API, template models.MetricTemplate) bool {
+	ctx := c.Request.Context()
+
+	exists, err := api.PG.MetricTemplateNameExists(ctx, template.Name, template.Id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to check if metric template name exists", logger.ErrField(err))
+		return false
+	}
+	if exists {
+		c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgMetricTemplateNameExists))
+		return false
+	}
+
+	dataPoliciesExists, expressionsExists, err := validReferences(ctx, api, template.Metrics)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to check template metrics references", logger.ErrField(err))
+		return false
+	}
+	if !dataPoliciesExists {
+		c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgDataPolicyNotFound))
+		return false
+	}
+	if !expressionsExists {
+		c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgAlarmExpressionNotFound))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package metrictemplate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Deletes a metric template. The metrics of the linked containers are kept.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func DeleteHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("templateId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.DeleteMetricTemplate(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to delete metric template", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+		api.Log.Info("Metric template deleted, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
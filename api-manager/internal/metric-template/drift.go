@@ -0,0 +1,65 @@
+package metrictemplate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets the drift report of a metric template, the metrics edited or deleted
+// on the linked containers since the last sync.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func DriftHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.ParseInt(c.Param("templateId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, template, err := api.PG.GetMetricTemplate(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+
+		containersIds, err := api.PG.GetMetricTemplateContainersIds(ctx, template.Id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template containers ids", logger.ErrField(err))
+			return
+		}
+
+		links, err := api.PG.GetMetricTemplateLinks(ctx, template.Id, 0)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template links", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(drifts(template, containersIds, links)))
+	}
+}
@@ -0,0 +1,222 @@
+package metrictemplate
+
+import (
+	"context"
+	"sort"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+// validContainerType returns true if templates can be applied on containers of the type.
+func validContainerType(ct types.ContainerType) bool {
+	return ct == types.CTBasic || ct == types.CTSNMPv2c || ct == types.CTFlexLegacy
+}
+
+// validMetrics returns true if the metrics names are unique, the types are valid and
+// the protocol fields required by the container type are set. The fields not used by
+// the container type are cleared and the alarm expressions are sorted.
+func validMetrics(ct types.ContainerType, metrics []models.MetricTemplateMetric) bool {
+	names := make(map[string]struct{}, len(metrics))
+	for i := range metrics {
+		m := &metrics[i]
+		if _, ok := names[m.Name]; ok {
+			return false
+		}
+		names[m.Name] = struct{}{}
+
+		if !types.ValidateMetricType(m.Type) {
+			return false
+		}
+		switch ct {
+		case types.CTSNMPv2c:
+			if m.OID == "" {
+				return false
+			}
+			m.Port, m.PortType = 0, 0
+		case types.CTFlexLegacy:
+			if m.OID == "" || m.Port == 0 || m.PortType == 0 {
+				return false
+			}
+		default:
+			m.OID, m.Port, m.PortType = "", 0, 0
+		}
+		if m.AlarmExpressionsIds == nil {
+			m.AlarmExpressionsIds = []int32{}
+		}
+		sort.Slice(m.AlarmExpressionsIds, func(i, j int) bool {
+			return m.AlarmExpressionsIds[i] < m.AlarmExpressionsIds[j]
+		})
+	}
+	return true
+}
+
+// setIds sets the ids of the metrics added to the template, starting on next. The
+// metrics with id must be on the current template metrics. Returns the added metrics
+// ids and the next id.
+func setIds(metrics []models.MetricTemplateMetric, current []models.MetricTemplateMetric, next int64) (added map[int64]bool, nextId int64, ok bool) {
+	ids := make(map[int64]bool, len(current))
+	for _, m := range current {
+		ids[m.Id] = true
+	}
+	added = make(map[int64]bool)
+	seen := make(map[int64]bool, len(metrics))
+	for i := range metrics {
+		m := &metrics[i]
+		if m.Id == 0 {
+			m.Id = next
+			next++
+			added[m.Id] = true
+			continue
+		}
+		if !ids[m.Id] || seen[m.Id] {
+			return nil, next, false
+		}
+		seen[m.Id] = true
+	}
+	return added, next, true
+}
+
+// validReferences returns if the data policies and alarm expressions of the metrics exists.
+func validReferences(ctx context.Context, api *api.API, metrics []models.MetricTemplateMetric) (dataPoliciesExists bool, expressionsExists bool, err error) {
+	dps := make(map[int16]struct{})
+	expressions := make(map[int32]struct{})
+	for _, m := range metrics {
+		dps[m.DataPolicyId] = struct{}{}
+		for _, id := range m.AlarmExpressionsIds {
+			expressions[id] = struct{}{}
+		}
+	}
+	dpsIds := make([]int16, 0, len(dps))
+	for id := range dps {
+		dpsIds = append(dpsIds, id)
+	}
+	expressionsIds := make([]int32, 0, len(expressions))
+	for id := range expressions {
+		expressionsIds = append(expressionsIds, id)
+	}
+	r, err := api.PG.MetricTemplateReferencesExists(ctx, dpsIds, expressionsIds)
+	if err != nil {
+		return false, false, err
+	}
+	return r.DataPoliciesExists, r.AlarmExpressionsExists, nil
+}
+
+// diff returns the fields that are different on the definitions.
+func diff(a models.MetricDefinition, b models.MetricDefinition) (fields []string) {
+	fields = []string{}
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if a.Name != b.Name {
+		fields = append(fields, "name")
+	}
+	if a.Descr != b.Descr {
+		fields = append(fields, "descr")
+	}
+	if a.Enabled != b.Enabled {
+		fields = append(fields, "enabled")
+	}
+	if a.DataPolicyId != b.DataPolicyId {
+		fields = append(fields, "data-policy-id")
+	}
+	if a.RTSPullingTimes != b.RTSPullingTimes {
+		fields = append(fields, "rts-pulling-times")
+	}
+	if a.RTSCacheDuration != b.RTSCacheDuration {
+		fields = append(fields, "rts-cache-duration")
+	}
+	if a.DHSEnabled != b.DHSEnabled {
+		fields = append(fields, "dhs-enabled")
+	}
+	if a.DHSInterval != b.DHSInterval {
+		fields = append(fields, "dhs-interval")
+	}
+	if a.EvaluableExpression != b.EvaluableExpression {
+		fields = append(fields, "evaluable-expression")
+	}
+	if !equalLabels(a.Labels, b.Labels) {
+		fields = append(fields, "labels")
+	}
+	if a.OID != b.OID {
+		fields = append(fields, "oid")
+	}
+	if a.Port != b.Port {
+		fields = append(fields, "port")
+	}
+	if a.PortType != b.PortType {
+		fields = append(fields, "port-type")
+	}
+	if !equalIds(a.AlarmExpressionsIds, b.AlarmExpressionsIds) {
+		fields = append(fields, "alarm-expressions-ids")
+	}
+	return fields
+}
+
+func equalLabels(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// equalIds returns true if the sorted ids are equal.
+func equalIds(a []int32, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// drifts returns the drifted and missing metrics of the linked containers.
+func drifts(t models.MetricTemplate, containersIds []int32, links []models.MetricTemplateLink) []models.MetricTemplateDrift {
+	linked := make(map[int32]map[int64]models.MetricTemplateLink, len(containersIds))
+	for _, id := range containersIds {
+		linked[id] = make(map[int64]models.MetricTemplateLink)
+	}
+	for _, l := range links {
+		if linked[l.ContainerId] != nil {
+			linked[l.ContainerId][l.TemplateMetricId] = l
+		}
+	}
+
+	res := []models.MetricTemplateDrift{}
+	for _, id := range containersIds {
+		for _, m := range t.Metrics {
+			l, ok := linked[id][m.Id]
+			if !ok {
+				res = append(res, models.MetricTemplateDrift{
+					ContainerId:      id,
+					TemplateMetricId: m.Id,
+					Name:             m.Name,
+					Fields:           []string{},
+					Missing:          true,
+				})
+				continue
+			}
+			fields := diff(l.Applied, l.Current)
+			if len(fields) == 0 {
+				continue
+			}
+			res = append(res, models.MetricTemplateDrift{
+				ContainerId:      id,
+				MetricId:         l.MetricId,
+				TemplateMetricId: m.Id,
+				Name:             l.Current.Name,
+				Fields:           fields,
+			})
+		}
+	}
+	return res
+}
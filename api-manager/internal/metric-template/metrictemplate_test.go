@@ -0,0 +1,141 @@
+package metrictemplate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+func testDefinition() models.MetricDefinition {
+	return models.MetricDefinition{
+		Type:                types.MTFloat,
+		Name:                "CPU",
+		Descr:               "CPU usage.",
+		Enabled:             true,
+		DataPolicyId:        1,
+		RTSCacheDuration:    60000,
+		Labels:              map[string]string{"role": "core"},
+		OID:                 ".1.3.6.1.4.1.9.2.1.58.0",
+		AlarmExpressionsIds: []int32{1, 2},
+	}
+}
+
+func TestValidMetrics(t *testing.T) {
+	d := testDefinition()
+	d.Port, d.PortType = 1, 2
+	d.AlarmExpressionsIds = []int32{3, 1}
+	metrics := []models.MetricTemplateMetric{{MetricDefinition: d}}
+	if !validMetrics(types.CTSNMPv2c, metrics) {
+		t.Fatalf("validMetrics failed, want: %v, got: %v", true, false)
+	}
+	m := metrics[0]
+	if m.Port != 0 || m.PortType != 0 {
+		t.Errorf("validMetrics clear failed, want: %v, got: %v", [2]int16{}, [2]int16{m.Port, m.PortType})
+	}
+	if !reflect.DeepEqual(m.AlarmExpressionsIds, []int32{1, 3}) {
+		t.Errorf("validMetrics sort failed, want: %v, got: %v", []int32{1, 3}, m.AlarmExpressionsIds)
+	}
+
+	tests := []struct {
+		name string
+		ct   types.ContainerType
+		fn   func(m *models.MetricDefinition)
+		want bool
+	}{
+		{"basic without oid", types.CTBasic, func(m *models.MetricDefinition) { m.OID = "" }, true},
+		{"snmpv2c without oid", types.CTSNMPv2c, func(m *models.MetricDefinition) { m.OID = "" }, false},
+		{"flex without port", types.CTFlexLegacy, func(m *models.MetricDefinition) { m.PortType = 1 }, false},
+		{"flex", types.CTFlexLegacy, func(m *models.MetricDefinition) { m.Port, m.PortType = 1, 1 }, true},
+		{"invalid type", types.CTBasic, func(m *models.MetricDefinition) { m.Type = types.MTInvalid }, false},
+	}
+	for _, test := range tests {
+		d := testDefinition()
+		test.fn(&d)
+		got := validMetrics(test.ct, []models.MetricTemplateMetric{{MetricDefinition: d}})
+		if got != test.want {
+			t.Errorf("validMetrics %s failed, want: %v, got: %v", test.name, test.want, got)
+		}
+	}
+
+	d = testDefinition()
+	if validMetrics(types.CTSNMPv2c, []models.MetricTemplateMetric{{MetricDefinition: d}, {MetricDefinition: d}}) {
+		t.Errorf("validMetrics of repeated names failed, want: %v, got: %v", false, true)
+	}
+}
+
+func TestSetIds(t *testing.T) {
+	current := []models.MetricTemplateMetric{{Id: 1}, {Id: 2}}
+	metrics := []models.MetricTemplateMetric{{Id: 2}, {Id: 0}, {Id: 0}}
+	added, next, ok := setIds(metrics, current, 5)
+	if !ok {
+		t.Fatalf("setIds failed, want: %v, got: %v", true, ok)
+	}
+	if next != 7 || metrics[1].Id != 5 || metrics[2].Id != 6 {
+		t.Errorf("setIds failed, want: %v, got: %v", []int64{2, 5, 6}, []int64{metrics[0].Id, metrics[1].Id, metrics[2].Id})
+	}
+	if !reflect.DeepEqual(added, map[int64]bool{5: true, 6: true}) {
+		t.Errorf("setIds added failed, want: %v, got: %v", map[int64]bool{5: true, 6: true}, added)
+	}
+
+	for _, ids := range [][]int64{{3}, {1, 1}} {
+		metrics := make([]models.MetricTemplateMetric, len(ids))
+		for i, id := range ids {
+			metrics[i].Id = id
+		}
+		_, _, ok := setIds(metrics, current, 5)
+		if ok {
+			t.Errorf("setIds with ids %v failed, want: %v, got: %v", ids, false, ok)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := testDefinition()
+	b := testDefinition()
+	b.Labels = map[string]string{"role": "core"}
+	if fields := diff(a, b); len(fields) != 0 {
+		t.Errorf("diff of equal definitions failed, want: %v, got: %v", []string{}, fields)
+	}
+
+	b.Descr = "Edited."
+	b.Labels = nil
+	b.AlarmExpressionsIds = []int32{1}
+	want := []string{"descr", "labels", "alarm-expressions-ids"}
+	if fields := diff(a, b); !reflect.DeepEqual(fields, want) {
+		t.Errorf("diff failed, want: %v, got: %v", want, fields)
+	}
+
+	// nil and empty labels are equal
+	a.Labels, b.Labels = nil, map[string]string{}
+	want = []string{"descr", "alarm-expressions-ids"}
+	if fields := diff(a, b); !reflect.DeepEqual(fields, want) {
+		t.Errorf("diff of nil and empty labels failed, want: %v, got: %v", want, fields)
+	}
+}
+
+func TestDrifts(t *testing.T) {
+	d := testDefinition()
+	edited := testDefinition()
+	edited.Enabled = false
+	template := models.MetricTemplate{
+		Metrics: []models.MetricTemplateMetric{
+			{Id: 1, MetricDefinition: d},
+			{Id: 2, MetricDefinition: d},
+		},
+	}
+	links := []models.MetricTemplateLink{
+		{MetricId: 10, ContainerId: 1, TemplateMetricId: 1, Applied: d, Current: d},
+		{MetricId: 11, ContainerId: 1, TemplateMetricId: 2, Applied: d, Current: edited},
+		{MetricId: 12, ContainerId: 2, TemplateMetricId: 1, Applied: d, Current: d},
+	}
+	got := drifts(template, []int32{1, 2}, links)
+	want := []models.MetricTemplateDrift{
+		{ContainerId: 1, MetricId: 11, TemplateMetricId: 2, Name: "CPU", Fields: []string{"enabled"}},
+		{ContainerId: 2, TemplateMetricId: 2, Name: "CPU", Fields: []string{}, Missing: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("drifts failed, want: %v, got: %v", want, got)
+	}
+}
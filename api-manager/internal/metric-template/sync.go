@@ -0,0 +1,245 @@
+package metrictemplate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+var errMetricNotFound = errors.New("linked metric does not exists")
+
+// syncer syncs the containers metrics with a template on a transaction.
+type syncer struct {
+	api      *api.API
+	pg       *pg.PG
+	template models.MetricTemplate
+	// notifications is the notifications sent after the commit.
+	notifications []func()
+}
+
+// syncOptions is the options of a container sync.
+type syncOptions struct {
+	// force overwrites the drifted metrics, creates the missing metrics
+	// and deletes the drifted metrics removed from the template.
+	force bool
+	// create is the template metrics created if missing on the container,
+	// nil creates all missing metrics.
+	create map[int64]bool
+}
+
+// notify sends the notifications, must be called after the commit.
+func (s *syncer) notify() {
+	for _, n := range s.notifications {
+		n()
+	}
+}
+
+// sync links the container to the template and syncs its metrics. The drifted
+// metrics, edited on the container since the last sync, are skipped unless forced.
+// The drifted metrics removed from the template are unlinked and kept.
+func (s *syncer) sync(ctx context.Context, containerId int32, opts syncOptions) (r models.MetricTemplateSyncResult, err error) {
+	r.ContainerId = containerId
+	r.Skipped = []models.MetricTemplateDrift{}
+
+	err = s.pg.LinkMetricTemplateContainer(ctx, s.template.Id, containerId, s.template.Version, time.Now().Unix())
+	if err != nil {
+		return r, err
+	}
+	links, err := s.pg.GetMetricTemplateLinks(ctx, s.template.Id, containerId)
+	if err != nil {
+		return r, err
+	}
+	linked := make(map[int64]models.MetricTemplateLink, len(links))
+	for _, l := range links {
+		linked[l.TemplateMetricId] = l
+	}
+
+	for _, m := range s.template.Metrics {
+		l, ok := linked[m.Id]
+		if !ok {
+			if !opts.force && opts.create != nil && !opts.create[m.Id] {
+				r.Skipped = append(r.Skipped, models.MetricTemplateDrift{
+					ContainerId:      containerId,
+					TemplateMetricId: m.Id,
+					Name:             m.Name,
+					Fields:           []string{},
+					Missing:          true,
+				})
+				continue
+			}
+			id, err := s.writeMetric(ctx, containerId, 0, m.MetricDefinition)
+			if err != nil {
+				return r, err
+			}
+			err = s.setAlarmExpressions(ctx, id, nil, m.AlarmExpressionsIds)
+			if err != nil {
+				return r, err
+			}
+			err = s.pg.CreateMetricTemplateLink(ctx, s.template.Id, models.MetricTemplateLink{
+				MetricId:         id,
+				ContainerId:      containerId,
+				TemplateMetricId: m.Id,
+				Applied:          m.MetricDefinition,
+			})
+			if err != nil {
+				return r, err
+			}
+			r.Created++
+			continue
+		}
+		delete(linked, m.Id)
+
+		if len(diff(m.MetricDefinition, l.Current)) == 0 {
+			if len(diff(m.MetricDefinition, l.Applied)) != 0 {
+				err = s.pg.UpdateMetricTemplateLink(ctx, l.MetricId, m.MetricDefinition)
+				if err != nil {
+					return r, err
+				}
+			}
+			continue
+		}
+		fields := diff(l.Applied, l.Current)
+		if len(fields) > 0 && !opts.force {
+			r.Skipped = append(r.Skipped, models.MetricTemplateDrift{
+				ContainerId:      containerId,
+				MetricId:         l.MetricId,
+				TemplateMetricId: m.Id,
+				Name:             l.Current.Name,
+				Fields:           fields,
+			})
+			continue
+		}
+		_, err = s.writeMetric(ctx, containerId, l.MetricId, m.MetricDefinition)
+		if err != nil {
+			return r, err
+		}
+		err = s.setAlarmExpressions(ctx, l.MetricId, l.Current.AlarmExpressionsIds, m.AlarmExpressionsIds)
+		if err != nil {
+			return r, err
+		}
+		err = s.pg.UpdateMetricTemplateLink(ctx, l.MetricId, m.MetricDefinition)
+		if err != nil {
+			return r, err
+		}
+		r.Updated++
+	}
+
+	// metrics removed from the template
+	for _, l := range links {
+		if _, ok := linked[l.TemplateMetricId]; !ok {
+			continue
+		}
+		fields := diff(l.Applied, l.Current)
+		if len(fields) > 0 && !opts.force {
+			err = s.pg.DeleteMetricTemplateLink(ctx, l.MetricId)
+			if err != nil {
+				return r, err
+			}
+			r.Skipped = append(r.Skipped, models.MetricTemplateDrift{
+				ContainerId: containerId,
+				MetricId:    l.MetricId,
+				Name:        l.Current.Name,
+				Fields:      fields,
+			})
+			continue
+		}
+		_, err = s.pg.DeleteMetric(ctx, l.MetricId)
+		if err != nil {
+			return r, err
+		}
+		metricId := l.MetricId
+		s.notifications = append(s.notifications, func() {
+			t.NotifyMetricDeleted(s.api.Amqph, containerId, metricId)
+		})
+		r.Deleted++
+	}
+	return r, nil
+}
+
+// setAlarmExpressions creates and removes the metric alarm expressions relations.
+func (s *syncer) setAlarmExpressions(ctx context.Context, metricId int64, current []int32, desired []int32) (err error) {
+	relations := make(map[int32]bool, len(current))
+	for _, id := range current {
+		relations[id] = true
+	}
+	for _, id := range desired {
+		if relations[id] {
+			delete(relations, id)
+			continue
+		}
+		err = s.pg.CrateMetricAlarmExpressionRel(ctx, id, metricId)
+		if err != nil {
+			return err
+		}
+	}
+	for id := range relations {
+		_, err = s.pg.RemoveMetricAlarmExpressionRel(ctx, id, metricId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMetric creates the container metric of the definition, or updates it if the
+// id is not zero. Returns the metric id.
+func (s *syncer) writeMetric(ctx context.Context, containerId int32, id int64, d models.MetricDefinition) (int64, error) {
+	base := models.BaseMetric{
+		Id:                  id,
+		ContainerId:         containerId,
+		ContainerType:       s.template.ContainerType,
+		Type:                d.Type,
+		Name:                d.Name,
+		Descr:               d.Descr,
+		Enabled:             d.Enabled,
+		DataPolicyId:        d.DataPolicyId,
+		RTSPullingTimes:     d.RTSPullingTimes,
+		RTSCacheDuration:    d.RTSCacheDuration,
+		DHSEnabled:          d.DHSEnabled,
+		DHSInterval:         d.DHSInterval,
+		EvaluableExpression: d.EvaluableExpression,
+		Labels:              d.Labels,
+	}
+	switch s.template.ContainerType {
+	case types.CTSNMPv2c:
+		m := models.Metric[models.SNMPMetric]{
+			Base:     base,
+			Protocol: models.SNMPMetric{Id: id, OID: d.OID},
+		}
+		return saveMetric(ctx, s, m, s.pg.CreateSNMPv2cMetric, s.pg.UpdateSNMPv2cMetric)
+	case types.CTFlexLegacy:
+		m := models.Metric[models.FlexLegacyMetric]{
+			Base:     base,
+			Protocol: models.FlexLegacyMetric{Id: id, OID: d.OID, Port: d.Port, PortType: d.PortType},
+		}
+		return saveMetric(ctx, s, m, s.pg.CreateFlexLegacyMetric, s.pg.UpdateFlexLegacyMetric)
+	default:
+		m := models.Metric[struct{}]{Base: base}
+		return saveMetric(ctx, s, m, s.pg.CreateBasicMetric, s.pg.UpdateBasicMetric)
+	}
+}
+
+func saveMetric[T any](
+	ctx context.Context,
+	s *syncer,
+	m models.Metric[T],
+	create func(context.Context, models.Metric[T]) (int64, error),
+	update func(context.Context, models.Metric[T]) (bool, error),
+) (int64, error) {
+	id, exists, notify, err := tools.SaveMetric(ctx, s.api, m, create, update)
+	if err != nil {
+		return id, err
+	}
+	if !exists {
+		return id, errMetricNotFound
+	}
+	s.notifications = append(s.notifications, notify)
+	return id, nil
+}
@@ -0,0 +1,138 @@
+package metrictemplate
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Updates a metric template and propagates the changes to the linked containers.
+// The metrics edited on the containers since the last sync are skipped.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If invalid metrics.
+//   - 400 If name already exists.
+//   - 404 If not found.
+//   - 404 If a data policy or alarm expression does not exists.
+//   - 200 If succeeded. With the sync result of each linked container.
+func UpdateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("templateId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var template models.MetricTemplate
+		err = c.ShouldBind(&template)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(template)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		exists, current, err := api.PG.GetMetricTemplate(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric template", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+
+		template.Id = int32(id)
+		template.ContainerType = current.ContainerType
+		if !validMetrics(template.ContainerType, template.Metrics) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidTemplateMetrics))
+			return
+		}
+
+		if !validate(c, api, template) {
+			return
+		}
+
+		s := &syncer{api: api}
+		var results []models.MetricTemplateSyncResult
+		found, validIds := false, true
+		err = api.PG.WithTx(ctx, func(p *pg.PG) error {
+			s.pg = p
+			// locks the template, so the ids are set on the latest version
+			exists, current, next, err := p.GetMetricTemplateForUpdate(ctx, template.Id)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return nil
+			}
+			found = true
+			added, next, ok := setIds(template.Metrics, current.Metrics, next)
+			if !ok {
+				validIds = false
+				return nil
+			}
+
+			template.UpdatedAt = time.Now().Unix()
+			_, template.Version, err = p.UpdateMetricTemplate(ctx, template, next)
+			if err != nil {
+				return err
+			}
+			s.template = template
+
+			containersIds, err := p.GetMetricTemplateContainersIds(ctx, template.Id)
+			if err != nil {
+				return err
+			}
+			results = make([]models.MetricTemplateSyncResult, 0, len(containersIds))
+			for _, containerId := range containersIds {
+				r, err := s.sync(ctx, containerId, syncOptions{create: added})
+				if err != nil {
+					return err
+				}
+				results = append(results, r)
+			}
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update metric template", logger.ErrField(err))
+			return
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricTemplateNotFound))
+			return
+		}
+		if !validIds {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidTemplateMetrics))
+			return
+		}
+		s.notify()
+		api.Log.Info("Metric template updated, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.DataRes(results))
+	}
+}
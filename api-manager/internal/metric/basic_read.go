@@ -63,6 +63,12 @@ func MGetBasicHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		selector, err := tools.SelectorQuery(c, "labels")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
 		containerId, err := strconv.ParseInt(c.Param("containerId"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
@@ -85,6 +91,7 @@ func MGetBasicHandler(api *api.API) func(c *gin.Context) {
 			Name:         c.Query("name"),
 			Descr:        c.Query("descr"),
 			Enabled:      enabled,
+			Labels:       selector,
 			OrderBy:      c.Query("order-by"),
 			OrderByFn:    c.Query("order-by-fn"),
 			DataPolicyId: int16(dpId),
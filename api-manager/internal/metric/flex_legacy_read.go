@@ -63,6 +63,12 @@ func MGetFlexLegacyHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		selector, err := tools.SelectorQuery(c, "labels")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
 		containerId, err := strconv.ParseInt(c.Param("containerId"), 10, 64)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
@@ -89,6 +95,7 @@ func MGetFlexLegacyHandler(api *api.API) func(c *gin.Context) {
 			Enabled:      enabled,
 			Port:         int16(port),
 			PortType:     int16(portType),
+			Labels:       selector,
 			OrderBy:      c.Query("order-by"),
 			OrderByFn:    c.Query("order-by-fn"),
 			DataPolicyId: int16(dpId),
@@ -0,0 +1,365 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// auditMaxBody is the maximum size of the request and response bodies saved
+// on the audit entries.
+const auditMaxBody = 64 << 10
+
+// auditSkipRoutes are the mutating routes, relative to the routes prefix, that
+// are not audited, as they only write metrics data.
+var auditSkipRoutes = map[string]bool{
+	"POST /metrics/data":         true,
+	"POST /metrics/remote-write": true,
+}
+
+// auditSensitiveFields are the JSON fields redacted on the audit entries.
+var auditSensitiveFields = []string{"password", "community", "api-key", "secret", "token"}
+
+// auditRedacted is the value of the redacted fields.
+const auditRedacted = "[REDACTED]"
+
+// internalRequestKey is the request context key of the internal requests.
+type internalRequestKey struct{}
+
+// isInternalRequest returns true if the request was made by the api itself.
+func isInternalRequest(c *gin.Context) bool {
+	internal, _ := c.Request.Context().Value(internalRequestKey{}).(bool)
+	return internal
+}
+
+// auditWriter is a response writer that keeps the response body.
+type auditWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= auditMaxBody {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// auditQueueSize is the maximum number of audit entries waiting to be saved.
+// Entries of requests finished when the queue is full are dropped.
+const auditQueueSize = 1000
+
+// auditJob is an audit entry completed and saved after the response.
+type auditJob struct {
+	entry models.AuditEntry
+	// after is the path read as the entity after the request, empty if none.
+	after string
+	// body is the request body, used as after if the entity can't be read.
+	body []byte
+	// creds is the client credentials of the entity read.
+	creds internalCredentials
+}
+
+// Audit saves an audit entry of each authenticated POST, PATCH and DELETE request.
+// The entity before and after the request are read with the GET route of the
+// entity, if exists, otherwise the request body is used as after. Only the entity
+// before is read on the request, the entity after is read and the entry is saved
+// asynchronously, after the response.
+func Audit(api *api.API) func(c *gin.Context) {
+	prefix := "/" + strings.Trim(env.APIManagerRoutesPrefix, "/")
+	var (
+		once      sync.Once
+		getRoutes map[string]bool
+	)
+	queue := make(chan auditJob, auditQueueSize)
+	go saveAuditEntries(api, queue)
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		route := strings.TrimPrefix(c.FullPath(), prefix)
+		if method != http.MethodPost && method != http.MethodPatch && method != http.MethodDelete {
+			c.Next()
+			return
+		}
+		if c.FullPath() == "" || auditSkipRoutes[method+" "+route] {
+			c.Next()
+			return
+		}
+		once.Do(func() {
+			getRoutes = make(map[string]bool)
+			for _, r := range api.Router.Routes() {
+				if r.Method == http.MethodGet {
+					getRoutes[r.Path] = true
+				}
+			}
+		})
+
+		job := auditJob{
+			entry: models.AuditEntry{
+				Method:    method,
+				Route:     route,
+				Entity:    strings.TrimPrefix(c.Request.URL.Path, prefix),
+				IP:        c.ClientIP(),
+				CreatedAt: time.Now().Unix(),
+			},
+			creds: getInternalCredentials(api, c),
+		}
+
+		if method != http.MethodPost && getRoutes[c.FullPath()] {
+			job.entry.Before = internalGet(c.Request.Context(), api, job.creds, c.Request.URL.Path)
+		}
+
+		body, err := readBody(c)
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		w := &auditWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			return
+		}
+		job.entry.Status = int16(w.Status())
+		job.entry.UserId = meta.UserId
+		job.entry.APIKeyId = meta.APIKeyId
+		job.entry.Role = int16(meta.Role)
+
+		if job.entry.Status < 300 {
+			switch method {
+			case http.MethodPost:
+				entity, ok := createdEntity(w.body.Bytes(), c.FullPath(), getRoutes)
+				if ok {
+					job.entry.Entity = strings.TrimSuffix(job.entry.Entity, "/") + "/" + entity
+					job.after = strings.TrimSuffix(c.Request.URL.Path, "/") + "/" + entity
+				}
+			case http.MethodPatch:
+				if job.entry.Before != nil {
+					job.after = c.Request.URL.Path
+				}
+			}
+			if method != http.MethodDelete {
+				job.body = body
+			}
+		}
+		select {
+		case queue <- job:
+		default:
+			api.Log.Warn("Audit queue is full, entry dropped, route: " + job.entry.Method + " " + job.entry.Route)
+		}
+	}
+}
+
+// saveAuditEntries reads the entities after the requests and saves the audit entries
+// of the queue.
+func saveAuditEntries(api *api.API, queue <-chan auditJob) {
+	for job := range queue {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		entry := job.entry
+		if entry.Status < 300 {
+			if job.after != "" {
+				entry.After = internalGet(ctx, api, job.creds, job.after)
+			}
+			if entry.After == nil && json.Valid(job.body) {
+				entry.After = job.body
+			}
+			entry.Diff = auditDiff(entry.Before, entry.After)
+		}
+		entry.Before = redact(entry.Before)
+		entry.After = redact(entry.After)
+
+		_, err := api.PG.CreateAuditEntry(ctx, entry)
+		if err != nil {
+			api.Log.Error("Fail to create audit entry", logger.ErrField(err))
+		}
+		cancel()
+	}
+}
+
+// readBody reads the request body, keeping it readable by the handler. Bodies
+// larger than the audit maximum body size are not returned.
+func readBody(c *gin.Context) (body []byte, err error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(io.LimitReader(c.Request.Body, auditMaxBody+1))
+	if err != nil {
+		return nil, err
+	}
+	c.Request.Body = readCloser{
+		Reader: io.MultiReader(bytes.NewReader(b), c.Request.Body),
+		Closer: c.Request.Body,
+	}
+	if len(b) > auditMaxBody {
+		return nil, nil
+	}
+	return b, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// internalCredentials is the client credentials and ip headers used on the
+// internal requests.
+type internalCredentials struct {
+	cookie     string
+	apikey     string
+	remoteAddr string
+	ipHeaders  map[string]string
+}
+
+// getInternalCredentials returns the request client credentials.
+func getInternalCredentials(api *api.API, c *gin.Context) internalCredentials {
+	creds := internalCredentials{
+		cookie:     c.GetHeader("Cookie"),
+		apikey:     c.GetHeader(APIKeyHeader),
+		remoteAddr: c.Request.RemoteAddr,
+		ipHeaders:  make(map[string]string),
+	}
+	for _, h := range api.Router.RemoteIPHeaders {
+		if v := c.GetHeader(h); v != "" {
+			creds.ipHeaders[h] = v
+		}
+	}
+	return creds
+}
+
+// internalGet returns the response data of a GET request to the path, made
+// with the client credentials. Returns nil if the request fails.
+func internalGet(ctx context.Context, api *api.API, creds internalCredentials, path string) json.RawMessage {
+	if creds.cookie == "" && creds.apikey == "" {
+		return nil
+	}
+	ctx = context.WithValue(ctx, internalRequestKey{}, true)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Cookie", creds.cookie)
+	req.Header.Set(APIKeyHeader, creds.apikey)
+	// keep the client ip, checked on API Keys with allowed ips
+	req.RemoteAddr = creds.remoteAddr
+	for h, v := range creds.ipHeaders {
+		req.Header.Set(h, v)
+	}
+
+	w := httptest.NewRecorder()
+	api.Router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return nil
+	}
+	var res struct {
+		Data json.RawMessage `json:"data"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil || string(res.Data) == "null" {
+		return nil
+	}
+	return res.Data
+}
+
+// createdEntity returns the id of the entity created by a POST request, if the
+// entity has a GET route.
+func createdEntity(response []byte, route string, getRoutes map[string]bool) (id string, ok bool) {
+	var res struct {
+		Data struct {
+			Id *int64 `json:"id"`
+		} `json:"data"`
+	}
+	err := json.Unmarshal(response, &res)
+	if err != nil || res.Data.Id == nil {
+		return "", false
+	}
+	base := strings.TrimSuffix(route, "/") + "/:"
+	for r := range getRoutes {
+		if strings.HasPrefix(r, base) && !strings.Contains(r[len(base):], "/") {
+			return strconv.FormatInt(*res.Data.Id, 10), true
+		}
+	}
+	return "", false
+}
+
+// auditDiff returns the changed fields between before and after. If one of them
+// is not a JSON object, returns nil.
+func auditDiff(before json.RawMessage, after json.RawMessage) []string {
+	if !isObject(before) || !isObject(after) {
+		return nil
+	}
+	return tools.ChangedFields(before, after)
+}
+
+func isObject(b json.RawMessage) bool {
+	if b == nil {
+		return true
+	}
+	var m map[string]any
+	return json.Unmarshal(b, &m) == nil
+}
+
+// redact replaces the sensitive fields values of the JSON. Invalid JSONs are
+// returned as nil.
+func redact(b json.RawMessage) json.RawMessage {
+	if b == nil {
+		return nil
+	}
+	var v any
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return nil
+	}
+	b, err = json.Marshal(redactValue(v))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, f := range v {
+			if isSensitiveField(k) {
+				v[k] = auditRedacted
+				continue
+			}
+			v[k] = redactValue(f)
+		}
+	case []any:
+		for i, f := range v {
+			v[i] = redactValue(f)
+		}
+	}
+	return v
+}
+
+func isSensitiveField(field string) bool {
+	field = strings.ToLower(field)
+	for _, s := range auditSensitiveFields {
+		if strings.Contains(field, s) {
+			return true
+		}
+	}
+	return false
+}
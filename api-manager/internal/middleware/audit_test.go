@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	b := json.RawMessage(`{"username":"john","password":"secret","protocol":{"community":"public","port":161},"keys":[{"api-key":"k"}]}`)
+	want := `{"keys":[{"api-key":"[REDACTED]"}],"password":"[REDACTED]","protocol":{"community":"[REDACTED]","port":161},"username":"john"}`
+	got := string(redact(b))
+	if got != want {
+		t.Errorf("redact failed, want: %v, got: %v", want, got)
+	}
+	if redact(json.RawMessage(`invalid`)) != nil {
+		t.Errorf("redact of invalid JSON failed, want: nil, got: not nil")
+	}
+}
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		before json.RawMessage
+		after  json.RawMessage
+		want   []string
+	}{
+		{json.RawMessage(`{"name":"a","descr":"b"}`), json.RawMessage(`{"name":"a","descr":"c"}`), []string{"descr"}},
+		{nil, json.RawMessage(`{"name":"a","descr":"b"}`), []string{"descr", "name"}},
+		{json.RawMessage(`{"name":"a"}`), nil, []string{"name"}},
+		{json.RawMessage(`[1,2]`), json.RawMessage(`{"name":"a"}`), nil},
+	}
+	for _, test := range tests {
+		got := auditDiff(test.before, test.after)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("auditDiff failed, want: %v, got: %v", test.want, got)
+		}
+	}
+}
+
+func TestCreatedEntity(t *testing.T) {
+	getRoutes := map[string]bool{
+		"/api/v1/users/:userId":                     true,
+		"/api/v1/users/:userId/teams":               true,
+		"/api/v1/alarm/profiles/:profileId/emails/": true,
+	}
+	tests := []struct {
+		response string
+		route    string
+		wantId   string
+		wantOk   bool
+	}{
+		{`{"data":{"id":3},"message":""}`, "/api/v1/users/", "3", true},
+		{`{"data":{"id":3},"message":""}`, "/api/v1/alarm/profiles/:profileId/emails/", "", false},
+		{`{"data":null,"message":""}`, "/api/v1/users/", "", false},
+	}
+	for _, test := range tests {
+		id, ok := createdEntity([]byte(test.response), test.route, getRoutes)
+		if id != test.wantId || ok != test.wantOk {
+			t.Errorf("createdEntity failed, want: %v %v, got: %v %v", test.wantId, test.wantOk, id, ok)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// ContainerGuard allow users that can manage the container of the route pass, which
+// are the admins and the admins of the container team. If the route has a metric,
+// the metric must be of the container. Routes without a container must validate
+// the team on the handler.
+// Responses:
+//   - 400 If invalid id
+//   - 403 If can't manage the container
+//   - 404 If container does not exists
+//   - 404 If metric is not of the container
+func ContainerGuard(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("containerId")
+		if rawId == "" {
+			c.Next()
+			return
+		}
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, teamId, err := api.PG.GetContainerTeam(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+			api.Log.Error("Fail to get container team", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusNotFound, tools.MsgRes(tools.MsgContainerNotFound))
+			return
+		}
+
+		ok, err := tools.ManagesTeamContainers(api, c, teamId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if user manages the team containers", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		rawMetricId := c.Param("metricId")
+		if rawMetricId == "" {
+			c.Next()
+			return
+		}
+		metricId, err := strconv.ParseInt(rawMetricId, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		exists, containerId, err := api.PG.GetMetricContainerId(ctx, metricId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+			api.Log.Error("Fail to get metric container id", logger.ErrField(err))
+			return
+		}
+		if !exists || containerId != int32(id) {
+			c.AbortWithStatusJSON(http.StatusNotFound, tools.MsgRes(tools.MsgMetricNotFound))
+			return
+		}
+		c.Next()
+	}
+}
@@ -19,7 +19,7 @@ func RequestsCounter(api *api.API) func(c *gin.Context) {
 			return
 		}
 		_, ok := api.Counter.Whitelist.Load(meta.UserId)
-		if !ok && meta.Role < roles.Master {
+		if !ok && meta.Role < roles.Master && !isInternalRequest(c) {
 			api.Counter.IncrRequests()
 		}
 		c.Next()
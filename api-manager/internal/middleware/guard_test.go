@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// guardData is the teams, containers and metrics of the guards tests.
+type guardData struct {
+	// members is the team role by user and team.
+	members map[[2]int64]int64
+	// containers is the team of each container.
+	containers map[int64]int64
+	// metrics is the container of each metric.
+	metrics map[int64]int64
+	// shared is the contextual metrics by team shared with the user teams.
+	shared map[[2]int64]bool
+}
+
+// query returns the single value row of the guards queries.
+func (d guardData) query(query string, args []driver.Value) (value int64, ok bool) {
+	switch {
+	case strings.Contains(query, "FROM users_teams ut"):
+		value, ok = d.members[[2]int64{args[0].(int64), args[1].(int64)}]
+	case strings.Contains(query, "FROM containers WHERE id"):
+		value, ok = d.containers[args[0].(int64)]
+	case strings.Contains(query, "FROM metrics WHERE id"):
+		value, ok = d.metrics[args[0].(int64)]
+	case strings.Contains(query, "FROM dashboards d"):
+		if d.shared[[2]int64{args[0].(int64), args[3].(int64)}] {
+			value = 1
+		}
+		ok = true
+	}
+	return value, ok
+}
+
+// guardConnector is a database connector that answers the guards queries.
+type guardConnector struct{ data guardData }
+
+func (c guardConnector) Connect(context.Context) (driver.Conn, error) { return guardConn(c), nil }
+func (c guardConnector) Driver() driver.Driver                        { return nil }
+
+type guardConn struct{ data guardData }
+
+func (c guardConn) Prepare(query string) (driver.Stmt, error) {
+	return guardStmt{data: c.data, query: query}, nil
+}
+func (c guardConn) Close() error              { return nil }
+func (c guardConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type guardStmt struct {
+	data  guardData
+	query string
+}
+
+func (s guardStmt) Close() error                               { return nil }
+func (s guardStmt) NumInput() int                              { return -1 }
+func (s guardStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (s guardStmt) Query(args []driver.Value) (driver.Rows, error) {
+	value, ok := s.data.query(s.query, args)
+	return &guardRows{value: value, done: !ok}, nil
+}
+
+type guardRows struct {
+	value int64
+	done  bool
+}
+
+func (r *guardRows) Columns() []string { return []string{"value"} }
+func (r *guardRows) Close() error      { return nil }
+func (r *guardRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
+
+// newGuardAPI returns an api with the users teams, containers and metrics. User 1 is
+// admin of team 1 and viewer of team 2, container 10 is of team 1, container 20 of
+// team 2 and container 30 has no team. Metric 100 is of container 10. The
+// contextual metric 1000 of team 3 is on a dashboard shared with the user teams.
+func newGuardAPI() *api.API {
+	data := guardData{
+		members:    map[[2]int64]int64{{1, 1}: int64(roles.TeamAdmin), {1, 2}: int64(roles.TeamViewer)},
+		containers: map[int64]int64{10: 1, 20: 2, 30: 0},
+		metrics:    map[int64]int64{100: 10},
+		shared:     map[[2]int64]bool{{3, 1000}: true},
+	}
+	return &api.API{PG: pg.NewWithDB(sql.OpenDB(guardConnector{data: data}))}
+}
+
+// serveGuard serves the route with the guard, as the user with the role,
+// returning the response status.
+func serveGuard(guard gin.HandlerFunc, route string, path string, role roles.Role) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(route, func(c *gin.Context) {
+		c.Set("sess_meta", auth.SessionMeta{UserId: 1, Role: role})
+	}, guard, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestTeamGuard(t *testing.T) {
+	api := newGuardAPI()
+	guard := TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamAdmin)
+	tests := []struct {
+		name string
+		path string
+		role roles.Role
+		want int
+	}{
+		{"team admin", "/teams/1", roles.Viewer, http.StatusOK},
+		{"team viewer", "/teams/2", roles.Viewer, http.StatusForbidden},
+		{"not member", "/teams/3", roles.Viewer, http.StatusForbidden},
+		{"teams manager not member", "/teams/3", roles.TeamsManager, http.StatusOK},
+		{"invalid id", "/teams/x", roles.Viewer, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		got := serveGuard(guard, "/teams/:teamId", test.path, test.role)
+		if got != test.want {
+			t.Errorf("TeamGuard %s failed, want: %d, got: %d", test.name, test.want, got)
+		}
+	}
+}
+
+func TestMetricDataGuard(t *testing.T) {
+	api := newGuardAPI()
+	guard := MetricDataGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer)
+	tests := []struct {
+		name string
+		path string
+		role roles.Role
+		want int
+	}{
+		{"team member", "/teams/2/ctx/1/metrics/2000", roles.Viewer, http.StatusOK},
+		{"shared metric", "/teams/3/ctx/1/metrics/1000", roles.Viewer, http.StatusOK},
+		{"not shared metric", "/teams/3/ctx/1/metrics/2000", roles.Viewer, http.StatusForbidden},
+		{"shared metric of other team", "/teams/4/ctx/1/metrics/1000", roles.Viewer, http.StatusForbidden},
+		{"invalid metric id", "/teams/3/ctx/1/metrics/x", roles.Viewer, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		got := serveGuard(guard, "/teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId", test.path, test.role)
+		if got != test.want {
+			t.Errorf("MetricDataGuard %s failed, want: %d, got: %d", test.name, test.want, got)
+		}
+	}
+}
+
+func TestContainerGuard(t *testing.T) {
+	api := newGuardAPI()
+	guard := ContainerGuard(api)
+	tests := []struct {
+		name string
+		path string
+		role roles.Role
+		want int
+	}{
+		{"team admin container", "/containers/10/metrics/100", roles.Viewer, http.StatusOK},
+		{"team viewer container", "/containers/20/metrics/100", roles.Viewer, http.StatusForbidden},
+		{"container without team", "/containers/30/metrics/100", roles.TeamsManager, http.StatusForbidden},
+		{"admin other team container", "/containers/20/metrics/100", roles.Admin, http.StatusNotFound},
+		{"metric of other container", "/containers/10/metrics/200", roles.Viewer, http.StatusNotFound},
+		{"container not found", "/containers/40/metrics/100", roles.Admin, http.StatusNotFound},
+	}
+	for _, test := range tests {
+		got := serveGuard(guard, "/containers/:containerId/metrics/:metricId", test.path, test.role)
+		if got != test.want {
+			t.Errorf("ContainerGuard %s failed, want: %d, got: %d", test.name, test.want, got)
+		}
+	}
+}
+
+func TestManagesTeamContainers(t *testing.T) {
+	api := newGuardAPI()
+	guard := func(c *gin.Context) {
+		ok, err := tools.ManagesTeamContainers(api, c, int32(c.GetInt("teamId")))
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			c.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+	tests := []struct {
+		name   string
+		teamId int
+		role   roles.Role
+		want   int
+	}{
+		{"team admin", 1, roles.Viewer, http.StatusOK},
+		{"team viewer", 2, roles.Viewer, http.StatusForbidden},
+		{"not member", 3, roles.TeamsManager, http.StatusForbidden},
+		{"no team", 0, roles.TeamsManager, http.StatusForbidden},
+		{"admin", 3, roles.Admin, http.StatusOK},
+	}
+	for _, test := range tests {
+		teamId := test.teamId
+		got := serveGuard(func(c *gin.Context) {
+			c.Set("teamId", teamId)
+			guard(c)
+		}, "/", "/", test.role)
+		if got != test.want {
+			t.Errorf("ManagesTeamContainers %s failed, want: %d, got: %d", test.name, test.want, got)
+		}
+	}
+}
@@ -50,9 +50,9 @@ func ParseContextParams(api *api.API) func(c *gin.Context) {
 					Key:   "ctxId",
 					Value: strconv.FormatInt(int64(r.ContextId), 10),
 				}
-			case "id":
+			case "teamId":
 				c.Params[i] = gin.Param{
-					Key:   "id",
+					Key:   "teamId",
 					Value: strconv.FormatInt(int64(r.TeamId), 10),
 				}
 			}
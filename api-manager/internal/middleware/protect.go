@@ -1,19 +1,28 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
 	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/gin-gonic/gin"
 )
 
 // APIKeyHeader is the API Key header.
 const APIKeyHeader = "X-API-Key"
 
+// ErrIPNotAllowed is returned when the client ip is not allowed to use the API Key.
+var ErrIPNotAllowed = errors.New("ip not allowed to use the api key")
+
 func validateClient(api *api.API, c *gin.Context) (meta auth.SessionMeta, err error) {
 	ctx := c.Request.Context()
 	sess, err := c.Cookie(auth.SessionCookieName)
@@ -26,8 +35,17 @@ func validateClient(api *api.API, c *gin.Context) (meta auth.SessionMeta, err er
 		if err != nil {
 			return meta, err
 		}
+		if !auth.IPAllowed(apikeyMeta.AllowedIPs, c.ClientIP()) {
+			return meta, ErrIPNotAllowed
+		}
+		err = api.Auth.SetAPIKeyLastUsed(ctx, apikeyMeta.Id, time.Now())
+		if err != nil {
+			api.Log.Error("Fail to set api key last use", logger.ErrField(err))
+		}
 		meta.Role = apikeyMeta.Role
 		meta.UserId = apikeyMeta.UserId
+		meta.APIKeyId = apikeyMeta.Id
+		meta.Scopes = apikeyMeta.Scopes
 		return meta, nil
 	}
 	meta, err = api.Auth.Validate(ctx, sess)
@@ -37,11 +55,36 @@ func validateClient(api *api.API, c *gin.Context) (meta auth.SessionMeta, err er
 	return meta, nil
 }
 
+// clientRoute returns the matched route without the routes prefix.
+func clientRoute(c *gin.Context) string {
+	return strings.TrimPrefix(c.FullPath(), path.Join("/", env.APIManagerRoutesPrefix))
+}
+
+// scopesAllow returns true if the client API Key scopes allow the request.
+func scopesAllow(c *gin.Context, meta auth.SessionMeta) bool {
+	if len(meta.Scopes) == 0 {
+		return true
+	}
+	teamId, _ := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	containerId, _ := strconv.ParseInt(c.Param("containerId"), 10, 32)
+	return auth.ScopesAllow(meta.Scopes, auth.ScopeRequest{
+		Method:      c.Request.Method,
+		Route:       clientRoute(c),
+		TeamId:      int32(teamId),
+		ContainerId: int32(containerId),
+	})
+}
+
 // Protect validates the user session and role. If succeeded, save session metada in context.
+// API Keys are also checked against their scopes and allowed ips. The request is
+// counted on the client rate limit.
 // Responses:
 //   - 401 If no session cookie
 //   - 401 If session invalid
+//   - 401 If API Key ip not allowed
 //   - 403 If invalid role
+//   - 403 If out of API Key scopes
+//   - 429 If rate limit exceeded
 func Protect(api *api.API, accessLevel roles.Role) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		meta, err := validateClient(api, c)
@@ -52,21 +95,30 @@ func Protect(api *api.API, accessLevel roles.Role) func(c *gin.Context) {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		if meta.Role < accessLevel {
+		if meta.Role < accessLevel || !scopesAllow(c, meta) {
 			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
+		if !rateLimit(api, c, meta) {
+			c.Abort()
+			return
+		}
 		c.Set("sess_meta", meta)
 	}
 }
 
 // Protect validates the user session and role. Allowing users with required roles,
 // or if user info belogs to user. If succeeded, save session metada in context.
+// API Keys are also checked against their scopes and allowed ips. The request is
+// counted on the client rate limit.
 // Responses:
 //   - 400 If invalid id
 //   - 401 If no session cookie
 //   - 401 If session invalid
+//   - 401 If API Key ip not allowed
 //   - 403 If invalid role
+//   - 403 If out of API Key scopes
+//   - 429 If rate limit exceeded
 func ProtectUser(api *api.API, accessLevel roles.Role) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		id, err := strconv.ParseInt(c.Param("userId"), 10, 32)
@@ -83,10 +135,14 @@ func ProtectUser(api *api.API, accessLevel roles.Role) func(c *gin.Context) {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		if meta.Role < accessLevel && meta.UserId != int32(id) {
+		if meta.Role < accessLevel && meta.UserId != int32(id) || !scopesAllow(c, meta) {
 			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
+		if !rateLimit(api, c, meta) {
+			c.Abort()
+			return
+		}
 
 		// save session metadata
 		c.Set("sess_meta", meta)
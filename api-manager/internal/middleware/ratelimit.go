@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitedKey is the context key set when the request was counted, so the
+// routes protected twice are counted once.
+const rateLimitedKey = "rate_limited"
+
+// rateLimitGroups are the route groups of the routes, the other routes are on
+// the default group.
+var rateLimitGroups = map[string]string{
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data":         models.RateLimitGroupRealtime,
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data/history": models.RateLimitGroupHistory,
+	"GET /teams/:teamId/ctx/:ctxId/data/export":                       models.RateLimitGroupHistory,
+	"POST /metrics/data":         models.RateLimitGroupIngest,
+	"POST /metrics/remote-write": models.RateLimitGroupIngest,
+	"POST /metrics/data/import":  models.RateLimitGroupIngest,
+}
+
+// rateLimitGroup returns the route group of the route.
+func rateLimitGroup(method string, route string) string {
+	group, ok := rateLimitGroups[method+" "+route]
+	if !ok {
+		return models.RateLimitGroupDefault
+	}
+	return group
+}
+
+// rateLimitRemaining returns the remaining requests of the window.
+func rateLimitRemaining(p models.RateLimitPolicy, count int64) int64 {
+	if count >= int64(p.Requests) {
+		return 0
+	}
+	return int64(p.Requests) - count
+}
+
+// rateLimitBucket is the counter of a client on a route group window.
+type rateLimitBucket struct {
+	policy models.RateLimitPolicy
+	count  int64
+	reset  time.Duration
+}
+
+// exceeded returns true if the bucket requests exceeded the policy.
+func (b rateLimitBucket) exceeded() bool {
+	return b.count > int64(b.policy.Requests)
+}
+
+// strictestBucket returns the bucket that limits the request: the exceeded
+// one with the longest reset, otherwise the one with less remaining requests.
+func strictestBucket(buckets []rateLimitBucket) rateLimitBucket {
+	b := buckets[0]
+	for _, o := range buckets[1:] {
+		if o.exceeded() != b.exceeded() {
+			if o.exceeded() {
+				b = o
+			}
+			continue
+		}
+		if o.exceeded() {
+			if o.reset > b.reset {
+				b = o
+			}
+			continue
+		}
+		if rateLimitRemaining(o.policy, o.count) < rateLimitRemaining(b.policy, b.count) {
+			b = o
+		}
+	}
+	return b
+}
+
+// rateLimit counts the client request on its route group and sets the
+// RateLimit headers of the strictest bucket. The requests with an API Key
+// are counted on both the API Key and its user buckets, so the user limit
+// holds across its API Keys. Returns false if the request was aborted.
+func rateLimit(api *api.API, c *gin.Context, meta auth.SessionMeta) (ok bool) {
+	if _, limited := c.Get(rateLimitedKey); limited || isInternalRequest(c) {
+		return true
+	}
+	c.Set(rateLimitedKey, true)
+	ctx := c.Request.Context()
+	group := rateLimitGroup(c.Request.Method, clientRoute(c))
+
+	apikeyIds := []int32{0}
+	if meta.APIKeyId != 0 {
+		apikeyIds = append(apikeyIds, meta.APIKeyId)
+	}
+	buckets := make([]rateLimitBucket, 0, len(apikeyIds))
+	for _, apikeyId := range apikeyIds {
+		policy, ok := rateLimitPolicy(api, c, group, meta.UserId, apikeyId)
+		if !ok {
+			return false
+		}
+		if policy.Requests == 0 {
+			continue
+		}
+
+		client := "users:" + strconv.FormatInt(int64(meta.UserId), 10)
+		if apikeyId != 0 {
+			client = "apikeys:" + strconv.FormatInt(int64(apikeyId), 10)
+		}
+		window := time.Duration(policy.Window) * time.Second
+		count, reset, err := api.Cache.IncrRateLimit(ctx, group, client, window)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			c.AbortWithStatus(http.StatusInternalServerError)
+			api.Log.Error("Fail to increment rate limit on cache", logger.ErrField(err))
+			return false
+		}
+		buckets = append(buckets, rateLimitBucket{policy: policy, count: count, reset: reset})
+	}
+	if len(buckets) == 0 {
+		return true
+	}
+
+	b := strictestBucket(buckets)
+	resetSeconds := strconv.FormatInt(int64(math.Ceil(b.reset.Seconds())), 10)
+	c.Header("RateLimit-Policy", strconv.FormatInt(int64(b.policy.Requests), 10)+";w="+strconv.FormatInt(int64(b.policy.Window), 10))
+	c.Header("RateLimit-Limit", strconv.FormatInt(int64(b.policy.Requests), 10))
+	c.Header("RateLimit-Remaining", strconv.FormatInt(rateLimitRemaining(b.policy, b.count), 10))
+	c.Header("RateLimit-Reset", resetSeconds)
+	if b.exceeded() {
+		c.Header("Retry-After", resetSeconds)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, tools.MsgRes(tools.MsgRateLimited))
+		return false
+	}
+	return true
+}
+
+// rateLimitPolicy returns the rate limit policy of the client on the route
+// group. The apikeyId is zero for the user policy. Returns false if fails,
+// with the request already aborted.
+func rateLimitPolicy(api *api.API, c *gin.Context, group string, userId int32, apikeyId int32) (policy models.RateLimitPolicy, ok bool) {
+	ctx := c.Request.Context()
+	r, err := api.Cache.GetRateLimitPolicy(ctx, group, userId, apikeyId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return policy, false
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		api.Log.Error("Fail to get rate limit policy on cache", logger.ErrField(err))
+		return policy, false
+	}
+	if r.Exists {
+		return r.Policy, true
+	}
+
+	exists, policy, err := api.PG.GetClientRateLimit(ctx, group, userId, apikeyId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return policy, false
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		api.Log.Error("Fail to get rate limit on database", logger.ErrField(err))
+		return policy, false
+	}
+	if !exists {
+		policy = api.RateLimits[group]
+	}
+	err = api.Cache.SetRateLimitPolicy(ctx, group, userId, apikeyId, policy)
+	if err != nil {
+		if ctx.Err() != nil {
+			return policy, false
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		api.Log.Error("Fail to set rate limit policy on cache", logger.ErrField(err))
+		return policy, false
+	}
+	return policy, true
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestRateLimitGroup(t *testing.T) {
+	tests := []struct {
+		method string
+		route  string
+		want   string
+	}{
+		{"GET", "/teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data", models.RateLimitGroupRealtime},
+		{"GET", "/teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data/history", models.RateLimitGroupHistory},
+		{"GET", "/teams/:teamId/ctx/:ctxId/data/export", models.RateLimitGroupHistory},
+		{"POST", "/metrics/remote-write", models.RateLimitGroupIngest},
+		{"GET", "/metrics/data/import/:jobId", models.RateLimitGroupDefault},
+		{"GET", "/containers/basics/", models.RateLimitGroupDefault},
+	}
+	for _, test := range tests {
+		if got := rateLimitGroup(test.method, test.route); got != test.want {
+			t.Errorf("rateLimitGroup of %s %s failed, want: %s, got: %s", test.method, test.route, test.want, got)
+		}
+	}
+}
+
+func TestRateLimitRemaining(t *testing.T) {
+	p := models.RateLimitPolicy{Requests: 10, Window: 60}
+	tests := []struct {
+		count int64
+		want  int64
+	}{
+		{1, 9},
+		{10, 0},
+		{11, 0},
+	}
+	for _, test := range tests {
+		if got := rateLimitRemaining(p, test.count); got != test.want {
+			t.Errorf("rateLimitRemaining of %d failed, want: %d, got: %d", test.count, test.want, got)
+		}
+	}
+}
+
+func TestStrictestBucket(t *testing.T) {
+	user := rateLimitBucket{policy: models.RateLimitPolicy{Requests: 100, Window: 60}, count: 50, reset: time.Second * 30}
+	apikey := rateLimitBucket{policy: models.RateLimitPolicy{Requests: 10, Window: 60}, count: 9, reset: time.Second * 10}
+	userExceeded := rateLimitBucket{policy: models.RateLimitPolicy{Requests: 100, Window: 60}, count: 101, reset: time.Second * 30}
+	apikeyExceeded := rateLimitBucket{policy: models.RateLimitPolicy{Requests: 10, Window: 60}, count: 11, reset: time.Second * 10}
+	apikeyFull := rateLimitBucket{policy: models.RateLimitPolicy{Requests: 10, Window: 60}, count: 10, reset: time.Second * 40}
+	tests := []struct {
+		name    string
+		buckets []rateLimitBucket
+		want    rateLimitBucket
+	}{
+		{"less remaining", []rateLimitBucket{user, apikey}, apikey},
+		{"exceeded", []rateLimitBucket{userExceeded, apikey}, userExceeded},
+		{"exceeded over full", []rateLimitBucket{userExceeded, apikeyFull}, userExceeded},
+		{"longest reset", []rateLimitBucket{userExceeded, apikeyExceeded}, userExceeded},
+	}
+	for _, test := range tests {
+		if got := strictestBucket(test.buckets); got != test.want {
+			t.Errorf("strictestBucket %s failed, want: %+v, got: %+v", test.name, test.want, got)
+		}
+	}
+}
@@ -12,51 +12,99 @@ import (
 )
 
 // TeamGuard allow authenticated users with a role bigger or equal to the accessLevel
-// and members of team pass. If user role is bigger ot equal to the freepassLevel will
-// let pass even if not member.
+// and members of team with a team role bigger or equal to the teamRole pass. If the
+// route has a context, the member role on the context is used instead, if any. If
+// user role is bigger ot equal to the freepassLevel will let pass even if not member.
 // Responses:
 //   - 400 If invalid id
 //   - 403 If invalid role
 //   - 403 If not member
-func TeamGuard(api *api.API, accessLevel roles.Role, freepassLevel roles.Role) func(c *gin.Context) {
+//   - 403 If invalid team role
+func TeamGuard(api *api.API, accessLevel roles.Role, freepassLevel roles.Role, teamRole roles.TeamRole) func(c *gin.Context) {
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
+		teamGuard(api, c, accessLevel, freepassLevel, teamRole, false)
+	}
+}
 
-		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
-			return
-		}
+// MetricDataGuard is the TeamGuard of the contextual metrics data routes, which
+// also lets pass the members of the teams that a dashboard of the team showing
+// the contextual metric is shared with, so they can read the dashboard data.
+// Responses:
+//   - 400 If invalid id
+//   - 403 If invalid role
+//   - 403 If not member nor the contextual metric is shared
+//   - 403 If invalid team role
+func MetricDataGuard(api *api.API, accessLevel roles.Role, freepassLevel roles.Role, teamRole roles.TeamRole) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		teamGuard(api, c, accessLevel, freepassLevel, teamRole, true)
+	}
+}
 
-		meta, err := tools.GetSessionMeta(c)
-		if err != nil {
-			c.AbortWithStatus(http.StatusInternalServerError)
-			return
-		}
+// teamGuard checks the team access of TeamGuard. If shared is true, the users
+// that are not allowed pass if the route contextual metric is shared with them.
+func teamGuard(api *api.API, c *gin.Context, accessLevel roles.Role, freepassLevel roles.Role, teamRole roles.TeamRole, shared bool) {
+	ctx := c.Request.Context()
 
-		if meta.Role >= freepassLevel {
-			c.Next()
-			return
-		} else if meta.Role < accessLevel {
-			c.AbortWithStatus(http.StatusForbidden)
-			return
-		}
+	teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+		return
+	}
 
-		exists, err := api.PG.TeamMemberExists(ctx, int32(teamId), meta.UserId)
-		if err != nil {
-			if ctx.Err() != nil {
-				return
-			}
-			c.AbortWithStatus(http.StatusInternalServerError)
-			api.Log.Error("Fail to check if user is a member of a team", logger.ErrField(err))
-			return
-		}
+	meta, err := tools.GetSessionMeta(c)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	if meta.Role >= freepassLevel {
+		c.Next()
+		return
+	} else if meta.Role < accessLevel {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
 
-		if !exists {
-			c.AbortWithStatus(http.StatusForbidden)
+	// ctx id is zero if the route has no context
+	ctxId, _ := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+
+	exists, role, err := api.PG.GetTeamMemberRole(ctx, int32(teamId), meta.UserId, int32(ctxId))
+	if err != nil {
+		if ctx.Err() != nil {
 			return
 		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		api.Log.Error("Fail to get user team role", logger.ErrField(err))
+		return
+	}
 
+	if exists && role >= teamRole {
 		c.Next()
+		return
 	}
+	if !shared {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	ctxMetricId, err := strconv.ParseInt(c.Param("ctxMetricId"), 10, 64)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+		return
+	}
+	ok, err := api.PG.ContextualMetricShared(ctx, int32(teamId), int32(ctxId), meta.UserId, ctxMetricId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+		api.Log.Error("Fail to check if contextual metric is shared", logger.ErrField(err))
+		return
+	}
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	c.Next()
 }
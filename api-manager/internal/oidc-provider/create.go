@@ -0,0 +1,64 @@
+package oidcprovider
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates an OpenID Connect provider.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If ident already exists.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var provider models.OIDCProvider
+		err := c.ShouldBind(&provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		exists, err := api.PG.OIDCProviderIdentExists(ctx, provider.Ident, -1)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if OIDC provider ident exists", logger.ErrField(err))
+			return
+		}
+		if exists {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgIdentExists))
+			return
+		}
+
+		id, err := api.PG.CreateOIDCProvider(ctx, provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create OIDC provider", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("OIDC provider created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
@@ -0,0 +1,47 @@
+package oidcprovider
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Deletes an OpenID Connect provider. The provider users are kept, but can't
+// login until a provider with the same ident is created.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func DeleteHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("providerId")
+		id, err := strconv.ParseInt(rawId, 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.DeleteOIDCProvider(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to delete OIDC provider", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgOIDCProviderNotFound))
+			return
+		}
+		api.Log.Info("OIDC provider deleted, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -0,0 +1,111 @@
+package oidcprovider
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets an OpenID Connect provider.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func GetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.ParseInt(c.Param("providerId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, provider, err := api.PG.GetOIDCProvider(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC provider", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgOIDCProviderNotFound))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(provider))
+	}
+}
+
+// Gets OpenID Connect providers.
+// Params:
+//   - "limit" Limit of providers returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//   - "ident" Filter by ident.
+//   - "name" Filter by name.
+//   - "issuer" Filter by issuer.
+//   - "enabled" Filter by enabled state, "1" or "0".
+//   - "order-by" Column to order by.
+//   - "order-by-fn" Order function, "asc" or "desc".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var e bool
+		var enabled *bool
+		rawEnabled := c.Query("enabled")
+		if rawEnabled == "1" {
+			e = true
+			enabled = &e
+		} else if rawEnabled == "0" {
+			enabled = &e
+		}
+
+		providers, err := api.PG.GetOIDCProviders(ctx, pg.OIDCProviderQueryFilters{
+			Ident:     c.Query("ident"),
+			Name:      c.Query("name"),
+			Issuer:    c.Query("issuer"),
+			Enabled:   enabled,
+			OrderBy:   c.Query("order-by"),
+			OrderByFn: c.Query("order-by-fn"),
+			Limit:     limit,
+			Offset:    offset,
+		})
+		if err != nil {
+			if err == pg.ErrInvalidOrderByColumn || err == pg.ErrInvalidFilterValue || err == pg.ErrInvalidOrderByFn {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC providers", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(providers))
+	}
+}
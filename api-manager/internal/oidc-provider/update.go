@@ -0,0 +1,77 @@
+package oidcprovider
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Updates an OpenID Connect provider.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If ident already exists.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func UpdateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("providerId")
+		id, err := strconv.ParseInt(rawId, 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var provider models.OIDCProvider
+		err = c.ShouldBind(&provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(provider)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		provider.Id = int32(id)
+
+		exists, err := api.PG.OIDCProviderIdentExists(ctx, provider.Ident, provider.Id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if OIDC provider ident exists", logger.ErrField(err))
+			return
+		}
+		if exists {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgIdentExists))
+			return
+		}
+
+		exists, err = api.PG.UpdateOIDCProvider(ctx, provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update OIDC provider", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgOIDCProviderNotFound))
+			return
+		}
+		api.Log.Info("OIDC provider updated, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -0,0 +1,37 @@
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+	"sync"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+// Get the OpenAPI document of the api.
+// Responses:
+//   - 200 If succeeded.
+func SpecHandler(api *api.API) func(c *gin.Context) {
+	var once sync.Once
+	var doc *Document
+	return func(c *gin.Context) {
+		once.Do(func() {
+			doc = Build(api.Router.Routes(), env.APIManagerRoutesPrefix, Operations)
+		})
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// Get the api documentation page, which reads the OpenAPI document.
+// Responses:
+//   - 200 If succeeded.
+func DocsHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+	}
+}
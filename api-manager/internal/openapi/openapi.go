@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Version is the OpenAPI specification version.
+const Version = "3.0.3"
+
+const (
+	// SessionCookieScheme is the security scheme name of the session cookie.
+	SessionCookieScheme = "session"
+	// APIKeyScheme is the security scheme name of the api key header.
+	APIKeyScheme = "apiKey"
+)
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+type PathItem struct {
+	Get    *OperationObject `json:"get,omitempty"`
+	Post   *OperationObject `json:"post,omitempty"`
+	Patch  *OperationObject `json:"patch,omitempty"`
+	Delete *OperationObject `json:"delete,omitempty"`
+}
+
+type OperationObject struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationId string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
+
+// Operation is the documentation of a route.
+type Operation struct {
+	// Tag is the group of the operation.
+	Tag string
+	// Summary is the short description.
+	Summary string
+	// Description is the long description.
+	Description string
+	// Public is true if no authentication is required.
+	Public bool
+	// Params are the query params.
+	Params []Param
+	// Body is a value of the json body type.
+	Body any
+	// Consumes are the raw body content types. If body is set, are additional
+	// content types of the body.
+	Consumes []string
+	// Data is a value of the response data type.
+	Data any
+	// Plain is true if the data is not wrapped in the api response. Plain without
+	// data means that the response has only the produced content types.
+	Plain bool
+	// Produces are additional content types of the succeeded response.
+	Produces []string
+	// Responses are the responses descriptions, each starting with the status code,
+	// like "404 If not found.".
+	Responses []string
+}
+
+// Param is a query param.
+type Param struct {
+	// Name is the param name.
+	Name string
+	// Descr is the param description.
+	Descr string
+}
+
+// Key returns the operations key of a route.
+func Key(method string, path string) string {
+	return method + " " + path
+}
+
+// Build builds the OpenAPI document of the routes, which paths are relative to the prefix.
+// Routes without documentation are ignored.
+func Build(routes gin.RoutesInfo, prefix string, operations map[string]Operation) *Document {
+	prefix = "/" + strings.Trim(prefix, "/")
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   "Nemesys API",
+			Version: "1",
+		},
+		Servers: []Server{{URL: prefix}},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				SessionCookieScheme: {Type: "apiKey", In: "cookie", Name: auth.SessionCookieName},
+				APIKeyScheme:        {Type: "apiKey", In: "header", Name: middleware.APIKeyHeader},
+			},
+		},
+	}
+	schemas := newSchemaBuilder(doc.Components.Schemas)
+
+	tags := make(map[string]bool)
+	for _, r := range routes {
+		path := strings.TrimPrefix(r.Path, prefix)
+		if prefix == "/" {
+			path = r.Path
+		}
+		op, ok := operations[Key(r.Method, path)]
+		if !ok {
+			continue
+		}
+
+		specPath, pathParams := toSpecPath(path)
+		item, ok := doc.Paths[specPath]
+		if !ok {
+			item = new(PathItem)
+			doc.Paths[specPath] = item
+		}
+
+		o := buildOperation(schemas, r.Method, path, op, pathParams)
+		switch r.Method {
+		case http.MethodGet:
+			item.Get = o
+		case http.MethodPost:
+			item.Post = o
+		case http.MethodPatch:
+			item.Patch = o
+		case http.MethodDelete:
+			item.Delete = o
+		}
+		tags[op.Tag] = true
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+	return doc
+}
+
+func buildOperation(schemas *schemaBuilder, method string, path string, op Operation, pathParams []string) *OperationObject {
+	o := &OperationObject{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationId: getOperationId(method, path),
+		Responses:   make(map[string]Response),
+		Security:    []map[string][]string{},
+	}
+	if op.Tag != "" {
+		o.Tags = []string{op.Tag}
+	}
+	if !op.Public {
+		o.Security = []map[string][]string{
+			{SessionCookieScheme: {}},
+			{APIKeyScheme: {}},
+		}
+	}
+
+	for _, p := range pathParams {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:     p,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	for _, p := range op.Params {
+		o.Parameters = append(o.Parameters, Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Descr,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if op.Body != nil {
+		schema := schemas.schemaOf(op.Body)
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: schema},
+			},
+		}
+		for _, t := range op.Consumes {
+			o.RequestBody.Content[t] = MediaType{Schema: schema}
+		}
+	} else if len(op.Consumes) > 0 {
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content:  make(map[string]MediaType, len(op.Consumes)),
+		}
+		for _, t := range op.Consumes {
+			o.RequestBody.Content[t] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	}
+
+	for _, r := range op.Responses {
+		code, descr, _ := strings.Cut(r, " ")
+		res, ok := o.Responses[code]
+		if ok {
+			res.Description += "\n" + descr
+		} else {
+			res.Description = descr
+		}
+		if code == strconv.Itoa(http.StatusOK) {
+			res.Content = getDataContent(schemas, op)
+		} else {
+			res.Content = map[string]MediaType{
+				"application/json": {Schema: schemas.schemaOf(apiResponse{})},
+			}
+		}
+		o.Responses[code] = res
+	}
+	// the authenticated routes are rate limited
+	if _, ok := o.Responses[strconv.Itoa(http.StatusTooManyRequests)]; !ok && !op.Public {
+		o.Responses[strconv.Itoa(http.StatusTooManyRequests)] = Response{
+			Description: "If rate limit exceeded.",
+			Content: map[string]MediaType{
+				"application/json": {Schema: schemas.schemaOf(apiResponse{})},
+			},
+		}
+	}
+	if _, ok := o.Responses[strconv.Itoa(http.StatusOK)]; !ok {
+		o.Responses[strconv.Itoa(http.StatusOK)] = Response{
+			Description: "If succeeded.",
+			Content:     getDataContent(schemas, op),
+		}
+	}
+	return o
+}
+
+// apiResponse is the api response without data.
+type apiResponse struct {
+	Data    any    `json:"data"`
+	Message string `json:"message"`
+}
+
+// getDataContent returns the content of the succeeded response.
+func getDataContent(schemas *schemaBuilder, op Operation) map[string]MediaType {
+	content := make(map[string]MediaType, len(op.Produces)+1)
+	for _, t := range op.Produces {
+		content[t] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+	if op.Plain && op.Data == nil {
+		return content
+	}
+
+	var schema *Schema
+	if op.Plain {
+		schema = schemas.schemaOf(op.Data)
+	} else if op.Data == nil {
+		schema = schemas.schemaOf(apiResponse{})
+	} else {
+		schema = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"data":    schemas.schemaOf(op.Data),
+				"message": {Type: "string"},
+			},
+		}
+	}
+	content["application/json"] = MediaType{Schema: schema}
+	return content
+}
+
+// toSpecPath converts the gin path params to the spec format, returning the
+// params names.
+func toSpecPath(path string) (specPath string, params []string) {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			params = append(params, p[1:])
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+// getOperationId returns an unique operation id, like "get-users-userId".
+func getOperationId(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, p := range strings.Split(path, "/") {
+		p = strings.TrimLeft(p, ":*")
+		if p != "" {
+			id += "-" + p
+		}
+	}
+	return id
+}
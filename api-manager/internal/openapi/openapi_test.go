@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testTier struct {
+	Retention int32 `json:"retention" validate:"required,min=1,max=10"`
+}
+
+type testModel struct {
+	Id     int32      `json:"id" validate:"-"`
+	Name   string     `json:"name" validate:"required,max=50"`
+	Type   string     `json:"type" validate:"required,oneof=a b"`
+	Values []string   `json:"values" validate:"max=5,dive,max=255"`
+	Tiers  []testTier `json:"tiers" validate:"max=5,dive"`
+}
+
+func TestBuild(t *testing.T) {
+	routes := gin.RoutesInfo{
+		{Method: "GET", Path: "/api/v1/models/:modelId"},
+		{Method: "PATCH", Path: "/api/v1/models/:modelId"},
+		{Method: "GET", Path: "/api/v1/undocumented"},
+	}
+	operations := map[string]Operation{
+		"GET /models/:modelId": {
+			Summary:   "Get a model.",
+			Data:      testModel{},
+			Responses: []string{"404 If not found.", "200 If succeeded."},
+		},
+		"PATCH /models/:modelId": {
+			Public: true,
+			Body:   testModel{},
+		},
+	}
+	doc := Build(routes, "api/v1", operations)
+
+	if len(doc.Paths) != 1 {
+		t.Fatalf("Paths length failed, want: %d, got: %d", 1, len(doc.Paths))
+	}
+	item, ok := doc.Paths["/models/{modelId}"]
+	if !ok || item.Get == nil || item.Patch == nil {
+		t.Fatalf("Path item failed, got: %+v", doc.Paths)
+	}
+	if len(item.Get.Parameters) != 1 || item.Get.Parameters[0].In != "path" || item.Get.Parameters[0].Name != "modelId" {
+		t.Errorf("Path param failed, got: %+v", item.Get.Parameters)
+	}
+	if len(item.Get.Security) != 2 || len(item.Patch.Security) != 0 {
+		t.Errorf("Security failed, got: %v and %v", item.Get.Security, item.Patch.Security)
+	}
+	if _, ok := item.Get.Responses["404"]; !ok {
+		t.Errorf("Responses failed, got: %v", item.Get.Responses)
+	}
+
+	s, ok := doc.Components.Schemas["TestModel"]
+	if !ok {
+		t.Fatalf("Model schema not found, got: %v", doc.Components.Schemas)
+	}
+	if want := []string{"name", "type"}; !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("Required failed, want: %v, got: %v", want, s.Required)
+	}
+	if p := s.Properties["name"]; p.MaxLength == nil || *p.MaxLength != 50 {
+		t.Errorf("Name max length failed, want: %d, got: %v", 50, p.MaxLength)
+	}
+	if p := s.Properties["type"]; !reflect.DeepEqual(p.Enum, []any{"a", "b"}) {
+		t.Errorf("Type enum failed, want: %v, got: %v", []any{"a", "b"}, p.Enum)
+	}
+	if p := s.Properties["values"]; p.MaxItems == nil || *p.MaxItems != 5 || p.Items.MaxLength == nil || *p.Items.MaxLength != 255 {
+		t.Errorf("Values limits failed, got: %+v", p)
+	}
+	if p := s.Properties["tiers"]; p.Items.Ref != "#/components/schemas/TestTier" {
+		t.Errorf("Tiers ref failed, want: %s, got: %s", "#/components/schemas/TestTier", p.Items.Ref)
+	}
+	tier := doc.Components.Schemas["TestTier"].Properties["retention"]
+	if tier.Minimum == nil || *tier.Minimum != 1 || tier.Maximum == nil || *tier.Maximum != 10 {
+		t.Errorf("Retention limits failed, got: %+v", tier)
+	}
+}
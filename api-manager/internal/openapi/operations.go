@@ -0,0 +1,2635 @@
+package openapi
+
+import (
+	"github.com/fernandotsda/nemesys/api-manager/internal/config"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/service"
+)
+
+// sharedMetricDataDescr is the description of the contextual metric data
+// routes, which the teams of the shared dashboards can read.
+const sharedMetricDataDescr = "The team viewers, and the members of the teams that a dashboard of the team " +
+	"showing the contextual metric is shared with, can read the data."
+
+// Operations are the routes documentation, keyed by the method and the path relative
+// to the routes prefix, like "GET /users/:userId". Every route must be documented,
+// keep it in sync with the router and the handlers comments.
+var Operations = map[string]Operation{
+	"POST /login": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Login into a user account.",
+		Description: "Local accounts are checked locally, other users are authenticated on the LDAP server, if enabled, " +
+			"and are provisioned on the first login. The LDAP users role and teams are synced on every login.",
+		Body: models.Login{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"401 If username or password is incorrect.",
+			"403 If the LDAP user has no role.",
+			"409 If the LDAP user email is used by other user.",
+			"200 If succeeded, with the login token if the TOTP code is required.",
+		},
+	},
+	"POST /login/totp": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Completes a login with the TOTP code or a recovery code.",
+		Description: "Second step of the logins of users with TOTP enabled. If the authentication policy requires TOTP " +
+			"and the user is not enrolled, the code of the enrollment secret enables the TOTP and the recovery codes are returned. " +
+			"The login token is removed after 5 wrong codes, and the user codes are locked out for 15 minutes after 10 wrong codes.",
+		Body: models.LoginTOTP{},
+		Data: models.RecoveryCodes{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If the TOTP enrollment was not started.",
+			"401 If invalid or expired login token.",
+			"401 If the code is wrong.",
+			"429 If the user has too many wrong codes.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /login/totp/enrollment": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Starts the TOTP enrollment of a login that requires it.",
+		Body:    models.LoginToken{},
+		Data:    models.TOTPEnrollment{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If TOTP is already enabled.",
+			"401 If invalid or expired login token.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /password-reset": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Sends a password reset token to the email of a local user.",
+		Description: "The response is the same if the email has no user, so the emails can't be enumerated. " +
+			"The token expires after the PASSWORD_RESET_TTL and is replaced by a new request.",
+		Body: models.PasswordResetRequest{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If the SMTP server is not configured.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /password-reset/confirm": {
+		Tag:         "Session",
+		Public:      true,
+		Summary:     "Resets a user password with the token sent by email.",
+		Description: "The token is used once and the user session is revoked.",
+		Body:        models.PasswordReset{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If invalid or expired token.",
+			"404 If user does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /login/oidc": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Get the enabled OpenID Connect providers.",
+		Data:    []models.OIDCProviderInfo{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /login/oidc/:ident": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Starts a login on an OpenID Connect provider.",
+		Description: "Redirects the user to the provider authorization endpoint, using the authorization code flow with PKCE. " +
+			"The login state is bound to the browser by the oidc_state cookie.",
+		Params: []Param{
+			{Name: "redirect", Descr: "Path where the user is redirected after the login. Is optional."},
+		},
+		Responses: []string{
+			"404 If provider does not exists or is disabled.",
+			"302 If succeeded.",
+		},
+	},
+	"GET /login/oidc/:ident/callback": {
+		Tag:     "Session",
+		Public:  true,
+		Summary: "Completes a login on an OpenID Connect provider.",
+		Description: "The user is identified by the id token iss and sub claims. It is provisioned on the first login " +
+			"and its role and teams are synced on every login, mapping the provider groups claim.",
+		Params: []Param{
+			{Name: "code", Descr: "The authorization code."},
+			{Name: "state", Descr: "The login state."},
+		},
+		Responses: []string{
+			"400 If invalid or expired state.",
+			"400 If the state was not started by the browser.",
+			"401 If the login on the provider failed.",
+			"403 If the user has no role.",
+			"403 If the username is used by another provider.",
+			"409 If the user email is used by other user.",
+			"404 If provider does not exists or is disabled.",
+			"302 If succeeded and the login has a redirect.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /session": {
+		Tag:     "Session",
+		Summary: "Get the session user.",
+		Data:    models.UserWithoutPW{},
+		Responses: []string{
+			"404 If user not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /logout": {
+		Tag:     "Session",
+		Summary: "Logout of a user account.",
+		Responses: []string{
+			"400 If no session was running.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /session/password": {
+		Tag:         "Session",
+		Summary:     "Changes the session user password.",
+		Description: "The session is renewed, so the other sessions and password reset tokens of the user are revoked.",
+		Body:        models.PasswordChange{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If the password is managed by the authentication provider.",
+			"400 If the current password is wrong.",
+			"404 If user does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /session/totp": {
+		Tag:     "Session",
+		Summary: "Get the session user TOTP status.",
+		Data:    models.TOTPStatus{},
+		Responses: []string{
+			"404 If user does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /session/totp": {
+		Tag:         "Session",
+		Summary:     "Starts the session user TOTP enrollment.",
+		Description: "Returns the secret and its provisioning uri, which is encoded on the QR code read by the authenticator apps. The TOTP is enabled with the first code.",
+		Data:        models.TOTPEnrollment{},
+		Responses: []string{
+			"400 If TOTP is already enabled.",
+			"404 If user does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /session/totp/enable": {
+		Tag:     "Session",
+		Summary: "Enables the session user TOTP, returning the recovery codes.",
+		Body:    models.TOTPCode{},
+		Data:    models.RecoveryCodes{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If TOTP is already enabled.",
+			"400 If the TOTP enrollment was not started.",
+			"400 If the code is wrong.",
+			"404 If user does not exists.",
+			"429 If the user has too many wrong codes.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /session/totp/disable": {
+		Tag:     "Session",
+		Summary: "Disables the session user TOTP with a code or a recovery code.",
+		Body:    models.TOTPCodeOrRecovery{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If TOTP is not enabled.",
+			"400 If the code is wrong.",
+			"403 If the authentication policy requires TOTP for the user role.",
+			"404 If user does not exists.",
+			"429 If the user has too many wrong codes.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /session/totp/recovery-codes": {
+		Tag:     "Session",
+		Summary: "Replaces the session user recovery codes.",
+		Body:    models.TOTPCode{},
+		Data:    models.RecoveryCodes{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If TOTP is not enabled.",
+			"400 If the code is wrong.",
+			"404 If user does not exists.",
+			"429 If the user has too many wrong codes.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /auth-policy": {
+		Tag:     "Session",
+		Summary: "Get the authentication policy.",
+		Data:    models.AuthPolicy{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /auth-policy": {
+		Tag:         "Session",
+		Summary:     "Update the authentication policy.",
+		Description: "The users that must use TOTP and are not enrolled enroll on the next login.",
+		Body:        models.AuthPolicy{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /services/status": {
+		Tag:     "Services",
+		Summary: "Get services status.",
+		Data:    []service.ServiceStatus{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /refkeys/:refkey": {
+		Tag:     "Reference keys",
+		Summary: "Get a metric reference key.",
+		Data:    models.MetricRefkey{},
+		Responses: []string{
+			"404 If metric not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /cost": {
+		Tag:     "Server cost",
+		Summary: "Get the current server cost.",
+		Data:    models.ServerCostResult{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /price-table": {
+		Tag:     "Server cost",
+		Summary: "Get the server price table.",
+		Data:    models.ServerPriceTable{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /base-plan": {
+		Tag:     "Server cost",
+		Summary: "Get the server base plan.",
+		Data:    models.ServerBasePlan{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"POST /metrics/data": {
+		Tag:     "Metric data",
+		Summary: "Adds a metric data to metric data history if enabled and send data to real time service.",
+		Body:    models.MetricDataByRefkey{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If refkey not found.",
+			"403 If metric container is out of the API Key scopes.",
+			"400 If metric is disabled.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /metrics/remote-write": {
+		Tag:         "Metric data",
+		Consumes:    []string{"application/x-protobuf"},
+		Summary:     "Receives a Prometheus remote write request (snappy compressed protobuf) and adds each sample as a metric data, using the same path as the metric data add.",
+		Description: "Receives a Prometheus remote write request (snappy compressed protobuf) and adds each sample as a metric data, using the same path as the metric data add. The series are mapped to metrics by the refkey built with the values of the \"refkey-labels\". Series of containers out of the API Key scopes are ignored. All series are validated before any sample is written, but the samples are written one by one, so a request that fails while writing may have written part of its samples.",
+		Params: []Param{
+			{Name: "refkey-labels", Descr: "Comma separated labels used to build the refkey, the label values are joined with \":\". Default is \"__name__,instance\"."},
+			{Name: "container-id", Descr: "Basic container to create the missing metrics. If omitted, series without refkey are ignored."},
+			{Name: "data-policy-id", Descr: "Data policy of the created metrics. Required if \"container-id\" is set."},
+		},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"403 If container is out of the API Key scopes.",
+			"404 If container not found.",
+			"404 If data policy not found.",
+			"413 If body is too large.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /metrics/data/import": {
+		Tag:         "Metric data",
+		Consumes:    []string{"text/csv", "application/x-ndjson"},
+		Summary:     "Imports the data history of metrics from a csv or ndjson file on the body.",
+		Description: "Imports the data history of metrics from a csv or ndjson file on the body. The file is imported in background, writing each point with the original timestamp on the data policy tier that keeps it, without sending it to the alarm service and the real time service. The csv header must have the \"timestamp\", \"value\" and \"refkey\" or \"metric_id\" columns, and each ndjson line the same keys. Rows are keyed by refkey or, if empty, by metric id. Timestamps are unix seconds or RFC3339.",
+		Params: []Param{
+			{Name: "format", Descr: "File format, \"csv\" or \"ndjson\". Default is \"csv\"."},
+		},
+		Data: models.ImportJob{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If invalid csv header.",
+			"413 If body is too large.",
+			"200 If succeeded, with the import job.",
+		},
+	},
+	"GET /metrics/data/import/:jobId": {
+		Tag:     "Metric data",
+		Summary: "Gets an import job progress.",
+		Data:    models.ImportJob{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /base-plan": {
+		Tag:     "Server cost",
+		Summary: "Update the server base plan.",
+		Body:    models.ServerBasePlan{},
+		Responses: []string{
+			"400 If invalid body.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /price-table": {
+		Tag:     "Server cost",
+		Summary: "Update the server price table.",
+		Body:    models.ServerPriceTable{},
+		Responses: []string{
+			"400 If invalid body.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /users/:userId/logout": {
+		Tag:     "Users",
+		Summary: "Force a user logout.",
+		Responses: []string{
+			"400 If invalid id.",
+			"400 If no session was running.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /users/:userId/totp": {
+		Tag:         "Users",
+		Summary:     "Resets a user TOTP.",
+		Description: "For users that lost their authenticator and recovery codes. The user enrolls again on the next login if the authentication policy requires TOTP.",
+		Responses: []string{
+			"400 If invalid id.",
+			"403 If target's role is superior then the user who requested.",
+			"404 If user does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /users/": {
+		Tag:     "Users",
+		Summary: "Get multiple users in database.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of users returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "role"},
+			{Name: "team-role"},
+			{Name: "first-name"},
+			{Name: "last-name"},
+			{Name: "username"},
+			{Name: "email"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.User{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /users/:userId": {
+		Tag:     "Users",
+		Summary: "Get user in database",
+		Data:    models.UserWithoutPW{},
+		Responses: []string{
+			"400 If invalid id",
+			"404 If user not foud",
+			"200 If succeeded",
+		},
+	},
+	"GET /users/:userId/teams": {
+		Tag:     "Users",
+		Summary: "Get user's teams.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of teams returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.Team{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /users/": {
+		Tag:     "Users",
+		Summary: "Create user on database.",
+		Body:    models.User{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If username is already in use.",
+			"400 If email is already in use.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /users/:userId": {
+		Tag:     "Users",
+		Summary: "Updates user on database.",
+		Body:    models.User{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If username or email already in use.",
+			"404 If user not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /users/:userId": {
+		Tag:     "Users",
+		Summary: "Deletes user from databse",
+		Responses: []string{
+			"400 If invalid id",
+			"404 If user not founded",
+			"201 If succeeded",
+		},
+	},
+	"GET /users/:userId/api-keys/": {
+		Tag:     "Users",
+		Summary: "Get the API Keys of a user, with the last use date.",
+		Data:    []models.APIKeyInfo{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /users/:userId/api-keys/": {
+		Tag:         "Users",
+		Summary:     "Creates a API Key.",
+		Description: "Creates a API Key. The API Key scopes and allowed ips are optional, without scopes the API Key has the same access of the user. Scopes with the \"read\" permission allow GET requests, \"history\" the data history and export requests and \"ingest\" the metric data requests. The \"team-id\" restricts the scope to the routes of the team and the \"container-id\" to the routes or metric data of the container.",
+		Body:        models.APIKeyInfo{},
+		Data:        models.APIkey{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If invalid scope.",
+			"404 If user not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /users/:userId/api-keys/:apikeyId": {
+		Tag:     "Users",
+		Summary: "Deletes a API Key.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/": {
+		Tag:     "Teams",
+		Summary: "Get multi teams on database",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of teams returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "name"},
+			{Name: "ident"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Team{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/": {
+		Tag:     "Teams",
+		Summary: "Creates a new team on databse.",
+		Body:    models.Team{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If ident is already in use.",
+			"400 If ident can be parsed to number.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId": {
+		Tag:     "Teams",
+		Summary: "Updates a team on database.",
+		Body:    models.Team{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid",
+			"400 If ident is already in use.",
+			"400 If ident can be parsed to number.",
+			"404 If team does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId": {
+		Tag:     "Teams",
+		Summary: "Deletes team from databse",
+		Responses: []string{
+			"404 If team not founded",
+			"200 If succeeded",
+		},
+	},
+	"GET /teams/:teamId/members": {
+		Tag:     "Teams",
+		Summary: "Get team's members.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of teams returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "role"},
+			{Name: "team-role"},
+			{Name: "first-name"},
+			{Name: "last-name"},
+			{Name: "username"},
+			{Name: "email"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Member{},
+		Responses: []string{
+			"400 If invalid user or team id.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/members": {
+		Tag:     "Teams",
+		Summary: "Add a member.",
+		Body:    models.TeamMember{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If invalid user or team id.",
+			"400 If user is already a member.",
+			"404 If team does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId/members/:userId": {
+		Tag:     "Teams",
+		Summary: "Updates a member team role.",
+		Body:    models.TeamMemberRole{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If invalid user or team id.",
+			"404 If member does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/members/:userId/contexts/": {
+		Tag:     "Teams",
+		Summary: "Get a member roles on the team contexts.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of roles returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.ContextRole{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/members/:userId/contexts/": {
+		Tag:         "Teams",
+		Summary:     "Sets a member role on a team context.",
+		Description: "The context role replaces the member team role on the context.",
+		Body:        models.ContextRole{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If invalid user or team id.",
+			"404 If member does not exists.",
+			"404 If context does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/members/:userId/contexts/:ctxId": {
+		Tag:     "Teams",
+		Summary: "Removes a member role of a team context.",
+		Responses: []string{
+			"400 If invalid user, team or context id.",
+			"404 If member context role does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/members/:userId": {
+		Tag:     "Teams",
+		Summary: "Remove a member.",
+		Responses: []string{
+			"400 If invalid user or team id.",
+			"400 If user is already a member.",
+			"404 If relation does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/": {
+		Tag:     "Contexts",
+		Summary: "Creates a new context.",
+		Body:    models.Context{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If ident is already in use.",
+			"400 If ident can be parsed to number.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId/ctx/:ctxId": {
+		Tag:     "Contexts",
+		Summary: "Updates a context.",
+		Body:    models.Context{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If ident is already in use.",
+			"400 If ident can be parsed to number.",
+			"400 If invalid id.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/ctx/:ctxId": {
+		Tag:     "Contexts",
+		Summary: "Deletes a context.",
+		Responses: []string{
+			"404 If context not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/metrics/": {
+		Tag:     "Contextual metrics",
+		Summary: "Creates a new contextual metric.",
+		Body:    models.ContextualMetric{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If context or metric does not exists.",
+			"400 If ident is already in use.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId": {
+		Tag:     "Contextual metrics",
+		Summary: "Updates a contextual metric.",
+		Body:    models.ContextualMetric{},
+		Responses: []string{
+			"400 If invalid id.",
+			"400 If invalid body.",
+			"400 If invalid json fields.",
+			"404 If contextual metric does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId": {
+		Tag:     "Contextual metrics",
+		Summary: "Deletes a contextual metric.",
+		Responses: []string{
+			"400 If invalid id.",
+			"404 If contextual metric does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/billing-reports/": {
+		Tag:         "Billing reports",
+		Summary:     "Creates a new billing report of a context month, computing the 95th and 99th percentiles, average, peak and volume of each contextual metric and of the sum of all contextual metrics, and saves it.",
+		Description: "The contextual metrics are selected by \"contextual-metrics-ids\" or by the label \"selector\", matched against the container labels merged with the metric labels. Without both, all the context contextual metrics are selected.",
+		Body:        models.BillingReport{},
+		Data:        models.BillingReport{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If month is in the future.",
+			"400 If a contextual metric is not numeric.",
+			"400 If tier does not exists on a metric data policy.",
+			"404 If a contextual metric is not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/ctx/:ctxId/billing-reports/:reportId": {
+		Tag:     "Billing reports",
+		Summary: "Deletes a billing report.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId": {
+		Tag:     "Teams",
+		Summary: "Get team in database",
+		Data:    models.Team{},
+		Responses: []string{
+			"404 If team not foud",
+			"200 If succeeded",
+		},
+	},
+	"GET /teams/:teamId/ctx/": {
+		Tag:     "Contexts",
+		Summary: "Get all team's contexts.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of teams returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "ident"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Context{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId": {
+		Tag:     "Contexts",
+		Summary: "Get all team's contexts.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of teams returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "ident"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Context{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/": {
+		Tag:     "Contextual metrics",
+		Summary: "Get multi contextual metrics.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of metrics returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "ident"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.ContextualMetric{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId": {
+		Tag:     "Contextual metrics",
+		Summary: "Get a contextual metric.",
+		Data:    models.ContextualMetric{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/alarm-history": {
+		Tag:     "Contextual metrics",
+		Summary: "Get metric alarm history.",
+		Params: []Param{
+			{Name: "start"},
+			{Name: "stop"},
+			{Name: "level"},
+		},
+		Data: [][3]any{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/alarm-state": {
+		Tag:     "Contextual metrics",
+		Summary: "Get the metric's alarm state.",
+		Data:    models.AlarmState{},
+		Responses: []string{
+			"404 If alarm state not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/alarm-state/recognize": {
+		Tag:     "Contextual metrics",
+		Summary: "Updates the metric's alarm state to recognized.",
+		Responses: []string{
+			"404 If alarm state not found.",
+			"400 If alarm state is not in alarm state.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/alarm-state/resolve": {
+		Tag:     "Contextual metrics",
+		Summary: "Updates the metric's alarm state to not alarmed.",
+		Responses: []string{
+			"404 If alarm state not found.",
+			"400 If alarm state is not in recognized state.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/billing-reports/": {
+		Tag:     "Billing reports",
+		Summary: "Get multi billing reports of a context, newest first.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of reports returned. Default is 30, max is 30, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.BillingReport{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/billing-reports/:reportId": {
+		Tag:      "Billing reports",
+		Summary:  "Get a billing report.",
+		Produces: []string{"text/csv"},
+		Params: []Param{
+			{Name: "format", Descr: "Response format, \"json\" or \"csv\". The csv is downloaded as a file, with the total on the last row. Default is \"json\"."},
+		},
+		Data: models.BillingReport{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/scheduled-reports/": {
+		Tag:     "Scheduled reports",
+		Summary: "Get multi scheduled reports of a context.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of reports returned. Default is 30, max is 30, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.ScheduledReport{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId": {
+		Tag:     "Scheduled reports",
+		Summary: "Get a scheduled report.",
+		Data:    models.ScheduledReport{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/scheduled-reports/": {
+		Tag:         "Scheduled reports",
+		Summary:     "Creates a new scheduled report of a context.",
+		Description: "The report runs on the cron \"schedule\", in UTC, computing the \"aggregations\" and optionally the alarm counts by alarm category of each contextual metric on the \"time-range\" until the run. The files of the \"formats\" are saved with the run and sent to the \"emails\" through the SMTP server. The contextual metrics are selected by \"contextual-metrics-ids\" or by the label \"selector\". Without both, all the context contextual metrics are selected.",
+		Body:        models.ScheduledReport{},
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If the schedule never runs.",
+			"404 If context or a contextual metric does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId": {
+		Tag:         "Scheduled reports",
+		Summary:     "Updates a scheduled report.",
+		Description: "The next run is computed again from the schedule.",
+		Body:        models.ScheduledReport{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If the schedule never runs.",
+			"404 If not found or a contextual metric does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId": {
+		Tag:     "Scheduled reports",
+		Summary: "Deletes a scheduled report with its runs and files.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId/run": {
+		Tag:         "Scheduled reports",
+		Summary:     "Runs a scheduled report now, with the time range until now.",
+		Description: "The run is executed on background, the schedule is not changed. Use the run id to follow its status.",
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId/runs": {
+		Tag:     "Scheduled reports",
+		Summary: "Get multi runs of a scheduled report, newest first.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of runs returned. Default is 30, max is 30, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.ScheduledReportRun{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If report not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId/runs/:runId": {
+		Tag:     "Scheduled reports",
+		Summary: "Get a scheduled report run.",
+		Data:    models.ScheduledReportRun{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If report or run not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/scheduled-reports/:reportId/runs/:runId/files/:format": {
+		Tag:         "Scheduled reports",
+		Summary:     "Downloads a scheduled report run file.",
+		Description: "The format is \"csv\" or \"pdf\", one of the run formats.",
+		Produces:    []string{"text/csv", "application/pdf"},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If report, run or file not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/dashboards/": {
+		Tag:     "Dashboards",
+		Summary: "Get multi dashboards of the team and shared with the team.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of dashboards returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.DashboardSimplified{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/dashboards/:dashboardId": {
+		Tag:     "Dashboards",
+		Summary: "Get a dashboard of the team or shared with the team.",
+		Data:    models.Dashboard{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/dashboards/": {
+		Tag:         "Dashboards",
+		Summary:     "Creates a new team dashboard.",
+		Description: "The panels contextual metrics must be of the team contexts. The dashboard is saved as its version 1.",
+		Body:        models.Dashboard{},
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If a panel contextual metric is not of the team or a panel custom query does not exists.",
+			"404 If team not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /teams/:teamId/dashboards/:dashboardId": {
+		Tag:         "Dashboards",
+		Summary:     "Updates a team dashboard, saving it as a new version.",
+		Description: "Dashboards shared with the team can't be updated.",
+		Body:        models.Dashboard{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If a panel contextual metric is not of the team or a panel custom query does not exists.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/dashboards/:dashboardId": {
+		Tag:     "Dashboards",
+		Summary: "Deletes a team dashboard with its versions.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/dashboards/:dashboardId/versions": {
+		Tag:     "Dashboards",
+		Summary: "Get the versions of a dashboard, newest first.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of versions returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.DashboardVersion{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If dashboard not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/dashboards/:dashboardId/versions/:version": {
+		Tag:     "Dashboards",
+		Summary: "Get a version of a dashboard.",
+		Data:    models.Dashboard{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If dashboard or version not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/dashboards/:dashboardId/versions/:version/rollback": {
+		Tag:         "Dashboards",
+		Summary:     "Rollbacks a team dashboard to a version.",
+		Description: "The content of the version is saved as a new version, so the rollback can be undone too.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If dashboard or version not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/dashboards/:dashboardId/shares": {
+		Tag:     "Dashboards",
+		Summary: "Get the teams a team dashboard is shared with.",
+		Data:    []models.Team{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If dashboard not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /teams/:teamId/dashboards/:dashboardId/shares": {
+		Tag:     "Dashboards",
+		Summary: "Shares a team dashboard with other team.",
+		Description: "The members of the other team can read the dashboard and its versions, but can't change it. " +
+			"They can also read the data of the contextual metrics on the dashboard panels, but not of the other team contextual metrics.",
+		Body: models.DashboardShare{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If the team is the dashboard owner.",
+			"404 If dashboard or team not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /teams/:teamId/dashboards/:dashboardId/shares/:shareTeamId": {
+		Tag:     "Dashboards",
+		Summary: "Stops sharing a team dashboard with other team.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If dashboard not found or not shared with the team.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /metric-templates/": {
+		Tag:     "Metric templates",
+		Summary: "Get multi metric templates.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of templates returned. Default is 30, max is 30, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.MetricTemplateSimplified{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /metric-templates/:templateId": {
+		Tag:     "Metric templates",
+		Summary: "Get a metric template.",
+		Data:    models.MetricTemplate{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /metric-templates/": {
+		Tag:         "Metric templates",
+		Summary:     "Creates a new metric template.",
+		Description: "A template is a named set of metric definitions, with their data policy and alarm expressions, of a container type. The \"oid\" is required by SNMPv2c and Flex Legacy templates, \"port\" and \"port-type\" by Flex Legacy templates. The metrics ids must be zero.",
+		Body:        models.MetricTemplate{},
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If invalid container type or metrics.",
+			"400 If name already exists.",
+			"404 If a data policy or alarm expression does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /metric-templates/:templateId": {
+		Tag:         "Metric templates",
+		Summary:     "Updates a metric template and propagates the changes to the linked containers.",
+		Description: "The metrics with id are updated, the metrics with zero id are added and the missing metrics are removed. On each linked container the added metrics are created, the updated are updated and the removed are deleted. The metrics edited on a container since the last sync are skipped and returned, the edited metrics removed from the template are unlinked and kept. The container type is not updated.",
+		Body:        models.MetricTemplate{},
+		Data:        []models.MetricTemplateSyncResult{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If invalid metrics.",
+			"400 If name already exists.",
+			"404 If not found.",
+			"404 If a data policy or alarm expression does not exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /metric-templates/:templateId": {
+		Tag:     "Metric templates",
+		Summary: "Deletes a metric template. The metrics of the linked containers are kept.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /metric-templates/:templateId/containers": {
+		Tag:     "Metric templates",
+		Summary: "Get multi containers linked to a metric template.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.MetricTemplateContainer{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If template not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /metric-templates/:templateId/containers": {
+		Tag:         "Metric templates",
+		Summary:     "Applies a metric template to containers.",
+		Description: "Links the containers to the template, creates the missing metrics and updates the outdated ones, in a single transaction. The metrics edited on a container since the last sync are skipped and returned, unless \"force\" is true, which overwrites them.",
+		Body:        models.MetricTemplateApplyForm{},
+		Data:        []models.MetricTemplateSyncResult{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If a container type is not the template container type.",
+			"404 If template or a container not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /metric-templates/:templateId/containers/:containerId": {
+		Tag:     "Metric templates",
+		Summary: "Unlinks a container from a metric template. The container metrics are kept.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If container is not linked.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /metric-templates/:templateId/drift": {
+		Tag:         "Metric templates",
+		Summary:     "Gets the drift report of a metric template.",
+		Description: "Lists the metrics edited on the linked containers since the last sync, with the edited fields, and the template metrics missing on the containers.",
+		Data:        []models.MetricTemplateDrift{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /data-policies/": {
+		Tag:     "Data policies",
+		Summary: "Get all data policies.",
+		Data:    []models.DataPolicy{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /data-policies/:dpId": {
+		Tag:     "Data policies",
+		Summary: "Get a data policy.",
+		Data:    models.DataPolicy{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /data-policies/": {
+		Tag:     "Data policies",
+		Summary: "Creates a new data policy.",
+		Body:    models.DataPolicy{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If tiers retentions or intervals are not increasing, or an interval is not shorter than the previous retention.",
+			"400 If exceeds the maximum number of data policies.",
+			"400 If an aggregation function is not supported by the storage backend.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /data-policies/:dpId": {
+		Tag:     "Data policies",
+		Summary: "Update a data policy.",
+		Body:    models.DataPolicy{},
+		Responses: []string{
+			"400 If invalid id.",
+			"400 If invalid body.",
+			"400 If invalid body fields.",
+			"400 If tiers retentions or intervals are not increasing, or an interval is not shorter than the previous retention.",
+			"400 If an aggregation function is not supported by the storage backend.",
+			"404 If data policy not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /data-policies/:dpId": {
+		Tag:     "Data policies",
+		Summary: "Deletes a data policy.",
+		Responses: []string{
+			"400 If invalid id.",
+			"404 If data policy not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /config/export": {
+		Tag:         "Configuration",
+		Summary:     "Exports the configuration document.",
+		Description: "Exports the configuration document, with the data policies, alarm categories, alarm expressions, alarm profiles, containers, metrics and contexts. The entities are referenced by name, or by ident for the teams, contexts and contextual metrics, instead of ids.",
+		Params: []Param{
+			{Name: "format", Descr: "Document format, \"json\" or \"yaml\". Default is \"json\"."},
+		},
+		Data:     config.Document{},
+		Plain:    true,
+		Produces: []string{"application/yaml"},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /config/import": {
+		Tag:         "Configuration",
+		Summary:     "Imports a configuration document.",
+		Description: "Imports a configuration document, creating the missing entities and updating the changed ones. Entities missing on the document are kept. The changes are applied in a single transaction.",
+		Params: []Param{
+			{Name: "format", Descr: "Document format, \"json\" or \"yaml\". Default is \"json\"."},
+			{Name: "dry-run", Descr: "If true, only returns the changes. Default is false."},
+		},
+		Body:     config.Document{},
+		Consumes: []string{"application/yaml"},
+		Data:     config.Plan{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If exceeds the maximum number of data policies.",
+			"400 If an aggregation function is not supported by the storage backend.",
+			"400 If the document is invalid, with the errors found.",
+			"200 If succeeded, with the changes.",
+		},
+	},
+	"GET /containers/basics/": {
+		Tag:     "Containers",
+		Summary: "Get basic containers.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "createdAtStart"},
+			{Name: "createdAtStop"},
+			{Name: "enabled"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "team-id", Descr: "Team id, required if the user is not an admin."},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Container[struct{}]{},
+		Responses: []string{
+			"403 If can't manage the team containers.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/basics/:containerId": {
+		Tag:     "Containers",
+		Summary: "Get a basic container.",
+		Data:    models.Container[struct{}]{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/basics/": {
+		Tag:     "Containers",
+		Summary: "Creates a basic container.",
+		Body:    models.Container[struct{}]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/basics/:containerId": {
+		Tag:     "Containers",
+		Summary: "Updates a basic container.",
+		Body:    models.Container[struct{}]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If not found.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/basics/:containerId": {
+		Tag:     "Containers",
+		Summary: "Delete a container and dependencies.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/basics/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Get multi Flex Legacy metrics.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "enabled"},
+			{Name: "data-policy-id"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Metric[struct{}]{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/basics/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Get a basic metric.",
+		Data:    models.Metric[struct{}]{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/basics/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Creates a basic metric .",
+		Body:    models.Metric[struct{}]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/basics/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Updates a basic metric.",
+		Body:    models.Metric[struct{}]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container or metric not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/basics/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Delete a metric.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/basics/:containerId/metrics/:metricId/refkeys/": {
+		Tag:     "Reference keys",
+		Summary: "Get all references key of a metric.",
+		Data:    []models.MetricRefkey{},
+		Responses: []string{
+			"404 If metric not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/basics/:containerId/metrics/:metricId/refkeys/": {
+		Tag:     "Reference keys",
+		Summary: "Creates a metric reference key.",
+		Body:    models.MetricRefkey{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If metric not found.",
+			"400 If refkey already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/basics/:containerId/metrics/:metricId/refkeys/:refkeyId": {
+		Tag:     "Reference keys",
+		Summary: "Updates a metric reference key.",
+		Body:    models.MetricRefkey{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If metric not found.",
+			"400 If refkey already exists.",
+			"404 If refkey not found on the metric.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/basics/:containerId/metrics/:metricId/refkeys/:refkeyId": {
+		Tag:     "Reference keys",
+		Summary: "Deletes a metric reference key.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If refkey not found on the metric.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/snmpv2c/": {
+		Tag:     "Containers",
+		Summary: "Get SNMPv2c containers.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "createdAtStart"},
+			{Name: "createdAtStop"},
+			{Name: "enabled"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "team-id", Descr: "Team id, required if the user is not an admin."},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+			{Name: "target"},
+		},
+		Data: []models.Container[models.SNMPv2cContainer]{},
+		Responses: []string{
+			"403 If can't manage the team containers.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/snmpv2c/:containerId": {
+		Tag:     "Containers",
+		Summary: "Get a SNMP container.",
+		Data:    models.Container[models.SNMPv2cContainer]{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/snmpv2c/": {
+		Tag:     "Containers",
+		Summary: "Creates a SNMP container.",
+		Body:    models.Container[models.SNMPv2cContainer]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If target:port is in use.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/snmpv2c/:containerId": {
+		Tag:     "Containers",
+		Summary: "Updates a SNMP container.",
+		Body:    models.Container[models.SNMPv2cContainer]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If target:port is in use.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/snmpv2c/:containerId": {
+		Tag:     "Containers",
+		Summary: "Delete a container and dependencies.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/snmpv2c/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Get multi SNMPv2c metrics.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "enabled"},
+			{Name: "data-policy-id"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Metric[models.SNMPMetric]{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/snmpv2c/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Get a SNMPv2c metric.",
+		Data:    models.Metric[models.SNMPMetric]{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/snmpv2c/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Creates a SNMPv2c metric .",
+		Body:    models.Metric[models.SNMPMetric]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/snmpv2c/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Updates a SNMPv2c metric.",
+		Body:    models.Metric[models.SNMPMetric]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container or metric not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/snmpv2c/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Delete a metric.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/flex-legacy/": {
+		Tag:     "Containers",
+		Summary: "Get flex legacy containers.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "createdAtStart"},
+			{Name: "createdAtStop"},
+			{Name: "model"},
+			{Name: "enabled"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "team-id", Descr: "Team id, required if the user is not an admin."},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+			{Name: "target"},
+			{Name: "serial-number"},
+			{Name: "city"},
+			{Name: "region"},
+			{Name: "country"},
+		},
+		Data: []models.Container[models.FlexLegacyContainer]{},
+		Responses: []string{
+			"403 If can't manage the team containers.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/flex-legacy/:containerId": {
+		Tag:     "Containers",
+		Summary: "Get a Flex Legacy container.",
+		Data:    models.Container[models.FlexLegacyContainer]{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/flex-legacy/": {
+		Tag:     "Containers",
+		Summary: "Creates a Flex Legacy container.",
+		Body:    models.Container[models.FlexLegacyContainer]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If serial-number or target:port is in use.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/flex-legacy/:containerId": {
+		Tag:     "Containers",
+		Summary: "Updates a Flex Legacy container.",
+		Body:    models.Container[models.FlexLegacyContainer]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If serial-number or target:port is in use.",
+			"404 If container not found.",
+			"403 If can't manage the container team.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/flex-legacy/:containerId": {
+		Tag:     "Containers",
+		Summary: "Delete a container and dependencies.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/flex-legacy/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Get multi Basic metrics.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "enabled"},
+			{Name: "port"},
+			{Name: "port-type"},
+			{Name: "data-policy-id"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "labels", Descr: "Label selector, as \"site=POA,role in (core,edge)\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Metric[models.FlexLegacyMetric]{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/flex-legacy/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Get a Flex Legacy metric.",
+		Data:    models.Metric[models.FlexLegacyMetric]{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /containers/flex-legacy/:containerId/metrics/": {
+		Tag:     "Metrics",
+		Summary: "Creates a Flex Legacy metric .",
+		Body:    models.Metric[models.FlexLegacyMetric]{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /containers/flex-legacy/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Updates a Flex Legacy metric.",
+		Body:    models.Metric[models.FlexLegacyMetric]{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If container or metric not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /containers/flex-legacy/:containerId/metrics/:metricId": {
+		Tag:     "Metrics",
+		Summary: "Delete a metric.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /sites/": {
+		Tag:     "Sites",
+		Summary: "Get multi sites.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of sites returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "parent-id", Descr: "Filter by parent site id."},
+			{Name: "kind", Descr: "Filter by kind, \"region\", \"site\" or \"rack\"."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.Site{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /sites/geojson": {
+		Tag:     "Sites",
+		Summary: "Get the sites map as GeoJSON.",
+		Description: "Returns a GeoJSON FeatureCollection with a Point feature for each site with coordinates. The feature properties are the site \"id\", \"parent-id\", \"kind\", \"name\" and \"descr\", the \"alarm-state\", which is the worst current alarm state of the metrics of the site containers and of its child sites, the \"alarmed-metrics\", the \"recognized-metrics\" and the \"containers\" count. " +
+			"Only the containers of the user teams are summarized, unless the user is a teams manager or above.",
+		Produces: []string{"application/geo+json"},
+		Params: []Param{
+			{Name: "kind", Descr: "Filter by kind, \"region\", \"site\" or \"rack\"."},
+		},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /sites/:siteId": {
+		Tag:     "Sites",
+		Summary: "Get a site.",
+		Data:    models.Site{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /sites/": {
+		Tag:         "Sites",
+		Summary:     "Creates a new site.",
+		Description: "Regions can be inside regions, sites inside regions and racks inside sites. A \"parent-id\" of 0 means no parent.",
+		Body:        models.Site{},
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If the site can't be inside the parent.",
+			"404 If parent site not found.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /sites/:siteId": {
+		Tag:     "Sites",
+		Summary: "Updates a site.",
+		Body:    models.Site{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If the site can't be inside the parent.",
+			"400 If the kind changed and the site has child sites.",
+			"404 If site or parent site not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /sites/:siteId": {
+		Tag:     "Sites",
+		Summary: "Deletes a site. The site containers are detached.",
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If the site has child sites.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /sites/:siteId/containers": {
+		Tag:         "Sites",
+		Summary:     "Get the containers of a site, without the containers of its child sites.",
+		Description: "Only the containers of the user teams are returned, unless the user is a teams manager or above.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.SiteContainer{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /sites/:siteId/containers/:containerId": {
+		Tag:     "Sites",
+		Summary: "Attaches a container to a site, moving it from its current site.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If site or container not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /sites/:siteId/containers/:containerId": {
+		Tag:     "Sites",
+		Summary: "Detaches a container from a site.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If container is not on the site.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /custom-queries/": {
+		Tag:     "Custom queries",
+		Summary: "Get multi custom queries.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of metrics returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "ident"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.CustomQuery{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /custom-queries/:cqId": {
+		Tag:     "Custom queries",
+		Summary: "GetHandler a custom query.",
+		Data:    models.CustomQuery{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /custom-queries/": {
+		Tag:     "Custom queries",
+		Summary: "Creates a new custom query.",
+		Body:    models.CustomQuery{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If flux or params are invalid.",
+			"400 If flux uses a function that is not allowed.",
+			"400 If flux does not compile.",
+			"400 If custom queries are not supported by the storage backend.",
+			"400 If ident is already in use.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /custom-queries/:cqId": {
+		Tag:     "Custom queries",
+		Summary: "Updates a custom query.",
+		Body:    models.CustomQuery{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If flux or params are invalid.",
+			"400 If flux uses a function that is not allowed.",
+			"400 If flux does not compile.",
+			"400 If custom queries are not supported by the storage backend.",
+			"404 If custom query does not exists.",
+			"400 If ident is already in use.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /custom-queries/:cqId": {
+		Tag:     "Custom queries",
+		Summary: "DeleteHandler a custom query.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/profiles/": {
+		Tag:     "Alarm profiles",
+		Summary: "Get multi alarm profiles.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.AlarmProfile{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/profiles/:profileId": {
+		Tag:     "Alarm profiles",
+		Summary: "Get a alarm profile.",
+		Data:    models.AlarmProfile{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/profiles/": {
+		Tag:         "Alarm profiles",
+		Summary:     "Crates a alarm profile.",
+		Description: "The alarm profile is notified of the alarms of its categories. If the label \"selector\" is set, only of the alarmed metrics which container labels merged with the metric labels match it.",
+		Body:        models.AlarmProfile{},
+		Data:        models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /alarm/profiles/:profileId": {
+		Tag:     "Alarm profiles",
+		Summary: "Updates a alarm profile.",
+		Body:    models.AlarmProfile{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/profiles/:profileId": {
+		Tag:     "Alarm profiles",
+		Summary: "Deletes a alarm profile.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/profiles/:profileId/emails/": {
+		Tag:     "Alarm profiles",
+		Summary: "Get alarm profile emails.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data:  []models.AlarmProfileEmailWithoutProfileId{},
+		Plain: true,
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/profiles/:profileId/emails/": {
+		Tag:     "Alarm profiles",
+		Summary: "Add an email to alarm profile.",
+		Body:    models.AlarmProfileEmail{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If alarm profile not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/profiles/:profileId/emails/:emailId": {
+		Tag:     "Alarm profiles",
+		Summary: "Remove an email from alarm profile.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If relation not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/profiles/:profileId/categories/": {
+		Tag:     "Alarm profiles",
+		Summary: "Get alarm categories.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+		},
+		Data: []models.AlarmCategory{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/profiles/:profileId/categories/": {
+		Tag:     "Alarm profiles",
+		Summary: "Add an alarm category to alarm profile.",
+		Body:    models.Id32{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If alarm profile not found.",
+			"404 If alarm category not found.",
+			"400 If relation alredy exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/profiles/:profileId/categories/:categoryId": {
+		Tag:     "Alarm profiles",
+		Summary: "Remove an alarm category from alarm profile.",
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/profiles/:profileId/endpoints/": {
+		Tag:     "Alarm profiles",
+		Summary: "Get alarm profile's alarm endpoints.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "name"},
+			{Name: "url"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.AlarmEndpoint{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/profiles/:profileId/endpoints/": {
+		Tag:     "Alarm profiles",
+		Summary: "Creates a alarm endpoint relation.",
+		Body:    models.Id32{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If alarm profile not found.",
+			"404 If alarm endpoint not found.",
+			"400 If relation already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/profiles/:profileId/endpoints/:endpointId": {
+		Tag:     "Alarm profiles",
+		Summary: "Deletes a alarm endpoint relation.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/categories/": {
+		Tag:     "Alarm categories",
+		Summary: "Get alarm categories.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "level"},
+			{Name: "name"},
+			{Name: "descr"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.AlarmCategory{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/categories/:categoryId": {
+		Tag:     "Alarm categories",
+		Summary: "Get alarm category.",
+		Data:    models.AlarmCategory{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/categories/": {
+		Tag:     "Alarm categories",
+		Summary: "Create an alarm category.",
+		Body:    models.AlarmCategory{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If category level already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /alarm/categories/:categoryId": {
+		Tag:     "Alarm categories",
+		Summary: "Update an alarm category.",
+		Body:    models.AlarmCategory{},
+		Responses: []string{
+			"400 If invalid param.",
+			"400 If invalid body.",
+			"404 If not found.",
+			"400 If category level already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/categories/:categoryId": {
+		Tag:     "Alarm categories",
+		Summary: "Delete an alarm category.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/expressions/": {
+		Tag:     "Alarm expressions",
+		Summary: "Get alarm expressions.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "categoryId"},
+			{Name: "name"},
+			{Name: "expression"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.AlarmExpression{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/expressions/": {
+		Tag:     "Alarm expressions",
+		Summary: "Crates a alarm expression.",
+		Body:    models.AlarmExpression{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If alarm expression already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /alarm/expressions/:expressionId": {
+		Tag:     "Alarm expressions",
+		Summary: "Updates a alarm expression.",
+		Body:    models.AlarmExpression{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/expressions/:expressionId": {
+		Tag:     "Alarm expressions",
+		Summary: "Deletes a alarm expression.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/expressions/:expressionId/metrics": {
+		Tag:     "Alarm expressions",
+		Summary: "Creates a metric relation with alarm expression.",
+		Body:    models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If alarm expression not found.",
+			"404 If metric not found.",
+			"400 If relation already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/expressions/:expressionId/metrics/:metricId": {
+		Tag:     "Alarm expressions",
+		Summary: "Remove a metric relation with alarm expression.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If relation already not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/endpoints/": {
+		Tag:     "Alarm endpoints",
+		Summary: "Gets a notification endpoint.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+			{Name: "name"},
+			{Name: "url"},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.AlarmEndpoint{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/endpoints/:endpointId": {
+		Tag:     "Alarm endpoints",
+		Summary: "Gets a notification endpoint.",
+		Data:    models.AlarmEndpoint{},
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/endpoints/": {
+		Tag:     "Alarm endpoints",
+		Summary: "Creates a new notification endpoint.",
+		Body:    models.AlarmEndpoint{},
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /alarm/endpoints/:endpointId": {
+		Tag:     "Alarm endpoints",
+		Summary: "Updates a notification endpoint.",
+		Body:    models.AlarmEndpoint{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/endpoints/:endpointId": {
+		Tag:     "Alarm endpoints",
+		Summary: "Deletes a notification endpoint.",
+		Data:    models.Id64{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /alarm/trap-relations/": {
+		Tag:     "Alarm categories",
+		Summary: "Get all trap categories relations.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of containers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+		},
+		Data: []models.TrapCategoryRelation{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /alarm/trap-relations/": {
+		Tag:     "Alarm categories",
+		Summary: "Creates an trap category relation.",
+		Body:    models.TrapCategoryRelation{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If alarm category not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /alarm/trap-relations/:trapId": {
+		Tag:     "Alarm categories",
+		Summary: "Deletes a trap category relation.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If relation not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /request-count/whitelist/members/": {
+		Tag:     "Requests count whitelist",
+		Summary: "Get the users ids of the requests count whitelist.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of results."},
+			{Name: "offset", Descr: "Offset for searching."},
+		},
+		Data: []int32{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /request-count/whitelist/members/": {
+		Tag:     "Requests count whitelist",
+		Summary: "Adds a user to the requests count whitelist.",
+		Body:    models.Id32{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"404 If user not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /request-count/whitelist/members/:userId": {
+		Tag:     "Requests count whitelist",
+		Summary: "Removes a user from the requests count whitelist.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If user is not in the whitelist.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /audit/": {
+		Tag:         "Audit",
+		Summary:     "Get multiple audit entries.",
+		Description: "Each authenticated POST, PATCH and DELETE request, except the metrics data writes, is saved on the audit log with the entity before and after the request.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of entries returned. Default is 30, max is 100, min is 1."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "user-id", Descr: "User id."},
+			{Name: "api-key-id", Descr: "API Key id."},
+			{Name: "method", Descr: "Request method."},
+			{Name: "route", Descr: "Route prefix, like \"/users\"."},
+			{Name: "entity", Descr: "Entity path prefix, like \"/users/1\"."},
+			{Name: "status", Descr: "Response status code."},
+			{Name: "ip", Descr: "Client ip."},
+			{Name: "from", Descr: "Minimum creation date in unix seconds."},
+			{Name: "to", Descr: "Maximum creation date in unix seconds."},
+			{Name: "order-by", Descr: "Column to order by. Default is \"id\"."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\". Default is \"desc\"."},
+		},
+		Data: []models.AuditEntry{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /oidc-providers/": {
+		Tag:     "OpenID Connect providers",
+		Summary: "Gets OpenID Connect providers.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of providers returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "ident"},
+			{Name: "name"},
+			{Name: "issuer"},
+			{Name: "enabled", Descr: "Filter by enabled state, \"1\" or \"0\"."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.OIDCProvider{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /oidc-providers/:providerId": {
+		Tag:     "OpenID Connect providers",
+		Summary: "Gets an OpenID Connect provider.",
+		Data:    models.OIDCProvider{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /oidc-providers/": {
+		Tag:     "OpenID Connect providers",
+		Summary: "Creates an OpenID Connect provider.",
+		Body:    models.OIDCProvider{},
+		Data:    models.Id32{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If ident already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /oidc-providers/:providerId": {
+		Tag:     "OpenID Connect providers",
+		Summary: "Updates an OpenID Connect provider.",
+		Body:    models.OIDCProvider{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If ident already exists.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /oidc-providers/:providerId": {
+		Tag:         "OpenID Connect providers",
+		Summary:     "Deletes an OpenID Connect provider.",
+		Description: "The provider users are kept, but can't login until a provider with the same ident is created.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /rate-limits/": {
+		Tag:     "Rate limits",
+		Summary: "Gets rate limits.",
+		Params: []Param{
+			{Name: "limit", Descr: "Limit of rate limits returned. Default is 30, max is 30, min is 0."},
+			{Name: "offset", Descr: "Offset for searching. Default is 0, min is 0."},
+			{Name: "group", Descr: "Filter by route group."},
+			{Name: "user-id", Descr: "Filter by user id."},
+			{Name: "apikey-id", Descr: "Filter by API Key id."},
+			{Name: "order-by", Descr: "Column to order by."},
+			{Name: "order-by-fn", Descr: "Order function, \"asc\" or \"desc\"."},
+		},
+		Data: []models.RateLimit{},
+		Responses: []string{
+			"400 If invalid params.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /rate-limits/:rateLimitId": {
+		Tag:     "Rate limits",
+		Summary: "Gets a rate limit.",
+		Data:    models.RateLimit{},
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"POST /rate-limits/": {
+		Tag:         "Rate limits",
+		Summary:     "Creates a rate limit.",
+		Description: "Creates a rate limit of a route group, for all clients, a user or an API Key. The most specific rate limit of the client is used, falling back to the default rate limits of the environment. The changes are applied on the cached clients policies in up to one minute.",
+		Body:        models.RateLimit{},
+		Data:        models.Id32{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If rate limit already exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /rate-limits/:rateLimitId": {
+		Tag:     "Rate limits",
+		Summary: "Updates a rate limit.",
+		Body:    models.RateLimit{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If rate limit already exists.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /rate-limits/:rateLimitId": {
+		Tag:         "Rate limits",
+		Summary:     "Deletes a rate limit.",
+		Description: "The clients fall back to the less specific rate limit.",
+		Responses: []string{
+			"400 If invalid params.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /trap-listeners/": {
+		Tag:     "Trap listeners",
+		Summary: "Get all trap listeners.",
+		Data:    []models.TrapListener{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"POST /trap-listeners/": {
+		Tag:     "Trap listeners",
+		Summary: "Creates a trap listener.",
+		Body:    models.TrapListener{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If host and port exists.",
+			"200 If succeeded.",
+		},
+	},
+	"PATCH /trap-listeners/:listenerId": {
+		Tag:     "Trap listeners",
+		Summary: "Updates a trap listener.",
+		Body:    models.TrapListener{},
+		Responses: []string{
+			"400 If invalid body.",
+			"400 If json fields are invalid.",
+			"400 If host and port exists.",
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"DELETE /trap-listeners/:listenerId": {
+		Tag:     "Trap listeners",
+		Summary: "Deletes a trap listener.",
+		Responses: []string{
+			"404 If not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/basics/:containerId/metrics/:metricId/alarm-expressions": {
+		Tag:     "Metrics",
+		Summary: "Get metric's alarm expressions.",
+		Data:    []models.AlarmExpressionSimplified{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/snmpv2c/:containerId/metrics/:metricId/alarm-expressions": {
+		Tag:     "Metrics",
+		Summary: "Get metric's alarm expressions.",
+		Data:    []models.AlarmExpressionSimplified{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /containers/flex-legacy/:containerId/metrics/:metricId/alarm-expressions": {
+		Tag:     "Metrics",
+		Summary: "Get metric's alarm expressions.",
+		Data:    []models.AlarmExpressionSimplified{},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data": {
+		Tag:         "Contextual metrics",
+		Summary:     "Return the current metric's value.",
+		Description: sharedMetricDataDescr,
+		Responses: []string{
+			"503 If data is not available.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/metrics/:ctxMetricId/data/history": {
+		Tag:         "Contextual metrics",
+		Summary:     "Queries the metric history.",
+		Description: sharedMetricDataDescr,
+		Params: []Param{
+			{Name: "start", Descr: "Range start in unix seconds."},
+			{Name: "stop", Descr: "Range stop in unix seconds. Default is now."},
+			{Name: "aggregate", Descr: "Aggregation function to read from the aggregated tiers. Default is the data policy primary function."},
+			{Name: "step", Descr: "Window duration in seconds. The series is aggregated into evenly spaced windows. Default is no windowing."},
+			{Name: "max-points", Descr: "Maximum number of points. A bigger step is used if needed."},
+			{Name: "fill", Descr: "Fill method of the empty windows, \"none\", \"null\", \"previous\" or \"linear\". Default is \"none\". Requires \"step\" or \"max-points\"."},
+			{Name: "custom_query", Descr: "Custom query id or ident."},
+			{Name: "params[<name>]", Descr: "Custom query param value. Default is the param default."},
+		},
+		Data: [][]any{},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If aggregate is not stored by the data policy.",
+			"400 If the custom query flux is no longer valid.",
+			"400 If invalid custom query params.",
+			"400 If the storage backend does not support custom queries.",
+			"404 If custom query not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /teams/:teamId/ctx/:ctxId/data/export": {
+		Tag:         "Contextual metrics",
+		Summary:     "Exports the history of one or many contextual metrics of a context.",
+		Description: "Exports the history of one or many contextual metrics of a context. The data is streamed, one contextual metric after another, with the metric and context metadata on each row.",
+		Params: []Param{
+			{Name: "start", Descr: "Range start in unix seconds."},
+			{Name: "stop", Descr: "Range stop in unix seconds. Default is now."},
+			{Name: "format", Descr: "Export format, \"csv\", \"ndjson\" or \"parquet\". Default is \"csv\"."},
+			{Name: "metrics", Descr: "Comma separated contextual metrics ids. Default is all contextual metrics of the context, max is 100."},
+			{Name: "aggregate", Descr: "Aggregation function to read from the aggregated tiers. Default is the data policy primary function."},
+		},
+		Plain:    true,
+		Produces: []string{"text/csv", "application/x-ndjson", "application/vnd.apache.parquet"},
+		Responses: []string{
+			"400 If invalid params.",
+			"400 If aggregate is not stored by a metric data policy.",
+			"404 If a contextual metric is not found.",
+			"200 If succeeded.",
+		},
+	},
+	"GET /openapi.json": {
+		Tag:     "Documentation",
+		Summary: "Get the OpenAPI document of the api.",
+		Public:  true,
+		Data:    map[string]any{},
+		Plain:   true,
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+	"GET /docs": {
+		Tag:      "Documentation",
+		Summary:  "Get the api documentation page, which reads the OpenAPI document.",
+		Public:   true,
+		Plain:    true,
+		Produces: []string{"text/html"},
+		Responses: []string{
+			"200 If succeeded.",
+		},
+	},
+}
@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// schemaBuilder builds the schemas of the go types, saving the structs
+// schemas on the components.
+type schemaBuilder struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder(components map[string]*Schema) *schemaBuilder {
+	return &schemaBuilder{
+		components: components,
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema of the value type.
+func (b *schemaBuilder) schemaOf(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return b.schema(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := b.schema(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: b.schema(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			b.addFields(s, t)
+			return s
+		}
+		return b.structRef(t)
+	}
+	return &Schema{}
+}
+
+// structRef saves the struct schema on the components if not saved yet and
+// returns a reference to it.
+func (b *schemaBuilder) structRef(t reflect.Type) *Schema {
+	name, ok := b.names[t]
+	if !ok {
+		name = schemaName(t)
+		for i := 2; b.components[name] != nil; i++ {
+			name = schemaName(t) + strconv.Itoa(i)
+		}
+		b.names[t] = name
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		b.components[name] = s
+		b.addFields(s, t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// addFields adds the struct exported fields to the schema, following
+// the json and validate tags.
+func (b *schemaBuilder) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if f.Anonymous && f.Type.Kind() == reflect.Struct {
+				b.addFields(s, f.Type)
+				continue
+			}
+			name = f.Name
+		}
+
+		var required bool
+		fs := b.schema(f.Type)
+		if fs.Ref == "" {
+			required = applyValidate(fs, f.Tag.Get("validate"))
+		} else {
+			required = hasRule(f.Tag.Get("validate"), "required")
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// applyValidate applies the validate tag rules on the schema. Returns true
+// if the field is required.
+func applyValidate(s *Schema, tag string) (required bool) {
+	target := s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "dive":
+			if target.Items == nil && target.AdditionalProperties == nil {
+				return required
+			}
+			if target.Items != nil {
+				target = target.Items
+			} else {
+				target = target.AdditionalProperties
+			}
+			if target.Ref != "" {
+				return required
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			setLimit(target, name == "min", n)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, v)
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		}
+	}
+	return required
+}
+
+// setLimit sets the min or max limit of the schema, which meaning depends on the
+// schema type.
+func setLimit(s *Schema, min bool, n float64) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if min {
+			s.MinLength = &i
+		} else {
+			s.MaxLength = &i
+		}
+	case "array":
+		if min {
+			s.MinItems = &i
+		} else {
+			s.MaxItems = &i
+		}
+	default:
+		if min {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+// hasRule returns true if the validate tag has the rule before any dive.
+func hasRule(tag string, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+var schemaNameReplacer = regexp.MustCompile(`[\w./-]*/|[^\w]+`)
+
+// schemaName returns the struct schema name, without packages paths, like
+// "Container_SNMPv2cContainer" for the "models.Container[models.SNMPv2cContainer]".
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	name = schemaNameReplacer.ReplaceAllStringFunc(name, func(s string) string {
+		if strings.HasSuffix(s, "/") {
+			return ""
+		}
+		return "_"
+	})
+	name = strings.Trim(strings.ReplaceAll(name, "models_", ""), "_")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a rate limit. The changes are applied on the cached clients
+// policies in up to one minute.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If rate limit already exists.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var limit models.RateLimit
+		err := c.ShouldBind(&limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		limit.Id = -1
+
+		exists, err := api.PG.RateLimitExists(ctx, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if rate limit exists", logger.ErrField(err))
+			return
+		}
+		if exists {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgRateLimitExists))
+			return
+		}
+
+		id, err := api.PG.CreateRateLimit(ctx, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create rate limit", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Rate limit created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
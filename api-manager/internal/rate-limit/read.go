@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Gets a rate limit.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func GetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		id, err := strconv.ParseInt(c.Param("rateLimitId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, limit, err := api.PG.GetRateLimit(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get rate limit", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgRateLimitNotFound))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(limit))
+	}
+}
+
+// Gets rate limits.
+// Params:
+//   - "limit" Limit of rate limits returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//   - "group" Filter by route group.
+//   - "user-id" Filter by user id.
+//   - "apikey-id" Filter by API Key id.
+//   - "order-by" Column to order by.
+//   - "order-by-fn" Order function, "asc" or "desc".
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		userId, err := tools.IntMinQuery(c, "user-id", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		apikeyId, err := tools.IntMinQuery(c, "apikey-id", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		limits, err := api.PG.GetRateLimits(ctx, pg.RateLimitQueryFilters{
+			Group:     c.Query("group"),
+			UserId:    int32(userId),
+			APIKeyId:  int32(apikeyId),
+			OrderBy:   c.Query("order-by"),
+			OrderByFn: c.Query("order-by-fn"),
+			Limit:     limit,
+			Offset:    offset,
+		})
+		if err != nil {
+			if err == pg.ErrInvalidOrderByColumn || err == pg.ErrInvalidFilterValue || err == pg.ErrInvalidOrderByFn {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get rate limits", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(limits))
+	}
+}
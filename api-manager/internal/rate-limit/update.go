@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Updates a rate limit.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If rate limit already exists.
+//   - 404 If not found.
+//   - 200 If succeeded.
+func UpdateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("rateLimitId")
+		id, err := strconv.ParseInt(rawId, 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var limit models.RateLimit
+		err = c.ShouldBind(&limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		limit.Id = int32(id)
+
+		exists, err := api.PG.RateLimitExists(ctx, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if rate limit exists", logger.ErrField(err))
+			return
+		}
+		if exists {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgRateLimitExists))
+			return
+		}
+
+		exists, err = api.PG.UpdateRateLimit(ctx, limit)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update rate limit", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgRateLimitNotFound))
+			return
+		}
+		api.Log.Info("Rate limit updated, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -12,12 +12,19 @@ import (
 
 // Deletes a metric reference key.
 // Responses:
-//   - 404 If metric not found.
+//   - 400 If invalid params.
+//   - 404 If refkey not found on the metric.
 //   - 200 If succeeded.
 func DeleteHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 
+		metricId, err := strconv.ParseInt(c.Param("metricId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
 		rawId := c.Param("refkeyId")
 		id, err := strconv.ParseInt(rawId, 10, 32)
 		if err != nil {
@@ -25,7 +32,7 @@ func DeleteHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		exists, err := api.PG.DeleteMetricRefkey(ctx, id)
+		exists, err := api.PG.DeleteMetricRefkey(ctx, metricId, id)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
@@ -18,6 +18,7 @@ import (
 //   - 400 If json fields are invalid.
 //   - 404 If metric not found.
 //   - 400 If refkey already exists.
+//   - 404 If refkey not found on the metric.
 //   - 200 If succeeded.
 func UpdateHandler(api *api.API, containerType types.ContainerType) func(c *gin.Context) {
 	return func(c *gin.Context) {
@@ -0,0 +1,19 @@
+package roles
+
+// TeamRole is the role of a member in a team, or in one of the team contexts.
+type TeamRole = uint8
+
+const (
+	TeamUnknown TeamRole = iota
+	// TeamViewer reads the team contexts and its metrics.
+	TeamViewer
+	// TeamOperator also recognizes and resolves the contexts alarms.
+	TeamOperator
+	// TeamAdmin also manages the team contexts, containers and metrics.
+	TeamAdmin
+	TeamInvalid
+)
+
+func ValidateTeamRole(role TeamRole) bool {
+	return role > TeamUnknown && role < TeamInvalid
+}
@@ -9,17 +9,27 @@ import (
 	alarmexp "github.com/fernandotsda/nemesys/api-manager/internal/alarm-expression"
 	profile "github.com/fernandotsda/nemesys/api-manager/internal/alarm-profile"
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/audit"
+	billingreport "github.com/fernandotsda/nemesys/api-manager/internal/billing-report"
+	"github.com/fernandotsda/nemesys/api-manager/internal/config"
 	"github.com/fernandotsda/nemesys/api-manager/internal/container"
 	ctxmetric "github.com/fernandotsda/nemesys/api-manager/internal/contextual-metric"
 	"github.com/fernandotsda/nemesys/api-manager/internal/cost"
 	whitelist "github.com/fernandotsda/nemesys/api-manager/internal/counter-whitelist"
 	customquery "github.com/fernandotsda/nemesys/api-manager/internal/custom-query"
+	"github.com/fernandotsda/nemesys/api-manager/internal/dashboard"
 	datapolicy "github.com/fernandotsda/nemesys/api-manager/internal/data-policy"
 	"github.com/fernandotsda/nemesys/api-manager/internal/metric"
 	metricdata "github.com/fernandotsda/nemesys/api-manager/internal/metric-data"
+	metrictemplate "github.com/fernandotsda/nemesys/api-manager/internal/metric-template"
 	"github.com/fernandotsda/nemesys/api-manager/internal/middleware"
+	"github.com/fernandotsda/nemesys/api-manager/internal/oidc-provider"
+	"github.com/fernandotsda/nemesys/api-manager/internal/openapi"
+	ratelimit "github.com/fernandotsda/nemesys/api-manager/internal/rate-limit"
 	"github.com/fernandotsda/nemesys/api-manager/internal/refkey"
 	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	scheduledreport "github.com/fernandotsda/nemesys/api-manager/internal/scheduled-report"
+	"github.com/fernandotsda/nemesys/api-manager/internal/site"
 	"github.com/fernandotsda/nemesys/api-manager/internal/status"
 	"github.com/fernandotsda/nemesys/api-manager/internal/team"
 	"github.com/fernandotsda/nemesys/api-manager/internal/trap"
@@ -28,6 +38,7 @@ import (
 	"github.com/gin-contrib/cors"
 
 	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/service"
 	"github.com/fernandotsda/nemesys/shared/types"
 	"github.com/gin-gonic/gin"
@@ -43,6 +54,13 @@ func Set(s service.Service) {
 	router := gin.New()
 	api.Router = router
 
+	// the client ip is used by the API Keys allowed ips, so the forwarded
+	// headers are only read from trusted proxies
+	err := router.SetTrustedProxies(trustedProxies(env.APIManagerTrustedProxies))
+	if err != nil {
+		api.Log.Fatal("Fail to set trusted proxies", logger.ErrField(err))
+	}
+
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     strings.Split(env.APIManagerAllowOrigins, ";"),
 		AllowMethods:     []string{"POST", "GET", "DELETE", "PATCH"},
@@ -51,14 +69,31 @@ func Set(s service.Service) {
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	router.Use(middleware.Audit(api))
 
 	r := router.Group(env.APIManagerRoutesPrefix)
 	r.POST("/login", middleware.Limiter(api, time.Second/2), uauth.LoginHandler(api))
+	r.POST("/login/totp", middleware.Limiter(api, time.Second/2), uauth.LoginTOTPHandler(api))
+	r.POST("/login/totp/enrollment", middleware.Limiter(api, time.Second/2), uauth.LoginTOTPEnrollmentHandler(api))
+	r.POST("/password-reset", middleware.Limiter(api, time.Second*5), uauth.RequestPasswordResetHandler(api))
+	r.POST("/password-reset/confirm", middleware.Limiter(api, time.Second/2), uauth.ResetPasswordHandler(api))
+	r.GET("/login/oidc", uauth.MGetOIDCProvidersHandler(api))
+	r.GET("/login/oidc/:ident", middleware.Limiter(api, time.Second/2), uauth.OIDCLoginHandler(api))
+	r.GET("/login/oidc/:ident/callback", middleware.Limiter(api, time.Second/2), uauth.OIDCCallbackHandler(api))
+	r.GET("/openapi.json", openapi.SpecHandler(api))
+	r.GET("/docs", openapi.DocsHandler(api))
 
 	viewer := r.Group("/", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api))
 	{
 		viewer.GET("/session", middleware.Protect(api, roles.Viewer), user.SessionInfoHandler(api))
 		viewer.POST("/logout", middleware.Protect(api, roles.Viewer), uauth.Logout(api))
+
+		viewer.POST("/session/password", middleware.Limiter(api, time.Second/2), uauth.ChangePasswordHandler(api))
+		viewer.GET("/session/totp", uauth.GetTOTPHandler(api))
+		viewer.POST("/session/totp", uauth.EnrollTOTPHandler(api))
+		viewer.POST("/session/totp/enable", middleware.Limiter(api, time.Second/2), uauth.EnableTOTPHandler(api))
+		viewer.POST("/session/totp/disable", middleware.Limiter(api, time.Second/2), uauth.DisableTOTPHandler(api))
+		viewer.POST("/session/totp/recovery-codes", middleware.Limiter(api, time.Second/2), uauth.RegenerateRecoveryCodesHandler(api))
 	}
 
 	adm := r.Group("/", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
@@ -68,19 +103,25 @@ func Set(s service.Service) {
 		adm.GET("/cost", cost.GetCostHandler(api))
 		adm.GET("/price-table", cost.GetPriceTableHandler(api))
 		adm.GET("/base-plan", cost.GetBasePlanHandler(api))
+		adm.GET("/auth-policy", uauth.GetPolicyHandler(api))
 
 		adm.POST("/metrics/data", metricdata.AddHandler(api))
+		adm.POST("/metrics/remote-write", metricdata.RemoteWriteHandler(api))
+		adm.POST("/metrics/data/import", metricdata.ImportHandler(api))
+		adm.GET("/metrics/data/import/:jobId", metricdata.GetImportHandler(api))
 	}
 
 	master := r.Group("/", middleware.Protect(api, roles.Master))
 	{
 		master.PATCH("/base-plan", cost.UpdateBasePlanHandler(api))
 		master.PATCH("/price-table", cost.UpdatePriceTableHandler(api))
+		master.PATCH("/auth-policy", uauth.UpdatePolicyHandler(api))
 	}
 
 	users := r.Group("/users")
 	{
 		users.POST("/:userId/logout", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), uauth.ForceLogout(api))
+		users.DELETE("/:userId/totp", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), uauth.ResetTOTPHandler(api))
 
 		users.GET("/", middleware.Protect(api, roles.TeamsManager), middleware.RequestsCounter(api), user.GetUsers(api))
 		users.GET("/:userId", middleware.ProtectUser(api, roles.Admin), middleware.RequestsCounter(api), user.GetHandler(api))
@@ -106,41 +147,88 @@ func Set(s service.Service) {
 
 		teamsManagement.GET("/:teamId/members", team.MGetMembersHandler(api))
 		teamsManagement.POST("/:teamId/members", team.AddMemberHandler(api))
+		teamsManagement.PATCH("/:teamId/members/:userId", team.UpdateMemberHandler(api))
 		teamsManagement.DELETE("/:teamId/members/:userId", team.RemoveMemberHandler(api))
 
-		ctx := teamsManagement.Group("/:teamId/ctx")
+		memberContexts := teamsManagement.Group("/:teamId/members/:userId/contexts")
 		{
-			ctx.POST("/", team.CreateContextHandler(api))
-			ctx.PATCH("/:ctxId", middleware.ParseContextParams(api), team.UpdateContextHandler(api))
-			ctx.DELETE("/:ctxId", middleware.ParseContextParams(api), team.DeleteContextHandler(api))
+			memberContexts.GET("/", team.MGetMemberContextsRolesHandler(api))
+			memberContexts.POST("/", team.SetMemberContextRoleHandler(api))
+			memberContexts.DELETE("/:ctxId", team.DeleteMemberContextRoleHandler(api))
 		}
 
-		ctxMetrics := ctx.Group("/:ctxId/metrics")
+		ctx := teamsManagement.Group("/:teamId/ctx")
+		billingReports := ctx.Group("/:ctxId/billing-reports")
 		{
-			ctxMetrics.POST("/", middleware.ParseContextParams(api), ctxmetric.CreateHandler(api))
-			ctxMetrics.PATCH("/:ctxMetricId", middleware.ParseContextualMetricParams(api), ctxmetric.UpdateHandler(api))
-			ctxMetrics.DELETE("/:ctxMetricId", middleware.ParseContextualMetricParams(api), ctxmetric.DeleteHandler(api))
+			billingReports.POST("/", middleware.ParseContextParams(api), middleware.DataHistoryRequestsCounter(api), billingreport.CreateHandler(api))
+			billingReports.DELETE("/:reportId", middleware.ParseContextParams(api), billingreport.DeleteHandler(api))
 		}
 	}
 
 	teams := r.Group("/teams", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api))
 	{
-		teams.GET("/:teamId", middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager), team.GetHandler(api))
+		teams.GET("/:teamId", middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer), team.GetHandler(api))
+
+		teamAdmin := middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamAdmin)
+		teamOperator := middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamOperator)
 
-		ctx := teams.Group("/:teamId/ctx", middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager))
+		ctx := teams.Group("/:teamId/ctx", middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer))
 		{
 			ctx.GET("/", team.MGetContextHandler(api))
 			ctx.GET("/:ctxId", middleware.ParseContextParams(api), team.MGetContextHandler(api))
+			ctx.POST("/", teamAdmin, team.CreateContextHandler(api))
+			ctx.PATCH("/:ctxId", teamAdmin, middleware.ParseContextParams(api), team.UpdateContextHandler(api))
+			ctx.DELETE("/:ctxId", teamAdmin, middleware.ParseContextParams(api), team.DeleteContextHandler(api))
 		}
 
 		ctxMetrics := ctx.Group("/:ctxId/metrics")
 		{
 			ctxMetrics.GET("/", middleware.ParseContextParams(api), ctxmetric.MGet(api))
 			ctxMetrics.GET("/:ctxMetricId", middleware.ParseContextualMetricParams(api), ctxmetric.Get(api))
+			ctxMetrics.POST("/", teamAdmin, middleware.ParseContextParams(api), ctxmetric.CreateHandler(api))
+			ctxMetrics.PATCH("/:ctxMetricId", teamAdmin, middleware.ParseContextualMetricParams(api), ctxmetric.UpdateHandler(api))
+			ctxMetrics.DELETE("/:ctxMetricId", teamAdmin, middleware.ParseContextualMetricParams(api), ctxmetric.DeleteHandler(api))
 			ctxMetrics.GET("/:ctxMetricId/alarm-history", middleware.ParseContextualMetricParams(api), middleware.MetricRequest(api), ctxmetric.AlarmHistoryHandler(api))
 			ctxMetrics.GET("/:ctxMetricId/alarm-state", middleware.ParseContextualMetricParams(api), ctxmetric.GetAlarmStateHandler(api))
-			ctxMetrics.POST("/:ctxMetricId/alarm-state/recognize", middleware.ParseContextualMetricParams(api), ctxmetric.RecognizeAlarmStateHandler(api))
-			ctxMetrics.POST("/:ctxMetricId/alarm-state/resolve", middleware.ParseContextualMetricParams(api), ctxmetric.ResolveAlarmStateHandler(api))
+			ctxMetrics.POST("/:ctxMetricId/alarm-state/recognize", teamOperator, middleware.ParseContextualMetricParams(api), ctxmetric.RecognizeAlarmStateHandler(api))
+			ctxMetrics.POST("/:ctxMetricId/alarm-state/resolve", teamOperator, middleware.ParseContextualMetricParams(api), ctxmetric.ResolveAlarmStateHandler(api))
+		}
+
+		billingReports := ctx.Group("/:ctxId/billing-reports")
+		{
+			billingReports.GET("/", middleware.ParseContextParams(api), billingreport.MGetHandler(api))
+			billingReports.GET("/:reportId", middleware.ParseContextParams(api), billingreport.GetHandler(api))
+		}
+
+		scheduledReports := ctx.Group("/:ctxId/scheduled-reports")
+		{
+			scheduledReports.GET("/", middleware.ParseContextParams(api), scheduledreport.MGetHandler(api))
+			scheduledReports.GET("/:reportId", middleware.ParseContextParams(api), scheduledreport.GetHandler(api))
+			scheduledReports.POST("/", teamAdmin, middleware.ParseContextParams(api), scheduledreport.CreateHandler(api))
+			scheduledReports.PATCH("/:reportId", teamAdmin, middleware.ParseContextParams(api), scheduledreport.UpdateHandler(api))
+			scheduledReports.DELETE("/:reportId", teamAdmin, middleware.ParseContextParams(api), scheduledreport.DeleteHandler(api))
+
+			scheduledReports.POST("/:reportId/run", teamAdmin, middleware.ParseContextParams(api), middleware.DataHistoryRequestsCounter(api), scheduledreport.RunHandler(api))
+			scheduledReports.GET("/:reportId/runs", middleware.ParseContextParams(api), scheduledreport.MGetRunsHandler(api))
+			scheduledReports.GET("/:reportId/runs/:runId", middleware.ParseContextParams(api), scheduledreport.GetRunHandler(api))
+			scheduledReports.GET("/:reportId/runs/:runId/files/:format", middleware.ParseContextParams(api), scheduledreport.GetFileHandler(api))
+		}
+
+		dashboards := teams.Group("/:teamId/dashboards", middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer))
+		{
+			dashboards.GET("/", dashboard.MGetHandler(api))
+			dashboards.GET("/:dashboardId", dashboard.GetHandler(api))
+			dashboards.POST("/", teamAdmin, dashboard.CreateHandler(api))
+			dashboards.PATCH("/:dashboardId", teamAdmin, dashboard.UpdateHandler(api))
+			dashboards.DELETE("/:dashboardId", teamAdmin, dashboard.DeleteHandler(api))
+
+			dashboards.GET("/:dashboardId/versions", dashboard.MGetVersionsHandler(api))
+			dashboards.GET("/:dashboardId/versions/:version", dashboard.GetVersionHandler(api))
+			dashboards.POST("/:dashboardId/versions/:version/rollback", teamAdmin, dashboard.RollbackHandler(api))
+
+			dashboards.GET("/:dashboardId/shares", teamAdmin, dashboard.MGetSharesHandler(api))
+			dashboards.POST("/:dashboardId/shares", teamAdmin, dashboard.ShareHandler(api))
+			dashboards.DELETE("/:dashboardId/shares/:shareTeamId", teamAdmin, dashboard.UnshareHandler(api))
 		}
 	}
 
@@ -153,7 +241,26 @@ func Set(s service.Service) {
 		dp.DELETE("/:dpId", datapolicy.DeleteHandler(api))
 	}
 
-	basic := r.Group("/containers/basics", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
+	metricTemplates := r.Group("/metric-templates", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
+	{
+		metricTemplates.GET("/", metrictemplate.MGetHandler(api))
+		metricTemplates.GET("/:templateId", metrictemplate.GetHandler(api))
+		metricTemplates.POST("/", metrictemplate.CreateHandler(api))
+		metricTemplates.PATCH("/:templateId", metrictemplate.UpdateHandler(api))
+		metricTemplates.DELETE("/:templateId", metrictemplate.DeleteHandler(api))
+		metricTemplates.GET("/:templateId/containers", metrictemplate.MGetContainersHandler(api))
+		metricTemplates.POST("/:templateId/containers", metrictemplate.ApplyHandler(api))
+		metricTemplates.DELETE("/:templateId/containers/:containerId", metrictemplate.UnlinkHandler(api))
+		metricTemplates.GET("/:templateId/drift", metrictemplate.DriftHandler(api))
+	}
+
+	configuration := r.Group("/config", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
+	{
+		configuration.GET("/export", config.ExportHandler(api))
+		configuration.POST("/import", config.ImportHandler(api))
+	}
+
+	basic := r.Group("/containers/basics", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), middleware.ContainerGuard(api))
 	{
 		basic.GET("/", container.GetBasicContainersHandlers(api))
 		basic.GET("/:containerId", container.GetBasicHandler(api))
@@ -181,7 +288,7 @@ func Set(s service.Service) {
 		}
 	}
 
-	SNMPv2c := r.Group("/containers/snmpv2c", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
+	SNMPv2c := r.Group("/containers/snmpv2c", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), middleware.ContainerGuard(api))
 	{
 		SNMPv2c.GET("/", container.GetSNMPv2cContainers(api))
 		SNMPv2c.GET("/:containerId", container.GetSNMPv2cHandler(api))
@@ -201,7 +308,7 @@ func Set(s service.Service) {
 		}
 	}
 
-	flexLegacy := r.Group("/containers/flex-legacy", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api))
+	flexLegacy := r.Group("/containers/flex-legacy", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), middleware.ContainerGuard(api))
 	{
 		flexLegacy.GET("/", container.GetFlexLegacyContainersHandler(api))
 		flexLegacy.GET("/:containerId", container.GetFlexLegacyHandler(api))
@@ -221,6 +328,19 @@ func Set(s service.Service) {
 		}
 	}
 
+	sites := r.Group("/sites")
+	{
+		sites.GET("/", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), site.MGetHandler(api))
+		sites.GET("/geojson", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), site.GeoJSONHandler(api))
+		sites.GET("/:siteId", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), site.GetHandler(api))
+		sites.POST("/", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), site.CreateHandler(api))
+		sites.PATCH("/:siteId", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), site.UpdateHandler(api))
+		sites.DELETE("/:siteId", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), site.DeleteHandler(api))
+		sites.GET("/:siteId/containers", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), site.MGetContainersHandler(api))
+		sites.POST("/:siteId/containers/:containerId", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), site.AddContainerHandler(api))
+		sites.DELETE("/:siteId/containers/:containerId", middleware.Protect(api, roles.Admin), middleware.RequestsCounter(api), site.RemoveContainerHandler(api))
+	}
+
 	customQuery := r.Group("/custom-queries")
 	{
 		customQuery.GET("/", middleware.Protect(api, roles.Viewer), middleware.RequestsCounter(api), customquery.MGetHandler(api))
@@ -301,6 +421,29 @@ func Set(s service.Service) {
 		requestWhitelist.DELETE("/:userId", whitelist.DeleteHandler(api))
 	}
 
+	auditLog := r.Group("/audit", middleware.Protect(api, roles.Master))
+	{
+		auditLog.GET("/", audit.MGetHandler(api))
+	}
+
+	oidcProviders := r.Group("/oidc-providers", middleware.Protect(api, roles.Master))
+	{
+		oidcProviders.GET("/", oidcprovider.MGetHandler(api))
+		oidcProviders.GET("/:providerId", oidcprovider.GetHandler(api))
+		oidcProviders.POST("/", oidcprovider.CreateHandler(api))
+		oidcProviders.PATCH("/:providerId", oidcprovider.UpdateHandler(api))
+		oidcProviders.DELETE("/:providerId", oidcprovider.DeleteHandler(api))
+	}
+
+	rateLimits := r.Group("/rate-limits", middleware.Protect(api, roles.Master))
+	{
+		rateLimits.GET("/", ratelimit.MGetHandler(api))
+		rateLimits.GET("/:rateLimitId", ratelimit.GetHandler(api))
+		rateLimits.POST("/", ratelimit.CreateHandler(api))
+		rateLimits.PATCH("/:rateLimitId", ratelimit.UpdateHandler(api))
+		rateLimits.DELETE("/:rateLimitId", ratelimit.DeleteHandler(api))
+	}
+
 	trapListeners := r.Group("/trap-listeners", middleware.Protect(api, roles.Admin))
 	{
 		trapListeners.GET("/", trap.MGetHandler(api))
@@ -315,6 +458,7 @@ func Set(s service.Service) {
 			middleware.Protect(api, roles.Viewer),
 			middleware.RealtimeDataRequestsCounter(api),
 			middleware.ParseContextualMetricParams(api),
+			middleware.MetricDataGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer),
 			middleware.MetricRequest(api),
 			ctxmetric.DataHandler(api),
 		)
@@ -322,12 +466,28 @@ func Set(s service.Service) {
 			middleware.Protect(api, roles.Viewer),
 			middleware.DataHistoryRequestsCounter(api),
 			middleware.ParseContextualMetricParams(api),
+			middleware.MetricDataGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer),
 			middleware.MetricRequest(api),
 			ctxmetric.QueryDataHandler(api),
 		)
+		r.GET("/teams/:teamId/ctx/:ctxId/data/export",
+			middleware.Protect(api, roles.Viewer),
+			middleware.DataHistoryRequestsCounter(api),
+			middleware.ParseContextParams(api),
+			middleware.TeamGuard(api, roles.Viewer, roles.TeamsManager, roles.TeamViewer),
+			ctxmetric.ExportDataHandler(api),
+		)
 	}
 }
 
 func setGetAlarmExpressions(api *api.API, r *gin.RouterGroup) {
 	r.GET("/:metricId/alarm-expressions", metric.GetAlarmExpressions(api))
 }
+
+// trustedProxies returns the proxies of the ";" separated list, nil if empty.
+func trustedProxies(proxies string) []string {
+	if proxies == "" {
+		return nil
+	}
+	return strings.Split(proxies, ";")
+}
@@ -0,0 +1,170 @@
+package router
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// joinPaths joins the paths like gin does, keeping the relative path trailing slash.
+func joinPaths(absolute string, relative string) string {
+	if relative == "" {
+		return absolute
+	}
+	p := path.Join(absolute, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(p, "/") {
+		return p + "/"
+	}
+	return p
+}
+
+// stringArg returns the call first argument if it is a string literal.
+func stringArg(call *ast.CallExpr) string {
+	if len(call.Args) == 0 {
+		return ""
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, _ := strconv.Unquote(lit.Value)
+	return s
+}
+
+// getRoutes returns the routes registered by the function body, relative to the
+// routes prefix, following the groups in the order they are declared.
+func getRoutes(fns map[string]*ast.FuncDecl, fn *ast.FuncDecl, prefixes map[string]string) (routes []string) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			call, ok := n.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Group" {
+				return true
+			}
+			group, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			prefixes[n.Lhs[0].(*ast.Ident).Name] = joinPaths(prefixes[group.Name], stringArg(call))
+		case *ast.CallExpr:
+			if ident, ok := n.Fun.(*ast.Ident); ok {
+				helper, ok := fns[ident.Name]
+				if !ok || len(n.Args) != 2 {
+					return true
+				}
+				group, ok := n.Args[1].(*ast.Ident)
+				if !ok {
+					return true
+				}
+				param := helper.Type.Params.List[len(helper.Type.Params.List)-1].Names[0].Name
+				routes = append(routes, getRoutes(fns, helper, map[string]string{param: prefixes[group.Name]})...)
+				return true
+			}
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			group, ok := sel.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if _, ok := prefixes[group.Name]; !ok {
+				return true
+			}
+			switch sel.Sel.Name {
+			case "GET", "POST", "PATCH", "DELETE":
+				routes = append(routes, openapi.Key(sel.Sel.Name, joinPaths(prefixes[group.Name], stringArg(n))))
+			}
+		}
+		return true
+	})
+	return routes
+}
+
+func TestRoutesDocumentation(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "router.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Fail to parse router, err: %s", err)
+	}
+
+	fns := make(map[string]*ast.FuncDecl)
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			fns[fn.Name.Name] = fn
+		}
+	}
+	set := fns["Set"]
+	delete(fns, "Set")
+
+	routes := getRoutes(fns, set, map[string]string{"router": "/"})
+	if len(routes) == 0 {
+		t.Fatal("No route found on router")
+	}
+
+	registered := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		registered[r] = true
+		if _, ok := openapi.Operations[r]; !ok {
+			t.Errorf("Route is not documented on the OpenAPI operations, route: %s", r)
+		}
+	}
+	for k := range openapi.Operations {
+		if !registered[k] {
+			t.Errorf("OpenAPI operation has no route, operation: %s", k)
+		}
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		proxies string
+		want    int
+	}{
+		// spoofed header of a client that is not a proxy
+		{"", http.StatusForbidden},
+		{"192.0.2.2;10.1.0.0/16", http.StatusForbidden},
+		// header set by a trusted proxy
+		{"192.0.2.1", http.StatusOK},
+		{"10.1.0.0/16;192.0.2.0/24", http.StatusOK},
+	}
+	for _, test := range tests {
+		router := gin.New()
+		err := router.SetTrustedProxies(trustedProxies(test.proxies))
+		if err != nil {
+			t.Errorf("SetTrustedProxies(%q) failed, err: %s", test.proxies, err)
+			continue
+		}
+		router.GET("/", func(c *gin.Context) {
+			if !auth.IPAllowed([]string{"203.0.113.7"}, c.ClientIP()) {
+				c.Status(http.StatusForbidden)
+				return
+			}
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:4000"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != test.want {
+			t.Errorf("Client ip with trusted proxies %q failed, want: %d, got: %d", test.proxies, test.want, w.Code)
+		}
+	}
+}
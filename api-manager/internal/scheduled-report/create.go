@@ -0,0 +1,98 @@
+package scheduledreport
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a new scheduled report of a context.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If the schedule never runs.
+//   - 404 If context or a contextual metric does not exists.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var r models.ScheduledReport
+		err = c.ShouldBind(&r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(r)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		r.ContextId = int32(ctxId)
+
+		ok, err := prepare(&r, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgScheduleNeverRuns))
+			return
+		}
+
+		exists, err := api.PG.ContextExists(ctx, r.ContextId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if context exists", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextNotFound))
+			return
+		}
+
+		valid, err := validMetrics(ctx, api, r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to validate scheduled report contextual metrics", logger.ErrField(err))
+			return
+		}
+		if !valid {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextualMetricNotFound))
+			return
+		}
+
+		id, err := api.PG.CreateScheduledReport(ctx, r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create scheduled report", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Scheduled report created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
@@ -0,0 +1,20 @@
+package scheduledreport
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// writeReportCSV writes the report as csv, one row per contextual metric.
+func writeReportCSV(w io.Writer, d reportData) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(d.header())
+	if err != nil {
+		return err
+	}
+	err = cw.WriteAll(d.records(-1))
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,160 @@
+package scheduledreport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// pdfPageWidth and pdfPageHeight are the landscape A4 page size in points.
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	// pdfMargin is the page margin in points.
+	pdfMargin = 36
+	// pdfFontSize is the font size in points.
+	pdfFontSize = 8
+	// pdfLeading is the line height in points.
+	pdfLeading = 10
+	// pdfLinesPerPage is the number of lines of each page.
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+	// pdfMaxCellLength is the maximum length of a table cell, longer cells
+	// are truncated.
+	pdfMaxCellLength = 24
+)
+
+// writeReportPDF writes the report as a pdf with the report table in monospaced
+// text.
+func writeReportPDF(w io.Writer, d reportData) error {
+	return writePDF(w, paginate(reportLines(d), pdfLinesPerPage))
+}
+
+// reportLines returns the report text lines, a title followed by the table.
+func reportLines(d reportData) []string {
+	lines := []string{
+		d.Name,
+		fmt.Sprintf("From %s to %s (UTC)",
+			time.Unix(d.Start, 0).UTC().Format("2006-01-02 15:04"),
+			time.Unix(d.Stop, 0).UTC().Format("2006-01-02 15:04")),
+		"",
+	}
+	if len(d.Metrics) == 0 {
+		return append(lines, "No contextual metrics.")
+	}
+
+	header := d.header()
+	header[0], header[1], header[2] = "id", "ident", "name"
+	rows := append([][]string{header}, d.records(2)...)
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = truncate(cell, pdfMaxCellLength)
+			if n := len([]rune(row[i])); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	for i, row := range rows {
+		var b strings.Builder
+		for j, cell := range row {
+			if j > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[j]-len([]rune(cell))))
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+		if i == 0 {
+			lines = append(lines, strings.Repeat("-", len([]rune(lines[len(lines)-1]))))
+		}
+	}
+	return lines
+}
+
+// truncate returns s with at most n runes.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "~"
+}
+
+// paginate splits the lines in pages.
+func paginate(lines []string, perPage int) (pages [][]string) {
+	for len(lines) > perPage {
+		pages = append(pages, lines[:perPage])
+		lines = lines[perPage:]
+	}
+	return append(pages, lines)
+}
+
+// writePDF writes a pdf document with a page for each lines group, using the
+// standard Courier font.
+func writePDF(w io.Writer, pages [][]string) error {
+	var b bytes.Buffer
+	// objects 1 and 2 are the catalog and the pages tree, 3 is the font,
+	// followed by a page and a content stream for each page
+	offsets := make([]int, 0, 3+2*len(pages))
+	object := func(content string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+
+		var s bytes.Buffer
+		fmt.Fprintf(&s, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			s.WriteString("(")
+			s.Write(pdfEscape(line))
+			s.WriteString(") Tj T*\n")
+		}
+		s.WriteString("ET")
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", s.Len(), s.Bytes()))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// pdfEscape returns the text as a pdf literal string content in WinAnsi
+// encoding. Characters out of the latin-1 range are replaced by "?".
+func pdfEscape(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b = append(b, '\\', byte(r))
+		case r < 32:
+			b = append(b, ' ')
+		case r < 256:
+			b = append(b, byte(r))
+		default:
+			b = append(b, '?')
+		}
+	}
+	return b
+}
@@ -0,0 +1,287 @@
+package scheduledreport
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/cron"
+	"github.com/fernandotsda/nemesys/shared/labels"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+// defaultInterval is the default sample interval in seconds.
+const defaultInterval = 300
+
+// maxReportMetrics is the maximum number of contextual metrics in a report.
+const maxReportMetrics = 100
+
+// maxAlarmCategories is the maximum number of alarm categories counted.
+const maxAlarmCategories = 100
+
+// reportData is the generated report content.
+type reportData struct {
+	// Name is the report name.
+	Name string
+	// Start is the reported range start in unix seconds.
+	Start int64
+	// Stop is the reported range stop in unix seconds.
+	Stop int64
+	// Aggregations are the report aggregations.
+	Aggregations []string
+	// AlarmCounts is true if the alarms are counted.
+	AlarmCounts bool
+	// Categories are the alarm categories, higher level first.
+	Categories []models.AlarmCategory
+	// Metrics are the contextual metrics results.
+	Metrics []reportMetric
+}
+
+type reportMetric struct {
+	// Id is the contextual metric identifier.
+	Id int64
+	// Ident is the contextual metric ident.
+	Ident string
+	// Name is the contextual metric name.
+	Name string
+	// Samples is the number of samples.
+	Samples int64
+	// Stats are the aggregations results. Aggregations of non numeric metrics
+	// without samples are missing.
+	Stats map[string]float64
+	// Alarms is the total number of alarms.
+	Alarms int64
+	// AlarmsByLevel is the number of alarms of each alarm category level.
+	AlarmsByLevel map[int32]int64
+}
+
+// nextRunAt returns the next run of the schedule after now in unix seconds, or
+// zero if the schedule never runs.
+func nextRunAt(schedule string, now time.Time) (int64, error) {
+	s, err := cron.Parse(schedule)
+	if err != nil {
+		return 0, err
+	}
+	next := s.Next(now)
+	if next.IsZero() {
+		return 0, nil
+	}
+	return next.Unix(), nil
+}
+
+// getMetadata returns the metadata of the report contextual metrics.
+func getMetadata(ctx context.Context, api *api.API, r models.ScheduledReport) ([]models.ContextualMetricMetadata, error) {
+	if r.Selector != "" {
+		selector, err := labels.Parse(r.Selector)
+		if err != nil {
+			return nil, err
+		}
+		return api.PG.GetContextualMetricsMetadataBySelector(ctx, r.ContextId, selector, maxReportMetrics)
+	}
+	return api.PG.GetContextualMetricsMetadata(ctx, r.ContextId, r.ContextualMetricsIds, maxReportMetrics)
+}
+
+// generate queries the report contextual metrics data and alarm history on the
+// range.
+func generate(ctx context.Context, api *api.API, r models.ScheduledReport, start int64, stop int64) (data reportData, err error) {
+	metadata, err := getMetadata(ctx, api, r)
+	if err != nil {
+		return data, err
+	}
+
+	data = reportData{
+		Name:         r.Name,
+		Start:        start,
+		Stop:         stop,
+		Aggregations: r.Aggregations,
+		AlarmCounts:  r.AlarmCounts,
+		Metrics:      make([]reportMetric, len(metadata)),
+	}
+	if r.AlarmCounts {
+		data.Categories, err = api.PG.GetAlarmCategories(ctx, pg.AlarmCategoriesQueryFilters{Limit: maxAlarmCategories})
+		if err != nil {
+			return data, err
+		}
+		sort.Slice(data.Categories, func(i, j int) bool {
+			return data.Categories[i].Level > data.Categories[j].Level
+		})
+	}
+
+	for i, m := range metadata {
+		var samples int64
+		values := make([]float64, 0)
+		err = api.Storage.QueryStream(ctx, storage.QueryOptions{
+			Start:        start,
+			Stop:         stop,
+			DataPolicyId: m.MetricRequest.DataPolicyId,
+			MetricId:     m.MetricRequest.MetricId,
+			MetricType:   m.MetricRequest.MetricType,
+			Step:         int64(r.Interval),
+		}, func(timestamp time.Time, value any) error {
+			samples++
+			if m.MetricRequest.MetricType == types.MTString {
+				return nil
+			}
+			if v, ok := toFloat(value); ok {
+				values = append(values, v)
+			}
+			return nil
+		})
+		if err != nil {
+			return data, err
+		}
+
+		metric := reportMetric{
+			Id:      m.Id,
+			Ident:   m.Ident,
+			Name:    m.Name,
+			Samples: samples,
+			Stats:   getStats(r.Aggregations, values, samples, expectedSamples(start, stop, r.Interval)),
+		}
+		if r.AlarmCounts {
+			points, err := api.Storage.QueryAlarmHistory(ctx, storage.QueryAlarmHistoryOptions{
+				Start:       start,
+				Stop:        stop,
+				ContainerId: m.MetricRequest.ContainerId,
+				MetricId:    m.MetricRequest.MetricId,
+			})
+			if err != nil {
+				return data, err
+			}
+			metric.Alarms, metric.AlarmsByLevel = countAlarms(points)
+		}
+		data.Metrics[i] = metric
+	}
+	return data, nil
+}
+
+// expectedSamples returns the number of sample windows on the range.
+func expectedSamples(start int64, stop int64, interval int32) int64 {
+	if stop <= start || interval <= 0 {
+		return 0
+	}
+	return (stop - start + int64(interval) - 1) / int64(interval)
+}
+
+// getStats returns the aggregations of the values. The availability is the
+// percentage of the expected samples that has data.
+func getStats(aggregations []string, values []float64, samples int64, expected int64) map[string]float64 {
+	stats := make(map[string]float64, len(aggregations))
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	for _, a := range aggregations {
+		if a == "availability" {
+			if expected > 0 {
+				stats[a] = math.Min(float64(samples)/float64(expected)*100, 100)
+			}
+			continue
+		}
+		if len(sorted) == 0 {
+			continue
+		}
+		switch a {
+		case "avg":
+			var sum float64
+			for _, v := range sorted {
+				sum += v
+			}
+			stats[a] = sum / float64(len(sorted))
+		case "min":
+			stats[a] = sorted[0]
+		case "max":
+			stats[a] = sorted[len(sorted)-1]
+		case "p95":
+			stats[a] = percentile(sorted, 95)
+		case "p99":
+			stats[a] = percentile(sorted, 99)
+		}
+	}
+	return stats
+}
+
+// percentile returns the nearest rank percentile of the sorted values.
+func percentile(sorted []float64, p int) float64 {
+	i := (p*len(sorted)+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+// countAlarms returns the total and the number of alarms of each level of the
+// alarm history points.
+func countAlarms(points [][3]any) (total int64, byLevel map[int32]int64) {
+	byLevel = make(map[int32]int64)
+	for _, p := range points {
+		total++
+		if level, ok := toFloat(p[2]); ok {
+			byLevel[int32(level)]++
+		}
+	}
+	return total, byLevel
+}
+
+// toFloat converts a numeric or boolean value to float.
+func toFloat(v any) (f float64, ok bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// header returns the report table header.
+func (d reportData) header() []string {
+	header := []string{"contextual_metric_id", "contextual_metric_ident", "contextual_metric_name", "samples"}
+	header = append(header, d.Aggregations...)
+	if d.AlarmCounts {
+		header = append(header, "alarms")
+		for _, c := range d.Categories {
+			header = append(header, "alarms_"+c.Name)
+		}
+	}
+	return header
+}
+
+// records returns the report table rows, with the floats formatted with the
+// precision.
+func (d reportData) records(prec int) [][]string {
+	records := make([][]string, len(d.Metrics))
+	for i, m := range d.Metrics {
+		record := []string{strconv.FormatInt(m.Id, 10), m.Ident, m.Name, strconv.FormatInt(m.Samples, 10)}
+		for _, a := range d.Aggregations {
+			v, ok := m.Stats[a]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.FormatFloat(v, 'f', prec, 64))
+		}
+		if d.AlarmCounts {
+			record = append(record, strconv.FormatInt(m.Alarms, 10))
+			for _, c := range d.Categories {
+				record = append(record, strconv.FormatInt(m.AlarmsByLevel[c.Level], 10))
+			}
+		}
+		records[i] = record
+	}
+	return records
+}
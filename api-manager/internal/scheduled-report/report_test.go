@@ -0,0 +1,174 @@
+package scheduledreport
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestGetStats(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(100 - i)
+	}
+	aggregations := []string{"avg", "min", "max", "p95", "p99", "availability"}
+	stats := getStats(aggregations, values, 100, 200)
+	want := map[string]float64{"avg": 50.5, "min": 1, "max": 100, "p95": 95, "p99": 99, "availability": 50}
+	for _, a := range aggregations {
+		if stats[a] != want[a] {
+			t.Errorf("getStats %s failed, want: %v, got: %v", a, want[a], stats[a])
+		}
+	}
+
+	// non numeric samples only have availability
+	stats = getStats(aggregations, nil, 10, 10)
+	if len(stats) != 1 || stats["availability"] != 100 {
+		t.Errorf("getStats without values failed, want: %v, got: %v", map[string]float64{"availability": 100}, stats)
+	}
+}
+
+func TestExpectedSamples(t *testing.T) {
+	tests := []struct {
+		start    int64
+		stop     int64
+		interval int32
+		want     int64
+	}{
+		{0, 3600, 300, 12},
+		{0, 3601, 300, 13},
+		{0, 0, 300, 0},
+		{10, 0, 300, 0},
+	}
+	for _, test := range tests {
+		got := expectedSamples(test.start, test.stop, test.interval)
+		if got != test.want {
+			t.Errorf("expectedSamples(%d, %d, %d) failed, want: %d, got: %d", test.start, test.stop, test.interval, test.want, got)
+		}
+	}
+}
+
+func TestCountAlarms(t *testing.T) {
+	points := [][3]any{
+		{int64(1), 10.0, int64(2)},
+		{int64(2), 12.0, int64(2)},
+		{int64(3), 15.0, int64(5)},
+		{int64(4), 15.0, nil},
+	}
+	total, byLevel := countAlarms(points)
+	if total != 4 {
+		t.Errorf("countAlarms total failed, want: %d, got: %d", 4, total)
+	}
+	if byLevel[2] != 2 || byLevel[5] != 1 || len(byLevel) != 2 {
+		t.Errorf("countAlarms by level failed, want: %v, got: %v", map[int32]int64{2: 2, 5: 1}, byLevel)
+	}
+}
+
+func TestNextRunAt(t *testing.T) {
+	now := time.Date(2023, 3, 15, 10, 20, 0, 0, time.UTC)
+	next, err := nextRunAt("0 8 * * mon", now)
+	if err != nil {
+		t.Fatalf("nextRunAt failed, err: %s", err)
+	}
+	want := time.Date(2023, 3, 20, 8, 0, 0, 0, time.UTC).Unix()
+	if next != want {
+		t.Errorf("nextRunAt failed, want: %d, got: %d", want, next)
+	}
+
+	next, err = nextRunAt("0 0 30 2 *", now)
+	if err != nil || next != 0 {
+		t.Errorf("nextRunAt of never running schedule failed, want: %d, got: %d", 0, next)
+	}
+}
+
+func testReportData() reportData {
+	return reportData{
+		Name:         "Weekly availability",
+		Start:        time.Date(2023, 3, 8, 0, 0, 0, 0, time.UTC).Unix(),
+		Stop:         time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC).Unix(),
+		Aggregations: []string{"avg", "availability"},
+		AlarmCounts:  true,
+		Categories:   []models.AlarmCategory{{Name: "critical", Level: 5}, {Name: "warning", Level: 1}},
+		Metrics: []reportMetric{
+			{Id: 1, Ident: "wan", Name: "WAN (link)", Samples: 2016, Stats: map[string]float64{"avg": 12.5, "availability": 100},
+				Alarms: 3, AlarmsByLevel: map[int32]int64{5: 1, 1: 2}},
+			{Id: 2, Ident: "status", Name: "Status", Samples: 10, Stats: map[string]float64{"availability": 0.5}},
+		},
+	}
+}
+
+func TestWriteReportCSV(t *testing.T) {
+	var b bytes.Buffer
+	err := writeReportCSV(&b, testReportData())
+	if err != nil {
+		t.Fatalf("writeReportCSV failed, err: %s", err)
+	}
+	want := "contextual_metric_id,contextual_metric_ident,contextual_metric_name,samples,avg,availability,alarms,alarms_critical,alarms_warning\n" +
+		"1,wan,WAN (link),2016,12.5,100,3,1,2\n" +
+		"2,status,Status,10,,0.5,0,0,0\n"
+	if b.String() != want {
+		t.Errorf("writeReportCSV failed, want: %q, got: %q", want, b.String())
+	}
+}
+
+func TestWriteReportPDF(t *testing.T) {
+	d := testReportData()
+	// enough metrics for more than one page
+	for i := 3; i <= 80; i++ {
+		d.Metrics = append(d.Metrics, reportMetric{Id: int64(i), Ident: "m" + strconv.Itoa(i), Name: "Metric", Stats: map[string]float64{}})
+	}
+
+	var b bytes.Buffer
+	err := writeReportPDF(&b, d)
+	if err != nil {
+		t.Fatalf("writeReportPDF failed, err: %s", err)
+	}
+	pdf := b.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("writeReportPDF failed, invalid header or trailer")
+	}
+	if !strings.Contains(pdf, "/Count 2") {
+		t.Errorf("writeReportPDF pages failed, want: %s", "/Count 2")
+	}
+	if !strings.Contains(pdf, `wan     WAN \(link\)`) {
+		t.Errorf("writeReportPDF failed, escaped row not found")
+	}
+
+	// each xref entry must point to its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	if m == nil {
+		t.Fatalf("writeReportPDF failed, startxref not found")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("writeReportPDF startxref failed, got: %q", pdf[xref:xref+10])
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1)
+	if len(entries) != 3+2*2 {
+		t.Fatalf("writeReportPDF xref entries failed, want: %d, got: %d", 7, len(entries))
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(e[1])
+		want := fmt.Sprintf("%d 0 obj\n", i+1)
+		if !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("writeReportPDF xref offset failed, want: %q, got: %q", want, pdf[offset:offset+len(want)])
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	lines := make([]string, 25)
+	pages := paginate(lines, 10)
+	if len(pages) != 3 || len(pages[2]) != 5 {
+		t.Errorf("paginate failed, want: %d pages, got: %d", 3, len(pages))
+	}
+	pages = paginate(nil, 10)
+	if len(pages) != 1 {
+		t.Errorf("paginate of no lines failed, want: %d pages, got: %d", 1, len(pages))
+	}
+}
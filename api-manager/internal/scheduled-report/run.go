@@ -0,0 +1,144 @@
+package scheduledreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/mail"
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// runTimeout is the maximum duration of a run.
+const runTimeout = time.Minute * 10
+
+// keepRuns is the number of runs kept of each report, older runs and its files
+// are deleted.
+const keepRuns = 50
+
+// maxRunErrorLength is the maximum length of the run error.
+const maxRunErrorLength = 1024
+
+var contentTypes = map[string]string{
+	models.ScheduledReportFormatCSV: "text/csv",
+	models.ScheduledReportFormatPDF: "application/pdf",
+}
+
+// startRun creates a running run of the report, with the time range until stop.
+func startRun(ctx context.Context, api *api.API, r models.ScheduledReport, stop int64) (run models.ScheduledReportRun, err error) {
+	run = models.ScheduledReportRun{
+		ReportId:  r.Id,
+		Status:    models.ScheduledReportRunRunning,
+		Start:     stop - int64(r.TimeRange),
+		Stop:      stop,
+		Formats:   []string{},
+		CreatedAt: time.Now().Unix(),
+	}
+	run.Id, err = api.PG.CreateScheduledReportRun(ctx, run)
+	return run, err
+}
+
+// execute generates the run files, sends them to the report emails and saves the run
+// result. Runs on background, limited to the run timeout.
+func execute(api *api.API, r models.ScheduledReport, run models.ScheduledReportRun) {
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+
+	attachments, err := render(ctx, api, r, &run)
+	if err != nil {
+		run.Status = models.ScheduledReportRunFailed
+		run.Error = err.Error()
+		api.Log.Error("Fail to run scheduled report, id: "+strconv.FormatInt(int64(r.Id), 10), logger.ErrField(err))
+	} else {
+		run.Status = models.ScheduledReportRunDone
+		run.Sent, run.Error = send(api, r, run, attachments)
+	}
+	if len(run.Error) > maxRunErrorLength {
+		run.Error = run.Error[:maxRunErrorLength]
+	}
+	run.FinishedAt = time.Now().Unix()
+
+	err = api.PG.FinishScheduledReportRun(ctx, run)
+	if err != nil {
+		api.Log.Error("Fail to finish scheduled report run", logger.ErrField(err))
+		return
+	}
+	api.Log.Info("Scheduled report run finished, id: " + strconv.FormatInt(run.Id, 10) + ", status: " + run.Status)
+
+	_, err = api.PG.PruneScheduledReportRuns(ctx, r.Id, keepRuns)
+	if err != nil {
+		api.Log.Error("Fail to prune scheduled report runs", logger.ErrField(err))
+	}
+}
+
+// render generates the report and saves the run files, returning them as
+// attachments.
+func render(ctx context.Context, api *api.API, r models.ScheduledReport, run *models.ScheduledReportRun) (attachments []mail.Attachment, err error) {
+	data, err := generate(ctx, api, r, run.Start, run.Stop)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments = make([]mail.Attachment, 0, len(r.Formats))
+	for _, format := range r.Formats {
+		var b bytes.Buffer
+		switch format {
+		case models.ScheduledReportFormatCSV:
+			err = writeReportCSV(&b, data)
+		case models.ScheduledReportFormatPDF:
+			err = writeReportPDF(&b, data)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = api.PG.CreateScheduledReportFile(ctx, run.Id, format, b.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		run.Formats = append(run.Formats, format)
+		attachments = append(attachments, mail.Attachment{
+			Name:        fileName(r, *run, format),
+			ContentType: contentTypes[format],
+			Data:        b.Bytes(),
+		})
+	}
+	return attachments, nil
+}
+
+// send sends the attachments to the report emails. Returns the error message
+// if the email could not be sent.
+func send(api *api.API, r models.ScheduledReport, run models.ScheduledReportRun, attachments []mail.Attachment) (sent bool, errMsg string) {
+	if len(r.Emails) == 0 {
+		return false, ""
+	}
+	if api.Mailer == nil {
+		return false, "SMTP server is not configured"
+	}
+
+	body := fmt.Sprintf("The report '%s' from %s to %s (UTC) is attached.\n",
+		r.Name,
+		time.Unix(run.Start, 0).UTC().Format("2006-01-02 15:04"),
+		time.Unix(run.Stop, 0).UTC().Format("2006-01-02 15:04"),
+	)
+	if r.Descr != "" {
+		body += "\n" + r.Descr + "\n"
+	}
+	err := api.Mailer.SendWithAttachments(r.Emails, "Scheduled report: "+r.Name, body, attachments)
+	if err != nil {
+		api.Log.Error("Fail to send scheduled report email", logger.ErrField(err))
+		return false, "Fail to send email: " + err.Error()
+	}
+	return true, ""
+}
+
+// fileName returns the run file name of the format.
+func fileName(r models.ScheduledReport, run models.ScheduledReportRun, format string) string {
+	return fmt.Sprintf("scheduled-report-%d-%s.%s", r.Id, time.Unix(run.Stop, 0).UTC().Format("2006-01-02-1504"), format)
+}
@@ -0,0 +1,263 @@
+package scheduledreport
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Runs a scheduled report now, with the time range until now. The run is
+// executed on background, the schedule is not changed.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If not found.
+//   - 200 If succeeded. With the run id.
+func RunHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("reportId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, r, err := api.PG.GetScheduledReport(ctx, int32(ctxId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportNotFound))
+			return
+		}
+
+		run, err := startRun(ctx, api, r, time.Now().Unix())
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create scheduled report run", logger.ErrField(err))
+			return
+		}
+		go execute(api, r, run)
+		api.Log.Info("Scheduled report run started, id: " + strconv.FormatInt(run.Id, 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(run.Id))
+	}
+}
+
+// Get multi runs of a scheduled report, newest first.
+// Params:
+//   - "limit" Limit of runs returned. Default is 30, max is 30, min is 1.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If report not found.
+//   - 200 If succeeded.
+func MGetRunsHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("reportId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, _, err := api.PG.GetScheduledReport(ctx, int32(ctxId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportNotFound))
+			return
+		}
+
+		runs, err := api.PG.GetScheduledReportRuns(ctx, int32(id), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report runs", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(runs))
+	}
+}
+
+// Get a scheduled report run.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If report or run not found.
+//   - 200 If succeeded.
+func GetRunHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("reportId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		runId, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, _, err := api.PG.GetScheduledReport(ctx, int32(ctxId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportNotFound))
+			return
+		}
+
+		exists, run, err := api.PG.GetScheduledReportRun(ctx, int32(id), runId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report run", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportRunNotFound))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(run))
+	}
+}
+
+// Downloads a scheduled report run file.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If report or file not found.
+//   - 200 If succeeded.
+func GetFileHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		id, err := strconv.ParseInt(c.Param("reportId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		runId, err := strconv.ParseInt(c.Param("runId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		format := c.Param("format")
+		contentType, ok := contentTypes[format]
+		if !ok {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, r, err := api.PG.GetScheduledReport(ctx, int32(ctxId), int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportNotFound))
+			return
+		}
+
+		exists, run, err := api.PG.GetScheduledReportRun(ctx, r.Id, runId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report run", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportRunNotFound))
+			return
+		}
+
+		exists, content, err := api.PG.GetScheduledReportFile(ctx, r.Id, runId, format)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get scheduled report file", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgScheduledReportFileNotFound))
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName(r, run, format)))
+		c.Data(http.StatusOK, contentType, content)
+	}
+}
@@ -0,0 +1,35 @@
+package scheduledreport
+
+import (
+	"context"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// prepare sets the report defaults and the next run after now. Returns false if
+// the schedule never runs.
+func prepare(r *models.ScheduledReport, now time.Time) (ok bool, err error) {
+	if r.Interval == 0 {
+		r.Interval = defaultInterval
+	}
+	r.NextRunAt, err = nextRunAt(r.Schedule, now)
+	if err != nil {
+		return false, err
+	}
+	return r.NextRunAt != 0, nil
+}
+
+// validMetrics returns true if the report contextual metrics are of the report
+// context.
+func validMetrics(ctx context.Context, api *api.API, r models.ScheduledReport) (valid bool, err error) {
+	if len(r.ContextualMetricsIds) == 0 {
+		return true, nil
+	}
+	metadata, err := api.PG.GetContextualMetricsMetadata(ctx, r.ContextId, r.ContextualMetricsIds, maxReportMetrics)
+	if err != nil {
+		return false, err
+	}
+	return len(metadata) == len(r.ContextualMetricsIds), nil
+}
@@ -0,0 +1,85 @@
+package scheduledreport
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/service"
+)
+
+// schedulerInterval is the interval between each due reports check.
+const schedulerInterval = time.Minute
+
+// maxDueReports is the maximum number of due reports started on each check.
+const maxDueReports = 20
+
+// StartScheduler starts running the due scheduled reports in background, until
+// the service is done. Each run is claimed before starting, so only one
+// instance runs it.
+func StartScheduler(s service.Service) {
+	api := s.(*api.API)
+	go scheduler(api)
+}
+
+func scheduler(api *api.API) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	now := time.Now()
+	n, err := api.PG.FailStaleScheduledReportRuns(ctx, now.Add(-runTimeout).Unix(), now.Unix(), "Run was interrupted")
+	cancel()
+	if err != nil {
+		api.Log.Error("Fail to update stale scheduled report runs", logger.ErrField(err))
+	} else if n > 0 {
+		api.Log.Warn("Stale scheduled report runs failed, runs: " + strconv.FormatInt(n, 10))
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-api.Done():
+			return
+		}
+		runDue(api, time.Now())
+	}
+}
+
+// runDue claims and starts the reports due on now.
+func runDue(api *api.API, now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	reports, err := api.PG.GetDueScheduledReports(ctx, now.Unix(), maxDueReports)
+	if err != nil {
+		api.Log.Error("Fail to get due scheduled reports", logger.ErrField(err))
+		return
+	}
+	for _, r := range reports {
+		next, err := nextRunAt(r.Schedule, now)
+		if err != nil {
+			api.Log.Error("Fail to parse scheduled report schedule, id: "+strconv.FormatInt(int64(r.Id), 10), logger.ErrField(err))
+			continue
+		}
+
+		claimed, err := api.PG.ClaimScheduledReport(ctx, r.Id, r.NextRunAt, next)
+		if err != nil {
+			api.Log.Error("Fail to claim scheduled report", logger.ErrField(err))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// the range is aligned to the schedule, even if the check is late
+		run, err := startRun(ctx, api, r, r.NextRunAt)
+		if err != nil {
+			api.Log.Error("Fail to create scheduled report run", logger.ErrField(err))
+			continue
+		}
+		go execute(api, r, run)
+	}
+}
@@ -0,0 +1,156 @@
+package site
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Attaches a container to a site, moving it from its current site.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If site or container not found.
+//   - 200 If succeeded.
+func AddContainerHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		siteId, err := strconv.ParseInt(c.Param("siteId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		containerId, err := strconv.ParseInt(c.Param("containerId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, _, err := api.PG.GetSite(ctx, int32(siteId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get site", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgSiteNotFound))
+			return
+		}
+
+		exists, err = api.PG.AddContainerToSite(ctx, int32(siteId), int32(containerId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to add container to site", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContainerNotFound))
+			return
+		}
+		api.Log.Info("Container added to site, container id: " + c.Param("containerId") + ", site id: " + c.Param("siteId"))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Detaches a container from a site.
+// Responses:
+//   - 400 If invalid params.
+//   - 404 If container is not on the site.
+//   - 200 If succeeded.
+func RemoveContainerHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		siteId, err := strconv.ParseInt(c.Param("siteId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		containerId, err := strconv.ParseInt(c.Param("containerId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.RemoveContainerFromSite(ctx, int32(siteId), int32(containerId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to remove container from site", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgSiteContainerNotFound))
+			return
+		}
+		api.Log.Info("Container removed from site, container id: " + c.Param("containerId") + ", site id: " + c.Param("siteId"))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Gets the containers of a site, without the containers of its child sites.
+// Only the containers of the session user teams are returned, unless the user
+// is a teams manager or above.
+// Params:
+//   - "limit" Limit of containers returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func MGetContainersHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		siteId, err := strconv.ParseInt(c.Param("siteId"), 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		containers, err := api.PG.GetSiteContainers(ctx, int32(siteId), containersUserId(meta), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get site containers", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(containers))
+	}
+}
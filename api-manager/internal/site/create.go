@@ -0,0 +1,69 @@
+package site
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Creates a site.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If the site can't be inside the parent.
+//   - 404 If parent site not found.
+//   - 200 If succeeded.
+func CreateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var s models.Site
+		err := c.ShouldBind(&s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		msg, err := checkParent(ctx, api, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get parent site", logger.ErrField(err))
+			return
+		}
+		switch msg {
+		case tools.MsgSiteParentNotFound:
+			c.JSON(http.StatusNotFound, tools.MsgRes(msg))
+			return
+		case tools.MsgInvalidSiteParent:
+			c.JSON(http.StatusBadRequest, tools.MsgRes(msg))
+			return
+		}
+
+		id, err := api.PG.CreateSite(ctx, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create site", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Site created, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.IdRes(int64(id)))
+	}
+}
@@ -0,0 +1,147 @@
+package site
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+	"github.com/gin-gonic/gin"
+)
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string            `json:"type"`
+	Geometry   point             `json:"geometry"`
+	Properties featureProperties `json:"properties"`
+}
+
+type point struct {
+	Type string `json:"type"`
+	// Coordinates is the longitude and latitude, in this order.
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type featureProperties struct {
+	Id                int32            `json:"id"`
+	ParentId          int32            `json:"parent-id"`
+	Kind              string           `json:"kind"`
+	Name              string           `json:"name"`
+	Descr             string           `json:"descr"`
+	AlarmState        types.AlarmState `json:"alarm-state"`
+	AlarmedMetrics    int32            `json:"alarmed-metrics"`
+	RecognizedMetrics int32            `json:"recognized-metrics"`
+	Containers        int32            `json:"containers"`
+}
+
+// newFeatureCollection creates a feature collection with the sites that
+// have coordinates. The alarm state of each site is the worst state of
+// the metrics of the site and its child sites.
+func newFeatureCollection(sites []models.Site, summaries []models.SiteAlarmSummary, kind string) featureCollection {
+	bySite := make(map[int32]models.SiteAlarmSummary, len(summaries))
+	for _, s := range summaries {
+		bySite[s.SiteId] = s
+	}
+
+	fc := featureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]feature, 0, len(sites)),
+	}
+	for _, s := range sites {
+		if s.Latitude == nil || s.Longitude == nil {
+			continue
+		}
+		if kind != "" && s.Kind != kind {
+			continue
+		}
+		summary := bySite[s.Id]
+		fc.Features = append(fc.Features, feature{
+			Type: "Feature",
+			Geometry: point{
+				Type:        "Point",
+				Coordinates: [2]float64{*s.Longitude, *s.Latitude},
+			},
+			Properties: featureProperties{
+				Id:                s.Id,
+				ParentId:          s.ParentId,
+				Kind:              s.Kind,
+				Name:              s.Name,
+				Descr:             s.Descr,
+				AlarmState:        worstState(summary),
+				AlarmedMetrics:    summary.Alarmed,
+				RecognizedMetrics: summary.Recognized,
+				Containers:        summary.Containers,
+			},
+		})
+	}
+	return fc
+}
+
+// Gets the sites with coordinates as a GeoJSON feature collection. Each
+// feature has the worst current alarm state of the site and its child sites.
+// The containers and alarms are summarized only for the containers of the
+// session user teams, unless the user is a teams manager or above.
+// Params:
+//   - "kind" Filter by kind.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func GeoJSONHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		kind := c.Query("kind")
+		switch kind {
+		case "", models.SiteKindRegion, models.SiteKindSite, models.SiteKindRack:
+		default:
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		sites, err := api.PG.GetAllSites(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get all sites", logger.ErrField(err))
+			return
+		}
+
+		summaries, err := api.PG.GetSitesAlarmSummaries(ctx, containersUserId(meta))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get sites alarm summaries", logger.ErrField(err))
+			return
+		}
+
+		b, err := json.Marshal(newFeatureCollection(sites, summaries, kind))
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to marshal sites feature collection", logger.ErrField(err))
+			return
+		}
+
+		c.Data(http.StatusOK, "application/geo+json", b)
+	}
+}
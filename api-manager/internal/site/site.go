@@ -0,0 +1,68 @@
+package site
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+// validParent returns true if a site of the kind can be inside a site of the
+// parent kind. The parent kind is empty if the site has no parent.
+func validParent(kind string, parentKind string) bool {
+	switch kind {
+	case models.SiteKindRegion, models.SiteKindSite:
+		return parentKind == "" || parentKind == models.SiteKindRegion
+	case models.SiteKindRack:
+		return parentKind == models.SiteKindSite
+	}
+	return false
+}
+
+// worstState returns the worst alarm state of the summary, alarmed, then
+// recognized and then not alarmed.
+func worstState(s models.SiteAlarmSummary) types.AlarmState {
+	if s.Alarmed > 0 {
+		return types.ASAlarmed
+	}
+	if s.Recognized > 0 {
+		return types.ASRecognized
+	}
+	return types.ASNotAlarmed
+}
+
+// containersUserId returns the session user id if the user only sees the
+// containers of its teams, otherwise zero. As on the teams routes, the teams
+// managers and above see the containers of all teams.
+func containersUserId(meta auth.SessionMeta) int32 {
+	if meta.Role >= roles.TeamsManager {
+		return 0
+	}
+	return meta.UserId
+}
+
+// checkParent checks if the site parent exists and if the site can be inside it.
+// Returns an empty message if the parent is valid.
+func checkParent(ctx context.Context, api *api.API, s models.Site) (msg string, err error) {
+	if s.ParentId == 0 {
+		if !validParent(s.Kind, "") {
+			return tools.MsgInvalidSiteParent, nil
+		}
+		return "", nil
+	}
+	exists, parent, err := api.PG.GetSite(ctx, s.ParentId)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return tools.MsgSiteParentNotFound, nil
+	}
+	if !validParent(s.Kind, parent.Kind) {
+		return tools.MsgInvalidSiteParent, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,103 @@
+package site
+
+import (
+	"testing"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+func TestValidParent(t *testing.T) {
+	tests := []struct {
+		kind       string
+		parentKind string
+		want       bool
+	}{
+		{models.SiteKindRegion, "", true},
+		{models.SiteKindRegion, models.SiteKindRegion, true},
+		{models.SiteKindRegion, models.SiteKindSite, false},
+		{models.SiteKindSite, "", true},
+		{models.SiteKindSite, models.SiteKindRegion, true},
+		{models.SiteKindSite, models.SiteKindSite, false},
+		{models.SiteKindSite, models.SiteKindRack, false},
+		{models.SiteKindRack, "", false},
+		{models.SiteKindRack, models.SiteKindRegion, false},
+		{models.SiteKindRack, models.SiteKindSite, true},
+		{models.SiteKindRack, models.SiteKindRack, false},
+		{"room", "", false},
+	}
+	for _, test := range tests {
+		got := validParent(test.kind, test.parentKind)
+		if got != test.want {
+			t.Errorf("validParent(%q, %q) failed, want: %v, got: %v", test.kind, test.parentKind, test.want, got)
+		}
+	}
+}
+
+func TestWorstState(t *testing.T) {
+	tests := []struct {
+		summary models.SiteAlarmSummary
+		want    types.AlarmState
+	}{
+		{models.SiteAlarmSummary{}, types.ASNotAlarmed},
+		{models.SiteAlarmSummary{Recognized: 2}, types.ASRecognized},
+		{models.SiteAlarmSummary{Alarmed: 1, Recognized: 2}, types.ASAlarmed},
+		{models.SiteAlarmSummary{Alarmed: 1}, types.ASAlarmed},
+	}
+	for _, test := range tests {
+		got := worstState(test.summary)
+		if got != test.want {
+			t.Errorf("worstState(%+v) failed, want: %v, got: %v", test.summary, test.want, got)
+		}
+	}
+}
+
+func TestNewFeatureCollection(t *testing.T) {
+	lat, lon := -30.03, -51.23
+	sites := []models.Site{
+		{Id: 1, Kind: models.SiteKindRegion, Name: "South", Latitude: &lat, Longitude: &lon},
+		{Id: 2, ParentId: 1, Kind: models.SiteKindSite, Name: "POA", Latitude: &lat, Longitude: &lon},
+		{Id: 3, ParentId: 2, Kind: models.SiteKindRack, Name: "R01"},
+	}
+	summaries := []models.SiteAlarmSummary{
+		{SiteId: 1, Containers: 2, Alarmed: 1},
+		{SiteId: 2, Containers: 2, Alarmed: 1},
+	}
+
+	fc := newFeatureCollection(sites, summaries, "")
+	if len(fc.Features) != 2 {
+		t.Fatalf("newFeatureCollection failed, want: %d features, got: %d", 2, len(fc.Features))
+	}
+	f := fc.Features[1]
+	if f.Geometry.Coordinates != [2]float64{lon, lat} {
+		t.Errorf("newFeatureCollection coordinates failed, want: %v, got: %v", [2]float64{lon, lat}, f.Geometry.Coordinates)
+	}
+	if f.Properties.AlarmState != types.ASAlarmed {
+		t.Errorf("newFeatureCollection alarm state failed, want: %v, got: %v", types.ASAlarmed, f.Properties.AlarmState)
+	}
+
+	fc = newFeatureCollection(sites, summaries, models.SiteKindSite)
+	if len(fc.Features) != 1 || fc.Features[0].Properties.Id != 2 {
+		t.Errorf("newFeatureCollection with kind failed, want: site 2, got: %+v", fc.Features)
+	}
+}
+
+func TestContainersUserId(t *testing.T) {
+	tests := []struct {
+		role roles.Role
+		want int32
+	}{
+		{roles.Viewer, 7},
+		{roles.TeamsManager, 0},
+		{roles.Admin, 0},
+		{roles.Master, 0},
+	}
+	for _, test := range tests {
+		got := containersUserId(auth.SessionMeta{UserId: 7, Role: test.role})
+		if got != test.want {
+			t.Errorf("containersUserId of role %d failed, want: %d, got: %d", test.role, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package site
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Updates a site.
+// Responses:
+//   - 400 If invalid params.
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If the site can't be inside the parent.
+//   - 400 If the kind changed and the site has child sites.
+//   - 404 If site or parent site not found.
+//   - 200 If succeeded.
+func UpdateHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawId := c.Param("siteId")
+		id, err := strconv.ParseInt(rawId, 0, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var s models.Site
+		err = c.ShouldBind(&s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		s.Id = int32(id)
+
+		exists, current, err := api.PG.GetSite(ctx, s.Id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get site", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgSiteNotFound))
+			return
+		}
+
+		if s.ParentId != 0 {
+			// the parent can't be the site or one of its descendants
+			in, err := api.PG.SiteInSubtree(ctx, s.Id, s.ParentId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to check if parent is in the site subtree", logger.ErrField(err))
+				return
+			}
+			if in {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidSiteParent))
+				return
+			}
+		}
+
+		msg, err := checkParent(ctx, api, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get parent site", logger.ErrField(err))
+			return
+		}
+		switch msg {
+		case tools.MsgSiteParentNotFound:
+			c.JSON(http.StatusNotFound, tools.MsgRes(msg))
+			return
+		case tools.MsgInvalidSiteParent:
+			c.JSON(http.StatusBadRequest, tools.MsgRes(msg))
+			return
+		}
+
+		if s.Kind != current.Kind {
+			has, err := api.PG.SiteHasChildren(ctx, s.Id)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to check if site has children", logger.ErrField(err))
+				return
+			}
+			if has {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgSiteHasChildren))
+				return
+			}
+		}
+
+		exists, err = api.PG.UpdateSite(ctx, s)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update site", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgSiteNotFound))
+			return
+		}
+		api.Log.Info("Site updated, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
@@ -0,0 +1,182 @@
+package team
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Sets a member role on a team context, which replaces the member team role on the
+// context.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If invalid user or team id.
+//   - 404 If member does not exists.
+//   - 404 If context does not exists.
+//   - 200 If succeeded.
+func SetMemberContextRoleHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawTeamId := c.Param("teamId")
+		rawUserId := c.Param("userId")
+
+		teamId, err := strconv.ParseInt(rawTeamId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		userId, err := strconv.ParseInt(rawUserId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var ctxRole models.ContextRole
+		err = c.ShouldBind(&ctxRole)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(ctxRole)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		r, err := api.PG.ExistsMemberContext(ctx, int32(teamId), int32(userId), ctxRole.ContextId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check if member and context exists", logger.ErrField(err))
+			return
+		}
+		if !r.MemberExists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMemberNotFound))
+			return
+		}
+		if !r.ContextExists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextNotFound))
+			return
+		}
+
+		err = api.PG.SetMemberContextRole(ctx, int32(userId), ctxRole)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to set member context role", logger.ErrField(err))
+			return
+		}
+		api.Log.Debug("Team " + rawTeamId + " member " + rawUserId + " context role setted")
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Removes a member role of a team context, so the member team role is used on the
+// context.
+// Responses:
+//   - 400 If invalid user, team or context id.
+//   - 404 If member context role does not exists.
+//   - 200 If succeeded.
+func DeleteMemberContextRoleHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		userId, err := strconv.ParseInt(c.Param("userId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		ctxId, err := strconv.ParseInt(c.Param("ctxId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, err := api.PG.DeleteMemberContextRole(ctx, int32(teamId), int32(userId), int32(ctxId))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to delete member context role", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgContextRoleNotFound))
+			return
+		}
+		api.Log.Debug("Member context role deleted, context id: " + c.Param("ctxId"))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Get a member roles on the team contexts.
+// Params:
+//   - "limit" Limit of roles returned. Default is 30, max is 30, min is 0.
+//   - "offset" Offset for searching. Default is 0, min is 0.
+//
+// Responses:
+//   - 400 If invalid params.
+//   - 200 If succeeded.
+func MGetMemberContextsRolesHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		teamId, err := strconv.ParseInt(c.Param("teamId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		userId, err := strconv.ParseInt(c.Param("userId"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		limit, err := tools.IntRangeQuery(c, "limit", 30, 30, 1)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+		offset, err := tools.IntMinQuery(c, "offset", 0, 0)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		roles, err := api.PG.GetMemberContextsRoles(ctx, int32(teamId), int32(userId), limit, offset)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get member contexts roles", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(roles))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
@@ -13,7 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// Add a member.
+// Add a member. The member role is the team viewer if no role is given.
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If invalid user or team id.
@@ -31,7 +32,7 @@ func AddMemberHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		var id models.Id32
+		var id models.TeamMember
 		err = c.ShouldBind(&id)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
@@ -43,6 +44,9 @@ func AddMemberHandler(api *api.API) func(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
 			return
 		}
+		if id.Role == roles.TeamUnknown {
+			id.Role = roles.TeamViewer
+		}
 
 		r, err := api.PG.ExistsRelUserTeam(ctx, id.Id, int32(teamId))
 		if err != nil {
@@ -66,7 +70,7 @@ func AddMemberHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
-		err = api.PG.AddTeamMember(ctx, id.Id, int32(teamId))
+		err = api.PG.AddTeamMember(ctx, id.Id, int32(teamId), id.Role)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
@@ -81,6 +85,64 @@ func AddMemberHandler(api *api.API) func(c *gin.Context) {
 	}
 }
 
+// Updates a member team role.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 400 If invalid user or team id.
+//   - 404 If member does not exists.
+//   - 200 If succeeded.
+func UpdateMemberHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		rawTeamId := c.Param("teamId")
+		rawUserId := c.Param("userId")
+
+		teamId, err := strconv.ParseInt(rawTeamId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		userId, err := strconv.ParseInt(rawUserId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		var role models.TeamMemberRole
+		err = c.ShouldBind(&role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(role)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		exists, err := api.PG.UpdateTeamMemberRole(ctx, int32(userId), int32(teamId), role.Role)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update member role", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgMemberNotFound))
+			return
+		}
+		api.Log.Debug("Team " + rawTeamId + " member " + rawUserId + " role updated")
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
 // Remove a member.
 // Responses:
 //   - 400 If invalid user or team id.
@@ -129,6 +191,7 @@ func RemoveMemberHandler(api *api.API) func(c *gin.Context) {
 // Params:
 //   - "limit" Limit of teams returned. Default is 30, max is 30, min is 0.
 //   - "offset" Offset for searching. Default is 0, min is 0.
+//   - "team-role" Member team role.
 //
 // Responses:
 //   - 400 If invalid user or team id.
@@ -154,11 +217,13 @@ func MGetMembersHandler(api *api.API) func(c *gin.Context) {
 		}
 
 		role, _ := strconv.ParseInt(c.Query("role"), 0, 16)
+		teamRole, _ := strconv.ParseInt(c.Query("team-role"), 0, 16)
 		m, err := api.PG.GetTeamMembers(ctx, pg.MemberQueryFilters{
 			Limit:     limit,
 			Offset:    offset,
 			TeamId:    int32(id),
 			Role:      int16(role),
+			TeamRole:  int16(teamRole),
 			FirstName: c.Query("first-name"),
 			LastName:  c.Query("last-name"),
 			Username:  c.Query("username"),
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ChangedFields returns the JSON fields names which values are different,
+// ignoring the skipped fields.
+func ChangedFields(current any, desired any, skip ...string) (fields []string) {
+	c, d := toFields(current), toFields(desired)
+	keys := make(map[string]bool, len(d))
+	for k := range c {
+		keys[k] = true
+	}
+	for k := range d {
+		keys[k] = true
+	}
+	for _, s := range skip {
+		delete(keys, s)
+	}
+	for k := range keys {
+		if !reflect.DeepEqual(c[k], d[k]) {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// toFields returns the value JSON fields.
+func toFields(v any) (fields map[string]any) {
+	b, _ := json.Marshal(v)
+	json.Unmarshal(b, &fields)
+	for k, f := range fields {
+		// nil and empty lists are the same
+		if l, ok := f.([]any); ok && len(l) == 0 {
+			fields[k] = nil
+		}
+	}
+	return fields
+}
@@ -12,8 +12,11 @@ const (
 	MsgUserNotFound                        = "User does not exists."
 	MsgMemberNotFound                      = "Member does not exists."
 	MsgCustomQueryNotFound                 = "Custom query does not exists."
+	MsgBillingReportNotFound               = "Billing report does not exists."
+	MsgImportJobNotFound                   = "Import job does not exists."
 	MsgRefkeyNotFound                      = "Metric reference not exists."
 	MsgAPIKeyNotFound                      = "API Key does not exists."
+	MsgRateLimitNotFound                   = "Rate limit does not exists."
 	MsgAlarmExpressionNotFound             = "Alarm expression does not exists."
 	MsgAlarmProfileNotFound                = "Alarm profile does not exists."
 	MsgUserWhitelistNotFound               = "User does not exists in whitelist."
@@ -26,25 +29,78 @@ const (
 	MsgTrapListenerNotFound                = "Trap listener does not exists."
 	MsgAlarmEndpointNotFound               = "Alarm endpoint does not exists."
 	MsgAlarmEndpointRelationNotFound       = "Alarm endpoint relation does not exists."
+	MsgContextRoleNotFound                 = "Member context role does not exists."
+	MsgOIDCProviderNotFound                = "OpenID Connect provider does not exists."
+	MsgSiteNotFound                        = "Site does not exists."
+	MsgSiteParentNotFound                  = "Parent site does not exists."
+	MsgSiteContainerNotFound               = "Container is not on the site."
+	MsgDashboardNotFound                   = "Dashboard does not exists."
+	MsgDashboardVersionNotFound            = "Dashboard version does not exists."
+	MsgDashboardShareNotFound              = "Dashboard is not shared with the team."
+	MsgScheduledReportNotFound             = "Scheduled report does not exists."
+	MsgScheduledReportRunNotFound          = "Scheduled report run does not exists."
+	MsgScheduledReportFileNotFound         = "Scheduled report run file does not exists."
+	MsgMetricTemplateNotFound              = "Metric template does not exists."
+	MsgMetricTemplateContainerNotFound     = "Container is not linked to the metric template."
 
 	MsgParamsNotSameType     = "Params must have same type. Use only numbers or only text."
 	MsgIdentIsNumber         = "Identification must not be number as text."
 	MsgRequestTimeout        = "Request timeout."
 	MsgMaxDataPolicy         = "Max number of data policies reached."
 	MsgWrongUsernameOrPW     = "Wrong username or password."
+	MsgUserWithoutRole       = "User has no role."
+	MsgUserOfOtherProvider   = "Username is used by another authentication provider."
+	MsgInvalidOIDCState      = "Invalid or expired login state."
+	MsgOIDCLoginFailed       = "Login on the identity provider failed."
 	MsgSessionAlreadyRemoved = "Session already removed."
+	MsgInvalidLoginToken     = "Invalid or expired login token."
+	MsgWrongTOTPCode         = "Wrong TOTP code."
+	MsgTOTPLocked            = "Too many wrong TOTP codes, try again later."
+	MsgTOTPAlreadyEnabled    = "TOTP is already enabled."
+	MsgTOTPNotEnabled        = "TOTP is not enabled."
+	MsgTOTPNotEnrolled       = "TOTP enrollment was not started."
+	MsgTOTPRequired          = "TOTP is required by the authentication policy."
+	MsgWrongPassword         = "Wrong password."
+	MsgPasswordNotLocal      = "Password is managed by the authentication provider."
+	MsgInvalidResetToken     = "Invalid or expired password reset token."
+	MsgPasswordResetDisabled = "Password reset by email is not configured."
+	MsgOutOfAPIKeyScopes     = "Container is out of the API Key scopes."
+	MsgRateLimited           = "Rate limit exceeded, try again later."
+	MsgBodyTooLarge          = "Body too large."
+	MsgRateLimitExists       = "Rate limit of the group, user and API Key already exists."
 	MsgMetricDisabled        = "Metric is not enabled."
 	MsgContainerDisabled     = "Container is not enabled."
 	MsgMetricIsNotAlarmed    = "Metric alarm state is not alarmed."
+	MsgTeamNotManaged        = "User is not an admin of the team."
+	MsgMetricNotOfTeam       = "Metric container is not of the team."
 	MsgMetricIsNotRecognized = "Metric alarm state is not recognized."
+	MsgSiteHasChildren       = "Site has child sites."
+	MsgDashboardOwnerShare   = "Dashboard can't be shared with its owner team."
+	MsgScheduleNeverRuns     = "Schedule never runs."
+	MsgContainerTypeMismatch = "Container type is not the metric template container type."
 
-	MsgInvalidParams     = "Invalid route params."
-	MsgInvalidBody       = "Invalid body."
-	MsgInvalidMetricType = "Invalid metric type."
-	MsgInvalidAggrFn     = "Invalid data-policy aggregation function."
-	MsgInvalidJSONFields = "Invalid JSON fields."
-	MsgInvalidMetricData = "Invalid metric data, could not parse input data to metric type. Check if metric type is correct."
-	MsgInvalidRole       = "Invalid user role."
+	MsgInvalidParams                 = "Invalid route params."
+	MsgInvalidBody                   = "Invalid body."
+	MsgInvalidMetricType             = "Invalid metric type."
+	MsgInvalidAggrFn                 = "Invalid data-policy aggregation function."
+	MsgInvalidAggregate              = "Aggregation function is not stored by the data policy."
+	MsgInvalidDataPolicyTiers        = "Invalid data-policy tiers, retentions and intervals must be increasing and intervals shorter than the previous retention."
+	MsgInvalidJSONFields             = "Invalid JSON fields."
+	MsgInvalidImportHeader           = "Invalid import csv header, the header must have timestamp, value and refkey or metric_id columns."
+	MsgInvalidReportMonth            = "Invalid report month, the month can't be in the future."
+	MsgInvalidReportTier             = "Invalid report tier, the tier does not exists on a metric data policy."
+	MsgInvalidCustomQueryFlux        = "Invalid custom query flux, the flux must read the data variable and only reference declared params."
+	MsgInvalidCustomQueryParams      = "Invalid custom query params."
+	MsgCustomQueryFunctionNotAllowed = "Custom query uses a function that is not allowed."
+	MsgCustomQueryNotSupported       = "Custom queries are not supported by the storage backend."
+	MsgInvalidAPIKeyScope            = "Invalid API Key scope, history scopes can't have a container and ingest scopes can't have a team."
+	MsgInvalidMetricData             = "Invalid metric data, could not parse input data to metric type. Check if metric type is correct."
+	MsgInvalidRole                   = "Invalid user role."
+	MsgInvalidConfig                 = "Invalid configuration document."
+	MsgInvalidSiteParent             = "Invalid site parent, regions can be inside regions, sites inside regions and racks inside sites."
+	MsgInvalidDashboardPanels        = "Invalid dashboard panels, contextual metrics must be of the team contexts and custom queries must exists."
+	MsgInvalidTemplateMetrics        = "Invalid template metrics, names must be unique, ids must be of the template and protocol fields are required by the container type."
+	MsgInvalidContainerType          = "Invalid container type."
 
 	MsgIdentExists                       = "Identification already exists."
 	MsgTargetPortExists                  = "Target and port combination already exists."
@@ -61,6 +117,7 @@ const (
 	MsgTrapRelationExists                = "Trap category already have a relation."
 	MsgTrapListerHostPortExists          = "Trap listener host port already exists."
 	MsgAlarmEndpointRelationExists       = "Alarm endpoint relation already exists."
+	MsgMetricTemplateNameExists          = "Metric template name already exists."
 )
 
 // MsgRes returns an APIResponse with empty data but with the message.
@@ -83,6 +140,13 @@ func DataRes(data any) (response api.APIResponse) {
 	return response
 }
 
+// DataMsgRes returns an APIResponse with the data and the message.
+func DataMsgRes(data any, msg string) (response api.APIResponse) {
+	response.Data = data
+	response.Message = msg
+	return response
+}
+
 // IdRes return and APIResponse with empty message but and id as data.
 func IdRes(id int64) (response api.APIResponse) {
 	response.Data = idMSG{Id: id}
@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// SetProtocolId sets the id of the containers and metrics protocols, which
+// some updates and the services notifications use.
+func SetProtocolId(protocol any, id int64) {
+	switch p := protocol.(type) {
+	case *models.SNMPv2cContainer:
+		p.Id = int32(id)
+	case *models.FlexLegacyContainer:
+		p.Id = int32(id)
+	case *models.SNMPMetric:
+		p.Id = id
+	case *models.FlexLegacyMetric:
+		p.Id = id
+	}
+}
+
+// SaveMetric creates the metric if its id is zero, otherwise updates it, with the
+// create and update functions of the metric protocol. Returns the metric id and
+// the services notification of the change, which must be sent after the commit.
+// Returns false if the updated metric does not exists.
+func SaveMetric[T any](
+	ctx context.Context,
+	api *api.API,
+	m models.Metric[T],
+	create func(context.Context, models.Metric[T]) (int64, error),
+	update func(context.Context, models.Metric[T]) (bool, error),
+) (id int64, exists bool, notify func(), err error) {
+	SetProtocolId(&m.Protocol, m.Base.Id)
+	if m.Base.Id == 0 {
+		id, err = create(ctx, m)
+		if err != nil {
+			return id, false, nil, err
+		}
+		m.Base.Id = id
+		SetProtocolId(&m.Protocol, id)
+		return id, true, func() {
+			t.NotifyMetricCreated(api.Amqph, m.Base, m.Protocol)
+		}, nil
+	}
+	exists, err = update(ctx, m)
+	if err != nil || !exists {
+		return m.Base.Id, false, nil, err
+	}
+	return m.Base.Id, true, func() {
+		t.NotifyMetricUpdated(api.Amqph, m.Base, m.Protocol)
+	}, nil
+}
@@ -3,6 +3,7 @@ package tools
 import (
 	"strconv"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/gin-gonic/gin"
 )
 
@@ -92,3 +93,10 @@ func IntMinQuery(c *gin.Context, key string, defaultValue int, min int) (int, er
 
 	return v, nil
 }
+
+// SelectorQuery returns the label selector of a query value.
+// If query value is "", returns an empty selector.
+// If query value is invalid selector returns an error.
+func SelectorQuery(c *gin.Context, key string) (labels.Selector, error) {
+	return labels.Parse(c.Query(key))
+}
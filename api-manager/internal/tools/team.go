@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/gin-gonic/gin"
+)
+
+// ManagesTeamContainers returns true if the user can manage the containers of
+// the team, which are the admins and the team admins. A zero team id means the
+// containers without team, managed only by the admins.
+func ManagesTeamContainers(api *api.API, c *gin.Context, teamId int32) (ok bool, err error) {
+	meta, err := GetSessionMeta(c)
+	if err != nil {
+		return false, err
+	}
+	if meta.Role >= roles.Admin {
+		return true, nil
+	}
+	if teamId == 0 {
+		return false, nil
+	}
+	exists, role, err := api.PG.GetTeamMemberRole(c.Request.Context(), teamId, meta.UserId, 0)
+	if err != nil {
+		return false, err
+	}
+	return exists && role >= roles.TeamAdmin, nil
+}
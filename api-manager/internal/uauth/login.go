@@ -1,11 +1,13 @@
 package uauth
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/fernandotsda/nemesys/api-manager/internal/api"
 	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
 	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
 	"github.com/fernandotsda/nemesys/shared/env"
 	"github.com/fernandotsda/nemesys/shared/logger"
@@ -17,8 +19,10 @@ import (
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If invalid body fields.
-//   - 404 If username or password is incorrect.
-//   - 200 If succeeded.
+//   - 401 If username or password is incorrect.
+//   - 403 If the LDAP user has no role.
+//   - 409 If the LDAP user email is used by other user.
+//   - 200 If succeeded, with the login token if the TOTP code is required.
 func LoginHandler(api *api.API) func(c *gin.Context) {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -45,32 +49,131 @@ func LoginHandler(api *api.API) func(c *gin.Context) {
 			c.Status(http.StatusInternalServerError)
 			return
 		}
-		if !r.Exists {
+
+		// local accounts are always checked locally, so they keep working
+		// when the LDAP server is unreachable
+		if r.Exists && r.Provider == models.UserProviderLocal {
+			if !auth.CheckHash(form.Password, r.Password) {
+				c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongUsernameOrPW))
+				return
+			}
+			completeLogin(api, c, int32(r.Id), uint8(r.Role), r.TOTPEnabled)
+			return
+		}
+		// LDAP usernames are case insensitive, so the users are provisioned
+		// with the normalized username
+		username := auth.NormalizeUsername(form.Username)
+		if username != form.Username {
+			r, err = api.PG.GetLoginInfo(ctx, username)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				api.Log.Error("Fail to get login info", logger.ErrField(err))
+				c.Status(http.StatusInternalServerError)
+				return
+			}
+		}
+		if api.LDAP == nil || (r.Exists && r.Provider != models.UserProviderLDAP) {
 			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongUsernameOrPW))
 			return
 		}
 
-		if !auth.CheckHash(form.Password, r.Password) {
-			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongUsernameOrPW))
+		user, err := api.LDAP.Authenticate(form.Username, form.Password)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongUsernameOrPW))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to authenticate user on LDAP", logger.ErrField(err))
+			return
+		}
+		if user.Role == roles.Unknown {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgUserWithoutRole))
 			return
 		}
 
-		token, err := api.Auth.NewSession(ctx, auth.SessionMeta{
-			UserId: int32(r.Id),
-			Role:   uint8(r.Role),
-		})
+		id, ok, err := provisionUser(ctx, api, models.UserProviderLDAP, username, user.ExternalUser)
 		if err != nil {
 			if ctx.Err() != nil {
 				return
 			}
+			if errors.Is(err, errEmailExists) {
+				c.JSON(http.StatusConflict, tools.MsgRes(tools.MsgEmailExists))
+				return
+			}
 			c.Status(http.StatusInternalServerError)
-			api.Log.Error("Fail to create user session", logger.ErrField(err))
+			api.Log.Error("Fail to provision LDAP user", logger.ErrField(err))
 			return
 		}
-		ttl, _ := strconv.Atoi(env.UserSessionTTL)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongUsernameOrPW))
+			return
+		}
+		completeLogin(api, c, id, user.Role, r.Exists && r.TOTPEnabled)
+	}
+}
 
-		c.SetCookie(auth.SessionCookieName, token, ttl, "/", env.APIManagerCookieDomain, false, true)
+// completeLogin completes a password login, creating the session or, if the
+// user has TOTP enabled or the authentication policy requires it, the login
+// challenge of the second step.
+func completeLogin(api *api.API, c *gin.Context, userId int32, role uint8, totpEnabled bool) {
+	ctx := c.Request.Context()
 
-		c.JSON(http.StatusOK, tools.EmptyRes())
+	policy, err := api.PG.GetAuthPolicy(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to get authentication policy", logger.ErrField(err))
+		return
+	}
+	if !totpEnabled && !policy.TOTPRequired(role) {
+		if newSession(api, c, userId, role) {
+			c.JSON(http.StatusOK, tools.EmptyRes())
+		}
+		return
 	}
+
+	token, err := api.Auth.NewLoginChallenge(ctx, auth.LoginChallenge{
+		UserId:     userId,
+		Role:       role,
+		Enrollment: !totpEnabled,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to create login challenge", logger.ErrField(err))
+		return
+	}
+	c.JSON(http.StatusOK, tools.DataRes(models.LoginChallenge{
+		Token:          token,
+		TOTPEnrollment: !totpEnabled,
+	}))
+}
+
+// newSession creates a new user session and sets the session cookie. Returns
+// false if fails, with the response already written.
+func newSession(api *api.API, c *gin.Context, userId int32, role uint8) (ok bool) {
+	ctx := c.Request.Context()
+	token, err := api.Auth.NewSession(ctx, auth.SessionMeta{
+		UserId: userId,
+		Role:   role,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to create user session", logger.ErrField(err))
+		return false
+	}
+	ttl, _ := strconv.Atoi(env.UserSessionTTL)
+
+	c.SetCookie(auth.SessionCookieName, token, ttl, "/", env.APIManagerCookieDomain, false, true)
+	return true
 }
@@ -0,0 +1,244 @@
+package uauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+	"github.com/gin-gonic/gin"
+)
+
+// Get the enabled OpenID Connect providers.
+// Responses:
+//   - 200 If succeeded.
+func MGetOIDCProvidersHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		enabled := true
+		providers, err := api.PG.GetOIDCProviders(ctx, pg.OIDCProviderQueryFilters{
+			Enabled:   &enabled,
+			OrderBy:   "name",
+			OrderByFn: "ASC",
+			Limit:     100,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC providers", logger.ErrField(err))
+			return
+		}
+
+		infos := make([]models.OIDCProviderInfo, len(providers))
+		for i, p := range providers {
+			infos[i] = models.OIDCProviderInfo{Ident: p.Ident, Name: p.Name}
+		}
+		c.JSON(http.StatusOK, tools.DataRes(infos))
+	}
+}
+
+// Starts a login on an OpenID Connect provider, redirecting the user to the
+// provider authorization endpoint. The login state is bound to the browser by
+// a short-lived cookie.
+// Params:
+//   - "redirect" Path where the user is redirected after the login. Is optional.
+//
+// Responses:
+//   - 404 If provider does not exists or is disabled.
+//   - 302 If succeeded.
+func OIDCLoginHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		exists, provider, err := api.PG.GetOIDCProviderByIdent(ctx, c.Param("ident"))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC provider", logger.ErrField(err))
+			return
+		}
+		if !exists || !provider.Enabled {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgOIDCProviderNotFound))
+			return
+		}
+
+		client, err := auth.NewOIDC(ctx, provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create OIDC client", logger.ErrField(err))
+			return
+		}
+
+		var tokens [3]string
+		for i := range tokens {
+			tokens[i], err = auth.NewURLToken()
+			if err != nil {
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to create token", logger.ErrField(err))
+				return
+			}
+		}
+		state, nonce, verifier := tokens[0], tokens[1], tokens[2]
+
+		err = api.Auth.SaveOIDCState(ctx, state, auth.OIDCState{
+			Provider: provider.Ident,
+			Verifier: verifier,
+			Nonce:    nonce,
+			Redirect: safeRedirect(c.Query("redirect")),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to save OIDC state", logger.ErrField(err))
+			return
+		}
+
+		c.SetCookie(auth.OIDCStateCookieName, auth.OIDCStateHash(state), int(auth.OIDCStateTTL.Seconds()), "/", env.APIManagerCookieDomain, false, true)
+		c.Redirect(http.StatusFound, client.AuthCodeURL(state, nonce, verifier))
+	}
+}
+
+// Completes a login on an OpenID Connect provider. The user is provisioned on
+// the first login and its role and teams are synced on every login. The state
+// must be the one bound to the browser that started the login.
+// Params:
+//   - "code" The authorization code.
+//   - "state" The login state.
+//
+// Responses:
+//   - 400 If invalid or expired state.
+//   - 400 If the state was not started by the browser.
+//   - 401 If the login on the provider failed.
+//   - 403 If the user has no role.
+//   - 403 If the username is used by another provider.
+//   - 409 If the user email is used by other user.
+//   - 404 If provider does not exists or is disabled.
+//   - 302 If succeeded and the login has a redirect.
+//   - 200 If succeeded.
+func OIDCCallbackHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		stateHash, _ := c.Cookie(auth.OIDCStateCookieName)
+		c.SetCookie(auth.OIDCStateCookieName, "", -1, "/", env.APIManagerCookieDomain, false, true)
+		if subtle.ConstantTimeCompare([]byte(stateHash), []byte(auth.OIDCStateHash(c.Query("state")))) != 1 {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidOIDCState))
+			return
+		}
+
+		s, exists, err := api.Auth.PopOIDCState(ctx, c.Query("state"))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC state", logger.ErrField(err))
+			return
+		}
+		if !exists || s.Provider != c.Param("ident") {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidOIDCState))
+			return
+		}
+		if c.Query("error") != "" || c.Query("code") == "" {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgOIDCLoginFailed))
+			return
+		}
+
+		exists, provider, err := api.PG.GetOIDCProviderByIdent(ctx, s.Provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get OIDC provider", logger.ErrField(err))
+			return
+		}
+		if !exists || !provider.Enabled {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgOIDCProviderNotFound))
+			return
+		}
+
+		client, err := auth.NewOIDC(ctx, provider)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create OIDC client", logger.ErrField(err))
+			return
+		}
+
+		username, user, err := client.Exchange(ctx, c.Query("code"), s.Verifier, s.Nonce)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				api.Log.Debug("OIDC login failed, provider: " + provider.Ident + ", err: " + err.Error())
+				c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgOIDCLoginFailed))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to exchange OIDC code", logger.ErrField(err))
+			return
+		}
+		if user.Role == roles.Unknown {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgUserWithoutRole))
+			return
+		}
+
+		id, ok, err := provisionUser(ctx, api, models.UserProviderOIDC(provider.Ident), username, user)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, errEmailExists) {
+				c.JSON(http.StatusConflict, tools.MsgRes(tools.MsgEmailExists))
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to provision OIDC user", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgUserOfOtherProvider))
+			return
+		}
+
+		if !newSession(api, c, id, user.Role) {
+			return
+		}
+		if s.Redirect != "" {
+			c.Redirect(http.StatusFound, s.Redirect)
+			return
+		}
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// safeRedirect returns the redirect if it is a local path, otherwise returns
+// an empty string, so the login can't redirect to other sites.
+func safeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		return ""
+	}
+	return redirect
+}
@@ -0,0 +1,279 @@
+package uauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Changes the session user password. The session is renewed, so the other
+// sessions and password reset tokens of the user are revoked.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If the password is managed by the authentication provider.
+//   - 400 If the current password is wrong.
+//   - 404 If user does not exists.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func ChangePasswordHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		var form models.PasswordChange
+		err = c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		exists, password, provider, err := api.PG.GetUserPassword(ctx, meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get user password", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+			return
+		}
+		if provider != models.UserProviderLocal {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgPasswordNotLocal))
+			return
+		}
+		if !auth.CheckHash(form.CurrentPassword, password) {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgWrongPassword))
+			return
+		}
+
+		if !updatePassword(api, c, meta.UserId, form.NewPassword) {
+			return
+		}
+		api.Log.Info("User password changed, id: " + strconv.FormatInt(int64(meta.UserId), 10))
+
+		// API Keys clients have no session cookie to renew
+		if meta.APIKeyId != 0 {
+			err = api.Auth.RemoveSession(ctx, meta.UserId)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to remove user session", logger.ErrField(err))
+				return
+			}
+			c.JSON(http.StatusOK, tools.EmptyRes())
+			return
+		}
+		if newSession(api, c, meta.UserId, meta.Role) {
+			c.JSON(http.StatusOK, tools.EmptyRes())
+		}
+	}
+}
+
+// Sends a password reset token to the email of a local user. The response is
+// the same if the email has no user, so the emails can't be enumerated.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If the SMTP server is not configured.
+//   - 200 If succeeded.
+func RequestPasswordResetHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var form models.PasswordResetRequest
+		err := c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+		if api.Mailer == nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgPasswordResetDisabled))
+			return
+		}
+
+		exists, id, username, provider, err := api.PG.GetUserByEmail(ctx, form.Email)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get user by email", logger.ErrField(err))
+			return
+		}
+		if !exists || provider != models.UserProviderLocal {
+			api.Log.Debug("Password reset requested for an email without local user")
+			c.JSON(http.StatusOK, tools.EmptyRes())
+			return
+		}
+
+		token, err := api.Auth.NewPasswordReset(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create password reset token", logger.ErrField(err))
+			return
+		}
+
+		// the email is sent in background, so the response time does not
+		// reveal if the email has a user
+		go func(email string) {
+			err := api.Mailer.Send([]string{email}, "Password reset", passwordResetMessage(username, token, api.Auth.PasswordResetTTL.Minutes()))
+			if err != nil {
+				api.Log.Error("Fail to send password reset email", logger.ErrField(err))
+				return
+			}
+			api.Log.Info("Password reset email sent, user id: " + strconv.FormatInt(int64(id), 10))
+		}(form.Email)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Resets a user password with the token sent by email. The user session is
+// revoked.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If invalid or expired token.
+//   - 404 If user does not exists.
+//   - 200 If succeeded.
+func ResetPasswordHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var form models.PasswordReset
+		err := c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		id, exists, err := api.Auth.PopPasswordReset(ctx, form.Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get password reset token", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidResetToken))
+			return
+		}
+
+		if !updatePassword(api, c, id, form.Password) {
+			return
+		}
+		err = api.Auth.RemoveSession(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to remove user session", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("User password reset, id: " + strconv.FormatInt(int64(id), 10))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// updatePassword hashes and updates the user password and removes the user
+// password reset token. Returns false if fails, with the response already written.
+func updatePassword(api *api.API, c *gin.Context, userId int32, password string) (ok bool) {
+	ctx := c.Request.Context()
+
+	hash, err := auth.Hash(password, api.UserPWBcryptCost)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to hash password", logger.ErrField(err))
+		return false
+	}
+	exists, err := api.PG.UpdateUserPassword(ctx, userId, hash)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to update user password", logger.ErrField(err))
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+		return false
+	}
+	err = api.Auth.RemovePasswordReset(ctx, userId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to remove password reset token", logger.ErrField(err))
+		return false
+	}
+	return true
+}
+
+// passwordResetMessage returns the password reset email body.
+func passwordResetMessage(username string, token string, ttl float64) string {
+	link := token
+	if env.PasswordResetURL != "" {
+		u, err := url.Parse(env.PasswordResetURL)
+		if err == nil {
+			q := u.Query()
+			q.Set("token", token)
+			u.RawQuery = q.Encode()
+			link = u.String()
+		}
+	}
+	return fmt.Sprintf(`A password reset was requested for the user '%s'.
+
+Reset the password with: %s
+
+The reset expires in %.0f minutes and can be used once. If you did not request it, ignore this email.`,
+		username,
+		link,
+		ttl,
+	)
+}
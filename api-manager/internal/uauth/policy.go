@@ -0,0 +1,71 @@
+package uauth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Get the authentication policy.
+// Responses:
+//   - 200 If succeeded.
+func GetPolicyHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		policy, err := api.PG.GetAuthPolicy(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get authentication policy", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(policy))
+	}
+}
+
+// Update the authentication policy. The users that must use TOTP and are not
+// enrolled enroll on the next login.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If json fields are invalid.
+//   - 200 If succeeded.
+func UpdatePolicyHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var policy models.AuthPolicy
+		err := c.ShouldBind(&policy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+
+		err = api.Validate.Struct(policy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		err = api.PG.UpdateAuthPolicy(ctx, policy)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to update authentication policy", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("Authentication policy updated, TOTP required role: " + strconv.Itoa(int(policy.TOTPRequiredRole)))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
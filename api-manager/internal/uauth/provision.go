@@ -0,0 +1,121 @@
+package uauth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/roles"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
+)
+
+// errEmailExists is returned when the provided user email is used by other user.
+var errEmailExists = errors.New("email is used by other user")
+
+// provisionUser creates the user of an external provider on the first login,
+// otherwise updates its attributes and role. Users with a subject are matched
+// by their issuer and subject, so a change of the username only renames the
+// user, otherwise they are matched by the username. Existing users of the
+// provider without a subject are bound to the subject on their next login.
+// The user memberships of the mapped teams are synced. Returns false if the
+// username is used by a user of other provider or subject, and errEmailExists
+// if the email is used by other user.
+func provisionUser(ctx context.Context, api *api.API, provider string, username string, user auth.ExternalUser) (id int32, ok bool, err error) {
+	u := models.User{
+		Role:      user.Role,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Username:  username,
+		Email:     user.Email,
+	}
+	if u.FirstName == "" {
+		u.FirstName = username
+	}
+	// the email is unique, so users without email can't have an empty one
+	if u.Email == "" {
+		u.Email = username + "@" + provider
+	}
+
+	err = api.PG.WithTx(ctx, func(p *pg.PG) error {
+		var r pg.UsersLoginInfoResponse
+		var err error
+		if user.Subject != "" {
+			r, err = p.GetLoginInfoBySubject(ctx, user.Issuer, user.Subject)
+			if err != nil {
+				return err
+			}
+		}
+		bind := false
+		if !r.Exists {
+			r, err = p.GetLoginInfo(ctx, username)
+			if err != nil {
+				return err
+			}
+			if r.Exists && r.Subject != "" {
+				return nil
+			}
+			bind = r.Exists && user.Subject != ""
+		}
+		if r.Exists && r.Provider != provider {
+			return nil
+		}
+		if r.Exists {
+			u.Id = int32(r.Id)
+		}
+		usernameExists, emailExists, err := p.UsernameAndEmailExists(ctx, username, u.Email, u.Id)
+		if err != nil {
+			return err
+		}
+		if usernameExists {
+			return nil
+		}
+		if emailExists {
+			return errEmailExists
+		}
+		ok = true
+		if r.Exists {
+			id = u.Id
+			_, err = p.UpdateUserKeepPW(ctx, u)
+			if err == nil && bind {
+				_, err = p.SetUserSubject(ctx, id, user.Issuer, user.Subject)
+			}
+		} else {
+			id, err = p.CreateProvidedUser(ctx, u, provider, user.Issuer, user.Subject)
+		}
+		if err != nil {
+			return err
+		}
+
+		for team, role := range user.Teams {
+			exists, teamId, err := p.GetTeamIdByIdent(ctx, team)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				api.Log.Warn("Provider group team does not exists, team: " + team)
+				continue
+			}
+			if role == roles.TeamUnknown {
+				_, err = p.RemoveTeamMember(ctx, id, teamId)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			exists, err = p.UpdateTeamMemberRole(ctx, id, teamId, role)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				err = p.AddTeamMember(ctx, id, teamId, role)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	return id, ok, err
+}
@@ -0,0 +1,717 @@
+package uauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/api-manager/internal/api"
+	"github.com/fernandotsda/nemesys/api-manager/internal/auth"
+	"github.com/fernandotsda/nemesys/api-manager/internal/tools"
+	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Completes a login with the TOTP code or a recovery code. If the login
+// requires the TOTP enrollment, the code of the pending secret enables the
+// TOTP and the recovery codes are returned.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If the TOTP enrollment was not started.
+//   - 401 If invalid or expired login token.
+//   - 401 If the code is wrong.
+//   - 429 If the user has too many wrong codes.
+//   - 200 If succeeded.
+func LoginTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var form models.LoginTOTP
+		err := c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		challenge, exists, err := api.Auth.GetLoginChallenge(ctx, form.Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get login challenge", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgInvalidLoginToken))
+			return
+		}
+
+		exists, secret, enabled, err := api.PG.GetUserTOTP(ctx, challenge.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get user TOTP", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgInvalidLoginToken))
+			return
+		}
+		enrolling := !enabled
+		if enrolling {
+			if secret == "" {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPNotEnrolled))
+				return
+			}
+			// there are no recovery codes before the enrollment
+			form.RecoveryCode = ""
+		}
+
+		ok, err := checkCode(ctx, api, challenge.UserId, secret, form.Code, form.RecoveryCode)
+		if err != nil {
+			if errors.Is(err, errTOTPLocked) {
+				c.JSON(http.StatusTooManyRequests, tools.MsgRes(tools.MsgTOTPLocked))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check TOTP code", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			err = api.Auth.FailLoginChallenge(ctx, form.Token)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.Status(http.StatusInternalServerError)
+				api.Log.Error("Fail to update login challenge", logger.ErrField(err))
+				return
+			}
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgWrongTOTPCode))
+			return
+		}
+
+		// the challenge is removed after the code check, so it can't be
+		// completed twice
+		exists, err = api.Auth.RemoveLoginChallenge(ctx, form.Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to remove login challenge", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgInvalidLoginToken))
+			return
+		}
+
+		if !enrolling {
+			if newSession(api, c, challenge.UserId, challenge.Role) {
+				c.JSON(http.StatusOK, tools.EmptyRes())
+			}
+			return
+		}
+
+		codes, ok := enableTOTP(api, c, challenge.UserId)
+		if !ok {
+			return
+		}
+		if newSession(api, c, challenge.UserId, challenge.Role) {
+			c.JSON(http.StatusOK, tools.DataRes(models.RecoveryCodes{RecoveryCodes: codes}))
+		}
+	}
+}
+
+// Starts the TOTP enrollment of a login that requires it.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If TOTP is already enabled.
+//   - 401 If invalid or expired login token.
+//   - 200 If succeeded.
+func LoginTOTPEnrollmentHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		var form models.LoginToken
+		err := c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		challenge, exists, err := api.Auth.GetLoginChallenge(ctx, form.Token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get login challenge", logger.ErrField(err))
+			return
+		}
+		if !exists || !challenge.Enrollment {
+			c.JSON(http.StatusUnauthorized, tools.MsgRes(tools.MsgInvalidLoginToken))
+			return
+		}
+		enrollTOTP(api, c, challenge.UserId)
+	}
+}
+
+// Gets the session user TOTP status.
+// Responses:
+//   - 404 If user does not exists.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func GetTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		exists, _, enabled, err := api.PG.GetUserTOTP(ctx, meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get user TOTP", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+			return
+		}
+		policy, err := api.PG.GetAuthPolicy(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get authentication policy", logger.ErrField(err))
+			return
+		}
+		n, err := api.PG.CountUserRecoveryCodes(ctx, meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to count user recovery codes", logger.ErrField(err))
+			return
+		}
+
+		c.JSON(http.StatusOK, tools.DataRes(models.TOTPStatus{
+			Enabled:       enabled,
+			Required:      policy.TOTPRequired(meta.Role),
+			RecoveryCodes: n,
+		}))
+	}
+}
+
+// Starts the session user TOTP enrollment, returning the secret and its
+// provisioning uri. The TOTP is enabled with the first code.
+// Responses:
+//   - 400 If TOTP is already enabled.
+//   - 404 If user does not exists.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func EnrollTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+		enrollTOTP(api, c, meta.UserId)
+	}
+}
+
+// Enables the session user TOTP with a code of the pending secret, returning
+// the recovery codes.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If TOTP is already enabled.
+//   - 400 If the TOTP enrollment was not started.
+//   - 400 If the code is wrong.
+//   - 404 If user does not exists.
+//   - 429 If the user has too many wrong codes.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func EnableTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		var form models.TOTPCode
+		err = c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		secret, enabled, ok := getUserTOTP(api, c, meta.UserId)
+		if !ok {
+			return
+		}
+		if enabled {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPAlreadyEnabled))
+			return
+		}
+		if secret == "" {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPNotEnrolled))
+			return
+		}
+
+		ok, err = checkCode(ctx, api, meta.UserId, secret, form.Code, "")
+		if err != nil {
+			if errors.Is(err, errTOTPLocked) {
+				c.JSON(http.StatusTooManyRequests, tools.MsgRes(tools.MsgTOTPLocked))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check TOTP code", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgWrongTOTPCode))
+			return
+		}
+
+		codes, ok := enableTOTP(api, c, meta.UserId)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, tools.DataRes(models.RecoveryCodes{RecoveryCodes: codes}))
+	}
+}
+
+// Replaces the session user recovery codes, returning the new ones.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If TOTP is not enabled.
+//   - 400 If the code is wrong.
+//   - 404 If user does not exists.
+//   - 429 If the user has too many wrong codes.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func RegenerateRecoveryCodesHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		var form models.TOTPCode
+		err = c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		secret, enabled, ok := getUserTOTP(api, c, meta.UserId)
+		if !ok {
+			return
+		}
+		if !enabled {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPNotEnabled))
+			return
+		}
+
+		ok, err = checkCode(ctx, api, meta.UserId, secret, form.Code, "")
+		if err != nil {
+			if errors.Is(err, errTOTPLocked) {
+				c.JSON(http.StatusTooManyRequests, tools.MsgRes(tools.MsgTOTPLocked))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check TOTP code", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgWrongTOTPCode))
+			return
+		}
+
+		codes, hashes, err := newRecoveryCodes()
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to create recovery codes", logger.ErrField(err))
+			return
+		}
+		err = api.PG.ReplaceUserRecoveryCodes(ctx, meta.UserId, hashes)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to replace user recovery codes", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("User recovery codes replaced, id: " + strconv.FormatInt(int64(meta.UserId), 10))
+
+		c.JSON(http.StatusOK, tools.DataRes(models.RecoveryCodes{RecoveryCodes: codes}))
+	}
+}
+
+// Disables the session user TOTP with a code or a recovery code.
+// Responses:
+//   - 400 If invalid body.
+//   - 400 If invalid body fields.
+//   - 400 If TOTP is not enabled.
+//   - 400 If the code is wrong.
+//   - 403 If the authentication policy requires TOTP for the user role.
+//   - 404 If user does not exists.
+//   - 429 If the user has too many wrong codes.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func DisableTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		var form models.TOTPCodeOrRecovery
+		err = c.ShouldBind(&form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidBody))
+			return
+		}
+		err = api.Validate.Struct(form)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
+			return
+		}
+
+		secret, enabled, ok := getUserTOTP(api, c, meta.UserId)
+		if !ok {
+			return
+		}
+		if !enabled {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPNotEnabled))
+			return
+		}
+
+		policy, err := api.PG.GetAuthPolicy(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get authentication policy", logger.ErrField(err))
+			return
+		}
+		if policy.TOTPRequired(meta.Role) {
+			c.JSON(http.StatusForbidden, tools.MsgRes(tools.MsgTOTPRequired))
+			return
+		}
+
+		ok, err = checkCode(ctx, api, meta.UserId, secret, form.Code, form.RecoveryCode)
+		if err != nil {
+			if errors.Is(err, errTOTPLocked) {
+				c.JSON(http.StatusTooManyRequests, tools.MsgRes(tools.MsgTOTPLocked))
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to check TOTP code", logger.ErrField(err))
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgWrongTOTPCode))
+			return
+		}
+
+		_, err = api.PG.DisableUserTOTP(ctx, meta.UserId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to disable user TOTP", logger.ErrField(err))
+			return
+		}
+		api.Log.Info("User TOTP disabled, id: " + strconv.FormatInt(int64(meta.UserId), 10))
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// Resets a user TOTP, for users that lost their authenticator and recovery
+// codes. The user enrolls again on the next login if the authentication
+// policy requires TOTP.
+// Responses:
+//   - 400 If invalid id.
+//   - 403 If target's role is superior then the user who requested.
+//   - 404 If user does not exists.
+//   - 200 If succeeded.
+//
+// Keys dependencies:
+//   - "sess_meta" Session metadata.
+func ResetTOTPHandler(api *api.API) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		meta, err := tools.GetSessionMeta(c)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get session metadata", logger.ErrField(err))
+			return
+		}
+
+		rawId := c.Param("userId")
+		id, err := strconv.ParseInt(rawId, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidParams))
+			return
+		}
+
+		exists, role, err := api.PG.GetUserRole(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get user role", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+			return
+		}
+		if uint8(role) > meta.Role {
+			c.Status(http.StatusForbidden)
+			return
+		}
+
+		exists, err = api.PG.DisableUserTOTP(ctx, int32(id))
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to disable user TOTP", logger.ErrField(err))
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+			return
+		}
+		api.Log.Info("User TOTP reset, id: " + rawId)
+
+		c.JSON(http.StatusOK, tools.EmptyRes())
+	}
+}
+
+// getUserTOTP returns the user TOTP secret and enabled state. Returns false if
+// fails, with the response already written.
+func getUserTOTP(api *api.API, c *gin.Context, userId int32) (secret string, enabled bool, ok bool) {
+	ctx := c.Request.Context()
+	exists, secret, enabled, err := api.PG.GetUserTOTP(ctx, userId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", false, false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to get user TOTP", logger.ErrField(err))
+		return "", false, false
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+		return "", false, false
+	}
+	return secret, enabled, true
+}
+
+// enrollTOTP sets a new pending TOTP secret to the user and writes the secret
+// and its provisioning uri.
+func enrollTOTP(api *api.API, c *gin.Context, userId int32) {
+	ctx := c.Request.Context()
+
+	exists, user, err := api.PG.GetUserWithoutPW(ctx, userId)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to get user", logger.ErrField(err))
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, tools.MsgRes(tools.MsgUserNotFound))
+		return
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to create TOTP secret", logger.ErrField(err))
+		return
+	}
+	ok, err := api.PG.SetUserTOTPSecret(ctx, userId, secret)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to set user TOTP secret", logger.ErrField(err))
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPAlreadyEnabled))
+		return
+	}
+
+	c.JSON(http.StatusOK, tools.DataRes(models.TOTPEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPURI(user.Username, secret),
+	}))
+}
+
+// enableTOTP enables the user pending TOTP secret and returns the new recovery
+// codes. Returns false if fails, with the response already written.
+func enableTOTP(api *api.API, c *gin.Context, userId int32) (codes []string, ok bool) {
+	ctx := c.Request.Context()
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to create recovery codes", logger.ErrField(err))
+		return nil, false
+	}
+	ok, err = api.PG.EnableUserTOTP(ctx, userId, hashes)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		c.Status(http.StatusInternalServerError)
+		api.Log.Error("Fail to enable user TOTP", logger.ErrField(err))
+		return nil, false
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgTOTPNotEnrolled))
+		return nil, false
+	}
+	api.Log.Info("User TOTP enabled, id: " + strconv.FormatInt(int64(userId), 10))
+	return codes, true
+}
+
+// errTOTPLocked is returned when the user has too many wrong codes.
+var errTOTPLocked = errors.New("too many wrong TOTP codes")
+
+// checkCode checks the TOTP code, or the recovery code if the code is empty.
+// The code is accepted once and the recovery code is removed. The wrong codes
+// are counted per user and reset by a right one, returning errTOTPLocked
+// without checking the code if the user has too many.
+func checkCode(ctx context.Context, api *api.API, userId int32, secret string, code string, recoveryCode string) (ok bool, err error) {
+	locked, err := api.Auth.TOTPLocked(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	if locked {
+		return false, errTOTPLocked
+	}
+	ok, err = matchCode(ctx, api, userId, secret, code, recoveryCode)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, api.Auth.FailTOTP(ctx, userId)
+	}
+	return true, api.Auth.ResetTOTPFailures(ctx, userId)
+}
+
+// matchCode returns true if the TOTP code or the recovery code matches.
+func matchCode(ctx context.Context, api *api.API, userId int32, secret string, code string, recoveryCode string) (ok bool, err error) {
+	if code == "" {
+		if recoveryCode == "" {
+			return false, nil
+		}
+		return api.PG.UseUserRecoveryCode(ctx, userId, auth.HashRecoveryCode(recoveryCode))
+	}
+	step, ok := auth.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return api.Auth.UseTOTPStep(ctx, userId, step)
+}
+
+// newRecoveryCodes returns new recovery codes and their hashes.
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	codes, err = auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes = make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
@@ -14,10 +14,12 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
-// Creates a API Key.
+// Creates a API Key. The API Key scopes and allowed ips are optional, without
+// scopes the API Key has the same access of the user.
 // Responses:
 //   - 400 If invalid body.
 //   - 400 If json fields are invalid.
+//   - 400 If invalid scope.
 //   - 404 If user not found.
 //   - 200 If succeeded.
 func CreateAPIKeyHandler(api *api.API) func(c *gin.Context) {
@@ -43,6 +45,12 @@ func CreateAPIKeyHandler(api *api.API) func(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidJSONFields))
 			return
 		}
+		for _, s := range apikeyInfo.Scopes {
+			if !auth.ValidScope(s) {
+				c.JSON(http.StatusBadRequest, tools.MsgRes(tools.MsgInvalidAPIKeyScope))
+				return
+			}
+		}
 
 		meta, err := tools.GetSessionMeta(c)
 		if err != nil {
@@ -85,6 +93,8 @@ func CreateAPIKeyHandler(api *api.API) func(c *gin.Context) {
 		apikeyMeta.UserId = int32(userId)
 		apikeyMeta.Role = uint8(role)
 		apikeyMeta.Id = id
+		apikeyMeta.Scopes = apikeyInfo.Scopes
+		apikeyMeta.AllowedIPs = apikeyInfo.AllowedIPs
 		apikey, err := api.Auth.NewAPIKey(ctx, apikeyMeta, time.Duration(apikeyInfo.TTL)*time.Hour)
 		if err != nil {
 			tx.Rollback()
@@ -199,7 +209,7 @@ func DeleteAPIKeyHandler(api *api.API) func(c *gin.Context) {
 
 }
 
-// Deletes a API Key.
+// Get the API Keys of a user, with the last use date.
 // Responses:
 //   - 404 If not found.
 //   - 200 If succeeded.
@@ -269,6 +279,23 @@ func MGetAPIKeyHandler(api *api.API) func(c *gin.Context) {
 			return
 		}
 
+		ids := make([]int16, len(keysAlived))
+		for i, k := range keysAlived {
+			ids[i] = k.Id
+		}
+		lastUsed, err := api.Auth.GetAPIKeysLastUsed(ctx, ids)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Status(http.StatusInternalServerError)
+			api.Log.Error("Fail to get api keys last use", logger.ErrField(err))
+			return
+		}
+		for i := range keysAlived {
+			keysAlived[i].LastUsed = lastUsed[i]
+		}
+
 		c.JSON(http.StatusOK, tools.DataRes(keysAlived))
 	}
 }
@@ -8,17 +8,18 @@ import (
 	"github.com/fernandotsda/nemesys/shared/amqph"
 	t "github.com/fernandotsda/nemesys/shared/amqph/tools"
 	"github.com/fernandotsda/nemesys/shared/env"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/pg"
 	"github.com/fernandotsda/nemesys/shared/service"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/storage/backend"
 	"github.com/rabbitmq/amqp091-go"
 )
 
 type DHS struct {
 	service.Tools
-	// influxClient is the influx client.
-	influxClient *influxdb.Client
+	// storage is the metrics data storage backend.
+	storage storage.Storage
 	// pg is the postgres handler.
 	pg *pg.PG
 	// amqpConn is the amqp connection.
@@ -60,12 +61,18 @@ func New(serviceNumber int) service.Service {
 	}
 	log.Info("Connected to amqp server")
 
-	influxClient, err := influxdb.Connect()
+	storage, err := backend.Connect()
 	if err != nil {
-		log.Panic("Fail to connect to influxdb", logger.ErrField(err))
+		log.Panic("Fail to connect to storage backend", logger.ErrField(err))
+		return nil
+	}
+	log.Info("Connected to storage backend: " + env.StorageBackend)
+
+	err = storage.StartWriteBuffer(tools.ServiceIdent)
+	if err != nil {
+		log.Panic("Fail to start storage write buffer", logger.ErrField(err))
 		return nil
 	}
-	log.Info("Connected to influxdb")
 
 	publishers, err := strconv.Atoi(env.DHSAMQPPublishers)
 	if err != nil {
@@ -78,11 +85,11 @@ func New(serviceNumber int) service.Service {
 		Conn:       amqpConn,
 		Publishers: publishers,
 	})
-	go t.ServicePing(amqph, tools.ServiceIdent)
+	go t.ServicePingWithStatus(amqph, tools.ServiceIdent, storage.PongStatus(tools.ServiceIdent))
 
 	return &DHS{
 		Tools:                    tools,
-		influxClient:             &influxClient,
+		storage:                  storage,
 		pg:                       pg.New(),
 		amqpConn:                 amqpConn,
 		amqph:                    amqph,
@@ -135,7 +142,7 @@ func (d *DHS) Run() {
 
 func (d *DHS) Close() error {
 	d.amqpConn.Close()
-	d.influxClient.Close()
+	d.storage.Close()
 	d.pg.Close()
 	d.DispatchDone(nil)
 	return nil
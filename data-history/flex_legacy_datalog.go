@@ -179,7 +179,7 @@ func (w *flexLegacyDatalogWorker) Run() {
 						continue
 					}
 
-					err = w.dhs.influxClient.WritePoint(ctx, models.MetricDataResponse{
+					err = w.dhs.storage.WritePoint(ctx, models.MetricDataResponse{
 						MetricBasicDataReponse: models.MetricBasicDataReponse{
 							Id:           data.MetricId,
 							Type:         data.MetricType,
@@ -189,7 +189,7 @@ func (w *flexLegacyDatalogWorker) Run() {
 						},
 					}, data.Timestamp)
 					if err != nil {
-						w.dhs.log.Error("Fail to write flex legacy metric datalog data on influxdb", logger.ErrField(err), logField)
+						w.dhs.log.Error("Fail to write flex legacy metric datalog data on storage backend", logger.ErrField(err), logField)
 						continue
 					}
 
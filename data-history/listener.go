@@ -40,7 +40,7 @@ func (d *DHS) metricsDataListener() {
 					continue
 				}
 
-				err = d.influxClient.WritePoint(ctx, models.MetricDataResponse{
+				err = d.storage.WritePoint(ctx, models.MetricDataResponse{
 					ContainerId:            r.ContainerId,
 					MetricBasicDataReponse: m,
 				}, time)
@@ -49,7 +49,7 @@ func (d *DHS) metricsDataListener() {
 					continue
 				}
 			}
-			d.log.Debug("Metrics data saved on influxdb, container id: " + strconv.FormatInt(int64(r.ContainerId), 10))
+			d.log.Debug("Metrics data saved on storage backend, container id: " + strconv.FormatInt(int64(r.ContainerId), 10))
 		case <-done:
 			return
 
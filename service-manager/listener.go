@@ -27,7 +27,7 @@ func (s *ServiceManager) logListener() {
 			if err != nil {
 				continue
 			}
-			s.influxClient.WriteLog(context.Background(), log)
+			s.storage.WriteLog(context.Background(), log)
 		case <-done:
 			return
 		}
@@ -6,6 +6,7 @@ import (
 	"github.com/fernandotsda/nemesys/shared/amqp"
 	"github.com/fernandotsda/nemesys/shared/amqph"
 	"github.com/fernandotsda/nemesys/shared/logger"
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/service"
 	"github.com/fernandotsda/nemesys/shared/uuid"
 	"github.com/rabbitmq/amqp091-go"
@@ -19,7 +20,7 @@ func (s *ServiceManager) pingHandler() {
 		case t := <-ticker.C:
 			for i, serv := range s.services {
 				go func(i int, serv service.ServiceStatus) {
-					online := s.pingService(serv.Ident)
+					online, pong := s.pingService(serv.Ident)
 
 					if !serv.Online && online {
 						s.log.Info("Service is online, ident: " + serv.Ident)
@@ -28,6 +29,7 @@ func (s *ServiceManager) pingHandler() {
 					serv.LastPing = t
 					if online {
 						serv.LostConnectionTime = emptyTime
+						serv.InfluxWriteBuffer = pong.InfluxWriteBuffer
 					}
 					if !online && serv.LostConnectionTime == emptyTime {
 						serv.LostConnectionTime = t
@@ -53,11 +55,11 @@ func (s *ServiceManager) pingHandler() {
 	}
 }
 
-func (s *ServiceManager) pingService(serviceIdent string) (online bool) {
+func (s *ServiceManager) pingService(serviceIdent string) (online bool, pong models.ServicePong) {
 	pingId, err := uuid.New()
 	if err != nil {
 		s.log.Error("Fail to generate new uuid", logger.ErrField(err))
-		return false
+		return false, pong
 	}
 	s.amqph.Publish(amqph.Publish{
 		Exchange:   amqp.ExchangeServicePing,
@@ -66,8 +68,17 @@ func (s *ServiceManager) pingService(serviceIdent string) (online bool) {
 			CorrelationId: pingId,
 		},
 	})
-	_, err = s.pingPlumber.Listen(pingId, s.pingInterval)
-	return err == nil
+	d, err := s.pingPlumber.Listen(pingId, s.pingInterval)
+	if err != nil {
+		return false, pong
+	}
+	if len(d.Body) > 0 {
+		err = amqp.Decode(d.Body, &pong)
+		if err != nil {
+			s.log.Error("Fail to decode amqp body", logger.ErrField(err))
+		}
+	}
+	return true, pong
 }
 
 func (s *ServiceManager) pongHandler() {
@@ -1,6 +1,8 @@
 package manager
 
 import (
+	"context"
+	"fmt"
 	stdlog "log"
 	"strconv"
 	"time"
@@ -8,11 +10,13 @@ import (
 	"github.com/fernandotsda/nemesys/shared/amqp"
 	"github.com/fernandotsda/nemesys/shared/amqph"
 	"github.com/fernandotsda/nemesys/shared/env"
-	"github.com/fernandotsda/nemesys/shared/influxdb"
 	"github.com/fernandotsda/nemesys/shared/initdb"
 	"github.com/fernandotsda/nemesys/shared/logger"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/pg"
 	"github.com/fernandotsda/nemesys/shared/service"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/storage/backend"
 	"github.com/rabbitmq/amqp091-go"
 )
 
@@ -22,8 +26,8 @@ type ServiceManager struct {
 	amqpConn *amqp091.Connection
 	// amqph is the amqp handler
 	amqph *amqph.Amqph
-	// influxClient is the influxdb client.
-	influxClient *influxdb.Client
+	// storage is the metrics data storage backend.
+	storage storage.Storage
 	// log is the log.
 	log *logger.Logger
 	// services is all services registered.
@@ -54,14 +58,20 @@ func Start() {
 	}
 	log.Info("Connected to amqp server")
 
-	influxClient, err := influxdb.Connect()
+	storage, err := backend.Connect()
 	if err != nil {
-		log.Fatal("Fail to connect to influxdb", logger.ErrField(err))
+		log.Fatal("Fail to connect to storage backend", logger.ErrField(err))
 		return
 	}
-	log.Info("Connected to influxdb")
+	log.Info("Connected to storage backend: " + env.StorageBackend)
 
-	created, err := influxClient.CreateLogsBucket()
+	err = storage.StartWriteBuffer("service-manager")
+	if err != nil {
+		log.Fatal("Fail to start storage write buffer", logger.ErrField(err))
+		return
+	}
+
+	created, err := storage.CreateLogsBucket()
 	if err != nil {
 		log.Fatal("Fail to create logs bucket", logger.ErrField(err))
 		return
@@ -70,7 +80,7 @@ func Start() {
 		log.Info("Logs bucket created with success")
 	}
 
-	created, err = influxClient.CreateRequestsCountBucket()
+	created, err = storage.CreateRequestsCountBucket()
 	if err != nil {
 		log.Fatal("Fail to create requets count bucket", logger.ErrField(err))
 		return
@@ -79,7 +89,7 @@ func Start() {
 		log.Info("Requests count bucket created with success")
 	}
 
-	created, err = influxClient.CreateAlarmHistoryBucket()
+	created, err = storage.CreateAlarmHistoryBucket()
 	if err != nil {
 		log.Fatal("Fail to create alarm history bucket", logger.ErrField(err))
 		return
@@ -95,16 +105,16 @@ func Start() {
 	})
 
 	s := ServiceManager{
-		amqpConn:     amqpConn,
-		influxClient: &influxClient,
-		log:          log,
-		services:     make([]service.ServiceStatus, 0),
-		Tools:        service.NewTools(service.ServiceManager, 1),
-		amqph:        amqph,
-		pingPlumber:  *models.NewAMQPPlumber(),
+		amqpConn:    amqpConn,
+		storage:     storage,
+		log:         log,
+		services:    make([]service.ServiceStatus, 0),
+		Tools:       service.NewTools(service.ServiceManager, 1),
+		amqph:       amqph,
+		pingPlumber: *models.NewAMQPPlumber(),
 	}
 
-	initialized, err := initdb.PG()
+	initialized, migrated, err := initdb.PG()
 	if err != nil {
 		log.Fatal("Fail to inicialize postgres", logger.ErrField(err))
 		return
@@ -113,6 +123,15 @@ func Start() {
 		log.Info("Postgres inicialized with success")
 	} else {
 		log.Info("Postgres inicialization skipped")
+		if migrated > 0 {
+			log.Info(fmt.Sprintf("Postgres migrated with success, %d migrations applied", migrated))
+		}
+
+		err = migrateDataPolicies(storage)
+		if err != nil {
+			log.Fatal("Fail to migrate data policies storage", logger.ErrField(err))
+			return
+		}
 	}
 
 	interval, err := strconv.ParseInt(env.ServiceManagerPingInterval, 0, 64)
@@ -133,6 +152,19 @@ func Start() {
 	<-s.Done()
 }
 
+// migrateDataPolicies migrates the storage of the data policies created by older versions.
+func migrateDataPolicies(storage storage.Storage) error {
+	pg := pg.New()
+	defer pg.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	dps, err := pg.GetDataPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	return storage.MigrateDataPolicies(ctx, dps)
+}
+
 func loadEnv() {
 	err := env.LoadEnvFile()
 	if err != nil {
@@ -3,10 +3,18 @@ package tools
 import (
 	"github.com/fernandotsda/nemesys/shared/amqp"
 	"github.com/fernandotsda/nemesys/shared/amqph"
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/rabbitmq/amqp091-go"
 )
 
+// ServicePing listen to service manager pings and responds with an empty pong.
 func ServicePing(a *amqph.Amqph, serviceIdent string) {
+	ServicePingWithStatus(a, serviceIdent, nil)
+}
+
+// ServicePingWithStatus listen to service manager pings and responds with
+// the pong returned by status. Nil status responds with an empty pong.
+func ServicePingWithStatus(a *amqph.Amqph, serviceIdent string, status func() models.ServicePong) {
 	var options amqph.ListenerOptions
 	options.QueueDeclarationOptions.Exclusive = true
 	options.QueueBindOptions.RoutingKey = serviceIdent
@@ -16,11 +24,19 @@ func ServicePing(a *amqph.Amqph, serviceIdent string) {
 	for {
 		select {
 		case d := <-msgs:
+			var body []byte
+			if status != nil {
+				b, err := amqp.Encode(status())
+				if err == nil {
+					body = b
+				}
+			}
 			a.Publish(amqph.Publish{
 				Exchange:   amqp.ExchangeServicePong,
 				RoutingKey: "service-manager",
 				Publishing: amqp091.Publishing{
 					CorrelationId: d.CorrelationId,
+					Body:          body,
 				},
 			})
 		case <-done:
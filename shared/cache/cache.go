@@ -25,6 +25,8 @@ type Cache struct {
 	serverCostExp             time.Duration
 	metricAlarmExpressionsExp time.Duration
 	metricAlarmCategoryExp    time.Duration
+	importJobExp              time.Duration
+	rateLimitPolicyExp        time.Duration
 }
 
 // New returns a prepared Cache struct.
@@ -49,6 +51,8 @@ func New() (cache *Cache, err error) {
 		serverCostExp:             time.Second * 30,
 		metricAlarmExpressionsExp: time.Minute,
 		metricAlarmCategoryExp:    time.Minute * 2,
+		importJobExp:              time.Hour * 24,
+		rateLimitPolicyExp:        time.Minute,
 	}, nil
 }
 
@@ -3,22 +3,28 @@ package cache
 import (
 	"context"
 
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/rdb"
 	"github.com/go-redis/redis/v8"
 )
 
 type GetCustomQueryResponse struct {
+	// Exists is the cache existence.
 	Exists bool
-	Flux   string
+	// CustomQuery is the custom query.
+	CustomQuery models.CustomQuery
 }
 
-func (c *Cache) SetCustomQuery(ctx context.Context, flux string, id int32) error {
-	return c.redis.Set(ctx, rdb.CacheCustomQueryKey(id), flux, c.customQueryExp).Err()
+func (c *Cache) SetCustomQuery(ctx context.Context, cq models.CustomQuery, id int32) error {
+	b, err := c.encode(cq)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, rdb.CacheCustomQueryKey(id), b, c.customQueryExp).Err()
 }
 
 func (c *Cache) GetCustomQuery(ctx context.Context, id int32) (r GetCustomQueryResponse, err error) {
-	cmd := c.redis.Get(ctx, rdb.CacheCustomQueryKey(id))
-	err = cmd.Err()
+	b, err := c.redis.Get(ctx, rdb.CacheCustomQueryKey(id)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return r, nil
@@ -26,16 +32,19 @@ func (c *Cache) GetCustomQuery(ctx context.Context, id int32) (r GetCustomQueryR
 		return r, err
 	}
 	r.Exists = true
-	return r, cmd.Scan(&r.Flux)
+	return r, c.decode(b, &r.CustomQuery)
 }
 
-func (c *Cache) SetCustomQueryByIdent(ctx context.Context, flux string, ident string) error {
-	return c.redis.Set(ctx, rdb.CacheCustomQueryByIdentKey(ident), flux, c.customQueryExp).Err()
+func (c *Cache) SetCustomQueryByIdent(ctx context.Context, cq models.CustomQuery, ident string) error {
+	b, err := c.encode(cq)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, rdb.CacheCustomQueryByIdentKey(ident), b, c.customQueryExp).Err()
 }
 
 func (c *Cache) GetCustomQueryByIdent(ctx context.Context, ident string) (r GetCustomQueryResponse, err error) {
-	cmd := c.redis.Get(ctx, rdb.CacheCustomQueryByIdentKey(ident))
-	err = cmd.Err()
+	b, err := c.redis.Get(ctx, rdb.CacheCustomQueryByIdentKey(ident)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return r, nil
@@ -43,5 +52,5 @@ func (c *Cache) GetCustomQueryByIdent(ctx context.Context, ident string) (r GetC
 		return r, err
 	}
 	r.Exists = true
-	return r, cmd.Scan(&r.Flux)
+	return r, c.decode(b, &r.CustomQuery)
 }
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/rdb"
+	"github.com/go-redis/redis/v8"
+)
+
+type GetImportJobResponse struct {
+	// Exists is the cache existence.
+	Exists bool
+	// Job is the import job.
+	Job models.ImportJob
+}
+
+func (c *Cache) SetImportJob(ctx context.Context, job models.ImportJob) error {
+	b, err := c.encode(job)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, rdb.CacheImportJobKey(job.Id), b, c.importJobExp).Err()
+}
+
+func (c *Cache) GetImportJob(ctx context.Context, id string) (r GetImportJobResponse, err error) {
+	b, err := c.redis.Get(ctx, rdb.CacheImportJobKey(id)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return r, nil
+		}
+		return r, err
+	}
+	r.Exists = true
+	return r, c.decode(b, &r.Job)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/rdb"
 	"github.com/go-redis/redis/v8"
 )
@@ -22,3 +23,57 @@ func (c *Cache) GetUserLimited(ctx context.Context, ip string, route string) (su
 func (c *Cache) SetUserLimited(ctx context.Context, ip string, route string, duration time.Duration) (err error) {
 	return c.redis.Set(ctx, rdb.CacheUserLimited(ip, route), nil, duration).Err()
 }
+
+type GetRateLimitPolicyResponse struct {
+	// Exists is the cache existence.
+	Exists bool
+	// Policy is the rate limit policy.
+	Policy models.RateLimitPolicy
+}
+
+func (c *Cache) SetRateLimitPolicy(ctx context.Context, group string, userId int32, apikeyId int32, policy models.RateLimitPolicy) (err error) {
+	b, err := c.encode(policy)
+	if err != nil {
+		return err
+	}
+	return c.redis.Set(ctx, rdb.CacheRateLimitPolicyKey(group, userId, apikeyId), b, c.rateLimitPolicyExp).Err()
+}
+
+func (c *Cache) GetRateLimitPolicy(ctx context.Context, group string, userId int32, apikeyId int32) (r GetRateLimitPolicyResponse, err error) {
+	b, err := c.redis.Get(ctx, rdb.CacheRateLimitPolicyKey(group, userId, apikeyId)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return r, nil
+		}
+		return r, err
+	}
+	r.Exists = true
+	return r, c.decode(b, &r.Policy)
+}
+
+// incrRateLimit increments the window counter, starting the window on the
+// first request. Returns the counter and the window remaining time in
+// milliseconds.
+var incrRateLimit = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// IncrRateLimit counts a request of the client on the route group window.
+// Returns the number of requests on the window and the time until the
+// window reset.
+func (c *Cache) IncrRateLimit(ctx context.Context, group string, client string, window time.Duration) (count int64, reset time.Duration, err error) {
+	values, err := incrRateLimit.Run(ctx, c.redis, []string{rdb.CacheRateLimitCounterKey(group, client)}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, 0, err
+	}
+	return values[0], time.Duration(values[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,202 @@
+// Package cron parses cron schedules of five fields, minute, hour, day of month,
+// month and day of week, evaluated in UTC.
+package cron
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxScheduleLength is the maximum schedule length.
+const MaxScheduleLength = 100
+
+// maxSearchYears is how many years Next searches for a matching time.
+const maxSearchYears = 5
+
+var ErrInvalidSchedule = errors.New("invalid cron schedule")
+
+// macros are the schedules shorthands.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type field struct {
+	min   int
+	max   int
+	names map[string]int
+}
+
+var fields = [5]field{
+	{min: 0, max: 59},
+	{min: 0, max: 23},
+	{min: 1, max: 31},
+	{min: 1, max: 12, names: monthNames},
+	// 7 is also sunday
+	{min: 0, max: 7, names: dayNames},
+}
+
+// Schedule is a parsed cron schedule. Each field is a bit set of the
+// matching values.
+type Schedule struct {
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	// domAll and dowAll are true if the day of month or the day of week
+	// is "*". If both are restricted, a day matches any of them.
+	domAll bool
+	dowAll bool
+}
+
+// Parse parses the schedule, like "30 6 * * mon-fri", "*/15 * * * *" or
+// "@daily". Each field accepts "*", values, ranges, lists and steps. Months
+// and days of week accept the first three letters of the names.
+func Parse(spec string) (s Schedule, err error) {
+	if len(spec) > MaxScheduleLength {
+		return s, ErrInvalidSchedule
+	}
+	spec = strings.TrimSpace(spec)
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		spec = m
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return s, ErrInvalidSchedule
+	}
+	sets := [5]uint64{}
+	for i, p := range parts {
+		sets[i], err = parseField(strings.ToLower(p), fields[i])
+		if err != nil {
+			return s, err
+		}
+	}
+
+	// day of week 7 is sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return Schedule{
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAll: parts[2] == "*",
+		dowAll: parts[4] == "*",
+	}, nil
+}
+
+// Valid returns true if the schedule is valid.
+func Valid(spec string) bool {
+	_, err := Parse(spec)
+	return err == nil
+}
+
+func parseField(s string, f field) (set uint64, err error) {
+	for _, item := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return 0, ErrInvalidSchedule
+			}
+		}
+
+		var start, end int
+		if rangePart == "*" {
+			start, end = f.min, f.max
+		} else {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			start, err = parseValue(first, f)
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				end, err = parseValue(last, f)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// "a/n" is from a to the field max
+				end = f.max
+			}
+		}
+		if start > end {
+			return 0, ErrInvalidSchedule
+		}
+		for v := start; v <= end; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	if v, ok := f.names[s]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, ErrInvalidSchedule
+	}
+	return v, nil
+}
+
+// Next returns the first time after t that matches the schedule, in UTC.
+// Returns the zero time if no time matches in the next years, like on
+// "0 0 30 2 *".
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAll || s.dowAll {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,76 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec  string
+		valid bool
+	}{
+		{"* * * * *", true},
+		{"30 6 * * mon-fri", true},
+		{"*/15 * * * *", true},
+		{"0 8 1,15 * *", true},
+		{"0 0 * jan-mar,dec sun", true},
+		{"5/10 * * * *", true},
+		{"0 0 * * 7", true},
+		{"@weekly", true},
+		{"@Daily", true},
+		{"", false},
+		{"* * * *", false},
+		{"* * * * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"10-5 * * * *", false},
+		{"*/0 * * * *", false},
+		{"a * * * *", false},
+		{"@every", false},
+	}
+	for _, test := range tests {
+		got := Valid(test.spec)
+		if got != test.valid {
+			t.Errorf("Valid(%q) failed, want: %v, got: %v", test.spec, test.valid, got)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2023-03-15 is a wednesday
+	now := time.Date(2023, 3, 15, 10, 20, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2023, 3, 15, 10, 21, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2023, 3, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 6 * * *", time.Date(2023, 3, 16, 6, 30, 0, 0, time.UTC)},
+		{"0 8 * * mon", time.Date(2023, 3, 20, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 0", time.Date(2023, 3, 19, 8, 0, 0, 0, time.UTC)},
+		{"0 8 * * 7", time.Date(2023, 3, 19, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{"0 0 1 * fri", time.Date(2023, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) failed, err: %s", test.spec, err)
+			continue
+		}
+		got := s.Next(now)
+		if !got.Equal(test.want) {
+			t.Errorf("Next of %q failed, want: %v, got: %v", test.spec, test.want, got)
+		}
+	}
+}
@@ -85,6 +85,32 @@ var (
 	InfluxTLSCertFilePath = ""
 	// InfluxTLSKeyFilePath is the path for the TLS key file. Default is "".
 	InfluxTLSKeyFilePath = ""
+	// InfluxWriteBufferDir is the directory where writes are buffered while influxdb is unreachable.
+	// Empty value disables the buffer. Default is "influx-write-buffer".
+	InfluxWriteBufferDir = "influx-write-buffer"
+	// InfluxWriteBufferMaxSize is the maximum size of the write buffer in megabytes. Default is "256".
+	InfluxWriteBufferMaxSize = "256"
+	// InfluxWriteBufferMaxAge is the maximum age of a buffered write in hours. Default is "24".
+	InfluxWriteBufferMaxAge = "24"
+	// InfluxWriteBufferFlushInterval is the interval between buffer flush attempts in seconds. Default is "10".
+	InfluxWriteBufferFlushInterval = "10"
+
+	// StorageBackend is the metrics data storage backend, "influxdb" or "timescaledb". Default is "influxdb".
+	StorageBackend = "influxdb"
+	// TimescaleHost is the timescaledb host. Default is "127.0.0.1".
+	TimescaleHost = "127.0.0.1"
+	// TimescalePort is the timescaledb port. Default is "5432".
+	TimescalePort = "5432"
+	// TimescaleUsername is the timescaledb username. Default is "postgres".
+	TimescaleUsername = "postgres"
+	// TimescalePassword is the timescaledb password. Default is "postgres".
+	TimescalePassword = "postgres"
+	// TimescaleDBName is the timescaledb database name. Default is "nemesys_metrics".
+	TimescaleDBName = "nemesys_metrics"
+	// TimescaleMaxOpenConn is the maximum number of open connections. Default is "6".
+	TimescaleMaxOpenConn = "6"
+	// TimescaleWriteInterval is the interval between batched writes in milliseconds. Default is "1000".
+	TimescaleWriteInterval = "1000"
 
 	// RDBAuthHost is redis for auth host. Default is "localhost".
 	RDBAuthHost = "localhost"
@@ -114,6 +140,9 @@ var (
 	APIManagerCookieDomain = "localhost"
 	// APIManagerAllowOrigins is the allowed origins for CORS. Default is "http://localhost:5173;https://nemesys.cloud".
 	APIManagerAllowOrigins = "http://localhost:5173;https://nemesys.cloud"
+	// APIManagerTrustedProxies is the ips and cidrs of the proxies trusted to set the client ip
+	// headers, like "X-Forwarded-For", separated by ";". Default is "", no proxy is trusted.
+	APIManagerTrustedProxies = ""
 
 	// UserSessionTTL is the user session TTL (time to live) in secods. Default is "604900" (one week).
 	UserSessionTTL = "604800"
@@ -123,6 +152,61 @@ var (
 	// to each machine config, the recommended is to set a cost
 	// that makes '/login' route takes around 200ms. Default is "11".
 	UserPWBcryptCost = "11"
+	// PasswordResetTTL is the password reset token TTL (time to live) in seconds. Default is "1800".
+	PasswordResetTTL = "1800"
+	// PasswordResetURL is the url of the password reset page sent on the reset emails, the
+	// token is added as the "token" query param. The token is sent alone if empty. Default is "".
+	PasswordResetURL = ""
+
+	// RateLimitWindow is the window in seconds of the default rate limits. Default is "60".
+	RateLimitWindow = "60"
+	// RateLimitDefault is the default max number of requests of a user or API Key on each window,
+	// on the routes without a specific group. Zero for unlimited. Default is "600".
+	RateLimitDefault = "600"
+	// RateLimitRealtime is the default max number of requests of a user or API Key on each window,
+	// on the realtime data routes. Zero for unlimited. Default is "120".
+	RateLimitRealtime = "120"
+	// RateLimitHistory is the default max number of requests of a user or API Key on each window,
+	// on the data history and export routes. Zero for unlimited. Default is "60".
+	RateLimitHistory = "60"
+	// RateLimitIngest is the default max number of requests of a user or API Key on each window,
+	// on the metric data ingestion routes. Zero for unlimited. Default is "6000".
+	RateLimitIngest = "6000"
+
+	// LDAPURL is the LDAP server url, as "ldap://host:389" or "ldaps://host:636". The LDAP
+	// authentication is disabled if empty. Default is "".
+	LDAPURL = ""
+	// LDAPStartTLS is the use of StartTLS on "ldap://" urls. Default is "false".
+	LDAPStartTLS = "false"
+	// LDAPTLSSkipVerify is the skip of the LDAP server certificate verification. Default is "false".
+	LDAPTLSSkipVerify = "false"
+	// LDAPTimeout is the LDAP requests timeout in seconds. Default is "5".
+	LDAPTimeout = "5"
+	// LDAPBindDN is the DN used to search the users. Default is "".
+	LDAPBindDN = ""
+	// LDAPBindPassword is the password of the bind DN. Default is "".
+	LDAPBindPassword = ""
+	// LDAPBaseDN is the DN where the users are searched. Default is "".
+	LDAPBaseDN = ""
+	// LDAPUserFilter is the users search filter, "%s" is replaced by the escaped username.
+	// Default is "(sAMAccountName=%s)".
+	LDAPUserFilter = "(sAMAccountName=%s)"
+	// LDAPFirstNameAttribute is the user first name attribute. Default is "givenName".
+	LDAPFirstNameAttribute = "givenName"
+	// LDAPLastNameAttribute is the user last name attribute. Default is "sn".
+	LDAPLastNameAttribute = "sn"
+	// LDAPEmailAttribute is the user email attribute. Default is "mail".
+	LDAPEmailAttribute = "mail"
+	// LDAPGroupAttribute is the user attribute with the DNs of the user groups. Default is "memberOf".
+	LDAPGroupAttribute = "memberOf"
+	// LDAPRolesGroups maps the groups to the users roles, in the format "role:groupDN;role:groupDN",
+	// where role is 1 (viewer), 2 (teams manager), 3 (admin) or 4 (master). The highest role of
+	// the user groups is used and users without roles can't login. Default is "".
+	LDAPRolesGroups = ""
+	// LDAPTeamsGroups maps the groups to the teams memberships, in the format
+	// "teamIdent:teamRole:groupDN;teamIdent:teamRole:groupDN", where teamRole is 1 (viewer),
+	// 2 (operator) or 3 (admin). Default is "".
+	LDAPTeamsGroups = ""
 
 	// DHSFlexLegacyDatalogWorkers is the number of flex-legacy datalog workers. Default is "3".
 	DHSFlexLegacyDatalogWorkers = "3"
@@ -148,6 +232,8 @@ var (
 	AlarmHistoryBucketRetention = "168" // 7 days
 	// LogsBucketRetention is the retention in houts of the logs bucket. Default is "168".
 	LogsBucketRetention = "168" // 7 days
+	// AuditLogRetention is the retention in hours of the audit log entries. Default is "2160".
+	AuditLogRetention = "2160" // 90 days
 
 	// AlarmServiceAMQPPublishers is the number of amqp publishers, which means number
 	// of socket channels openned. Default is "1".
@@ -83,6 +83,19 @@ func Init() {
 	set("INFLUX_TOKEN", &InfluxToken)
 	set("INFLUX_TLS_CERT_FILE_PATH", &InfluxTLSCertFilePath)
 	set("INFLUX_TLS_KEY_FILE_PATH", &InfluxTLSKeyFilePath)
+	set("INFLUX_WRITE_BUFFER_DIR", &InfluxWriteBufferDir)
+	set("INFLUX_WRITE_BUFFER_MAX_SIZE", &InfluxWriteBufferMaxSize)
+	set("INFLUX_WRITE_BUFFER_MAX_AGE", &InfluxWriteBufferMaxAge)
+	set("INFLUX_WRITE_BUFFER_FLUSH_INTERVAL", &InfluxWriteBufferFlushInterval)
+
+	set("STORAGE_BACKEND", &StorageBackend)
+	set("TIMESCALE_HOST", &TimescaleHost)
+	set("TIMESCALE_PORT", &TimescalePort)
+	set("TIMESCALE_USERNAME", &TimescaleUsername)
+	set("TIMESCALE_PASSWORD", &TimescalePassword)
+	set("TIMESCALE_DB_NAME", &TimescaleDBName)
+	set("TIMESCALE_MAX_OPEN_CONN", &TimescaleMaxOpenConn)
+	set("TIMESCALE_WRITE_INTERVAL", &TimescaleWriteInterval)
 
 	set("RDB_AUTH_HOST", &RDBAuthHost)
 	set("RDB_AUTH_PORT", &RDBAuthPort)
@@ -99,10 +112,34 @@ func Init() {
 	set("API_MANAGER_ROUTES_PREFIX", &APIManagerRoutesPrefix)
 	set("API_COOKIE_DOMAIN", &APIManagerCookieDomain)
 	set("API_MANAGE_ALLOW_ORIGINS", &APIManagerAllowOrigins)
+	set("API_MANAGER_TRUSTED_PROXIES", &APIManagerTrustedProxies)
 
 	set("USER_SESSION_TTL", &UserSessionTTL)
 	set("USER_SESSION_TOKEN_SIZE", &UserSessionTokenSize)
 	set("USER_PW_BCRYPT_COST", &UserPWBcryptCost)
+	set("PASSWORD_RESET_TTL", &PasswordResetTTL)
+	set("PASSWORD_RESET_URL", &PasswordResetURL)
+
+	set("RATE_LIMIT_WINDOW", &RateLimitWindow)
+	set("RATE_LIMIT_DEFAULT", &RateLimitDefault)
+	set("RATE_LIMIT_REALTIME", &RateLimitRealtime)
+	set("RATE_LIMIT_HISTORY", &RateLimitHistory)
+	set("RATE_LIMIT_INGEST", &RateLimitIngest)
+
+	set("LDAP_URL", &LDAPURL)
+	set("LDAP_START_TLS", &LDAPStartTLS)
+	set("LDAP_TLS_SKIP_VERIFY", &LDAPTLSSkipVerify)
+	set("LDAP_TIMEOUT", &LDAPTimeout)
+	set("LDAP_BIND_DN", &LDAPBindDN)
+	set("LDAP_BIND_PASSWORD", &LDAPBindPassword)
+	set("LDAP_BASE_DN", &LDAPBaseDN)
+	set("LDAP_USER_FILTER", &LDAPUserFilter)
+	set("LDAP_FIRST_NAME_ATTRIBUTE", &LDAPFirstNameAttribute)
+	set("LDAP_LAST_NAME_ATTRIBUTE", &LDAPLastNameAttribute)
+	set("LDAP_EMAIL_ATTRIBUTE", &LDAPEmailAttribute)
+	set("LDAP_GROUP_ATTRIBUTE", &LDAPGroupAttribute)
+	set("LDAP_ROLES_GROUPS", &LDAPRolesGroups)
+	set("LDAP_TEAMS_GROUPS", &LDAPTeamsGroups)
 
 	set("DHS_FLEX_LEGACY_DATALOG_WORKERS", &DHSFlexLegacyDatalogWorkers)
 	set("DHS_FLEX_LEGACY_DATALOG_REQUEST_INTERVAL", &DHSFlexLegacyDatlogRequestInterval)
@@ -116,6 +153,7 @@ func Init() {
 	set("ALARM_HISTORY_BUCKET_RETENTION", &AlarmHistoryBucketRetention)
 	set("REQUESTS_COUNT_BUCKET_RETENTION", &RequestsCountBucketRetention)
 	set("LOGS_BUCKET_RETENTION", &LogsBucketRetention)
+	set("AUDIT_LOG_RETENTION", &AuditLogRetention)
 
 	set("ALARM_SERVICE_AMQP_PUBLISHERS", &AlarmServiceAMQPPublishers)
 	set("API_MANAGER_AMQP_PUBLISHERS", &APIManagerAMQPPublishers)
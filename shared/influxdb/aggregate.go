@@ -3,63 +3,108 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
 	iapi "github.com/influxdata/influxdb-client-go/v2/api"
 )
 
-var aggrFns = []string{"mean", "median", "max", "min", "sum", "derivative", "nonnegative derivative", "distinct", "count", "increase", "skew", "spread", "stddev", "first", "last", "unique", "sort"}
+// getAggrFnFlux returns the flux of the aggregation function.
+func getAggrFnFlux(fn string) string {
+	if q, ok := storage.ParsePercentile(fn); ok {
+		return fmt.Sprintf("(column, tables=<-) => tables |> quantile(q: %g, column: column)", q)
+	}
+	return fn
+}
 
-// ValidateAggrFunction validates the aggregation function.
-func ValidateAggrFunction(fn string) (valid bool) {
-	for _, v := range aggrFns {
-		if v == fn {
-			return true
-		}
+// getAggrField returns the field name where an aggregation function is stored on the
+// tiers. The primary function is stored on the metric type field, while the additional
+// functions are stored on the field suffixed by the function name.
+func getAggrField(mt types.MetricType, fn string) string {
+	if fn == "" {
+		return getField(mt)
 	}
-	return false
+	return getField(mt) + "-" + fn
 }
 
-// getTaskName returns a task name for a data policy id.
-func getTaskName(dataPolicyId int16) string {
-	return fmt.Sprintf("%d-aggr-task", dataPolicyId)
+// getTaskName returns a task name for a data policy tier.
+func getTaskName(dataPolicyId int16, tier int) string {
+	return fmt.Sprintf("%d-aggr-%d-task", dataPolicyId, tier)
 }
 
-// getTaskFlux returns the task flux.
-func getTaskFlux(dp models.DataPolicy) string {
-	return fmt.Sprintf(`
-		option task = {name: "%s", every: %s, offset: %s}
+// getTaskFlux returns the flux of the task that aggregates the data of the
+// previous tier into the tier, right before it expires on the previous tier.
+// Each aggregation function is written on its own field.
+func getTaskFlux(dp models.DataPolicy, tier int) string {
+	t := dp.Tiers[tier-1]
+	sourceRetention := dp.Retention * 3600
+	if tier > 1 {
+		sourceRetention = dp.Tiers[tier-2].Retention * 3600
+	}
+
+	flux := fmt.Sprintf(`
+		option task = {name: "%s", every: %ds}
 		data = from(bucket: "%s")
-			|> range(start: -%s, stop: -%s)
-			|> filter(fn: (r) => r._measurement == "metrics")
-		data
-			|> aggregateWindow(every: %ds, fn: %s, createEmpty: false)
-			|> to(bucket: "%s")`,
-		getTaskName(dp.Id),
-		fmt.Sprintf("%ds", dp.AggrInterval),
-		fmt.Sprintf("%ds", dp.Retention*3600),
-		GetBucketName(dp.Id, false),
-		fmt.Sprintf("%ds", dp.Retention*3600),
-		fmt.Sprintf("%ds", dp.Retention*3600-dp.AggrInterval),
-		dp.AggrInterval,
-		dp.AggrFn,
-		GetBucketName(dp.Id, true),
+			|> range(start: -%ds, stop: -%ds)
+			|> filter(fn: (r) => r._measurement == "metrics")`,
+		getTaskName(dp.Id, tier),
+		t.Interval,
+		GetBucketName(dp.Id, tier-1),
+		sourceRetention,
+		sourceRetention-t.Interval,
 	)
+
+	fns := append([]string{""}, dp.AggrFns...)
+	for _, fn := range fns {
+		aggrFn := fn
+		if fn == "" {
+			aggrFn = dp.AggrFn
+		}
+
+		// raw data has only the metric type fields
+		sourceFn := fn
+		if tier == 1 {
+			sourceFn = ""
+		}
+
+		filters := make([]string, len(fieldsMetricTypes))
+		for i, mt := range fieldsMetricTypes {
+			filters[i] = fmt.Sprintf(`r._field == "%s"`, getAggrField(mt, sourceFn))
+		}
+
+		flux += fmt.Sprintf(`
+		data
+			|> filter(fn: (r) => %s)
+			|> aggregateWindow(every: %ds, fn: %s, createEmpty: false)`,
+			strings.Join(filters, " or "),
+			t.Interval,
+			getAggrFnFlux(aggrFn),
+		)
+		if sourceFn != fn {
+			flux += fmt.Sprintf(`
+			|> map(fn: (r) => ({r with _field: r._field + "-%s"}))`, fn)
+		}
+		flux += fmt.Sprintf(`
+			|> to(bucket: "%s")`, GetBucketName(dp.Id, tier))
+	}
+	return flux
 }
 
-// createAggrTask creates an task of data aggregation.
-func (c *Client) createAggrTask(ctx context.Context, dp models.DataPolicy) (err error) {
+// createAggrTask creates the data aggregation task of a tier.
+func (c *Client) createAggrTask(ctx context.Context, dp models.DataPolicy, tier int) (err error) {
 	api := c.TasksAPI()
-	_, err = api.CreateTaskByFlux(ctx, getTaskFlux(dp), *c.DefaultOrg.Id)
+	_, err = api.CreateTaskByFlux(ctx, getTaskFlux(dp, tier), *c.DefaultOrg.Id)
 	return err
 }
 
-// updateAggrTask updates a task of data aggregation.
-func (c *Client) updateAggrTask(ctx context.Context, dp models.DataPolicy) (err error) {
+// updateAggrTask updates the data aggregation task of a tier.
+func (c *Client) updateAggrTask(ctx context.Context, dp models.DataPolicy, tier int) (err error) {
 	api := c.TasksAPI()
 	// find task
 	filter := iapi.TaskFilter{
-		Name:  getTaskName(dp.Id),
+		Name:  getTaskName(dp.Id, tier),
 		OrgID: *c.DefaultOrg.Id,
 		Limit: 1,
 	}
@@ -71,18 +116,23 @@ func (c *Client) updateAggrTask(ctx context.Context, dp models.DataPolicy) (err
 		return ErrTaskNotFound
 	}
 	task := tasks[0]
-	task.Flux = getTaskFlux(dp)
+	task.Flux = getTaskFlux(dp, tier)
 	_, err = api.UpdateTask(ctx, &task)
 	return err
 }
 
-// deleteAggrTask deletes a task of data aggregation.
-func (c *Client) deleteAggrTask(ctx context.Context, id int16) (err error) {
+// deleteAggrTask deletes the data aggregation task of a tier.
+func (c *Client) deleteAggrTask(ctx context.Context, id int16, tier int) (err error) {
+	return c.deleteTask(ctx, getTaskName(id, tier))
+}
+
+// deleteTask deletes a task by name.
+func (c *Client) deleteTask(ctx context.Context, name string) (err error) {
 	api := c.TasksAPI()
 
 	// find task
 	filter := iapi.TaskFilter{
-		Name:  getTaskName(id),
+		Name:  name,
 		OrgID: *c.DefaultOrg.Id,
 		Limit: 1,
 	}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/fernandotsda/nemesys/shared/env"
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 )
 
@@ -15,20 +16,6 @@ const (
 	alarmHistoryMeasurementName = "history"
 )
 
-type QueryAlarmHistoryOptions struct {
-	// Start is the range start in seconds.
-	Start int64
-	// Stop is the range stop in seconds.
-	Stop int64
-	// ContianerId is the container identifier.
-	ContainerId int32
-	// MetricId is the metric identfier.
-	MetricId int64
-	// Level is the alarm category level. Nil value
-	// means get from all levels
-	Level *int32
-}
-
 // CreateAlarmHistoryBucket creates an alarm history bucket with
 // the retention configurated in the enviroment.
 func (c *Client) CreateAlarmHistoryBucket() (cteated bool, err error) {
@@ -66,10 +53,10 @@ func (c *Client) WriteAlarmOccurency(occurency models.AlarmOccurency) {
 	p.AddField("value", occurency.Value)
 	p.AddField("level", occurency.Category.Level)
 
-	c.WriteAPI(*c.DefaultOrg.Id, alarmHistoryBucketName).WritePoint(p)
+	c.writeAPI(alarmHistoryBucketName).WritePoint(p)
 }
 
-func (c *Client) QueryAlarmHistory(ctx context.Context, options QueryAlarmHistoryOptions) (points [][3]any, err error) {
+func (c *Client) QueryAlarmHistory(ctx context.Context, options storage.QueryAlarmHistoryOptions) (points [][3]any, err error) {
 	api := c.QueryAPI(*c.DefaultOrg.Id)
 
 	query := getAlarmHistoryQuery(options)
@@ -86,7 +73,7 @@ func (c *Client) QueryAlarmHistory(ctx context.Context, options QueryAlarmHistor
 	return points, nil
 }
 
-func getAlarmHistoryQuery(options QueryAlarmHistoryOptions) string {
+func getAlarmHistoryQuery(options storage.QueryAlarmHistoryOptions) string {
 	var startS string = strconv.FormatInt(options.Start, 10)
 	var stopS string
 
@@ -0,0 +1,414 @@
+package influxdb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/influxdata/influxdb-client-go/v2/api"
+	http2 "github.com/influxdata/influxdb-client-go/v2/api/http"
+)
+
+const bufferSegmentExt = ".lp"
+
+// writeBuffer is an on-disk write-ahead buffer for the batches that
+// could not be written because influxdb was unreachable. Each batch is
+// stored in a segment file, where the first line is the bucket name
+// and the rest is the batch in line protocol.
+type writeBuffer struct {
+	mu sync.Mutex
+	// dir is the buffer directory.
+	dir string
+	// maxSize is the maximum size of the buffer in bytes.
+	maxSize int64
+	// maxAge is the maximum age of a segment.
+	maxAge time.Duration
+	// flushInterval is the interval between flush attempts.
+	flushInterval time.Duration
+	// segments is the buffered segments, oldest first.
+	segments []bufferSegment
+	// size is the current size of the buffer in bytes.
+	size int64
+	// points is the current number of buffered points.
+	points int64
+	// dropped is the number of points dropped since start.
+	dropped int64
+	// seq is the segment sequence, used to avoid name colision.
+	seq int64
+	// hooked is the set of buckets which write api already have
+	// the write failed callback.
+	hooked map[string]struct{}
+	// done stops the flush routine.
+	done chan struct{}
+}
+
+type bufferSegment struct {
+	// name is the segment file name.
+	name string
+	// bucket is the bucket name.
+	bucket string
+	// size is the segment size in bytes.
+	size int64
+	// points is the number of points in the segment.
+	points int64
+	// time is the segment creation time.
+	time time.Time
+}
+
+// StartWriteBuffer enables the on-disk write buffer for all point writes,
+// using a directory named after the service ident inside
+// env.InfluxWriteBufferDir. Batches that fail due to connectivity issues are
+// stored on disk and flushed when influxdb is reachable again. An empty
+// env.InfluxWriteBufferDir disables the buffer.
+func (c *Client) StartWriteBuffer(serviceIdent string) (err error) {
+	if env.InfluxWriteBufferDir == "" {
+		return nil
+	}
+
+	maxSize, err := strconv.ParseInt(env.InfluxWriteBufferMaxSize, 10, 64)
+	if err != nil {
+		return err
+	}
+	maxAge, err := strconv.ParseInt(env.InfluxWriteBufferMaxAge, 10, 64)
+	if err != nil {
+		return err
+	}
+	flushInterval, err := strconv.ParseInt(env.InfluxWriteBufferFlushInterval, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	b := &writeBuffer{
+		dir:           filepath.Join(env.InfluxWriteBufferDir, serviceIdent),
+		maxSize:       maxSize * 1024 * 1024,
+		maxAge:        time.Duration(maxAge) * time.Hour,
+		flushInterval: time.Duration(flushInterval) * time.Second,
+		segments:      make([]bufferSegment, 0),
+		hooked:        make(map[string]struct{}),
+		done:          make(chan struct{}),
+	}
+	err = os.MkdirAll(b.dir, 0755)
+	if err != nil {
+		return err
+	}
+	err = b.load()
+	if err != nil {
+		return err
+	}
+
+	c.buffer = b
+	go c.flushWriteBuffer()
+	return nil
+}
+
+// WriteBufferStatus returns the write buffer status.
+func (c *Client) WriteBufferStatus() (status models.InfluxWriteBufferStatus) {
+	b := c.buffer
+	if b == nil {
+		return status
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status.Enabled = true
+	status.Segments = len(b.segments)
+	status.Points = b.points
+	status.Size = b.size
+	status.Dropped = b.dropped
+	if len(b.segments) > 0 {
+		status.Oldest = b.segments[0].time
+	}
+	return status
+}
+
+// PongStatus returns a function that creates the service pong with
+// the write buffer status. Used to report the status to the service manager.
+func (c *Client) PongStatus(serviceIdent string) func() models.ServicePong {
+	return func() models.ServicePong {
+		status := c.WriteBufferStatus()
+		return models.ServicePong{
+			ServiceIdent:      serviceIdent,
+			InfluxWriteBuffer: &status,
+		}
+	}
+}
+
+// Close stops the write buffer flush routine and closes the client,
+// flushing pending writes.
+func (c *Client) Close() {
+	if c.buffer != nil {
+		close(c.buffer.done)
+	}
+	c.Client.Close()
+}
+
+// writeAPI returns the non-blocking write api of the bucket. If the write buffer
+// is enabled, failed batches are redirected to it.
+func (c *Client) writeAPI(bucket string) api.WriteAPI {
+	w := c.WriteAPI(*c.DefaultOrg.Id, bucket)
+
+	b := c.buffer
+	if b == nil {
+		return w
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.hooked[bucket]; ok {
+		return w
+	}
+	w.SetWriteFailedCallback(func(batch string, _ http2.Error, _ uint) bool {
+		// keep client retry behavior if batch can't be buffered
+		return b.push(bucket, batch) != nil
+	})
+	b.hooked[bucket] = struct{}{}
+	return w
+}
+
+// load loads the existing segments in the buffer directory.
+func (b *writeBuffer) load() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != bufferSegmentExt {
+			continue
+		}
+		s, err := b.readSegmentInfo(e.Name())
+		if err != nil {
+			// corrupted segment, discard it
+			os.Remove(filepath.Join(b.dir, e.Name()))
+			continue
+		}
+		b.segments = append(b.segments, s)
+		b.size += s.size
+		b.points += s.points
+	}
+	sort.Slice(b.segments, func(i, j int) bool {
+		return b.segments[i].name < b.segments[j].name
+	})
+	return nil
+}
+
+// readSegmentInfo reads the segment file and returns its info.
+func (b *writeBuffer) readSegmentInfo(name string) (s bufferSegment, err error) {
+	nano, err := strconv.ParseInt(strings.SplitN(strings.TrimSuffix(name, bufferSegmentExt), "-", 2)[0], 10, 64)
+	if err != nil {
+		return s, err
+	}
+
+	f, err := os.Open(filepath.Join(b.dir, name))
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		return s, ErrInvalidBufferSegment
+	}
+	s.bucket = scanner.Text()
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			s.points++
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return s, err
+	}
+	if s.bucket == "" || s.points == 0 {
+		return s, ErrInvalidBufferSegment
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return s, err
+	}
+
+	s.name = name
+	s.size = info.Size()
+	s.time = time.Unix(0, nano)
+	return s, nil
+}
+
+// push stores a batch on disk, dropping the oldest segments if the buffer
+// exceeds the maximum size.
+func (b *writeBuffer) push(bucket string, batch string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.seq++
+	s := bufferSegment{
+		name:   fmt.Sprintf("%020d-%08d%s", now.UnixNano(), b.seq, bufferSegmentExt),
+		bucket: bucket,
+		size:   int64(len(bucket) + 1 + len(batch)),
+		points: int64(strings.Count(strings.TrimRight(batch, "\n"), "\n") + 1),
+		time:   now,
+	}
+
+	if s.size > b.maxSize {
+		b.dropped += s.points
+		return nil
+	}
+	for b.size+s.size > b.maxSize && len(b.segments) > 0 {
+		b.drop(0)
+	}
+
+	err := os.WriteFile(filepath.Join(b.dir, s.name), []byte(bucket+"\n"+batch), 0644)
+	if err != nil {
+		return err
+	}
+	b.segments = append(b.segments, s)
+	b.size += s.size
+	b.points += s.points
+	return nil
+}
+
+// drop removes the segment at the index i, counting its points as dropped.
+// Must be called with the lock held.
+func (b *writeBuffer) drop(i int) {
+	b.dropped += b.segments[i].points
+	b.remove(i)
+}
+
+// remove removes the segment at the index i. Must be called with the lock held.
+func (b *writeBuffer) remove(i int) {
+	s := b.segments[i]
+	os.Remove(filepath.Join(b.dir, s.name))
+	b.size -= s.size
+	b.points -= s.points
+	b.segments = append(b.segments[:i], b.segments[i+1:]...)
+}
+
+// dropExpired drops all segments older than the maximum age.
+func (b *writeBuffer) dropExpired() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	limit := time.Now().Add(-b.maxAge)
+	for len(b.segments) > 0 && b.segments[0].time.Before(limit) {
+		b.drop(0)
+	}
+}
+
+// first returns the oldest segment.
+func (b *writeBuffer) first() (s bufferSegment, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.segments) == 0 {
+		return s, false
+	}
+	return b.segments[0], true
+}
+
+// release removes the segment if it still in the buffer. If dropped is true,
+// the segment points are counted as dropped.
+func (b *writeBuffer) release(name string, dropped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, s := range b.segments {
+		if s.name != name {
+			continue
+		}
+		if dropped {
+			b.drop(i)
+		} else {
+			b.remove(i)
+		}
+		return
+	}
+}
+
+// flushWriteBuffer periodically writes the buffered segments, oldest first,
+// while influxdb is reachable.
+func (c *Client) flushWriteBuffer() {
+	b := c.buffer
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.dropExpired()
+			c.flushSegments()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// flushSegments writes the buffered segments until the buffer is empty or a
+// write fails due to connectivity issues.
+func (c *Client) flushSegments() {
+	b := c.buffer
+	if _, ok := b.first(); !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.flushInterval)
+	ok, err := c.Ping(ctx)
+	cancel()
+	if err != nil || !ok {
+		return
+	}
+
+	for {
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+
+		s, ok := b.first()
+		if !ok {
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join(b.dir, s.name))
+		if err != nil {
+			b.release(s.name, true)
+			continue
+		}
+		// skip bucket line
+		_, batch, _ := bytes.Cut(data, []byte("\n"))
+
+		// each segment has its own timeout, otherwise a long backlog would
+		// exceed the timeout and fail the remaining writes
+		ctx, cancel := context.WithTimeout(context.Background(), b.flushInterval)
+		err = c.WriteAPIBlocking(*c.DefaultOrg.Id, s.bucket).WriteRecord(ctx, string(batch))
+		cancel()
+		if err != nil {
+			if isRetryableWriteError(err) {
+				return
+			}
+			b.release(s.name, true)
+			continue
+		}
+		b.release(s.name, false)
+	}
+}
+
+// isRetryableWriteError returns true if the write error is caused by
+// connectivity issues or server overload.
+func isRetryableWriteError(err error) bool {
+	herr, ok := err.(*http2.Error)
+	if !ok {
+		return true
+	}
+	return herr.StatusCode == 0 || herr.StatusCode == http.StatusTooManyRequests || herr.StatusCode >= http.StatusInternalServerError
+}
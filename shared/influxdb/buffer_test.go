@@ -0,0 +1,45 @@
+package influxdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteBuffer(t *testing.T) {
+	b := &writeBuffer{
+		dir:      t.TempDir(),
+		maxSize:  128,
+		maxAge:   time.Hour,
+		segments: make([]bufferSegment, 0),
+	}
+
+	batch := "metrics,metric_id=1 float-metrics=1\nmetrics,metric_id=1 float-metrics=2\n"
+	err := b.push("1-raw", batch)
+	if err != nil {
+		t.Fatalf("Fail to push batch, err: %s", err)
+	}
+	if b.points != 2 {
+		t.Errorf("Wrong number of points, want: %d, got: %d", 2, b.points)
+	}
+
+	// exceed max size, the oldest segment must be dropped
+	err = b.push("2-raw", batch)
+	if err != nil {
+		t.Fatalf("Fail to push batch, err: %s", err)
+	}
+	if len(b.segments) != 1 || b.segments[0].bucket != "2-raw" {
+		t.Errorf("Oldest segment was not dropped, segments: %v", b.segments)
+	}
+	if b.dropped != 2 {
+		t.Errorf("Wrong number of dropped points, want: %d, got: %d", 2, b.dropped)
+	}
+
+	loaded := &writeBuffer{dir: b.dir, segments: make([]bufferSegment, 0)}
+	err = loaded.load()
+	if err != nil {
+		t.Fatalf("Fail to load buffer, err: %s", err)
+	}
+	if len(loaded.segments) != 1 || loaded.segments[0].bucket != "2-raw" || loaded.points != 2 || loaded.size != b.size {
+		t.Errorf("Loaded buffer mismatch, want: %v, got: %v", b.segments, loaded.segments)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 
 	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/domain"
 )
 
+// Client is the InfluxDB storage backend.
 type Client struct {
 	influxdb2.Client
 
@@ -17,8 +19,12 @@ type Client struct {
 	DefaultOrg *domain.Organization
 	// buckets is the a map of bucket name and bucket.
 	buckets map[string]*domain.Bucket
+	// buffer is the on-disk write buffer. Nil if disabled.
+	buffer *writeBuffer
 }
 
+var _ storage.Storage = (*Client)(nil)
+
 // Connect connects to InfluxDB and create the default organixation if not exists, returning the client.
 func Connect() (c Client, err error) {
 	ctx := context.Background()
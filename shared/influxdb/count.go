@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/fernandotsda/nemesys/shared/models"
 )
@@ -12,24 +13,28 @@ func (c *Client) CountAllMetricsPoints(dps []models.DataPolicy) (n int, err erro
 		return 0, nil
 	}
 	query := ""
-	var tables string
-	for i, dp := range dps {
-		query += fmt.Sprintf(`
-			raw_%d = from(bucket: "%s")
-				|> range(start: -%dh)
-				|> filter(fn: (r) => r["_measurement"] == "metrics")
-				|> count()
-			aggr_%d = from(bucket: "%s")
+	tables := make([]string, 0, len(dps))
+	for _, dp := range dps {
+		retention := dp.Retention
+		for tier := 0; tier <= len(dp.Tiers); tier++ {
+			if tier > 0 {
+				retention = dp.Tiers[tier-1].Retention
+			}
+			table := fmt.Sprintf("dp_%d_%d", dp.Id, tier)
+			query += fmt.Sprintf(`
+			%s = from(bucket: "%s")
 				|> range(start: -%dh)
 				|> filter(fn: (r) => r["_measurement"] == "metrics")
 				|> count()`,
-			dp.Id, GetBucketName(dp.Id, false), dp.Retention, dp.Id, GetBucketName(dp.Id, true), dp.AggrRetention)
-		if i != 0 {
-			tables += ","
+				table, GetBucketName(dp.Id, tier), retention)
+			tables = append(tables, table)
 		}
-		tables += fmt.Sprintf(`raw_%d, aggr_%d`, dp.Id, dp.Id)
 	}
-	query += fmt.Sprintf(`data = union(tables: [%s]) data`, tables)
+	if len(tables) == 1 {
+		query += fmt.Sprintf(` %s`, tables[0])
+	} else {
+		query += fmt.Sprintf(`data = union(tables: [%s]) data`, strings.Join(tables, ","))
+	}
 
 	table, err := c.QueryAPI(*c.DefaultOrg.Id).Query(context.Background(), query)
 	if err != nil {
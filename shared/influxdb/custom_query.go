@@ -0,0 +1,492 @@
+package influxdb
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/influxdata/influxdb-client-go/v2/domain"
+)
+
+const (
+	CustomQueryParamDuration = "duration"
+	CustomQueryParamNumber   = "number"
+	CustomQueryParamEnum     = "enum"
+)
+
+// customQueryFunctions is the functions whitelist of the custom queries. Functions
+// that read or write buckets, or need imports, are not allowed.
+var customQueryFunctions = map[string]struct{}{
+	"aggregateWindow":          {},
+	"bool":                     {},
+	"count":                    {},
+	"cumulativeSum":            {},
+	"derivative":               {},
+	"difference":               {},
+	"distinct":                 {},
+	"drop":                     {},
+	"duplicate":                {},
+	"duration":                 {},
+	"elapsed":                  {},
+	"exponentialMovingAverage": {},
+	"fill":                     {},
+	"filter":                   {},
+	"first":                    {},
+	"float":                    {},
+	"group":                    {},
+	"increase":                 {},
+	"int":                      {},
+	"integral":                 {},
+	"keep":                     {},
+	"last":                     {},
+	"limit":                    {},
+	"map":                      {},
+	"max":                      {},
+	"mean":                     {},
+	"median":                   {},
+	"min":                      {},
+	"mode":                     {},
+	"movingAverage":            {},
+	"quantile":                 {},
+	"rename":                   {},
+	"sample":                   {},
+	"set":                      {},
+	"sort":                     {},
+	"spread":                   {},
+	"stateCount":               {},
+	"stateDuration":            {},
+	"stddev":                   {},
+	"string":                   {},
+	"sum":                      {},
+	"tail":                     {},
+	"time":                     {},
+	"timeShift":                {},
+	"timedMovingAverage":       {},
+	"toBool":                   {},
+	"toFloat":                  {},
+	"toInt":                    {},
+	"toString":                 {},
+	"toUInt":                   {},
+	"truncateTimeColumn":       {},
+	"uint":                     {},
+	"unique":                   {},
+	"window":                   {},
+	"yield":                    {},
+}
+
+// fluxKeywords is the flux keywords that can precede a parenthesis without
+// being a function call.
+var fluxKeywords = map[string]struct{}{
+	"and":    {},
+	"or":     {},
+	"not":    {},
+	"if":     {},
+	"then":   {},
+	"else":   {},
+	"exists": {},
+	"return": {},
+}
+
+// fluxForbiddenKeywords is the flux keywords not allowed on custom queries.
+var fluxForbiddenKeywords = map[string]struct{}{
+	"import":   {},
+	"option":   {},
+	"package":  {},
+	"builtin":  {},
+	"testcase": {},
+}
+
+// fluxOperators is the flux operators, longest first.
+var fluxOperators = []string{"|>", "=>", "==", "!=", "<=", ">=", "=~", "!~", "<-",
+	"+", "-", "*", "/", "%", "^", "<", ">", "=", "(", ")", "[", "]", "{", "}", ",", ":", ".", "?"}
+
+var (
+	fluxIdentRegex    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	fluxNumberRegex   = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	fluxDurationRegex = regexp.MustCompile(`^([0-9]+(ns|us|µs|ms|mo|s|m|h|d|w|y))+$`)
+	fluxDateRegex     = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}(T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2}))?`)
+)
+
+type fluxTokenKind int
+
+const (
+	fluxIdent fluxTokenKind = iota
+	fluxLiteral
+	fluxOperator
+)
+
+type fluxToken struct {
+	kind fluxTokenKind
+	text string
+}
+
+// ValidateCustomQuery validates the custom query params and compile checks the
+// flux. The flux must read the "data" variable, can only use the whitelisted
+// functions and can only reference the declared params.
+func ValidateCustomQuery(cq models.CustomQuery) error {
+	declared := make(map[string]struct{}, len(cq.Params))
+	for _, p := range cq.Params {
+		if !fluxIdentRegex.MatchString(p.Name) {
+			return ErrInvalidCustomQueryParams
+		}
+		if _, ok := declared[p.Name]; ok {
+			return ErrInvalidCustomQueryParams
+		}
+		declared[p.Name] = struct{}{}
+
+		if (p.Type == CustomQueryParamEnum) != (len(p.Values) > 0) {
+			return ErrInvalidCustomQueryParams
+		}
+		if p.Default != "" {
+			if _, err := getCustomQueryParamFlux(p, p.Default); err != nil {
+				return err
+			}
+		}
+	}
+
+	tokens, err := lexFlux(cq.Flux)
+	if err != nil {
+		return err
+	}
+	return checkFluxTokens(tokens, declared)
+}
+
+// AnalyzeCustomQuery checks the custom query flux with the InfluxDB query analyzer,
+// which reports the syntax and semantic errors without running the query. The flux
+// must be validated by ValidateCustomQuery first. Returns ErrInvalidCustomQueryFlux
+// if the analyzer reports any error.
+func (c *Client) AnalyzeCustomQuery(ctx context.Context, cq models.CustomQuery) (err error) {
+	flux, err := getCustomQueryAnalyzeFlux(cq)
+	if err != nil {
+		return err
+	}
+	res, err := c.APIClient().PostQueryAnalyze(ctx, &domain.PostQueryAnalyzeAllParams{
+		Body: domain.PostQueryAnalyzeJSONRequestBody{Query: flux},
+	})
+	if err != nil {
+		return err
+	}
+	if res.Errors != nil && len(*res.Errors) > 0 {
+		return ErrInvalidCustomQueryFlux
+	}
+	return nil
+}
+
+// getCustomQueryAnalyzeFlux returns the custom query flux declaring the base query
+// variables, using the params defaults or, for the required params, placeholder values.
+func getCustomQueryAnalyzeFlux(cq models.CustomQuery) (flux string, err error) {
+	values := make(map[string]string, len(cq.Params))
+	for _, p := range cq.Params {
+		if p.Default != "" {
+			continue
+		}
+		switch p.Type {
+		case CustomQueryParamDuration:
+			values[p.Name] = "1m"
+		case CustomQueryParamNumber:
+			values[p.Name] = "1"
+		case CustomQueryParamEnum:
+			values[p.Name] = p.Values[0]
+		}
+	}
+	flux, err = GetCustomQueryFlux(cq, values)
+	if err != nil {
+		return flux, err
+	}
+	return `
+		series = from(bucket: "analyze")
+			|> range(start: -1m)
+			|> filter(fn: (r) => r["_measurement"] == "metrics")
+		data = series` + flux, nil
+}
+
+// GetCustomQueryFlux returns the custom query flux with the params record, using
+// the params values or the params defaults.
+func GetCustomQueryFlux(cq models.CustomQuery, values map[string]string) (flux string, err error) {
+	fields := make([]string, len(cq.Params))
+	for i, p := range cq.Params {
+		v, ok := values[p.Name]
+		if !ok || v == "" {
+			v = p.Default
+		}
+		if v == "" {
+			return flux, ErrInvalidCustomQueryParams
+		}
+		pflux, err := getCustomQueryParamFlux(p, v)
+		if err != nil {
+			return flux, err
+		}
+		fields[i] = p.Name + ": " + pflux
+	}
+	for name := range values {
+		if !hasCustomQueryParam(cq, name) {
+			return flux, ErrInvalidCustomQueryParams
+		}
+	}
+
+	flux = "\n"
+	if len(fields) > 0 {
+		flux += "params = {" + strings.Join(fields, ", ") + "}\n"
+	}
+	return flux + cq.Flux, nil
+}
+
+// hasCustomQueryParam returns true if the custom query declares the param.
+func hasCustomQueryParam(cq models.CustomQuery, name string) bool {
+	for _, p := range cq.Params {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getCustomQueryParamFlux validates the param value and returns it as a flux literal.
+func getCustomQueryParamFlux(p models.CustomQueryParam, v string) (flux string, err error) {
+	switch p.Type {
+	case CustomQueryParamDuration:
+		if !fluxDurationRegex.MatchString(v) {
+			return flux, ErrInvalidCustomQueryParams
+		}
+		return v, nil
+	case CustomQueryParamNumber:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+			return flux, ErrInvalidCustomQueryParams
+		}
+		flux = strconv.FormatFloat(f, 'f', -1, 64)
+		if !strings.Contains(flux, ".") {
+			// keep float type
+			flux += ".0"
+		}
+		return flux, nil
+	case CustomQueryParamEnum:
+		for _, value := range p.Values {
+			if v == value {
+				return quoteFluxString(v), nil
+			}
+		}
+		return flux, ErrInvalidCustomQueryParams
+	default:
+		return flux, ErrInvalidCustomQueryParams
+	}
+}
+
+// quoteFluxString returns the string as a flux string literal.
+func quoteFluxString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\', '$':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// lexFlux splits the flux into tokens, ignoring comments. Returns an error if
+// a token is invalid, a string or regex is not terminated, the brackets are not
+// balanced or a string uses interpolation.
+//
+// The Flux parser is only available through the InfluxDB server, so this is not a
+// full parser: it only needs to tell identifiers apart from strings, regexes and
+// comments, which is what checkFluxTokens relies on to find the function calls and
+// params references. Anything it can't tokenize is rejected, and the full syntax
+// and semantic check is left to AnalyzeCustomQuery.
+func lexFlux(flux string) (tokens []fluxToken, err error) {
+	tokens = make([]fluxToken, 0)
+	brackets := make([]byte, 0)
+	closing := map[byte]byte{')': '(', ']': '[', '}': '{'}
+
+	for i := 0; i < len(flux); {
+		r, size := utf8.DecodeRuneInString(flux[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(flux[i:], "//"):
+			end := strings.IndexByte(flux[i:], '\n')
+			if end < 0 {
+				end = len(flux) - i
+			}
+			i += end
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(flux) {
+				r, size := utf8.DecodeRuneInString(flux[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += size
+			}
+			tokens = append(tokens, fluxToken{kind: fluxIdent, text: flux[i:j]})
+			i = j
+		case r >= '0' && r <= '9':
+			if date := fluxDateRegex.FindString(flux[i:]); len(date) > 10 || (len(date) == 10 && !isFluxNumberRune(flux, i+10)) {
+				tokens = append(tokens, fluxToken{kind: fluxLiteral, text: date})
+				i += len(date)
+				continue
+			}
+			j := i
+			for j < len(flux) && isFluxNumberRune(flux, j) {
+				_, size := utf8.DecodeRuneInString(flux[j:])
+				j += size
+			}
+			lit := flux[i:j]
+			if !fluxNumberRegex.MatchString(lit) && !fluxDurationRegex.MatchString(lit) {
+				return nil, ErrInvalidCustomQueryFlux
+			}
+			tokens = append(tokens, fluxToken{kind: fluxLiteral, text: lit})
+			i = j
+		case r == '"':
+			j := i + 1
+			for ; j < len(flux) && flux[j] != '"'; j++ {
+				if strings.HasPrefix(flux[j:], "${") {
+					return nil, ErrInvalidCustomQueryFlux
+				}
+				if flux[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(flux) {
+				return nil, ErrInvalidCustomQueryFlux
+			}
+			tokens = append(tokens, fluxToken{kind: fluxLiteral, text: flux[i : j+1]})
+			i = j + 1
+		case r == '/' && len(tokens) > 0 && (tokens[len(tokens)-1].text == "=~" || tokens[len(tokens)-1].text == "!~"):
+			j := i + 1
+			for ; j < len(flux) && flux[j] != '/' && flux[j] != '\n'; j++ {
+				if flux[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(flux) || flux[j] != '/' {
+				return nil, ErrInvalidCustomQueryFlux
+			}
+			tokens = append(tokens, fluxToken{kind: fluxLiteral, text: flux[i : j+1]})
+			i = j + 1
+		default:
+			op := ""
+			for _, o := range fluxOperators {
+				if strings.HasPrefix(flux[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, ErrInvalidCustomQueryFlux
+			}
+			switch op {
+			case "(", "[", "{":
+				brackets = append(brackets, op[0])
+			case ")", "]", "}":
+				if len(brackets) == 0 || brackets[len(brackets)-1] != closing[op[0]] {
+					return nil, ErrInvalidCustomQueryFlux
+				}
+				brackets = brackets[:len(brackets)-1]
+			}
+			tokens = append(tokens, fluxToken{kind: fluxOperator, text: op})
+			i += len(op)
+		}
+	}
+	if len(brackets) > 0 {
+		return nil, ErrInvalidCustomQueryFlux
+	}
+	return tokens, nil
+}
+
+// isFluxNumberRune returns true if the rune at the index can be part of a number
+// or duration literal.
+func isFluxNumberRune(flux string, i int) bool {
+	if i >= len(flux) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(flux[i:])
+	return r == '.' || r == 'µ' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z')
+}
+
+// checkFluxTokens checks that the tokens read the data variable, only call
+// whitelisted functions, only reference declared params and do not redeclare
+// the base query variables.
+func checkFluxTokens(tokens []fluxToken, params map[string]struct{}) error {
+	readsData := false
+	for i, t := range tokens {
+		var prev, next fluxToken
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i < len(tokens)-1 {
+			next = tokens[i+1]
+		}
+
+		if t.kind == fluxOperator {
+			// call of an expression result, like "(f)()" or "fns[0]()"
+			if t.text == "(" && prev.kind == fluxOperator && (prev.text == ")" || prev.text == "]" || prev.text == "}") {
+				return ErrCustomQueryFunctionNotAllowed
+			}
+			continue
+		}
+		if t.kind != fluxIdent {
+			continue
+		}
+
+		if _, ok := fluxForbiddenKeywords[t.text]; ok {
+			return ErrInvalidCustomQueryFlux
+		}
+
+		member := prev.kind == fluxOperator && prev.text == "."
+		if next.kind == fluxOperator && next.text == "(" {
+			if _, ok := fluxKeywords[t.text]; !ok {
+				if _, ok := customQueryFunctions[t.text]; !ok || member {
+					return ErrCustomQueryFunctionNotAllowed
+				}
+			}
+		}
+		if member {
+			continue
+		}
+
+		if next.kind == fluxOperator && next.text == "=" && isBaseQueryVariable(t.text) {
+			return ErrInvalidCustomQueryFlux
+		}
+
+		switch t.text {
+		case "data":
+			readsData = true
+		case "params":
+			if i+2 >= len(tokens) || next.text != "." {
+				return ErrInvalidCustomQueryParams
+			}
+			if _, ok := params[tokens[i+2].text]; !ok {
+				return ErrInvalidCustomQueryParams
+			}
+		}
+	}
+	if !readsData {
+		return ErrInvalidCustomQueryFlux
+	}
+	return nil
+}
+
+// isBaseQueryVariable returns true if the variable is declared by the base query.
+func isBaseQueryVariable(name string) bool {
+	return name == "data" || name == "series" || name == "params" || strings.HasPrefix(name, "tier_")
+}
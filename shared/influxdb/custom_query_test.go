@@ -0,0 +1,170 @@
+package influxdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestValidateCustomQuery(t *testing.T) {
+	params := []models.CustomQueryParam{
+		{Name: "every", Type: CustomQueryParamDuration, Default: "5m"},
+		{Name: "factor", Type: CustomQueryParamNumber},
+		{Name: "fn", Type: CustomQueryParamEnum, Values: []string{"mean", "max"}},
+	}
+
+	tests := []struct {
+		flux string
+		want error
+	}{
+		{`data |> mean()`, nil},
+		{`data |> aggregateWindow(every: params.every, fn: mean) |> map(fn: (r) => ({r with _value: r._value * params.factor}))`, nil},
+		{`data |> filter(fn: (r) => r._value > 1.5 and r.host =~ /^srv-[0-9]+$/) // comment`, nil},
+		{`data |> range(start: 2022-01-01T00:00:00Z) |> filter(fn: (r) => r._time > 2022-01-01)`, ErrCustomQueryFunctionNotAllowed},
+		{`from(bucket: "other") |> range(start: -1h)`, ErrCustomQueryFunctionNotAllowed},
+		{`data |> to(bucket: "other")`, ErrCustomQueryFunctionNotAllowed},
+		{`data |> strings.toUpper(v: "a")`, ErrCustomQueryFunctionNotAllowed},
+		{`f = (tables=<-) => tables
+		data |> f()`, ErrCustomQueryFunctionNotAllowed},
+		{`import "sql"
+		data`, ErrInvalidCustomQueryFlux},
+		{`data |> mean(`, ErrInvalidCustomQueryFlux},
+		{`data |> filter(fn: (r) => r.host == "${r.x}")`, ErrInvalidCustomQueryFlux},
+		{`data = 1`, ErrInvalidCustomQueryFlux},
+		{`x = 1`, ErrInvalidCustomQueryFlux},
+		{`data |> limit(n: params.other)`, ErrInvalidCustomQueryParams},
+	}
+	for _, test := range tests {
+		err := ValidateCustomQuery(models.CustomQuery{Flux: test.flux, Params: params})
+		if err != test.want {
+			t.Errorf("ValidateCustomQuery(%q) failed, want: %v, got: %v", test.flux, test.want, err)
+		}
+	}
+}
+
+func TestGetCustomQueryFlux(t *testing.T) {
+	cq := models.CustomQuery{
+		Flux: `data`,
+		Params: []models.CustomQueryParam{
+			{Name: "every", Type: CustomQueryParamDuration, Default: "5m"},
+			{Name: "factor", Type: CustomQueryParamNumber},
+			{Name: "fn", Type: CustomQueryParamEnum, Values: []string{"mean", `a"b`}},
+		},
+	}
+
+	tests := []struct {
+		values map[string]string
+		want   string
+		err    error
+	}{
+		{map[string]string{"factor": "2", "fn": "mean"}, "\nparams = {every: 5m, factor: 2, fn: \"mean\"}\ndata", nil},
+		{map[string]string{"every": "1h30m", "factor": "1e2", "fn": `a"b`}, "\nparams = {every: 1h30m, factor: 100.0, fn: \"a\\\"b\"}\ndata", nil},
+		{map[string]string{"factor": "2"}, "", ErrInvalidCustomQueryParams},
+		{map[string]string{"factor": "2", "fn": "sum"}, "", ErrInvalidCustomQueryParams},
+		{map[string]string{"factor": "2", "fn": "mean", "every": "5 minutes"}, "", ErrInvalidCustomQueryParams},
+		{map[string]string{"factor": "x", "fn": "mean"}, "", ErrInvalidCustomQueryParams},
+		{map[string]string{"factor": "2", "fn": "mean", "other": "1"}, "", ErrInvalidCustomQueryParams},
+	}
+	for _, test := range tests {
+		got, err := GetCustomQueryFlux(cq, test.values)
+		if err != test.err {
+			t.Errorf("GetCustomQueryFlux(%v) failed, want err: %v, got: %v", test.values, test.err, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("GetCustomQueryFlux(%v) failed, want: %q, got: %q", test.values, test.want, got)
+		}
+	}
+}
+
+func TestGetCustomQueryAnalyzeFlux(t *testing.T) {
+	cq := models.CustomQuery{
+		Flux: `data`,
+		Params: []models.CustomQueryParam{
+			{Name: "every", Type: CustomQueryParamDuration, Default: "5m"},
+			{Name: "factor", Type: CustomQueryParamNumber},
+			{Name: "fn", Type: CustomQueryParamEnum, Values: []string{"max", "mean"}},
+		},
+	}
+	got, err := getCustomQueryAnalyzeFlux(cq)
+	if err != nil {
+		t.Fatalf("getCustomQueryAnalyzeFlux failed, want err: %v, got: %v", nil, err)
+	}
+	want := "\nparams = {every: 5m, factor: 1, fn: \"max\"}\ndata"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("getCustomQueryAnalyzeFlux failed, want suffix: %q, got: %q", want, got)
+	}
+	if !strings.Contains(got, "data = series") {
+		t.Errorf("getCustomQueryAnalyzeFlux failed, want data variable declared, got: %q", got)
+	}
+}
+
+func TestLexFlux(t *testing.T) {
+	tests := []struct {
+		flux string
+		want []string
+		err  error
+	}{
+		{`data // from(bucket: "x")`, []string{"data"}, nil},
+		{"// to()\ndata", []string{"data"}, nil},
+		{`data |> filter(fn: (r) => r.a == "// not a comment")`, []string{"data", "|>", "filter", "(", "fn", ":", "(", "r", ")", "=>", "r", ".", "a", "==", `"// not a comment"`, ")"}, nil},
+		{`"a \"from()\" b"`, []string{`"a \"from()\" b"`}, nil},
+		{`"a \\" b`, []string{`"a \\"`, "b"}, nil},
+		{`"\${x}"`, []string{`"\${x}"`}, nil},
+		{"\"multi\nline\"", []string{"\"multi\nline\""}, nil},
+		{`"$x {y}"`, []string{`"$x {y}"`}, nil},
+		{`r.a =~ /\/a"b/`, []string{"r", ".", "a", "=~", `/\/a"b/`}, nil},
+		{`"${x}"`, nil, ErrInvalidCustomQueryFlux},
+		{`"a ${x} b"`, nil, ErrInvalidCustomQueryFlux},
+		{`"abc`, nil, ErrInvalidCustomQueryFlux},
+		{`"abc\"`, nil, ErrInvalidCustomQueryFlux},
+		{`r.a =~ /abc`, nil, ErrInvalidCustomQueryFlux},
+		{`("(")`, []string{"(", `"("`, ")"}, nil},
+		{`(// )` + "\n", nil, ErrInvalidCustomQueryFlux},
+	}
+	for _, test := range tests {
+		tokens, err := lexFlux(test.flux)
+		if err != test.err {
+			t.Errorf("lexFlux(%q) failed, want err: %v, got: %v", test.flux, test.err, err)
+			continue
+		}
+		got := make([]string, 0, len(tokens))
+		for _, token := range tokens {
+			got = append(got, token.text)
+		}
+		if test.err == nil && strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("lexFlux(%q) failed, want: %q, got: %q", test.flux, test.want, got)
+		}
+	}
+}
+
+func TestCheckFluxTokens(t *testing.T) {
+	params := map[string]struct{}{"every": {}}
+	tests := []struct {
+		flux string
+		want error
+	}{
+		{`data |> filter(fn: (r) => r.a == "from(bucket: \"x\")")`, nil},
+		{`data |> filter(fn: (r) => r.a == "params.other")`, nil},
+		{`data // |> to(bucket: "x")`, nil},
+		{"// data\nx", ErrInvalidCustomQueryFlux},
+		{`"data"`, ErrInvalidCustomQueryFlux},
+		{`data |> limit(n: params.every)`, nil},
+		{`data |> limit(n: params .other)`, ErrInvalidCustomQueryParams},
+		{`data |> limit(n: params)`, ErrInvalidCustomQueryParams},
+		{`data |> r.from()`, ErrCustomQueryFunctionNotAllowed},
+		{`data |> (mean)()`, ErrCustomQueryFunctionNotAllowed},
+	}
+	for _, test := range tests {
+		tokens, err := lexFlux(test.flux)
+		if err != nil {
+			t.Errorf("lexFlux(%q) failed, want err: %v, got: %v", test.flux, nil, err)
+			continue
+		}
+		err = checkFluxTokens(tokens, params)
+		if err != test.want {
+			t.Errorf("checkFluxTokens(%q) failed, want: %v, got: %v", test.flux, test.want, err)
+		}
+	}
+}
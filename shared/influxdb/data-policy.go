@@ -5,102 +5,162 @@ import (
 	"fmt"
 
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 )
 
-func GetBucketName(dataPolicyId int16, aggr bool) string {
-	var suffix string
-	if aggr {
-		suffix = "aggr"
-	} else {
-		suffix = "raw"
+// GetBucketName returns the bucket name of a data policy tier. Tier 0 is
+// the raw data bucket.
+func GetBucketName(dataPolicyId int16, tier int) string {
+	if tier == 0 {
+		return fmt.Sprintf("%d-raw", dataPolicyId)
 	}
-	return fmt.Sprintf("%d-%s", dataPolicyId, suffix)
+	return fmt.Sprintf("%d-aggr-%d", dataPolicyId, tier)
 }
 
-// CreateDataPolicy creates two buckets on database to represent a data policy.
+// CreateDataPolicy creates the raw bucket and one bucket and aggregation task
+// per tier to represent a data policy.
 func (c *Client) CreateDataPolicy(ctx context.Context, dp models.DataPolicy) (err error) {
-	err = c.createBucket(ctx, GetBucketName(dp.Id, false), int64(dp.Retention*3600))
+	err = c.createBucket(ctx, GetBucketName(dp.Id, 0), int64(dp.Retention*3600))
 	if err != nil {
 		return err
 	}
 
-	if dp.UseAggr {
-		err = c.createBucket(ctx, GetBucketName(dp.Id, true), int64(dp.AggrRetention*3600+dp.Retention*3600))
+	for i, tier := range dp.Tiers {
+		err = c.createBucket(ctx, GetBucketName(dp.Id, i+1), int64(tier.Retention*3600))
+		if err != nil {
+			return err
+		}
+		err = c.createAggrTask(ctx, dp, i+1)
 		if err != nil {
 			return err
 		}
-		err = c.createAggrTask(ctx, dp)
 	}
-
-	return err
+	return nil
 }
 
-// UpdateDataPolicy updates/removes the data policy buckets according to the data policy update.
+// UpdateDataPolicy updates the data policy buckets and tasks according to the data policy
+// update, creating the new tiers and removing the tiers that no longer exist.
 func (c *Client) UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) (err error) {
 	api := c.BucketsAPI()
 
-	err = c.updateBucket(ctx, GetBucketName(dp.Id, false), int64(dp.Retention*3600))
+	err = c.updateBucket(ctx, GetBucketName(dp.Id, 0), int64(dp.Retention*3600))
 	if err != nil {
 		return err
 	}
 
-	aggrName := GetBucketName(dp.Id, true)
-	aggrRetention := int64(dp.AggrRetention*3600 + dp.Retention*3600)
+	for i, tier := range dp.Tiers {
+		name := GetBucketName(dp.Id, i+1)
+		retention := int64(tier.Retention * 3600)
 
-	_, err = api.FindBucketByName(ctx, aggrName)
-	if dp.UseAggr {
+		_, err = api.FindBucketByName(ctx, name)
 		if err != nil {
-			err = c.createBucket(ctx, aggrName, aggrRetention)
-			if err != nil {
-				return err
-			}
-			err = c.createAggrTask(ctx, dp)
+			err = c.createBucket(ctx, name, retention)
 			if err != nil {
 				return err
 			}
-		} else {
-			err = c.updateBucket(ctx, aggrName, aggrRetention)
-			if err != nil {
-				return err
-			}
-			err = c.updateAggrTask(ctx, dp)
+			err = c.createAggrTask(ctx, dp, i+1)
 			if err != nil {
 				return err
 			}
+			continue
 		}
-	} else {
-		if err == nil {
-			err = c.deleteBucket(ctx, aggrName)
-			if err != nil {
-				return err
-			}
-			err = c.deleteAggrTask(ctx, dp.Id)
+
+		err = c.updateBucket(ctx, name, retention)
+		if err != nil {
+			return err
+		}
+		err = c.updateAggrTask(ctx, dp, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return c.deleteTiers(ctx, dp.Id, len(dp.Tiers)+1)
+}
+
+// DeleteDataPolicy deletes the data policy buckets and tasks.
+func (c *Client) DeleteDataPolicy(ctx context.Context, id int16) (err error) {
+	err = c.deleteBucket(ctx, GetBucketName(id, 0))
+	if err != nil {
+		return err
+	}
+	return c.deleteTiers(ctx, id, 1)
+}
+
+// MigrateDataPolicies migrates the single aggregation bucket and task of the data
+// policies created by older versions, named "<id>-aggr" and "<id>-aggr-task", to
+// the first tier bucket and task.
+func (c *Client) MigrateDataPolicies(ctx context.Context, dps []models.DataPolicy) (err error) {
+	api := c.BucketsAPI()
+	for _, dp := range dps {
+		bucket, err := api.FindBucketByName(ctx, fmt.Sprintf("%d-aggr", dp.Id))
+		if err != nil {
+			continue
+		}
+
+		err = c.deleteTask(ctx, fmt.Sprintf("%d-aggr-task", dp.Id))
+		if err != nil && err != ErrTaskNotFound {
+			return err
+		}
+
+		if len(dp.Tiers) == 0 {
+			err = api.DeleteBucket(ctx, bucket)
 			if err != nil {
 				return err
 			}
+			continue
+		}
+
+		bucket.Name = GetBucketName(dp.Id, 1)
+		bucket, err = api.UpdateBucket(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		c.saveBucketLocal(bucket)
+
+		err = c.createAggrTask(ctx, dp, 1)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-// DeleteDataPolicy deletes the data policy buckets.
-func (c *Client) DeleteDataPolicy(ctx context.Context, id int16) (err error) {
+// deleteTiers deletes the buckets and tasks of all existing tiers starting at from.
+func (c *Client) deleteTiers(ctx context.Context, id int16, from int) (err error) {
 	api := c.BucketsAPI()
-
-	err = c.deleteBucket(ctx, GetBucketName(id, false))
-	if err != nil {
-		return err
-	}
-	_, err = api.FindBucketByName(ctx, GetBucketName(id, true))
-	if err == nil {
-		err = c.deleteAggrTask(ctx, id)
+	for tier := from; tier <= storage.MaxDataPolicyTiers; tier++ {
+		_, err = api.FindBucketByName(ctx, GetBucketName(id, tier))
+		if err != nil {
+			return nil
+		}
+		err = c.deleteAggrTask(ctx, id, tier)
 		if err != nil {
 			return err
 		}
-		err = c.deleteBucket(ctx, GetBucketName(id, true))
+		err = c.deleteBucket(ctx, GetBucketName(id, tier))
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// getTiersRetentions returns the retention in seconds of the raw bucket
+// followed by the existing tiers buckets.
+func (c *Client) getTiersRetentions(id int16) (retentions []int64, err error) {
+	retentions = make([]int64, 0, storage.MaxDataPolicyTiers+1)
+	for tier := 0; tier <= storage.MaxDataPolicyTiers; tier++ {
+		bucket, err := c.getBucket(GetBucketName(id, tier))
+		if err != nil {
+			if tier == 0 {
+				return nil, err
+			}
+			break
+		}
+		if len(bucket.RetentionRules) == 0 {
+			return nil, ErrInvalidRetentionRulesLength
+		}
+		retentions = append(retentions, bucket.RetentionRules[0].EverySeconds)
+	}
+	return retentions, nil
+}
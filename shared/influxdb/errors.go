@@ -3,13 +3,13 @@ package influxdb
 import "errors"
 
 var (
-	ErrUnsupportedMetricType       = errors.New("unsupported metric type")
-	ErrTaskNotFound                = errors.New("task not foud")
-	ErrMetricDataResponseIsFailed  = errors.New("metric data response is set as failed")
-	ErrInvalidDuration             = errors.New("invalid duration")
-	ErrInvalidQueryOptions         = errors.New("invalid query options")
-	ErrInvalidRetentionRulesLength = errors.New("invalid retention rules length")
-	ErrLogIsNil                    = errors.New("log is nil")
-	ErrInvalidLog                  = errors.New("invalid log format")
-	ErrInvalidCountReturn          = errors.New("fail to transform datapolicy points count into number")
+	ErrUnsupportedMetricType         = errors.New("unsupported metric type")
+	ErrTaskNotFound                  = errors.New("task not foud")
+	ErrInvalidDuration               = errors.New("invalid duration")
+	ErrInvalidRetentionRulesLength   = errors.New("invalid retention rules length")
+	ErrInvalidCountReturn            = errors.New("fail to transform datapolicy points count into number")
+	ErrInvalidBufferSegment          = errors.New("invalid write buffer segment")
+	ErrInvalidCustomQueryFlux        = errors.New("invalid custom query flux")
+	ErrInvalidCustomQueryParams      = errors.New("invalid custom query params")
+	ErrCustomQueryFunctionNotAllowed = errors.New("custom query function not allowed")
 )
@@ -0,0 +1,114 @@
+package influxdb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+)
+
+// historyBatchSize is the number of points written on each history writer batch.
+const historyBatchSize = 5000
+
+// historyWriter writes metric data points with their original timestamps, bypassing
+// the realtime and alarm services. Each point is written on the finest data policy
+// tier that still keeps it, so points older than the raw retention are written on an
+// aggregated tier as the value of every aggregation function. The points are written
+// in batches with the blocking write api, so the write errors are reported to the caller
+// instead of retried on background.
+type historyWriter struct {
+	c *Client
+	// now is the reference time, in unix seconds, of the points age.
+	now int64
+	// dps is the data policies by id.
+	dps map[int16]models.DataPolicy
+	// points is the pending points of each bucket.
+	points map[string][]*write.Point
+	// pending is the number of pending points.
+	pending int
+	// written is the number of points written.
+	written int64
+}
+
+// NewHistoryWriter returns a new history writer.
+func (c *Client) NewHistoryWriter(dps []models.DataPolicy) storage.HistoryWriter {
+	w := &historyWriter{
+		c:      c,
+		now:    time.Now().Unix(),
+		dps:    make(map[int16]models.DataPolicy, len(dps)),
+		points: make(map[string][]*write.Point),
+	}
+	for _, dp := range dps {
+		w.dps[dp.Id] = dp
+	}
+	return w
+}
+
+// WritePoint buffers a data point, writing the pending points when the batch is full.
+// Returns storage.ErrPointOutOfRetention if the point is older than all data policy
+// tiers retentions.
+func (w *historyWriter) WritePoint(data models.MetricDataResponse, timestamp time.Time) (err error) {
+	if data.Failed {
+		return storage.ErrMetricDataResponseIsFailed
+	}
+
+	dp, ok := w.dps[data.DataPolicyId]
+	if !ok {
+		return storage.ErrUnknownDataPolicy
+	}
+	tier := storage.GetPointTier(storage.GetTiersRetentions(dp), w.now-timestamp.Unix())
+	if tier < 0 {
+		return storage.ErrPointOutOfRetention
+	}
+
+	value := data.Value
+	if tier > 0 {
+		// aggregated tiers store numeric values as float
+		switch v := value.(type) {
+		case int64:
+			value = float64(v)
+		}
+	}
+
+	p := influxdb2.NewPointWithMeasurement("metrics")
+	p.AddTag("metric_id", strconv.FormatInt(data.Id, 10))
+	p.AddField(getField(data.Type), value)
+	if tier > 0 {
+		for _, fn := range dp.AggrFns {
+			p.AddField(getAggrField(data.Type, fn), value)
+		}
+	}
+	p.SetTime(timestamp)
+
+	bucket := GetBucketName(data.DataPolicyId, tier)
+	w.points[bucket] = append(w.points[bucket], p)
+	w.pending++
+	if w.pending >= historyBatchSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the pending points of all buckets.
+func (w *historyWriter) Flush() (err error) {
+	ctx := context.Background()
+	for bucket, points := range w.points {
+		err = w.c.WriteAPIBlocking(*w.c.DefaultOrg.Id, bucket).WritePoint(ctx, points...)
+		if err != nil {
+			return err
+		}
+		w.written += int64(len(points))
+		w.pending -= len(points)
+		delete(w.points, bucket)
+	}
+	return nil
+}
+
+// Written returns the number of points written.
+func (w *historyWriter) Written() int64 {
+	return w.written
+}
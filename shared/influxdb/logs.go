@@ -3,9 +3,9 @@ package influxdb
 import (
 	"context"
 	"strconv"
-	"time"
 
 	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/domain"
 )
@@ -61,37 +61,20 @@ func (c *Client) UpdateLogsBucket(retentionHours int64) (err error) {
 }
 
 func (c *Client) WriteLog(ctx context.Context, log map[string]any) error {
-	if log == nil {
-		return ErrLogIsNil
-	}
-
-	bucket, err := c.getBucket(logsBucketName)
+	l, err := storage.ParseLog(log)
 	if err != nil {
 		return err
 	}
 
-	level, ok1 := log["level"].(string)
-	serv, ok2 := log["serv"].(string)
-	ts, ok3 := log["ts"].(string)
-	timestamp, err := time.Parse(time.RFC3339Nano, ts)
-	msg := log["msg"]
-
-	if !ok1 || !ok2 || !ok3 || err != nil || msg == nil {
-		return ErrInvalidLog
-	}
-
 	p := influxdb2.NewPointWithMeasurement(logsBucketName)
-	p.AddTag("level", level)
-	p.AddTag("serv", serv)
-	p.SetTime(timestamp)
+	p.AddTag("level", l.Level)
+	p.AddTag("serv", l.Service)
+	p.SetTime(l.Timestamp)
 
-	for name, v := range log {
-		if name == "level" || name == "ts" || name == "serv" {
-			continue
-		}
+	for name, v := range l.Fields {
 		p.AddField(name, v)
 	}
 
-	c.WriteAPI(*c.DefaultOrg.Id, *bucket.Id).WritePoint(p)
+	c.writeAPI(logsBucketName).WritePoint(p)
 	return nil
 }
@@ -2,42 +2,39 @@ package influxdb
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/fernandotsda/nemesys/shared/types"
-	"github.com/influxdata/influxdb-client-go/v2/domain"
+	"github.com/fernandotsda/nemesys/shared/storage"
 )
 
-type QueryOptions struct {
-	// Start is the start range in seconds.
-	Start int64
-	// Stop is the end range in seconds. Can be ommited.
-	Stop int64
-	// CustomQueryFlux is the custom query flux. Can be ommited.
-	CustomQueryFlux string
-	// DataPolicyId is the data policy id.
-	DataPolicyId int16
-	// Metric id is the metric id.
-	MetricId int64
-	// MetricType is the metric type.
-	MetricType types.MetricType
+func (c *Client) Query(ctx context.Context, opts storage.QueryOptions) (points [][]any, err error) {
+	points = make([][]any, 0)
+	err = c.QueryStream(ctx, opts, func(timestamp time.Time, value any) error {
+		points = append(points, []any{timestamp.Unix(), value})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
 }
 
-func (c *Client) Query(ctx context.Context, opts QueryOptions) (points [][]any, err error) {
+// QueryStream queries the metric data, calling fn for each point as it is read,
+// without buffering the result. Stops at the first error returned by fn.
+func (c *Client) QueryStream(ctx context.Context, opts storage.QueryOptions, fn func(timestamp time.Time, value any) error) (err error) {
 	queryApi := c.QueryAPI(*c.DefaultOrg.Id)
 
-	rawBucket, err := c.getBucket(GetBucketName(opts.DataPolicyId, false))
+	retentions, err := c.getTiersRetentions(opts.DataPolicyId)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	_, err = c.getBucket(GetBucketName(opts.DataPolicyId, true))
 
-	query, err := getBaseQuery(opts, rawBucket, err == nil)
+	query, err := getBaseQuery(opts, retentions, time.Now().Unix())
 	if err != nil {
-		return nil, ErrInvalidQueryOptions
+		return storage.ErrInvalidQueryOptions
 	}
 
 	if opts.CustomQueryFlux != "" {
@@ -48,72 +45,111 @@ func (c *Client) Query(ctx context.Context, opts QueryOptions) (points [][]any,
 
 	table, err := queryApi.Query(ctx, query)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer table.Close()
 
-	points = make([][]any, 0)
 	for table.Next() {
 		r := table.Record()
-		points = append(points, []any{r.Time().Unix(), r.Value()})
+		err = fn(r.Time(), r.Value())
+		if err != nil {
+			return err
+		}
 	}
-	return points, nil
+	return table.Err()
 }
 
-func getBaseQuery(opts QueryOptions, rawBucket *domain.Bucket, useAggr bool) (query string, err error) {
-	if len(rawBucket.RetentionRules) == 0 {
-		return query, errors.New("invalid bucket")
+func getBaseQuery(opts storage.QueryOptions, retentions []int64, now int64) (query string, err error) {
+	stop, ranges, err := storage.GetQueryRanges(opts, retentions, now)
+	if err != nil {
+		return query, err
 	}
 
-	var stopDur string
-	var startDur string
+	step, err := storage.GetStep(opts, stop)
+	if err != nil {
+		return query, err
+	}
+
+	// the series is windowed on a separated variable
+	variable := "data"
+	if step > 0 {
+		variable = "series"
+	}
 
-	if opts.Stop == 0 {
-		stopDur = "now()"
+	if len(ranges) == 1 {
+		query = getTierRangeQuery(opts, variable, ranges[0], stop)
 	} else {
-		stopDur = strconv.FormatInt(opts.Stop, 10)
+		tables := make([]string, len(ranges))
+		for i, r := range ranges {
+			tables[i] = "tier_" + strconv.Itoa(r.Tier)
+			query += getTierRangeQuery(opts, tables[i], r, stop)
+		}
+		query += fmt.Sprintf(`
+			%s = union(tables: [%s])
+				|> sort(columns: ["_time"])`,
+			variable,
+			strings.Join(tables, ", "),
+		)
 	}
-	startDur = strconv.FormatInt(opts.Start, 10)
 
-	retentionSeconds := rawBucket.RetentionRules[0].EverySeconds
-	retention := time.Duration(retentionSeconds) * time.Second
+	if step > 0 {
+		query += getWindowQuery(opts, step)
+		if opts.Fill == storage.FillLinear {
+			query = `import "interpolate"` + "\n" + query
+		}
+	}
+	return query, nil
+}
+
+// getWindowQuery returns the flux that aggregates the series variable into evenly
+// spaced windows, filling the empty ones, into the data variable.
+func getWindowQuery(opts storage.QueryOptions, step int64) string {
+	query := fmt.Sprintf(`
+			data = series
+				|> aggregateWindow(every: %ds, fn: %s, createEmpty: %t)`,
+		step,
+		getAggrFnFlux(storage.GetWindowAggregate(opts)),
+		opts.Fill == storage.FillNull || opts.Fill == storage.FillPrevious,
+	)
+	switch opts.Fill {
+	case storage.FillPrevious:
+		query += `
+				|> fill(usePrevious: true)`
+	case storage.FillLinear:
+		query += fmt.Sprintf(`
+				|> interpolate.linear(every: %ds)`, step)
+	}
+	return query
+}
 
-	if retention+(time.Duration(opts.Start)*time.Second) >= 0 || !useAggr {
-		query = fmt.Sprintf(`
-			data = from(bucket: "%s")
-				|> range(start: %s, stop: %s)
+// getTierRangeQuery returns the flux that reads the range of a tier into a variable.
+// All tiers share the full query range, so the union merges them into a single
+// table, and the tier range is applied as a time filter.
+func getTierRangeQuery(opts storage.QueryOptions, variable string, r storage.TierRange, stop int64) string {
+	// raw data has only the metric type field
+	field := getField(opts.MetricType)
+	if r.Tier > 0 {
+		field = getAggrField(opts.MetricType, storage.GetTierAggregate(opts))
+	}
+	query := fmt.Sprintf(`
+			%s = from(bucket: "%s")
+				|> range(start: %d, stop: %d)
 				|> filter(fn: (r) => r["_measurement"] == "metrics")
-				|> filter(fn: (r) => r["metric_id"] == "%s")
+				|> filter(fn: (r) => r["metric_id"] == "%d")
 				|> filter(fn: (r) => r["_field"] == "%s")`,
-			GetBucketName(opts.DataPolicyId, false),
-			startDur,
-			stopDur,
-			strconv.FormatInt(opts.MetricId, 10),
-			getField(opts.MetricType),
-		)
-	} else {
-		query = fmt.Sprintf(`
-			raw = from(bucket: "%s")
-				|> range(start: %s, stop: %s)
-				|> filter(fn: (r) => r["_measurement"] == "metrics")
-				|> filter(fn: (r) => r["metric_id"] == "%s")
-				|> filter(fn: (r) => r["_field"] == "%s")
-			aggr = from(bucket: "%s")
-				|> range(start: %s, stop: %s)
-				|> filter(fn: (r) => r["_measurement"] == "metrics")
-				|> filter(fn: (r) => r["metric_id"] == "%s")
-				|> filter(fn: (r) => r["_field"] == "%s")
-			data = union(tables: [aggr, raw])`,
-			GetBucketName(opts.DataPolicyId, false),
-			DurationFromSeconds(-retentionSeconds),
-			stopDur,
-			strconv.FormatInt(opts.MetricId, 10),
-			getField(opts.MetricType),
-			GetBucketName(opts.DataPolicyId, true),
-			startDur,
-			DurationFromSeconds(-retentionSeconds),
-			strconv.FormatInt(opts.MetricId, 10),
-			getField(opts.MetricType),
+		variable,
+		GetBucketName(opts.DataPolicyId, r.Tier),
+		opts.Start,
+		stop,
+		opts.MetricId,
+		field,
+	)
+	if r.Start != opts.Start || r.Stop != stop {
+		query += fmt.Sprintf(`
+				|> filter(fn: (r) => r._time >= %s and r._time < %s)`,
+			time.Unix(r.Start, 0).UTC().Format(time.RFC3339),
+			time.Unix(r.Stop, 0).UTC().Format(time.RFC3339),
 		)
 	}
-	return query, nil
+	return query
 }
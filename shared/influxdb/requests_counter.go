@@ -48,7 +48,7 @@ func (c *Client) WriteHistoryDataRequestsCount(count int64) {
 
 func (c *Client) writeRequestsCount(count int64, measurement string) {
 	p := influxdb2.NewPointWithMeasurement(measurement).AddField("count", count)
-	c.WriteAPI(*c.DefaultOrg.Id, requestsCountBucketName).WritePoint(p)
+	c.writeAPI(requestsCountBucketName).WritePoint(p)
 }
 
 func (c *Client) GetTotalRequests(ctx context.Context) (total int64, err error) {
@@ -6,11 +6,15 @@ import (
 	"time"
 
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
 	"github.com/fernandotsda/nemesys/shared/types"
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/domain"
 )
 
+// fieldsMetricTypes is the metric types that have a field on the metrics measurement.
+var fieldsMetricTypes = []types.MetricType{types.MTInt, types.MTFloat, types.MTBool}
+
 // getField returns a field name for a metric type.
 func getField(mt types.MetricType) string {
 	switch mt {
@@ -28,14 +32,7 @@ func getField(mt types.MetricType) string {
 // WritePoint writes a data point into influxdb client buffer. ContainerId is ignored.
 func (c *Client) WritePoint(ctx context.Context, data models.MetricDataResponse, timestamp time.Time) error {
 	if data.Failed {
-		return ErrMetricDataResponseIsFailed
-	}
-
-	// find bucket
-	bucketName := GetBucketName(data.DataPolicyId, false)
-	bucket, err := c.getBucket(bucketName)
-	if err != nil {
-		return err
+		return storage.ErrMetricDataResponseIsFailed
 	}
 
 	// create point
@@ -45,7 +42,7 @@ func (c *Client) WritePoint(ctx context.Context, data models.MetricDataResponse,
 	p.SetTime(timestamp)
 
 	// write point
-	c.WriteAPI(*c.DefaultOrg.Id, *bucket.Id).WritePoint(p)
+	c.writeAPI(GetBucketName(data.DataPolicyId, 0)).WritePoint(p)
 	return nil
 }
 
@@ -8,14 +8,15 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-// PG creates the database and it's tables if database does not exist.
-func PG() (initialized bool, err error) {
+// PG creates the database and it's tables if database does not exist, otherwise
+// applies the pending migrations on the existing database.
+func PG() (initialized bool, migrated int, err error) {
 	ctx := context.Background()
 
 	// connect to default database
 	conn, err := connect(ctx, "postgres")
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 	defer conn.Close(ctx)
 
@@ -24,35 +25,50 @@ func PG() (initialized bool, err error) {
 	var exists bool
 	err = conn.QueryRow(ctx, sql, env.PGDBName).Scan(&exists)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
 	if exists {
-		return false, nil
+		err = conn.Close(ctx)
+		if err != nil {
+			return false, 0, fmt.Errorf("fail to close connection")
+		}
+		conn, err = connect(ctx, env.PGDBName)
+		if err != nil {
+			return false, 0, err
+		}
+		defer conn.Close(ctx)
+		migrated, err = migrate(ctx, conn, false)
+		return false, migrated, err
 	}
 	// create database
 	sql = fmt.Sprintf("CREATE DATABASE %s WITH ENCODING 'UTF8'", env.PGDBName)
 	_, err = conn.Exec(ctx, sql)
 	if err != nil {
-		return false, fmt.Errorf("fail to create database, err: %s", err)
+		return false, 0, fmt.Errorf("fail to create database, err: %s", err)
 	}
 	err = conn.Close(ctx)
 	if err != nil {
-		return false, fmt.Errorf("fail to close connection")
+		return false, 0, fmt.Errorf("fail to close connection")
 	}
 
 	conn, err = connect(ctx, env.PGDBName)
 	if err != nil {
-		return false, err
+		return false, 0, err
 	}
+	defer conn.Close(ctx)
 	// exec commands
 	for _, sql := range sqlCommands {
 		_, err = conn.Exec(ctx, sql)
 		if err != nil {
-			return false, fmt.Errorf("fail to exec command: \"%s\", err: %s", sql, err)
+			return false, 0, fmt.Errorf("fail to exec command: \"%s\", err: %s", sql, err)
 		}
 	}
 
-	return true, nil
+	_, err = migrate(ctx, conn, true)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, 0, nil
 }
 
 func connect(ctx context.Context, db string) (*pgx.Conn, error) {
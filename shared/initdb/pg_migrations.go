@@ -0,0 +1,408 @@
+package initdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// sqlSchemaVersion creates the schema version table, which stores the number
+// of migrations applied on the database.
+const sqlSchemaVersion = `CREATE TABLE IF NOT EXISTS schema_version (
+	version INT4 NOT NULL
+);`
+
+// migrations are the schema changes applied on databases created by older
+// versions, in order. Each migration is a list of commands executed on a
+// single transaction. Databases created by PG already have the latest schema,
+// so on creation all migrations are only marked as applied.
+//
+// Migrations must never be removed or reordered, only appended.
+var migrations [][]string = [][]string{
+	// 1: data policies aggregation tiers
+	{
+		`CREATE TABLE IF NOT EXISTS data_policies_tiers (
+			data_policy_id INT2 NOT NULL,
+			tier INT2 NOT NULL,
+			retention INT4 NOT NULL,
+			aggr_interval INT4 NOT NULL,
+			PRIMARY KEY (data_policy_id, tier),
+			CONSTRAINT dpt_fk_data_policy_id
+				FOREIGN KEY(data_policy_id)
+					REFERENCES data_policies(id)
+					ON DELETE CASCADE
+		);`,
+		// the aggregation bucket retention was the raw retention plus the aggregation retention
+		`DO $$
+		BEGIN
+			IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'data_policies' AND column_name = 'use_aggr') THEN
+				INSERT INTO data_policies_tiers (data_policy_id, tier, retention, aggr_interval)
+					SELECT id, 1, retention + aggr_retention, aggr_interval FROM data_policies WHERE use_aggr
+					ON CONFLICT DO NOTHING;
+				ALTER TABLE data_policies DROP COLUMN use_aggr, DROP COLUMN aggr_retention, DROP COLUMN aggr_interval;
+			END IF;
+		END $$;`,
+		`ALTER TABLE data_policies ADD COLUMN IF NOT EXISTS aggr_fns VARCHAR(255) NOT NULL DEFAULT '';`,
+	},
+	// 2: custom queries params, existing queries have no params (msgpack empty array)
+	{
+		`ALTER TABLE custom_queries ADD COLUMN IF NOT EXISTS params bytea NOT NULL DEFAULT '\x90'::bytea;`,
+		`ALTER TABLE custom_queries ALTER COLUMN params DROP DEFAULT;`,
+	},
+	// 3: users provider subject, existing users are bound on the next login
+	{
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS issuer VARCHAR (255) NOT NULL DEFAULT '';`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS subject VARCHAR (255) NOT NULL DEFAULT '';`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS u_issuer_subject_index ON users (issuer, subject) WHERE subject != '';`,
+	},
+	// 4: billing reports
+	{
+		`CREATE TABLE IF NOT EXISTS billing_reports (
+			id SERIAL8 PRIMARY KEY,
+			ctx_id INT4 NOT NULL,
+			month VARCHAR (7) NOT NULL,
+			sample_interval INT4 NOT NULL,
+			tier INT2 NOT NULL,
+			created_at INT8 NOT NULL,
+			metrics bytea NOT NULL,
+			total bytea NOT NULL,
+			CONSTRAINT br_fk_ctx_id
+				FOREIGN KEY(ctx_id)
+					REFERENCES contexts(id)
+					ON DELETE CASCADE
+		);`,
+	},
+	// 5: audit log
+	{
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id SERIAL8 PRIMARY KEY,
+			user_id INT4 NOT NULL,
+			apikey_id INT4 NOT NULL,
+			role INT2 NOT NULL,
+			method VARCHAR (6) NOT NULL,
+			route VARCHAR (255) NOT NULL,
+			entity VARCHAR (255) NOT NULL,
+			status INT2 NOT NULL,
+			before JSONB,
+			after JSONB,
+			diff JSONB NOT NULL,
+			ip VARCHAR (45) NOT NULL,
+			created_at INT8 NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS al_created_at_index ON audit_log (created_at);`,
+	},
+	// 6: team roles and team containers, existing members keep the alarms access
+	// they had before the team roles (operator)
+	{
+		`ALTER TABLE users_teams ADD COLUMN IF NOT EXISTS role INT2 NOT NULL DEFAULT 2;`,
+		`ALTER TABLE users_teams ALTER COLUMN role SET DEFAULT 1;`,
+		`ALTER TABLE containers ADD COLUMN IF NOT EXISTS team_id INT4
+			CONSTRAINT c_fk_team_id
+				REFERENCES teams(id)
+				ON DELETE SET NULL;`,
+		`CREATE INDEX IF NOT EXISTS c_team_id_index ON containers (team_id);`,
+		`CREATE TABLE IF NOT EXISTS users_contexts (
+			user_id INT4 NOT NULL,
+			ctx_id INT4 NOT NULL,
+			role INT2 NOT NULL,
+			PRIMARY KEY (user_id, ctx_id),
+			CONSTRAINT uc_fk_user_id
+				FOREIGN KEY(user_id)
+					REFERENCES users(id)
+					ON DELETE CASCADE,
+			CONSTRAINT uc_fk_ctx_id
+				FOREIGN KEY(ctx_id)
+					REFERENCES contexts(id)
+					ON DELETE CASCADE
+		);`,
+	},
+	// 7: users authentication provider, existing users are local
+	{
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS provider VARCHAR (60) NOT NULL DEFAULT 'local';`,
+	},
+	// 8: OpenID Connect providers
+	{
+		`CREATE TABLE IF NOT EXISTS oidc_providers (
+			id SERIAL4 PRIMARY KEY,
+			ident VARCHAR (50) UNIQUE NOT NULL,
+			name VARCHAR (50) NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			issuer VARCHAR (255) NOT NULL,
+			client_id VARCHAR (255) NOT NULL,
+			client_secret VARCHAR (255) NOT NULL,
+			redirect_url VARCHAR (255) NOT NULL,
+			scopes JSONB NOT NULL,
+			username_claim VARCHAR (50) NOT NULL,
+			groups_claim VARCHAR (50) NOT NULL,
+			roles_groups JSONB NOT NULL,
+			teams_groups JSONB NOT NULL
+		);`,
+	},
+	// 9: TOTP two-factor authentication
+	{
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret VARCHAR (64) NOT NULL DEFAULT '';`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT false;`,
+		`CREATE TABLE IF NOT EXISTS users_recovery_codes (
+			user_id INT4 NOT NULL,
+			code_hash VARCHAR (64) NOT NULL,
+			PRIMARY KEY (user_id, code_hash),
+			CONSTRAINT urc_fk_user_id
+				FOREIGN KEY(user_id)
+					REFERENCES users(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS auth_policy (
+			id INT4 PRIMARY KEY,
+			totp_required_role INT2 NOT NULL
+		);`,
+		`INSERT INTO auth_policy (id, totp_required_role) VALUES (1, 0) ON CONFLICT DO NOTHING;`,
+	},
+	// 10: API Keys scopes and allowed ips, existing keys have full access from any ip
+	{
+		`ALTER TABLE apikeys ADD COLUMN IF NOT EXISTS scopes JSONB NOT NULL DEFAULT '[]';`,
+		`ALTER TABLE apikeys ADD COLUMN IF NOT EXISTS allowed_ips JSONB NOT NULL DEFAULT '[]';`,
+	},
+	// 11: rate limits
+	{
+		`CREATE TABLE IF NOT EXISTS rate_limits (
+			id SERIAL4 PRIMARY KEY,
+			route_group VARCHAR (16) NOT NULL,
+			user_id INT4 NOT NULL,
+			apikey_id INT4 NOT NULL,
+			requests INT4 NOT NULL,
+			window_size INT4 NOT NULL,
+			UNIQUE (route_group, user_id, apikey_id)
+		);`,
+	},
+	// 12: containers and metrics labels and label selectors
+	{
+		`ALTER TABLE containers ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';`,
+		`CREATE INDEX IF NOT EXISTS c_labels_index ON containers USING GIN (labels);`,
+		`ALTER TABLE metrics ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';`,
+		`CREATE INDEX IF NOT EXISTS m_labels_index ON metrics USING GIN (labels);`,
+		`ALTER TABLE alarm_profiles ADD COLUMN IF NOT EXISTS selector VARCHAR (1024) NOT NULL DEFAULT '';`,
+		`ALTER TABLE billing_reports ADD COLUMN IF NOT EXISTS selector VARCHAR (1024) NOT NULL DEFAULT '';`,
+	},
+	// 13: sites and containers site
+	{
+		`CREATE TABLE IF NOT EXISTS sites (
+			id SERIAL4 PRIMARY KEY,
+			parent_id INT4,
+			kind VARCHAR (10) NOT NULL,
+			name VARCHAR (50) NOT NULL,
+			descr VARCHAR (255) NOT NULL,
+			latitude FLOAT8,
+			longitude FLOAT8,
+			CONSTRAINT s_fk_parent_id
+				FOREIGN KEY(parent_id)
+					REFERENCES sites(id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS s_parent_id_index ON sites (parent_id);`,
+		`ALTER TABLE containers ADD COLUMN IF NOT EXISTS site_id INT4
+			CONSTRAINT c_fk_site_id
+				REFERENCES sites(id)
+				ON DELETE SET NULL;`,
+		`CREATE INDEX IF NOT EXISTS c_site_id_index ON containers (site_id);`,
+	},
+	// 14: dashboards
+	{
+		`CREATE TABLE IF NOT EXISTS dashboards (
+			id SERIAL4 PRIMARY KEY,
+			team_id INT4 NOT NULL,
+			name VARCHAR (50) NOT NULL,
+			descr VARCHAR (255) NOT NULL,
+			time_range INT4 NOT NULL,
+			refresh_interval INT4 NOT NULL,
+			panels JSONB NOT NULL,
+			version INT4 NOT NULL,
+			updated_at INT8 NOT NULL,
+			CONSTRAINT d_fk_team_id
+				FOREIGN KEY(team_id)
+					REFERENCES teams(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS d_team_id_index ON dashboards (team_id);`,
+		`CREATE TABLE IF NOT EXISTS dashboards_versions (
+			dashboard_id INT4 NOT NULL,
+			version INT4 NOT NULL,
+			user_id INT4,
+			name VARCHAR (50) NOT NULL,
+			descr VARCHAR (255) NOT NULL,
+			time_range INT4 NOT NULL,
+			refresh_interval INT4 NOT NULL,
+			panels JSONB NOT NULL,
+			created_at INT8 NOT NULL,
+			PRIMARY KEY (dashboard_id, version),
+			CONSTRAINT dv_fk_dashboard_id
+				FOREIGN KEY(dashboard_id)
+					REFERENCES dashboards(id)
+					ON DELETE CASCADE,
+			CONSTRAINT dv_fk_user_id
+				FOREIGN KEY(user_id)
+					REFERENCES users(id)
+					ON DELETE SET NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS dashboards_shares (
+			dashboard_id INT4 NOT NULL,
+			team_id INT4 NOT NULL,
+			PRIMARY KEY (dashboard_id, team_id),
+			CONSTRAINT ds_fk_dashboard_id
+				FOREIGN KEY(dashboard_id)
+					REFERENCES dashboards(id)
+					ON DELETE CASCADE,
+			CONSTRAINT ds_fk_team_id
+				FOREIGN KEY(team_id)
+					REFERENCES teams(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS ds_team_id_index ON dashboards_shares (team_id);`,
+	},
+	// 15: scheduled reports
+	{
+		`CREATE TABLE IF NOT EXISTS scheduled_reports (
+			id SERIAL4 PRIMARY KEY,
+			ctx_id INT4 NOT NULL,
+			name VARCHAR (50) NOT NULL,
+			descr VARCHAR (255) NOT NULL,
+			enabled BOOLEAN NOT NULL,
+			schedule VARCHAR (100) NOT NULL,
+			time_range INT4 NOT NULL,
+			sample_interval INT4 NOT NULL,
+			aggregations JSONB NOT NULL,
+			alarm_counts BOOLEAN NOT NULL,
+			contextual_metrics_ids JSONB NOT NULL,
+			selector VARCHAR (1024) NOT NULL,
+			formats JSONB NOT NULL,
+			emails JSONB NOT NULL,
+			next_run_at INT8 NOT NULL,
+			CONSTRAINT sr_fk_ctx_id
+				FOREIGN KEY(ctx_id)
+					REFERENCES contexts(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS sr_next_run_at_index ON scheduled_reports (next_run_at) WHERE enabled;`,
+		`CREATE TABLE IF NOT EXISTS scheduled_reports_runs (
+			id SERIAL8 PRIMARY KEY,
+			report_id INT4 NOT NULL,
+			status VARCHAR (10) NOT NULL,
+			start INT8 NOT NULL,
+			stop INT8 NOT NULL,
+			formats JSONB NOT NULL,
+			sent BOOLEAN NOT NULL,
+			error VARCHAR (1024) NOT NULL,
+			created_at INT8 NOT NULL,
+			finished_at INT8 NOT NULL,
+			CONSTRAINT srr_fk_report_id
+				FOREIGN KEY(report_id)
+					REFERENCES scheduled_reports(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS srr_report_id_index ON scheduled_reports_runs (report_id);`,
+		`CREATE TABLE IF NOT EXISTS scheduled_reports_files (
+			run_id INT8 NOT NULL,
+			format VARCHAR (10) NOT NULL,
+			content bytea NOT NULL,
+			PRIMARY KEY (run_id, format),
+			CONSTRAINT srf_fk_run_id
+				FOREIGN KEY(run_id)
+					REFERENCES scheduled_reports_runs(id)
+					ON DELETE CASCADE
+		);`,
+	},
+	// 16: metric templates
+	{
+		`CREATE TABLE IF NOT EXISTS metric_templates (
+			id SERIAL4 PRIMARY KEY,
+			name VARCHAR (50) UNIQUE NOT NULL,
+			descr VARCHAR (255) NOT NULL,
+			container_type INT2 NOT NULL,
+			metrics JSONB NOT NULL,
+			next_metric_id INT8 NOT NULL,
+			version INT4 NOT NULL,
+			updated_at INT8 NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS metric_templates_containers (
+			template_id INT4 NOT NULL,
+			container_id INT4 NOT NULL,
+			applied_version INT4 NOT NULL,
+			applied_at INT8 NOT NULL,
+			PRIMARY KEY (template_id, container_id),
+			CONSTRAINT mtc_fk_template_id
+				FOREIGN KEY(template_id)
+					REFERENCES metric_templates(id)
+					ON DELETE CASCADE,
+			CONSTRAINT mtc_fk_container_id
+				FOREIGN KEY(container_id)
+					REFERENCES containers(id)
+					ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS metric_templates_links (
+			metric_id INT8 PRIMARY KEY,
+			template_id INT4 NOT NULL,
+			container_id INT4 NOT NULL,
+			template_metric_id INT8 NOT NULL,
+			applied JSONB NOT NULL,
+			UNIQUE (template_id, container_id, template_metric_id),
+			CONSTRAINT mtl_fk_metric_id
+				FOREIGN KEY(metric_id)
+					REFERENCES metrics(id)
+					ON DELETE CASCADE,
+			CONSTRAINT mtl_fk_template_container
+				FOREIGN KEY(template_id, container_id)
+					REFERENCES metric_templates_containers(template_id, container_id)
+					ON DELETE CASCADE
+		);`,
+	},
+}
+
+// migrate applies the pending migrations, returning how many were applied.
+// If skip is true, the migrations are only marked as applied.
+func migrate(ctx context.Context, conn *pgx.Conn, skip bool) (applied int, err error) {
+	_, err = conn.Exec(ctx, sqlSchemaVersion)
+	if err != nil {
+		return 0, fmt.Errorf("fail to create schema version table, err: %s", err)
+	}
+
+	var version int
+	err = conn.QueryRow(ctx, `SELECT version FROM schema_version`).Scan(&version)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			return 0, fmt.Errorf("fail to get schema version, err: %s", err)
+		}
+		_, err = conn.Exec(ctx, `INSERT INTO schema_version (version) VALUES (0)`)
+		if err != nil {
+			return 0, fmt.Errorf("fail to create schema version, err: %s", err)
+		}
+	}
+
+	if skip {
+		_, err = conn.Exec(ctx, `UPDATE schema_version SET version = $1`, len(migrations))
+		return 0, err
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return applied, err
+		}
+		for _, sql := range migrations[i] {
+			_, err = tx.Exec(ctx, sql)
+			if err != nil {
+				tx.Rollback(ctx)
+				return applied, fmt.Errorf("fail to exec migration %d command: \"%s\", err: %s", i+1, sql, err)
+			}
+		}
+		_, err = tx.Exec(ctx, `UPDATE schema_version SET version = $1`, i+1)
+		if err != nil {
+			tx.Rollback(ctx)
+			return applied, err
+		}
+		err = tx.Commit(ctx)
+		if err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
@@ -9,7 +9,42 @@ var sqlCommands []string = []string{
 		username VARCHAR (50) UNIQUE NOT NULL,
 		password VARCHAR (255) NOT NULL,
 		email VARCHAR (255) UNIQUE NOT NULL,
-		role INT2 NOT NULL
+		role INT2 NOT NULL,
+		provider VARCHAR (60) NOT NULL DEFAULT 'local',
+		totp_secret VARCHAR (64) NOT NULL DEFAULT '',
+		totp_enabled BOOLEAN NOT NULL DEFAULT false,
+		issuer VARCHAR (255) NOT NULL DEFAULT '',
+		subject VARCHAR (255) NOT NULL DEFAULT ''
+	);`,
+	`CREATE UNIQUE INDEX u_issuer_subject_index ON users (issuer, subject) WHERE subject != '';`,
+
+	// Users TOTP recovery codes table
+	`CREATE TABLE users_recovery_codes (
+		user_id INT4 NOT NULL,
+		code_hash VARCHAR (64) NOT NULL,
+		PRIMARY KEY (user_id, code_hash),
+		CONSTRAINT urc_fk_user_id
+		FOREIGN KEY(user_id)
+				REFERENCES users(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Authentication policy table
+	`CREATE TABLE auth_policy (
+		id INT4 PRIMARY KEY,
+		totp_required_role INT2 NOT NULL
+	);`,
+	`INSERT INTO auth_policy (id, totp_required_role) VALUES (1, 0);`,
+
+	// Rate limits table
+	`CREATE TABLE rate_limits (
+		id SERIAL4 PRIMARY KEY,
+		route_group VARCHAR (16) NOT NULL,
+		user_id INT4 NOT NULL,
+		apikey_id INT4 NOT NULL,
+		requests INT4 NOT NULL,
+		window_size INT4 NOT NULL,
+		UNIQUE (route_group, user_id, apikey_id)
 	);`,
 
 	// API Keys table
@@ -18,6 +53,8 @@ var sqlCommands []string = []string{
 		user_id INT4 NOT NULL,
 		descr VARCHAR(255) NOT NULL, 
 		ttl INT2 NOT NULL,
+		scopes JSONB NOT NULL DEFAULT '[]',
+		allowed_ips JSONB NOT NULL DEFAULT '[]',
 		created_at INT8 NOT NULL,
 		CONSTRAINT a_fk_user_id
 		FOREIGN KEY(user_id)
@@ -37,6 +74,7 @@ var sqlCommands []string = []string{
 	`CREATE TABLE users_teams (
 		user_id INT4,
 		team_id INT4,
+		role INT2 NOT NULL DEFAULT 1,
 		CONSTRAINT ut_fk_user_id
 		FOREIGN KEY(user_id)
 				REFERENCES users(id)
@@ -55,11 +93,37 @@ var sqlCommands []string = []string{
 		name VARCHAR (50) NOT NULL,
 		descr VARCHAR (255) NOT NULL,
 		retention INT4 NOT NULL,
-		use_aggr BOOLEAN NOT NULL,
-		aggr_retention INT4 NOT NULL,
+		aggr_fn VARCHAR(50) NOT NULL,
+		aggr_fns VARCHAR(255) NOT NULL
+	);`,
+
+	// Data policies tiers table
+	`CREATE TABLE data_policies_tiers (
+		data_policy_id INT2 NOT NULL,
+		tier INT2 NOT NULL,
+		retention INT4 NOT NULL,
 		aggr_interval INT4 NOT NULL,
-		aggr_fn VARCHAR(50) NOT NULL
+		PRIMARY KEY (data_policy_id, tier),
+		CONSTRAINT dpt_fk_data_policy_id
+			FOREIGN KEY(data_policy_id)
+				REFERENCES data_policies(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Sites table
+	`CREATE TABLE sites (
+		id SERIAL4 PRIMARY KEY,
+		parent_id INT4,
+		kind VARCHAR (10) NOT NULL,
+		name VARCHAR (50) NOT NULL,
+		descr VARCHAR (255) NOT NULL,
+		latitude FLOAT8,
+		longitude FLOAT8,
+		CONSTRAINT s_fk_parent_id
+			FOREIGN KEY(parent_id)
+				REFERENCES sites(id)
 	);`,
+	`CREATE INDEX s_parent_id_index ON sites (parent_id);`,
 
 	// Containers table
 	`CREATE TABLE containers (
@@ -69,11 +133,25 @@ var sqlCommands []string = []string{
 		type INT2 NOT NULL,
 		enabled BOOLEAN NOT NULL,
 		created_at INT8 NOT NULL,
-		rts_pulling_interval INT4 NOT NULL
+		rts_pulling_interval INT4 NOT NULL,
+		team_id INT4,
+		labels JSONB NOT NULL DEFAULT '{}',
+		site_id INT4,
+		CONSTRAINT c_fk_team_id
+			FOREIGN KEY(team_id)
+				REFERENCES teams(id)
+				ON DELETE SET NULL,
+		CONSTRAINT c_fk_site_id
+			FOREIGN KEY(site_id)
+				REFERENCES sites(id)
+				ON DELETE SET NULL
 	);`,
 
 	// Create container index
 	`CREATE INDEX c_container_type_index ON containers (type);`,
+	`CREATE INDEX c_team_id_index ON containers (team_id);`,
+	`CREATE INDEX c_labels_index ON containers USING GIN (labels);`,
+	`CREATE INDEX c_site_id_index ON containers (site_id);`,
 
 	// Metrics table
 	`CREATE TABLE metrics (
@@ -90,6 +168,7 @@ var sqlCommands []string = []string{
 		dhs_enabled BOOLEAN NOT NULL,
 		dhs_interval INT4 NOT NULL,
 		ev_expression VARCHAR (255) NOT NULL,
+		labels JSONB NOT NULL DEFAULT '{}',
 		CONSTRAINT m_fk_container_id
 			FOREIGN KEY(container_id)
 				REFERENCES containers(id)
@@ -103,6 +182,7 @@ var sqlCommands []string = []string{
 	// Create metric index
 	`CREATE INDEX m_container_id_index ON metrics (container_id);`,
 	`CREATE INDEX m_container_type_index ON metrics (container_type);`,
+	`CREATE INDEX m_labels_index ON metrics USING GIN (labels);`,
 
 	// Create metrics ref table
 	`CREATE TABLE metrics_ref (
@@ -213,6 +293,22 @@ var sqlCommands []string = []string{
 	// Create context index
 	`CREATE INDEX ctx_ident_index ON contexts (ident, team_id);`,
 
+	// Users contexts roles table
+	`CREATE TABLE users_contexts (
+		user_id INT4 NOT NULL,
+		ctx_id INT4 NOT NULL,
+		role INT2 NOT NULL,
+		PRIMARY KEY (user_id, ctx_id),
+		CONSTRAINT uc_fk_user_id
+			FOREIGN KEY(user_id)
+				REFERENCES users(id)
+				ON DELETE CASCADE,
+		CONSTRAINT uc_fk_ctx_id
+			FOREIGN KEY(ctx_id)
+				REFERENCES contexts(id)
+				ON DELETE CASCADE
+	);`,
+
 	// Create contextual metrics
 	`CREATE TABLE contextual_metrics (
 		id SERIAL8 PRIMARY KEY,
@@ -240,7 +336,8 @@ var sqlCommands []string = []string{
 		id SERIAL4 PRIMARY KEY,
 		ident VARCHAR (50) NOT NULL UNIQUE,
 		descr VARCHAR (255) NOT NULL,
-		flux VARCHAR (1000) NOT NULL
+		flux VARCHAR (1000) NOT NULL,
+		params bytea NOT NULL
 	);`,
 
 	// Create request registry table
@@ -255,7 +352,8 @@ var sqlCommands []string = []string{
 	`CREATE TABLE alarm_profiles (
 		id SERIAL4 PRIMARY KEY,
 		name VARCHAR (50) NOT NULL,
-		descr VARCHAR (255) NOT NULL
+		descr VARCHAR (255) NOT NULL,
+		selector VARCHAR (1024) NOT NULL DEFAULT ''
 	);`,
 
 	// Crate alarm profiles emails table
@@ -451,4 +549,222 @@ var sqlCommands []string = []string{
 				REFERENCES alarm_categories(id)
 				ON DELETE CASCADE
 	);`,
+
+	// Create billing reports table
+	`CREATE TABLE billing_reports (
+		id SERIAL8 PRIMARY KEY,
+		ctx_id INT4 NOT NULL,
+		month VARCHAR (7) NOT NULL,
+		sample_interval INT4 NOT NULL,
+		tier INT2 NOT NULL,
+		created_at INT8 NOT NULL,
+		metrics bytea NOT NULL,
+		total bytea NOT NULL,
+		selector VARCHAR (1024) NOT NULL DEFAULT '',
+		CONSTRAINT br_fk_ctx_id
+			FOREIGN KEY(ctx_id)
+				REFERENCES contexts(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create audit log table
+	`CREATE TABLE audit_log (
+		id SERIAL8 PRIMARY KEY,
+		user_id INT4 NOT NULL,
+		apikey_id INT4 NOT NULL,
+		role INT2 NOT NULL,
+		method VARCHAR (6) NOT NULL,
+		route VARCHAR (255) NOT NULL,
+		entity VARCHAR (255) NOT NULL,
+		status INT2 NOT NULL,
+		before JSONB,
+		after JSONB,
+		diff JSONB NOT NULL,
+		ip VARCHAR (45) NOT NULL,
+		created_at INT8 NOT NULL
+	);`,
+	`CREATE INDEX al_created_at_index ON audit_log (created_at);`,
+
+	// Create OpenID Connect providers table
+	`CREATE TABLE oidc_providers (
+		id SERIAL4 PRIMARY KEY,
+		ident VARCHAR (50) UNIQUE NOT NULL,
+		name VARCHAR (50) NOT NULL,
+		enabled BOOLEAN NOT NULL,
+		issuer VARCHAR (255) NOT NULL,
+		client_id VARCHAR (255) NOT NULL,
+		client_secret VARCHAR (255) NOT NULL,
+		redirect_url VARCHAR (255) NOT NULL,
+		scopes JSONB NOT NULL,
+		username_claim VARCHAR (50) NOT NULL,
+		groups_claim VARCHAR (50) NOT NULL,
+		roles_groups JSONB NOT NULL,
+		teams_groups JSONB NOT NULL
+	);`,
+
+	// Create dashboards table
+	`CREATE TABLE dashboards (
+		id SERIAL4 PRIMARY KEY,
+		team_id INT4 NOT NULL,
+		name VARCHAR (50) NOT NULL,
+		descr VARCHAR (255) NOT NULL,
+		time_range INT4 NOT NULL,
+		refresh_interval INT4 NOT NULL,
+		panels JSONB NOT NULL,
+		version INT4 NOT NULL,
+		updated_at INT8 NOT NULL,
+		CONSTRAINT d_fk_team_id
+			FOREIGN KEY(team_id)
+				REFERENCES teams(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create dashboards team index
+	`CREATE INDEX d_team_id_index ON dashboards (team_id);`,
+
+	// Create dashboards versions table
+	`CREATE TABLE dashboards_versions (
+		dashboard_id INT4 NOT NULL,
+		version INT4 NOT NULL,
+		user_id INT4,
+		name VARCHAR (50) NOT NULL,
+		descr VARCHAR (255) NOT NULL,
+		time_range INT4 NOT NULL,
+		refresh_interval INT4 NOT NULL,
+		panels JSONB NOT NULL,
+		created_at INT8 NOT NULL,
+		PRIMARY KEY (dashboard_id, version),
+		CONSTRAINT dv_fk_dashboard_id
+			FOREIGN KEY(dashboard_id)
+				REFERENCES dashboards(id)
+				ON DELETE CASCADE,
+		CONSTRAINT dv_fk_user_id
+			FOREIGN KEY(user_id)
+				REFERENCES users(id)
+				ON DELETE SET NULL
+	);`,
+
+	// Create dashboards shares table
+	`CREATE TABLE dashboards_shares (
+		dashboard_id INT4 NOT NULL,
+		team_id INT4 NOT NULL,
+		PRIMARY KEY (dashboard_id, team_id),
+		CONSTRAINT ds_fk_dashboard_id
+			FOREIGN KEY(dashboard_id)
+				REFERENCES dashboards(id)
+				ON DELETE CASCADE,
+		CONSTRAINT ds_fk_team_id
+			FOREIGN KEY(team_id)
+				REFERENCES teams(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create dashboards shares team index
+	`CREATE INDEX ds_team_id_index ON dashboards_shares (team_id);`,
+
+	// Create scheduled reports table
+	`CREATE TABLE scheduled_reports (
+		id SERIAL4 PRIMARY KEY,
+		ctx_id INT4 NOT NULL,
+		name VARCHAR (50) NOT NULL,
+		descr VARCHAR (255) NOT NULL,
+		enabled BOOLEAN NOT NULL,
+		schedule VARCHAR (100) NOT NULL,
+		time_range INT4 NOT NULL,
+		sample_interval INT4 NOT NULL,
+		aggregations JSONB NOT NULL,
+		alarm_counts BOOLEAN NOT NULL,
+		contextual_metrics_ids JSONB NOT NULL,
+		selector VARCHAR (1024) NOT NULL,
+		formats JSONB NOT NULL,
+		emails JSONB NOT NULL,
+		next_run_at INT8 NOT NULL,
+		CONSTRAINT sr_fk_ctx_id
+			FOREIGN KEY(ctx_id)
+				REFERENCES contexts(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create scheduled reports next run index
+	`CREATE INDEX sr_next_run_at_index ON scheduled_reports (next_run_at) WHERE enabled;`,
+
+	// Create scheduled reports runs table
+	`CREATE TABLE scheduled_reports_runs (
+		id SERIAL8 PRIMARY KEY,
+		report_id INT4 NOT NULL,
+		status VARCHAR (10) NOT NULL,
+		start INT8 NOT NULL,
+		stop INT8 NOT NULL,
+		formats JSONB NOT NULL,
+		sent BOOLEAN NOT NULL,
+		error VARCHAR (1024) NOT NULL,
+		created_at INT8 NOT NULL,
+		finished_at INT8 NOT NULL,
+		CONSTRAINT srr_fk_report_id
+			FOREIGN KEY(report_id)
+				REFERENCES scheduled_reports(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create scheduled reports runs report index
+	`CREATE INDEX srr_report_id_index ON scheduled_reports_runs (report_id);`,
+
+	// Create scheduled reports output files table
+	`CREATE TABLE scheduled_reports_files (
+		run_id INT8 NOT NULL,
+		format VARCHAR (10) NOT NULL,
+		content bytea NOT NULL,
+		PRIMARY KEY (run_id, format),
+		CONSTRAINT srf_fk_run_id
+			FOREIGN KEY(run_id)
+				REFERENCES scheduled_reports_runs(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create metric templates table
+	`CREATE TABLE metric_templates (
+		id SERIAL4 PRIMARY KEY,
+		name VARCHAR (50) UNIQUE NOT NULL,
+		descr VARCHAR (255) NOT NULL,
+		container_type INT2 NOT NULL,
+		metrics JSONB NOT NULL,
+		next_metric_id INT8 NOT NULL,
+		version INT4 NOT NULL,
+		updated_at INT8 NOT NULL
+	);`,
+
+	// Create metric templates containers table
+	`CREATE TABLE metric_templates_containers (
+		template_id INT4 NOT NULL,
+		container_id INT4 NOT NULL,
+		applied_version INT4 NOT NULL,
+		applied_at INT8 NOT NULL,
+		PRIMARY KEY (template_id, container_id),
+		CONSTRAINT mtc_fk_template_id
+			FOREIGN KEY(template_id)
+				REFERENCES metric_templates(id)
+				ON DELETE CASCADE,
+		CONSTRAINT mtc_fk_container_id
+			FOREIGN KEY(container_id)
+				REFERENCES containers(id)
+				ON DELETE CASCADE
+	);`,
+
+	// Create metric templates links table
+	`CREATE TABLE metric_templates_links (
+		metric_id INT8 PRIMARY KEY,
+		template_id INT4 NOT NULL,
+		container_id INT4 NOT NULL,
+		template_metric_id INT8 NOT NULL,
+		applied JSONB NOT NULL,
+		UNIQUE (template_id, container_id, template_metric_id),
+		CONSTRAINT mtl_fk_metric_id
+			FOREIGN KEY(metric_id)
+				REFERENCES metrics(id)
+				ON DELETE CASCADE,
+		CONSTRAINT mtl_fk_template_container
+			FOREIGN KEY(template_id, container_id)
+				REFERENCES metric_templates_containers(template_id, container_id)
+				ON DELETE CASCADE
+	);`,
 }
@@ -0,0 +1,66 @@
+package labels
+
+import (
+	"errors"
+	"regexp"
+)
+
+const (
+	// MaxLabels is the maximum number of labels of a container or metric.
+	MaxLabels = 64
+	// MaxKeyLength is the maximum length of a label key.
+	MaxKeyLength = 63
+	// MaxValueLength is the maximum length of a label value.
+	MaxValueLength = 63
+)
+
+var (
+	// ErrTooManyLabels is returned when there are more than MaxLabels labels.
+	ErrTooManyLabels = errors.New("too many labels")
+	// ErrInvalidKey is returned when a label key is invalid.
+	ErrInvalidKey = errors.New("invalid label key")
+	// ErrInvalidValue is returned when a label value is invalid.
+	ErrInvalidValue = errors.New("invalid label value")
+)
+
+// labelRegex matches the label keys and the non empty label values, as
+// "site", "app.kubernetes.io/name" or "POA-01".
+var labelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([-_./a-zA-Z0-9]*[a-zA-Z0-9])?$`)
+
+// ValidKey returns true if the key is a valid label key.
+func ValidKey(key string) bool {
+	return len(key) <= MaxKeyLength && labelRegex.MatchString(key)
+}
+
+// ValidValue returns true if the value is a valid label value. Values may be
+// empty.
+func ValidValue(value string) bool {
+	return value == "" || (len(value) <= MaxValueLength && labelRegex.MatchString(value))
+}
+
+// Validate validates the labels keys and values.
+func Validate(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return ErrTooManyLabels
+	}
+	for k, v := range labels {
+		if !ValidKey(k) {
+			return ErrInvalidKey
+		}
+		if !ValidValue(v) {
+			return ErrInvalidValue
+		}
+	}
+	return nil
+}
+
+// Merge returns the labels merged, the later labels override the former.
+func Merge(labels ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, l := range labels {
+		for k, v := range l {
+			merged[k] = v
+		}
+	}
+	return merged
+}
@@ -0,0 +1,196 @@
+package labels
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Operator is a selector requirement operator.
+type Operator string
+
+const (
+	// Equals matches the labels with the key and the value.
+	Equals Operator = "="
+	// NotEquals matches the labels without the key and value.
+	NotEquals Operator = "!="
+	// In matches the labels with the key and any of the values.
+	In Operator = "in"
+	// NotIn matches the labels without the key and any of the values.
+	NotIn Operator = "notin"
+	// Exists matches the labels with the key.
+	Exists Operator = "exists"
+	// NotExists matches the labels without the key.
+	NotExists Operator = "!"
+)
+
+// MaxSelectorLength is the maximum length of a selector.
+const MaxSelectorLength = 1024
+
+// ErrInvalidSelector is returned when the selector is invalid.
+var ErrInvalidSelector = errors.New("invalid label selector")
+
+// setRegex matches the set based requirements, as "site in (POA, GRU)".
+var setRegex = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// Requirement is a selector requirement.
+type Requirement struct {
+	// Key is the label key.
+	Key string
+	// Operator is the requirement operator.
+	Operator Operator
+	// Values are the label values. Has one value for the Equals and NotEquals
+	// operators and none for the Exists and NotExists operators.
+	Values []string
+}
+
+// Selector is a label selector, matches the labels that match all the
+// requirements. An empty selector matches any labels.
+type Selector []Requirement
+
+// Parse parses a selector as "site=POA,role!=edge,customer in (acme,umbrella),!deprecated".
+// An empty string returns an empty selector.
+func Parse(s string) (selector Selector, err error) {
+	if len(s) > MaxSelectorLength {
+		return nil, ErrInvalidSelector
+	}
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	parts, err := splitRequirements(s)
+	if err != nil {
+		return nil, err
+	}
+	selector = make(Selector, 0, len(parts))
+	for _, p := range parts {
+		r, err := parseRequirement(strings.TrimSpace(p))
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, r)
+	}
+	return selector, nil
+}
+
+// Valid returns true if the selector can be parsed.
+func Valid(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// splitRequirements splits the selector on the commas outside parentheses.
+func splitRequirements(s string) (parts []string, err error) {
+	var depth, start int
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, ErrInvalidSelector
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, ErrInvalidSelector
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, ErrInvalidSelector
+	}
+	return append(parts, s[start:]), nil
+}
+
+func parseRequirement(p string) (r Requirement, err error) {
+	if m := setRegex.FindStringSubmatch(p); m != nil {
+		r = Requirement{Key: m[1], Operator: Operator(m[2])}
+		for _, v := range strings.Split(m[3], ",") {
+			v = strings.TrimSpace(v)
+			if !ValidValue(v) {
+				return r, ErrInvalidSelector
+			}
+			r.Values = append(r.Values, v)
+		}
+	} else if strings.HasPrefix(p, "!") && !strings.Contains(p, "=") {
+		r = Requirement{Key: strings.TrimSpace(p[1:]), Operator: NotExists}
+	} else if i := strings.Index(p, "!="); i != -1 {
+		r = Requirement{Key: strings.TrimSpace(p[:i]), Operator: NotEquals, Values: []string{strings.TrimSpace(p[i+2:])}}
+	} else if i := strings.Index(p, "=="); i != -1 {
+		r = Requirement{Key: strings.TrimSpace(p[:i]), Operator: Equals, Values: []string{strings.TrimSpace(p[i+2:])}}
+	} else if i := strings.Index(p, "="); i != -1 {
+		r = Requirement{Key: strings.TrimSpace(p[:i]), Operator: Equals, Values: []string{strings.TrimSpace(p[i+1:])}}
+	} else {
+		r = Requirement{Key: p, Operator: Exists}
+	}
+	if !ValidKey(r.Key) {
+		return r, ErrInvalidSelector
+	}
+	for _, v := range r.Values {
+		if !ValidValue(v) {
+			return r, ErrInvalidSelector
+		}
+	}
+	return r, nil
+}
+
+// Matches returns true if the labels match all the selector requirements.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if the labels match the requirement.
+func (r Requirement) Matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Operator {
+	case Equals:
+		return ok && v == r.Values[0]
+	case NotEquals:
+		return !ok || v != r.Values[0]
+	case In:
+		return ok && contains(r.Values, v)
+	case NotIn:
+		return !ok || !contains(r.Values, v)
+	case Exists:
+		return ok
+	case NotExists:
+		return !ok
+	}
+	return false
+}
+
+// String returns the selector in its canonical form.
+func (s Selector) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		switch r.Operator {
+		case Equals, NotEquals:
+			parts[i] = r.Key + string(r.Operator) + r.Values[0]
+		case In, NotIn:
+			parts[i] = r.Key + " " + string(r.Operator) + " (" + strings.Join(r.Values, ",") + ")"
+		case Exists:
+			parts[i] = r.Key
+		case NotExists:
+			parts[i] = "!" + r.Key
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
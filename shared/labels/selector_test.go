@@ -0,0 +1,76 @@
+package labels
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+		valid    bool
+	}{
+		{"", "", true},
+		{"site=POA", "site=POA", true},
+		{"site == POA, role != edge", "site=POA,role!=edge", true},
+		{"customer in (acme, umbrella)", "customer in (acme,umbrella)", true},
+		{"customer notin (acme),!deprecated,core", "customer notin (acme),!deprecated,core", true},
+		{"site=", "site=", true},
+		{"site in (POA", "", false},
+		{"site in ((POA))", "", false},
+		{"-site=POA", "", false},
+		{"site=P O A", "", false},
+		{"!", "", false},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.selector)
+		if (err == nil) != test.valid {
+			t.Errorf("Parse %q failed, want valid: %v, got error: %v", test.selector, test.valid, err)
+			continue
+		}
+		if got := s.String(); err == nil && got != test.want {
+			t.Errorf("Parse %q failed, want: %s, got: %s", test.selector, test.want, got)
+		}
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"site": "POA", "role": "core", "customer": "acme"}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"site=POA", true},
+		{"site=GRU", false},
+		{"site=POA,role=edge", false},
+		{"role!=edge", true},
+		{"rack!=A1", true},
+		{"customer in (acme,umbrella)", true},
+		{"customer notin (acme)", false},
+		{"rack notin (A1)", true},
+		{"customer", true},
+		{"!customer", false},
+		{"!rack", true},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.selector)
+		if err != nil {
+			t.Errorf("Parse %q failed, err: %s", test.selector, err)
+			continue
+		}
+		if got := s.Matches(labels); got != test.want {
+			t.Errorf("Matches %q failed, want: %v, got: %v", test.selector, test.want, got)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(map[string]string{"site": "POA", "app.io/role": "", "rack_id": "A-1"}); err != nil {
+		t.Errorf("Validate of valid labels failed, want: %v, got: %v", nil, err)
+	}
+	if err := Validate(map[string]string{"site ": "POA"}); err != ErrInvalidKey {
+		t.Errorf("Validate of invalid key failed, want: %v, got: %v", ErrInvalidKey, err)
+	}
+	if err := Validate(map[string]string{"site": "-POA"}); err != ErrInvalidValue {
+		t.Errorf("Validate of invalid value failed, want: %v, got: %v", ErrInvalidValue, err)
+	}
+}
@@ -0,0 +1,176 @@
+// Package mail sends emails through the SMTP server configured for the alarm emails.
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+)
+
+// Mailer sends emails.
+type Mailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewFromEnv creates a mailer of the alarm emails sender settings. Returns false
+// if the SMTP server is not configured.
+func NewFromEnv() (m *Mailer, enabled bool) {
+	if env.MetricAlarmEmailSenderHost == "" || env.MetricAlarmEmailSender == "" {
+		return nil, false
+	}
+	return &Mailer{
+		addr: env.MetricAlarmEmailSenderHost + ":" + env.MetricAlarmEmailSenderHostPort,
+		from: env.MetricAlarmEmailSender,
+		auth: smtp.PlainAuth("", env.MetricAlarmEmailSender, env.MetricAlarmEmailSenderPassword, env.MetricAlarmEmailSenderHost),
+	}, true
+}
+
+// Attachment is an email file attachment.
+type Attachment struct {
+	// Name is the file name.
+	Name string
+	// ContentType is the file content type.
+	ContentType string
+	// Data is the file content.
+	Data []byte
+}
+
+// Send sends a plain text email.
+func (m *Mailer) Send(to []string, subject string, body string) error {
+	msg, err := Message(m.from, to, subject, body, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, to, msg)
+}
+
+// SendWithAttachments sends a plain text email with the attachments.
+func (m *Mailer) SendWithAttachments(to []string, subject string, body string, attachments []Attachment) error {
+	msg, err := MessageWithAttachments(m.from, to, subject, body, attachments, time.Now())
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.from, to, msg)
+}
+
+// Message returns a plain text email message.
+func Message(from string, to []string, subject string, body string, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	err := writeHeader(&b, from, to, subject, date)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	b.WriteString("\r\n")
+
+	err = writeText(&b, body)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// MessageWithAttachments returns a multipart email message, with the plain text
+// body and the attachments encoded in base64.
+func MessageWithAttachments(from string, to []string, subject string, body string, attachments []Attachment, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	err := writeHeader(&b, from, to, subject, date)
+	if err != nil {
+		return nil, err
+	}
+
+	mw := multipart.NewWriter(&b)
+	b.WriteString("Content-Type: multipart/mixed; boundary=" + mw.Boundary() + "\r\n")
+	b.WriteString("\r\n")
+
+	w, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = writeText(w, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attachments {
+		if strings.ContainsAny(a.Name, "\r\n") || strings.ContainsAny(a.ContentType, "\r\n") {
+			return nil, fmt.Errorf("invalid attachment %q", a.Name)
+		}
+		w, err = mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Name})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = writeBase64(w, a.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writeHeader writes the message header, without the content type.
+func writeHeader(b *bytes.Buffer, from string, to []string, subject string, date time.Time) error {
+	for _, addr := range append([]string{from}, to...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return fmt.Errorf("invalid address %q", addr)
+		}
+	}
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	return nil
+}
+
+// writeText writes the text encoded in quoted-printable, with CRLF line endings.
+func writeText(w io.Writer, text string) error {
+	qw := quotedprintable.NewWriter(w)
+	_, err := qw.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n")))
+	if err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// writeBase64 writes the data encoded in base64, in lines of 76 characters.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := 76
+		if len(encoded) < n {
+			n = len(encoded)
+		}
+		_, err := io.WriteString(w, encoded[:n]+"\r\n")
+		if err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage(t *testing.T) {
+	date := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	b, err := Message("nemesys@example.com", []string{"a@example.com", "b@example.com"}, "Relatório", "Line 1\nLine 2 é", date)
+	if err != nil {
+		t.Fatalf("fail to create message, err: %s", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("fail to read message, err: %s", err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || len(to) != 2 {
+		t.Errorf("Message To failed, want: %d addresses, got: %v, err: %v", 2, to, err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Relatório" {
+		t.Errorf("Message Subject failed, want: %s, got: %s, err: %v", "Relatório", subject, err)
+	}
+	got, err := msg.Header.Date()
+	if err != nil || !got.Equal(date) {
+		t.Errorf("Message Date failed, want: %v, got: %v, err: %v", date, got, err)
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !bytes.Contains(body, []byte("Line 1\r\nLine 2 =C3=A9")) {
+		t.Errorf("Message body failed, got: %q", body)
+	}
+
+	_, err = Message("nemesys@example.com", []string{"a@example.com\r\nBcc: c@example.com"}, "Subject", "Body", date)
+	if err == nil {
+		t.Error("Message with header injection failed, want: error, got: nil")
+	}
+}
+
+func TestMessageWithAttachments(t *testing.T) {
+	date := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	pdf := bytes.Repeat([]byte("%PDF-1.4 binary \x00\xff"), 20)
+	b, err := MessageWithAttachments("nemesys@example.com", []string{"a@example.com"}, "Report", "See attached.", []Attachment{
+		{Name: "report.csv", ContentType: "text/csv", Data: []byte("a,b\n1,2\n")},
+		{Name: "report.pdf", ContentType: "application/pdf", Data: pdf},
+	}, date)
+	if err != nil {
+		t.Fatalf("fail to create message, err: %s", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("fail to read message, err: %s", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" {
+		t.Fatalf("MessageWithAttachments Content-Type failed, want: %s, got: %s, err: %v", "multipart/mixed", mediaType, err)
+	}
+
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	part, err := r.NextPart()
+	if err != nil {
+		t.Fatalf("fail to read body part, err: %s", err)
+	}
+	body, _ := io.ReadAll(part)
+	if string(body) != "See attached." {
+		t.Errorf("MessageWithAttachments body failed, want: %q, got: %q", "See attached.", body)
+	}
+
+	wants := []struct {
+		name string
+		data []byte
+	}{
+		{"report.csv", []byte("a,b\n1,2\n")},
+		{"report.pdf", pdf},
+	}
+	for _, want := range wants {
+		part, err = r.NextPart()
+		if err != nil {
+			t.Fatalf("fail to read attachment part, err: %s", err)
+		}
+		if part.FileName() != want.name {
+			t.Errorf("MessageWithAttachments file name failed, want: %s, got: %s", want.name, part.FileName())
+		}
+		encoded, _ := io.ReadAll(part)
+		got, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+		if err != nil || !bytes.Equal(got, want.data) {
+			t.Errorf("MessageWithAttachments %s data failed, want: %q, got: %q, err: %v", want.name, want.data, got, err)
+		}
+	}
+	_, err = r.NextPart()
+	if err != io.EOF {
+		t.Errorf("MessageWithAttachments parts failed, want: %v, got: %v", io.EOF, err)
+	}
+}
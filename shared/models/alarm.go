@@ -13,6 +13,9 @@ type AlarmProfile struct {
 	Name string `json:"name" validate:"required,max=50"`
 	// Descr is the alarm profile description.
 	Descr string `json:"descr" validate:"required,max=255"`
+	// Selector is the label selector of the alarmed metrics, matched against the
+	// container labels merged with the metric labels. Empty matches any metric.
+	Selector string `json:"selector" validate:"max=1024,selector"`
 }
 
 type AlarmProfileSimplified struct {
@@ -20,6 +23,8 @@ type AlarmProfileSimplified struct {
 	Id int32 `json:"id" validate:"-"`
 	// Name is the alarm profile name.
 	Name string `json:"name" validate:"required,max=50"`
+	// Selector is the alarm profile label selector.
+	Selector string `json:"selector" validate:"-"`
 }
 
 type AlarmProfileEmailWithoutProfileId struct {
@@ -97,6 +102,8 @@ type AlarmNotificationInfo struct {
 	Value any `json:"value"`
 	// Descr is the description.
 	Descr string `json:"descr"`
+	// Labels are the container labels merged with the metric labels.
+	Labels map[string]string `json:"labels"`
 }
 
 type DirectAlarm struct {
@@ -1,5 +1,14 @@
 package models
 
+const (
+	// APIKeyPermissionRead allows the GET requests.
+	APIKeyPermissionRead = "read"
+	// APIKeyPermissionHistory allows the data history and export requests.
+	APIKeyPermissionHistory = "history"
+	// APIKeyPermissionIngest allows the metric data ingestion requests.
+	APIKeyPermissionIngest = "ingest"
+)
+
 type APIKeyInfo struct {
 	// Id is the API Key indentifier.
 	Id int16 `json:"id" validate:"-"`
@@ -9,8 +18,28 @@ type APIKeyInfo struct {
 	Descr string `json:"descr" validate:"required"`
 	// TTL is the time-to-live of the API Key in hours.
 	TTL int32 `json:"ttl" validate:"min=0"`
+	// Scopes are the API Key scopes. If empty, the API Key has the
+	// same access of its user.
+	Scopes []APIKeyScope `json:"scopes" validate:"max=32,dive"`
+	// AllowedIPs are the ips and cidrs allowed to use the API Key. If
+	// empty, any ip is allowed.
+	AllowedIPs []string `json:"allowed-ips" validate:"max=32,dive,ip|cidr"`
 	// CreatedAt is the date of creation in UNIX format.
 	CreatedAt int64 `json:"created-at" validate:"-"`
+	// LastUsed is the date of the last use in UNIX format, zero if
+	// never used.
+	LastUsed int64 `json:"last-used" validate:"-"`
+}
+
+type APIKeyScope struct {
+	// Permission is the scope permission, "read", "history" or "ingest".
+	Permission string `json:"permission" validate:"required,oneof=read history ingest"`
+	// TeamId restricts the scope to a team, zero for any team. Not
+	// allowed on "ingest" scopes.
+	TeamId int32 `json:"team-id" validate:"min=0"`
+	// ContainerId restricts the scope to a container, zero for any
+	// container. Not allowed on "history" scopes.
+	ContainerId int32 `json:"container-id" validate:"min=0"`
 }
 
 type APIkey struct {
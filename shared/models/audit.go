@@ -0,0 +1,32 @@
+package models
+
+import "encoding/json"
+
+type AuditEntry struct {
+	// Id is the entry unique id.
+	Id int64 `json:"id"`
+	// UserId is the user id.
+	UserId int32 `json:"user-id"`
+	// APIKeyId is the API Key id, zero if the user used a session.
+	APIKeyId int32 `json:"api-key-id"`
+	// Role is the user role.
+	Role int16 `json:"role"`
+	// Method is the request method.
+	Method string `json:"method"`
+	// Route is the route path, like "/users/:userId".
+	Route string `json:"route"`
+	// Entity is the path of the changed entity, like "/users/1".
+	Entity string `json:"entity"`
+	// Status is the response status code.
+	Status int16 `json:"status"`
+	// Before is the entity before the request, if readable.
+	Before json.RawMessage `json:"before"`
+	// After is the entity after the request, if readable, or the request body.
+	After json.RawMessage `json:"after"`
+	// Diff is the changed fields between before and after.
+	Diff []string `json:"diff"`
+	// IP is the client ip.
+	IP string `json:"ip"`
+	// CreatedAt is the request date in unix seconds.
+	CreatedAt int64 `json:"created-at"`
+}
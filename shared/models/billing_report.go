@@ -0,0 +1,56 @@
+package models
+
+type BillingReport struct {
+	// Id is the report unique id.
+	Id int64 `json:"id" validate:"-"`
+	// ContextId is the context id.
+	ContextId int32 `json:"context-id" validate:"-"`
+	// Month is the report month, formatted as "2006-01", in UTC.
+	Month string `json:"month" validate:"required,datetime=2006-01"`
+	// Interval is the sample interval in seconds. Each sample is the mean
+	// of the interval.
+	Interval int32 `json:"interval" validate:"omitempty,min=10,max=86400"`
+	// Tier is the data policy tier to read the data from, where zero is
+	// the raw data.
+	Tier int `json:"tier" validate:"min=0,max=5"`
+	// ContextualMetricsIds is the contextual metrics of the report. Empty
+	// means all contextual metrics of the context.
+	ContextualMetricsIds []int64 `json:"contextual-metrics-ids" validate:"max=100"`
+	// Selector is the label selector of the contextual metrics of the report,
+	// matched against the container labels merged with the metric labels.
+	// Can't be used with ContextualMetricsIds.
+	Selector string `json:"selector" validate:"max=1024,selector,excluded_with=ContextualMetricsIds"`
+	// CreatedAt is the report creation date in unix seconds.
+	CreatedAt int64 `json:"created-at" validate:"-"`
+	// Metrics is the statistics of each contextual metric.
+	Metrics []BillingReportMetric `json:"metrics" validate:"-"`
+	// Total is the statistics of the sum of all contextual metrics.
+	Total BillingReportStats `json:"total" validate:"-"`
+}
+
+type BillingReportMetric struct {
+	// ContextualMetricId is the contextual metric id.
+	ContextualMetricId int64 `json:"contextual-metric-id"`
+	// ContextualMetricIdent is the contextual metric ident.
+	ContextualMetricIdent string `json:"contextual-metric-ident"`
+	// ContextualMetricName is the contextual metric name.
+	ContextualMetricName string `json:"contextual-metric-name"`
+	// Stats is the contextual metric statistics.
+	Stats BillingReportStats `json:"stats"`
+}
+
+type BillingReportStats struct {
+	// Samples is the number of samples.
+	Samples int64 `json:"samples"`
+	// P95 is the 95th percentile.
+	P95 float64 `json:"p95"`
+	// P99 is the 99th percentile.
+	P99 float64 `json:"p99"`
+	// Avg is the average.
+	Avg float64 `json:"avg"`
+	// Peak is the maximum sample.
+	Peak float64 `json:"peak"`
+	// Volume is the sum of each sample multiplied by the interval,
+	// like bits for a bits per second metric.
+	Volume float64 `json:"volume"`
+}
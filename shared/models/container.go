@@ -24,4 +24,9 @@ type BaseContainer struct {
 	RTSPullingInterval int32 `json:"rts-pulling-interval" validate:"required,min=100,max=3600000"`
 	// CreatedAt is the time in UNIX format of creation of the container.
 	CreatedAt int64 `json:"created-at" validate:"-"`
+	// TeamId is the team that owns the container, zero if the container has
+	// no team. The team admins can manage the team containers.
+	TeamId int32 `json:"team-id" validate:"min=0"`
+	// Labels are the container key/value labels, as "site": "POA".
+	Labels map[string]string `json:"labels" validate:"labels"`
 }
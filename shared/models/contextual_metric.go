@@ -14,3 +14,24 @@ type ContextualMetric struct {
 	// Descr is the conextual metric description.
 	Descr string `json:"descr" validate:"max=255"`
 }
+
+type ContextualMetricMetadata struct {
+	// TeamId is the team identifier.
+	TeamId int32
+	// ContextId is the context identifier.
+	ContextId int32
+	// ContextIdent is the context ident.
+	ContextIdent string
+	// ContextName is the context name.
+	ContextName string
+	// Id is the contextual metric identifier.
+	Id int64
+	// Ident is the contextual metric ident.
+	Ident string
+	// Name is the contextual metric name.
+	Name string
+	// Enabled is the metric enabled status.
+	Enabled bool
+	// MetricRequest is the metric request information.
+	MetricRequest MetricRequest
+}
@@ -7,6 +7,20 @@ type CustomQuery struct {
 	Ident string `json:"ident" validate:"required,max=1000"`
 	// Descr is the custom query description.
 	Descr string `json:"descr" validate:"required"`
-	// Flux is the flux code to use during the query.
+	// Flux is the flux code to use during the query. The queried data is on the
+	// "data" variable and the params values on the "params" record.
 	Flux string `json:"flux" validate:"required"`
+	// Params is the custom query params.
+	Params []CustomQueryParam `json:"params" validate:"max=10,dive"`
+}
+
+type CustomQueryParam struct {
+	// Name is the param name, used on the flux as "params.<name>".
+	Name string `json:"name" validate:"required,max=50"`
+	// Type is the param type, "duration", "number" or "enum".
+	Type string `json:"type" validate:"required,oneof=duration number enum"`
+	// Values is the allowed values of an enum param.
+	Values []string `json:"values" validate:"max=50,dive,max=255"`
+	// Default is the param default value. Empty means the param is required.
+	Default string `json:"default" validate:"max=255"`
 }
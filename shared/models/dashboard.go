@@ -0,0 +1,75 @@
+package models
+
+type Dashboard struct {
+	// Id is the dashboard identifier.
+	Id int32 `json:"id" validate:"-"`
+	// TeamId is the owner team identifier.
+	TeamId int32 `json:"team-id" validate:"-"`
+	// Name is the dashboard name.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the dashboard description.
+	Descr string `json:"descr" validate:"max=255"`
+	// TimeRange is the time range of the panels in seconds, until now.
+	TimeRange int32 `json:"time-range" validate:"required,min=60,max=31536000"`
+	// RefreshInterval is the panels refresh interval in seconds, zero
+	// disables the refresh.
+	RefreshInterval int32 `json:"refresh-interval" validate:"omitempty,min=5,max=86400"`
+	// Panels is the dashboard panels.
+	Panels []DashboardPanel `json:"panels" validate:"max=100,dive"`
+	// Version is the dashboard version, incremented on each update.
+	Version int32 `json:"version" validate:"-"`
+	// UpdatedAt is the last update date in unix seconds.
+	UpdatedAt int64 `json:"updated-at" validate:"-"`
+}
+
+type DashboardPanel struct {
+	// Title is the panel title.
+	Title string `json:"title" validate:"max=50"`
+	// Type is the panel visualization type.
+	Type string `json:"type" validate:"required,oneof=line area bar gauge stat table"`
+	// X is the panel column on the dashboard grid.
+	X int16 `json:"x" validate:"min=0,max=23"`
+	// Y is the panel row on the dashboard grid.
+	Y int16 `json:"y" validate:"min=0,max=1000"`
+	// Width is the panel width in grid columns.
+	Width int16 `json:"width" validate:"min=1,max=24"`
+	// Height is the panel height in grid rows.
+	Height int16 `json:"height" validate:"min=1,max=48"`
+	// ContextualMetricsIds is the contextual metrics of the panel, from
+	// the contexts of the owner team.
+	ContextualMetricsIds []int64 `json:"contextual-metrics-ids" validate:"max=20"`
+	// CustomQueryId is the custom query used on the panel data, zero if none.
+	CustomQueryId int32 `json:"custom-query-id" validate:"min=0"`
+	// Options is the visualization options, like colors and thresholds.
+	Options map[string]string `json:"options" validate:"max=32,dive,keys,max=50,endkeys,max=255"`
+}
+
+type DashboardSimplified struct {
+	// Id is the dashboard identifier.
+	Id int32 `json:"id"`
+	// TeamId is the owner team identifier.
+	TeamId int32 `json:"team-id"`
+	// Name is the dashboard name.
+	Name string `json:"name"`
+	// Descr is the dashboard description.
+	Descr string `json:"descr"`
+	// Version is the dashboard version.
+	Version int32 `json:"version"`
+	// UpdatedAt is the last update date in unix seconds.
+	UpdatedAt int64 `json:"updated-at"`
+}
+
+type DashboardVersion struct {
+	// Version is the dashboard version.
+	Version int32 `json:"version"`
+	// UserId is the identifier of the user that created the version, zero
+	// if the user was deleted.
+	UserId int32 `json:"user-id"`
+	// CreatedAt is the version creation date in unix seconds.
+	CreatedAt int64 `json:"created-at"`
+}
+
+type DashboardShare struct {
+	// TeamId is the identifier of the team the dashboard is shared with.
+	TeamId int32 `json:"team-id" validate:"required,min=1"`
+}
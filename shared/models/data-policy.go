@@ -7,14 +7,23 @@ type DataPolicy struct {
 	Name string `json:"name" validate:"required,max=50"`
 	// Descr is the data policy description.
 	Descr string `json:"descr" validate:"required,max=255"`
-	// UseAggr is the aggregation enabled status.
-	UseAggr bool `json:"use-aggregation" validate:"-"`
 	// Retention is the raw data retention in hours.
 	Retention int32 `json:"retention" validate:"required,min=1"`
-	// AggrRetention is the aggregation retention in hours.
-	AggrRetention int32 `json:"aggregation-retention" validate:"required,min=1"`
-	// AggrInterval is the aggregation interval in seconds.
-	AggrInterval int32 `json:"aggregation-interval" validate:"required,min=1"`
-	// AggrFn is the aggregation funcion.
+	// Tiers is the ordered list of aggregation tiers, from the finest
+	// to the coarsest interval. Empty means no aggregation.
+	Tiers []DataPolicyTier `json:"tiers" validate:"max=5,dive"`
+	// AggrFn is the primary aggregation funcion.
 	AggrFn string `json:"aggregation-function" validate:"required"`
+	// AggrFns is the additional aggregation functions, stored
+	// as separated fields on the tiers.
+	AggrFns []string `json:"aggregation-functions" validate:"max=8"`
+}
+
+type DataPolicyTier struct {
+	// Retention is the maximum age in hours of the data kept on the tier.
+	// Must be greater than the previous tier retention.
+	Retention int32 `json:"retention" validate:"required,min=1"`
+	// Interval is the aggregation interval in seconds. Must be greater
+	// than the previous tier interval.
+	Interval int32 `json:"interval" validate:"required,min=1"`
 }
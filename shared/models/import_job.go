@@ -0,0 +1,41 @@
+package models
+
+const (
+	ImportJobRunning = "running"
+	ImportJobDone    = "done"
+	ImportJobFailed  = "failed"
+)
+
+type ImportJob struct {
+	// Id is the job unique id.
+	Id string `json:"id"`
+	// Status is the job status, "running", "done" or "failed".
+	Status string `json:"status"`
+	// Format is the file format.
+	Format string `json:"format"`
+	// Size is the file size in bytes.
+	Size int64 `json:"size"`
+	// Read is the number of bytes read.
+	Read int64 `json:"read"`
+	// Rows is the number of rows read.
+	Rows int64 `json:"rows"`
+	// Imported is the number of rows written with success.
+	Imported int64 `json:"imported"`
+	// Rejected is the number of rows rejected.
+	Rejected int64 `json:"rejected"`
+	// RejectedRows is the first rejected rows.
+	RejectedRows []ImportRejectedRow `json:"rejected-rows"`
+	// Error is the error that failed the job.
+	Error string `json:"error"`
+	// CreatedAt is the job creation date in unix seconds.
+	CreatedAt int64 `json:"created-at"`
+	// FinishedAt is the job finish date in unix seconds.
+	FinishedAt int64 `json:"finished-at"`
+}
+
+type ImportRejectedRow struct {
+	// Line is the file line of the row.
+	Line int64 `json:"line"`
+	// Reason is the rejection reason.
+	Reason string `json:"reason"`
+}
@@ -6,3 +6,22 @@ type Login struct {
 	// Password is the password.
 	Password string `json:"password" validate:"required,min=5,max=50"`
 }
+
+type PasswordChange struct {
+	// CurrentPassword is the current password.
+	CurrentPassword string `json:"current-password" validate:"required,max=50"`
+	// NewPassword is the new password.
+	NewPassword string `json:"new-password" validate:"required,min=5,max=50"`
+}
+
+type PasswordResetRequest struct {
+	// Email is the user email.
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type PasswordReset struct {
+	// Token is the password reset token sent by email.
+	Token string `json:"token" validate:"required,max=64"`
+	// Password is the new password.
+	Password string `json:"password" validate:"required,min=5,max=50"`
+}
@@ -93,6 +93,8 @@ type BaseMetric struct {
 	DHSInterval int32 `json:"dhs-interval" validate:"-"`
 	// EvaluableExpression is the a evaluable expression for the metric value.
 	EvaluableExpression string `json:"evaluable-expression" validate:"max=255"`
+	// Labels are the metric key/value labels, as "role": "core".
+	Labels map[string]string `json:"labels" validate:"labels"`
 }
 
 type MetricRequest struct {
@@ -0,0 +1,137 @@
+package models
+
+import "github.com/fernandotsda/nemesys/shared/types"
+
+type MetricTemplate struct {
+	// Id is the metric template identifier.
+	Id int32 `json:"id" validate:"-"`
+	// Name is the metric template name.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the metric template description.
+	Descr string `json:"descr" validate:"max=255"`
+	// ContainerType is the type of the containers which the template is applied.
+	ContainerType types.ContainerType `json:"container-type" validate:"required"`
+	// Metrics is the template metrics.
+	Metrics []MetricTemplateMetric `json:"metrics" validate:"required,min=1,max=200,dive"`
+	// Version is the template version, incremented on each update.
+	Version int32 `json:"version" validate:"-"`
+	// UpdatedAt is the last update date in unix seconds.
+	UpdatedAt int64 `json:"updated-at" validate:"-"`
+}
+
+type MetricTemplateMetric struct {
+	// Id is the template metric identifier, kept between the template versions.
+	// Zero on update adds the metric to the template.
+	Id int64 `json:"id" validate:"min=0"`
+	MetricDefinition
+}
+
+// MetricDefinition is the configuration of a metric created by a template.
+type MetricDefinition struct {
+	// Type is the metric type.
+	Type types.MetricType `json:"type" validate:"required"`
+	// Name is the metric name.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the metric description.
+	Descr string `json:"descr" validate:"required,max=255"`
+	// Enabled is the metric enable state.
+	Enabled bool `json:"enabled" validate:"-"`
+	// DataPolicyId is the metric data policy identifier.
+	DataPolicyId int16 `json:"data-policy-id" validate:"required"`
+	// RTSPullingTimes is how many times will pull the data.
+	RTSPullingTimes int16 `json:"rts-pulling-times" validate:"min=0,max=1000000"`
+	// RTSCacheDuration is the data duration in miliseconds on RTS cache. Max is one hour.
+	RTSCacheDuration int32 `json:"rts-cache-duration" validate:"min=1000,max=3600000"`
+	// DHSEnabled is the enabled state of for the data history service.
+	DHSEnabled bool `json:"dhs-enabled" validate:"-"`
+	// DHSInterval is the interval in seconds of the data pulling of the data history service.
+	DHSInterval int32 `json:"dhs-interval" validate:"-"`
+	// EvaluableExpression is the a evaluable expression for the metric value.
+	EvaluableExpression string `json:"evaluable-expression" validate:"max=255"`
+	// Labels are the metric key/value labels.
+	Labels map[string]string `json:"labels" validate:"labels"`
+	// OID is the snmp object identifier, used by SNMPv2c and Flex Legacy metrics.
+	OID string `json:"oid" validate:"max=128"`
+	// Port is the flex port, used by Flex Legacy metrics.
+	Port int16 `json:"port" validate:"min=0"`
+	// PortType is the flex port type, used by Flex Legacy metrics.
+	PortType int16 `json:"port-type" validate:"min=0"`
+	// AlarmExpressionsIds is the alarm expressions of the metric.
+	AlarmExpressionsIds []int32 `json:"alarm-expressions-ids" validate:"max=20,unique"`
+}
+
+type MetricTemplateSimplified struct {
+	// Id is the metric template identifier.
+	Id int32 `json:"id"`
+	// Name is the metric template name.
+	Name string `json:"name"`
+	// Descr is the metric template description.
+	Descr string `json:"descr"`
+	// ContainerType is the type of the containers which the template is applied.
+	ContainerType types.ContainerType `json:"container-type"`
+	// Version is the template version.
+	Version int32 `json:"version"`
+	// UpdatedAt is the last update date in unix seconds.
+	UpdatedAt int64 `json:"updated-at"`
+}
+
+type MetricTemplateContainer struct {
+	// ContainerId is the container identifier.
+	ContainerId int32 `json:"container-id"`
+	// Name is the container name.
+	Name string `json:"name"`
+	// AppliedVersion is the template version last applied on the container.
+	AppliedVersion int32 `json:"applied-version"`
+	// AppliedAt is the date of the last apply in unix seconds.
+	AppliedAt int64 `json:"applied-at"`
+}
+
+// MetricTemplateLink is a container metric created by a template.
+type MetricTemplateLink struct {
+	// MetricId is the container metric identifier.
+	MetricId int64
+	// ContainerId is the container identifier.
+	ContainerId int32
+	// TemplateMetricId is the template metric identifier.
+	TemplateMetricId int64
+	// Applied is the definition applied on the metric by the template.
+	Applied MetricDefinition
+	// Current is the current metric definition.
+	Current MetricDefinition
+}
+
+type MetricTemplateDrift struct {
+	// ContainerId is the container identifier.
+	ContainerId int32 `json:"container-id"`
+	// MetricId is the container metric identifier, zero if missing.
+	MetricId int64 `json:"metric-id"`
+	// TemplateMetricId is the template metric identifier, zero if the
+	// metric was removed from the template.
+	TemplateMetricId int64 `json:"template-metric-id"`
+	// Name is the metric name.
+	Name string `json:"name"`
+	// Fields is the fields edited on the container metric.
+	Fields []string `json:"fields"`
+	// Missing is true if the container metric does not exists.
+	Missing bool `json:"missing"`
+}
+
+type MetricTemplateApplyForm struct {
+	// ContainersIds is the containers which the template is applied.
+	ContainersIds []int32 `json:"containers-ids" validate:"required,min=1,max=100,unique"`
+	// Force overwrites the metrics edited on the containers.
+	Force bool `json:"force" validate:"-"`
+}
+
+type MetricTemplateSyncResult struct {
+	// ContainerId is the container identifier.
+	ContainerId int32 `json:"container-id"`
+	// Created is the number of metrics created.
+	Created int `json:"created"`
+	// Updated is the number of metrics updated.
+	Updated int `json:"updated"`
+	// Deleted is the number of metrics deleted.
+	Deleted int `json:"deleted"`
+	// Skipped is the drifted metrics not changed.
+	Skipped []MetricTemplateDrift `json:"skipped"`
+}
@@ -0,0 +1,54 @@
+package models
+
+type OIDCProvider struct {
+	// Id is the provider identifier.
+	Id int32 `json:"id" validate:"-"`
+	// Ident is the provider identification, used on the login url.
+	Ident string `json:"ident" validate:"required,min=2,max=50"`
+	// Name is the provider name.
+	Name string `json:"name" validate:"required,max=50"`
+	// Enabled is the provider enabled state.
+	Enabled bool `json:"enabled" validate:"-"`
+	// Issuer is the provider issuer url, where the discovery document is.
+	Issuer string `json:"issuer" validate:"required,url,max=255"`
+	// ClientId is the client id.
+	ClientId string `json:"client-id" validate:"required,max=255"`
+	// ClientSecret is the client secret. Is optional for public clients.
+	ClientSecret string `json:"client-secret" validate:"max=255"`
+	// RedirectURL is the callback url registered on the provider.
+	RedirectURL string `json:"redirect-url" validate:"required,url,max=255"`
+	// Scopes are the requested scopes besides "openid".
+	Scopes []string `json:"scopes" validate:"max=10,dive,required,max=50"`
+	// UsernameClaim is the id token claim used as username.
+	UsernameClaim string `json:"username-claim" validate:"required,max=50"`
+	// GroupsClaim is the id token claim with the user groups.
+	GroupsClaim string `json:"groups-claim" validate:"max=50"`
+	// RolesGroups maps the groups to the user role.
+	RolesGroups []OIDCRoleGroup `json:"roles-groups" validate:"max=50,dive"`
+	// TeamsGroups maps the groups to the user teams memberships.
+	TeamsGroups []OIDCTeamGroup `json:"teams-groups" validate:"max=50,dive"`
+}
+
+// OIDCProviderInfo is the public information of an OpenID Connect provider.
+type OIDCProviderInfo struct {
+	// Ident is the provider identification.
+	Ident string `json:"ident"`
+	// Name is the provider name.
+	Name string `json:"name"`
+}
+
+type OIDCRoleGroup struct {
+	// Group is the group name.
+	Group string `json:"group" validate:"required,max=255"`
+	// Role is the user role.
+	Role uint8 `json:"role" validate:"required,min=1,max=4"`
+}
+
+type OIDCTeamGroup struct {
+	// Group is the group name.
+	Group string `json:"group" validate:"required,max=255"`
+	// Team is the team ident.
+	Team string `json:"team" validate:"required,max=50"`
+	// Role is the member team role.
+	Role uint8 `json:"role" validate:"required,min=1,max=3"`
+}
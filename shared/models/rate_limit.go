@@ -0,0 +1,40 @@
+package models
+
+const (
+	// RateLimitGroupDefault is the route group of the requests without a
+	// specific group.
+	RateLimitGroupDefault = "default"
+	// RateLimitGroupRealtime is the route group of the realtime data requests.
+	RateLimitGroupRealtime = "realtime"
+	// RateLimitGroupHistory is the route group of the data history and export
+	// requests.
+	RateLimitGroupHistory = "history"
+	// RateLimitGroupIngest is the route group of the metric data ingestion
+	// requests.
+	RateLimitGroupIngest = "ingest"
+)
+
+type RateLimit struct {
+	// Id is the rate limit identifier.
+	Id int32 `json:"id" validate:"-"`
+	// Group is the route group, "default", "realtime", "history" or "ingest".
+	Group string `json:"group" validate:"required,oneof=default realtime history ingest"`
+	// UserId restricts the rate limit to a user, zero for all users.
+	UserId int32 `json:"user-id" validate:"min=0"`
+	// APIKeyId restricts the rate limit to an API Key, zero for all API Keys.
+	// Can't be used with the UserId.
+	APIKeyId int32 `json:"apikey-id" validate:"min=0,excluded_with=UserId"`
+	// Requests is the max number of requests on each window, zero for
+	// unlimited.
+	Requests int32 `json:"requests" validate:"min=0"`
+	// Window is the window size in seconds.
+	Window int32 `json:"window" validate:"required,min=1,max=86400"`
+}
+
+type RateLimitPolicy struct {
+	// Requests is the max number of requests on each window, zero for
+	// unlimited.
+	Requests int32
+	// Window is the window size in seconds.
+	Window int32
+}
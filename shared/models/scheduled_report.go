@@ -0,0 +1,74 @@
+package models
+
+const (
+	ScheduledReportRunRunning = "running"
+	ScheduledReportRunDone    = "done"
+	ScheduledReportRunFailed  = "failed"
+)
+
+const (
+	ScheduledReportFormatCSV = "csv"
+	ScheduledReportFormatPDF = "pdf"
+)
+
+type ScheduledReport struct {
+	// Id is the report definition identifier.
+	Id int32 `json:"id" validate:"-"`
+	// ContextId is the context identifier.
+	ContextId int32 `json:"context-id" validate:"-"`
+	// Name is the report name, used on the email subject.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the report description.
+	Descr string `json:"descr" validate:"max=255"`
+	// Enabled is the schedule enabled state. Disabled reports can still
+	// be run manually.
+	Enabled bool `json:"enabled"`
+	// Schedule is the cron schedule, in UTC.
+	Schedule string `json:"schedule" validate:"required,max=100,cron"`
+	// TimeRange is the reported time range in seconds, until the run time.
+	TimeRange int32 `json:"time-range" validate:"required,min=3600,max=31536000"`
+	// Interval is the sample interval in seconds. Each sample is the mean
+	// of the interval.
+	Interval int32 `json:"interval" validate:"omitempty,min=10,max=86400"`
+	// Aggregations are the statistics computed for each contextual metric.
+	Aggregations []string `json:"aggregations" validate:"required,min=1,max=6,unique,dive,oneof=avg min max p95 p99 availability"`
+	// AlarmCounts enables the alarm counts by category of each contextual
+	// metric.
+	AlarmCounts bool `json:"alarm-counts"`
+	// ContextualMetricsIds is the contextual metrics of the report. Empty
+	// means all contextual metrics of the context.
+	ContextualMetricsIds []int64 `json:"contextual-metrics-ids" validate:"max=100,unique"`
+	// Selector is the label selector of the contextual metrics of the report,
+	// matched against the container labels merged with the metric labels.
+	// Can't be used with ContextualMetricsIds.
+	Selector string `json:"selector" validate:"max=1024,selector,excluded_with=ContextualMetricsIds"`
+	// Formats are the output files formats, "csv" and "pdf".
+	Formats []string `json:"formats" validate:"required,min=1,max=2,unique,dive,oneof=csv pdf"`
+	// Emails are the report recipients.
+	Emails []string `json:"emails" validate:"max=20,unique,dive,email,max=255"`
+	// NextRunAt is the next scheduled run date in unix seconds.
+	NextRunAt int64 `json:"next-run-at" validate:"-"`
+}
+
+type ScheduledReportRun struct {
+	// Id is the run identifier.
+	Id int64 `json:"id"`
+	// ReportId is the report definition identifier.
+	ReportId int32 `json:"report-id"`
+	// Status is the run status, "running", "done" or "failed".
+	Status string `json:"status"`
+	// Start is the reported range start in unix seconds.
+	Start int64 `json:"start"`
+	// Stop is the reported range stop in unix seconds.
+	Stop int64 `json:"stop"`
+	// Formats are the formats of the output files.
+	Formats []string `json:"formats"`
+	// Sent is true if the email was sent.
+	Sent bool `json:"sent"`
+	// Error is the error that failed the run or the email.
+	Error string `json:"error"`
+	// CreatedAt is the run creation date in unix seconds.
+	CreatedAt int64 `json:"created-at"`
+	// FinishedAt is the run finish date in unix seconds.
+	FinishedAt int64 `json:"finished-at"`
+}
@@ -1,5 +1,26 @@
 package models
 
+import "time"
+
 type ServicePong struct {
 	ServiceIdent string
+	// InfluxWriteBuffer is the influxdb write buffer status. Nil if the
+	// service doesn't write on influxdb.
+	InfluxWriteBuffer *InfluxWriteBufferStatus
+}
+
+type InfluxWriteBufferStatus struct {
+	// Enabled is the buffer enabled status.
+	Enabled bool `json:"enabled"`
+	// Segments is the number of buffered batches.
+	Segments int `json:"segments"`
+	// Points is the number of buffered points.
+	Points int64 `json:"points"`
+	// Size is the buffer size in bytes.
+	Size int64 `json:"size"`
+	// Dropped is the number of points dropped since the service start,
+	// due to the size or age limits.
+	Dropped int64 `json:"dropped"`
+	// Oldest is the time of the oldest buffered batch.
+	Oldest time.Time `json:"oldest"`
 }
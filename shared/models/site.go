@@ -0,0 +1,53 @@
+package models
+
+import "github.com/fernandotsda/nemesys/shared/types"
+
+const (
+	// SiteKindRegion is a region, may be inside other region.
+	SiteKindRegion = "region"
+	// SiteKindSite is a site, may be inside a region.
+	SiteKindSite = "site"
+	// SiteKindRack is a rack, must be inside a site.
+	SiteKindRack = "rack"
+)
+
+type Site struct {
+	// Id is the site identifier.
+	Id int32 `json:"id" validate:"-"`
+	// ParentId is the parent site identifier, zero if the site has no parent.
+	ParentId int32 `json:"parent-id" validate:"min=0"`
+	// Kind is the site kind, "region", "site" or "rack".
+	Kind string `json:"kind" validate:"required,oneof=region site rack"`
+	// Name is the site name.
+	Name string `json:"name" validate:"required,max=50"`
+	// Descr is the site description.
+	Descr string `json:"descr" validate:"max=255"`
+	// Latitude is the site latitude in degrees, nil if the site has no
+	// coordinates.
+	Latitude *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	// Longitude is the site longitude in degrees, nil if the site has no
+	// coordinates.
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+}
+
+type SiteContainer struct {
+	// Id is the container identifier.
+	Id int32 `json:"id"`
+	// Name is the container name.
+	Name string `json:"name"`
+	// Type is the container type.
+	Type types.ContainerType `json:"type"`
+	// Enabled is the container enabled state.
+	Enabled bool `json:"enabled"`
+}
+
+type SiteAlarmSummary struct {
+	// SiteId is the site identifier.
+	SiteId int32
+	// Containers is the number of containers on the site and its child sites.
+	Containers int32
+	// Alarmed is the number of alarmed metrics.
+	Alarmed int32
+	// Recognized is the number of recognized metrics.
+	Recognized int32
+}
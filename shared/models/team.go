@@ -15,3 +15,29 @@ type AddMemberReq struct {
 	// UserId is the user identifier.
 	UserId int32 `json:"user-id" validate:"required"`
 }
+
+type TeamMember struct {
+	// Id is the user identifier.
+	Id int32 `json:"id" validate:"required"`
+	// Role is the member team role. Default is viewer.
+	Role uint8 `json:"role" validate:"omitempty,min=1,max=3"`
+}
+
+type TeamMemberRole struct {
+	// Role is the member team role.
+	Role uint8 `json:"role" validate:"required,min=1,max=3"`
+}
+
+type Member struct {
+	User
+	// TeamRole is the member team role.
+	TeamRole uint8 `json:"team-role"`
+}
+
+type ContextRole struct {
+	// ContextId is the context identifier.
+	ContextId int32 `json:"ctx-id" validate:"required"`
+	// Role is the member role on the context, which replaces the member team
+	// role on the context.
+	Role uint8 `json:"role" validate:"required,min=1,max=3"`
+}
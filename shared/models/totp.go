@@ -0,0 +1,68 @@
+package models
+
+type LoginTOTP struct {
+	// Token is the login token returned by the password login.
+	Token string `json:"token" validate:"required,max=64"`
+	// Code is the TOTP code.
+	Code string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	// RecoveryCode is a recovery code, used instead of the code.
+	RecoveryCode string `json:"recovery-code" validate:"max=20"`
+}
+
+type LoginToken struct {
+	// Token is the login token.
+	Token string `json:"token" validate:"required,max=64"`
+}
+
+// LoginChallenge is returned by the password login when the second step is required.
+type LoginChallenge struct {
+	// Token is the login token used on the second step.
+	Token string `json:"token"`
+	// TOTPEnrollment is true if the user must enroll on the TOTP to login.
+	TOTPEnrollment bool `json:"totp-enrollment"`
+}
+
+type TOTPCode struct {
+	// Code is the TOTP code.
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type TOTPCodeOrRecovery struct {
+	// Code is the TOTP code.
+	Code string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	// RecoveryCode is a recovery code, used instead of the code.
+	RecoveryCode string `json:"recovery-code" validate:"max=20"`
+}
+
+type TOTPEnrollment struct {
+	// Secret is the base32 encoded secret.
+	Secret string `json:"secret"`
+	// URI is the provisioning uri, encoded on the QR code.
+	URI string `json:"uri"`
+}
+
+type TOTPStatus struct {
+	// Enabled is the TOTP enabled state.
+	Enabled bool `json:"enabled"`
+	// Required is true if the authentication policy requires the TOTP
+	// for the user role.
+	Required bool `json:"required"`
+	// RecoveryCodes is the number of unused recovery codes.
+	RecoveryCodes int `json:"recovery-codes"`
+}
+
+type RecoveryCodes struct {
+	// RecoveryCodes are the recovery codes, shown only once.
+	RecoveryCodes []string `json:"recovery-codes"`
+}
+
+type AuthPolicy struct {
+	// TOTPRequiredRole is the lowest role that must use TOTP. Zero means
+	// TOTP is optional for all roles.
+	TOTPRequiredRole uint8 `json:"totp-required-role" validate:"max=4"`
+}
+
+// TOTPRequired returns true if the policy requires TOTP for the role.
+func (p AuthPolicy) TOTPRequired(role uint8) bool {
+	return p.TOTPRequiredRole != 0 && role >= p.TOTPRequiredRole
+}
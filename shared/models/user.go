@@ -1,5 +1,19 @@
 package models
 
+const (
+	// UserProviderLocal is the provider of the users created on the api.
+	UserProviderLocal = "local"
+	// UserProviderLDAP is the provider of the users provisioned on the
+	// first LDAP login.
+	UserProviderLDAP = "ldap"
+)
+
+// UserProviderOIDC returns the provider of the users provisioned on the
+// first login on the OpenID Connect provider.
+func UserProviderOIDC(ident string) string {
+	return "oidc:" + ident
+}
+
 type User struct {
 	// Id is the user identifier.
 	Id int32 `json:"id" validate:"-"`
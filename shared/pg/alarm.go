@@ -1,27 +1,46 @@
 package pg
 
 import (
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"golang.org/x/net/context"
 )
 
 const (
 	sqlAlarmGetNotficationInfo = `WITH 
-		c AS (SELECT name FROM containers WHERE id = $1),
-		ca AS (SELECT name FROM alarm_categories WHERE id = $2),
+		c AS (SELECT name, labels FROM containers WHERE id = $1),
+		ca AS (SELECT name FROM alarm_categories WHERE id = $2)
 	SELECT name, container_type,
-		(SELECT * FROM c),
-		(SELECT * FROM ca) FROM metrics WHERE id = $3`
+		(SELECT name FROM c),
+		(SELECT name FROM ca),
+		(SELECT labels FROM c), labels FROM metrics WHERE id = $3`
 )
 
 func (pg *PG) GetAlarmNotificationInfo(ctx context.Context, metricId int64, containerId int32, categoryId int32) (info models.AlarmNotificationInfo, err error) {
 	info.MetricId = metricId
 	info.AlarmCategory.Id = categoryId
 	info.ContainerId = containerId
-	return info, pg.db.QueryRowContext(ctx, sqlAlarmGetNotficationInfo, containerId, categoryId, metricId).Scan(
+	var rawContainerLabels, rawMetricLabels []byte
+	err = pg.db.QueryRowContext(ctx, sqlAlarmGetNotficationInfo, containerId, categoryId, metricId).Scan(
 		&info.MetricName,
 		&info.ContainerType,
 		&info.ContainerName,
 		&info.AlarmCategory.Name,
+		&rawContainerLabels,
+		&rawMetricLabels,
 	)
+	if err != nil {
+		return info, err
+	}
+	var containerLabels, metricLabels map[string]string
+	err = unmarshalLabels(rawContainerLabels, &containerLabels)
+	if err != nil {
+		return info, err
+	}
+	err = unmarshalLabels(rawMetricLabels, &metricLabels)
+	if err != nil {
+		return info, err
+	}
+	info.Labels = labels.Merge(containerLabels, metricLabels)
+	return info, nil
 }
@@ -43,7 +43,7 @@ const (
 	sqlAlarmCategoriesLevelExists = `SELECT EXISTS (SELECT 1 FROM alarm_categories WHERE level = $1 AND id != $2);`
 	sqlAlarmCategoriesExists      = `SELECT EXISTS (SELECT 1 FROM alarm_categories WHERE id = $1);`
 
-	sqlAlarmCategoriesGetProfilesSimplified = `SELECT p.id, p.name FROM alarm_profiles p 
+	sqlAlarmCategoriesGetProfilesSimplified = `SELECT p.id, p.name, p.selector FROM alarm_profiles p 
 		LEFT JOIN alarm_profiles_categories_rel r ON r.profile_id = p.id WHERE r.category_id = $1;`
 	sqlAlarmCategoriesGetSimplifiedByIds    = `SELECT id, level FROM alarm_categories WHERE id = ANY($1) ORDER BY level DESC;`
 	sqlAlarmCategoriesCreateTrapIdRel       = `INSERT INTO traps_categories_rel (trap_id, category_id) VALUES ($1, $2);`
@@ -155,7 +155,7 @@ func (pg *PG) GetCategoryAlarmProfilesSimplified(ctx context.Context, id int32)
 	profiles = make([]models.AlarmProfileSimplified, 0)
 	var p models.AlarmProfileSimplified
 	for rows.Next() {
-		err = rows.Scan(&p.Id, &p.Name)
+		err = rows.Scan(&p.Id, &p.Name, &p.Selector)
 		if err != nil {
 			return nil, err
 		}
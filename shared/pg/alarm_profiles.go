@@ -44,9 +44,9 @@ type AlarmProfileExistsCategoryAndRelationResponse struct {
 }
 
 const (
-	sqlAlarmProfilesCreate         = `INSERT INTO alarm_profiles (name, descr) VALUES ($1, $2) RETURNING id;`
-	sqlAlarmProfilesUpdate         = `UPDATE alarm_profiles SET (name, descr) = ($1, $2) WHERE id = $3;`
-	sqlAlarmProfilesGet            = `SELECT name, descr FROM alarm_profiles WHERE id = $1;`
+	sqlAlarmProfilesCreate         = `INSERT INTO alarm_profiles (name, descr, selector) VALUES ($1, $2, $3) RETURNING id;`
+	sqlAlarmProfilesUpdate         = `UPDATE alarm_profiles SET (name, descr, selector) = ($1, $2, $3) WHERE id = $4;`
+	sqlAlarmProfilesGet            = `SELECT name, descr, selector FROM alarm_profiles WHERE id = $1;`
 	sqlAlarmProfilesDelete         = `DELETE FROM alarm_profiles WHERE id = $1;`
 	sqlAlarmProfilesExists         = `SELECT EXISTS (SELECT 1 FROM alarm_profiles WHERE id = $1);`
 	sqlAlarmProfilesAddCategory    = `INSERT INTO alarm_profiles_categories_rel (profile_id, category_id) VALUES($1, $2);`
@@ -64,13 +64,14 @@ const (
 	sqlAlarmProfilesDeleteEmail   = `DELETE FROM alarm_profiles_emails WHERE id = $1;`
 	sqlAlarmProfilesDeleteEmails  = `DELETE FROM alarm_profiles_emails WHERE alarm_profile_id = $1;`
 
-	customSqlAlarmProfilesMGet = `SELECT id, name, descr FROM alarm_profiles`
+	customSqlAlarmProfilesMGet = `SELECT id, name, descr, selector FROM alarm_profiles`
 )
 
 func (pg *PG) CreateAlarmProfile(ctx context.Context, profile models.AlarmProfile) (id int64, err error) {
 	return id, pg.db.QueryRowContext(ctx, sqlAlarmProfilesCreate,
 		profile.Name,
 		profile.Descr,
+		profile.Selector,
 	).Scan(&id)
 }
 
@@ -78,6 +79,7 @@ func (pg *PG) UpdateAlarmProfile(ctx context.Context, profile models.AlarmProfil
 	t, err := pg.db.ExecContext(ctx, sqlAlarmProfilesUpdate,
 		profile.Name,
 		profile.Descr,
+		profile.Selector,
 		profile.Id,
 	)
 	if err != nil {
@@ -91,6 +93,7 @@ func (pg *PG) GetAlarmProfile(ctx context.Context, id int32) (exists bool, profi
 	err = pg.db.QueryRowContext(ctx, sqlAlarmProfilesGet, id).Scan(
 		&profile.Name,
 		&profile.Descr,
+		&profile.Selector,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -119,6 +122,7 @@ func (pg *PG) GetAlarmProfiles(ctx context.Context, filters AlarmProfileQueryFil
 			&profile.Id,
 			&profile.Name,
 			&profile.Descr,
+			&profile.Selector,
 		)
 		if err != nil {
 			return nil, err
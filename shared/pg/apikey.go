@@ -2,31 +2,46 @@ package pg
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 
 	"github.com/fernandotsda/nemesys/shared/models"
 )
 
 const (
-	sqlAPIKeyCreate  = `INSERT INTO apikeys (user_id, created_at, descr, ttl) VALUES ($1, $2, $3, $4) RETURNING id;`
+	sqlAPIKeyCreate  = `INSERT INTO apikeys (user_id, created_at, descr, ttl, scopes, allowed_ips) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
 	sqlAPIKeyDelete  = `DELETE FROM apikeys WHERE id = $1 AND user_id = $2;`
 	sqlAPIKeyMDelete = `DELETE FROM apikeys WHERE id = ANY($1)`
-	sqlAPIKeyMGet    = `SELECT id, ttl, created_at, descr FROM apikeys WHERE user_id = $1;`
+	sqlAPIKeyMGet    = `SELECT id, ttl, created_at, descr, scopes, allowed_ips FROM apikeys WHERE user_id = $1;`
 )
 
-func (pg *PG) CreateAPIKey(ctx context.Context, apikey models.APIKeyInfo) (id int32, tx *sql.Tx, err error) {
+func (pg *PG) CreateAPIKey(ctx context.Context, apikey models.APIKeyInfo) (id int32, tx Tx, err error) {
+	if apikey.Scopes == nil {
+		apikey.Scopes = []models.APIKeyScope{}
+	}
+	if apikey.AllowedIPs == nil {
+		apikey.AllowedIPs = []string{}
+	}
+	scopes, err := json.Marshal(apikey.Scopes)
+	if err != nil {
+		return 0, nil, err
+	}
+	allowedIPs, err := json.Marshal(apikey.AllowedIPs)
+	if err != nil {
+		return 0, nil, err
+	}
 	tx, err = pg.db.BeginTx(ctx, nil)
 	if err != nil {
 		return 0, nil, err
 	}
-	err = tx.QueryRowContext(ctx, sqlAPIKeyCreate, apikey.UserId, apikey.CreatedAt, apikey.Descr, apikey.TTL).Scan(&id)
+	err = tx.QueryRowContext(ctx, sqlAPIKeyCreate, apikey.UserId, apikey.CreatedAt, apikey.Descr, apikey.TTL, scopes, allowedIPs).Scan(&id)
 	if err != nil {
+		tx.Rollback()
 		return 0, nil, err
 	}
 	return id, tx, nil
 }
 
-func (pg *PG) DeleteAPIKey(ctx context.Context, id int16, userId int32) (exists bool, tx *sql.Tx, err error) {
+func (pg *PG) DeleteAPIKey(ctx context.Context, id int16, userId int32) (exists bool, tx Tx, err error) {
 	tx, err = pg.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, nil, err
@@ -54,9 +69,18 @@ func (pg *PG) GetAPIKeys(ctx context.Context, userId int32) (keys []models.APIKe
 	}
 	defer rows.Close()
 	keys = []models.APIKeyInfo{}
-	var key models.APIKeyInfo
+	var scopes, allowedIPs []byte
 	for rows.Next() {
-		err = rows.Scan(&key.Id, &key.TTL, &key.CreatedAt, &key.Descr)
+		var key models.APIKeyInfo
+		err = rows.Scan(&key.Id, &key.TTL, &key.CreatedAt, &key.Descr, &scopes, &allowedIPs)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(scopes, &key.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(allowedIPs, &key.AllowedIPs)
 		if err != nil {
 			return nil, err
 		}
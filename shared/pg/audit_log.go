@@ -0,0 +1,134 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+var AuditLogValidOrderByColumns = []string{"id", "user_id", "route", "created_at"}
+
+type AuditLogQueryFilters struct {
+	UserId    int32  `type:"=" column:"user_id"`
+	APIKeyId  int32  `type:"=" column:"apikey_id"`
+	Method    string `type:"=" column:"method"`
+	Route     string `type:"ilike" column:"route"`
+	Entity    string `type:"ilike" column:"entity"`
+	Status    int16  `type:"=" column:"status"`
+	IP        string `type:"=" column:"ip"`
+	From      int64  `type:">=" column:"created_at"`
+	To        int64  `type:"<=" column:"created_at"`
+	OrderBy   string
+	OrderByFn string
+	Limit     int
+	Offset    int
+}
+
+func (f AuditLogQueryFilters) GetOrderBy() string {
+	return f.OrderBy
+}
+
+func (f AuditLogQueryFilters) GetOrderByFn() string {
+	return f.OrderByFn
+}
+
+func (f AuditLogQueryFilters) GetLimit() int {
+	return f.Limit
+}
+
+func (f AuditLogQueryFilters) GetOffset() int {
+	return f.Offset
+}
+
+const (
+	sqlAuditLogCreate = `INSERT INTO audit_log 
+		(user_id, apikey_id, role, method, route, entity, status, before, after, diff, ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id;`
+	sqlAuditLogDeleteBefore = `DELETE FROM audit_log WHERE created_at < $1;`
+	customSqlAuditLogMGet   = `SELECT id, user_id, apikey_id, role, method, route, entity, status,
+		before, after, diff, ip, created_at FROM audit_log`
+)
+
+func (pg *PG) CreateAuditEntry(ctx context.Context, e models.AuditEntry) (id int64, err error) {
+	diff, err := json.Marshal(e.Diff)
+	if err != nil {
+		return 0, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlAuditLogCreate,
+		e.UserId,
+		e.APIKeyId,
+		e.Role,
+		e.Method,
+		e.Route,
+		e.Entity,
+		e.Status,
+		nullJSON(e.Before),
+		nullJSON(e.After),
+		string(diff),
+		e.IP,
+		e.CreatedAt,
+	).Scan(&id)
+}
+
+func (pg *PG) GetAuditEntries(ctx context.Context, filters AuditLogQueryFilters) (entries []models.AuditEntry, err error) {
+	sql, params, err := applyFilters(filters, customSqlAuditLogMGet, AuditLogValidOrderByColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries = make([]models.AuditEntry, 0, filters.Limit)
+	for rows.Next() {
+		var (
+			e    models.AuditEntry
+			diff []byte
+		)
+		err = rows.Scan(
+			&e.Id,
+			&e.UserId,
+			&e.APIKeyId,
+			&e.Role,
+			&e.Method,
+			&e.Route,
+			&e.Entity,
+			&e.Status,
+			&e.Before,
+			&e.After,
+			&diff,
+			&e.IP,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(diff, &e.Diff)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// DeleteAuditEntriesBefore deletes the audit entries created before the
+// unix date, returning the number of deleted entries.
+func (pg *PG) DeleteAuditEntriesBefore(ctx context.Context, date int64) (n int64, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlAuditLogDeleteBefore, date)
+	if err != nil {
+		return 0, err
+	}
+	return t.RowsAffected()
+}
+
+// nullJSON returns nil if the JSON is empty, otherwise the JSON as string.
+func nullJSON(b json.RawMessage) any {
+	if len(b) == 0 {
+		return nil
+	}
+	return string(b)
+}
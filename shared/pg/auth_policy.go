@@ -0,0 +1,21 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+const (
+	sqlAuthPolicyGet    = `SELECT totp_required_role FROM auth_policy WHERE id = 1;`
+	sqlAuthPolicyUpdate = `UPDATE auth_policy SET totp_required_role = $1 WHERE id = 1;`
+)
+
+func (pg *PG) GetAuthPolicy(ctx context.Context) (policy models.AuthPolicy, err error) {
+	return policy, pg.db.QueryRowContext(ctx, sqlAuthPolicyGet).Scan(&policy.TOTPRequiredRole)
+}
+
+func (pg *PG) UpdateAuthPolicy(ctx context.Context, policy models.AuthPolicy) (err error) {
+	_, err = pg.db.ExecContext(ctx, sqlAuthPolicyUpdate, policy.TOTPRequiredRole)
+	return err
+}
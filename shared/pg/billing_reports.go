@@ -0,0 +1,127 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	sqlBillingReportsCreate = `INSERT INTO billing_reports (ctx_id, month, sample_interval, tier, created_at, metrics, total, selector)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`
+	sqlBillingReportsGet = `SELECT month, sample_interval, tier, created_at, metrics, total, selector
+		FROM billing_reports WHERE id = $1 AND ctx_id = $2;`
+	sqlBillingReportsMGet = `SELECT id, month, sample_interval, tier, created_at, metrics, total, selector
+		FROM billing_reports WHERE ctx_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3;`
+	sqlBillingReportsDelete = `DELETE FROM billing_reports WHERE id = $1 AND ctx_id = $2;`
+)
+
+func (pg *PG) CreateBillingReport(ctx context.Context, r models.BillingReport) (id int64, err error) {
+	metricsBytes, err := msgpack.Marshal(r.Metrics)
+	if err != nil {
+		return 0, err
+	}
+	totalBytes, err := msgpack.Marshal(r.Total)
+	if err != nil {
+		return 0, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlBillingReportsCreate,
+		r.ContextId,
+		r.Month,
+		r.Interval,
+		r.Tier,
+		r.CreatedAt,
+		metricsBytes,
+		totalBytes,
+		r.Selector,
+	).Scan(&id)
+}
+
+func (pg *PG) GetBillingReport(ctx context.Context, ctxId int32, id int64) (exists bool, r models.BillingReport, err error) {
+	var mbytes, tbytes []byte
+	err = pg.db.QueryRowContext(ctx, sqlBillingReportsGet, id, ctxId).Scan(
+		&r.Month,
+		&r.Interval,
+		&r.Tier,
+		&r.CreatedAt,
+		&mbytes,
+		&tbytes,
+		&r.Selector,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, r, nil
+		}
+		return false, r, err
+	}
+	r.Id = id
+	r.ContextId = ctxId
+	err = decodeBillingReport(&r, mbytes, tbytes)
+	if err != nil {
+		return false, r, err
+	}
+	return true, r, nil
+}
+
+func (pg *PG) GetBillingReports(ctx context.Context, ctxId int32, limit int, offset int) (reports []models.BillingReport, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlBillingReportsMGet, ctxId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports = make([]models.BillingReport, 0, limit)
+	var mbytes, tbytes []byte
+	for rows.Next() {
+		var r models.BillingReport
+		err = rows.Scan(
+			&r.Id,
+			&r.Month,
+			&r.Interval,
+			&r.Tier,
+			&r.CreatedAt,
+			&mbytes,
+			&tbytes,
+			&r.Selector,
+		)
+		if err != nil {
+			return nil, err
+		}
+		r.ContextId = ctxId
+		err = decodeBillingReport(&r, mbytes, tbytes)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+func (pg *PG) DeleteBillingReport(ctx context.Context, ctxId int32, id int64) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlBillingReportsDelete, id, ctxId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// decodeBillingReport decodes the report metrics and total, setting the
+// contextual metrics ids.
+func decodeBillingReport(r *models.BillingReport, mbytes []byte, tbytes []byte) (err error) {
+	err = msgpack.Unmarshal(mbytes, &r.Metrics)
+	if err != nil {
+		return err
+	}
+	err = msgpack.Unmarshal(tbytes, &r.Total)
+	if err != nil {
+		return err
+	}
+	r.ContextualMetricsIds = make([]int64, len(r.Metrics))
+	for i, m := range r.Metrics {
+		r.ContextualMetricsIds[i] = m.ContextualMetricId
+	}
+	return nil
+}
@@ -12,7 +12,7 @@ import (
 
 type PG struct {
 	// db is the connection db.
-	db *sql.DB
+	db database
 }
 
 func New() *PG {
@@ -36,7 +36,12 @@ func New() *PG {
 	db.SetConnMaxLifetime(time.Duration(lifetime) * time.Second)
 	db.SetConnMaxIdleTime(time.Duration(idleLifetime) * time.Second)
 
-	return &PG{db: db}
+	return &PG{db: poolDatabase{DB: db}}
+}
+
+// NewWithDB returns a PG using an opened database.
+func NewWithDB(db *sql.DB) *PG {
+	return &PG{db: poolDatabase{DB: db}}
 }
 
 func (pg *PG) Close() {
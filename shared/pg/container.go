@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -18,6 +19,8 @@ type BasicContainersQueryFilters struct {
 	CreatedAtStart int64               `type:">=" column:"created_at"`
 	CreatedAtStop  int64               `type:"<=" column:"created_at"`
 	Enabled        *bool               `type:"=" column:"enabled"`
+	TeamId         int32               `type:"=" column:"team_id"`
+	Labels         labels.Selector     `type:"selector" column:"labels"`
 	OrderBy        string
 	OrderByFn      string
 	Limit          int
@@ -55,20 +58,25 @@ type BaseContainerEnabledResponse struct {
 }
 
 const (
-	sqlContainersCreate        = `INSERT INTO containers (name, descr, type, enabled, rts_pulling_interval, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
-	sqlContainersGet           = `SELECT name, descr, enabled, rts_pulling_interval, created_at FROM containers WHERE id = $1 AND type = $2;`
-	sqlContainersUpdate        = `UPDATE containers SET (name, descr, enabled, rts_pulling_interval) = ($1, $2, $3, $4) WHERE id = $5;`
+	sqlContainersCreate        = `INSERT INTO containers (name, descr, type, enabled, rts_pulling_interval, created_at, team_id, labels) VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, 0), $8) RETURNING id;`
+	sqlContainersGet           = `SELECT name, descr, enabled, rts_pulling_interval, created_at, COALESCE(team_id, 0), labels FROM containers WHERE id = $1 AND type = $2;`
+	sqlContainersUpdate        = `UPDATE containers SET (name, descr, enabled, rts_pulling_interval, team_id, labels) = ($1, $2, $3, $4, NULLIF($5, 0), $6) WHERE id = $7;`
 	sqlContainersDelete        = `DELETE FROM containers WHERE id = $1;`
 	sqlContainersMGet          = `SELECT id, name, descr, enabled, rts_pulling_interval, created_at FROM containers WHERE type = $1 LIMIT $2 OFFSET $3;`
 	sqlContainersGetRTSInfo    = `SELECT rts_pulling_interval FROM containers WHERE id = $1;`
 	sqlContainersExists        = `SELECT EXISTS (SELECT 1 FROM containers WHERE id = $1);`
 	sqlContainersEnabled       = `SELECT enabled FROM containers WHERE id = $1;`
 	sqlContainersMGetIdEnabled = `SELECT id FROM containers WHERE enabled = true AND type = $1 LIMIT $2 OFFSET $3;`
+	sqlContainersGetTeam       = `SELECT COALESCE(team_id, 0) FROM containers WHERE id = $1;`
 
-	customSqlContainersMGet = `SELECT b.id, b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at FROM containers b`
+	customSqlContainersMGet = `SELECT b.id, b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at, COALESCE(b.team_id, 0), b.labels FROM containers b`
 )
 
 func (pg *PG) CreateBasicContainer(ctx context.Context, container models.Container[struct{}]) (id int32, err error) {
+	rawLabels, err := marshalLabels(container.Base.Labels)
+	if err != nil {
+		return id, err
+	}
 	return id, pg.db.QueryRowContext(ctx, sqlContainersCreate,
 		container.Base.Name,
 		container.Base.Descr,
@@ -76,10 +84,16 @@ func (pg *PG) CreateBasicContainer(ctx context.Context, container models.Contain
 		container.Base.Enabled,
 		container.Base.RTSPullingInterval,
 		time.Now().Unix(),
+		container.Base.TeamId,
+		rawLabels,
 	).Scan(&id)
 }
 
-func (pg *PG) createContainer(ctx context.Context, tx *sql.Tx, container models.BaseContainer) (id int32, err error) {
+func (pg *PG) createContainer(ctx context.Context, tx Tx, container models.BaseContainer) (id int32, err error) {
+	rawLabels, err := marshalLabels(container.Labels)
+	if err != nil {
+		return id, err
+	}
 	return id, tx.QueryRowContext(ctx, sqlContainersCreate,
 		container.Name,
 		container.Descr,
@@ -87,15 +101,23 @@ func (pg *PG) createContainer(ctx context.Context, tx *sql.Tx, container models.
 		container.Enabled,
 		container.RTSPullingInterval,
 		time.Now().Unix(),
+		container.TeamId,
+		rawLabels,
 	).Scan(&id)
 }
 
 func (pg *PG) UpdateBasicContainer(ctx context.Context, container models.Container[struct{}]) (exists bool, err error) {
+	rawLabels, err := marshalLabels(container.Base.Labels)
+	if err != nil {
+		return false, err
+	}
 	t, err := pg.db.ExecContext(ctx, sqlContainersUpdate,
 		container.Base.Name,
 		container.Base.Descr,
 		container.Base.Enabled,
 		container.Base.RTSPullingInterval,
+		container.Base.TeamId,
+		rawLabels,
 		container.Base.Id,
 	)
 	if err != nil {
@@ -105,12 +127,18 @@ func (pg *PG) UpdateBasicContainer(ctx context.Context, container models.Contain
 	return rowsAffected != 0, err
 }
 
-func (pg *PG) updateContainer(ctx context.Context, tx *sql.Tx, container models.BaseContainer) (exists bool, err error) {
+func (pg *PG) updateContainer(ctx context.Context, tx Tx, container models.BaseContainer) (exists bool, err error) {
+	rawLabels, err := marshalLabels(container.Labels)
+	if err != nil {
+		return false, err
+	}
 	t, err := tx.ExecContext(ctx, sqlContainersUpdate,
 		container.Name,
 		container.Descr,
 		container.Enabled,
 		container.RTSPullingInterval,
+		container.TeamId,
+		rawLabels,
 		container.Id,
 	)
 	if err != nil {
@@ -141,6 +169,7 @@ func (pg *PG) GetContainer(ctx context.Context, id int32, t types.ContainerType)
 		return false, container, err
 	}
 	defer rows.Close()
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&container.Name,
@@ -148,10 +177,16 @@ func (pg *PG) GetContainer(ctx context.Context, id int32, t types.ContainerType)
 			&container.Enabled,
 			&container.RTSPullingInterval,
 			&container.CreatedAt,
+			&container.TeamId,
+			&rawLabels,
 		)
 		if err != nil {
 			return false, container, err
 		}
+		err = unmarshalLabels(rawLabels, &container.Labels)
+		if err != nil {
+			return false, container, err
+		}
 		container.Id = id
 		container.Type = t
 		exists = true
@@ -173,6 +208,7 @@ func (pg *PG) GetBasicContainers(ctx context.Context, filters BasicContainersQue
 	containers = make([]models.Container[struct{}], 0, filters.Limit)
 	container := models.Container[struct{}]{}
 	container.Base.Type = types.CTBasic
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&container.Base.Id,
@@ -181,10 +217,16 @@ func (pg *PG) GetBasicContainers(ctx context.Context, filters BasicContainersQue
 			&container.Base.Enabled,
 			&container.Base.RTSPullingInterval,
 			&container.Base.CreatedAt,
+			&container.Base.TeamId,
+			&rawLabels,
 		)
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &container.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		containers = append(containers, container)
 	}
 	return containers, nil
@@ -210,6 +252,19 @@ func (pg *PG) ContainerExist(ctx context.Context, id int32) (exists bool, err er
 	return exists, pg.db.QueryRowContext(ctx, sqlContainersExists, id).Scan(&exists)
 }
 
+// GetContainerTeam returns the container team id, or zero if the container
+// has no team.
+func (pg *PG) GetContainerTeam(ctx context.Context, id int32) (exists bool, teamId int32, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlContainersGetTeam, id).Scan(&teamId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+	return true, teamId, nil
+}
+
 func (pg *PG) ContainerEnabled(ctx context.Context, id int32) (exists bool, enabled bool, err error) {
 	rows, err := pg.db.QueryContext(ctx, sqlContainersEnabled, id)
 	if err != nil {
@@ -2,7 +2,10 @@ package pg
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 )
 
@@ -89,6 +92,25 @@ const (
 	sqlCtxMetricsDelete = `DELETE FROM contextual_metrics WHERE id = $1;`
 	sqlCtxMetricsGet    = `SELECT ctx_id, metric_id, ident, name, descr FROM contextual_metrics WHERE id = $1;`
 
+	sqlCtxMetricsMGetMetadata = `SELECT c.team_id, c.id, c.ident, c.name, cm.id, cm.ident, cm.name,
+		m.enabled, m.id, m.type, m.container_id, m.container_type, m.data_policy_id FROM contextual_metrics cm
+		JOIN contexts c ON c.id = cm.ctx_id
+		JOIN metrics m ON m.id = cm.metric_id
+		WHERE cm.ctx_id = $1 ORDER BY cm.id LIMIT $2;`
+	sqlCtxMetricsMGetMetadataByIds = `SELECT c.team_id, c.id, c.ident, c.name, cm.id, cm.ident, cm.name,
+		m.enabled, m.id, m.type, m.container_id, m.container_type, m.data_policy_id FROM contextual_metrics cm
+		JOIN contexts c ON c.id = cm.ctx_id
+		JOIN metrics m ON m.id = cm.metric_id
+		WHERE cm.ctx_id = $1 AND cm.id = ANY($2) ORDER BY cm.id LIMIT $3;`
+	customSqlCtxMetricsMGetMetadataBySelector = `SELECT c.team_id, c.id, c.ident, c.name, cm.id, cm.ident, cm.name,
+		m.enabled, m.id, m.type, m.container_id, m.container_type, m.data_policy_id FROM contextual_metrics cm
+		JOIN contexts c ON c.id = cm.ctx_id
+		JOIN metrics m ON m.id = cm.metric_id
+		JOIN containers ct ON ct.id = m.container_id
+		WHERE cm.ctx_id = $1`
+	sqlCtxMetricsCountByTeam = `SELECT COUNT(*) FROM contextual_metrics cm
+		JOIN contexts c ON c.id = cm.ctx_id WHERE c.team_id = $1 AND cm.id = ANY($2);`
+
 	customSqlCtxMetricsMGet = `SELECT id, metric_id, ident, name, descr FROM contextual_metrics`
 )
 
@@ -239,3 +261,73 @@ func (pg *PG) DeleteContextualMetric(ctx context.Context, id int64) (exists bool
 	rowsAffected, _ := t.RowsAffected()
 	return rowsAffected != 0, err
 }
+
+// GetContextualMetricsMetadata returns the metadata of the context contextual metrics. If ids
+// is empty, returns all contextual metrics of the context, up to the limit.
+func (pg *PG) GetContextualMetricsMetadata(ctx context.Context, contextId int32, ids []int64, limit int) (metadata []models.ContextualMetricMetadata, err error) {
+	var rows *sql.Rows
+	if len(ids) == 0 {
+		rows, err = pg.db.QueryContext(ctx, sqlCtxMetricsMGetMetadata, contextId, limit)
+	} else {
+		rows, err = pg.db.QueryContext(ctx, sqlCtxMetricsMGetMetadataByIds, contextId, ids, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scanContextualMetricsMetadata(rows)
+}
+
+// GetContextualMetricsMetadataBySelector returns the metadata of the context contextual
+// metrics which container labels merged with the metric labels match the selector, up to
+// the limit.
+func (pg *PG) GetContextualMetricsMetadataBySelector(ctx context.Context, contextId int32, selector labels.Selector, limit int) (metadata []models.ContextualMetricMetadata, err error) {
+	statements, params, err := getSelectorStatements("(ct.labels || m.labels)", selector, 2)
+	if err != nil {
+		return nil, err
+	}
+	query := customSqlCtxMetricsMGetMetadataBySelector
+	for _, s := range statements {
+		query += " AND " + s
+	}
+	query += fmt.Sprintf(" ORDER BY cm.id LIMIT $%d;", len(params)+2)
+	params = append([]any{contextId}, params...)
+	rows, err := pg.db.QueryContext(ctx, query, append(params, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return scanContextualMetricsMetadata(rows)
+}
+
+func scanContextualMetricsMetadata(rows *sql.Rows) (metadata []models.ContextualMetricMetadata, err error) {
+	defer rows.Close()
+	metadata = make([]models.ContextualMetricMetadata, 0)
+	var m models.ContextualMetricMetadata
+	for rows.Next() {
+		err = rows.Scan(
+			&m.TeamId,
+			&m.ContextId,
+			&m.ContextIdent,
+			&m.ContextName,
+			&m.Id,
+			&m.Ident,
+			&m.Name,
+			&m.Enabled,
+			&m.MetricRequest.MetricId,
+			&m.MetricRequest.MetricType,
+			&m.MetricRequest.ContainerId,
+			&m.MetricRequest.ContainerType,
+			&m.MetricRequest.DataPolicyId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		metadata = append(metadata, m)
+	}
+	return metadata, rows.Err()
+}
+
+// CountTeamContextualMetrics returns how many of the contextual metrics are on the
+// contexts of the team.
+func (pg *PG) CountTeamContextualMetrics(ctx context.Context, teamId int32, ids []int64) (n int, err error) {
+	return n, pg.db.QueryRowContext(ctx, sqlCtxMetricsCountByTeam, teamId, ids).Scan(&n)
+}
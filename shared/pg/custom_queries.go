@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 var CustomQueryValidOrderByColumns = []string{"descr", "ident"}
@@ -35,31 +36,42 @@ func (f CustomQueryQueryFilters) GetOffset() int {
 }
 
 const (
-	sqlCustomQueriesCreate      = `INSERT INTO custom_queries (ident, descr, flux) VALUES ($1, $2, $3) RETURNING id;`
-	sqlCustomQueriesUpdate      = `UPDATE custom_queries SET (ident, descr, flux) = ($1, $2, $3) WHERE id = $4;`
-	sqlCustomQueriesGet         = `SELECT ident, descr, flux FROM custom_queries WHERE id = $1;`
-	sqlCustomQueriesGetByIdent  = `SELECT id, descr, flux FROM custom_queries WHERE ident = $1;`
+	sqlCustomQueriesCreate      = `INSERT INTO custom_queries (ident, descr, flux, params) VALUES ($1, $2, $3, $4) RETURNING id;`
+	sqlCustomQueriesUpdate      = `UPDATE custom_queries SET (ident, descr, flux, params) = ($1, $2, $3, $4) WHERE id = $5;`
+	sqlCustomQueriesGet         = `SELECT ident, descr, flux, params FROM custom_queries WHERE id = $1;`
+	sqlCustomQueriesGetByIdent  = `SELECT id, descr, flux, params FROM custom_queries WHERE ident = $1;`
 	sqlCustomQueriesDelete      = `DELETE FROM custom_queries WHERE id = $1;`
 	sqlCustomQueriesExistsIdent = `SELECT 
 		EXISTS (SELECT 1 FROM custom_queries WHERE id != $1 AND ident = $2),
 		EXISTS (SELECT 1 FROM custom_queries WHERE id = $1);`
+	sqlCustomQueriesCount = `SELECT COUNT(*) FROM custom_queries WHERE id = ANY($1);`
 
-	customSqlCustomQueriesMGet = `SELECT id, ident, descr, flux FROM custom_queries`
+	customSqlCustomQueriesMGet = `SELECT id, ident, descr, flux, params FROM custom_queries`
 )
 
 func (pg *PG) CreateCustomQuery(ctx context.Context, cq models.CustomQuery) (id int32, err error) {
+	paramsBytes, err := msgpack.Marshal(cq.Params)
+	if err != nil {
+		return 0, err
+	}
 	return id, pg.db.QueryRowContext(ctx, sqlCustomQueriesCreate,
 		cq.Ident,
 		cq.Descr,
 		cq.Flux,
+		paramsBytes,
 	).Scan(&id)
 }
 
 func (pg *PG) UpdateCustomQuery(ctx context.Context, cq models.CustomQuery) (exists bool, err error) {
+	paramsBytes, err := msgpack.Marshal(cq.Params)
+	if err != nil {
+		return false, err
+	}
 	t, err := pg.db.ExecContext(ctx, sqlCustomQueriesUpdate,
 		cq.Ident,
 		cq.Descr,
 		cq.Flux,
+		paramsBytes,
 		cq.Id,
 	)
 	if err != nil {
@@ -80,27 +92,35 @@ func (pg *PG) GetCustomQueries(ctx context.Context, filters CustomQueryQueryFilt
 	}
 	defer rows.Close()
 	cqs = make([]models.CustomQuery, 0, filters.Limit)
-	var cq models.CustomQuery
+	var pbytes []byte
 	for rows.Next() {
+		var cq models.CustomQuery
 		err = rows.Scan(
 			&cq.Id,
 			&cq.Ident,
 			&cq.Descr,
 			&cq.Flux,
+			&pbytes,
 		)
 		if err != nil {
 			return nil, err
 		}
+		err = msgpack.Unmarshal(pbytes, &cq.Params)
+		if err != nil {
+			return nil, err
+		}
 		cqs = append(cqs, cq)
 	}
 	return cqs, nil
 }
 
 func (pg *PG) GetCustomQuery(ctx context.Context, id int32) (exists bool, cq models.CustomQuery, err error) {
+	var pbytes []byte
 	err = pg.db.QueryRowContext(ctx, sqlCustomQueriesGet, id).Scan(
 		&cq.Ident,
 		&cq.Descr,
 		&cq.Flux,
+		&pbytes,
 	)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -109,14 +129,20 @@ func (pg *PG) GetCustomQuery(ctx context.Context, id int32) (exists bool, cq mod
 		return false, cq, nil
 	}
 	cq.Id = id
+	err = msgpack.Unmarshal(pbytes, &cq.Params)
+	if err != nil {
+		return false, cq, err
+	}
 	return true, cq, nil
 }
 
 func (pg *PG) GetCustomQueryByIdent(ctx context.Context, ident string) (exists bool, cq models.CustomQuery, err error) {
+	var pbytes []byte
 	err = pg.db.QueryRowContext(ctx, sqlCustomQueriesGetByIdent, ident).Scan(
 		&cq.Id,
 		&cq.Descr,
 		&cq.Flux,
+		&pbytes,
 	)
 	if err != nil {
 		if err != sql.ErrNoRows {
@@ -125,6 +151,10 @@ func (pg *PG) GetCustomQueryByIdent(ctx context.Context, ident string) (exists b
 		return false, cq, nil
 	}
 	cq.Ident = ident
+	err = msgpack.Unmarshal(pbytes, &cq.Params)
+	if err != nil {
+		return false, cq, err
+	}
 	return true, cq, nil
 }
 
@@ -143,3 +173,8 @@ func (pg *PG) DeleteCustomQuery(ctx context.Context, id int32) (exists bool, err
 func (pg *PG) ExistsCustomQueryIdent(ctx context.Context, id int32, ident string) (existsCq bool, identExists bool, err error) {
 	return existsCq, identExists, pg.db.QueryRowContext(ctx, sqlCustomQueriesExistsIdent, id, ident).Scan(&identExists, &existsCq)
 }
+
+// CountCustomQueries returns how many of the custom queries exists.
+func (pg *PG) CountCustomQueries(ctx context.Context, ids []int32) (n int, err error) {
+	return n, pg.db.QueryRowContext(ctx, sqlCustomQueriesCount, ids).Scan(&n)
+}
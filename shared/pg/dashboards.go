@@ -0,0 +1,293 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+var DashboardValidOrderByColumns = []string{"name", "descr", "updated_at"}
+
+type DashboardQueryFilters struct {
+	// ViewerTeamId is the team which owns or has the dashboards shared with.
+	ViewerTeamId int32  `type:"=" column:"viewer_team_id"`
+	Name         string `type:"ilike" column:"name"`
+	Descr        string `type:"ilike" column:"descr"`
+	OrderBy      string
+	OrderByFn    string
+	Limit        int
+	Offset       int
+}
+
+func (f DashboardQueryFilters) GetOrderBy() string {
+	return f.OrderBy
+}
+
+func (f DashboardQueryFilters) GetOrderByFn() string {
+	return f.OrderByFn
+}
+
+func (f DashboardQueryFilters) GetLimit() int {
+	return f.Limit
+}
+
+func (f DashboardQueryFilters) GetOffset() int {
+	return f.Offset
+}
+
+const (
+	sqlDashboardsCreate = `WITH d AS (
+			INSERT INTO dashboards (team_id, name, descr, time_range, refresh_interval, panels, version, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, 1, $7)
+			RETURNING id, version, name, descr, time_range, refresh_interval, panels, updated_at
+		) INSERT INTO dashboards_versions (dashboard_id, version, user_id, name, descr, time_range, refresh_interval, panels, created_at)
+		SELECT id, version, NULLIF($8, 0), name, descr, time_range, refresh_interval, panels, updated_at FROM d
+		RETURNING dashboard_id;`
+	sqlDashboardsUpdate = `WITH d AS (
+			UPDATE dashboards SET (name, descr, time_range, refresh_interval, panels, version, updated_at)
+			= ($1, $2, $3, $4, $5, version + 1, $6) WHERE id = $7 AND team_id = $8
+			RETURNING id, version, name, descr, time_range, refresh_interval, panels, updated_at
+		) INSERT INTO dashboards_versions (dashboard_id, version, user_id, name, descr, time_range, refresh_interval, panels, created_at)
+		SELECT id, version, NULLIF($9, 0), name, descr, time_range, refresh_interval, panels, updated_at FROM d
+		RETURNING version;`
+	sqlDashboardsRollback = `WITH u AS (
+			UPDATE dashboards d SET (name, descr, time_range, refresh_interval, panels, version, updated_at)
+			= (v.name, v.descr, v.time_range, v.refresh_interval, v.panels, d.version + 1, $4)
+			FROM dashboards_versions v
+			WHERE d.id = $1 AND d.team_id = $2 AND v.dashboard_id = d.id AND v.version = $3
+			RETURNING d.id, d.version, d.name, d.descr, d.time_range, d.refresh_interval, d.panels, d.updated_at
+		) INSERT INTO dashboards_versions (dashboard_id, version, user_id, name, descr, time_range, refresh_interval, panels, created_at)
+		SELECT id, version, NULLIF($5, 0), name, descr, time_range, refresh_interval, panels, updated_at FROM u
+		RETURNING version;`
+	sqlDashboardsDelete = `DELETE FROM dashboards WHERE id = $1 AND team_id = $2;`
+	sqlDashboardsGet    = `SELECT team_id, name, descr, time_range, refresh_interval, panels, version, updated_at
+		FROM dashboards WHERE id = $1 AND (team_id = $2
+		OR EXISTS (SELECT 1 FROM dashboards_shares WHERE dashboard_id = $1 AND team_id = $2));`
+	sqlDashboardsGetVersion = `SELECT name, descr, time_range, refresh_interval, panels, created_at
+		FROM dashboards_versions WHERE dashboard_id = $1 AND version = $2;`
+	sqlDashboardsMGetVersions = `SELECT version, COALESCE(user_id, 0), created_at FROM dashboards_versions
+		WHERE dashboard_id = $1 ORDER BY version DESC LIMIT $2 OFFSET $3;`
+	sqlDashboardsShare      = `INSERT INTO dashboards_shares (dashboard_id, team_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	sqlDashboardsUnshare    = `DELETE FROM dashboards_shares WHERE dashboard_id = $1 AND team_id = $2;`
+	sqlDashboardsMGetShares = `SELECT t.id, t.name, t.ident, t.descr FROM dashboards_shares s
+		JOIN teams t ON t.id = s.team_id WHERE s.dashboard_id = $1 ORDER BY t.id;`
+	sqlDashboardsContextualMetricShared = `SELECT EXISTS (SELECT 1 FROM dashboards d
+		JOIN dashboards_shares s ON s.dashboard_id = d.id
+		JOIN users_teams ut ON ut.team_id = s.team_id AND ut.user_id = $3
+		JOIN contexts c ON c.team_id = d.team_id AND c.id = $2
+		JOIN contextual_metrics cm ON cm.ctx_id = c.id AND cm.id = $4
+		CROSS JOIN jsonb_array_elements(d.panels) p
+		WHERE d.team_id = $1 AND p->'contextual-metrics-ids' @> to_jsonb(cm.id));`
+
+	customSqlDashboardsMGet = `SELECT id, team_id, name, descr, version, updated_at FROM (
+			SELECT id, team_id, name, descr, version, updated_at, team_id AS viewer_team_id FROM dashboards
+			UNION ALL
+			SELECT d.id, d.team_id, d.name, d.descr, d.version, d.updated_at, s.team_id FROM dashboards d
+			JOIN dashboards_shares s ON s.dashboard_id = d.id
+		) AS dashboards`
+)
+
+func marshalPanels(panels []models.DashboardPanel) (string, error) {
+	if panels == nil {
+		panels = []models.DashboardPanel{}
+	}
+	b, err := json.Marshal(panels)
+	return string(b), err
+}
+
+// CreateDashboard creates the dashboard and its first version, created by the user.
+func (pg *PG) CreateDashboard(ctx context.Context, d models.Dashboard, userId int32) (id int32, err error) {
+	panels, err := marshalPanels(d.Panels)
+	if err != nil {
+		return id, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlDashboardsCreate,
+		d.TeamId,
+		d.Name,
+		d.Descr,
+		d.TimeRange,
+		d.RefreshInterval,
+		panels,
+		d.UpdatedAt,
+		userId,
+	).Scan(&id)
+}
+
+// UpdateDashboard updates the team dashboard and saves the new version, created by the
+// user. Returns the new version.
+func (pg *PG) UpdateDashboard(ctx context.Context, d models.Dashboard, userId int32) (exists bool, version int32, err error) {
+	panels, err := marshalPanels(d.Panels)
+	if err != nil {
+		return false, version, err
+	}
+	err = pg.db.QueryRowContext(ctx, sqlDashboardsUpdate,
+		d.Name,
+		d.Descr,
+		d.TimeRange,
+		d.RefreshInterval,
+		panels,
+		d.UpdatedAt,
+		d.Id,
+		d.TeamId,
+		userId,
+	).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, version, nil
+		}
+		return false, version, err
+	}
+	return true, version, nil
+}
+
+// RollbackDashboard sets the team dashboard to the content of the version, saving it as
+// a new version created by the user. Returns false if the dashboard or the version does
+// not exists.
+func (pg *PG) RollbackDashboard(ctx context.Context, teamId int32, id int32, version int32, updatedAt int64, userId int32) (exists bool, newVersion int32, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlDashboardsRollback, id, teamId, version, updatedAt, userId).Scan(&newVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, newVersion, nil
+		}
+		return false, newVersion, err
+	}
+	return true, newVersion, nil
+}
+
+func (pg *PG) DeleteDashboard(ctx context.Context, teamId int32, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlDashboardsDelete, id, teamId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// GetDashboard returns the dashboard if the team owns it or has it shared with.
+func (pg *PG) GetDashboard(ctx context.Context, teamId int32, id int32) (exists bool, d models.Dashboard, err error) {
+	var panels []byte
+	err = pg.db.QueryRowContext(ctx, sqlDashboardsGet, id, teamId).Scan(
+		&d.TeamId,
+		&d.Name,
+		&d.Descr,
+		&d.TimeRange,
+		&d.RefreshInterval,
+		&panels,
+		&d.Version,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, d, nil
+		}
+		return false, d, err
+	}
+	d.Id = id
+	return true, d, json.Unmarshal(panels, &d.Panels)
+}
+
+// GetDashboards returns the dashboards that the filters viewer team owns or has shared with.
+func (pg *PG) GetDashboards(ctx context.Context, filters DashboardQueryFilters) (dashboards []models.DashboardSimplified, err error) {
+	sql, params, err := applyFilters(filters, customSqlDashboardsMGet, DashboardValidOrderByColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	dashboards = make([]models.DashboardSimplified, 0, filters.Limit)
+	var d models.DashboardSimplified
+	for rows.Next() {
+		err = rows.Scan(&d.Id, &d.TeamId, &d.Name, &d.Descr, &d.Version, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		dashboards = append(dashboards, d)
+	}
+	return dashboards, rows.Err()
+}
+
+// GetDashboardVersion returns the dashboard content on the version. The UpdatedAt is
+// the version creation date.
+func (pg *PG) GetDashboardVersion(ctx context.Context, id int32, version int32) (exists bool, d models.Dashboard, err error) {
+	var panels []byte
+	err = pg.db.QueryRowContext(ctx, sqlDashboardsGetVersion, id, version).Scan(
+		&d.Name,
+		&d.Descr,
+		&d.TimeRange,
+		&d.RefreshInterval,
+		&panels,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, d, nil
+		}
+		return false, d, err
+	}
+	d.Id = id
+	d.Version = version
+	return true, d, json.Unmarshal(panels, &d.Panels)
+}
+
+// GetDashboardVersions returns the dashboard versions, newest first.
+func (pg *PG) GetDashboardVersions(ctx context.Context, id int32, limit int, offset int) (versions []models.DashboardVersion, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlDashboardsMGetVersions, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions = make([]models.DashboardVersion, 0, limit)
+	var v models.DashboardVersion
+	for rows.Next() {
+		err = rows.Scan(&v.Version, &v.UserId, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (pg *PG) ShareDashboard(ctx context.Context, id int32, teamId int32) error {
+	_, err := pg.db.ExecContext(ctx, sqlDashboardsShare, id, teamId)
+	return err
+}
+
+func (pg *PG) UnshareDashboard(ctx context.Context, id int32, teamId int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlDashboardsUnshare, id, teamId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// GetDashboardShares returns the teams the dashboard is shared with.
+func (pg *PG) GetDashboardShares(ctx context.Context, id int32) (teams []models.Team, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlDashboardsMGetShares, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	teams = make([]models.Team, 0)
+	var t models.Team
+	for rows.Next() {
+		err = rows.Scan(&t.Id, &t.Name, &t.Ident, &t.Descr)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+// ContextualMetricShared returns true if the contextual metric of the team
+// context is on a panel of a team dashboard shared with a team of the user.
+func (pg *PG) ContextualMetricShared(ctx context.Context, teamId int32, ctxId int32, userId int32, ctxMetricId int64) (shared bool, err error) {
+	return shared, pg.db.QueryRowContext(ctx, sqlDashboardsContextualMetricShared, teamId, ctxId, userId, ctxMetricId).Scan(&shared)
+}
@@ -3,17 +3,22 @@ package pg
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/fernandotsda/nemesys/shared/models"
 )
 
 const (
-	sqlDPCreate = `INSERT INTO data_policies (name, descr, use_aggr, retention, aggr_retention, aggr_interval, aggr_fn) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`
-	sqlDPUpdate = `UPDATE data_policies SET (name, descr, retention, use_aggr, aggr_retention, aggr_interval, aggr_fn) = ($1, $2, $3, $4, $5, $6, $7) WHERE id = $8;`
-	sqlDPDelete = `DELETE FROM data_policies WHERE id = $1;`
-	sqlDPGet    = `SELECT id, name, descr, retention, use_aggr, aggr_retention, aggr_interval, aggr_fn FROM data_policies WHERE id = $1;`
-	sqlDPMGet   = `SELECT id, name, descr, retention, use_aggr, aggr_retention, aggr_interval, aggr_fn FROM data_policies;`
-	sqlDPCount  = `SELECT COUNT(*) FROM data_policies;`
+	sqlDPCreate      = `INSERT INTO data_policies (name, descr, retention, aggr_fn, aggr_fns) VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	sqlDPUpdate      = `UPDATE data_policies SET (name, descr, retention, aggr_fn, aggr_fns) = ($1, $2, $3, $4, $5) WHERE id = $6;`
+	sqlDPDelete      = `DELETE FROM data_policies WHERE id = $1;`
+	sqlDPGet         = `SELECT id, name, descr, retention, aggr_fn, aggr_fns FROM data_policies WHERE id = $1;`
+	sqlDPMGet        = `SELECT id, name, descr, retention, aggr_fn, aggr_fns FROM data_policies;`
+	sqlDPCount       = `SELECT COUNT(*) FROM data_policies;`
+	sqlDPTierCreate  = `INSERT INTO data_policies_tiers (data_policy_id, tier, retention, aggr_interval) VALUES ($1, $2, $3, $4);`
+	sqlDPTiersDelete = `DELETE FROM data_policies_tiers WHERE data_policy_id = $1;`
+	sqlDPTiersGet    = `SELECT retention, aggr_interval FROM data_policies_tiers WHERE data_policy_id = $1 ORDER BY tier;`
+	sqlDPTiersMGet   = `SELECT data_policy_id, retention, aggr_interval FROM data_policies_tiers ORDER BY data_policy_id, tier;`
 )
 
 func (pg *PG) CountDataPolicy(ctx context.Context) (n int64, err error) {
@@ -21,7 +26,7 @@ func (pg *PG) CountDataPolicy(ctx context.Context) (n int64, err error) {
 	return n, err
 }
 
-func (pg *PG) CreateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx *sql.Tx, id int16, err error) {
+func (pg *PG) CreateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx Tx, id int16, err error) {
 	c, err := pg.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, id, err
@@ -29,19 +34,23 @@ func (pg *PG) CreateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx *s
 	err = c.QueryRowContext(ctx, sqlDPCreate,
 		dp.Name,
 		dp.Descr,
-		dp.UseAggr,
 		dp.Retention,
-		dp.AggrRetention,
-		dp.AggrInterval,
 		dp.AggrFn,
+		strings.Join(dp.AggrFns, ","),
 	).Scan(&id)
 	if err != nil {
+		c.Rollback()
+		return nil, id, err
+	}
+	err = createDataPolicyTiers(ctx, c, id, dp.Tiers)
+	if err != nil {
+		c.Rollback()
 		return nil, id, err
 	}
 	return c, id, nil
 }
 
-func (pg *PG) UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx *sql.Tx, exists bool, err error) {
+func (pg *PG) UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx Tx, exists bool, err error) {
 	c, err := pg.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, false, err
@@ -50,17 +59,40 @@ func (pg *PG) UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) (tx *s
 		dp.Name,
 		dp.Descr,
 		dp.Retention,
-		dp.UseAggr,
-		dp.AggrRetention,
-		dp.AggrInterval,
 		dp.AggrFn,
+		strings.Join(dp.AggrFns, ","),
 		dp.Id,
 	)
 	if err != nil {
+		c.Rollback()
 		return nil, false, err
 	}
 	rowsAffected, _ := t.RowsAffected()
-	return c, rowsAffected != 0, err
+	if rowsAffected == 0 {
+		c.Rollback()
+		return nil, false, nil
+	}
+	_, err = c.ExecContext(ctx, sqlDPTiersDelete, dp.Id)
+	if err != nil {
+		c.Rollback()
+		return nil, false, err
+	}
+	err = createDataPolicyTiers(ctx, c, dp.Id, dp.Tiers)
+	if err != nil {
+		c.Rollback()
+		return nil, false, err
+	}
+	return c, true, nil
+}
+
+func createDataPolicyTiers(ctx context.Context, tx Tx, id int16, tiers []models.DataPolicyTier) (err error) {
+	for i, tier := range tiers {
+		_, err = tx.ExecContext(ctx, sqlDPTierCreate, id, i+1, tier.Retention, tier.Interval)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (pg *PG) DeleteDataPolicy(ctx context.Context, id int16) (exists bool, err error) {
@@ -73,28 +105,38 @@ func (pg *PG) DeleteDataPolicy(ctx context.Context, id int16) (exists bool, err
 }
 
 func (pg *PG) GetDataPolicy(ctx context.Context, id int16) (exists bool, dp models.DataPolicy, err error) {
-	rows, err := pg.db.QueryContext(ctx, sqlDPMGet)
+	var aggrFns string
+	err = pg.db.QueryRowContext(ctx, sqlDPGet, id).Scan(
+		&dp.Id,
+		&dp.Name,
+		&dp.Descr,
+		&dp.Retention,
+		&dp.AggrFn,
+		&aggrFns,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, dp, nil
+		}
+		return false, dp, err
+	}
+	dp.AggrFns = splitAggrFns(aggrFns)
+
+	rows, err := pg.db.QueryContext(ctx, sqlDPTiersGet, id)
 	if err != nil {
 		return false, dp, err
 	}
 	defer rows.Close()
+	dp.Tiers = []models.DataPolicyTier{}
+	var tier models.DataPolicyTier
 	for rows.Next() {
-		err = rows.Scan(
-			&dp.Id,
-			&dp.Name,
-			&dp.Descr,
-			&dp.Retention,
-			&dp.UseAggr,
-			&dp.AggrRetention,
-			&dp.AggrInterval,
-			&dp.AggrFn,
-		)
+		err = rows.Scan(&tier.Retention, &tier.Interval)
 		if err != nil {
 			return false, dp, err
 		}
-		exists = true
+		dp.Tiers = append(dp.Tiers, tier)
 	}
-	return exists, dp, err
+	return true, dp, rows.Err()
 }
 
 func (pg *PG) GetDataPolicies(ctx context.Context) (dps []models.DataPolicy, err error) {
@@ -105,21 +147,51 @@ func (pg *PG) GetDataPolicies(ctx context.Context) (dps []models.DataPolicy, err
 	}
 	defer rows.Close()
 	var dp models.DataPolicy
+	var aggrFns string
 	for rows.Next() {
 		err = rows.Scan(
 			&dp.Id,
 			&dp.Name,
 			&dp.Descr,
 			&dp.Retention,
-			&dp.UseAggr,
-			&dp.AggrRetention,
-			&dp.AggrInterval,
 			&dp.AggrFn,
+			&aggrFns,
 		)
 		if err != nil {
 			return nil, err
 		}
+		dp.AggrFns = splitAggrFns(aggrFns)
+		dp.Tiers = []models.DataPolicyTier{}
 		dps = append(dps, dp)
 	}
-	return dps, err
+	rows.Close()
+
+	rows, err = pg.db.QueryContext(ctx, sqlDPTiersMGet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var id int16
+	var tier models.DataPolicyTier
+	for rows.Next() {
+		err = rows.Scan(&id, &tier.Retention, &tier.Interval)
+		if err != nil {
+			return nil, err
+		}
+		for i := range dps {
+			if dps[i].Id == id {
+				dps[i].Tiers = append(dps[i].Tiers, tier)
+				break
+			}
+		}
+	}
+	return dps, rows.Err()
+}
+
+// splitAggrFns splits the comma separated aggregation functions.
+func splitAggrFns(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
 }
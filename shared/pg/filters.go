@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+
+	"github.com/fernandotsda/nemesys/shared/labels"
 )
 
 type queryFilters2 interface {
@@ -35,7 +37,8 @@ func validateOrderFn(fn string) error {
 }
 
 // applyFilters apply the queryFilters in the provided sql. The queryFilters may NOT have any
-// unexported field, otherwise will panic.
+// unexported field, otherwise will panic. The fields of type "selector" must be a
+// labels.Selector and its column a JSONB column.
 func applyFilters(queryFilters queryFilters2, sql string, allowedColumns []string) (sqlResult string, params []any, err error) {
 	v := reflect.ValueOf(queryFilters)
 	typeof := reflect.TypeOf(queryFilters)
@@ -58,9 +61,23 @@ func applyFilters(queryFilters queryFilters2, sql string, allowedColumns []strin
 			continue
 		}
 
-		index := len(statements) + 1
+		index := len(params) + 1
 		column := field.Tag.Get("column")
 
+		if operator == "selector" {
+			selector, ok := fieldValue.(labels.Selector)
+			if !ok {
+				return "", nil, ErrInvalidFilterValue
+			}
+			selectorStatements, selectorParams, err := getSelectorStatements(column, selector, index)
+			if err != nil {
+				return "", nil, err
+			}
+			statements = append(statements, selectorStatements...)
+			params = append(params, selectorParams...)
+			continue
+		}
+
 		statements = append(statements, getStatement(column, operator, index))
 
 		fieldValueS, ok := fieldValue.(string)
@@ -91,7 +108,7 @@ func applyFilters(queryFilters queryFilters2, sql string, allowedColumns []strin
 		statementMerged += fmt.Sprintf(` ORDER BY %s %s`, orderBy, orderByFn)
 	}
 
-	statementMerged += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(statements)+1, len(statements)+2)
+	statementMerged += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(params)+1, len(params)+2)
 	params = append(params, queryFilters.GetLimit(), queryFilters.GetOffset())
 
 	return sql + statementMerged, params, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -17,12 +18,14 @@ type FlexLegacyContainerQueryFilters struct {
 	CreatedAtStart int64               `type:">=" column:"created_at"`
 	CreatedAtStop  int64               `type:"<=" column:"created_at"`
 	Enabled        *bool               `type:"=" column:"enabled"`
+	TeamId         int32               `type:"=" column:"team_id"`
 	Target         string              `type:"ilike" column:"target"`
 	SerialNumber   string              `type:"=" column:"serial_number"`
 	Model          int16               `type:"=" column:"model"`
 	City           string              `type:"ilike" column:"city"`
 	Region         string              `type:"ilike" column:"region"`
 	Country        string              `type:"ilike" column:"country"`
+	Labels         labels.Selector     `type:"selector" column:"labels"`
 	Limit          int
 	Offset         int
 	OrderBy        string
@@ -64,7 +67,7 @@ const (
 	sqlFlexLegacyContainersGetProtocol = `SELECT 
 		target, port, transport, community, retries, max_oids, timeout, serial_number, model, city, region, country
 		FROM flex_legacy_containers WHERE container_id = $1;`
-	sqlFlexLegacyContainersGet = `SELECT b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at,
+	sqlFlexLegacyContainersGet = `SELECT b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at, COALESCE(b.team_id, 0), b.labels,
 		p.target, p.port, p.transport, p.community, p.retries, p.max_oids, p.timeout, p.serial_number, p.model, p.city, p.region, p.country
 		FROM containers b FULL JOIN flex_legacy_containers p ON p.container_id = b.id WHERE b.id = $1;`
 	sqlFlexLegacyContainersGetSNMPConfig = `SELECT
@@ -77,7 +80,7 @@ const (
 	sqlFlexLegacyContainersCount         = `SELECT COUNT(*) FROM flex_legacy_containers;`
 	sqlFlexLegacyContainersGetIdByTarget = `SELECT container_id FROM flex_legacy_containers WHERE target = $1;`
 
-	customSqlFlexLegacyContainersMGet = `SELECT b.id, b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at,
+	customSqlFlexLegacyContainersMGet = `SELECT b.id, b.name, b.descr, b.enabled, b.rts_pulling_interval, b.created_at, COALESCE(b.team_id, 0), b.labels,
 	p.target, p.port, p.transport, p.community, p.retries, p.max_oids, p.timeout, p.serial_number, p.model, p.city, p.region, p.country
 	FROM containers b FULL JOIN flex_legacy_containers p ON p.container_id = b.id`
 )
@@ -169,6 +172,7 @@ func (pg *PG) GetFlexLegacyContainers(ctx context.Context, filters FlexLegacyCon
 	containers = make([]models.Container[models.FlexLegacyContainer], 0, filters.Limit)
 	container := models.Container[models.FlexLegacyContainer]{}
 	container.Base.Type = filters.Type
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&container.Base.Id,
@@ -177,6 +181,8 @@ func (pg *PG) GetFlexLegacyContainers(ctx context.Context, filters FlexLegacyCon
 			&container.Base.Enabled,
 			&container.Base.RTSPullingInterval,
 			&container.Base.CreatedAt,
+			&container.Base.TeamId,
+			&rawLabels,
 			&container.Protocol.Target,
 			&container.Protocol.Port,
 			&container.Protocol.Transport,
@@ -193,6 +199,10 @@ func (pg *PG) GetFlexLegacyContainers(ctx context.Context, filters FlexLegacyCon
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &container.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		container.Protocol.Id = container.Base.Id
 		containers = append(containers, container)
 	}
@@ -200,12 +210,15 @@ func (pg *PG) GetFlexLegacyContainers(ctx context.Context, filters FlexLegacyCon
 }
 
 func (pg *PG) GetFlexLegacyContainer(ctx context.Context, id int32) (exists bool, container models.Container[models.FlexLegacyContainer], err error) {
+	var rawLabels []byte
 	err = pg.db.QueryRowContext(ctx, sqlFlexLegacyContainersGet, id).Scan(
 		&container.Base.Name,
 		&container.Base.Descr,
 		&container.Base.Enabled,
 		&container.Base.RTSPullingInterval,
 		&container.Base.CreatedAt,
+		&container.Base.TeamId,
+		&rawLabels,
 		&container.Protocol.Target,
 		&container.Protocol.Port,
 		&container.Protocol.Transport,
@@ -225,6 +238,10 @@ func (pg *PG) GetFlexLegacyContainer(ctx context.Context, id int32) (exists bool
 		}
 		return false, container, err
 	}
+	err = unmarshalLabels(rawLabels, &container.Base.Labels)
+	if err != nil {
+		return false, container, err
+	}
 	container.Base.Type = types.CTFlexLegacy
 	container.Base.Id = id
 	container.Protocol.Id = id
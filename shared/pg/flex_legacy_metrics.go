@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -19,6 +20,7 @@ type FlexLegacyMetricQueryFilters struct {
 	Enabled       *bool               `type:"=" column:"enabled"`
 	Port          int16               `type:"=" column:"port"`
 	PortType      int16               `type:"=" column:"port_type"`
+	Labels        labels.Selector     `type:"selector" column:"labels"`
 	OrderBy       string
 	OrderByFn     string
 	Limit         int
@@ -46,7 +48,7 @@ const (
 	sqlFlexLegacyMetricsUpdate = `UPDATE flex_legacy_metrics SET (oid, port, port_type) = ($2, $3, $4) WHERE metric_id = $1;`
 	sqlFlexLegacyMetricsGet    = `SELECT 
 		b.container_id, b.name, b.descr, b.enabled, b.data_policy_id, 
-		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, 
+		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, b.labels,
 		p.oid, p.port, p.port_type FROM metrics b FULL JOIN flex_legacy_metrics p ON p.metric_id = b.id WHERE id = $1;`
 	sqlFlexLegacyMetricsGetProtocol          = `SELECT oid, port, port_type FROM flex_legacy_metrics WHERE metric_id = $1;`
 	sqlFlexLegacyMetricsGetAsSNMPMetric      = `SELECT oid FROM flex_legacy_metrics WHERE metric_id = $1;`
@@ -59,7 +61,7 @@ const (
 		WHERE m.container_id = $1 AND fm.port = $2 AND fm.port_type = $3`
 	customSqlFlexLegacyMetricsMGet = `SELECT 
 		b.id, b.container_id, b.name, b.descr, b.enabled, b.data_policy_id, 
-		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, 
+		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, b.labels,
 		p.oid, p.port, p.port_type FROM metrics b FULL JOIN flex_legacy_metrics p ON p.metric_id = b.id`
 )
 
@@ -114,6 +116,7 @@ func (pg *PG) UpdateFlexLegacyMetric(ctx context.Context, metric models.Metric[m
 }
 
 func (pg *PG) GetFlexLegacyMetric(ctx context.Context, id int64) (exists bool, metric models.Metric[models.FlexLegacyMetric], err error) {
+	var rawLabels []byte
 	err = pg.db.QueryRowContext(ctx, sqlFlexLegacyMetricsGet, id).Scan(
 		&metric.Base.ContainerId,
 		&metric.Base.Name,
@@ -126,6 +129,7 @@ func (pg *PG) GetFlexLegacyMetric(ctx context.Context, id int64) (exists bool, m
 		&metric.Base.DHSInterval,
 		&metric.Base.Type,
 		&metric.Base.EvaluableExpression,
+		&rawLabels,
 		&metric.Protocol.OID,
 		&metric.Protocol.Port,
 		&metric.Protocol.PortType,
@@ -136,6 +140,10 @@ func (pg *PG) GetFlexLegacyMetric(ctx context.Context, id int64) (exists bool, m
 		}
 		return false, metric, err
 	}
+	err = unmarshalLabels(rawLabels, &metric.Base.Labels)
+	if err != nil {
+		return false, metric, err
+	}
 	metric.Base.Id = id
 	metric.Base.ContainerType = types.CTFlexLegacy
 	metric.Protocol.Id = id
@@ -157,6 +165,7 @@ func (pg *PG) GetFlexLegacyMetrics(ctx context.Context, filters FlexLegacyMetric
 	var metric models.Metric[models.FlexLegacyMetric]
 	metric.Base.ContainerId = filters.ContainerId
 	metric.Base.ContainerType = types.CTFlexLegacy
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&metric.Base.Id,
@@ -171,6 +180,7 @@ func (pg *PG) GetFlexLegacyMetrics(ctx context.Context, filters FlexLegacyMetric
 			&metric.Base.DHSInterval,
 			&metric.Base.Type,
 			&metric.Base.EvaluableExpression,
+			&rawLabels,
 			&metric.Protocol.OID,
 			&metric.Protocol.Port,
 			&metric.Protocol.PortType,
@@ -178,6 +188,10 @@ func (pg *PG) GetFlexLegacyMetrics(ctx context.Context, filters FlexLegacyMetric
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &metric.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		metric.Protocol.Id = metric.Base.Id
 		metrics = append(metrics, metric)
 	}
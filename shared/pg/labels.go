@@ -0,0 +1,62 @@
+package pg
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fernandotsda/nemesys/shared/labels"
+)
+
+// marshalLabels marshals the labels to be saved on a JSONB column.
+func marshalLabels(l map[string]string) ([]byte, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(l)
+}
+
+// unmarshalLabels unmarshals the labels of a JSONB column.
+func unmarshalLabels(b []byte, l *map[string]string) error {
+	*l = nil
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, l)
+}
+
+// getSelectorStatements returns the statements and params of the selector
+// requirements on the JSONB column. The first param index is index.
+func getSelectorStatements(column string, selector labels.Selector, index int) (statements []string, params []any, err error) {
+	statements = make([]string, 0, len(selector))
+	for _, r := range selector {
+		i := index + len(params)
+		switch r.Operator {
+		case labels.Equals, labels.NotEquals:
+			b, err := json.Marshal(map[string]string{r.Key: r.Values[0]})
+			if err != nil {
+				return nil, nil, err
+			}
+			statement := fmt.Sprintf("%s @> $%d::jsonb", column, i)
+			if r.Operator == labels.NotEquals {
+				statement = "NOT " + statement
+			}
+			statements = append(statements, statement)
+			params = append(params, string(b))
+		case labels.In:
+			statements = append(statements, fmt.Sprintf("%s ->> $%d = ANY($%d)", column, i, i+1))
+			params = append(params, r.Key, r.Values)
+		case labels.NotIn:
+			statements = append(statements, fmt.Sprintf("NOT COALESCE(%s ->> $%d = ANY($%d), false)", column, i, i+1))
+			params = append(params, r.Key, r.Values)
+		case labels.Exists:
+			statements = append(statements, fmt.Sprintf("jsonb_exists(%s, $%d)", column, i))
+			params = append(params, r.Key)
+		case labels.NotExists:
+			statements = append(statements, fmt.Sprintf("NOT jsonb_exists(%s, $%d)", column, i))
+			params = append(params, r.Key)
+		default:
+			return nil, nil, ErrInvalidFilterValue
+		}
+	}
+	return statements, params, nil
+}
@@ -0,0 +1,330 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+type MetricTemplateReferencesExistsResponse struct {
+	// DataPoliciesExists is the data policies existence.
+	DataPoliciesExists bool
+	// AlarmExpressionsExists is the alarm expressions existence.
+	AlarmExpressionsExists bool
+}
+
+const (
+	sqlMetricTemplatesCreate = `INSERT INTO metric_templates (name, descr, container_type, metrics, next_metric_id, version, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 1, $6) RETURNING id;`
+	sqlMetricTemplatesUpdate = `UPDATE metric_templates SET (name, descr, metrics, next_metric_id, version, updated_at)
+		= ($1, $2, $3, $4, version + 1, $5) WHERE id = $6 RETURNING version;`
+	sqlMetricTemplatesDelete = `DELETE FROM metric_templates WHERE id = $1;`
+	sqlMetricTemplatesGet    = `SELECT name, descr, container_type, metrics, next_metric_id, version, updated_at
+		FROM metric_templates WHERE id = $1;`
+	sqlMetricTemplatesGetForUpdate = `SELECT name, descr, container_type, metrics, next_metric_id, version, updated_at
+		FROM metric_templates WHERE id = $1 FOR UPDATE;`
+	sqlMetricTemplatesMGet = `SELECT id, name, descr, container_type, version, updated_at
+		FROM metric_templates ORDER BY id LIMIT $1 OFFSET $2;`
+	sqlMetricTemplatesNameExists       = `SELECT EXISTS (SELECT 1 FROM metric_templates WHERE name = $1 AND id != $2);`
+	sqlMetricTemplatesReferencesExists = `SELECT
+		(SELECT count(*) FROM data_policies WHERE id = ANY ($1)) = $2,
+		(SELECT count(*) FROM alarm_expressions WHERE id = ANY ($3)) = $4;`
+
+	sqlMetricTemplatesContainersLink = `INSERT INTO metric_templates_containers (template_id, container_id, applied_version, applied_at)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (template_id, container_id)
+		DO UPDATE SET (applied_version, applied_at) = ($3, $4);`
+	sqlMetricTemplatesContainersUnlink = `DELETE FROM metric_templates_containers WHERE template_id = $1 AND container_id = $2;`
+	sqlMetricTemplatesContainersMGet   = `SELECT l.container_id, c.name, l.applied_version, l.applied_at
+		FROM metric_templates_containers l JOIN containers c ON c.id = l.container_id
+		WHERE l.template_id = $1 ORDER BY l.container_id LIMIT $2 OFFSET $3;`
+	sqlMetricTemplatesContainersMGetIds = `SELECT container_id FROM metric_templates_containers
+		WHERE template_id = $1 ORDER BY container_id;`
+
+	sqlMetricTemplatesLinksCreate = `INSERT INTO metric_templates_links (metric_id, template_id, container_id, template_metric_id, applied)
+		VALUES ($1, $2, $3, $4, $5);`
+	sqlMetricTemplatesLinksUpdate = `UPDATE metric_templates_links SET applied = $1 WHERE metric_id = $2;`
+	sqlMetricTemplatesLinksDelete = `DELETE FROM metric_templates_links WHERE metric_id = $1;`
+	sqlMetricTemplatesLinksMGet   = `SELECT l.metric_id, l.container_id, l.template_metric_id, l.applied,
+		m.type, m.name, m.descr, m.enabled, m.data_policy_id, m.rts_pulling_times, m.rts_data_cache_duration,
+		m.dhs_enabled, m.dhs_interval, m.ev_expression, m.labels,
+		COALESCE(s.oid, f.oid, ''), COALESCE(f.port, 0), COALESCE(f.port_type, 0),
+		(SELECT COALESCE(jsonb_agg(r.expression_id ORDER BY r.expression_id), '[]')
+			FROM metrics_alarm_expressions_rel r WHERE r.metric_id = m.id)
+		FROM metric_templates_links l
+		JOIN metrics m ON m.id = l.metric_id
+		LEFT JOIN snmpv2c_metrics s ON s.metric_id = m.id
+		LEFT JOIN flex_legacy_metrics f ON f.metric_id = m.id
+		WHERE l.template_id = $1 AND ($2 = 0 OR l.container_id = $2)
+		ORDER BY l.container_id, l.template_metric_id;`
+)
+
+func marshalTemplateMetrics(metrics []models.MetricTemplateMetric) (string, error) {
+	b, err := json.Marshal(metrics)
+	return string(b), err
+}
+
+// CreateMetricTemplate creates the metric template. The metrics must have
+// the ids set, lower than nextMetricId.
+func (pg *PG) CreateMetricTemplate(ctx context.Context, t models.MetricTemplate, nextMetricId int64) (id int32, err error) {
+	metrics, err := marshalTemplateMetrics(t.Metrics)
+	if err != nil {
+		return id, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlMetricTemplatesCreate,
+		t.Name,
+		t.Descr,
+		t.ContainerType,
+		metrics,
+		nextMetricId,
+		t.UpdatedAt,
+	).Scan(&id)
+}
+
+// UpdateMetricTemplate updates the metric template, the container type is
+// not updated. Returns the new version.
+func (pg *PG) UpdateMetricTemplate(ctx context.Context, t models.MetricTemplate, nextMetricId int64) (exists bool, version int32, err error) {
+	metrics, err := marshalTemplateMetrics(t.Metrics)
+	if err != nil {
+		return false, version, err
+	}
+	err = pg.db.QueryRowContext(ctx, sqlMetricTemplatesUpdate,
+		t.Name,
+		t.Descr,
+		metrics,
+		nextMetricId,
+		t.UpdatedAt,
+		t.Id,
+	).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, version, nil
+		}
+		return false, version, err
+	}
+	return true, version, nil
+}
+
+// DeleteMetricTemplate deletes the metric template and its links, the
+// containers metrics are kept.
+func (pg *PG) DeleteMetricTemplate(ctx context.Context, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlMetricTemplatesDelete, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) getMetricTemplate(ctx context.Context, query string, id int32) (exists bool, t models.MetricTemplate, nextMetricId int64, err error) {
+	var metrics []byte
+	err = pg.db.QueryRowContext(ctx, query, id).Scan(
+		&t.Name,
+		&t.Descr,
+		&t.ContainerType,
+		&metrics,
+		&nextMetricId,
+		&t.Version,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, t, nextMetricId, nil
+		}
+		return false, t, nextMetricId, err
+	}
+	t.Id = id
+	return true, t, nextMetricId, json.Unmarshal(metrics, &t.Metrics)
+}
+
+// GetMetricTemplate returns the metric template.
+func (pg *PG) GetMetricTemplate(ctx context.Context, id int32) (exists bool, t models.MetricTemplate, err error) {
+	exists, t, _, err = pg.getMetricTemplate(ctx, sqlMetricTemplatesGet, id)
+	return exists, t, err
+}
+
+// GetMetricTemplateForUpdate returns the metric template and the next template
+// metric id, locking the template until the end of the transaction.
+func (pg *PG) GetMetricTemplateForUpdate(ctx context.Context, id int32) (exists bool, t models.MetricTemplate, nextMetricId int64, err error) {
+	return pg.getMetricTemplate(ctx, sqlMetricTemplatesGetForUpdate, id)
+}
+
+func (pg *PG) GetMetricTemplates(ctx context.Context, limit int, offset int) (templates []models.MetricTemplateSimplified, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlMetricTemplatesMGet, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	templates = []models.MetricTemplateSimplified{}
+	var t models.MetricTemplateSimplified
+	for rows.Next() {
+		err = rows.Scan(
+			&t.Id,
+			&t.Name,
+			&t.Descr,
+			&t.ContainerType,
+			&t.Version,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// MetricTemplateNameExists returns true if another template, with a different
+// id, has the name.
+func (pg *PG) MetricTemplateNameExists(ctx context.Context, name string, id int32) (exists bool, err error) {
+	return exists, pg.db.QueryRowContext(ctx, sqlMetricTemplatesNameExists, name, id).Scan(&exists)
+}
+
+// MetricTemplateReferencesExists checks if all data policies and alarm expressions
+// exists. The ids must be unique.
+func (pg *PG) MetricTemplateReferencesExists(ctx context.Context, dataPoliciesIds []int16, expressionsIds []int32) (r MetricTemplateReferencesExistsResponse, err error) {
+	return r, pg.db.QueryRowContext(ctx, sqlMetricTemplatesReferencesExists,
+		dataPoliciesIds,
+		len(dataPoliciesIds),
+		expressionsIds,
+		len(expressionsIds),
+	).Scan(&r.DataPoliciesExists, &r.AlarmExpressionsExists)
+}
+
+// LinkMetricTemplateContainer links the container to the template, or updates
+// the applied version if already linked.
+func (pg *PG) LinkMetricTemplateContainer(ctx context.Context, templateId int32, containerId int32, version int32, appliedAt int64) (err error) {
+	_, err = pg.db.ExecContext(ctx, sqlMetricTemplatesContainersLink, templateId, containerId, version, appliedAt)
+	return err
+}
+
+// UnlinkMetricTemplateContainer unlinks the container and its metrics from the
+// template, the metrics are kept.
+func (pg *PG) UnlinkMetricTemplateContainer(ctx context.Context, templateId int32, containerId int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlMetricTemplatesContainersUnlink, templateId, containerId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) GetMetricTemplateContainers(ctx context.Context, templateId int32, limit int, offset int) (containers []models.MetricTemplateContainer, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlMetricTemplatesContainersMGet, templateId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	containers = []models.MetricTemplateContainer{}
+	var c models.MetricTemplateContainer
+	for rows.Next() {
+		err = rows.Scan(&c.ContainerId, &c.Name, &c.AppliedVersion, &c.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// GetMetricTemplateContainersIds returns the ids of the containers linked to the template.
+func (pg *PG) GetMetricTemplateContainersIds(ctx context.Context, templateId int32) (ids []int32, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlMetricTemplatesContainersMGetIds, templateId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var id int32
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (pg *PG) CreateMetricTemplateLink(ctx context.Context, templateId int32, l models.MetricTemplateLink) (err error) {
+	applied, err := json.Marshal(l.Applied)
+	if err != nil {
+		return err
+	}
+	_, err = pg.db.ExecContext(ctx, sqlMetricTemplatesLinksCreate,
+		l.MetricId,
+		templateId,
+		l.ContainerId,
+		l.TemplateMetricId,
+		string(applied),
+	)
+	return err
+}
+
+// UpdateMetricTemplateLink updates the definition applied on the linked metric.
+func (pg *PG) UpdateMetricTemplateLink(ctx context.Context, metricId int64, applied models.MetricDefinition) (err error) {
+	b, err := json.Marshal(applied)
+	if err != nil {
+		return err
+	}
+	_, err = pg.db.ExecContext(ctx, sqlMetricTemplatesLinksUpdate, string(b), metricId)
+	return err
+}
+
+// DeleteMetricTemplateLink unlinks the metric from its template, the metric is kept.
+func (pg *PG) DeleteMetricTemplateLink(ctx context.Context, metricId int64) (err error) {
+	_, err = pg.db.ExecContext(ctx, sqlMetricTemplatesLinksDelete, metricId)
+	return err
+}
+
+// GetMetricTemplateLinks returns the template linked metrics with their applied
+// and current definitions. If containerId is zero, returns the links of all containers.
+func (pg *PG) GetMetricTemplateLinks(ctx context.Context, templateId int32, containerId int32) (links []models.MetricTemplateLink, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlMetricTemplatesLinksMGet, templateId, containerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var applied, rawLabels, expressions []byte
+	for rows.Next() {
+		var l models.MetricTemplateLink
+		err = rows.Scan(
+			&l.MetricId,
+			&l.ContainerId,
+			&l.TemplateMetricId,
+			&applied,
+			&l.Current.Type,
+			&l.Current.Name,
+			&l.Current.Descr,
+			&l.Current.Enabled,
+			&l.Current.DataPolicyId,
+			&l.Current.RTSPullingTimes,
+			&l.Current.RTSCacheDuration,
+			&l.Current.DHSEnabled,
+			&l.Current.DHSInterval,
+			&l.Current.EvaluableExpression,
+			&rawLabels,
+			&l.Current.OID,
+			&l.Current.Port,
+			&l.Current.PortType,
+			&expressions,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(applied, &l.Applied)
+		if err != nil {
+			return nil, err
+		}
+		err = unmarshalLabels(rawLabels, &l.Current.Labels)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(expressions, &l.Current.AlarmExpressionsIds)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -17,6 +18,7 @@ type BasicMetricQueryFilters struct {
 	Descr         string              `type:"ilike" column:"descr"`
 	Enabled       *bool               `type:"=" column:"enabled"`
 	DataPolicyId  int16               `type:"=" column:"data_policy_id"`
+	Labels        labels.Selector     `type:"selector" column:"labels"`
 	OrderBy       string
 	OrderByFn     string
 	Limit         int
@@ -77,11 +79,11 @@ type GetMetricRequestResult struct {
 
 const (
 	sqlMetricsCreate = `INSERT INTO metrics 
-		(container_id, container_type, name, descr, enabled, data_policy_id, rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id;`
+		(container_id, container_type, name, descr, enabled, data_policy_id, rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id;`
 	sqlMetricsUpdate = `UPDATE metrics SET 
-		(name, descr, enabled, data_policy_id, rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression) 
-		= ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) WHERE id = $11;`
+		(name, descr, enabled, data_policy_id, rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression, labels) 
+		= ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) WHERE id = $12;`
 	sqlMetricsGetRTSConfig = `SELECT rts_pulling_times, rts_data_cache_duration
 		FROM metrics WHERE id = $1;`
 	sqlMetricsExistsContainerAndDataPolicy = `SELECT 
@@ -90,7 +92,7 @@ const (
 		EXISTS (SELECT 1 FROM data_policies WHERE id = $3);`
 	sqlMetricsGet = `SELECT 
 		container_id, container_type, name, descr, enabled, data_policy_id, 
-		rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression FROM metrics WHERE id = $1;`
+		rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression, labels FROM metrics WHERE id = $1;`
 	sqlMetricsDelete                  = `DELETE FROM metrics WHERE id = $1;`
 	sqlMetricsGetEvaluableExpression  = `SELECT ev_expression FROM metrics WHERE id = $1;`
 	sqlMetricsGetEvaluableExpressions = `SELECT id, ev_expression FROM metrics WHERE id = ANY($1);`
@@ -101,14 +103,17 @@ const (
 	sqlMetricsGetMetricsRequestsAndIntervals = `SELECT id, type, container_id, container_type, data_policy_id, dhs_interval FROM metrics WHERE dhs_enabled = true AND container_type != $1 LIMIT $2 OFFSET $3;`
 	sqlMetricsGetRequest                     = `SELECT type, container_id, container_type, data_policy_id, enabled FROM metrics WHERE id = $1;`
 	sqlMetricsDHSEnabled                     = `SELECT dhs_enabled FROM metrics WHERE id = $1;`
-	sqlMetricsCountNonFlex                   = `SELECT COUNT(*) FROM metrics WHERE dhs_enabled = true AND container_type != $1;`
-	sqlMetricsGetAlarmExpressions            = `SELECT e.id, e.expression, e.category_id FROM alarm_expressions e
+	sqlMetricsGetContainerId                 = `SELECT container_id FROM metrics WHERE id = $1;`
+	sqlMetricsGetContainerTeam               = `SELECT COALESCE(c.team_id, 0) FROM metrics m 
+		LEFT JOIN containers c ON c.id = m.container_id WHERE m.id = $1;`
+	sqlMetricsCountNonFlex        = `SELECT COUNT(*) FROM metrics WHERE dhs_enabled = true AND container_type != $1;`
+	sqlMetricsGetAlarmExpressions = `SELECT e.id, e.expression, e.category_id FROM alarm_expressions e
 	LEFT JOIN metrics_alarm_expressions_rel r ON r.expression_id = e.id WHERE r.metric_id = $1;`
 	sqlMetricsGetAlarmsExpressions = `SELECT r.metric_id, e.id, e.expression, e.category_id FROM alarm_expressions e
 	FULL OUTER JOIN metrics_alarm_expressions_rel r ON r.expression_id = e.id WHERE r.metric_id = ANY ($1);`
 
 	customSqlBasicMetricsMGet = `SELECT id, name, descr, enabled, data_policy_id, 
-	rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression`
+	rts_pulling_times, rts_data_cache_duration, dhs_enabled, dhs_interval, type, ev_expression, labels FROM metrics`
 )
 
 func (pg *PG) GetBasicMetric(ctx context.Context, id int64) (exists bool, metric models.Metric[struct{}], err error) {
@@ -117,6 +122,7 @@ func (pg *PG) GetBasicMetric(ctx context.Context, id int64) (exists bool, metric
 		return false, metric, err
 	}
 	defer rows.Close()
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&metric.Base.ContainerId,
@@ -131,10 +137,15 @@ func (pg *PG) GetBasicMetric(ctx context.Context, id int64) (exists bool, metric
 			&metric.Base.DHSInterval,
 			&metric.Base.Type,
 			&metric.Base.EvaluableExpression,
+			&rawLabels,
 		)
 		if err != nil {
 			return false, metric, err
 		}
+		err = unmarshalLabels(rawLabels, &metric.Base.Labels)
+		if err != nil {
+			return false, metric, err
+		}
 		metric.Base.Id = id
 		exists = true
 	}
@@ -147,6 +158,7 @@ func (pg *PG) GetMetric(ctx context.Context, id int64) (exists bool, metric mode
 		return false, metric, err
 	}
 	defer rows.Close()
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&metric.ContainerId,
@@ -161,10 +173,15 @@ func (pg *PG) GetMetric(ctx context.Context, id int64) (exists bool, metric mode
 			&metric.DHSInterval,
 			&metric.Type,
 			&metric.EvaluableExpression,
+			&rawLabels,
 		)
 		if err != nil {
 			return false, metric, err
 		}
+		err = unmarshalLabels(rawLabels, &metric.Labels)
+		if err != nil {
+			return false, metric, err
+		}
 		metric.Id = id
 		exists = true
 	}
@@ -186,6 +203,7 @@ func (pg *PG) GetBasicMetrics(ctx context.Context, filters BasicMetricQueryFilte
 	var m models.Metric[struct{}]
 	m.Base.ContainerId = filters.ContainerId
 	m.Base.ContainerType = filters.ContainerType
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&m.Base.Id,
@@ -199,10 +217,15 @@ func (pg *PG) GetBasicMetrics(ctx context.Context, filters BasicMetricQueryFilte
 			&m.Base.DHSInterval,
 			&m.Base.Type,
 			&m.Base.EvaluableExpression,
+			&rawLabels,
 		)
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &m.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		metrics = append(metrics, m)
 	}
 	return metrics, nil
@@ -238,7 +261,35 @@ func (pg *PG) GetMetricDHSEnabled(ctx context.Context, id int64) (exists bool, e
 	return true, enabled, nil
 }
 
-func (pg *PG) createMetric(ctx context.Context, tx *sql.Tx, metric models.BaseMetric) (id int64, err error) {
+func (pg *PG) GetMetricContainerId(ctx context.Context, id int64) (exists bool, containerId int32, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlMetricsGetContainerId, id).Scan(&containerId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+	return true, containerId, nil
+}
+
+// GetMetricContainerTeam returns the metric container team id, or zero if the
+// container has no team.
+func (pg *PG) GetMetricContainerTeam(ctx context.Context, id int64) (exists bool, teamId int32, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlMetricsGetContainerTeam, id).Scan(&teamId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+	return true, teamId, nil
+}
+
+func (pg *PG) createMetric(ctx context.Context, tx Tx, metric models.BaseMetric) (id int64, err error) {
+	rawLabels, err := marshalLabels(metric.Labels)
+	if err != nil {
+		return id, err
+	}
 	err = tx.QueryRowContext(ctx, sqlMetricsCreate,
 		metric.ContainerId,
 		metric.ContainerType,
@@ -252,11 +303,16 @@ func (pg *PG) createMetric(ctx context.Context, tx *sql.Tx, metric models.BaseMe
 		metric.DHSInterval,
 		metric.Type,
 		metric.EvaluableExpression,
+		rawLabels,
 	).Scan(&id)
 	return id, err
 }
 
 func (pg *PG) CreateBasicMetric(ctx context.Context, metric models.Metric[struct{}]) (id int64, err error) {
+	rawLabels, err := marshalLabels(metric.Base.Labels)
+	if err != nil {
+		return id, err
+	}
 	err = pg.db.QueryRowContext(ctx, sqlMetricsCreate,
 		metric.Base.ContainerId,
 		metric.Base.ContainerType,
@@ -270,11 +326,36 @@ func (pg *PG) CreateBasicMetric(ctx context.Context, metric models.Metric[struct
 		metric.Base.DHSInterval,
 		metric.Base.Type,
 		metric.Base.EvaluableExpression,
+		rawLabels,
 	).Scan(&id)
 	return id, err
 }
 
+// CreateBasicMetricWithRefkey creates a basic metric and a reference key to it
+// in a single transaction.
+func (pg *PG) CreateBasicMetricWithRefkey(ctx context.Context, metric models.Metric[struct{}], refkey string) (id int64, err error) {
+	c, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return id, err
+	}
+	id, err = pg.createMetric(ctx, c, metric.Base)
+	if err != nil {
+		c.Rollback()
+		return id, err
+	}
+	_, err = c.ExecContext(ctx, sqlRefkeyCreate, refkey, id)
+	if err != nil {
+		c.Rollback()
+		return id, err
+	}
+	return id, c.Commit()
+}
+
 func (pg *PG) UpdateBasicMetric(ctx context.Context, metric models.Metric[struct{}]) (exists bool, err error) {
+	rawLabels, err := marshalLabels(metric.Base.Labels)
+	if err != nil {
+		return false, err
+	}
 	t, err := pg.db.ExecContext(ctx, sqlMetricsUpdate,
 		metric.Base.Name,
 		metric.Base.Descr,
@@ -286,6 +367,7 @@ func (pg *PG) UpdateBasicMetric(ctx context.Context, metric models.Metric[struct
 		metric.Base.DHSInterval,
 		metric.Base.Type,
 		metric.Base.EvaluableExpression,
+		rawLabels,
 		metric.Base.Id,
 	)
 	if err != nil {
@@ -295,7 +377,11 @@ func (pg *PG) UpdateBasicMetric(ctx context.Context, metric models.Metric[struct
 	return rowsAffected != 0, err
 }
 
-func (pg *PG) updateMetric(ctx context.Context, tx *sql.Tx, metric models.BaseMetric) (exists bool, err error) {
+func (pg *PG) updateMetric(ctx context.Context, tx Tx, metric models.BaseMetric) (exists bool, err error) {
+	rawLabels, err := marshalLabels(metric.Labels)
+	if err != nil {
+		return false, err
+	}
 	t, err := tx.ExecContext(ctx, sqlMetricsUpdate,
 		metric.Name,
 		metric.Descr,
@@ -307,6 +393,7 @@ func (pg *PG) updateMetric(ctx context.Context, tx *sql.Tx, metric models.BaseMe
 		metric.DHSInterval,
 		metric.Type,
 		metric.EvaluableExpression,
+		rawLabels,
 		metric.Id,
 	)
 	if err != nil {
@@ -0,0 +1,204 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+var OIDCProviderValidOrderByColumns = []string{"id", "ident", "name"}
+
+type OIDCProviderQueryFilters struct {
+	Ident     string `type:"ilike" column:"ident"`
+	Name      string `type:"ilike" column:"name"`
+	Enabled   *bool  `type:"=" column:"enabled"`
+	Issuer    string `type:"ilike" column:"issuer"`
+	OrderBy   string
+	OrderByFn string
+	Limit     int
+	Offset    int
+}
+
+func (f OIDCProviderQueryFilters) GetOrderBy() string {
+	return f.OrderBy
+}
+
+func (f OIDCProviderQueryFilters) GetOrderByFn() string {
+	return f.OrderByFn
+}
+
+func (f OIDCProviderQueryFilters) GetLimit() int {
+	return f.Limit
+}
+
+func (f OIDCProviderQueryFilters) GetOffset() int {
+	return f.Offset
+}
+
+const (
+	sqlOIDCProvidersCreate = `INSERT INTO oidc_providers (ident, name, enabled, issuer, client_id, client_secret,
+		redirect_url, scopes, username_claim, groups_claim, roles_groups, teams_groups)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id;`
+	sqlOIDCProvidersUpdate = `UPDATE oidc_providers SET (ident, name, enabled, issuer, client_id, client_secret,
+		redirect_url, scopes, username_claim, groups_claim, roles_groups, teams_groups)
+		= ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) WHERE id = $13;`
+	sqlOIDCProvidersDelete      = `DELETE FROM oidc_providers WHERE id = $1;`
+	sqlOIDCProvidersExistsIdent = `SELECT EXISTS (SELECT 1 FROM oidc_providers WHERE ident = $1 AND id != $2);`
+	sqlOIDCProvidersGet         = `SELECT id, ident, name, enabled, issuer, client_id, client_secret,
+		redirect_url, scopes, username_claim, groups_claim, roles_groups, teams_groups FROM oidc_providers WHERE id = $1;`
+	sqlOIDCProvidersGetByIdent = `SELECT id, ident, name, enabled, issuer, client_id, client_secret,
+		redirect_url, scopes, username_claim, groups_claim, roles_groups, teams_groups FROM oidc_providers WHERE ident = $1;`
+	customSqlOIDCProvidersMGet = `SELECT id, ident, name, enabled, issuer, client_id, client_secret,
+		redirect_url, scopes, username_claim, groups_claim, roles_groups, teams_groups FROM oidc_providers`
+)
+
+// oidcProviderArgs returns the provider columns values, the JSON columns
+// are marshaled.
+func oidcProviderArgs(p models.OIDCProvider) (args []any, err error) {
+	if p.Scopes == nil {
+		p.Scopes = []string{}
+	}
+	if p.RolesGroups == nil {
+		p.RolesGroups = []models.OIDCRoleGroup{}
+	}
+	if p.TeamsGroups == nil {
+		p.TeamsGroups = []models.OIDCTeamGroup{}
+	}
+	scopes, err := json.Marshal(p.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	rolesGroups, err := json.Marshal(p.RolesGroups)
+	if err != nil {
+		return nil, err
+	}
+	teamsGroups, err := json.Marshal(p.TeamsGroups)
+	if err != nil {
+		return nil, err
+	}
+	return []any{
+		p.Ident,
+		p.Name,
+		p.Enabled,
+		p.Issuer,
+		p.ClientId,
+		p.ClientSecret,
+		p.RedirectURL,
+		string(scopes),
+		p.UsernameClaim,
+		p.GroupsClaim,
+		string(rolesGroups),
+		string(teamsGroups),
+	}, nil
+}
+
+func scanOIDCProvider(row interface{ Scan(dest ...any) error }) (p models.OIDCProvider, err error) {
+	var scopes, rolesGroups, teamsGroups []byte
+	err = row.Scan(
+		&p.Id,
+		&p.Ident,
+		&p.Name,
+		&p.Enabled,
+		&p.Issuer,
+		&p.ClientId,
+		&p.ClientSecret,
+		&p.RedirectURL,
+		&scopes,
+		&p.UsernameClaim,
+		&p.GroupsClaim,
+		&rolesGroups,
+		&teamsGroups,
+	)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(scopes, &p.Scopes)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(rolesGroups, &p.RolesGroups)
+	if err != nil {
+		return p, err
+	}
+	return p, json.Unmarshal(teamsGroups, &p.TeamsGroups)
+}
+
+func (pg *PG) CreateOIDCProvider(ctx context.Context, p models.OIDCProvider) (id int32, err error) {
+	args, err := oidcProviderArgs(p)
+	if err != nil {
+		return 0, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlOIDCProvidersCreate, args...).Scan(&id)
+}
+
+func (pg *PG) UpdateOIDCProvider(ctx context.Context, p models.OIDCProvider) (exists bool, err error) {
+	args, err := oidcProviderArgs(p)
+	if err != nil {
+		return false, err
+	}
+	t, err := pg.db.ExecContext(ctx, sqlOIDCProvidersUpdate, append(args, p.Id)...)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) DeleteOIDCProvider(ctx context.Context, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlOIDCProvidersDelete, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) OIDCProviderIdentExists(ctx context.Context, ident string, id int32) (exists bool, err error) {
+	return exists, pg.db.QueryRowContext(ctx, sqlOIDCProvidersExistsIdent, ident, id).Scan(&exists)
+}
+
+func (pg *PG) GetOIDCProvider(ctx context.Context, id int32) (exists bool, p models.OIDCProvider, err error) {
+	p, err = scanOIDCProvider(pg.db.QueryRowContext(ctx, sqlOIDCProvidersGet, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, p, nil
+		}
+		return false, p, err
+	}
+	return true, p, nil
+}
+
+func (pg *PG) GetOIDCProviderByIdent(ctx context.Context, ident string) (exists bool, p models.OIDCProvider, err error) {
+	p, err = scanOIDCProvider(pg.db.QueryRowContext(ctx, sqlOIDCProvidersGetByIdent, ident))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, p, nil
+		}
+		return false, p, err
+	}
+	return true, p, nil
+}
+
+func (pg *PG) GetOIDCProviders(ctx context.Context, filters OIDCProviderQueryFilters) (providers []models.OIDCProvider, err error) {
+	sql, params, err := applyFilters(filters, customSqlOIDCProvidersMGet, OIDCProviderValidOrderByColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	providers = make([]models.OIDCProvider, 0, filters.Limit)
+	for rows.Next() {
+		p, err := scanOIDCProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
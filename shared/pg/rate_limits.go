@@ -0,0 +1,127 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+var RateLimitValidOrderByColumns = []string{"id", "route_group", "user_id", "apikey_id", "requests"}
+
+type RateLimitQueryFilters struct {
+	Group     string `type:"=" column:"route_group"`
+	UserId    int32  `type:"=" column:"user_id"`
+	APIKeyId  int32  `type:"=" column:"apikey_id"`
+	OrderBy   string
+	OrderByFn string
+	Limit     int
+	Offset    int
+}
+
+func (f RateLimitQueryFilters) GetOrderBy() string {
+	return f.OrderBy
+}
+
+func (f RateLimitQueryFilters) GetOrderByFn() string {
+	return f.OrderByFn
+}
+
+func (f RateLimitQueryFilters) GetLimit() int {
+	return f.Limit
+}
+
+func (f RateLimitQueryFilters) GetOffset() int {
+	return f.Offset
+}
+
+const (
+	sqlRateLimitsCreate = `INSERT INTO rate_limits (route_group, user_id, apikey_id, requests, window_size)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	sqlRateLimitsUpdate = `UPDATE rate_limits SET (route_group, user_id, apikey_id, requests, window_size)
+		= ($1, $2, $3, $4, $5) WHERE id = $6;`
+	sqlRateLimitsDelete = `DELETE FROM rate_limits WHERE id = $1;`
+	sqlRateLimitsExists = `SELECT EXISTS (SELECT 1 FROM rate_limits WHERE route_group = $1 AND user_id = $2
+		AND apikey_id = $3 AND id != $4);`
+	sqlRateLimitsGet = `SELECT id, route_group, user_id, apikey_id, requests, window_size
+		FROM rate_limits WHERE id = $1;`
+	sqlRateLimitsGetClient = `SELECT requests, window_size FROM rate_limits WHERE route_group = $1
+		AND user_id IN (0, $2) AND apikey_id IN (0, $3) ORDER BY apikey_id DESC, user_id DESC LIMIT 1;`
+	customSqlRateLimitsMGet = `SELECT id, route_group, user_id, apikey_id, requests, window_size FROM rate_limits`
+)
+
+func (pg *PG) CreateRateLimit(ctx context.Context, l models.RateLimit) (id int32, err error) {
+	return id, pg.db.QueryRowContext(ctx, sqlRateLimitsCreate, l.Group, l.UserId, l.APIKeyId, l.Requests, l.Window).Scan(&id)
+}
+
+func (pg *PG) UpdateRateLimit(ctx context.Context, l models.RateLimit) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlRateLimitsUpdate, l.Group, l.UserId, l.APIKeyId, l.Requests, l.Window, l.Id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) DeleteRateLimit(ctx context.Context, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlRateLimitsDelete, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// RateLimitExists returns true if other rate limit with the same group, user and
+// API Key exists.
+func (pg *PG) RateLimitExists(ctx context.Context, l models.RateLimit) (exists bool, err error) {
+	return exists, pg.db.QueryRowContext(ctx, sqlRateLimitsExists, l.Group, l.UserId, l.APIKeyId, l.Id).Scan(&exists)
+}
+
+func (pg *PG) GetRateLimit(ctx context.Context, id int32) (exists bool, l models.RateLimit, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlRateLimitsGet, id).Scan(&l.Id, &l.Group, &l.UserId, &l.APIKeyId, &l.Requests, &l.Window)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, l, nil
+		}
+		return false, l, err
+	}
+	return true, l, nil
+}
+
+func (pg *PG) GetRateLimits(ctx context.Context, filters RateLimitQueryFilters) (limits []models.RateLimit, err error) {
+	sql, params, err := applyFilters(filters, customSqlRateLimitsMGet, RateLimitValidOrderByColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits = make([]models.RateLimit, 0, filters.Limit)
+	var l models.RateLimit
+	for rows.Next() {
+		err = rows.Scan(&l.Id, &l.Group, &l.UserId, &l.APIKeyId, &l.Requests, &l.Window)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, l)
+	}
+	return limits, nil
+}
+
+// GetClientRateLimit returns the most specific rate limit of the route group for
+// the client, the API Key rate limit first, then the user and then the group one.
+// The apikeyId is zero if the client uses a session.
+func (pg *PG) GetClientRateLimit(ctx context.Context, group string, userId int32, apikeyId int32) (exists bool, p models.RateLimitPolicy, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlRateLimitsGetClient, group, userId, apikeyId).Scan(&p.Requests, &p.Window)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, p, nil
+		}
+		return false, p, err
+	}
+	return true, p, nil
+}
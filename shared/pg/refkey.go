@@ -10,8 +10,8 @@ import (
 
 const (
 	sqlRefkeyCreate           = `INSERT INTO metrics_ref (refkey, metric_id) VALUES ($1, $2) RETURNING id;`
-	sqlRefkeyUpdate           = `UPDATE metrics_ref SET (refkey, metric_id) = ($1, $2) WHERE id = $3;`
-	sqlRefKeyDelete           = `DELETE FROM metrics_ref WHERE id = $1;`
+	sqlRefkeyUpdate           = `UPDATE metrics_ref SET refkey = $1 WHERE metric_id = $2 AND id = $3;`
+	sqlRefKeyDelete           = `DELETE FROM metrics_ref WHERE metric_id = $1 AND id = $2;`
 	sqlRefkeyGetByRefkey      = `SELECT id, metric_id FROM metrics_ref WHERE refkey = $1;`
 	sqlRefkeyGetMetricRefKeys = `SELECT id, refkey FROM metrics_ref WHERE metric_id = $1;`
 	sqlRefkeyExists           = `SELECT 
@@ -32,8 +32,8 @@ func (pg *PG) UpdateMetricRefkey(ctx context.Context, rk models.MetricRefkey) (e
 	return rowsAffected != 0, err
 }
 
-func (pg *PG) DeleteMetricRefkey(ctx context.Context, id int64) (exists bool, err error) {
-	t, err := pg.db.ExecContext(ctx, sqlRefKeyDelete, id)
+func (pg *PG) DeleteMetricRefkey(ctx context.Context, metricId int64, id int64) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlRefKeyDelete, metricId, id)
 	rowsAffected, _ := t.RowsAffected()
 	if err != nil {
 		return false, err
@@ -0,0 +1,348 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+const scheduledReportsFields = `id, ctx_id, name, descr, enabled, schedule, time_range, sample_interval, aggregations,
+	alarm_counts, contextual_metrics_ids, selector, formats, emails, next_run_at`
+
+const (
+	sqlScheduledReportsCreate = `INSERT INTO scheduled_reports (ctx_id, name, descr, enabled, schedule, time_range,
+		sample_interval, aggregations, alarm_counts, contextual_metrics_ids, selector, formats, emails, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id;`
+	sqlScheduledReportsUpdate = `UPDATE scheduled_reports SET (name, descr, enabled, schedule, time_range,
+		sample_interval, aggregations, alarm_counts, contextual_metrics_ids, selector, formats, emails, next_run_at)
+		= ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) WHERE id = $14 AND ctx_id = $15;`
+	sqlScheduledReportsDelete = `DELETE FROM scheduled_reports WHERE id = $1 AND ctx_id = $2;`
+	sqlScheduledReportsGet    = `SELECT ` + scheduledReportsFields + ` FROM scheduled_reports WHERE id = $1 AND ctx_id = $2;`
+	sqlScheduledReportsMGet   = `SELECT ` + scheduledReportsFields + ` FROM scheduled_reports
+		WHERE ctx_id = $1 ORDER BY id LIMIT $2 OFFSET $3;`
+	sqlScheduledReportsMGetDue = `SELECT ` + scheduledReportsFields + ` FROM scheduled_reports
+		WHERE enabled AND next_run_at > 0 AND next_run_at <= $1 ORDER BY next_run_at LIMIT $2;`
+	sqlScheduledReportsClaim = `UPDATE scheduled_reports SET next_run_at = $1 WHERE id = $2 AND next_run_at = $3;`
+
+	sqlScheduledReportsRunsCreate = `INSERT INTO scheduled_reports_runs (report_id, status, start, stop, formats, sent, error, created_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;`
+	sqlScheduledReportsRunsFinish = `UPDATE scheduled_reports_runs SET (status, formats, sent, error, finished_at)
+		= ($1, $2, $3, $4, $5) WHERE id = $6;`
+	sqlScheduledReportsRunsFailStale = `UPDATE scheduled_reports_runs SET (status, error, finished_at) = ($1, $2, $3)
+		WHERE status = $4 AND created_at < $5;`
+	sqlScheduledReportsRunsGet = `SELECT status, start, stop, formats, sent, error, created_at, finished_at
+		FROM scheduled_reports_runs WHERE id = $1 AND report_id = $2;`
+	sqlScheduledReportsRunsMGet = `SELECT id, status, start, stop, formats, sent, error, created_at, finished_at
+		FROM scheduled_reports_runs WHERE report_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3;`
+	sqlScheduledReportsRunsPrune = `DELETE FROM scheduled_reports_runs WHERE report_id = $1 AND id NOT IN (
+		SELECT id FROM scheduled_reports_runs WHERE report_id = $1 ORDER BY id DESC LIMIT $2);`
+
+	sqlScheduledReportsFilesCreate = `INSERT INTO scheduled_reports_files (run_id, format, content) VALUES ($1, $2, $3);`
+	sqlScheduledReportsFilesGet    = `SELECT f.content FROM scheduled_reports_files f
+		JOIN scheduled_reports_runs r ON r.id = f.run_id WHERE f.run_id = $1 AND f.format = $2 AND r.report_id = $3;`
+)
+
+// marshalScheduledReportLists returns the report JSONB columns.
+func marshalScheduledReportLists(r models.ScheduledReport) (aggregations string, ids string, formats string, emails string, err error) {
+	if r.ContextualMetricsIds == nil {
+		r.ContextualMetricsIds = []int64{}
+	}
+	if r.Emails == nil {
+		r.Emails = []string{}
+	}
+	var res [4][]byte
+	for i, v := range []any{r.Aggregations, r.ContextualMetricsIds, r.Formats, r.Emails} {
+		res[i], err = json.Marshal(v)
+		if err != nil {
+			return "", "", "", "", err
+		}
+	}
+	return string(res[0]), string(res[1]), string(res[2]), string(res[3]), nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanScheduledReport(s scanner) (r models.ScheduledReport, err error) {
+	var aggregations, ids, formats, emails []byte
+	err = s.Scan(
+		&r.Id,
+		&r.ContextId,
+		&r.Name,
+		&r.Descr,
+		&r.Enabled,
+		&r.Schedule,
+		&r.TimeRange,
+		&r.Interval,
+		&aggregations,
+		&r.AlarmCounts,
+		&ids,
+		&r.Selector,
+		&formats,
+		&emails,
+		&r.NextRunAt,
+	)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(aggregations, &r.Aggregations)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(ids, &r.ContextualMetricsIds)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(formats, &r.Formats)
+	if err != nil {
+		return r, err
+	}
+	return r, json.Unmarshal(emails, &r.Emails)
+}
+
+func (pg *PG) CreateScheduledReport(ctx context.Context, r models.ScheduledReport) (id int32, err error) {
+	aggregations, ids, formats, emails, err := marshalScheduledReportLists(r)
+	if err != nil {
+		return id, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlScheduledReportsCreate,
+		r.ContextId,
+		r.Name,
+		r.Descr,
+		r.Enabled,
+		r.Schedule,
+		r.TimeRange,
+		r.Interval,
+		aggregations,
+		r.AlarmCounts,
+		ids,
+		r.Selector,
+		formats,
+		emails,
+		r.NextRunAt,
+	).Scan(&id)
+}
+
+func (pg *PG) UpdateScheduledReport(ctx context.Context, r models.ScheduledReport) (exists bool, err error) {
+	aggregations, ids, formats, emails, err := marshalScheduledReportLists(r)
+	if err != nil {
+		return false, err
+	}
+	t, err := pg.db.ExecContext(ctx, sqlScheduledReportsUpdate,
+		r.Name,
+		r.Descr,
+		r.Enabled,
+		r.Schedule,
+		r.TimeRange,
+		r.Interval,
+		aggregations,
+		r.AlarmCounts,
+		ids,
+		r.Selector,
+		formats,
+		emails,
+		r.NextRunAt,
+		r.Id,
+		r.ContextId,
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) DeleteScheduledReport(ctx context.Context, ctxId int32, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlScheduledReportsDelete, id, ctxId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) GetScheduledReport(ctx context.Context, ctxId int32, id int32) (exists bool, r models.ScheduledReport, err error) {
+	r, err = scanScheduledReport(pg.db.QueryRowContext(ctx, sqlScheduledReportsGet, id, ctxId))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, r, nil
+		}
+		return false, r, err
+	}
+	return true, r, nil
+}
+
+func (pg *PG) GetScheduledReports(ctx context.Context, ctxId int32, limit int, offset int) (reports []models.ScheduledReport, err error) {
+	return pg.getScheduledReports(ctx, sqlScheduledReportsMGet, limit, ctxId, limit, offset)
+}
+
+// GetDueScheduledReports returns the enabled reports with the next run before or on now,
+// oldest first.
+func (pg *PG) GetDueScheduledReports(ctx context.Context, now int64, limit int) (reports []models.ScheduledReport, err error) {
+	return pg.getScheduledReports(ctx, sqlScheduledReportsMGetDue, limit, now, limit)
+}
+
+func (pg *PG) getScheduledReports(ctx context.Context, sql string, limit int, params ...any) (reports []models.ScheduledReport, err error) {
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	reports = make([]models.ScheduledReport, 0, limit)
+	for rows.Next() {
+		r, err := scanScheduledReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// ClaimScheduledReport sets the report next run if it is still the expected one.
+// Returns false if other instance has already claimed the run.
+func (pg *PG) ClaimScheduledReport(ctx context.Context, id int32, nextRunAt int64, newNextRunAt int64) (claimed bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlScheduledReportsClaim, newNextRunAt, id, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) CreateScheduledReportRun(ctx context.Context, run models.ScheduledReportRun) (id int64, err error) {
+	formats, err := json.Marshal(run.Formats)
+	if err != nil {
+		return id, err
+	}
+	return id, pg.db.QueryRowContext(ctx, sqlScheduledReportsRunsCreate,
+		run.ReportId,
+		run.Status,
+		run.Start,
+		run.Stop,
+		string(formats),
+		run.Sent,
+		run.Error,
+		run.CreatedAt,
+		run.FinishedAt,
+	).Scan(&id)
+}
+
+// FinishScheduledReportRun saves the run status, formats, sent, error and finish date.
+func (pg *PG) FinishScheduledReportRun(ctx context.Context, run models.ScheduledReportRun) error {
+	formats, err := json.Marshal(run.Formats)
+	if err != nil {
+		return err
+	}
+	_, err = pg.db.ExecContext(ctx, sqlScheduledReportsRunsFinish,
+		run.Status,
+		string(formats),
+		run.Sent,
+		run.Error,
+		run.FinishedAt,
+		run.Id,
+	)
+	return err
+}
+
+// FailStaleScheduledReportRuns sets the runs still running that were created before
+// the date as failed, with the error.
+func (pg *PG) FailStaleScheduledReportRuns(ctx context.Context, before int64, finishedAt int64, errMsg string) (n int64, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlScheduledReportsRunsFailStale,
+		models.ScheduledReportRunFailed,
+		errMsg,
+		finishedAt,
+		models.ScheduledReportRunRunning,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return t.RowsAffected()
+}
+
+func (pg *PG) GetScheduledReportRun(ctx context.Context, reportId int32, id int64) (exists bool, run models.ScheduledReportRun, err error) {
+	var formats []byte
+	err = pg.db.QueryRowContext(ctx, sqlScheduledReportsRunsGet, id, reportId).Scan(
+		&run.Status,
+		&run.Start,
+		&run.Stop,
+		&formats,
+		&run.Sent,
+		&run.Error,
+		&run.CreatedAt,
+		&run.FinishedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, run, nil
+		}
+		return false, run, err
+	}
+	run.Id = id
+	run.ReportId = reportId
+	return true, run, json.Unmarshal(formats, &run.Formats)
+}
+
+// GetScheduledReportRuns returns the report runs, newest first.
+func (pg *PG) GetScheduledReportRuns(ctx context.Context, reportId int32, limit int, offset int) (runs []models.ScheduledReportRun, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlScheduledReportsRunsMGet, reportId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	runs = make([]models.ScheduledReportRun, 0, limit)
+	var formats []byte
+	for rows.Next() {
+		run := models.ScheduledReportRun{ReportId: reportId}
+		err = rows.Scan(
+			&run.Id,
+			&run.Status,
+			&run.Start,
+			&run.Stop,
+			&formats,
+			&run.Sent,
+			&run.Error,
+			&run.CreatedAt,
+			&run.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(formats, &run.Formats)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// PruneScheduledReportRuns deletes the report runs and its files, except the
+// newest runs.
+func (pg *PG) PruneScheduledReportRuns(ctx context.Context, reportId int32, keep int) (n int64, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlScheduledReportsRunsPrune, reportId, keep)
+	if err != nil {
+		return 0, err
+	}
+	return t.RowsAffected()
+}
+
+func (pg *PG) CreateScheduledReportFile(ctx context.Context, runId int64, format string, content []byte) error {
+	_, err := pg.db.ExecContext(ctx, sqlScheduledReportsFilesCreate, runId, format, content)
+	return err
+}
+
+func (pg *PG) GetScheduledReportFile(ctx context.Context, reportId int32, runId int64, format string) (exists bool, content []byte, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlScheduledReportsFilesGet, runId, format, reportId).Scan(&content)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	return true, content, nil
+}
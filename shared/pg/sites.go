@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+var SiteValidOrderByColumns = []string{"id", "name", "descr", "kind"}
+
+type SiteQueryFilters struct {
+	ParentId  int32  `type:"=" column:"parent_id"`
+	Kind      string `type:"=" column:"kind"`
+	Name      string `type:"ilike" column:"name"`
+	Descr     string `type:"ilike" column:"descr"`
+	OrderBy   string
+	OrderByFn string
+	Limit     int
+	Offset    int
+}
+
+func (f SiteQueryFilters) GetOrderBy() string {
+	return f.OrderBy
+}
+
+func (f SiteQueryFilters) GetOrderByFn() string {
+	return f.OrderByFn
+}
+
+func (f SiteQueryFilters) GetLimit() int {
+	return f.Limit
+}
+
+func (f SiteQueryFilters) GetOffset() int {
+	return f.Offset
+}
+
+const (
+	sqlSitesCreate = `INSERT INTO sites (parent_id, kind, name, descr, latitude, longitude)
+		VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6) RETURNING id;`
+	sqlSitesUpdate = `UPDATE sites SET (parent_id, kind, name, descr, latitude, longitude)
+		= (NULLIF($1, 0), $2, $3, $4, $5, $6) WHERE id = $7;`
+	sqlSitesDelete = `DELETE FROM sites WHERE id = $1;`
+	sqlSitesGet    = `SELECT COALESCE(parent_id, 0), kind, name, descr, latitude, longitude
+		FROM sites WHERE id = $1;`
+	sqlSitesGetAll = `SELECT id, COALESCE(parent_id, 0), kind, name, descr, latitude, longitude
+		FROM sites ORDER BY id;`
+	sqlSitesHasChildren = `SELECT EXISTS (SELECT 1 FROM sites WHERE parent_id = $1);`
+	sqlSitesInSubtree   = `WITH RECURSIVE tree AS (
+			SELECT id FROM sites WHERE id = $1
+			UNION ALL
+			SELECT s.id FROM sites s JOIN tree t ON s.parent_id = t.id
+		) SELECT EXISTS (SELECT 1 FROM tree WHERE id = $2);`
+	sqlSitesGetAlarmSummaries = `WITH RECURSIVE tree AS (
+			SELECT id, id AS root FROM sites
+			UNION ALL
+			SELECT s.id, t.root FROM sites s JOIN tree t ON s.parent_id = t.id
+		) SELECT t.root, COUNT(DISTINCT c.id),
+			COUNT(a.metric_id) FILTER (WHERE a.state = $1),
+			COUNT(a.metric_id) FILTER (WHERE a.state = $2)
+		FROM tree t
+		JOIN containers c ON c.site_id = t.id
+			AND ($3 = 0 OR c.team_id IN (SELECT team_id FROM users_teams WHERE user_id = $3))
+		LEFT JOIN metrics m ON m.container_id = c.id
+		LEFT JOIN alarm_state a ON a.metric_id = m.id
+		GROUP BY t.root;`
+	sqlSitesAddContainer    = `UPDATE containers SET site_id = $1 WHERE id = $2;`
+	sqlSitesRemoveContainer = `UPDATE containers SET site_id = NULL WHERE site_id = $1 AND id = $2;`
+	sqlSitesGetContainers   = `SELECT id, name, type, enabled FROM containers WHERE site_id = $1
+		AND ($2 = 0 OR team_id IN (SELECT team_id FROM users_teams WHERE user_id = $2))
+		ORDER BY id LIMIT $3 OFFSET $4;`
+
+	customSqlSitesMGet = `SELECT id, COALESCE(parent_id, 0), kind, name, descr, latitude, longitude FROM sites`
+)
+
+func (pg *PG) CreateSite(ctx context.Context, s models.Site) (id int32, err error) {
+	return id, pg.db.QueryRowContext(ctx, sqlSitesCreate,
+		s.ParentId,
+		s.Kind,
+		s.Name,
+		s.Descr,
+		s.Latitude,
+		s.Longitude,
+	).Scan(&id)
+}
+
+func (pg *PG) UpdateSite(ctx context.Context, s models.Site) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlSitesUpdate,
+		s.ParentId,
+		s.Kind,
+		s.Name,
+		s.Descr,
+		s.Latitude,
+		s.Longitude,
+		s.Id,
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) DeleteSite(ctx context.Context, id int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlSitesDelete, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) GetSite(ctx context.Context, id int32) (exists bool, s models.Site, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlSitesGet, id).Scan(
+		&s.ParentId,
+		&s.Kind,
+		&s.Name,
+		&s.Descr,
+		&s.Latitude,
+		&s.Longitude,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, s, nil
+		}
+		return false, s, err
+	}
+	s.Id = id
+	return true, s, nil
+}
+
+func (pg *PG) GetSites(ctx context.Context, filters SiteQueryFilters) (sites []models.Site, err error) {
+	sql, params, err := applyFilters(filters, customSqlSitesMGet, SiteValidOrderByColumns)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := pg.db.QueryContext(ctx, sql, params...)
+	if err != nil {
+		return nil, err
+	}
+	return scanSites(rows, filters.Limit)
+}
+
+// GetAllSites returns all the sites.
+func (pg *PG) GetAllSites(ctx context.Context) (sites []models.Site, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlSitesGetAll)
+	if err != nil {
+		return nil, err
+	}
+	return scanSites(rows, 0)
+}
+
+func scanSites(rows *sql.Rows, size int) (sites []models.Site, err error) {
+	defer rows.Close()
+	sites = make([]models.Site, 0, size)
+	for rows.Next() {
+		var s models.Site
+		err = rows.Scan(
+			&s.Id,
+			&s.ParentId,
+			&s.Kind,
+			&s.Name,
+			&s.Descr,
+			&s.Latitude,
+			&s.Longitude,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, s)
+	}
+	return sites, rows.Err()
+}
+
+// SiteHasChildren returns true if the site is the parent of other sites.
+func (pg *PG) SiteHasChildren(ctx context.Context, id int32) (has bool, err error) {
+	return has, pg.db.QueryRowContext(ctx, sqlSitesHasChildren, id).Scan(&has)
+}
+
+// SiteInSubtree returns true if the site is the root site or one of its
+// descendants.
+func (pg *PG) SiteInSubtree(ctx context.Context, rootId int32, id int32) (in bool, err error) {
+	return in, pg.db.QueryRowContext(ctx, sqlSitesInSubtree, rootId, id).Scan(&in)
+}
+
+// GetSitesAlarmSummaries returns the containers and alarm states summary of each
+// site with containers on it or on its descendants. If userId is not zero, only
+// the containers of the user teams are summarized.
+func (pg *PG) GetSitesAlarmSummaries(ctx context.Context, userId int32) (summaries []models.SiteAlarmSummary, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlSitesGetAlarmSummaries, types.ASAlarmed, types.ASRecognized, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	summaries = make([]models.SiteAlarmSummary, 0)
+	var s models.SiteAlarmSummary
+	for rows.Next() {
+		err = rows.Scan(&s.SiteId, &s.Containers, &s.Alarmed, &s.Recognized)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+// AddContainerToSite attaches the container to the site, moving it from its
+// current site if any.
+func (pg *PG) AddContainerToSite(ctx context.Context, siteId int32, containerId int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlSitesAddContainer, siteId, containerId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// RemoveContainerFromSite detaches the container of the site. Returns false if
+// the container is not on the site.
+func (pg *PG) RemoveContainerFromSite(ctx context.Context, siteId int32, containerId int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlSitesRemoveContainer, siteId, containerId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// GetSiteContainers returns the containers of the site. If userId is not zero,
+// only the containers of the user teams are returned.
+func (pg *PG) GetSiteContainers(ctx context.Context, siteId int32, userId int32, limit int, offset int) (containers []models.SiteContainer, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlSitesGetContainers, siteId, userId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	containers = make([]models.SiteContainer, 0, limit)
+	var c models.SiteContainer
+	for rows.Next() {
+		err = rows.Scan(&c.Id, &c.Name, &c.Type, &c.Enabled)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, c)
+	}
+	return containers, rows.Err()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -17,7 +18,9 @@ type SNMPv2cContainerQueryFilters struct {
 	CreatedAtStart int64               `type:">=" column:"created_at"`
 	CreatedAtStop  int64               `type:"<=" column:"created_at"`
 	Enabled        *bool               `type:"=" column:"enabled"`
+	TeamId         int32               `type:"=" column:"team_id"`
 	Target         string              `type:"ilike" column:"target"`
+	Labels         labels.Selector     `type:"selector" column:"labels"`
 	OrderBy        string
 	OrderByFn      string
 	Limit          int
@@ -41,7 +44,7 @@ func (f SNMPv2cContainerQueryFilters) GetOffset() int {
 }
 
 const (
-	sqlSNMPv2cContainerGet = `SELECT c.name, c.descr, c.enabled, c.rts_pulling_interval, c.created_at,
+	sqlSNMPv2cContainerGet = `SELECT c.name, c.descr, c.enabled, c.rts_pulling_interval, c.created_at, COALESCE(c.team_id, 0), c.labels,
 	p.target, p.port, p.transport, p.community, p.retries, p.max_oids, p.timeout
 	FROM containers c FULL JOIN snmpv2c_containers p ON p.container_id = c.id WHERE id = $1;`
 	sqlSNMPv2cContainerGetProtocol = `SELECT target, port, transport, community,
@@ -52,7 +55,7 @@ const (
 	sqlSNMPv2cContainerUpdate = `UPDATE snmpv2c_containers SET (target, port, transport, community,
 		retries, max_oids, timeout) = ($1, $2, $3, $4, $5, $6, $7) WHERE container_id = $8;`
 
-	customSqlSNMPv2cContainerGet = `SELECT c.id, c.name, c.descr, c.enabled, c.rts_pulling_interval, c.created_at,
+	customSqlSNMPv2cContainerGet = `SELECT c.id, c.name, c.descr, c.enabled, c.rts_pulling_interval, c.created_at, COALESCE(c.team_id, 0), c.labels,
 		p.target, p.port, p.transport, p.community, p.retries, p.max_oids, p.timeout
 		FROM containers c FULL JOIN snmpv2c_containers p ON p.container_id = c.id`
 )
@@ -120,12 +123,15 @@ func (pg *PG) DeleteSNMPv2cContainer(ctx context.Context, id int32) (exists bool
 }
 
 func (pg *PG) GetSNMPv2cContainer(ctx context.Context, id int32) (exists bool, container models.Container[models.SNMPv2cContainer], err error) {
+	var rawLabels []byte
 	err = pg.db.QueryRowContext(ctx, sqlSNMPv2cContainerGet, id).Scan(
 		&container.Base.Name,
 		&container.Base.Descr,
 		&container.Base.Enabled,
 		&container.Base.RTSPullingInterval,
 		&container.Base.CreatedAt,
+		&container.Base.TeamId,
+		&rawLabels,
 		&container.Protocol.Target,
 		&container.Protocol.Port,
 		&container.Protocol.Transport,
@@ -140,6 +146,10 @@ func (pg *PG) GetSNMPv2cContainer(ctx context.Context, id int32) (exists bool, c
 		}
 		return false, container, err
 	}
+	err = unmarshalLabels(rawLabels, &container.Base.Labels)
+	if err != nil {
+		return false, container, err
+	}
 	container.Base.Type = types.CTSNMPv2c
 	container.Base.Id = id
 	container.Protocol.Id = id
@@ -160,6 +170,7 @@ func (pg *PG) GetSNMPv2cGetContainers(ctx context.Context, filters SNMPv2cContai
 	containers = make([]models.Container[models.SNMPv2cContainer], 0, filters.Limit)
 	container := models.Container[models.SNMPv2cContainer]{}
 	container.Base.Type = filters.Type
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&container.Base.Id,
@@ -168,6 +179,8 @@ func (pg *PG) GetSNMPv2cGetContainers(ctx context.Context, filters SNMPv2cContai
 			&container.Base.Enabled,
 			&container.Base.RTSPullingInterval,
 			&container.Base.CreatedAt,
+			&container.Base.TeamId,
+			&rawLabels,
 			&container.Protocol.Target,
 			&container.Protocol.Port,
 			&container.Protocol.Transport,
@@ -179,6 +192,10 @@ func (pg *PG) GetSNMPv2cGetContainers(ctx context.Context, filters SNMPv2cContai
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &container.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		container.Protocol.Id = container.Base.Id
 		containers = append(containers, container)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/fernandotsda/nemesys/shared/labels"
 	"github.com/fernandotsda/nemesys/shared/models"
 	"github.com/fernandotsda/nemesys/shared/types"
 )
@@ -17,6 +18,7 @@ type SNMPv2cMetricQueryFilters struct {
 	Descr         string              `type:"ilike" column:"descr"`
 	Enabled       *bool               `type:"=" column:"enabled"`
 	DataPolicyId  int16               `type:"=" column:"data_policy_id"`
+	Labels        labels.Selector     `type:"selector" column:"labels"`
 	OrderBy       string
 	OrderByFn     string
 	Limit         int
@@ -42,15 +44,15 @@ func (f SNMPv2cMetricQueryFilters) GetOffset() int {
 const (
 	sqlSNMPv2cMetricsGet = `SELECT 
 		b.container_id, b.name, b.descr, b.enabled, b.data_policy_id, 
-		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression 
+		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, b.labels 
 		p.oid FROM metrics b FULL JOIN snmpv2c_metrics p ON p.metric_id = b.id WHERE id = $1;`
-	sqlSNMPv2cMetricsGetByIds                   = `SELECT metric_id, oid FROM snmpv2c_metrics WHERE metric_id = ANY ($1);`
-	sqlSNMPv2cMetricsCreate                     = `INSERT INTO snmpv2c_metrics (oid, metric_id) VALUES ($1, $2);`
-	sqlSNMPv2cMetricsUpdate                     = `UPDATE snmpv2c_metrics SET (oid, metric_id) = ($1, $2) WHERE metric_id = $3;`
-	customSqlBasicMetricsMGetSNMPv2cMetricsMGet = `SELECT 
-	b.id, b.name, b.descr, b.enabled, b.data_policy_id, 
-	b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression 
-	p.oid FROM metrics b FULL JOIN snmpv2c_metrics p ON p.metric_id = b.id %s LIMIT $1 OFFSET $2`
+	sqlSNMPv2cMetricsGetByIds   = `SELECT metric_id, oid FROM snmpv2c_metrics WHERE metric_id = ANY ($1);`
+	sqlSNMPv2cMetricsCreate     = `INSERT INTO snmpv2c_metrics (oid, metric_id) VALUES ($1, $2);`
+	sqlSNMPv2cMetricsUpdate     = `UPDATE snmpv2c_metrics SET (oid, metric_id) = ($1, $2) WHERE metric_id = $3;`
+	customSqlSNMPv2cMetricsMGet = `SELECT 
+		b.id, b.name, b.descr, b.enabled, b.data_policy_id, 
+		b.rts_pulling_times, b.rts_data_cache_duration, b.dhs_enabled, b.dhs_interval, b.type, b.ev_expression, b.labels,
+		p.oid FROM metrics b FULL JOIN snmpv2c_metrics p ON p.metric_id = b.id`
 )
 
 func (pg *PG) CreateSNMPv2cMetric(ctx context.Context, m models.Metric[models.SNMPMetric]) (id int64, err error) {
@@ -96,6 +98,7 @@ func (pg *PG) UpdateSNMPv2cMetric(ctx context.Context, m models.Metric[models.SN
 }
 
 func (pg *PG) GetSNMPv2cMetric(ctx context.Context, id int64) (exists bool, metric models.Metric[models.SNMPMetric], err error) {
+	var rawLabels []byte
 	err = pg.db.QueryRowContext(ctx, sqlFlexLegacyMetricsGet, id).Scan(
 		&metric.Base.ContainerId,
 		&metric.Base.Name,
@@ -108,6 +111,7 @@ func (pg *PG) GetSNMPv2cMetric(ctx context.Context, id int64) (exists bool, metr
 		&metric.Base.DHSInterval,
 		&metric.Base.Type,
 		&metric.Base.EvaluableExpression,
+		&rawLabels,
 		&metric.Protocol.OID,
 	)
 	if err != nil {
@@ -116,6 +120,10 @@ func (pg *PG) GetSNMPv2cMetric(ctx context.Context, id int64) (exists bool, metr
 		}
 		return false, metric, err
 	}
+	err = unmarshalLabels(rawLabels, &metric.Base.Labels)
+	if err != nil {
+		return false, metric, err
+	}
 	metric.Base.Id = id
 	metric.Base.ContainerType = types.CTSNMPv2c
 	metric.Protocol.Id = id
@@ -124,7 +132,7 @@ func (pg *PG) GetSNMPv2cMetric(ctx context.Context, id int64) (exists bool, metr
 
 func (pg *PG) GetSNMPv2cMetrics(ctx context.Context, filters SNMPv2cMetricQueryFilters) (metrics []models.Metric[models.SNMPMetric], err error) {
 	filters.ContainerType = types.CTSNMPv2c
-	sql, params, err := applyFilters(filters, customSqlFlexLegacyMetricsMGet, SNMPv2cMetricValidOrderByColumns)
+	sql, params, err := applyFilters(filters, customSqlSNMPv2cMetricsMGet, SNMPv2cMetricValidOrderByColumns)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +145,7 @@ func (pg *PG) GetSNMPv2cMetrics(ctx context.Context, filters SNMPv2cMetricQueryF
 	var metric models.Metric[models.SNMPMetric]
 	metric.Base.ContainerId = filters.ContainerId
 	metric.Base.ContainerType = filters.ContainerType
+	var rawLabels []byte
 	for rows.Next() {
 		err = rows.Scan(
 			&metric.Base.Id,
@@ -150,13 +159,18 @@ func (pg *PG) GetSNMPv2cMetrics(ctx context.Context, filters SNMPv2cMetricQueryF
 			&metric.Base.DHSInterval,
 			&metric.Base.Type,
 			&metric.Base.EvaluableExpression,
+			&rawLabels,
 			&metric.Protocol.OID,
 		)
 		if err != nil {
 			return nil, err
 		}
+		err = unmarshalLabels(rawLabels, &metric.Base.Labels)
+		if err != nil {
+			return nil, err
+		}
 		metric.Protocol.Id = metric.Base.Id
-
+		metrics = append(metrics, metric)
 	}
 	return metrics, nil
 }
@@ -2,6 +2,7 @@ package pg
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/fernandotsda/nemesys/shared/models"
 )
@@ -23,8 +24,9 @@ type MemberQueryFilters struct {
 	FirstName string `type:"ilike" column:"first_name"`
 	LastName  string `type:"ilike" column:"last_name"`
 	Username  string `type:"ilike" column:"username"`
-	Role      int16  `type:"=" column:"role"`
+	Role      int16  `type:"=" column:"u.role"`
 	Email     string `type:"ilike" column:"email"`
+	TeamRole  int16  `type:"=" column:"ut.role"`
 	OrderBy   string
 	OrderByFn string
 	Limit     int
@@ -72,20 +74,28 @@ type TeamsExistsRelUserTeamResponse struct {
 }
 
 const (
-	sqlTeamsCreate            = `INSERT INTO teams (ident, descr, name) VALUES($1, $2, $3) RETURNING id;`
-	sqlTeamsExistsIdent       = `SELECT EXISTS (SELECT 1 FROM teams WHERE ident = $1 AND id != $2);`
-	sqlTeamsDelete            = `DELETE FROM teams WHERE id = $1;`
-	sqlTeamsGet               = `SELECT ident, descr, name FROM teams WHERE id = $1;`
-	sqlTeamsGetByIdent        = `SELECT id, descr, name FROM teams WHERE ident = $1;`
-	sqlTeamsUpdate            = `UPDATE teams SET (name, ident, descr) = ($1, $2, $3) WHERE id = $4;`
-	sqlTeamsAddMember         = `INSERT INTO users_teams (user_id, team_id) VALUES ($1, $2);`
-	sqlTeamsRemMember         = `DELETE FROM users_teams WHERE user_id = $1 AND team_id = $2;`
+	sqlTeamsCreate           = `INSERT INTO teams (ident, descr, name) VALUES($1, $2, $3) RETURNING id;`
+	sqlTeamsExistsIdent      = `SELECT EXISTS (SELECT 1 FROM teams WHERE ident = $1 AND id != $2);`
+	sqlTeamsDelete           = `DELETE FROM teams WHERE id = $1;`
+	sqlTeamsGet              = `SELECT ident, descr, name FROM teams WHERE id = $1;`
+	sqlTeamsGetByIdent       = `SELECT id, descr, name FROM teams WHERE ident = $1;`
+	sqlTeamsGetIdByIdent     = `SELECT id FROM teams WHERE ident = $1;`
+	sqlTeamsUpdate           = `UPDATE teams SET (name, ident, descr) = ($1, $2, $3) WHERE id = $4;`
+	sqlTeamsAddMember        = `INSERT INTO users_teams (user_id, team_id, role) VALUES ($1, $2, $3);`
+	sqlTeamsUpdateMemberRole = `UPDATE users_teams SET role = $3 WHERE user_id = $1 AND team_id = $2;`
+	sqlTeamsRemMember        = `WITH uc AS (DELETE FROM users_contexts 
+		WHERE user_id = $1 AND ctx_id IN (SELECT id FROM contexts WHERE team_id = $2))
+		DELETE FROM users_teams WHERE user_id = $1 AND team_id = $2;`
 	sqlTeamsExistsRelUserTeam = `SELECT 
 		EXISTS(SELECT 1 FROM users_teams WHERE user_id = $1 AND team_id = $2), 
 		EXISTS(SELECT 1 FROM users WHERE id=$1), 
 		EXISTS(SELECT 1 FROM teams WHERE id=$2);`
-	sqlTeamsMemberExists      = `SELECT EXISTS (SELECT 1 FROM users_teams WHERE user_id = $1 AND team_id = $2);`
-	customSqlTeamsMGetMembers = `SELECT u.id, u.first_name, u.last_name, u.username, u.role, u.email 
+	sqlTeamsMemberExists  = `SELECT EXISTS (SELECT 1 FROM users_teams WHERE user_id = $1 AND team_id = $2);`
+	sqlTeamsGetMemberRole = `SELECT COALESCE(uc.role, ut.role) FROM users_teams ut 
+		LEFT JOIN users_contexts uc ON uc.user_id = ut.user_id AND uc.ctx_id = $3 
+		AND uc.ctx_id IN (SELECT id FROM contexts WHERE team_id = $2)
+		WHERE ut.user_id = $1 AND ut.team_id = $2;`
+	customSqlTeamsMGetMembers = `SELECT u.id, u.first_name, u.last_name, u.username, u.role, u.email, ut.role AS team_role 
 	FROM users u 
 	LEFT JOIN users_teams ut ON ut.user_id = u.id`
 	customSqlTeamsMGet = `SELECT id, name, descr, ident FROM teams`
@@ -160,6 +170,17 @@ func (pg *PG) GetTeams(ctx context.Context, filters TeamQueryFilters) (teams []m
 	return teams, nil
 }
 
+func (pg *PG) GetTeamIdByIdent(ctx context.Context, ident string) (exists bool, id int32, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlTeamsGetIdByIdent, ident).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, id, nil
+		}
+		return false, id, err
+	}
+	return true, id, nil
+}
+
 func (pg *PG) UpdateTeam(ctx context.Context, team models.Team) (exists bool, err error) {
 	t, err := pg.db.ExecContext(ctx, sqlTeamsUpdate,
 		team.Name,
@@ -182,11 +203,20 @@ func (pg *PG) ExistsRelUserTeam(ctx context.Context, userId int32, teamId int32)
 	)
 }
 
-func (pg *PG) AddTeamMember(ctx context.Context, userId int32, teamId int32) error {
-	_, err := pg.db.ExecContext(ctx, sqlTeamsAddMember, userId, teamId)
+func (pg *PG) AddTeamMember(ctx context.Context, userId int32, teamId int32, role uint8) error {
+	_, err := pg.db.ExecContext(ctx, sqlTeamsAddMember, userId, teamId, role)
 	return err
 }
 
+func (pg *PG) UpdateTeamMemberRole(ctx context.Context, userId int32, teamId int32, role uint8) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlTeamsUpdateMemberRole, userId, teamId, role)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, err
+}
+
 func (pg *PG) RemoveTeamMember(ctx context.Context, userId int32, teamId int32) (exists bool, err error) {
 	t, err := pg.db.ExecContext(ctx, sqlTeamsRemMember, userId, teamId)
 	if err != nil {
@@ -196,7 +226,7 @@ func (pg *PG) RemoveTeamMember(ctx context.Context, userId int32, teamId int32)
 	return rowsAffected != 0, err
 }
 
-func (pg *PG) GetTeamMembers(ctx context.Context, filters MemberQueryFilters) (users []models.User, err error) {
+func (pg *PG) GetTeamMembers(ctx context.Context, filters MemberQueryFilters) (users []models.Member, err error) {
 	sql, params, err := applyFilters(filters, customSqlTeamsMGetMembers, UserValidOrderByColumns)
 	if err != nil {
 		return nil, err
@@ -206,8 +236,8 @@ func (pg *PG) GetTeamMembers(ctx context.Context, filters MemberQueryFilters) (u
 		return nil, err
 	}
 	defer rows.Close()
-	users = make([]models.User, 0, filters.Limit)
-	var u models.User
+	users = make([]models.Member, 0, filters.Limit)
+	var u models.Member
 	for rows.Next() {
 		err = rows.Scan(
 			&u.Id,
@@ -216,6 +246,7 @@ func (pg *PG) GetTeamMembers(ctx context.Context, filters MemberQueryFilters) (u
 			&u.Username,
 			&u.Role,
 			&u.Email,
+			&u.TeamRole,
 		)
 		if err != nil {
 			return nil, err
@@ -228,3 +259,16 @@ func (pg *PG) GetTeamMembers(ctx context.Context, filters MemberQueryFilters) (u
 func (pg *PG) TeamMemberExists(ctx context.Context, teamId int32, userId int32) (exists bool, err error) {
 	return exists, pg.db.QueryRowContext(ctx, sqlTeamsMemberExists, userId, teamId).Scan(&exists)
 }
+
+// GetTeamMemberRole returns the member role on the team. If the context id is not
+// zero and the member has a role on the context, returns the context role instead.
+func (pg *PG) GetTeamMemberRole(ctx context.Context, teamId int32, userId int32, ctxId int32) (exists bool, role uint8, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlTeamsGetMemberRole, userId, teamId, ctxId).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, 0, nil
+		}
+		return false, 0, err
+	}
+	return true, role, nil
+}
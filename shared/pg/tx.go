@@ -0,0 +1,110 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+// Conn is a database connection or transaction.
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Tx is a database transaction.
+type Tx interface {
+	Conn
+	Commit() error
+	Rollback() error
+}
+
+// database is the database used by the PG methods, the connection pool or
+// a transaction.
+type database interface {
+	Conn
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error)
+	Close() error
+}
+
+// poolDatabase is the database connection pool.
+type poolDatabase struct {
+	*sql.DB
+}
+
+func (d poolDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	tx, err := d.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// txDatabase is a transaction used as database. The transactions began on it
+// are savepoints.
+type txDatabase struct {
+	Tx
+	// savepoints is the number of savepoints created.
+	savepoints int
+}
+
+func (d *txDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	d.savepoints++
+	sp := &savepoint{
+		Tx:   d.Tx,
+		ctx:  ctx,
+		name: "sp_" + strconv.Itoa(d.savepoints),
+	}
+	_, err := d.Tx.ExecContext(ctx, "SAVEPOINT "+sp.name)
+	if err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+func (d *txDatabase) Close() error {
+	return nil
+}
+
+// savepoint is a transaction inside a transaction.
+type savepoint struct {
+	Tx
+	ctx  context.Context
+	name string
+	done bool
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.Tx.ExecContext(sp.ctx, "RELEASE SAVEPOINT "+sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.Tx.ExecContext(sp.ctx, "ROLLBACK TO SAVEPOINT "+sp.name)
+	return err
+}
+
+// WithTx runs fn with a PG which methods are executed in a single transaction,
+// the transactions began by the methods are savepoints. The transaction is committed
+// if fn succeeds, otherwise is rolled back.
+func (pg *PG) WithTx(ctx context.Context, fn func(pg *PG) error) error {
+	tx, err := pg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = fn(&PG{db: &txDatabase{Tx: tx}})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
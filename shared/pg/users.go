@@ -2,6 +2,7 @@ package pg
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/fernandotsda/nemesys/shared/models"
 )
@@ -46,6 +47,13 @@ type UsersLoginInfoResponse struct {
 	Role int
 	// Password is the user password.
 	Password string
+	// Provider is the user authentication provider.
+	Provider string
+	// TOTPEnabled is the user TOTP enabled state.
+	TOTPEnabled bool
+	// Subject is the user identifier on the provider issuer. Is empty if
+	// the provider has no subject or the user was not bound to one.
+	Subject string
 }
 
 const (
@@ -56,12 +64,18 @@ const (
 	sqlUsersExistsUsername = `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1);`
 	sqlUsersExists         = `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1);`
 	sqlUsersCreate         = `INSERT INTO users (role, first_name, last_name, username, password, email) VALUES($1, $2, $3, $4, $5, $6) RETURNING id;`
+	sqlUsersCreateProvided = `INSERT INTO users (role, first_name, last_name, username, password, email, provider, issuer, subject) VALUES($1, $2, $3, $4, '', $5, $6, $7, $8) RETURNING id;`
 	sqlUsersUpdate         = `UPDATE users SET (role, first_name, last_name, username, password, email) = ($1, $2, $3, $4, $5, $6) WHERE id = $7`
 	sqlUsersUpdateKeepPW   = `UPDATE users SET (role, first_name, last_name, username, email) = ($1, $2, $3, $4, $5) WHERE id = $6`
 	sqlUsersDelete         = `DELETE FROM users WHERE id=$1;`
 	sqlUsersGetWithoutPW   = `SELECT username, first_name, last_name, email, role FROM users WHERE id = $1;`
-	sqlUsersLoginInfo      = `SELECT id, role, password FROM users WHERE username = $1;`
+	sqlUsersLoginInfo      = `SELECT id, role, password, provider, totp_enabled, subject FROM users WHERE username = $1;`
+	sqlUsersLoginInfoBySub = `SELECT id, role, password, provider, totp_enabled, subject FROM users WHERE issuer = $1 AND subject = $2;`
+	sqlUsersSetSubject     = `UPDATE users SET (issuer, subject) = ($1, $2) WHERE id = $3;`
 	sqlUsersGetRole        = `SELECT role FROM users WHERE id = $1;`
+	sqlUsersGetPassword    = `SELECT password, provider FROM users WHERE id = $1;`
+	sqlUsersGetByEmail     = `SELECT id, username, provider FROM users WHERE email = $1;`
+	sqlUsersUpdatePassword = `UPDATE users SET password = $1 WHERE id = $2;`
 	sqlUsersTeams          = `SELECT id, name, ident, descr FROM teams t 
 		LEFT JOIN users_teams ut ON ut.team_id = t.id 
 		WHERE ut.user_id = $1 LIMIT $2 OFFSET $3;`
@@ -91,6 +105,22 @@ func (pg *PG) CreateUser(ctx context.Context, user models.User) (id int32, err e
 	return id, pg.db.QueryRowContext(ctx, sqlUsersCreate, user.Role, user.FirstName, user.LastName, user.Username, user.Password, user.Email).Scan(&id)
 }
 
+// CreateProvidedUser creates a user of an external provider, identified on the
+// provider issuer by the subject. Subject is empty if the provider has none.
+func (pg *PG) CreateProvidedUser(ctx context.Context, user models.User, provider string, issuer string, subject string) (id int32, err error) {
+	return id, pg.db.QueryRowContext(ctx, sqlUsersCreateProvided, user.Role, user.FirstName, user.LastName, user.Username, user.Email, provider, issuer, subject).Scan(&id)
+}
+
+// SetUserSubject binds the user to the subject of the provider issuer.
+func (pg *PG) SetUserSubject(ctx context.Context, id int32, issuer string, subject string) (e bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlUsersSetSubject, issuer, subject, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, err
+}
+
 func (pg *PG) DeleteUser(ctx context.Context, id int32) (e bool, err error) {
 	t, err := pg.db.ExecContext(ctx, sqlUsersDelete, id)
 	if err != nil {
@@ -186,7 +216,17 @@ func (pg *PG) UpdateUserKeepPW(ctx context.Context, user models.User) (exists bo
 }
 
 func (pg *PG) GetLoginInfo(ctx context.Context, username string) (r UsersLoginInfoResponse, err error) {
-	rows, err := pg.db.QueryContext(ctx, sqlUsersLoginInfo, username)
+	return pg.getLoginInfo(ctx, sqlUsersLoginInfo, username)
+}
+
+// GetLoginInfoBySubject returns the login info of the user bound to the subject
+// of the provider issuer.
+func (pg *PG) GetLoginInfoBySubject(ctx context.Context, issuer string, subject string) (r UsersLoginInfoResponse, err error) {
+	return pg.getLoginInfo(ctx, sqlUsersLoginInfoBySub, issuer, subject)
+}
+
+func (pg *PG) getLoginInfo(ctx context.Context, sql string, args ...any) (r UsersLoginInfoResponse, err error) {
+	rows, err := pg.db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		return r, err
 	}
@@ -196,6 +236,9 @@ func (pg *PG) GetLoginInfo(ctx context.Context, username string) (r UsersLoginIn
 			&r.Id,
 			&r.Role,
 			&r.Password,
+			&r.Provider,
+			&r.TOTPEnabled,
+			&r.Subject,
 		)
 		if err != nil {
 			return r, err
@@ -221,6 +264,40 @@ func (pg *PG) GetUserRole(ctx context.Context, id int32) (exists bool, role int1
 	return exists, role, nil
 }
 
+// GetUserPassword returns the user password hash and provider.
+func (pg *PG) GetUserPassword(ctx context.Context, id int32) (exists bool, password string, provider string, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlUsersGetPassword, id).Scan(&password, &provider)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, password, provider, nil
+		}
+		return false, password, provider, err
+	}
+	return true, password, provider, nil
+}
+
+// GetUserByEmail returns the id, username and provider of the user of the email.
+func (pg *PG) GetUserByEmail(ctx context.Context, email string) (exists bool, id int32, username string, provider string, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlUsersGetByEmail, email).Scan(&id, &username, &provider)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, id, username, provider, nil
+		}
+		return false, id, username, provider, err
+	}
+	return true, id, username, provider, nil
+}
+
+// UpdateUserPassword updates the user password hash.
+func (pg *PG) UpdateUserPassword(ctx context.Context, id int32, password string) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlUsersUpdatePassword, password, id)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
 func (pg *PG) GetUserTeams(ctx context.Context, userId int32, limit int, offset int) (teams []models.Team, err error) {
 	rows, err := pg.db.QueryContext(ctx, sqlUsersTeams, userId, limit, offset)
 	if err != nil {
@@ -0,0 +1,69 @@
+package pg
+
+import (
+	"context"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// UsersContextsExistsMemberContextResponse is the response for ExistsMemberContext handler.
+type UsersContextsExistsMemberContextResponse struct {
+	// MemberExists is the member existence.
+	MemberExists bool
+	// ContextExists is the team context existence.
+	ContextExists bool
+}
+
+const (
+	sqlUsersContextsSet = `INSERT INTO users_contexts (user_id, ctx_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, ctx_id) DO UPDATE SET role = $3;`
+	sqlUsersContextsDelete = `DELETE FROM users_contexts WHERE user_id = $1 AND ctx_id = $2 
+		AND ctx_id IN (SELECT id FROM contexts WHERE team_id = $3);`
+	sqlUsersContextsMGet = `SELECT uc.ctx_id, uc.role FROM users_contexts uc 
+		LEFT JOIN contexts c ON c.id = uc.ctx_id WHERE uc.user_id = $1 AND c.team_id = $2 
+		ORDER BY uc.ctx_id LIMIT $3 OFFSET $4;`
+	sqlUsersContextsExistsMemberContext = `SELECT
+		EXISTS (SELECT 1 FROM users_teams WHERE user_id = $1 AND team_id = $2),
+		EXISTS (SELECT 1 FROM contexts WHERE id = $3 AND team_id = $2);`
+)
+
+// SetMemberContextRole sets the member role on a context, replacing the member
+// team role on the context.
+func (pg *PG) SetMemberContextRole(ctx context.Context, userId int32, ctxRole models.ContextRole) error {
+	_, err := pg.db.ExecContext(ctx, sqlUsersContextsSet, userId, ctxRole.ContextId, ctxRole.Role)
+	return err
+}
+
+func (pg *PG) DeleteMemberContextRole(ctx context.Context, teamId int32, userId int32, ctxId int32) (exists bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlUsersContextsDelete, userId, ctxId, teamId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, err
+}
+
+func (pg *PG) GetMemberContextsRoles(ctx context.Context, teamId int32, userId int32, limit int, offset int) (roles []models.ContextRole, err error) {
+	rows, err := pg.db.QueryContext(ctx, sqlUsersContextsMGet, userId, teamId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	roles = make([]models.ContextRole, 0, limit)
+	var r models.ContextRole
+	for rows.Next() {
+		err = rows.Scan(&r.ContextId, &r.Role)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, nil
+}
+
+func (pg *PG) ExistsMemberContext(ctx context.Context, teamId int32, userId int32, ctxId int32) (r UsersContextsExistsMemberContextResponse, err error) {
+	return r, pg.db.QueryRowContext(ctx, sqlUsersContextsExistsMemberContext, userId, teamId, ctxId).Scan(
+		&r.MemberExists,
+		&r.ContextExists,
+	)
+}
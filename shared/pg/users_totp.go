@@ -0,0 +1,104 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	sqlUsersGetTOTP             = `SELECT totp_secret, totp_enabled FROM users WHERE id = $1;`
+	sqlUsersSetTOTPSecret       = `UPDATE users SET totp_secret = $1 WHERE id = $2 AND NOT totp_enabled;`
+	sqlUsersEnableTOTP          = `UPDATE users SET totp_enabled = true WHERE id = $1 AND totp_secret != '';`
+	sqlUsersDisableTOTP         = `UPDATE users SET (totp_secret, totp_enabled) = ('', false) WHERE id = $1;`
+	sqlUsersRecoveryCodesCreate = `INSERT INTO users_recovery_codes (user_id, code_hash) VALUES ($1, $2);`
+	sqlUsersRecoveryCodesDelete = `DELETE FROM users_recovery_codes WHERE user_id = $1;`
+	sqlUsersRecoveryCodesUse    = `DELETE FROM users_recovery_codes WHERE user_id = $1 AND code_hash = $2;`
+	sqlUsersRecoveryCodesCount  = `SELECT COUNT(*) FROM users_recovery_codes WHERE user_id = $1;`
+)
+
+// GetUserTOTP returns the user TOTP secret and enabled state. The secret is
+// pending while the TOTP is not enabled.
+func (pg *PG) GetUserTOTP(ctx context.Context, userId int32) (exists bool, secret string, enabled bool, err error) {
+	err = pg.db.QueryRowContext(ctx, sqlUsersGetTOTP, userId).Scan(&secret, &enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, secret, enabled, nil
+		}
+		return false, secret, enabled, err
+	}
+	return true, secret, enabled, nil
+}
+
+// SetUserTOTPSecret sets the user pending TOTP secret. Returns false if the
+// user does not exists or the TOTP is already enabled.
+func (pg *PG) SetUserTOTPSecret(ctx context.Context, userId int32, secret string) (ok bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlUsersSetTOTPSecret, secret, userId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+// EnableUserTOTP enables the user pending TOTP secret and replaces the user
+// recovery codes. Returns false if the user has no pending secret.
+func (pg *PG) EnableUserTOTP(ctx context.Context, userId int32, recoveryCodesHashes []string) (ok bool, err error) {
+	return ok, pg.WithTx(ctx, func(pg *PG) error {
+		t, err := pg.db.ExecContext(ctx, sqlUsersEnableTOTP, userId)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ := t.RowsAffected()
+		if rowsAffected == 0 {
+			return nil
+		}
+		ok = true
+		return pg.ReplaceUserRecoveryCodes(ctx, userId, recoveryCodesHashes)
+	})
+}
+
+// DisableUserTOTP disables the user TOTP and removes its recovery codes.
+func (pg *PG) DisableUserTOTP(ctx context.Context, userId int32) (exists bool, err error) {
+	return exists, pg.WithTx(ctx, func(pg *PG) error {
+		t, err := pg.db.ExecContext(ctx, sqlUsersDisableTOTP, userId)
+		if err != nil {
+			return err
+		}
+		rowsAffected, _ := t.RowsAffected()
+		exists = rowsAffected != 0
+		_, err = pg.db.ExecContext(ctx, sqlUsersRecoveryCodesDelete, userId)
+		return err
+	})
+}
+
+// ReplaceUserRecoveryCodes replaces the user recovery codes.
+func (pg *PG) ReplaceUserRecoveryCodes(ctx context.Context, userId int32, hashes []string) (err error) {
+	return pg.WithTx(ctx, func(pg *PG) error {
+		_, err := pg.db.ExecContext(ctx, sqlUsersRecoveryCodesDelete, userId)
+		if err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			_, err = pg.db.ExecContext(ctx, sqlUsersRecoveryCodesCreate, userId, hash)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseUserRecoveryCode removes the user recovery code. Returns false if the
+// code does not exists.
+func (pg *PG) UseUserRecoveryCode(ctx context.Context, userId int32, hash string) (ok bool, err error) {
+	t, err := pg.db.ExecContext(ctx, sqlUsersRecoveryCodesUse, userId, hash)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := t.RowsAffected()
+	return rowsAffected != 0, nil
+}
+
+func (pg *PG) CountUserRecoveryCodes(ctx context.Context, userId int32) (n int, err error) {
+	return n, pg.db.QueryRowContext(ctx, sqlUsersRecoveryCodesCount, userId).Scan(&n)
+}
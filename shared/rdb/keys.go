@@ -21,10 +21,46 @@ func AuthReverseAPIKeyKey(apikeyId int32) string {
 	return "auth:apikey:id:" + strconv.FormatInt(int64(apikeyId), 10)
 }
 
+func AuthAPIKeysLastUsedKey() string {
+	return "auth:apikeys:last-used"
+}
+
+func AuthOIDCStateKey(state string) string {
+	return "auth:oidc:states:" + state
+}
+
+func AuthTOTPStepKey(userId int32, step int64) string {
+	return fmt.Sprintf("auth:totp:%d:steps:%d", userId, step)
+}
+
+func AuthTOTPFailuresKey(userId int32) string {
+	return fmt.Sprintf("auth:totp:%d:failures", userId)
+}
+
+func AuthLoginChallengeKey(token string) string {
+	return "auth:login:challenges:" + token
+}
+
+func AuthPasswordResetKey(token string) string {
+	return "auth:password-resets:" + token
+}
+
+func AuthReversePasswordResetKey(userId int32) string {
+	return "auth:users:password-resets:" + strconv.FormatInt(int64(userId), 10)
+}
+
 func CacheUserLimited(ip string, route string) string {
 	return "cache:user-limited:" + ip + ":" + route
 }
 
+func CacheRateLimitCounterKey(group string, client string) string {
+	return "cache:rate-limits:counters:" + group + ":" + client
+}
+
+func CacheRateLimitPolicyKey(group string, userId int32, apikeyId int32) string {
+	return fmt.Sprintf("cache:rate-limits:policies:%s:%d:%d", group, userId, apikeyId)
+}
+
 func CacheMetricRequestByIdent(teamIdent string, contextIdent string, metricIdent string) string {
 	return fmt.Sprintf("cache:metrics:%s_%s_%s", teamIdent, contextIdent, metricIdent)
 }
@@ -79,3 +115,7 @@ func CacheMetricAlarmExpressionsKey(metricId int64) string {
 func CacheAlarmCategoryKey(id int32) string {
 	return "cache:alarm-categories:" + strconv.FormatInt(int64(id), 10)
 }
+
+func CacheImportJobKey(id string) string {
+	return "cache:import-jobs:" + id
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/models"
 )
 
 type Service interface {
@@ -33,6 +34,9 @@ type ServiceStatus struct {
 	LostConnectionTime time.Time `json:"lost-connection-time"`
 	// Type is the service type.
 	Type Type `json:"type"`
+	// InfluxWriteBuffer is the influxdb write buffer status. Nil if
+	// the service doesn't write on influxdb.
+	InfluxWriteBuffer *models.InfluxWriteBufferStatus `json:"influx-write-buffer,omitempty"`
 }
 
 type Type uint8
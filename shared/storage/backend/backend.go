@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/influxdb"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/timescale"
+)
+
+// Connect connects to the storage backend configurated in the enviroment.
+func Connect() (s storage.Storage, err error) {
+	switch env.StorageBackend {
+	case storage.BackendInfluxDB:
+		c, err := influxdb.Connect()
+		if err != nil {
+			return nil, err
+		}
+		return &c, nil
+	case storage.BackendTimescaleDB:
+		c, err := timescale.Connect()
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	default:
+		return nil, storage.ErrUnsupportedBackend
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+// MaxDataPolicyTiers is the maximum number of aggregation tiers of a data policy.
+const MaxDataPolicyTiers = 5
+
+// AggrFns is the aggregation functions, without the percentiles.
+var AggrFns = []string{"mean", "median", "max", "min", "sum", "derivative", "nonnegative derivative", "distinct", "count", "increase", "skew", "spread", "stddev", "first", "last", "unique", "sort"}
+
+// ValidateDataPolicyTiers validates if the tiers retentions and intervals
+// are strictly increasing and if each tier interval is shorter than the
+// retention of the previous tier, which is the data aggregated by the tier.
+func ValidateDataPolicyTiers(dp models.DataPolicy) (valid bool) {
+	if len(dp.Tiers) > MaxDataPolicyTiers {
+		return false
+	}
+	retention := dp.Retention
+	var interval int32
+	for _, tier := range dp.Tiers {
+		if tier.Retention <= retention || tier.Interval <= interval {
+			return false
+		}
+		if int64(tier.Interval) >= int64(retention)*3600 {
+			return false
+		}
+		retention = tier.Retention
+		interval = tier.Interval
+	}
+	return true
+}
+
+// GetTiersRetentions returns the retention in seconds of the raw data followed by
+// the tiers retentions.
+func GetTiersRetentions(dp models.DataPolicy) (retentions []int64) {
+	retentions = make([]int64, 0, len(dp.Tiers)+1)
+	retentions = append(retentions, int64(dp.Retention)*3600)
+	for _, tier := range dp.Tiers {
+		retentions = append(retentions, int64(tier.Retention)*3600)
+	}
+	return retentions
+}
+
+// ValidateAggrFunction validates the aggregation function. Percentiles are
+// written as "p" followed by the percentile, like "p95".
+func ValidateAggrFunction(fn string) (valid bool) {
+	if _, ok := ParsePercentile(fn); ok {
+		return true
+	}
+	for _, v := range AggrFns {
+		if v == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateDataPolicyAggrFunctions validates the primary and the additional
+// aggregation functions of a data policy, which must not repeat.
+func ValidateDataPolicyAggrFunctions(dp models.DataPolicy) (valid bool) {
+	if !ValidateAggrFunction(dp.AggrFn) {
+		return false
+	}
+	for i, fn := range dp.AggrFns {
+		if !ValidateAggrFunction(fn) || fn == dp.AggrFn {
+			return false
+		}
+		for _, v := range dp.AggrFns[:i] {
+			if v == fn {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ParsePercentile parses a percentile aggregation function, returning the quantile.
+func ParsePercentile(fn string) (q float64, ok bool) {
+	if len(fn) < 2 || fn[0] != 'p' {
+		return 0, false
+	}
+	p, err := strconv.Atoi(fn[1:])
+	if err != nil || p < 1 || p > 99 || strconv.Itoa(p) != fn[1:] {
+		return 0, false
+	}
+	return float64(p) / 100, true
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+func TestValidateDataPolicyAggrFunctions(t *testing.T) {
+	tests := []struct {
+		primary string
+		fns     []string
+		want    bool
+	}{
+		{"mean", []string{"min", "max", "p95"}, true},
+		{"p50", []string{}, true},
+		{"mean", []string{"mean"}, false},
+		{"mean", []string{"max", "max"}, false},
+		{"mean", []string{"p100"}, false},
+		{"mean", []string{"p05"}, false},
+		{"mean", []string{"avg"}, false},
+	}
+	for _, test := range tests {
+		got := ValidateDataPolicyAggrFunctions(models.DataPolicy{AggrFn: test.primary, AggrFns: test.fns})
+		if got != test.want {
+			t.Errorf("ValidateDataPolicyAggrFunctions(%s, %v) failed, want: %v, got: %v", test.primary, test.fns, test.want, got)
+		}
+	}
+}
+
+func TestValidateDataPolicyTiers(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiers []models.DataPolicyTier
+		want  bool
+	}{
+		{"no tiers", nil, true},
+		{"increasing", []models.DataPolicyTier{{Retention: 720, Interval: 300}, {Retention: 17520, Interval: 3600}}, true},
+		{"retention not increasing", []models.DataPolicyTier{{Retention: 48, Interval: 300}}, false},
+		{"interval not increasing", []models.DataPolicyTier{{Retention: 720, Interval: 300}, {Retention: 17520, Interval: 300}}, false},
+		{"interval equal to source retention", []models.DataPolicyTier{{Retention: 720, Interval: 48 * 3600}}, false},
+		{"interval longer than source retention", []models.DataPolicyTier{{Retention: 720, Interval: 300}, {Retention: 17520, Interval: 720*3600 + 1}}, false},
+	}
+	for _, test := range tests {
+		got := ValidateDataPolicyTiers(models.DataPolicy{Retention: 48, Tiers: test.tiers})
+		if got != test.want {
+			t.Errorf("ValidateDataPolicyTiers %s failed, want: %v, got: %v", test.name, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package storage
+
+import "errors"
+
+var (
+	ErrUnsupportedBackend         = errors.New("unsupported storage backend")
+	ErrMetricDataResponseIsFailed = errors.New("metric data response is set as failed")
+	ErrInvalidQueryOptions        = errors.New("invalid query options")
+	ErrCustomQueryNotSupported    = errors.New("custom queries are not supported by the storage backend")
+	ErrUnsupportedAggrFunction    = errors.New("aggregation function not supported by the storage backend")
+	ErrPointOutOfRetention        = errors.New("point is older than the data policy retention")
+	ErrUnknownDataPolicy          = errors.New("unknown data policy")
+	ErrLogIsNil                   = errors.New("log is nil")
+	ErrInvalidLog                 = errors.New("invalid log format")
+)
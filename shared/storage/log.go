@@ -0,0 +1,46 @@
+package storage
+
+import "time"
+
+// Log is a parsed service log.
+type Log struct {
+	// Level is the log level.
+	Level string
+	// Service is the service identifier.
+	Service string
+	// Timestamp is the log time.
+	Timestamp time.Time
+	// Fields is the log fields, without the level, service and timestamp.
+	Fields map[string]any
+}
+
+// ParseLog parses a service log, which must have the level, serv, ts and msg fields.
+func ParseLog(log map[string]any) (l Log, err error) {
+	if log == nil {
+		return l, ErrLogIsNil
+	}
+
+	level, ok1 := log["level"].(string)
+	serv, ok2 := log["serv"].(string)
+	ts, ok3 := log["ts"].(string)
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	msg := log["msg"]
+
+	if !ok1 || !ok2 || !ok3 || err != nil || msg == nil {
+		return l, ErrInvalidLog
+	}
+
+	l = Log{
+		Level:     level,
+		Service:   serv,
+		Timestamp: timestamp,
+		Fields:    make(map[string]any, len(log)),
+	}
+	for name, v := range log {
+		if name == "level" || name == "ts" || name == "serv" {
+			continue
+		}
+		l.Fields[name] = v
+	}
+	return l, nil
+}
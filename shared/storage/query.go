@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+type QueryOptions struct {
+	// Start is the start range in seconds.
+	Start int64
+	// Stop is the end range in seconds. Can be ommited.
+	Stop int64
+	// CustomQueryFlux is the custom query flux. Can be ommited.
+	CustomQueryFlux string
+	// DataPolicyId is the data policy id.
+	DataPolicyId int16
+	// Metric id is the metric id.
+	MetricId int64
+	// MetricType is the metric type.
+	MetricType types.MetricType
+	// Aggregate is the aggregation function to read from the tiers and
+	// to aggregate the windows. Empty means the primary function.
+	Aggregate string
+	// AggrFn is the data policy primary aggregation function, required
+	// if Aggregate is set.
+	AggrFn string
+	// Step is the window duration in seconds of the aggregated series.
+	// Zero means no windowing. Can be ommited.
+	Step int64
+	// MaxPoints is the maximum number of points of the series. If the step is
+	// too small to respect it, a bigger step is used. Can be ommited.
+	MaxPoints int64
+	// Fill is the fill method of the empty windows. Default is FillNone.
+	Fill string
+	// UseTier reads the whole range from Tier, instead of the finest tier
+	// available for each part of the range.
+	UseTier bool
+	// Tier is the data policy tier to read when UseTier is true, where zero
+	// is the raw data.
+	Tier int
+}
+
+type QueryAlarmHistoryOptions struct {
+	// Start is the range start in seconds.
+	Start int64
+	// Stop is the range stop in seconds.
+	Stop int64
+	// ContianerId is the container identifier.
+	ContainerId int32
+	// MetricId is the metric identfier.
+	MetricId int64
+	// Level is the alarm category level. Nil value
+	// means get from all levels
+	Level *int32
+}
+
+const (
+	// FillNone omits the empty windows.
+	FillNone = "none"
+	// FillNull returns the empty windows with null values.
+	FillNull = "null"
+	// FillPrevious fills the empty windows with the previous value.
+	FillPrevious = "previous"
+	// FillLinear fills the empty windows with a linear interpolation.
+	FillLinear = "linear"
+)
+
+// TierRange is a time range to be read from a data policy tier.
+type TierRange struct {
+	// Tier is the data policy tier.
+	Tier int
+	// Start is the range start in seconds.
+	Start int64
+	// Stop is the range stop in seconds.
+	Stop int64
+}
+
+// ValidateFill validates the fill method.
+func ValidateFill(fill string) bool {
+	return fill == "" || fill == FillNone || fill == FillNull || fill == FillPrevious || fill == FillLinear
+}
+
+// GetQueryRanges returns the query stop and the tiers ranges to be read, where
+// retentions are the raw data retention followed by the tiers retentions, in seconds.
+func GetQueryRanges(opts QueryOptions, retentions []int64, now int64) (stop int64, ranges []TierRange, err error) {
+	stop = opts.Stop
+	if stop == 0 {
+		stop = now
+	}
+
+	if opts.UseTier {
+		if opts.Tier < 0 || opts.Tier >= len(retentions) || opts.Start >= stop {
+			return stop, nil, ErrInvalidQueryOptions
+		}
+		ranges = []TierRange{{Tier: opts.Tier, Start: opts.Start, Stop: stop}}
+	} else {
+		ranges = GetTiersRanges(opts.Start, stop, now, retentions)
+	}
+	if len(ranges) == 0 {
+		return stop, nil, ErrInvalidQueryOptions
+	}
+	return stop, ranges, nil
+}
+
+// GetTiersRanges splits the range between the data policy tiers, using the finest
+// tier available for each part of the range. Retentions are the raw data retention
+// followed by the tiers retentions, in seconds. The ranges are ordered from the
+// oldest to the newest.
+func GetTiersRanges(start int64, stop int64, now int64, retentions []int64) (ranges []TierRange) {
+	ranges = make([]TierRange, 0, len(retentions))
+	for tier := len(retentions) - 1; tier >= 0; tier-- {
+		lo := now - retentions[tier]
+		if tier == len(retentions)-1 || start > lo {
+			lo = start
+		}
+		hi := stop
+		if tier > 0 && now-retentions[tier-1] < hi {
+			hi = now - retentions[tier-1]
+		}
+		if lo >= hi {
+			continue
+		}
+		ranges = append(ranges, TierRange{Tier: tier, Start: lo, Stop: hi})
+	}
+	return ranges
+}
+
+// GetStep returns the window step in seconds, the smallest step that respects both
+// the step and the max points options. Returns zero if the series is not windowed.
+func GetStep(opts QueryOptions, stop int64) (step int64, err error) {
+	if opts.Step < 0 || opts.MaxPoints < 0 || !ValidateFill(opts.Fill) {
+		return 0, ErrInvalidQueryOptions
+	}
+	step = opts.Step
+	if opts.MaxPoints > 0 {
+		// ceil of the range divided by the max points
+		minStep := (stop - opts.Start + opts.MaxPoints - 1) / opts.MaxPoints
+		if minStep < 1 {
+			minStep = 1
+		}
+		if minStep > step {
+			step = minStep
+		}
+	}
+	if step == 0 && opts.Fill != "" && opts.Fill != FillNone {
+		// only windows can be filled
+		return 0, ErrInvalidQueryOptions
+	}
+	return step, nil
+}
+
+// GetTierAggregate returns the additional aggregation function read from the
+// tiers, or empty for the primary function.
+func GetTierAggregate(opts QueryOptions) string {
+	if opts.Aggregate == opts.AggrFn {
+		return ""
+	}
+	return opts.Aggregate
+}
+
+// GetWindowAggregate returns the aggregation function of the windows.
+func GetWindowAggregate(opts QueryOptions) string {
+	if opts.Aggregate != "" {
+		return opts.Aggregate
+	}
+	if opts.MetricType == types.MTBool {
+		return "last"
+	}
+	return "mean"
+}
+
+// GetPointTier returns the finest tier that keeps a point of the age in seconds.
+// A zero retention is infinite. Returns -1 if no tier keeps the point.
+func GetPointTier(retentions []int64, age int64) int {
+	for tier, retention := range retentions {
+		if retention == 0 || age < retention {
+			return tier
+		}
+	}
+	return -1
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetTiersRanges(t *testing.T) {
+	var now int64 = 1_000_000
+	// raw for 100s, tier 1 for 1000s, tier 2 for 10000s
+	retentions := []int64{100, 1000, 10000}
+
+	tests := []struct {
+		start, stop int64
+		want        []TierRange
+	}{
+		{now - 50, now, []TierRange{{0, now - 50, now}}},
+		{now - 500, now, []TierRange{{1, now - 500, now - 100}, {0, now - 100, now}}},
+		{now - 5000, now - 200, []TierRange{{2, now - 5000, now - 1000}, {1, now - 1000, now - 200}}},
+		{now - 50000, now - 20000, []TierRange{{2, now - 50000, now - 20000}}},
+		{now, now - 10, []TierRange{}},
+	}
+	for _, test := range tests {
+		got := GetTiersRanges(test.start, test.stop, now, retentions)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("GetTiersRanges(%d, %d) failed, want: %v, got: %v", test.start, test.stop, test.want, got)
+		}
+	}
+}
+
+func TestGetStep(t *testing.T) {
+	tests := []struct {
+		opts QueryOptions
+		want int64
+		err  error
+	}{
+		{QueryOptions{Start: 0}, 0, nil},
+		{QueryOptions{Start: 0, Step: 60}, 60, nil},
+		{QueryOptions{Start: 0, MaxPoints: 100}, 10, nil},
+		{QueryOptions{Start: 0, MaxPoints: 300}, 4, nil},
+		{QueryOptions{Start: 0, Step: 60, MaxPoints: 100}, 60, nil},
+		{QueryOptions{Start: 0, Step: 1, MaxPoints: 100}, 10, nil},
+		{QueryOptions{Start: 0, MaxPoints: 10000}, 1, nil},
+		{QueryOptions{Start: 0, Step: 60, Fill: FillLinear}, 60, nil},
+		{QueryOptions{Start: 0, Fill: FillNone}, 0, nil},
+		{QueryOptions{Start: 0, Fill: FillNull}, 0, ErrInvalidQueryOptions},
+		{QueryOptions{Start: 0, Step: 60, Fill: "zero"}, 0, ErrInvalidQueryOptions},
+	}
+	for _, test := range tests {
+		got, err := GetStep(test.opts, 1000)
+		if err != test.err {
+			t.Errorf("GetStep(%+v) failed, want err: %v, got: %v", test.opts, test.err, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("GetStep(%+v) failed, want: %d, got: %d", test.opts, test.want, got)
+		}
+	}
+}
+
+func TestGetPointTier(t *testing.T) {
+	retentions := []int64{100, 1000, 10000}
+	tests := []struct {
+		age  int64
+		want int
+	}{
+		{-10, 0},
+		{0, 0},
+		{99, 0},
+		{100, 1},
+		{9999, 2},
+		{10000, -1},
+	}
+	for _, test := range tests {
+		got := GetPointTier(retentions, test.age)
+		if got != test.want {
+			t.Errorf("GetPointTier(%d) failed, want: %d, got: %d", test.age, test.want, got)
+		}
+	}
+	if got := GetPointTier([]int64{100, 0}, 1e9); got != 1 {
+		t.Errorf("GetPointTier with infinite retention failed, want: %d, got: %d", 1, got)
+	}
+}
+
+func TestGetTierAggregate(t *testing.T) {
+	tests := []struct {
+		aggregate string
+		aggrFn    string
+		want      string
+	}{
+		{"", "", ""},
+		{"max", "max", ""},
+		{"p95", "max", "p95"},
+	}
+	for _, test := range tests {
+		got := GetTierAggregate(QueryOptions{Aggregate: test.aggregate, AggrFn: test.aggrFn})
+		if got != test.want {
+			t.Errorf("GetTierAggregate(%s, %s) failed, want: %v, got: %v", test.aggregate, test.aggrFn, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+)
+
+const (
+	// BackendInfluxDB stores the data on InfluxDB.
+	BackendInfluxDB = "influxdb"
+	// BackendTimescaleDB stores the data on TimescaleDB.
+	BackendTimescaleDB = "timescaledb"
+)
+
+// Storage is a storage backend of the metrics data, alarm history, logs and
+// requests counters.
+type Storage interface {
+	// CreateDataPolicy creates the storage of a data policy and its tiers.
+	CreateDataPolicy(ctx context.Context, dp models.DataPolicy) error
+	// UpdateDataPolicy updates the storage of a data policy, creating the new
+	// tiers and removing the tiers that no longer exist.
+	UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) error
+	// DeleteDataPolicy deletes the storage of a data policy and its data.
+	DeleteDataPolicy(ctx context.Context, id int16) error
+	// MigrateDataPolicies migrates the storage of the data policies created by
+	// older versions to the current layout.
+	MigrateDataPolicies(ctx context.Context, dps []models.DataPolicy) error
+	// CountAllMetricsPoints counts the stored points of all data policies tiers.
+	CountAllMetricsPoints(dps []models.DataPolicy) (n int, err error)
+
+	// WritePoint writes a metric data point on the raw data.
+	WritePoint(ctx context.Context, data models.MetricDataResponse, timestamp time.Time) error
+	// NewHistoryWriter returns a writer of points with their original timestamps
+	// on the tiers of the data policies.
+	NewHistoryWriter(dps []models.DataPolicy) HistoryWriter
+	// Query queries the metric data.
+	Query(ctx context.Context, opts QueryOptions) (points [][]any, err error)
+	// QueryStream queries the metric data, calling fn for each point as it is read.
+	// Stops at the first error returned by fn.
+	QueryStream(ctx context.Context, opts QueryOptions, fn func(timestamp time.Time, value any) error) error
+
+	// AnalyzeCustomQuery checks the custom query without running it, returning
+	// an error if it does not compile.
+	AnalyzeCustomQuery(ctx context.Context, cq models.CustomQuery) error
+
+	// CreateAlarmHistoryBucket creates the alarm history storage if not exists.
+	CreateAlarmHistoryBucket() (created bool, err error)
+	// WriteAlarmOccurency writes an alarm occurency.
+	WriteAlarmOccurency(occurency models.AlarmOccurency)
+	// QueryAlarmHistory queries the alarm history, returning the timestamp, value
+	// and level of each occurency.
+	QueryAlarmHistory(ctx context.Context, opts QueryAlarmHistoryOptions) (points [][3]any, err error)
+
+	// CreateLogsBucket creates the logs storage if not exists.
+	CreateLogsBucket() (created bool, err error)
+	// WriteLog writes a log.
+	WriteLog(ctx context.Context, log map[string]any) error
+
+	// CreateRequestsCountBucket creates the requests count storage if not exists.
+	CreateRequestsCountBucket() (created bool, err error)
+	WriteRequestsCount(count int64)
+	WriteRealtimeDataRequestsCount(count int64)
+	WriteHistoryDataRequestsCount(count int64)
+	GetTotalRequests(ctx context.Context) (total int64, err error)
+	GetTotalRealtimeDataRequests(ctx context.Context) (total int64, err error)
+	GetTotalDataHistoryRequests(ctx context.Context) (total int64, err error)
+
+	// StartWriteBuffer starts the write buffer of the failed writes, if the
+	// backend has one.
+	StartWriteBuffer(serviceIdent string) error
+	// PongStatus returns the service pong status function.
+	PongStatus(serviceIdent string) func() models.ServicePong
+	// Close flushes the pending writes and closes the backend.
+	Close()
+}
+
+// HistoryWriter writes metric data points with their original timestamps, on the
+// finest data policy tier that still keeps them. Points written on an aggregated
+// tier are stored as the value of every data policy aggregation function.
+type HistoryWriter interface {
+	// WritePoint buffers a data point, writing the buffered points when the batch
+	// is full. Returns ErrPointOutOfRetention if the point is older than all data
+	// policy tiers retentions, ErrUnknownDataPolicy if the data policy is unknown
+	// or the error of the batch write.
+	WritePoint(data models.MetricDataResponse, timestamp time.Time) error
+	// Flush writes the buffered points, returning the write error.
+	Flush() error
+	// Written returns the number of points written with success.
+	Written() int64
+}
@@ -0,0 +1,169 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// aggregationInterval is the interval between the tiers aggregations.
+	aggregationInterval = time.Minute
+	// aggregationLockKey is the advisory lock key that keeps a single client
+	// aggregating the tiers.
+	aggregationLockKey = 7306184523
+)
+
+const (
+	sqlAggregationLock     = `SELECT pg_try_advisory_xact_lock($1);`
+	sqlAggregationGetTiers = `SELECT t.data_policy_id, t.tier, t.aggr_interval, s.retention, t.aggregated_until, dp.aggr_fn, dp.aggr_fns
+		FROM ts_data_policies_tiers t
+		JOIN ts_data_policies_tiers s ON s.data_policy_id = t.data_policy_id AND s.tier = t.tier - 1
+		JOIN ts_data_policies dp ON dp.id = t.data_policy_id
+		WHERE t.tier > 0 ORDER BY t.data_policy_id, t.tier;`
+	sqlAggregationSetUntil = `UPDATE ts_data_policies_tiers SET aggregated_until = $3 WHERE data_policy_id = $1 AND tier = $2;`
+)
+
+// aggregation is the aggregation of the previous tier data into a tier.
+type aggregation struct {
+	dataPolicyId int16
+	tier         int
+	// interval is the aggregation interval in seconds.
+	interval int64
+	// sourceRetention is the previous tier retention in seconds.
+	sourceRetention int64
+	// until is the time until the previous tier data was aggregated.
+	until   sql.NullTime
+	aggrFn  string
+	aggrFns []string
+}
+
+// runAggregations aggregates the tiers on each interval.
+func (c *Client) runAggregations(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// errors are retried on the next run, as the aggregated range is
+			// only saved on success
+			c.aggregate(context.Background(), time.Now())
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// aggregate aggregates the data of each tier into the next tier, right before it expires
+// on the tier. Only the client that holds the aggregation lock runs the aggregation.
+func (c *Client) aggregate(ctx context.Context, now time.Time) (err error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, sqlAggregationLock, aggregationLockKey).Scan(&locked)
+	if err != nil || !locked {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, sqlAggregationGetTiers)
+	if err != nil {
+		return err
+	}
+	aggrs := make([]aggregation, 0)
+	for rows.Next() {
+		var a aggregation
+		var fns string
+		err = rows.Scan(&a.dataPolicyId, &a.tier, &a.interval, &a.sourceRetention, &a.until, &a.aggrFn, &fns)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		err = json.Unmarshal([]byte(fns), &a.aggrFns)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		aggrs = append(aggrs, a)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, a := range aggrs {
+		until := getAggregationUntil(now.Unix(), a.interval, a.sourceRetention, int64(aggregationInterval/time.Second))
+		if a.until.Valid && a.until.Time.Unix() >= until {
+			continue
+		}
+		for _, query := range getAggregationSQL(a) {
+			_, err = tx.ExecContext(ctx, query, a.until, time.Unix(until, 0))
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, sqlAggregationSetUntil, a.dataPolicyId, a.tier, time.Unix(until, 0))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// getAggregationUntil returns the time, in unix seconds, until the source tier data is
+// aggregated. It's aligned to the interval and is at least one interval plus two runs
+// ahead of the source retention, so data is aggregated before it expires.
+func getAggregationUntil(now int64, interval int64, sourceRetention int64, runInterval int64) int64 {
+	until := now - sourceRetention + interval + 2*runInterval
+	until -= until % interval
+	if until > now-interval {
+		// only complete windows are aggregated
+		until = now - interval
+		until -= until % interval
+	}
+	return until
+}
+
+// getAggregationSQL returns the inserts of the aggregation, one per aggregation function,
+// which aggregate the source data between $1 and $2. A null $1 aggregates all the source
+// data. Windows are labeled by their stop, as the InfluxDB aggregateWindow does.
+func getAggregationSQL(a aggregation) []string {
+	source := getTableName(a.dataPolicyId, a.tier-1)
+	fns := append([]string{""}, a.aggrFns...)
+	queries := make([]string, 0, len(fns))
+	for _, fn := range fns {
+		aggrFn := fn
+		if fn == "" {
+			aggrFn = a.aggrFn
+		}
+		// raw data has only the primary values
+		sourceFn := fn
+		if a.tier == 1 {
+			sourceFn = ""
+		}
+		expr, ok := getAggrFnSQL(aggrFn, "coalesce(float_value, int_value::FLOAT8, bool_value::INT::FLOAT8)")
+		if !ok {
+			continue
+		}
+		queries = append(queries, fmt.Sprintf(`INSERT INTO %s (time, metric_id, aggr_fn, float_value)
+			SELECT time_bucket(INTERVAL '%[3]d seconds', time) + INTERVAL '%[3]d seconds' AS bucket, metric_id, '%[4]s', %[5]s
+			FROM %[2]s
+			WHERE aggr_fn = '%[6]s' AND ($1::TIMESTAMPTZ IS NULL OR time >= $1) AND time < $2
+			GROUP BY bucket, metric_id
+			ON CONFLICT (metric_id, aggr_fn, time) DO UPDATE SET float_value = EXCLUDED.float_value;`,
+			getTableName(a.dataPolicyId, a.tier),
+			source,
+			a.interval,
+			fn,
+			expr,
+			sourceFn,
+		))
+	}
+	return queries
+}
@@ -0,0 +1,44 @@
+package timescale
+
+import "testing"
+
+func TestGetAggregationUntil(t *testing.T) {
+	tests := []struct {
+		now, interval, sourceRetention, runInterval int64
+		want                                        int64
+	}{
+		// aggregated one interval plus two runs ahead of the retention
+		{100_000, 300, 3600, 60, 96_600},
+		{100_020, 300, 3600, 60, 96_600},
+		{100_200, 300, 3600, 60, 96_900},
+		// only complete windows
+		{1000, 300, 100, 60, 600},
+	}
+	for _, test := range tests {
+		got := getAggregationUntil(test.now, test.interval, test.sourceRetention, test.runInterval)
+		if got != test.want {
+			t.Errorf("getAggregationUntil(%d, %d, %d) failed, want: %d, got: %d", test.now, test.interval, test.sourceRetention, test.want, got)
+		}
+	}
+}
+
+func TestGetAggrFnSQL(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want string
+		ok   bool
+	}{
+		{"mean", "avg(v)", true},
+		{"spread", "max(v) - min(v)", true},
+		{"p95", "percentile_cont(0.95) WITHIN GROUP (ORDER BY v)", true},
+		{"last", "last(v, time)", true},
+		{"derivative", "", false},
+		{"p100", "", false},
+	}
+	for _, test := range tests {
+		got, ok := getAggrFnSQL(test.fn, "v")
+		if got != test.want || ok != test.ok {
+			t.Errorf("getAggrFnSQL(%s) failed, want: %s %v, got: %s %v", test.fn, test.want, test.ok, got, ok)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package timescale
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+)
+
+const (
+	sqlAlarmHistoryGet = `SELECT time, value, level FROM alarm_history
+		WHERE container_id = $1 AND metric_id = $2 AND time >= $3 AND time < $4 AND ($5::INT4 IS NULL OR level = $5)
+		ORDER BY time;`
+)
+
+var alarmHistoryColumns = []string{"time", "container_id", "metric_id", "level", "value"}
+
+// CreateAlarmHistoryBucket creates the alarm history hypertable with the retention
+// configurated in the enviroment.
+func (c *Client) CreateAlarmHistoryBucket() (created bool, err error) {
+	hours, err := strconv.ParseInt(env.AlarmHistoryBucketRetention, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return c.createTable(alarmHistoryTable, []string{
+		"container_id INT4 NOT NULL",
+		"metric_id INT8 NOT NULL",
+		"level INT4 NOT NULL",
+		"value JSONB",
+	}, hours*3600)
+}
+
+// WriteAlarmOccurency writes an alarm occurency into the client buffer.
+func (c *Client) WriteAlarmOccurency(occurency models.AlarmOccurency) {
+	value, err := json.Marshal(occurency.Value)
+	if err != nil {
+		value = []byte("null")
+	}
+	c.write(alarmHistoryTable, alarmHistoryColumns,
+		occurency.Time,
+		occurency.ContainerId,
+		occurency.MetricId,
+		occurency.Category.Level,
+		string(value),
+	)
+}
+
+func (c *Client) QueryAlarmHistory(ctx context.Context, options storage.QueryAlarmHistoryOptions) (points [][3]any, err error) {
+	stop := time.Now()
+	if options.Stop != 0 {
+		stop = time.Unix(options.Stop, 0)
+	}
+	rows, err := c.db.QueryContext(ctx, sqlAlarmHistoryGet,
+		options.ContainerId,
+		options.MetricId,
+		time.Unix(options.Start, 0),
+		stop,
+		options.Level,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points = make([][3]any, 0)
+	for rows.Next() {
+		var t time.Time
+		var raw []byte
+		var level int32
+		err = rows.Scan(&t, &raw, &level)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		err = json.Unmarshal(raw, &value)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, [3]any{t.Unix(), value, level})
+	}
+	return points, rows.Err()
+}
+
+// createTable creates a hypertable with the retention in seconds if not exists.
+func (c *Client) createTable(table string, columns []string, retention int64) (created bool, err error) {
+	ctx := context.Background()
+
+	var exists bool
+	err = c.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL;`, table).Scan(&exists)
+	if err != nil || exists {
+		return false, err
+	}
+
+	for _, cmd := range getCreateTableSQL(table, columns, retention) {
+		_, err = c.db.ExecContext(ctx, cmd)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,98 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Client is the TimescaleDB storage backend. Each data policy tier is stored on
+// its own hypertable, with the tier retention as the table retention policy, and
+// the tiers are aggregated by the client, as the InfluxDB tasks do.
+type Client struct {
+	db *sql.DB
+
+	// mu protects the pending batches.
+	mu sync.Mutex
+	// batches is the pending writes of each table.
+	batches map[string]*batch
+	// done stops the client routines.
+	done chan struct{}
+	// wg waits the client routines.
+	wg sync.WaitGroup
+}
+
+var _ storage.Storage = (*Client)(nil)
+
+// Connect connects to TimescaleDB, creating the nemesys tables if not exists, and
+// starts the write and aggregation routines.
+func Connect() (c *Client, err error) {
+	url := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", env.TimescaleUsername, env.TimescalePassword, env.TimescaleHost, env.TimescalePort, env.TimescaleDBName)
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConn, err := strconv.Atoi(env.TimescaleMaxOpenConn)
+	if err != nil {
+		return nil, err
+	}
+	writeInterval, err := strconv.ParseInt(env.TimescaleWriteInterval, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(maxConn)
+
+	ctx := context.Background()
+	err = db.PingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cmd := range sqlSchema {
+		_, err = db.ExecContext(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c = &Client{
+		db:      db,
+		batches: make(map[string]*batch),
+		done:    make(chan struct{}),
+	}
+	c.wg.Add(2)
+	go c.runWrites(time.Duration(writeInterval) * time.Millisecond)
+	go c.runAggregations(aggregationInterval)
+	return c, nil
+}
+
+// StartWriteBuffer does nothing, failed writes are kept in memory and retried
+// on the next write.
+func (c *Client) StartWriteBuffer(serviceIdent string) (err error) {
+	return nil
+}
+
+// PongStatus returns a function that returns the service pong.
+func (c *Client) PongStatus(serviceIdent string) func() models.ServicePong {
+	return func() models.ServicePong {
+		return models.ServicePong{ServiceIdent: serviceIdent}
+	}
+}
+
+// Close stops the client routines, flushing the pending writes, and closes
+// the connection.
+func (c *Client) Close() {
+	close(c.done)
+	c.wg.Wait()
+	c.flush(context.Background())
+	c.db.Close()
+}
@@ -0,0 +1,174 @@
+package timescale
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+)
+
+const (
+	sqlDataPoliciesSave = `INSERT INTO ts_data_policies (id, aggr_fn, aggr_fns) VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET aggr_fn = EXCLUDED.aggr_fn, aggr_fns = EXCLUDED.aggr_fns;`
+	sqlDataPoliciesDelete    = `DELETE FROM ts_data_policies WHERE id = $1;`
+	sqlDataPoliciesTiersSave = `INSERT INTO ts_data_policies_tiers (data_policy_id, tier, retention, aggr_interval) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (data_policy_id, tier) DO UPDATE SET retention = EXCLUDED.retention, aggr_interval = EXCLUDED.aggr_interval;`
+	sqlDataPoliciesTiersDeleteFrom   = `DELETE FROM ts_data_policies_tiers WHERE data_policy_id = $1 AND tier >= $2;`
+	sqlDataPoliciesTiersGetRetention = `SELECT retention FROM ts_data_policies_tiers WHERE data_policy_id = $1 ORDER BY tier;`
+)
+
+// validateAggrFunctions validates if the data policy aggregation functions
+// are supported.
+func validateAggrFunctions(dp models.DataPolicy) bool {
+	for _, fn := range append([]string{dp.AggrFn}, dp.AggrFns...) {
+		if _, ok := getAggrFnSQL(fn, "v"); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateDataPolicy creates one hypertable per tier to represent a data policy.
+// Returns storage.ErrUnsupportedAggrFunction if an aggregation function is not supported.
+func (c *Client) CreateDataPolicy(ctx context.Context, dp models.DataPolicy) (err error) {
+	return c.saveDataPolicy(ctx, dp)
+}
+
+// UpdateDataPolicy updates the data policy hypertables retentions, creating the new
+// tiers and removing the tiers that no longer exist. Returns storage.ErrUnsupportedAggrFunction
+// if an aggregation function is not supported.
+func (c *Client) UpdateDataPolicy(ctx context.Context, dp models.DataPolicy) (err error) {
+	return c.saveDataPolicy(ctx, dp)
+}
+
+func (c *Client) saveDataPolicy(ctx context.Context, dp models.DataPolicy) (err error) {
+	if !validateAggrFunctions(dp) {
+		return storage.ErrUnsupportedAggrFunction
+	}
+	aggrFns, err := json.Marshal(dp.AggrFns)
+	if err != nil {
+		return err
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, sqlDataPoliciesSave, dp.Id, dp.AggrFn, string(aggrFns))
+	if err != nil {
+		return err
+	}
+
+	for tier, retention := range storage.GetTiersRetentions(dp) {
+		var interval int32
+		if tier > 0 {
+			interval = dp.Tiers[tier-1].Interval
+		}
+		_, err = tx.ExecContext(ctx, sqlDataPoliciesTiersSave, dp.Id, tier, retention, interval)
+		if err != nil {
+			return err
+		}
+
+		table := getTableName(dp.Id, tier)
+		for _, cmd := range append(getCreateTierSQL(table, retention), getRetentionSQL(table, retention)...) {
+			_, err = tx.ExecContext(ctx, cmd)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = deleteTiers(ctx, tx, dp.Id, len(dp.Tiers)+1)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteDataPolicy deletes the data policy hypertables.
+func (c *Client) DeleteDataPolicy(ctx context.Context, id int16) (err error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = deleteTiers(ctx, tx, id, 0)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, sqlDataPoliciesDelete, id)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// deleteTiers deletes the hypertables of all tiers starting at from.
+func deleteTiers(ctx context.Context, tx *sql.Tx, id int16, from int) (err error) {
+	_, err = tx.ExecContext(ctx, sqlDataPoliciesTiersDeleteFrom, id, from)
+	if err != nil {
+		return err
+	}
+	for tier := from; tier <= storage.MaxDataPolicyTiers; tier++ {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, getTableName(id, tier)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTiersRetentions returns the retention in seconds of the raw data followed
+// by the tiers retentions.
+func (c *Client) getTiersRetentions(ctx context.Context, id int16) (retentions []int64, err error) {
+	rows, err := c.db.QueryContext(ctx, sqlDataPoliciesTiersGetRetention, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	retentions = make([]int64, 0, storage.MaxDataPolicyTiers+1)
+	for rows.Next() {
+		var retention int64
+		err = rows.Scan(&retention)
+		if err != nil {
+			return nil, err
+		}
+		retentions = append(retentions, retention)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(retentions) == 0 {
+		return nil, ErrDataPolicyNotFound
+	}
+	return retentions, nil
+}
+
+// MigrateDataPolicies does nothing, the TimescaleDB storage has no layout
+// created by older versions.
+func (c *Client) MigrateDataPolicies(ctx context.Context, dps []models.DataPolicy) (err error) {
+	return nil
+}
+
+// CountAllMetricsPoints counts the points of all data policies tiers.
+func (c *Client) CountAllMetricsPoints(dps []models.DataPolicy) (n int, err error) {
+	if len(dps) == 0 {
+		return 0, nil
+	}
+	counts := make([]string, 0, len(dps))
+	for _, dp := range dps {
+		for tier := 0; tier <= len(dp.Tiers); tier++ {
+			counts = append(counts, fmt.Sprintf("(SELECT count(*) FROM %s)", getTableName(dp.Id, tier)))
+		}
+	}
+	err = c.db.QueryRowContext(context.Background(), "SELECT "+strings.Join(counts, " + ")+";").Scan(&n)
+	return n, err
+}
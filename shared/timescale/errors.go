@@ -0,0 +1,8 @@
+package timescale
+
+import "errors"
+
+var (
+	ErrUnsupportedMetricType = errors.New("unsupported metric type")
+	ErrDataPolicyNotFound    = errors.New("data policy not found")
+)
@@ -0,0 +1,38 @@
+package timescale
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+	"github.com/fernandotsda/nemesys/shared/storage"
+)
+
+var logsColumns = []string{"time", "level", "serv", "fields"}
+
+// CreateLogsBucket creates the logs hypertable if not exists.
+func (c *Client) CreateLogsBucket() (created bool, err error) {
+	hours, err := strconv.ParseInt(env.LogsBucketRetention, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return c.createTable(logsTable, []string{
+		"level TEXT NOT NULL",
+		"serv TEXT NOT NULL",
+		"fields JSONB NOT NULL",
+	}, hours*3600)
+}
+
+func (c *Client) WriteLog(ctx context.Context, log map[string]any) error {
+	l, err := storage.ParseLog(log)
+	if err != nil {
+		return err
+	}
+	fields, err := json.Marshal(l.Fields)
+	if err != nil {
+		return storage.ErrInvalidLog
+	}
+	c.write(logsTable, logsColumns, l.Timestamp, l.Level, l.Service, string(fields))
+	return nil
+}
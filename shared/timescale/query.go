@@ -0,0 +1,168 @@
+package timescale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+func (c *Client) Query(ctx context.Context, opts storage.QueryOptions) (points [][]any, err error) {
+	points = make([][]any, 0)
+	err = c.QueryStream(ctx, opts, func(timestamp time.Time, value any) error {
+		points = append(points, []any{timestamp.Unix(), value})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// QueryStream queries the metric data, calling fn for each point as it is read,
+// without buffering the result. Stops at the first error returned by fn. Custom
+// queries are not supported.
+func (c *Client) QueryStream(ctx context.Context, opts storage.QueryOptions, fn func(timestamp time.Time, value any) error) (err error) {
+	if opts.CustomQueryFlux != "" {
+		return storage.ErrCustomQueryNotSupported
+	}
+
+	retentions, err := c.getTiersRetentions(ctx, opts.DataPolicyId)
+	if err != nil {
+		return err
+	}
+
+	query, args, err := getQuerySQL(opts, retentions, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var timestamp time.Time
+		var value any
+		err = rows.Scan(&timestamp, &value)
+		if err != nil {
+			return err
+		}
+		err = fn(timestamp, value)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// getValueColumn returns the column of the metric type values.
+func getValueColumn(mt types.MetricType) string {
+	switch mt {
+	case types.MTBool:
+		return "bool_value"
+	case types.MTFloat:
+		return "float_value"
+	default:
+		return "int_value"
+	}
+}
+
+// getQuerySQL returns the query of the metric data and its arguments.
+func getQuerySQL(opts storage.QueryOptions, retentions []int64, now int64) (query string, args []any, err error) {
+	stop, ranges, err := storage.GetQueryRanges(opts, retentions, now)
+	if err != nil {
+		return query, nil, err
+	}
+
+	step, err := storage.GetStep(opts, stop)
+	if err != nil {
+		return query, nil, err
+	}
+
+	// $1 is the metric id and $2, if any tier is read, the additional aggregation function
+	args = []any{opts.MetricId}
+
+	selects := make([]string, len(ranges))
+	for i, r := range ranges {
+		// raw data has only the metric type primary values
+		column, aggrFn := getValueColumn(opts.MetricType), "''"
+		if r.Tier > 0 {
+			column, aggrFn = "float_value", "$2"
+			if len(args) == 1 {
+				args = append(args, storage.GetTierAggregate(opts))
+			}
+		}
+		selects[i] = fmt.Sprintf(`SELECT time, %s AS value FROM %s WHERE metric_id = $1 AND aggr_fn = %s AND time >= to_timestamp(%d) AND time < to_timestamp(%d)`,
+			column,
+			getTableName(opts.DataPolicyId, r.Tier),
+			aggrFn,
+			r.Start,
+			r.Stop,
+		)
+	}
+	series := strings.Join(selects, " UNION ALL ")
+	if step == 0 {
+		return series + " ORDER BY time;", args, nil
+	}
+	query, err = getWindowSQL(opts, series, step, stop)
+	return query, args, err
+}
+
+// getWindowSQL returns the query that aggregates the series into evenly spaced windows,
+// filling the empty ones. Windows are labeled by their stop, as the InfluxDB aggregateWindow
+// does.
+func getWindowSQL(opts storage.QueryOptions, series string, step int64, stop int64) (query string, err error) {
+	value := "value"
+	if opts.MetricType == types.MTBool {
+		value = "value::INT::FLOAT8"
+	}
+	fn := storage.GetWindowAggregate(opts)
+	expr, ok := getAggrFnSQL(fn, value)
+	if !ok {
+		return query, storage.ErrInvalidQueryOptions
+	}
+	if opts.MetricType == types.MTBool && (fn == "last" || fn == "first") {
+		// bool series keep their type
+		expr = fmt.Sprintf("%s(value, time)", fn)
+	}
+
+	bucket := fmt.Sprintf("time_bucket(INTERVAL '%d seconds', time)", step)
+	switch opts.Fill {
+	case storage.FillNull, storage.FillPrevious, storage.FillLinear:
+		bucket = fmt.Sprintf("time_bucket_gapfill(INTERVAL '%d seconds', time, to_timestamp(%d), to_timestamp(%d))", step, opts.Start, stop)
+	}
+	switch opts.Fill {
+	case storage.FillPrevious:
+		expr = fmt.Sprintf("locf(%s)", expr)
+	case storage.FillLinear:
+		if opts.MetricType == types.MTBool {
+			return query, storage.ErrInvalidQueryOptions
+		}
+		expr = fmt.Sprintf("interpolate(%s)", expr)
+	}
+
+	return fmt.Sprintf(`SELECT bucket + INTERVAL '%d seconds' AS time, value FROM (
+			SELECT %s AS bucket, %s AS value FROM (%s) series
+			WHERE time >= to_timestamp(%d) AND time < to_timestamp(%d)
+			GROUP BY bucket
+		) windows ORDER BY time;`,
+		step,
+		bucket,
+		expr,
+		series,
+		opts.Start,
+		stop,
+	), nil
+}
+
+// AnalyzeCustomQuery returns ErrCustomQueryNotSupported, custom queries are not supported.
+func (c *Client) AnalyzeCustomQuery(ctx context.Context, cq models.CustomQuery) (err error) {
+	return storage.ErrCustomQueryNotSupported
+}
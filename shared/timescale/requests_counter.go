@@ -0,0 +1,70 @@
+package timescale
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/env"
+)
+
+const (
+	requestsCountMeasurement         = "requests"
+	requestsRealtimeCountMeasurement = "requests-realtime-data"
+	requestsHistoryCountMeasurement  = "requests-history-data"
+)
+
+const (
+	sqlRequestsCountGetTotal = `SELECT coalesce(sum(count), 0)::INT8 FROM requests_counts
+		WHERE measurement = $1 AND time >= now() - make_interval(hours => $2);`
+)
+
+var requestsCountColumns = []string{"time", "measurement", "count"}
+
+func (c *Client) CreateRequestsCountBucket() (created bool, err error) {
+	hours, err := strconv.ParseInt(env.RequestsCountBucketRetention, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return c.createTable(requestsCountTable, []string{
+		"measurement TEXT NOT NULL",
+		"count INT8 NOT NULL",
+	}, hours*3600)
+}
+
+func (c *Client) WriteRequestsCount(count int64) {
+	c.writeRequestsCount(count, requestsCountMeasurement)
+}
+
+func (c *Client) WriteRealtimeDataRequestsCount(count int64) {
+	c.writeRequestsCount(count, requestsRealtimeCountMeasurement)
+}
+
+func (c *Client) WriteHistoryDataRequestsCount(count int64) {
+	c.writeRequestsCount(count, requestsHistoryCountMeasurement)
+}
+
+func (c *Client) writeRequestsCount(count int64, measurement string) {
+	c.write(requestsCountTable, requestsCountColumns, time.Now(), measurement, count)
+}
+
+func (c *Client) GetTotalRequests(ctx context.Context) (total int64, err error) {
+	return c.getTotalRequests(ctx, requestsCountMeasurement)
+}
+
+func (c *Client) GetTotalRealtimeDataRequests(ctx context.Context) (total int64, err error) {
+	return c.getTotalRequests(ctx, requestsRealtimeCountMeasurement)
+}
+
+func (c *Client) GetTotalDataHistoryRequests(ctx context.Context) (total int64, err error) {
+	return c.getTotalRequests(ctx, requestsHistoryCountMeasurement)
+}
+
+func (c *Client) getTotalRequests(ctx context.Context, measurement string) (total int64, err error) {
+	hours, err := strconv.Atoi(env.RequestsCountBucketRetention)
+	if err != nil {
+		return total, err
+	}
+	err = c.db.QueryRowContext(ctx, sqlRequestsCountGetTotal, measurement, hours).Scan(&total)
+	return total, err
+}
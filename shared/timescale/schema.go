@@ -0,0 +1,123 @@
+package timescale
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fernandotsda/nemesys/shared/storage"
+)
+
+const (
+	alarmHistoryTable  = "alarm_history"
+	logsTable          = "logs"
+	requestsCountTable = "requests_counts"
+)
+
+// sqlSchema is the tables that keep the data policies and the tiers aggregations state.
+// The tables are prefixed to not clash with the postgres tables when both share the
+// same database.
+var sqlSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS timescaledb;`,
+	// rename the unprefixed tables of older versions, identified by the aggregation state
+	`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'data_policies_tiers' AND column_name = 'aggregated_until')
+			AND NOT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_name = 'ts_data_policies') THEN
+			ALTER TABLE data_policies RENAME TO ts_data_policies;
+			ALTER TABLE data_policies_tiers RENAME TO ts_data_policies_tiers;
+		END IF;
+	END $$;`,
+	`CREATE TABLE IF NOT EXISTS ts_data_policies (
+		id INT2 PRIMARY KEY,
+		aggr_fn TEXT NOT NULL,
+		aggr_fns TEXT NOT NULL
+	);`,
+	`CREATE TABLE IF NOT EXISTS ts_data_policies_tiers (
+		data_policy_id INT2 NOT NULL REFERENCES ts_data_policies(id) ON DELETE CASCADE,
+		tier INT2 NOT NULL,
+		retention INT8 NOT NULL,
+		aggr_interval INT4 NOT NULL,
+		aggregated_until TIMESTAMPTZ,
+		PRIMARY KEY (data_policy_id, tier)
+	);`,
+}
+
+// getTableName returns the hypertable name of a data policy tier. Tier 0 is the raw data.
+func getTableName(dataPolicyId int16, tier int) string {
+	return fmt.Sprintf("metrics_%d_%d", dataPolicyId, tier)
+}
+
+// getChunkInterval returns the hypertable chunk interval in seconds for the retention.
+func getChunkInterval(retention int64) int64 {
+	if retention < 172800 {
+		return 3600 // 1h
+	} else if retention < 15552000 {
+		return 86400 // 1d
+	}
+	return 604800 // 7d
+}
+
+// getCreateTierSQL returns the commands that create the hypertable of a data policy
+// tier, with the retention in seconds. Raw data is stored on the metric type column,
+// while the aggregated tiers store all values as float, with the primary aggregation
+// function as an empty aggr_fn.
+func getCreateTierSQL(table string, retention int64) []string {
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			time TIMESTAMPTZ NOT NULL,
+			metric_id INT8 NOT NULL,
+			aggr_fn TEXT NOT NULL,
+			int_value INT8,
+			float_value FLOAT8,
+			bool_value BOOL
+		);`, table),
+		fmt.Sprintf(`SELECT create_hypertable('%s', 'time', chunk_time_interval => INTERVAL '%d seconds', if_not_exists => TRUE);`,
+			table, getChunkInterval(retention)),
+		fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s_metric_id_idx ON %s (metric_id, aggr_fn, time DESC);`, table, table),
+	}
+}
+
+// getRetentionSQL returns the commands that replace the table retention policy.
+func getRetentionSQL(table string, retention int64) []string {
+	return []string{
+		fmt.Sprintf(`SELECT remove_retention_policy('%s', if_exists => TRUE);`, table),
+		fmt.Sprintf(`SELECT add_retention_policy('%s', INTERVAL '%d seconds');`, table, retention),
+	}
+}
+
+// getCreateTableSQL returns the commands that create a hypertable with the columns.
+func getCreateTableSQL(table string, columns []string, retention int64) []string {
+	return append([]string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (time TIMESTAMPTZ NOT NULL, %s);`, table, strings.Join(columns, ", ")),
+		fmt.Sprintf(`SELECT create_hypertable('%s', 'time', chunk_time_interval => INTERVAL '%d seconds', if_not_exists => TRUE);`,
+			table, getChunkInterval(retention)),
+	}, getRetentionSQL(table, retention)...)
+}
+
+// aggrFnsSQL is the SQL expressions of the supported aggregation functions, where
+// %[1]s is the value.
+var aggrFnsSQL = map[string]string{
+	"mean":   "avg(%[1]s)",
+	"median": "percentile_cont(0.5) WITHIN GROUP (ORDER BY %[1]s)",
+	"max":    "max(%[1]s)",
+	"min":    "min(%[1]s)",
+	"sum":    "sum(%[1]s)",
+	"count":  "count(%[1]s)::FLOAT8",
+	"spread": "max(%[1]s) - min(%[1]s)",
+	"stddev": "stddev_samp(%[1]s)",
+	"first":  "first(%[1]s, time)",
+	"last":   "last(%[1]s, time)",
+}
+
+// getAggrFnSQL returns the SQL expression of the aggregation function over the value.
+// Returns false if the function is not supported.
+func getAggrFnSQL(fn string, value string) (expr string, ok bool) {
+	if q, ok := storage.ParsePercentile(fn); ok {
+		return fmt.Sprintf("percentile_cont(%g) WITHIN GROUP (ORDER BY %s)", q, value), true
+	}
+	f, ok := aggrFnsSQL[fn]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf(f, value), true
+}
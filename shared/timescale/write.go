@@ -0,0 +1,257 @@
+package timescale
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fernandotsda/nemesys/shared/models"
+	"github.com/fernandotsda/nemesys/shared/storage"
+	"github.com/fernandotsda/nemesys/shared/types"
+)
+
+const (
+	// maxInsertRows is the maximum number of rows of a single insert.
+	maxInsertRows = 1000
+	// maxPendingRows is the maximum number of pending rows of a table. The oldest
+	// rows are dropped while the database is unreachable.
+	maxPendingRows = 500000
+)
+
+// pointsColumns is the columns of the metrics points written on the tiers.
+var pointsColumns = []string{"time", "metric_id", "aggr_fn", "int_value", "float_value", "bool_value"}
+
+// batch is the pending rows of a table.
+type batch struct {
+	// columns is the table columns written.
+	columns []string
+	// rows is the pending rows.
+	rows [][]any
+}
+
+// write adds a row to the table pending writes.
+func (c *Client) write(table string, columns []string, row ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.batches[table]
+	if !ok {
+		b = &batch{columns: columns}
+		c.batches[table] = b
+	}
+	b.rows = append(b.rows, row)
+	if len(b.rows) > maxPendingRows {
+		b.rows = b.rows[len(b.rows)-maxPendingRows:]
+	}
+}
+
+// runWrites flushes the pending writes on each interval.
+func (c *Client) runWrites(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.flush(context.Background())
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// flush writes the pending rows of all tables. The rows of a failed table are
+// kept to be retried on the next flush.
+func (c *Client) flush(ctx context.Context) {
+	c.mu.Lock()
+	batches := c.batches
+	c.batches = make(map[string]*batch, len(batches))
+	c.mu.Unlock()
+
+	for table, b := range batches {
+		for len(b.rows) > 0 {
+			n := len(b.rows)
+			if n > maxInsertRows {
+				n = maxInsertRows
+			}
+			_, err := c.db.ExecContext(ctx, getInsertSQL(table, b.columns, n), flatten(b.rows[:n])...)
+			if err != nil {
+				for _, row := range b.rows {
+					c.write(table, b.columns, row...)
+				}
+				break
+			}
+			b.rows = b.rows[n:]
+		}
+	}
+}
+
+// getInsertSQL returns the insert of n rows on the table. Rows that already exist
+// are ignored.
+func getInsertSQL(table string, columns []string, n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteByte('(')
+		for j := range columns {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "$%d", i*len(columns)+j+1)
+		}
+		b.WriteByte(')')
+	}
+	b.WriteString(" ON CONFLICT DO NOTHING;")
+	return b.String()
+}
+
+func flatten(rows [][]any) []any {
+	args := make([]any, 0, len(rows)*len(rows[0]))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+	return args
+}
+
+// getPointRow returns the row of a metric point. Raw data is written on the metric
+// type column, while aggregated tiers store the value as float.
+func getPointRow(data models.MetricDataResponse, timestamp time.Time, tier int) []any {
+	var i, f, b any
+	switch v := data.Value.(type) {
+	case int64:
+		i = v
+	case float64:
+		f = v
+	case bool:
+		b = v
+	}
+	if tier > 0 {
+		switch v := data.Value.(type) {
+		case int64:
+			i, f = nil, float64(v)
+		case bool:
+			b, f = nil, 0.0
+			if v {
+				f = 1.0
+			}
+		}
+	}
+	return []any{timestamp, data.Id, "", i, f, b}
+}
+
+// WritePoint writes a data point into the client buffer. ContainerId is ignored.
+func (c *Client) WritePoint(ctx context.Context, data models.MetricDataResponse, timestamp time.Time) error {
+	if data.Failed {
+		return storage.ErrMetricDataResponseIsFailed
+	}
+	if data.Type == types.MTString {
+		return ErrUnsupportedMetricType
+	}
+	c.write(getTableName(data.DataPolicyId, 0), pointsColumns, getPointRow(data, timestamp, 0)...)
+	return nil
+}
+
+// historyWriter writes metric data points with their original timestamps on the
+// finest data policy tier that still keeps them. The points are written in batches,
+// bypassing the client buffer, so the write errors are reported to the caller.
+type historyWriter struct {
+	c *Client
+	// now is the reference time, in unix seconds, of the points age.
+	now int64
+	// dps is the data policies by id.
+	dps map[int16]models.DataPolicy
+	// batches is the pending rows of each table.
+	batches map[string]*batch
+	// pending is the number of pending points.
+	pending int
+	// written is the number of points written.
+	written int64
+}
+
+// NewHistoryWriter returns a new history writer.
+func (c *Client) NewHistoryWriter(dps []models.DataPolicy) storage.HistoryWriter {
+	w := &historyWriter{
+		c:       c,
+		now:     time.Now().Unix(),
+		dps:     make(map[int16]models.DataPolicy, len(dps)),
+		batches: make(map[string]*batch),
+	}
+	for _, dp := range dps {
+		w.dps[dp.Id] = dp
+	}
+	return w
+}
+
+// WritePoint buffers a data point, writing the pending points when the batch is full.
+// Returns storage.ErrPointOutOfRetention if the point is older than all data policy
+// tiers retentions. Points written on an aggregated tier are stored as the value of
+// every aggregation function.
+func (w *historyWriter) WritePoint(data models.MetricDataResponse, timestamp time.Time) (err error) {
+	if data.Failed {
+		return storage.ErrMetricDataResponseIsFailed
+	}
+	if data.Type == types.MTString {
+		return ErrUnsupportedMetricType
+	}
+
+	dp, ok := w.dps[data.DataPolicyId]
+	if !ok {
+		return storage.ErrUnknownDataPolicy
+	}
+	tier := storage.GetPointTier(storage.GetTiersRetentions(dp), w.now-timestamp.Unix())
+	if tier < 0 {
+		return storage.ErrPointOutOfRetention
+	}
+
+	table := getTableName(data.DataPolicyId, tier)
+	b, ok := w.batches[table]
+	if !ok {
+		b = &batch{columns: pointsColumns}
+		w.batches[table] = b
+	}
+	b.rows = append(b.rows, getPointRow(data, timestamp, tier))
+	if tier > 0 {
+		for _, fn := range dp.AggrFns {
+			row := getPointRow(data, timestamp, tier)
+			row[2] = fn
+			b.rows = append(b.rows, row)
+		}
+	}
+
+	w.pending++
+	if w.pending >= maxInsertRows {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Flush writes the pending points.
+func (w *historyWriter) Flush() (err error) {
+	ctx := context.Background()
+	for table, b := range w.batches {
+		for len(b.rows) > 0 {
+			n := len(b.rows)
+			if n > maxInsertRows {
+				n = maxInsertRows
+			}
+			_, err = w.c.db.ExecContext(ctx, getInsertSQL(table, b.columns, n), flatten(b.rows[:n])...)
+			if err != nil {
+				return err
+			}
+			b.rows = b.rows[n:]
+		}
+		delete(w.batches, table)
+	}
+	w.written += int64(w.pending)
+	w.pending = 0
+	return nil
+}
+
+// Written returns the number of points written.
+func (w *historyWriter) Written() int64 {
+	return w.written
+}